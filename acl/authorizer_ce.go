@@ -10,6 +10,11 @@ package acl
 type AuthorizerContext struct {
 	// Peer is the name of the peer that the resource was imported from.
 	Peer string
+
+	// Request describes the request the token is used for. It is only
+	// consulted when resolving the token, to evaluate the conditions of
+	// its policies' rules.
+	Request *RequestContext
 }
 
 func (c *AuthorizerContext) PeerOrEmpty() string {
@@ -19,6 +24,13 @@ func (c *AuthorizerContext) PeerOrEmpty() string {
 	return c.Peer
 }
 
+func (c *AuthorizerContext) RequestOrNil() *RequestContext {
+	if c == nil {
+		return nil
+	}
+	return c.Request
+}
+
 // enterpriseAuthorizer stub interface
 type enterpriseAuthorizer interface{}
 
@@ -0,0 +1,447 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package acl
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestContext describes the request an Authorizer is resolved for. Rule
+// conditions are evaluated against it.
+type RequestContext struct {
+	// Time is when the request was made. The current time is used if unset.
+	Time time.Time
+
+	// SourceAddr is the address of the client that made the request. It is
+	// nil when unknown, in which case no source_cidrs condition is met.
+	SourceAddr net.IP
+
+	// Headers are the headers of the request. They are nil when unknown, in
+	// which case no required_headers condition is met.
+	Headers http.Header
+}
+
+// RuleConditions restrict when a rule applies. A rule with conditions only
+// grants access to requests meeting all of them, and is ignored otherwise.
+// Conditions can be set on individual rules, or for all the rules of a policy
+// in a top-level conditions block.
+//
+// Conditions can't be set on deny rules, or on a policy with deny rules, as a
+// request that doesn't meet them would then be allowed. The source address and
+// headers are only known for requests to the agent's own HTTP API, so
+// source_cidrs and required_headers can only be set on agent and agent_prefix
+// rules.
+//
+// Conditions are evaluated when the token is resolved for a request. A blocking
+// query keeps the rules that applied when it started, so it can still return
+// results up to its wait time after a window ends. Streaming subscriptions are
+// closed when a window starts or ends, so that clients subscribe again with
+// the rules that apply then.
+type RuleConditions struct {
+	// ValidBetween is the RFC 3339 start and end time of the window the rule
+	// applies in.
+	ValidBetween []string `hcl:"valid_between"`
+
+	// SourceCIDRs are the networks requests must be made from.
+	SourceCIDRs []string `hcl:"source_cidrs"`
+
+	// RequiredHeaders are the headers requests must have. An empty value
+	// requires the header to be present with any value.
+	RequiredHeaders map[string]string `hcl:"required_headers"`
+}
+
+// ruleConditions returns the conditions of a rule embedding RuleConditions.
+func (c *RuleConditions) ruleConditions() *RuleConditions {
+	return c
+}
+
+// isEmpty returns true if no conditions are set.
+func (c *RuleConditions) isEmpty() bool {
+	return c == nil || (len(c.ValidBetween) == 0 && len(c.SourceCIDRs) == 0 && len(c.RequiredHeaders) == 0)
+}
+
+// hasRequestConditions returns true if the conditions depend on the source
+// address or the headers of the request.
+func (c *RuleConditions) hasRequestConditions() bool {
+	return len(c.SourceCIDRs) > 0 || len(c.RequiredHeaders) > 0
+}
+
+// validate checks that the conditions are well formed.
+func (c *RuleConditions) validate() error {
+	if c.isEmpty() {
+		// Drop empty collections left over from decoding, so that rules without
+		// conditions compare equal to their zero value.
+		*c = RuleConditions{}
+		return nil
+	}
+
+	if len(c.ValidBetween) > 0 {
+		if _, _, err := c.window(); err != nil {
+			return err
+		}
+	}
+	for _, cidr := range c.SourceCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid source_cidrs entry %q: %v", cidr, err)
+		}
+	}
+	for name := range c.RequiredHeaders {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("required_headers can't contain an empty header name")
+		}
+	}
+	return nil
+}
+
+// window returns the start and end of the ValidBetween window.
+func (c *RuleConditions) window() (time.Time, time.Time, error) {
+	if len(c.ValidBetween) != 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("valid_between must be a list of a start and an end time")
+	}
+	start, err := time.Parse(time.RFC3339, c.ValidBetween[0])
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid valid_between start time: %v", err)
+	}
+	end, err := time.Parse(time.RFC3339, c.ValidBetween[1])
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid valid_between end time: %v", err)
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("valid_between start time must be before its end time")
+	}
+	return start, end, nil
+}
+
+// met returns true if the request meets all of the conditions. Conditions are
+// only evaluated once validated, so malformed conditions are never met.
+func (c *RuleConditions) met(req *RequestContext) bool {
+	if c.isEmpty() {
+		return true
+	}
+	if req == nil {
+		req = &RequestContext{}
+	}
+
+	if len(c.ValidBetween) > 0 {
+		now := req.Time
+		if now.IsZero() {
+			now = time.Now()
+		}
+		start, end, err := c.window()
+		if err != nil || now.Before(start) || !now.Before(end) {
+			return false
+		}
+	}
+
+	if len(c.SourceCIDRs) > 0 {
+		if req.SourceAddr == nil {
+			return false
+		}
+		var found bool
+		for _, cidr := range c.SourceCIDRs {
+			_, network, err := net.ParseCIDR(cidr)
+			if err == nil && network.Contains(req.SourceAddr) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for name, value := range c.RequiredHeaders {
+		values, ok := req.Headers[http.CanonicalHeaderKey(name)]
+		if !ok || len(values) == 0 {
+			return false
+		}
+		if value != "" && values[0] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// conditionalRule is implemented by the rules embedding RuleConditions.
+type conditionalRule interface {
+	ruleConditions() *RuleConditions
+
+	// denies returns true if the rule denies access to anything.
+	denies() bool
+}
+
+func (r *AgentRule) denies() bool         { return r.Policy == PolicyDeny }
+func (r *KeyRule) denies() bool           { return r.Policy == PolicyDeny }
+func (r *NodeRule) denies() bool          { return r.Policy == PolicyDeny }
+func (r *SessionRule) denies() bool       { return r.Policy == PolicyDeny }
+func (r *EventRule) denies() bool         { return r.Policy == PolicyDeny }
+func (r *PreparedQueryRule) denies() bool { return r.Policy == PolicyDeny }
+
+func (r *ServiceRule) denies() bool {
+	return r.Policy == PolicyDeny || r.Intentions == PolicyDeny
+}
+
+// rulesForRequest returns the rules whose conditions the request meets. It
+// appends a '1' or '0' to key for every conditional rule, depending on whether
+// its conditions were met. If policyMet is false, the conditions of the whole
+// policy weren't met and only the deny rules are returned.
+//
+// Deny rules are always returned, so that a condition that isn't met, or that
+// can't be evaluated for lack of a request, never lifts a deny. They can't
+// have conditions, but policies predating that check might.
+func rulesForRequest[R conditionalRule](rules []R, req *RequestContext, policyMet bool, key *strings.Builder) []R {
+	var out []R
+	for _, rule := range rules {
+		cond := rule.ruleConditions()
+		switch {
+		case rule.denies():
+			out = append(out, rule)
+		case !policyMet:
+		case cond.isEmpty():
+			out = append(out, rule)
+		case cond.met(req):
+			key.WriteByte('1')
+			out = append(out, rule)
+		default:
+			key.WriteByte('0')
+		}
+	}
+	return out
+}
+
+// validateRuleConditions validates the conditions of the given rules. Only
+// the rules of agent resources may set conditions on the request itself.
+func validateRuleConditions[R conditionalRule](kind string, rules []R, allowRequestConditions bool) error {
+	for _, rule := range rules {
+		cond := rule.ruleConditions()
+		if err := cond.validate(); err != nil {
+			return fmt.Errorf("Invalid %s conditions: %#v, got error: %v", kind, rule, err)
+		}
+		if cond.isEmpty() {
+			continue
+		}
+		if rule.denies() {
+			return fmt.Errorf("Invalid %s conditions: %#v, got error: conditions can't be set on deny rules", kind, rule)
+		}
+		if !allowRequestConditions && cond.hasRequestConditions() {
+			return fmt.Errorf("Invalid %s conditions: %#v, got error: source_cidrs and required_headers can only be set on agent and agent_prefix rules", kind, rule)
+		}
+	}
+	return nil
+}
+
+// anyDenies returns true if any of the rules deny access to anything.
+func anyDenies[R conditionalRule](rules []R) bool {
+	for _, rule := range rules {
+		if rule.denies() {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDenies returns true if any of the policy's rules deny access to anything.
+func (pr *PolicyRules) hasDenies() bool {
+	for _, policy := range []string{pr.ACL, pr.Keyring, pr.Operator, pr.Mesh, pr.Peering} {
+		if policy == PolicyDeny {
+			return true
+		}
+	}
+	return anyDenies(pr.Agents) || anyDenies(pr.AgentPrefixes) ||
+		anyDenies(pr.Keys) || anyDenies(pr.KeyPrefixes) ||
+		anyDenies(pr.Nodes) || anyDenies(pr.NodePrefixes) ||
+		anyDenies(pr.Services) || anyDenies(pr.ServicePrefixes) ||
+		anyDenies(pr.Sessions) || anyDenies(pr.SessionPrefixes) ||
+		anyDenies(pr.Events) || anyDenies(pr.EventPrefixes) ||
+		anyDenies(pr.PreparedQueries) || anyDenies(pr.PreparedQueryPrefixes)
+}
+
+// validateConditions validates the conditions of the policy and its rules.
+func (pr *PolicyRules) validateConditions() error {
+	if pr.Conditions != nil {
+		if err := pr.Conditions.validate(); err != nil {
+			return fmt.Errorf("Invalid policy conditions: %v", err)
+		}
+		if pr.Conditions.isEmpty() {
+			pr.Conditions = nil
+		}
+	}
+	if pr.Conditions != nil {
+		if pr.Conditions.hasRequestConditions() {
+			return fmt.Errorf("Invalid policy conditions: source_cidrs and required_headers can only be set on agent and agent_prefix rules")
+		}
+		if pr.hasDenies() {
+			return fmt.Errorf("Invalid policy conditions: conditions can't be set on a policy with deny rules")
+		}
+	}
+
+	for _, v := range []func() error{
+		func() error { return validateRuleConditions("agent", pr.Agents, true) },
+		func() error { return validateRuleConditions("agent_prefix", pr.AgentPrefixes, true) },
+		func() error { return validateRuleConditions("key", pr.Keys, false) },
+		func() error { return validateRuleConditions("key_prefix", pr.KeyPrefixes, false) },
+		func() error { return validateRuleConditions("node", pr.Nodes, false) },
+		func() error { return validateRuleConditions("node_prefix", pr.NodePrefixes, false) },
+		func() error { return validateRuleConditions("service", pr.Services, false) },
+		func() error { return validateRuleConditions("service_prefix", pr.ServicePrefixes, false) },
+		func() error { return validateRuleConditions("session", pr.Sessions, false) },
+		func() error { return validateRuleConditions("session_prefix", pr.SessionPrefixes, false) },
+		func() error { return validateRuleConditions("event", pr.Events, false) },
+		func() error { return validateRuleConditions("event_prefix", pr.EventPrefixes, false) },
+		func() error { return validateRuleConditions("query", pr.PreparedQueries, false) },
+		func() error { return validateRuleConditions("query_prefix", pr.PreparedQueryPrefixes, false) },
+	} {
+		if err := v(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasConditions returns true if any of the rules have conditions.
+func hasConditions[R conditionalRule](rules []R) bool {
+	for _, rule := range rules {
+		if !rule.ruleConditions().isEmpty() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasConditions returns true if the policy or any of its rules have
+// conditions.
+func (p *Policy) HasConditions() bool {
+	pr := &p.PolicyRules
+	return !pr.Conditions.isEmpty() ||
+		hasConditions(pr.Agents) || hasConditions(pr.AgentPrefixes) ||
+		hasConditions(pr.Keys) || hasConditions(pr.KeyPrefixes) ||
+		hasConditions(pr.Nodes) || hasConditions(pr.NodePrefixes) ||
+		hasConditions(pr.Services) || hasConditions(pr.ServicePrefixes) ||
+		hasConditions(pr.Sessions) || hasConditions(pr.SessionPrefixes) ||
+		hasConditions(pr.Events) || hasConditions(pr.EventPrefixes) ||
+		hasConditions(pr.PreparedQueries) || hasConditions(pr.PreparedQueryPrefixes)
+}
+
+// ForRequest returns the policy made of only the rules whose conditions the
+// request meets, along with all of its deny rules. Policies without conditions
+// are returned as is.
+//
+// A '1' or '0' is appended to key for every condition evaluated, depending on
+// whether it was met, so that the key identifies which of the policy's rules
+// apply to the request.
+func (p *Policy) ForRequest(req *RequestContext, key *strings.Builder) *Policy {
+	if !p.HasConditions() {
+		return p
+	}
+
+	pr := p.PolicyRules
+	policyMet := pr.Conditions.met(req)
+	if !pr.Conditions.isEmpty() {
+		if policyMet {
+			key.WriteByte('1')
+		} else {
+			key.WriteByte('0')
+			pr.ACL = denyOnly(pr.ACL)
+			pr.Keyring = denyOnly(pr.Keyring)
+			pr.Operator = denyOnly(pr.Operator)
+			pr.Mesh = denyOnly(pr.Mesh)
+			pr.Peering = denyOnly(pr.Peering)
+		}
+	}
+
+	pr.Agents = rulesForRequest(pr.Agents, req, policyMet, key)
+	pr.AgentPrefixes = rulesForRequest(pr.AgentPrefixes, req, policyMet, key)
+	pr.Keys = rulesForRequest(pr.Keys, req, policyMet, key)
+	pr.KeyPrefixes = rulesForRequest(pr.KeyPrefixes, req, policyMet, key)
+	pr.Nodes = rulesForRequest(pr.Nodes, req, policyMet, key)
+	pr.NodePrefixes = rulesForRequest(pr.NodePrefixes, req, policyMet, key)
+	pr.Services = rulesForRequest(pr.Services, req, policyMet, key)
+	pr.ServicePrefixes = rulesForRequest(pr.ServicePrefixes, req, policyMet, key)
+	pr.Sessions = rulesForRequest(pr.Sessions, req, policyMet, key)
+	pr.SessionPrefixes = rulesForRequest(pr.SessionPrefixes, req, policyMet, key)
+	pr.Events = rulesForRequest(pr.Events, req, policyMet, key)
+	pr.EventPrefixes = rulesForRequest(pr.EventPrefixes, req, policyMet, key)
+	pr.PreparedQueries = rulesForRequest(pr.PreparedQueries, req, policyMet, key)
+	pr.PreparedQueryPrefixes = rulesForRequest(pr.PreparedQueryPrefixes, req, policyMet, key)
+
+	if !policyMet {
+		return &Policy{PolicyRules: pr}
+	}
+	return &Policy{
+		PolicyRules:           pr,
+		EnterprisePolicyRules: p.EnterprisePolicyRules,
+	}
+}
+
+// NextConditionChange returns the first time after the request's time at which
+// a valid_between window of the policy or its rules starts or ends, which may
+// change the rules that apply. It returns the zero time if there is none.
+//
+// An Authorizer only reflects the rules that applied when it was resolved, so
+// this is when long-lived uses of it, like streaming subscriptions, need to
+// resolve the token again.
+func (p *Policy) NextConditionChange(req *RequestContext) time.Time {
+	now := time.Now()
+	if req != nil && !req.Time.IsZero() {
+		now = req.Time
+	}
+
+	pr := &p.PolicyRules
+	next := pr.Conditions.nextChange(now, time.Time{})
+	next = nextRuleChange(pr.Agents, now, next)
+	next = nextRuleChange(pr.AgentPrefixes, now, next)
+	next = nextRuleChange(pr.Keys, now, next)
+	next = nextRuleChange(pr.KeyPrefixes, now, next)
+	next = nextRuleChange(pr.Nodes, now, next)
+	next = nextRuleChange(pr.NodePrefixes, now, next)
+	next = nextRuleChange(pr.Services, now, next)
+	next = nextRuleChange(pr.ServicePrefixes, now, next)
+	next = nextRuleChange(pr.Sessions, now, next)
+	next = nextRuleChange(pr.SessionPrefixes, now, next)
+	next = nextRuleChange(pr.Events, now, next)
+	next = nextRuleChange(pr.EventPrefixes, now, next)
+	next = nextRuleChange(pr.PreparedQueries, now, next)
+	next = nextRuleChange(pr.PreparedQueryPrefixes, now, next)
+	return next
+}
+
+// nextRuleChange returns the earlier of next and the first start or end of the
+// rules' valid_between windows after now.
+func nextRuleChange[R conditionalRule](rules []R, now, next time.Time) time.Time {
+	for _, rule := range rules {
+		next = rule.ruleConditions().nextChange(now, next)
+	}
+	return next
+}
+
+// nextChange returns the earlier of next and the start or end of the
+// valid_between window, whichever is first after now. A zero next is later
+// than any time.
+func (c *RuleConditions) nextChange(now, next time.Time) time.Time {
+	if c == nil || len(c.ValidBetween) == 0 {
+		return next
+	}
+	start, end, err := c.window()
+	if err != nil {
+		return next
+	}
+	for _, t := range []time.Time{start, end} {
+		if t.After(now) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	return next
+}
+
+// denyOnly returns policy if it is a deny, and no policy otherwise.
+func denyOnly(policy string) string {
+	if policy == PolicyDeny {
+		return policy
+	}
+	return ""
+}
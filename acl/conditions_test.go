@@ -0,0 +1,279 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package acl
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleConditions_met(t *testing.T) {
+	window := []string{"2026-01-01T00:00:00Z", "2026-01-02T00:00:00Z"}
+	during := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	before := time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC)
+	end := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		conditions RuleConditions
+		req        *RequestContext
+		met        bool
+	}{
+		"none": {
+			conditions: RuleConditions{},
+			req:        nil,
+			met:        true,
+		},
+		"window - during": {
+			conditions: RuleConditions{ValidBetween: window},
+			req:        &RequestContext{Time: during},
+			met:        true,
+		},
+		"window - before": {
+			conditions: RuleConditions{ValidBetween: window},
+			req:        &RequestContext{Time: before},
+			met:        false,
+		},
+		"window - at end": {
+			conditions: RuleConditions{ValidBetween: window},
+			req:        &RequestContext{Time: end},
+			met:        false,
+		},
+		"window - no request uses current time": {
+			conditions: RuleConditions{ValidBetween: window},
+			req:        nil,
+			met:        false,
+		},
+		"cidr - inside": {
+			conditions: RuleConditions{SourceCIDRs: []string{"192.168.0.0/16", "10.0.0.0/24"}},
+			req:        &RequestContext{SourceAddr: net.ParseIP("10.0.0.7")},
+			met:        true,
+		},
+		"cidr - outside": {
+			conditions: RuleConditions{SourceCIDRs: []string{"10.0.0.0/24"}},
+			req:        &RequestContext{SourceAddr: net.ParseIP("10.0.1.7")},
+			met:        false,
+		},
+		"cidr - unknown source": {
+			conditions: RuleConditions{SourceCIDRs: []string{"10.0.0.0/24"}},
+			req:        &RequestContext{},
+			met:        false,
+		},
+		"header - present": {
+			conditions: RuleConditions{RequiredHeaders: map[string]string{"x-change-ticket": ""}},
+			req:        &RequestContext{Headers: http.Header{"X-Change-Ticket": {"CHG-1"}}},
+			met:        true,
+		},
+		"header - missing": {
+			conditions: RuleConditions{RequiredHeaders: map[string]string{"X-Change-Ticket": ""}},
+			req:        &RequestContext{Headers: http.Header{}},
+			met:        false,
+		},
+		"header - value": {
+			conditions: RuleConditions{RequiredHeaders: map[string]string{"X-Break-Glass": "yes"}},
+			req:        &RequestContext{Headers: http.Header{"X-Break-Glass": {"yes"}}},
+			met:        true,
+		},
+		"header - wrong value": {
+			conditions: RuleConditions{RequiredHeaders: map[string]string{"X-Break-Glass": "yes"}},
+			req:        &RequestContext{Headers: http.Header{"X-Break-Glass": {"no"}}},
+			met:        false,
+		},
+		"all - met": {
+			conditions: RuleConditions{
+				ValidBetween:    window,
+				SourceCIDRs:     []string{"10.0.0.0/24"},
+				RequiredHeaders: map[string]string{"X-Break-Glass": "yes"},
+			},
+			req: &RequestContext{
+				Time:       during,
+				SourceAddr: net.ParseIP("10.0.0.7"),
+				Headers:    http.Header{"X-Break-Glass": {"yes"}},
+			},
+			met: true,
+		},
+		"all - one unmet": {
+			conditions: RuleConditions{
+				ValidBetween:    window,
+				SourceCIDRs:     []string{"10.0.0.0/24"},
+				RequiredHeaders: map[string]string{"X-Break-Glass": "yes"},
+			},
+			req: &RequestContext{
+				Time:       before,
+				SourceAddr: net.ParseIP("10.0.0.7"),
+				Headers:    http.Header{"X-Break-Glass": {"yes"}},
+			},
+			met: false,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, tc.conditions.validate())
+			require.Equal(t, tc.met, tc.conditions.met(tc.req))
+		})
+	}
+}
+
+func TestPolicy_ForRequest(t *testing.T) {
+	window := `valid_between = ["2026-01-01T00:00:00Z", "2026-01-02T00:00:00Z"]`
+	bastion := &RequestContext{
+		Time:       time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+		SourceAddr: net.ParseIP("10.0.0.7"),
+	}
+	elsewhere := &RequestContext{
+		Time:       time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC),
+		SourceAddr: net.ParseIP("172.16.0.7"),
+	}
+
+	t.Run("no conditions", func(t *testing.T) {
+		p, err := NewPolicyFromSource(`key_prefix "" { policy = "read" }`, nil, nil)
+		require.NoError(t, err)
+		require.False(t, p.HasConditions())
+
+		var key strings.Builder
+		require.Same(t, p, p.ForRequest(elsewhere, &key))
+		require.Empty(t, key.String())
+	})
+
+	t.Run("rule conditions", func(t *testing.T) {
+		p, err := NewPolicyFromSource(`
+			agent_prefix "" {
+				policy = "read"
+			}
+			agent_prefix "web-" {
+				policy = "write"
+				source_cidrs = ["10.0.0.0/24"]
+			}
+			key_prefix "" {
+				policy = "write"
+				`+window+`
+			}`, nil, nil)
+		require.NoError(t, err)
+		require.True(t, p.HasConditions())
+
+		var key strings.Builder
+		filtered := p.ForRequest(bastion, &key)
+		require.Equal(t, "11", key.String())
+		require.Equal(t, p.PolicyRules, filtered.PolicyRules)
+
+		key.Reset()
+		filtered = p.ForRequest(elsewhere, &key)
+		require.Equal(t, "00", key.String())
+		require.Len(t, filtered.AgentPrefixes, 1)
+		require.Equal(t, "", filtered.AgentPrefixes[0].Node)
+		require.Empty(t, filtered.KeyPrefixes)
+
+		// the parsed policy is left untouched
+		require.Len(t, p.AgentPrefixes, 2)
+		require.Len(t, p.KeyPrefixes, 1)
+	})
+
+	t.Run("policy conditions", func(t *testing.T) {
+		p, err := NewPolicyFromSource(`
+			conditions {
+				`+window+`
+			}
+			operator = "write"
+			node_prefix "" {
+				policy = "write"
+			}`, nil, nil)
+		require.NoError(t, err)
+		require.True(t, p.HasConditions())
+
+		var key strings.Builder
+		require.Equal(t, p.PolicyRules, p.ForRequest(bastion, &key).PolicyRules)
+		require.Equal(t, "1", key.String())
+
+		key.Reset()
+		require.Equal(t, &Policy{PolicyRules: PolicyRules{Conditions: p.Conditions}}, p.ForRequest(elsewhere, &key))
+		require.Equal(t, "0", key.String())
+	})
+
+	t.Run("conditional deny", func(t *testing.T) {
+		_, err := NewPolicyFromSource(`
+			key_prefix "" {
+				policy = "write"
+			}
+			key_prefix "secret/" {
+				policy = "deny"
+				`+window+`
+			}`, nil, nil)
+		require.ErrorContains(t, err, "conditions can't be set on deny rules")
+
+		_, err = NewPolicyFromSource(`
+			conditions {
+				`+window+`
+			}
+			key_prefix "" {
+				policy = "write"
+			}
+			key_prefix "secret/" {
+				policy = "deny"
+			}`, nil, nil)
+		require.ErrorContains(t, err, "conditions can't be set on a policy with deny rules")
+	})
+
+	t.Run("conditional deny with nil context", func(t *testing.T) {
+		// Policies created before conditions were rejected on deny rules
+		// still keep their deny rules whether their conditions are met or not.
+		p := &Policy{PolicyRules: PolicyRules{
+			KeyPrefixes: []*KeyRule{
+				{Prefix: "", Policy: PolicyWrite},
+				{
+					Prefix:         "secret/",
+					Policy:         PolicyDeny,
+					RuleConditions: RuleConditions{SourceCIDRs: []string{"172.16.0.0/12"}},
+				},
+			},
+		}}
+
+		for _, req := range []*RequestContext{nil, bastion, elsewhere} {
+			var key strings.Builder
+			authz, err := NewPolicyAuthorizer([]*Policy{p.ForRequest(req, &key)}, nil)
+			require.NoError(t, err)
+			require.Equal(t, Deny, authz.KeyWrite("secret/foo", nil))
+			require.Equal(t, Allow, authz.KeyWrite("foo", nil))
+			require.Empty(t, key.String())
+		}
+	})
+
+	t.Run("policy conditions with nil context keep deny rules", func(t *testing.T) {
+		p := &Policy{PolicyRules: PolicyRules{
+			Conditions: &RuleConditions{SourceCIDRs: []string{"10.0.0.0/24"}},
+			Operator:   PolicyDeny,
+			ACL:        PolicyWrite,
+			KeyPrefixes: []*KeyRule{
+				{Prefix: "", Policy: PolicyWrite},
+				{Prefix: "secret/", Policy: PolicyDeny},
+			},
+		}}
+
+		var key strings.Builder
+		authz, err := NewPolicyAuthorizer([]*Policy{p.ForRequest(nil, &key)}, nil)
+		require.NoError(t, err)
+		require.Equal(t, "0", key.String())
+		require.Equal(t, Deny, authz.OperatorRead(nil))
+		require.Equal(t, Deny, authz.KeyWrite("secret/foo", nil))
+		require.Equal(t, Default, authz.KeyWrite("foo", nil))
+		require.Equal(t, Default, authz.ACLWrite(nil))
+	})
+
+	t.Run("request conditions outside agent rules", func(t *testing.T) {
+		_, err := NewPolicyFromSource(`key_prefix "" { policy = "write" source_cidrs = ["10.0.0.0/24"] }`, nil, nil)
+		require.ErrorContains(t, err, "can only be set on agent and agent_prefix rules")
+
+		_, err = NewPolicyFromSource(`
+			conditions {
+				required_headers = { X-Break-Glass = "yes" }
+			}
+			agent_prefix "" {
+				policy = "write"
+			}`, nil, nil)
+		require.ErrorContains(t, err, "can only be set on agent and agent_prefix rules")
+	})
+}
@@ -76,6 +76,9 @@ type PolicyRules struct {
 	Mesh                  string               `hcl:"mesh"`
 	Peering               string               `hcl:"peering"`
 
+	// Conditions, if set, restrict when all of the policy's rules apply.
+	Conditions *RuleConditions `hcl:"conditions"`
+
 	// Deprecated: exists just to track the former field for decoding
 	Identities []*IdentityRule `hcl:"identity,expand"`
 	// Deprecated: exists just to track the former field for decoding
@@ -93,6 +96,8 @@ type Policy struct {
 type AgentRule struct {
 	Node   string `hcl:",key"`
 	Policy string
+
+	RuleConditions `hcl:",squash"`
 }
 
 // IdentityRule represents a policy for a workload identity
@@ -115,6 +120,7 @@ type KeyRule struct {
 	Prefix string `hcl:",key"`
 	Policy string
 
+	RuleConditions `hcl:",squash"`
 	EnterpriseRule `hcl:",squash"`
 }
 
@@ -123,6 +129,7 @@ type NodeRule struct {
 	Name   string `hcl:",key"`
 	Policy string
 
+	RuleConditions `hcl:",squash"`
 	EnterpriseRule `hcl:",squash"`
 }
 
@@ -136,6 +143,7 @@ type ServiceRule struct {
 	// the intentions policy.
 	Intentions string
 
+	RuleConditions `hcl:",squash"`
 	EnterpriseRule `hcl:",squash"`
 }
 
@@ -144,18 +152,24 @@ type ServiceRule struct {
 type SessionRule struct {
 	Node   string `hcl:",key"`
 	Policy string
+
+	RuleConditions `hcl:",squash"`
 }
 
 // EventRule represents a user event rule.
 type EventRule struct {
 	Event  string `hcl:",key"`
 	Policy string
+
+	RuleConditions `hcl:",squash"`
 }
 
 // PreparedQueryRule represents a prepared query rule.
 type PreparedQueryRule struct {
 	Prefix string `hcl:",key"`
 	Policy string
+
+	RuleConditions `hcl:",squash"`
 }
 
 // isPolicyValid makes sure the given string matches one of the valid policies.
@@ -171,6 +185,10 @@ func isPolicyValid(policy string, allowList bool) bool {
 }
 
 func (pr *PolicyRules) Validate(conf *Config) error {
+	if err := pr.validateConditions(); err != nil {
+		return err
+	}
+
 	// Validate the acl policy - this one is allowed to be empty
 	if pr.ACL != "" && !isPolicyValid(pr.ACL, false) {
 		return fmt.Errorf("Invalid acl policy: %#v", pr.ACL)
@@ -480,6 +480,117 @@ func TestPolicySourceParse(t *testing.T) {
 			RulesJSON: `{ "peering": "" }`,
 			Expected:  &Policy{PolicyRules: PolicyRules{Peering: ""}},
 		},
+		{
+			Name: "Conditions",
+			Rules: `
+				conditions {
+					valid_between = ["2026-01-01T00:00:00Z", "2026-01-02T00:00:00Z"]
+				}
+				agent_prefix "" {
+					policy = "write"
+					source_cidrs = ["10.0.0.0/24"]
+					required_headers = {
+						X-Change-Ticket = ""
+					}
+				}
+				service "web" {
+					policy = "read"
+				}`,
+			RulesJSON: `{
+				"conditions": {
+					"valid_between": ["2026-01-01T00:00:00Z", "2026-01-02T00:00:00Z"]
+				},
+				"agent_prefix": {
+					"": {
+						"policy": "write",
+						"source_cidrs": ["10.0.0.0/24"],
+						"required_headers": {
+							"X-Change-Ticket": ""
+						}
+					}
+				},
+				"service": {
+					"web": {
+						"policy": "read"
+					}
+				}
+			}`,
+			Expected: &Policy{PolicyRules: PolicyRules{
+				Conditions: &RuleConditions{
+					ValidBetween: []string{"2026-01-01T00:00:00Z", "2026-01-02T00:00:00Z"},
+				},
+				AgentPrefixes: []*AgentRule{
+					{
+						Node:   "",
+						Policy: PolicyWrite,
+						RuleConditions: RuleConditions{
+							SourceCIDRs:     []string{"10.0.0.0/24"},
+							RequiredHeaders: map[string]string{"X-Change-Ticket": ""},
+						},
+					},
+				},
+				Services: []*ServiceRule{
+					{
+						Name:   "web",
+						Policy: PolicyRead,
+					},
+				},
+			}},
+		},
+		{
+			Name:      "Bad Conditions - Policy Window",
+			Rules:     `conditions { valid_between = ["2026-01-02T00:00:00Z", "2026-01-01T00:00:00Z"] }`,
+			RulesJSON: `{ "conditions": { "valid_between": ["2026-01-02T00:00:00Z", "2026-01-01T00:00:00Z"] }}`,
+			Err:       "Invalid policy conditions",
+		},
+		{
+			Name:      "Bad Conditions - Window Length",
+			Rules:     `node "foo" { policy = "read" valid_between = ["2026-01-01T00:00:00Z"] }`,
+			RulesJSON: `{ "node": { "foo": { "policy": "read", "valid_between": ["2026-01-01T00:00:00Z"] }}}`,
+			Err:       "Invalid node conditions",
+		},
+		{
+			Name:      "Bad Conditions - Window Time",
+			Rules:     `session_prefix "" { policy = "read" valid_between = ["tomorrow", "2026-01-01T00:00:00Z"] }`,
+			RulesJSON: `{ "session_prefix": { "": { "policy": "read", "valid_between": ["tomorrow", "2026-01-01T00:00:00Z"] }}}`,
+			Err:       "Invalid session_prefix conditions",
+		},
+		{
+			Name:      "Bad Conditions - CIDR",
+			Rules:     `agent "foo" { policy = "read" source_cidrs = ["10.0.0.1"] }`,
+			RulesJSON: `{ "agent": { "foo": { "policy": "read", "source_cidrs": ["10.0.0.1"] }}}`,
+			Err:       "Invalid agent conditions",
+		},
+		{
+			Name:      "Bad Conditions - Header",
+			Rules:     `agent "foo" { policy = "read" required_headers = { "" = "yes" } }`,
+			RulesJSON: `{ "agent": { "foo": { "policy": "read", "required_headers": { "": "yes" } }}}`,
+			Err:       "Invalid agent conditions",
+		},
+		{
+			Name:      "Bad Conditions - Request Conditions Outside Agent Rules",
+			Rules:     `query "foo" { policy = "read" required_headers = { "X-Break-Glass" = "yes" } }`,
+			RulesJSON: `{ "query": { "foo": { "policy": "read", "required_headers": { "X-Break-Glass": "yes" } }}}`,
+			Err:       "Invalid query conditions",
+		},
+		{
+			Name:      "Bad Conditions - Policy Request Conditions",
+			Rules:     `conditions { source_cidrs = ["10.0.0.0/24"] } agent "foo" { policy = "read" }`,
+			RulesJSON: `{ "conditions": { "source_cidrs": ["10.0.0.0/24"] }, "agent": { "foo": { "policy": "read" }}}`,
+			Err:       "Invalid policy conditions",
+		},
+		{
+			Name:      "Bad Conditions - Deny Rule",
+			Rules:     `service "foo" { policy = "read" intentions = "deny" valid_between = ["2026-01-01T00:00:00Z", "2026-01-02T00:00:00Z"] }`,
+			RulesJSON: `{ "service": { "foo": { "policy": "read", "intentions": "deny", "valid_between": ["2026-01-01T00:00:00Z", "2026-01-02T00:00:00Z"] }}}`,
+			Err:       "Invalid service conditions",
+		},
+		{
+			Name:      "Bad Conditions - Policy With Deny",
+			Rules:     `conditions { valid_between = ["2026-01-01T00:00:00Z", "2026-01-02T00:00:00Z"] } operator = "deny"`,
+			RulesJSON: `{ "conditions": { "valid_between": ["2026-01-01T00:00:00Z", "2026-01-02T00:00:00Z"] }, "operator": "deny" }`,
+			Err:       "Invalid policy conditions: conditions can't be set on a policy with deny rules",
+		},
 	}
 
 	for _, tc := range cases {
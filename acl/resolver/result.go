@@ -4,6 +4,8 @@
 package resolver
 
 import (
+	"time"
+
 	"github.com/hashicorp/consul/acl"
 	"github.com/hashicorp/consul/agent/structs"
 )
@@ -12,6 +14,11 @@ type Result struct {
 	acl.Authorizer
 	// TODO: likely we can reduce this interface
 	ACLIdentity structs.ACLIdentity
+
+	// ValidUntil is when the time windows of the token's policy conditions
+	// next change which rules apply, so the Authorizer must not be used past
+	// it. It is the zero time if the Authorizer doesn't expire.
+	ValidUntil time.Time
 }
 
 func (a Result) AccessorID() string {
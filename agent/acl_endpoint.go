@@ -1113,7 +1113,7 @@ func (s *HTTPHandlers) ACLAuthorize(resp http.ResponseWriter, req *http.Request)
 			return nil, err
 		}
 	} else {
-		authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(request.Token, nil, nil)
+		authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(request.Token, nil, requestAuthzContext(req))
 		if err != nil {
 			return nil, err
 		}
@@ -1145,7 +1145,7 @@ func (s *HTTPHandlers) ACLTemplatedPoliciesList(resp http.ResponseWriter, req *h
 	}
 
 	s.defaultMetaPartitionToAgent(&entMeta)
-	var authzContext acl.AuthorizerContext
+	authzContext := acl.AuthorizerContext{Request: requestContext(req)}
 	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &entMeta, &authzContext)
 	if err != nil {
 		return nil, err
@@ -1189,7 +1189,7 @@ func (s *HTTPHandlers) ACLTemplatedPolicyRead(resp http.ResponseWriter, req *htt
 	}
 
 	s.defaultMetaPartitionToAgent(&entMeta)
-	var authzContext acl.AuthorizerContext
+	authzContext := acl.AuthorizerContext{Request: requestContext(req)}
 	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &entMeta, &authzContext)
 	if err != nil {
 		return nil, err
@@ -1232,7 +1232,7 @@ func (s *HTTPHandlers) ACLTemplatedPolicyPreview(resp http.ResponseWriter, req *
 	}
 
 	s.defaultMetaPartitionToAgent(&entMeta)
-	var authzContext acl.AuthorizerContext
+	authzContext := acl.AuthorizerContext{Request: requestContext(req)}
 	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &entMeta, &authzContext)
 	if err != nil {
 		return nil, err
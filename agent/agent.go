@@ -60,6 +60,7 @@ import (
 	"github.com/hashicorp/consul/agent/rpcclient"
 	"github.com/hashicorp/consul/agent/rpcclient/configentry"
 	"github.com/hashicorp/consul/agent/rpcclient/health"
+	"github.com/hashicorp/consul/agent/rpcclient/kv"
 	"github.com/hashicorp/consul/agent/structs"
 	"github.com/hashicorp/consul/agent/systemd"
 	"github.com/hashicorp/consul/agent/token"
@@ -291,6 +292,12 @@ type Agent struct {
 	// checkUDPs maps the check ID to an associated UDP check
 	checkUDPs map[structs.CheckID]*checks.CheckUDP
 
+	// checkTLSs maps the check ID to an associated TLS check
+	checkTLSs map[structs.CheckID]*checks.CheckTLS
+
+	// checkDNSs maps the check ID to an associated DNS check
+	checkDNSs map[structs.CheckID]*checks.CheckDNS
+
 	// checkGRPCs maps the check ID to an associated GRPC check
 	checkGRPCs map[structs.CheckID]*checks.CheckGRPC
 
@@ -350,6 +357,18 @@ type Agent struct {
 	// dnsServer provides the DNS API
 	dnsServers []dnsServer
 
+	// dnsQueryServer answers the DNS queries that are not received by a DNS
+	// listener, such as the ones forwarded over gRPC or sent over HTTPS.
+	dnsQueryServer *DNSServer
+
+	// dnsRecursor forwards the DNS queries outside of the Consul domain to
+	// the recursors, it is shared by all the DNS servers.
+	dnsRecursor *dnsRecursor
+
+	// dnsZoneHistory keeps the last versions of the zones of the Consul
+	// domain served by zone transfers, it is shared by all the DNS servers.
+	dnsZoneHistory *dnsZoneHistory
+
 	// apiServers listening for connections. If any of these server goroutines
 	// fail, the agent will be shutdown.
 	apiServers *apiServers
@@ -416,6 +435,7 @@ type Agent struct {
 	// TODO: pass directly to HTTPHandlers and dnsServer once those are passed
 	// into Agent, which will allow us to remove this field.
 	rpcClientHealth       *health.Client
+	rpcClientKV           *kv.Client
 	rpcClientConfigEntry  *configentry.Client
 	grpcClientConfigEntry pbconfigentry.ConfigEntryServiceClient
 
@@ -470,6 +490,8 @@ func New(bd BaseDeps) (*Agent, error) {
 		checkH2PINGs:    make(map[structs.CheckID]*checks.CheckH2PING),
 		checkTCPs:       make(map[structs.CheckID]*checks.CheckTCP),
 		checkUDPs:       make(map[structs.CheckID]*checks.CheckUDP),
+		checkTLSs:       make(map[structs.CheckID]*checks.CheckTLS),
+		checkDNSs:       make(map[structs.CheckID]*checks.CheckDNS),
 		checkGRPCs:      make(map[structs.CheckID]*checks.CheckGRPC),
 		checkDockers:    make(map[structs.CheckID]*checks.CheckDocker),
 		checkAliases:    make(map[structs.CheckID]*checks.CheckAlias),
@@ -490,6 +512,8 @@ func New(bd BaseDeps) (*Agent, error) {
 		cache:           bd.Cache,
 		leafCertManager: bd.LeafCertManager,
 		routineManager:  routine.NewManager(bd.Logger),
+		dnsRecursor:     newDNSRecursor(bd.Logger.Named(logging.DNS)),
+		dnsZoneHistory:  newDNSZoneHistory(),
 	}
 
 	// TODO: create rpcClientHealth in BaseDeps once NetRPC is available without Agent
@@ -513,6 +537,19 @@ func New(bd BaseDeps) (*Agent, error) {
 		},
 	}
 
+	a.rpcClientKV = &kv.Client{
+		Client: rpcclient.Client{
+			NetRPC:    &a,
+			ViewStore: bd.ViewStore,
+			MaterializerDeps: rpcclient.MaterializerDeps{
+				Conn:   conn,
+				Logger: bd.Logger.Named("rpcclient.kv"),
+			},
+			UseStreamingBackend: a.config.UseStreamingBackend,
+			QueryOptionDefaults: config.ApplyDefaultQueryOptions(a.config),
+		},
+	}
+
 	a.rpcClientPeering = pbpeering.NewPeeringServiceClient(conn)
 	a.rpcClientOperator = pboperator.NewOperatorServiceClient(conn)
 	a.grpcClientConfigEntry = pbconfigentry.NewConfigEntryServiceClient(conn)
@@ -594,6 +631,11 @@ func LocalConfig(cfg *config.RuntimeConfig) local.Config {
 		NodeLocality:        cfg.StructLocality(),
 		Partition:           cfg.PartitionOrDefault(),
 		TaggedAddresses:     map[string]string{},
+
+		CheckFlapDetection:     cfg.CheckFlapDetectionEnabled,
+		CheckFlapHistorySize:   cfg.CheckFlapDetectionHistorySize,
+		CheckFlapLowThreshold:  cfg.CheckFlapDetectionLowThreshold,
+		CheckFlapHighThreshold: cfg.CheckFlapDetectionHighThreshold,
 	}
 	for k, v := range cfg.TaggedAddresses {
 		lc.TaggedAddresses[k] = v
@@ -1054,9 +1096,14 @@ func (a *Agent) listenAndServeGRPC(server *consul.Server) error {
 }
 
 func (a *Agent) listenAndServeDNS() error {
-	notif := make(chan net.Addr, len(a.config.DNSAddrs))
-	errCh := make(chan error, len(a.config.DNSAddrs))
-	for _, addr := range a.config.DNSAddrs {
+	type started struct {
+		network string
+		addr    net.Addr
+	}
+	numServers := len(a.config.DNSAddrs) + len(a.config.DNSTLSAddrs)
+	notif := make(chan started, numServers)
+	errCh := make(chan error, numServers)
+	start := func(network string, addr net.Addr) error {
 		// create server
 		s, err := NewDNSServer(a)
 		if err != nil {
@@ -1066,13 +1113,24 @@ func (a *Agent) listenAndServeDNS() error {
 
 		// start server
 		a.wgServers.Add(1)
-		go func(addr net.Addr) {
+		go func() {
 			defer a.wgServers.Done()
-			err := s.ListenAndServe(addr.Network(), addr.String(), func() { notif <- addr })
+			err := s.ListenAndServe(network, addr.String(), func() { notif <- started{network, addr} })
 			if err != nil && !strings.Contains(err.Error(), "accept") {
 				errCh <- err
 			}
-		}(addr)
+		}()
+		return nil
+	}
+	for _, addr := range a.config.DNSAddrs {
+		if err := start(addr.Network(), addr); err != nil {
+			return err
+		}
+	}
+	for _, addr := range a.config.DNSTLSAddrs {
+		if err := start("tcp-tls", addr); err != nil {
+			return err
+		}
 	}
 	s, _ := NewDNSServer(a)
 
@@ -1082,17 +1140,20 @@ func (a *Agent) listenAndServeDNS() error {
 		LocalAddr:   grpcDNS.LocalAddr{IP: net.IPv4(127, 0, 0, 1), Port: a.config.GRPCPort},
 	}).Register(a.externalGRPCServer)
 
+	// The same server answers DNS over HTTPS queries received by the HTTP
+	// handlers.
+	a.dnsQueryServer = s
 	a.dnsServers = append(a.dnsServers, s)
 
 	// wait for servers to be up
 	timeout := time.After(time.Second)
 	var merr *multierror.Error
-	for range a.config.DNSAddrs {
+	for range numServers {
 		select {
-		case addr := <-notif:
+		case srv := <-notif:
 			a.logger.Info("Started DNS server",
-				"address", addr.String(),
-				"network", addr.Network(),
+				"address", srv.addr.String(),
+				"network", srv.network,
 			)
 
 		case err := <-errCh:
@@ -1783,6 +1844,12 @@ func (a *Agent) ShutdownAgent() error {
 	for _, chk := range a.checkUDPs {
 		chk.Stop()
 	}
+	for _, chk := range a.checkTLSs {
+		chk.Stop()
+	}
+	for _, chk := range a.checkDNSs {
+		chk.Stop()
+	}
 	for _, chk := range a.checkGRPCs {
 		chk.Stop()
 	}
@@ -1812,6 +1879,7 @@ func (a *Agent) ShutdownAgent() error {
 	}
 
 	a.rpcClientHealth.Close()
+	a.rpcClientKV.Close()
 	a.rpcClientConfigEntry.Close()
 
 	var err error
@@ -2999,6 +3067,15 @@ func (a *Agent) addCheck(check *structs.HealthCheck, chkType *structs.CheckType,
 		check.EnterpriseMeta = service.EnterpriseMeta
 	}
 
+	if chkType != nil {
+		for _, dep := range chkType.DependsOn {
+			if dep == check.CheckID {
+				return fmt.Errorf("Check is not valid: check %q can't depend on itself", check.CheckID)
+			}
+		}
+		check.DependsOn = chkType.DependsOn
+	}
+
 	// Check if already registered
 	if chkType != nil {
 		maxOutputSize := a.config.CheckOutputMaxSize
@@ -3084,6 +3161,7 @@ func (a *Agent) addCheck(check *structs.HealthCheck, chkType *structs.CheckType,
 				Method:           chkType.Method,
 				Body:             chkType.Body,
 				DisableRedirects: chkType.DisableRedirects,
+				Assertions:       chkType.HTTPAssertions,
 				Interval:         chkType.Interval,
 				Timeout:          chkType.Timeout,
 				Logger:           a.logger,
@@ -3164,6 +3242,64 @@ func (a *Agent) addCheck(check *structs.HealthCheck, chkType *structs.CheckType,
 			udp.Start()
 			a.checkUDPs[cid] = udp
 
+		case chkType.IsTLS():
+			if existing, ok := a.checkTLSs[cid]; ok {
+				existing.Stop()
+				delete(a.checkTLSs, cid)
+			}
+			if chkType.Interval < checks.MinInterval {
+				a.logger.Warn("check has interval below minimum",
+					"check", cid.String(),
+					"minimum_interval", checks.MinInterval,
+				)
+				chkType.Interval = checks.MinInterval
+			}
+
+			tlsCheck := &checks.CheckTLS{
+				CheckID:         cid,
+				ServiceID:       sid,
+				TLS:             chkType.TLS,
+				WarningDays:     chkType.TLSExpiryWarningDays,
+				CriticalDays:    chkType.TLSExpiryCriticalDays,
+				Interval:        chkType.Interval,
+				Timeout:         chkType.Timeout,
+				Logger:          a.logger,
+				TLSClientConfig: a.tlsConfigurator.OutgoingTLSConfigForCheck(chkType.TLSSkipVerify, chkType.TLSServerName),
+				StatusHandler:   statusHandler,
+			}
+			tlsCheck.Start()
+			a.checkTLSs[cid] = tlsCheck
+
+		case chkType.IsDNS():
+			if existing, ok := a.checkDNSs[cid]; ok {
+				existing.Stop()
+				delete(a.checkDNSs, cid)
+			}
+			if chkType.Interval < checks.MinInterval {
+				a.logger.Warn("check has interval below minimum",
+					"check", cid.String(),
+					"minimum_interval", checks.MinInterval,
+				)
+				chkType.Interval = checks.MinInterval
+			}
+
+			dnsCheck := &checks.CheckDNS{
+				CheckID:        cid,
+				ServiceID:      sid,
+				DNS:            chkType.DNS,
+				QueryType:      chkType.DNSQueryType,
+				Resolver:       chkType.DNSResolver,
+				ExpectedValues: chkType.DNSExpectedValues,
+				Rcode:          chkType.DNSRcode,
+				MaxLatency:     chkType.DNSMaxLatency,
+				Interval:       chkType.Interval,
+				Timeout:        chkType.Timeout,
+				Logger:         a.logger,
+				StatusHandler:  statusHandler,
+			}
+			dnsCheck.Start()
+			a.checkDNSs[cid] = dnsCheck
+
 		case chkType.IsGRPC():
 			if existing, ok := a.checkGRPCs[cid]; ok {
 				existing.Stop()
@@ -3500,6 +3636,14 @@ func (a *Agent) cancelCheckMonitors(checkID structs.CheckID) {
 		check.Stop()
 		delete(a.checkUDPs, checkID)
 	}
+	if check, ok := a.checkTLSs[checkID]; ok {
+		check.Stop()
+		delete(a.checkTLSs, checkID)
+	}
+	if check, ok := a.checkDNSs[checkID]; ok {
+		check.Stop()
+		delete(a.checkDNSs, checkID)
+	}
 	if check, ok := a.checkGRPCs[checkID]; ok {
 		check.Stop()
 		delete(a.checkGRPCs, checkID)
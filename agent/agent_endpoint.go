@@ -73,7 +73,7 @@ func (s *HTTPHandlers) AgentSelf(resp http.ResponseWriter, req *http.Request) (i
 	// Fetch the ACL token, if any, and enforce agent policy.
 	var token string
 	s.parseToken(req, &token)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -185,7 +185,7 @@ func (s *HTTPHandlers) AgentMetrics(resp http.ResponseWriter, req *http.Request)
 	// Fetch the ACL token, if any, and enforce agent policy.
 	var token string
 	s.parseToken(req, &token)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +222,7 @@ func (s *HTTPHandlers) AgentMetricsStream(resp http.ResponseWriter, req *http.Re
 	// Fetch the ACL token, if any, and enforce agent policy.
 	var token string
 	s.parseToken(req, &token)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -275,7 +275,7 @@ func (s *HTTPHandlers) AgentReload(resp http.ResponseWriter, req *http.Request)
 	// Fetch the ACL token, if any, and enforce agent policy.
 	var token string
 	s.parseToken(req, &token)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -379,7 +379,7 @@ func (s *HTTPHandlers) AgentServices(resp http.ResponseWriter, req *http.Request
 	s.parseFilter(req, &filterExpression)
 
 	s.defaultMetaPartitionToAgent(&entMeta)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &entMeta, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &entMeta, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -468,7 +468,7 @@ func (s *HTTPHandlers) AgentService(resp http.ResponseWriter, req *http.Request)
 
 	// need to resolve to default the meta
 	s.defaultMetaPartitionToAgent(&entMeta)
-	_, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &entMeta, nil)
+	_, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &entMeta, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -499,7 +499,7 @@ func (s *HTTPHandlers) AgentService(resp http.ResponseWriter, req *http.Request)
 			ws.Add(svcState.WatchCh)
 
 			// Check ACLs.
-			authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+			authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, requestAuthzContext(req))
 			if err != nil {
 				return "", nil, err
 			}
@@ -542,7 +542,7 @@ func (s *HTTPHandlers) AgentChecks(resp http.ResponseWriter, req *http.Request)
 	}
 
 	s.defaultMetaPartitionToAgent(&entMeta)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &entMeta, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &entMeta, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -706,7 +706,7 @@ func (s *HTTPHandlers) AgentJoin(resp http.ResponseWriter, req *http.Request) (i
 	// Fetch the ACL token, if any, and enforce agent policy.
 	var token string
 	s.parseToken(req, &token)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -749,7 +749,7 @@ func (s *HTTPHandlers) AgentLeave(resp http.ResponseWriter, req *http.Request) (
 	// Fetch the ACL token, if any, and enforce agent policy.
 	var token string
 	s.parseToken(req, &token)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -771,7 +771,7 @@ func (s *HTTPHandlers) AgentForceLeave(resp http.ResponseWriter, req *http.Reque
 	// Fetch the ACL token, if any, and enforce agent policy.
 	var token string
 	s.parseToken(req, &token)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -837,7 +837,7 @@ func (s *HTTPHandlers) AgentRegisterCheck(resp http.ResponseWriter, req *http.Re
 	}
 
 	s.defaultMetaPartitionToAgent(&args.EnterpriseMeta)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &args.EnterpriseMeta, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &args.EnterpriseMeta, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -897,7 +897,7 @@ func (s *HTTPHandlers) AgentDeregisterCheck(resp http.ResponseWriter, req *http.
 		return nil, err
 	}
 
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &checkID.EnterpriseMeta, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &checkID.EnterpriseMeta, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -1009,7 +1009,7 @@ func (s *HTTPHandlers) agentCheckUpdate(resp http.ResponseWriter, req *http.Requ
 		return nil, err
 	}
 
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &cid.EnterpriseMeta, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &cid.EnterpriseMeta, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -1048,6 +1048,11 @@ func agentHealthService(serviceID structs.ServiceID, s *HTTPHandlers) (int, stri
 			ServiceName: c.ServiceName,
 			ServiceTags: c.ServiceTags,
 		}
+		for _, dep := range c.DependsOn {
+			healthCheck.DependsOn = append(healthCheck.DependsOn, string(dep))
+		}
+		healthCheck.SuppressedBy = string(c.SuppressedBy)
+		healthCheck.Flapping = c.Flapping
 		fillHealthCheckEnterpriseMeta(healthCheck, &c.EnterpriseMeta)
 		serviceChecks = append(serviceChecks, healthCheck)
 	}
@@ -1090,7 +1095,7 @@ func (s *HTTPHandlers) AgentHealthServiceByID(resp http.ResponseWriter, req *htt
 
 	// need to resolve to default the meta
 	s.defaultMetaPartitionToAgent(&entMeta)
-	var authzContext acl.AuthorizerContext
+	authzContext := acl.AuthorizerContext{Request: requestContext(req)}
 	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &entMeta, &authzContext)
 	if err != nil {
 		return nil, err
@@ -1149,7 +1154,7 @@ func (s *HTTPHandlers) AgentHealthServiceByName(resp http.ResponseWriter, req *h
 
 	s.defaultMetaPartitionToAgent(&entMeta)
 	// need to resolve to default the meta
-	var authzContext acl.AuthorizerContext
+	authzContext := acl.AuthorizerContext{Request: requestContext(req)}
 	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &entMeta, &authzContext)
 	if err != nil {
 		return nil, err
@@ -1231,7 +1236,7 @@ func (s *HTTPHandlers) AgentRegisterService(resp http.ResponseWriter, req *http.
 	s.parseToken(req, &token)
 
 	s.defaultMetaPartitionToAgent(&args.EnterpriseMeta)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &args.EnterpriseMeta, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &args.EnterpriseMeta, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -1367,7 +1372,7 @@ func (s *HTTPHandlers) AgentDeregisterService(resp http.ResponseWriter, req *htt
 		return nil, err
 	}
 
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &sid.EnterpriseMeta, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &sid.EnterpriseMeta, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -1420,7 +1425,7 @@ func (s *HTTPHandlers) AgentServiceMaintenance(resp http.ResponseWriter, req *ht
 		return nil, err
 	}
 
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &sid.EnterpriseMeta, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &sid.EnterpriseMeta, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -1466,7 +1471,7 @@ func (s *HTTPHandlers) AgentNodeMaintenance(resp http.ResponseWriter, req *http.
 	var token string
 	s.parseToken(req, &token)
 
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -1490,7 +1495,7 @@ func (s *HTTPHandlers) AgentMonitor(resp http.ResponseWriter, req *http.Request)
 	// Fetch the ACL token, if any, and enforce agent policy.
 	var token string
 	s.parseToken(req, &token)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -1571,7 +1576,7 @@ func (s *HTTPHandlers) AgentToken(resp http.ResponseWriter, req *http.Request) (
 	// Fetch the ACL token, if any, and enforce agent policy.
 	var token string
 	s.parseToken(req, &token)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -1780,7 +1785,7 @@ func (s *HTTPHandlers) AgentConnectAuthorize(resp http.ResponseWriter, req *http
 	// We need to verify service:write permissions for the given token.
 	// We do this manually here since the RPC request below only verifies
 	// service:read.
-	var authzContext acl.AuthorizerContext
+	authzContext := acl.AuthorizerContext{Request: requestContext(req)}
 	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, &authReq.EnterpriseMeta, &authzContext)
 	if err != nil {
 		return nil, fmt.Errorf("Could not resolve token to authorizer: %w", err)
@@ -1904,7 +1909,7 @@ func (s *HTTPHandlers) AgentHost(resp http.ResponseWriter, req *http.Request) (i
 	// Fetch the ACL token, if any, and enforce agent policy.
 	var token string
 	s.parseToken(req, &token)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -1933,7 +1938,7 @@ func (s *HTTPHandlers) AgentVersion(resp http.ResponseWriter, req *http.Request)
 func (s *HTTPHandlers) InternalRPCMethods(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	var token string
 	s.parseToken(req, &token)
-	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+	authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, requestAuthzContext(req))
 	if err != nil {
 		return nil, err
 	}
@@ -1862,6 +1862,128 @@ func TestAgent_AddCheck_GRPC(t *testing.T) {
 	requireCheckExistsMap(t, a.checkGRPCs, "grpchealth")
 }
 
+func TestAgent_AddCheck_TLS(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+
+	health := &structs.HealthCheck{
+		Node:    "foo",
+		CheckID: "tlsexpiry",
+		Name:    "certificate expiry",
+		Status:  api.HealthCritical,
+	}
+	chk := &structs.CheckType{
+		TLS:                  "localhost:12345",
+		TLSExpiryWarningDays: 14,
+		Interval:             15 * time.Second,
+	}
+	err := a.AddCheck(health, chk, false, "", ConfigSourceLocal)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Ensure we have a check mapping
+	requireCheckExists(t, a, "tlsexpiry")
+
+	// Ensure a check is setup
+	requireCheckExistsMap(t, a.checkTLSs, "tlsexpiry")
+
+	// Removing the check stops it
+	require.NoError(t, a.RemoveCheck(structs.NewCheckID("tlsexpiry", nil), false))
+	requireCheckMissingMap(t, a.checkTLSs, "tlsexpiry")
+}
+
+func TestAgent_AddCheck_DNS(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+
+	health := &structs.HealthCheck{
+		Node:    "foo",
+		CheckID: "dnsrecords",
+		Name:    "dns records",
+		Status:  api.HealthCritical,
+	}
+	chk := &structs.CheckType{
+		DNS:               "db.example.com",
+		DNSResolver:       "127.0.0.1:12345",
+		DNSExpectedValues: []string{"10.0.0.1"},
+		Interval:          15 * time.Second,
+	}
+	err := a.AddCheck(health, chk, false, "", ConfigSourceLocal)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Ensure we have a check mapping
+	requireCheckExists(t, a, "dnsrecords")
+
+	// Ensure a check is setup
+	requireCheckExistsMap(t, a.checkDNSs, "dnsrecords")
+
+	// Removing the check stops it
+	require.NoError(t, a.RemoveCheck(structs.NewCheckID("dnsrecords", nil), false))
+	requireCheckMissingMap(t, a.checkDNSs, "dnsrecords")
+}
+
+func TestAgent_AddCheck_DependsOn(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+
+	node := &structs.HealthCheck{
+		Node:    "foo",
+		CheckID: "disk",
+		Name:    "disk",
+		Status:  api.HealthCritical,
+	}
+	err := a.AddCheck(node, &structs.CheckType{TTL: 15 * time.Second}, false, "", ConfigSourceLocal)
+	require.NoError(t, err)
+
+	health := &structs.HealthCheck{
+		Node:    "foo",
+		CheckID: "app",
+		Name:    "app",
+		Status:  api.HealthCritical,
+	}
+	chk := &structs.CheckType{
+		TTL:       15 * time.Second,
+		DependsOn: []types.CheckID{"disk"},
+	}
+	err = a.AddCheck(health, chk, false, "", ConfigSourceLocal)
+	require.NoError(t, err)
+
+	// The dependent check is suppressed by the critical node check.
+	sChk := requireCheckExists(t, a, "app")
+	require.Equal(t, []types.CheckID{"disk"}, sChk.DependsOn)
+	require.Equal(t, types.CheckID("disk"), sChk.SuppressedBy)
+
+	// It is no longer suppressed once the node check passes.
+	require.NoError(t, a.updateTTLCheck(structs.NewCheckID("disk", nil), api.HealthPassing, ""))
+	sChk = requireCheckExists(t, a, "app")
+	require.Equal(t, api.HealthCritical, sChk.Status)
+	require.Empty(t, sChk.SuppressedBy)
+
+	// A check can't depend on itself.
+	chk = &structs.CheckType{TTL: 15 * time.Second, DependsOn: []types.CheckID{"self"}}
+	health = &structs.HealthCheck{Node: "foo", CheckID: "self", Name: "self"}
+	err = a.AddCheck(health, chk, false, "", ConfigSourceLocal)
+	require.Error(t, err)
+}
+
 func TestAgent_RestoreServiceWithAliasCheck(t *testing.T) {
 	// t.Parallel() don't even think about making this parallel
 
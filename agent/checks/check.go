@@ -5,6 +5,7 @@ package checks
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"crypto/tls"
 	"errors"
@@ -352,6 +353,10 @@ type CheckHTTP struct {
 	StatusHandler    *StatusHandler
 	DisableRedirects bool
 
+	// Assertions are checked against the response, on top of its status
+	// code.
+	Assertions []structs.HTTPCheckAssertion
+
 	httpClient *http.Client
 	stop       bool
 	stopCh     chan struct{}
@@ -365,15 +370,16 @@ type CheckHTTP struct {
 
 func (c *CheckHTTP) CheckType() structs.CheckType {
 	return structs.CheckType{
-		CheckID:       c.CheckID.ID,
-		HTTP:          c.HTTP,
-		Method:        c.Method,
-		Body:          c.Body,
-		Header:        c.Header,
-		Interval:      c.Interval,
-		ProxyHTTP:     c.ProxyHTTP,
-		Timeout:       c.Timeout,
-		OutputMaxSize: c.OutputMaxSize,
+		CheckID:        c.CheckID.ID,
+		HTTP:           c.HTTP,
+		Method:         c.Method,
+		Body:           c.Body,
+		Header:         c.Header,
+		HTTPAssertions: c.Assertions,
+		Interval:       c.Interval,
+		ProxyHTTP:      c.ProxyHTTP,
+		Timeout:        c.Timeout,
+		OutputMaxSize:  c.OutputMaxSize,
 	}
 }
 
@@ -484,6 +490,7 @@ func (c *CheckHTTP) check() {
 		req.Header.Set("Accept", "text/plain, text/*, */*")
 	}
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, err.Error())
@@ -491,30 +498,48 @@ func (c *CheckHTTP) check() {
 	}
 	defer resp.Body.Close()
 
-	// Read the response into a circular buffer to limit the size
+	// Read the response into a circular buffer to limit the size. The body is
+	// kept whole, up to a limit, when it has to be checked by assertions.
 	output, _ := circbuf.NewBuffer(int64(c.OutputMaxSize))
-	if _, err := io.Copy(output, resp.Body); err != nil {
+	var body bytes.Buffer
+	var reader io.Reader = resp.Body
+	if len(c.Assertions) > 0 {
+		reader = io.TeeReader(io.LimitReader(resp.Body, httpAssertionMaxBodySize), &body)
+	}
+	if _, err := io.Copy(output, reader); err != nil {
 		c.Logger.Warn("Check error while reading body",
 			"check", c.CheckID.String(),
 			"error", err,
 		)
 	}
+	latency := time.Since(start)
 
-	// Format the response body
-	result := fmt.Sprintf("HTTP %s %s: %s Output: %s", method, target, resp.Status, output.String())
-
+	var status string
 	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
 		// PASSING (2xx)
-		c.StatusHandler.updateCheck(c.CheckID, api.HealthPassing, result)
+		status = api.HealthPassing
 	} else if resp.StatusCode == 429 {
 		// WARNING
 		// 429 Too Many Requests (RFC 6585)
 		// The user has sent too many requests in a given amount of time.
-		c.StatusHandler.updateCheck(c.CheckID, api.HealthWarning, result)
+		status = api.HealthWarning
 	} else {
 		// CRITICAL
-		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, result)
+		status = api.HealthCritical
 	}
+
+	// Format the response body
+	result := fmt.Sprintf("HTTP %s %s: %s", method, target, resp.Status)
+	if len(c.Assertions) > 0 {
+		assertionStatus, failures := evaluateHTTPAssertions(c.Assertions, resp, body.Bytes(), latency)
+		status = worseStatus(status, assertionStatus)
+		if len(failures) > 0 {
+			result += fmt.Sprintf(" Failed assertions: %s", strings.Join(failures, "; "))
+		}
+	}
+	result += fmt.Sprintf(" Output: %s", output.String())
+
+	c.StatusHandler.updateCheck(c.CheckID, status, result)
 }
 
 type CheckH2PING struct {
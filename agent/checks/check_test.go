@@ -507,6 +507,105 @@ func TestCheckHTTP_DisableRedirects(t *testing.T) {
 	})
 }
 
+func TestCheckHTTP_Assertions(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Consul-Index", "42")
+		fmt.Fprint(w, `{"status":"degraded","checks":[{"name":"db","healthy":true}]}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		desc       string
+		assertions []structs.HTTPCheckAssertion
+		status     string
+		output     string
+	}{
+		{
+			desc: "all pass",
+			assertions: []structs.HTTPCheckAssertion{
+				{Body: `"status":"\w+"`},
+				{JSONPath: "$.checks[0].healthy", Value: "true"},
+				{Header: "X-Consul-Index", Value: "42"},
+				{MaxLatency: time.Minute},
+			},
+			status: api.HealthPassing,
+		},
+		{
+			desc: "json path value",
+			assertions: []structs.HTTPCheckAssertion{
+				{JSONPath: "$.status", Value: "ok", Status: api.HealthWarning},
+			},
+			status: api.HealthWarning,
+			output: `Failed assertions: $.status is "degraded", expected "ok"`,
+		},
+		{
+			desc: "worst status wins",
+			assertions: []structs.HTTPCheckAssertion{
+				{JSONPath: "$.status", Value: "ok", Status: api.HealthWarning},
+				{Header: "X-Missing"},
+			},
+			status: api.HealthCritical,
+			output: `Failed assertions: $.status is "degraded", expected "ok"; header X-Missing is missing`,
+		},
+		{
+			desc: "body",
+			assertions: []structs.HTTPCheckAssertion{
+				{Body: `"status":"ok"`},
+			},
+			status: api.HealthCritical,
+			output: `Failed assertions: body does not match "\"status\":\"ok\""`,
+		},
+		{
+			desc: "passing status",
+			assertions: []structs.HTTPCheckAssertion{
+				{JSONPath: "$.missing", Status: api.HealthPassing},
+			},
+			status: api.HealthPassing,
+			output: "Failed assertions: $.missing not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			notif := mock.NewNotify()
+			logger := testutil.Logger(t)
+			cid := structs.NewCheckID("foo", nil)
+
+			check := &CheckHTTP{
+				CheckID:       cid,
+				HTTP:          server.URL,
+				Method:        "GET",
+				Assertions:    tt.assertions,
+				OutputMaxSize: DefaultBufSize,
+				Interval:      10 * time.Millisecond,
+				Logger:        logger,
+				StatusHandler: NewStatusHandler(notif, logger, 0, 0, 0),
+			}
+			check.Start()
+			defer check.Stop()
+
+			retry.Run(t, func(r *retry.R) {
+				if got, want := notif.State(cid), tt.status; got != want {
+					r.Fatalf("got status %q want %q", got, want)
+				}
+				output := notif.Output(cid)
+				if !strings.HasSuffix(output, `Output: {"status":"degraded","checks":[{"name":"db","healthy":true}]}`) {
+					r.Fatalf("bad output: %q", output)
+				}
+				if tt.output == "" && strings.Contains(output, "Failed assertions") {
+					r.Fatalf("bad output: %q", output)
+				}
+				if !strings.Contains(output, tt.output) {
+					r.Fatalf("got output %q want %q", output, tt.output)
+				}
+			})
+		})
+	}
+}
+
 func TestCheckHTTPTCP_BigTimeout(t *testing.T) {
 	testCases := []struct {
 		timeoutIn, intervalIn, timeoutWant time.Duration
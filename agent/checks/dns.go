@@ -0,0 +1,235 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package checks
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/miekg/dns"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/lib"
+)
+
+// resolvConfPath is the file the resolver of a DNS check is read from when it
+// is not set.
+const resolvConfPath = "/etc/resolv.conf"
+
+// CheckDNS is used to periodically query a DNS resolver to determine the
+// health of the records of a name.
+// The check is passing if the response has the expected response code, at
+// least one answer when that code is NOERROR, and all of ExpectedValues among
+// its answers.
+// The check is warning if the response took longer than MaxLatency.
+// The check is critical if the query fails or if the response is not as
+// expected.
+// Supports failures_before_critical and success_before_passing.
+type CheckDNS struct {
+	CheckID        structs.CheckID
+	ServiceID      structs.ServiceID
+	DNS            string
+	QueryType      string
+	Resolver       string
+	ExpectedValues []string
+	Rcode          string
+	MaxLatency     time.Duration
+	Interval       time.Duration
+	Timeout        time.Duration
+	Logger         hclog.Logger
+	StatusHandler  *StatusHandler
+
+	client   *dns.Client
+	stop     bool
+	stopCh   chan struct{}
+	stopLock sync.Mutex
+}
+
+// Start is used to start a DNS check.
+// The check runs until stop is called
+func (c *CheckDNS) Start() {
+	c.stopLock.Lock()
+	defer c.stopLock.Unlock()
+
+	if c.client == nil {
+		c.client = &dns.Client{
+			Timeout: 10 * time.Second,
+		}
+		if c.Timeout > 0 {
+			c.client.Timeout = c.Timeout
+		}
+	}
+	if c.QueryType == "" {
+		c.QueryType = "A"
+	}
+	if c.Rcode == "" {
+		c.Rcode = "NOERROR"
+	}
+
+	c.stop = false
+	c.stopCh = make(chan struct{})
+	go c.run()
+}
+
+// Stop is used to stop a DNS check.
+func (c *CheckDNS) Stop() {
+	c.stopLock.Lock()
+	defer c.stopLock.Unlock()
+	if !c.stop {
+		c.stop = true
+		close(c.stopCh)
+	}
+}
+
+// run is invoked by a goroutine to run until Stop() is called
+func (c *CheckDNS) run() {
+	// Get the randomized initial pause time
+	initialPauseTime := lib.RandomStagger(c.Interval)
+	next := time.After(initialPauseTime)
+	for {
+		select {
+		case <-next:
+			c.check()
+			next = time.After(c.Interval)
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// check is invoked periodically to perform the DNS check
+func (c *CheckDNS) check() {
+	resolver, err := c.resolver()
+	if err != nil {
+		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, fmt.Sprintf("Failed to find a DNS resolver: %s", err))
+		return
+	}
+
+	qtype := strings.ToUpper(c.QueryType)
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(c.DNS), dns.StringToType[qtype])
+	query := fmt.Sprintf("DNS query %s %s to %s", qtype, m.Question[0].Name, resolver)
+
+	resp, rtt, err := c.client.Exchange(m, resolver)
+	if err == nil && resp.Truncated {
+		// Retry over TCP to get all the answers.
+		tcpClient := *c.client
+		tcpClient.Net = "tcp"
+		resp, rtt, err = tcpClient.Exchange(m, resolver)
+	}
+	if err != nil {
+		c.Logger.Warn("Check DNS query failed",
+			"check", c.CheckID.String(),
+			"error", err,
+		)
+		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, fmt.Sprintf("%s failed: %s", query, err))
+		return
+	}
+
+	values := make([]string, 0, len(resp.Answer))
+	for _, rr := range resp.Answer {
+		values = append(values, dnsAnswerValue(rr))
+	}
+
+	status := api.HealthPassing
+	var failures []string
+	rcode := dns.RcodeToString[resp.Rcode]
+	switch {
+	case !strings.EqualFold(rcode, c.Rcode):
+		status = api.HealthCritical
+		failures = append(failures, fmt.Sprintf("response code is %s, expected %s", rcode, strings.ToUpper(c.Rcode)))
+	case resp.Rcode == dns.RcodeSuccess && len(resp.Answer) == 0:
+		status = api.HealthCritical
+		failures = append(failures, "no answer")
+	}
+	for _, expected := range c.ExpectedValues {
+		if !containsDNSValue(values, expected) {
+			status = api.HealthCritical
+			failures = append(failures, fmt.Sprintf("%s is not in the answers", expected))
+		}
+	}
+	if c.MaxLatency > 0 && rtt > c.MaxLatency {
+		status = worseStatus(status, api.HealthWarning)
+		failures = append(failures, fmt.Sprintf("response took %s, more than %s", rtt.Round(time.Millisecond), c.MaxLatency))
+	}
+
+	result := fmt.Sprintf("%s: %s in %s", query, rcode, rtt.Round(time.Millisecond))
+	if len(values) > 0 {
+		result += fmt.Sprintf(", answers: %s", strings.Join(values, ", "))
+	}
+	if len(failures) > 0 {
+		result += fmt.Sprintf(" Failed assertions: %s", strings.Join(failures, "; "))
+	}
+	c.StatusHandler.updateCheck(c.CheckID, status, result)
+}
+
+// resolver returns the address of the resolver to query, which defaults to
+// the first nameserver of the system.
+func (c *CheckDNS) resolver() (string, error) {
+	if c.Resolver != "" {
+		if _, _, err := net.SplitHostPort(c.Resolver); err == nil {
+			return c.Resolver, nil
+		}
+		return net.JoinHostPort(strings.Trim(c.Resolver, "[]"), "53"), nil
+	}
+
+	conf, err := dns.ClientConfigFromFile(resolvConfPath)
+	if err != nil {
+		return "", err
+	}
+	if len(conf.Servers) == 0 {
+		return "", fmt.Errorf("no nameserver in %s", resolvConfPath)
+	}
+	return net.JoinHostPort(conf.Servers[0], conf.Port), nil
+}
+
+// dnsAnswerValue returns the value of an answer that is compared with the
+// expected values: the address of A and AAAA records, the target of CNAME,
+// NS, PTR, MX and SRV records, the text of TXT records and the data in the
+// presentation format for the other types.
+func dnsAnswerValue(rr dns.RR) string {
+	switch rr := rr.(type) {
+	case *dns.A:
+		return rr.A.String()
+	case *dns.AAAA:
+		return rr.AAAA.String()
+	case *dns.CNAME:
+		return rr.Target
+	case *dns.NS:
+		return rr.Ns
+	case *dns.PTR:
+		return rr.Ptr
+	case *dns.MX:
+		return rr.Mx
+	case *dns.SRV:
+		return rr.Target
+	case *dns.TXT:
+		return strings.Join(rr.Txt, "")
+	default:
+		return strings.TrimPrefix(rr.String(), rr.Header().String())
+	}
+}
+
+// containsDNSValue returns whether expected is one of values. Addresses are
+// compared as IPs and names regardless of their case and final dot.
+func containsDNSValue(values []string, expected string) bool {
+	expectedIP := net.ParseIP(expected)
+	for _, v := range values {
+		if expectedIP != nil {
+			if ip := net.ParseIP(v); ip != nil && ip.Equal(expectedIP) {
+				return true
+			}
+			continue
+		}
+		if strings.EqualFold(strings.TrimSuffix(v, "."), strings.TrimSuffix(expected, ".")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package checks
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/mock"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+)
+
+// startDNSServer starts a DNS server answering A queries for db.example.com
+// and TXT queries for example.com, and NXDOMAIN for any other name.
+func startDNSServer(t *testing.T, delay time.Duration) string {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		time.Sleep(delay)
+
+		m := new(dns.Msg)
+		m.SetReply(req)
+		q := req.Question[0]
+		hdr := dns.RR_Header{Name: q.Name, Rrtype: q.Qtype, Class: dns.ClassINET, Ttl: 30}
+		switch {
+		case q.Name == "db.example.com." && q.Qtype == dns.TypeA:
+			m.Answer = []dns.RR{
+				&dns.A{Hdr: hdr, A: net.ParseIP("10.0.0.1")},
+				&dns.A{Hdr: hdr, A: net.ParseIP("10.0.0.2")},
+			}
+		case q.Name == "example.com." && q.Qtype == dns.TypeTXT:
+			m.Answer = []dns.RR{&dns.TXT{Hdr: hdr, Txt: []string{"v=spf1 -all"}}}
+		case q.Name == "example.com.":
+		default:
+			m.SetRcode(req, dns.RcodeNameError)
+		}
+		w.WriteMsg(m)
+	})
+
+	server := &dns.Server{PacketConn: pc, Handler: handler}
+	go server.ActivateAndServe()
+	t.Cleanup(func() { server.Shutdown() })
+	return pc.LocalAddr().String()
+}
+
+func TestCheckDNS(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc       string
+		name       string
+		qtype      string
+		expected   []string
+		rcode      string
+		maxLatency time.Duration
+		status     string
+		output     string
+	}{
+		{
+			desc:     "passing",
+			name:     "db.example.com",
+			expected: []string{"10.0.0.2", "10.0.0.1"},
+			status:   api.HealthPassing,
+			output:   "DNS query A db.example.com. to 127.0.0.1:",
+		},
+		{
+			desc:     "txt",
+			name:     "example.com",
+			qtype:    "txt",
+			expected: []string{"v=spf1 -all"},
+			status:   api.HealthPassing,
+			output:   "answers: v=spf1 -all",
+		},
+		{
+			desc:     "missing value",
+			name:     "db.example.com",
+			expected: []string{"10.0.0.3"},
+			status:   api.HealthCritical,
+			output:   "Failed assertions: 10.0.0.3 is not in the answers",
+		},
+		{
+			desc:   "no answer",
+			name:   "example.com",
+			status: api.HealthCritical,
+			output: "Failed assertions: no answer",
+		},
+		{
+			desc:   "rcode",
+			name:   "missing.example.com",
+			status: api.HealthCritical,
+			output: "Failed assertions: response code is NXDOMAIN, expected NOERROR",
+		},
+		{
+			desc:   "expected rcode",
+			name:   "missing.example.com",
+			rcode:  "nxdomain",
+			status: api.HealthPassing,
+			output: ": NXDOMAIN in ",
+		},
+		{
+			desc:       "latency",
+			name:       "db.example.com",
+			maxLatency: time.Nanosecond,
+			status:     api.HealthWarning,
+			output:     "Failed assertions: response took",
+		},
+	}
+
+	addr := startDNSServer(t, 0)
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			notif := mock.NewNotify()
+			logger := testutil.Logger(t)
+			cid := structs.NewCheckID("foo", nil)
+
+			check := &CheckDNS{
+				CheckID:        cid,
+				DNS:            tt.name,
+				QueryType:      tt.qtype,
+				Resolver:       addr,
+				ExpectedValues: tt.expected,
+				Rcode:          tt.rcode,
+				MaxLatency:     tt.maxLatency,
+				Interval:       10 * time.Millisecond,
+				Logger:         logger,
+				StatusHandler:  NewStatusHandler(notif, logger, 0, 0, 0),
+			}
+			check.Start()
+			defer check.Stop()
+
+			retry.Run(t, func(r *retry.R) {
+				if got, want := notif.State(cid), tt.status; got != want {
+					r.Fatalf("got status %q want %q", got, want)
+				}
+				if output := notif.Output(cid); !strings.Contains(output, tt.output) {
+					r.Fatalf("got output %q want %q", output, tt.output)
+				}
+			})
+		})
+	}
+}
+
+func TestCheckDNS_Timeout(t *testing.T) {
+	t.Parallel()
+
+	addr := startDNSServer(t, 100*time.Millisecond)
+
+	notif := mock.NewNotify()
+	logger := testutil.Logger(t)
+	cid := structs.NewCheckID("foo", nil)
+
+	check := &CheckDNS{
+		CheckID:       cid,
+		DNS:           "db.example.com",
+		Resolver:      addr,
+		Interval:      10 * time.Millisecond,
+		Timeout:       10 * time.Millisecond,
+		Logger:        logger,
+		StatusHandler: NewStatusHandler(notif, logger, 0, 0, 0),
+	}
+	check.Start()
+	defer check.Stop()
+
+	retry.Run(t, func(r *retry.R) {
+		if got, want := notif.State(cid), api.HealthCritical; got != want {
+			r.Fatalf("got status %q want %q", got, want)
+		}
+		if output := notif.Output(cid); !strings.Contains(output, "failed") {
+			r.Fatalf("bad output: %q", output)
+		}
+	})
+}
+
+func TestCheckDNS_Resolver(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"127.0.0.1":      "127.0.0.1:53",
+		"127.0.0.1:5353": "127.0.0.1:5353",
+		"::1":            "[::1]:53",
+		"[::1]":          "[::1]:53",
+		"[::1]:5353":     "[::1]:5353",
+		"ns.example.com": "ns.example.com:53",
+	}
+	for resolver, expected := range cases {
+		c := &CheckDNS{Resolver: resolver}
+		addr, err := c.resolver()
+		require.NoError(t, err)
+		require.Equal(t, expected, addr, resolver)
+	}
+}
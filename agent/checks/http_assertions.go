@@ -0,0 +1,135 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package checks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/lib/jsonpath"
+)
+
+// httpAssertionMaxBodySize is the largest part of the body of a response that
+// is read to evaluate the assertions of an HTTP check.
+const httpAssertionMaxBodySize = 4 * 1024 * 1024
+
+// statusSeverity orders the statuses of a check from the best to the worst.
+var statusSeverity = map[string]int{
+	api.HealthPassing:  0,
+	api.HealthWarning:  1,
+	api.HealthCritical: 2,
+}
+
+// worseStatus returns the worst of two check statuses.
+func worseStatus(a, b string) string {
+	if statusSeverity[b] > statusSeverity[a] {
+		return b
+	}
+	return a
+}
+
+// evaluateHTTPAssertions checks the response of an HTTP check against its
+// assertions. It returns the worst status of the assertions that failed, or
+// passing when none did, along with a description of the failures.
+func evaluateHTTPAssertions(assertions []structs.HTTPCheckAssertion, resp *http.Response, body []byte, latency time.Duration) (string, []string) {
+	status := api.HealthPassing
+	var failures []string
+
+	// The body is only decoded once, by the first JSONPath assertion.
+	var doc interface{}
+	var docErr error
+	decoded := false
+
+	for _, assertion := range assertions {
+		var failure string
+		switch {
+		case assertion.Body != "":
+			re, err := regexp.Compile(assertion.Body)
+			if err != nil {
+				failure = fmt.Sprintf("invalid body regular expression %q: %v", assertion.Body, err)
+			} else if !re.Match(body) {
+				failure = fmt.Sprintf("body does not match %q", assertion.Body)
+			}
+
+		case assertion.JSONPath != "":
+			if !decoded {
+				docErr = json.Unmarshal(body, &doc)
+				decoded = true
+			}
+			failure = checkJSONPathAssertion(assertion, doc, docErr)
+
+		case assertion.Header != "":
+			values, ok := resp.Header[http.CanonicalHeaderKey(assertion.Header)]
+			switch {
+			case !ok:
+				failure = fmt.Sprintf("header %s is missing", assertion.Header)
+			case assertion.Value != "" && !containsString(values, assertion.Value):
+				failure = fmt.Sprintf("header %s is %q, expected %q", assertion.Header, values[0], assertion.Value)
+			}
+
+		case assertion.MaxLatency > 0:
+			if latency > assertion.MaxLatency {
+				failure = fmt.Sprintf("response took %s, more than %s", latency.Round(time.Millisecond), assertion.MaxLatency)
+			}
+		}
+
+		if failure == "" {
+			continue
+		}
+		failureStatus := assertion.Status
+		if failureStatus == "" {
+			failureStatus = api.HealthCritical
+		}
+		status = worseStatus(status, failureStatus)
+		failures = append(failures, failure)
+	}
+	return status, failures
+}
+
+// checkJSONPathAssertion returns a description of the failure of a JSONPath
+// assertion against the decoded body of a response, or an empty string when
+// it succeeds.
+func checkJSONPathAssertion(assertion structs.HTTPCheckAssertion, doc interface{}, docErr error) string {
+	if docErr != nil {
+		return fmt.Sprintf("body is not valid JSON: %v", docErr)
+	}
+	path, err := jsonpath.Parse(assertion.JSONPath)
+	if err != nil {
+		return err.Error()
+	}
+	value, ok := path.Find(doc)
+	if !ok {
+		return fmt.Sprintf("%s not found", assertion.JSONPath)
+	}
+	if assertion.Value == "" {
+		return ""
+	}
+
+	actual, isString := value.(string)
+	if !isString {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprintf("%s cannot be encoded: %v", assertion.JSONPath, err)
+		}
+		actual = string(encoded)
+	}
+	if actual != assertion.Value {
+		return fmt.Sprintf("%s is %q, expected %q", assertion.JSONPath, actual, assertion.Value)
+	}
+	return ""
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package checks
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/lib"
+)
+
+// CheckTLS is used to periodically perform a TLS handshake with an address to
+// determine the health of the certificates it presents.
+// The check is passing if the certificate chain is valid and none of its
+// certificates expires within WarningDays.
+// The check is warning if one of them expires within WarningDays.
+// The check is critical if one of them expires within CriticalDays, if the
+// chain is not valid or if the handshake fails.
+// Supports failures_before_critical and success_before_passing.
+type CheckTLS struct {
+	CheckID         structs.CheckID
+	ServiceID       structs.ServiceID
+	TLS             string
+	WarningDays     int
+	CriticalDays    int
+	Interval        time.Duration
+	Timeout         time.Duration
+	Logger          hclog.Logger
+	TLSClientConfig *tls.Config
+	StatusHandler   *StatusHandler
+
+	dialer   *net.Dialer
+	stop     bool
+	stopCh   chan struct{}
+	stopLock sync.Mutex
+}
+
+// Start is used to start a TLS check.
+// The check runs until stop is called
+func (c *CheckTLS) Start() {
+	c.stopLock.Lock()
+	defer c.stopLock.Unlock()
+
+	if c.dialer == nil {
+		// Create the socket dialer
+		c.dialer = &net.Dialer{
+			Timeout: 10 * time.Second,
+		}
+		if c.Timeout > 0 {
+			c.dialer.Timeout = c.Timeout
+		}
+	}
+	if c.WarningDays <= 0 {
+		c.WarningDays = structs.DefaultTLSExpiryWarningDays
+	}
+	if c.CriticalDays <= 0 {
+		c.CriticalDays = structs.DefaultTLSExpiryCriticalDays
+	}
+
+	c.stop = false
+	c.stopCh = make(chan struct{})
+	go c.run()
+}
+
+// Stop is used to stop a TLS check.
+func (c *CheckTLS) Stop() {
+	c.stopLock.Lock()
+	defer c.stopLock.Unlock()
+	if !c.stop {
+		c.stop = true
+		close(c.stopCh)
+	}
+}
+
+// run is invoked by a goroutine to run until Stop() is called
+func (c *CheckTLS) run() {
+	// Get the randomized initial pause time
+	initialPauseTime := lib.RandomStagger(c.Interval)
+	next := time.After(initialPauseTime)
+	for {
+		select {
+		case <-next:
+			c.check()
+			next = time.After(c.Interval)
+		case <-c.stopCh:
+			return
+		}
+	}
+}
+
+// check is invoked periodically to perform the TLS check
+func (c *CheckTLS) check() {
+	config := &tls.Config{}
+	if c.TLSClientConfig != nil {
+		config = c.TLSClientConfig.Clone()
+	}
+	if config.ServerName == "" {
+		if host, _, err := net.SplitHostPort(c.TLS); err == nil {
+			config.ServerName = host
+		}
+	}
+	// The chain is verified once the handshake is done so that the
+	// certificates are reported even when it is not valid.
+	skipVerify := config.InsecureSkipVerify
+	config.InsecureSkipVerify = true
+
+	conn, err := tls.DialWithDialer(c.dialer, "tcp", c.TLS, config)
+	if err != nil {
+		c.Logger.Warn("Check TLS handshake failed",
+			"check", c.CheckID.String(),
+			"error", err,
+		)
+		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, fmt.Sprintf("TLS handshake with %s failed: %s", c.TLS, err))
+		return
+	}
+	certs := conn.ConnectionState().PeerCertificates
+	conn.Close()
+
+	if len(certs) == 0 {
+		c.StatusHandler.updateCheck(c.CheckID, api.HealthCritical, fmt.Sprintf("TLS handshake with %s: no certificate presented", c.TLS))
+		return
+	}
+
+	now := time.Now()
+	status, output := c.checkCertificates(certs, now)
+
+	result := fmt.Sprintf("TLS handshake with %s: Success", c.TLS)
+	if !skipVerify {
+		if err := verifyCertificateChain(certs, config, now); err != nil {
+			status = api.HealthCritical
+			result = fmt.Sprintf("TLS handshake with %s: invalid certificate chain: %s", c.TLS, err)
+		}
+	}
+	c.StatusHandler.updateCheck(c.CheckID, status, result+"\n"+output)
+}
+
+// checkCertificates returns the status of the certificates presented by the
+// server, the leaf first followed by the intermediates, according to their
+// expiry, and a description of each of them.
+func (c *CheckTLS) checkCertificates(certs []*x509.Certificate, now time.Time) (string, string) {
+	status := api.HealthPassing
+	lines := make([]string, 0, len(certs))
+	for i, cert := range certs {
+		kind := "intermediate"
+		if i == 0 {
+			kind = "leaf"
+		}
+
+		remaining := cert.NotAfter.Sub(now)
+		certStatus := api.HealthPassing
+		switch {
+		case remaining <= time.Duration(c.CriticalDays)*24*time.Hour:
+			certStatus = api.HealthCritical
+		case remaining <= time.Duration(c.WarningDays)*24*time.Hour:
+			certStatus = api.HealthWarning
+		}
+		status = worseStatus(status, certStatus)
+
+		expiry := fmt.Sprintf("expires in %d days", int(remaining.Hours()/24))
+		if remaining < 0 {
+			expiry = fmt.Sprintf("expired %d days ago", int(-remaining.Hours()/24))
+		}
+		lines = append(lines, fmt.Sprintf("%s %s: Subject=%q Issuer=%q NotAfter=%s (%s)",
+			kind, certStatus, cert.Subject, cert.Issuer, cert.NotAfter.UTC().Format(time.RFC3339), expiry))
+	}
+	return status, strings.Join(lines, "\n")
+}
+
+// verifyCertificateChain verifies the certificates presented by a server
+// against the roots of config, or the system roots when it has none.
+func verifyCertificateChain(certs []*x509.Certificate, config *tls.Config, now time.Time) error {
+	opts := x509.VerifyOptions{
+		Roots:         config.RootCAs,
+		DNSName:       config.ServerName,
+		Intermediates: x509.NewCertPool(),
+		CurrentTime:   now,
+	}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(opts)
+	return err
+}
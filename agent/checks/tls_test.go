@@ -0,0 +1,153 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package checks
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/mock"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+	"github.com/hashicorp/consul/tlsutil"
+)
+
+// startTLSServer starts a TLS server presenting a certificate for 127.0.0.1
+// that expires in days, signed by the CA returned.
+func startTLSServer(t *testing.T, days int) (string, *x509.CertPool) {
+	t.Helper()
+
+	caPEM, caKey, err := tlsutil.GenerateCA(tlsutil.CAOpts{Name: "Test CA"})
+	require.NoError(t, err)
+	signer, err := tlsutil.ParseSigner(caKey)
+	require.NoError(t, err)
+	certPEM, keyPEM, err := tlsutil.GenerateCert(tlsutil.CertOpts{
+		Signer:      signer,
+		CA:          caPEM,
+		Name:        "server.dc1.consul",
+		Days:        days,
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	require.NoError(t, err)
+	cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM([]byte(caPEM)))
+	return server.Listener.Addr().String(), roots
+}
+
+func TestCheckTLS(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc       string
+		days       int
+		skipVerify bool
+		untrusted  bool
+		status     string
+		output     string
+	}{
+		{desc: "passing", days: 90, status: api.HealthPassing, output: "leaf passing"},
+		{desc: "warning", days: 20, status: api.HealthWarning, output: "leaf warning"},
+		{desc: "critical", days: 3, status: api.HealthCritical, output: "leaf critical"},
+		{desc: "untrusted", days: 90, untrusted: true, status: api.HealthCritical, output: "invalid certificate chain"},
+		{desc: "untrusted skip verify", days: 90, untrusted: true, skipVerify: true, status: api.HealthPassing, output: "leaf passing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			addr, roots := startTLSServer(t, tt.days)
+			tlsConfig := &tls.Config{RootCAs: roots, InsecureSkipVerify: tt.skipVerify}
+			if tt.untrusted {
+				tlsConfig.RootCAs = x509.NewCertPool()
+			}
+
+			notif := mock.NewNotify()
+			logger := testutil.Logger(t)
+			cid := structs.NewCheckID("foo", nil)
+
+			check := &CheckTLS{
+				CheckID:         cid,
+				TLS:             addr,
+				Interval:        10 * time.Millisecond,
+				Logger:          logger,
+				TLSClientConfig: tlsConfig,
+				StatusHandler:   NewStatusHandler(notif, logger, 0, 0, 0),
+			}
+			check.Start()
+			defer check.Stop()
+
+			retry.Run(t, func(r *retry.R) {
+				if got, want := notif.State(cid), tt.status; got != want {
+					r.Fatalf("got status %q want %q", got, want)
+				}
+				output := notif.Output(cid)
+				if !strings.Contains(output, tt.output) {
+					r.Fatalf("got output %q want %q", output, tt.output)
+				}
+				if !strings.Contains(output, `Subject="CN=server.dc1.consul" Issuer="CN=Test CA,`) {
+					r.Fatalf("bad output: %q", output)
+				}
+			})
+		})
+	}
+}
+
+func TestCheckTLS_HandshakeFailure(t *testing.T) {
+	t.Parallel()
+
+	// A plain TCP listener that closes connections fails the handshake.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	notif := mock.NewNotify()
+	logger := testutil.Logger(t)
+	cid := structs.NewCheckID("foo", nil)
+
+	check := &CheckTLS{
+		CheckID:       cid,
+		TLS:           ln.Addr().String(),
+		Interval:      10 * time.Millisecond,
+		Logger:        logger,
+		StatusHandler: NewStatusHandler(notif, logger, 0, 0, 0),
+	}
+	check.Start()
+	defer check.Stop()
+
+	retry.Run(t, func(r *retry.R) {
+		if got, want := notif.State(cid), api.HealthCritical; got != want {
+			r.Fatalf("got status %q want %q", got, want)
+		}
+		if output := notif.Output(cid); !strings.Contains(output, "TLS handshake with "+ln.Addr().String()+" failed") {
+			r.Fatalf("bad output: %q", output)
+		}
+	})
+}
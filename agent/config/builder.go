@@ -456,6 +456,28 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 		}
 	}
 
+	var dnssecKeyFiles []string
+	var dnssecNSEC3 bool
+	if c.DNS.DNSSEC != nil {
+		dnssecKeyFiles = c.DNS.DNSSEC.KeyFiles
+		dnssecNSEC3 = boolVal(c.DNS.DNSSEC.NSEC3)
+	}
+
+	recursorCache := RuntimeDNSRecursorCacheConfig{MaxTTL: time.Hour, MaxNegativeTTL: 5 * time.Minute, Prefetch: true}
+	if rc := c.DNS.RecursorCache; rc != nil {
+		recursorCache.Size = intVal(rc.MaxEntries)
+		recursorCache.MaxTTL = b.durationValWithDefault("dns_config.recursor_cache.max_ttl", rc.MaxTTL, recursorCache.MaxTTL)
+		recursorCache.MaxNegativeTTL = b.durationValWithDefault("dns_config.recursor_cache.max_negative_ttl", rc.MaxNegativeTTL, recursorCache.MaxNegativeTTL)
+		recursorCache.Prefetch = boolValWithDefault(rc.Prefetch, recursorCache.Prefetch)
+	}
+
+	zoneTransfer := RuntimeDNSZoneTransferConfig{CacheTTL: 5 * time.Second}
+	if zt := c.DNS.ZoneTransfer; zt != nil {
+		zoneTransfer.AllowedCIDRs = b.cidrsVal("dns_config.zone_transfer.allowed_cidrs", zt.AllowedCIDRs)
+		zoneTransfer.Token = stringVal(zt.Token)
+		zoneTransfer.CacheTTL = b.durationValWithDefault("dns_config.zone_transfer.cache_ttl", zt.CacheTTL, zoneTransfer.CacheTTL)
+	}
+
 	leaveOnTerm := !boolVal(c.ServerMode)
 	if c.LeaveOnTerm != nil {
 		leaveOnTerm = boolVal(c.LeaveOnTerm)
@@ -492,6 +514,7 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 
 	// determine port values and replace values <= 0 and > 65535 with -1
 	dnsPort := b.portVal("ports.dns", c.Ports.DNS)
+	dnsTLSPort := b.portVal("ports.dns_tls", c.Ports.DNSTLS)
 	httpPort := b.portVal("ports.http", c.Ports.HTTP)
 	httpsPort := b.portVal("ports.https", c.Ports.HTTPS)
 	serverPort := b.portVal("ports.server", c.Ports.Server)
@@ -639,6 +662,7 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 		b.warn("client_addr is empty, client services (DNS, HTTP, HTTPS, GRPC) will not be listening for connections")
 	}
 	dnsAddrs := b.makeAddrs(b.expandAddrs("addresses.dns", c.Addresses.DNS), clientAddrs, dnsPort)
+	dnsTLSAddrs := b.makeAddrs(b.expandAddrs("addresses.dns_tls", c.Addresses.DNSTLS), clientAddrs, dnsTLSPort)
 	httpAddrs := b.makeAddrs(b.expandAddrs("addresses.http", c.Addresses.HTTP), clientAddrs, httpPort)
 	httpsAddrs := b.makeAddrs(b.expandAddrs("addresses.https", c.Addresses.HTTPS), clientAddrs, httpsPort)
 	grpcAddrs := b.makeAddrs(b.expandAddrs("addresses.grpc", c.Addresses.GRPC), clientAddrs, grpcPort)
@@ -976,15 +1000,21 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 		DNSNodeTTL:            b.durationVal("dns_config.node_ttl", c.DNS.NodeTTL),
 		DNSOnlyPassing:        boolVal(c.DNS.OnlyPassing),
 		DNSPort:               dnsPort,
+		DNSTLSAddrs:           dnsTLSAddrs,
+		DNSTLSPort:            dnsTLSPort,
 		DNSRecursorStrategy:   b.dnsRecursorStrategyVal(stringVal(c.DNS.RecursorStrategy)),
+		DNSRecursorCache:      recursorCache,
 		DNSRecursorTimeout:    b.durationVal("recursor_timeout", c.DNS.RecursorTimeout),
 		DNSRecursors:          dnsRecursors,
 		DNSServiceTTL:         dnsServiceTTL,
 		DNSSOA:                soa,
+		DNSSECKeyFiles:        dnssecKeyFiles,
+		DNSSECNSEC3:           dnssecNSEC3,
 		DNSUDPAnswerLimit:     intVal(c.DNS.UDPAnswerLimit),
 		DNSNodeMetaTXT:        boolValWithDefault(c.DNS.NodeMetaTXT, true),
 		DNSUseCache:           boolVal(c.DNS.UseCache),
 		DNSCacheMaxAge:        b.durationVal("dns_config.cache_max_age", c.DNS.CacheMaxAge),
+		DNSZoneTransfer:       zoneTransfer,
 
 		// HTTP
 		HTTPPort:              httpPort,
@@ -1057,6 +1087,10 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 		AutoReloadConfig:                       boolVal(c.AutoReloadConfig),
 		CheckUpdateInterval:                    b.durationVal("check_update_interval", c.CheckUpdateInterval),
 		CheckOutputMaxSize:                     intValWithDefault(c.CheckOutputMaxSize, 4096),
+		CheckFlapDetectionEnabled:              boolVal(c.CheckFlapDetection.Enabled),
+		CheckFlapDetectionHistorySize:          intVal(c.CheckFlapDetection.HistorySize),
+		CheckFlapDetectionLowThreshold:         float64Val(c.CheckFlapDetection.LowThreshold),
+		CheckFlapDetectionHighThreshold:        float64Val(c.CheckFlapDetection.HighThreshold),
 		Checks:                                 checks,
 		ClientAddrs:                            clientAddrs,
 		ConfigEntryBootstrap:                   configEntries,
@@ -1215,6 +1249,7 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 	}
 
 	rt.UseStreamingBackend = boolValWithDefault(c.UseStreamingBackend, true)
+	rt.DNSAnswerOrderFromConfigEntries = boolVal(c.DNS.AnswerOrderFromConfigEntries)
 
 	if rt.Cache.EntryFetchMaxBurst <= 0 {
 		return RuntimeConfig{}, fmt.Errorf("cache.entry_fetch_max_burst must be strictly positive, was: %v", rt.Cache.EntryFetchMaxBurst)
@@ -1423,6 +1458,16 @@ func (b *builder) validate(rt RuntimeConfig) error {
 			return fmt.Errorf("DNS address cannot be a unix socket")
 		}
 	}
+	for _, a := range rt.DNSTLSAddrs {
+		if _, ok := a.(*net.UnixAddr); ok {
+			return fmt.Errorf("DNS TLS address cannot be a unix socket")
+		}
+	}
+	// The DNS over TLS listener serves the HTTPS certificate, without one
+	// every handshake would fail.
+	if len(rt.DNSTLSAddrs) > 0 && rt.TLS.HTTPS.CertFile == "" && !(rt.TLS.AutoTLS && rt.TLS.HTTPS.UseAutoCert) {
+		return fmt.Errorf("ports.dns_tls requires a certificate, set tls.https.cert_file or tls.defaults.cert_file")
+	}
 	for _, a := range rt.DNSRecursors {
 		if ipaddr.IsAny(a) {
 			return fmt.Errorf("DNS recursor address cannot be 0.0.0.0, :: or [::]")
@@ -1449,6 +1494,12 @@ func (b *builder) validate(rt RuntimeConfig) error {
 	if rt.CheckOutputMaxSize < 1 {
 		return fmt.Errorf("check_output_max_size must be positive, to discard check output use the discard_check_output flag")
 	}
+	if rt.CheckFlapDetectionHistorySize < 3 {
+		return fmt.Errorf("check_flap_detection.history_size cannot be %d. Must be at least 3", rt.CheckFlapDetectionHistorySize)
+	}
+	if rt.CheckFlapDetectionLowThreshold <= 0 || rt.CheckFlapDetectionHighThreshold > 1 || rt.CheckFlapDetectionLowThreshold > rt.CheckFlapDetectionHighThreshold {
+		return fmt.Errorf("check_flap_detection thresholds must satisfy 0 < low_threshold <= high_threshold <= 1")
+	}
 	if rt.AEInterval <= 0 {
 		return fmt.Errorf("ae_interval cannot be %s. Must be positive", rt.AEInterval)
 	}
@@ -1545,6 +1596,9 @@ func (b *builder) validate(rt RuntimeConfig) error {
 		// we leave this for consistency
 		return err
 	}
+	if err := addrsUnique(inuse, "DNS TLS", rt.DNSTLSAddrs); err != nil {
+		return err
+	}
 	if err := addrsUnique(inuse, "HTTP", rt.HTTPAddrs); err != nil {
 		return err
 	}
@@ -1750,9 +1804,19 @@ func (b *builder) checkVal(v *CheckDefinition) *structs.CheckDefinition {
 		Method:                         stringVal(v.Method),
 		Body:                           stringVal(v.Body),
 		DisableRedirects:               boolVal(v.DisableRedirects),
+		HTTPAssertions:                 b.httpCheckAssertionsVal(id, v.HTTPAssertions),
 		TCP:                            stringVal(v.TCP),
 		TCPUseTLS:                      boolVal(v.TCPUseTLS),
 		UDP:                            stringVal(v.UDP),
+		TLS:                            stringVal(v.TLS),
+		TLSExpiryWarningDays:           intVal(v.TLSExpiryWarningDays),
+		TLSExpiryCriticalDays:          intVal(v.TLSExpiryCriticalDays),
+		DNS:                            stringVal(v.DNS),
+		DNSQueryType:                   stringVal(v.DNSQueryType),
+		DNSResolver:                    stringVal(v.DNSResolver),
+		DNSExpectedValues:              v.DNSExpectedValues,
+		DNSRcode:                       stringVal(v.DNSRcode),
+		DNSMaxLatency:                  b.durationVal(fmt.Sprintf("check[%s].dns_max_latency", id), v.DNSMaxLatency),
 		Interval:                       b.durationVal(fmt.Sprintf("check[%s].interval", id), v.Interval),
 		DockerContainerID:              stringVal(v.DockerContainerID),
 		Shell:                          stringVal(v.Shell),
@@ -1767,6 +1831,7 @@ func (b *builder) checkVal(v *CheckDefinition) *structs.CheckDefinition {
 		SuccessBeforePassing:           intVal(v.SuccessBeforePassing),
 		FailuresBeforeCritical:         intVal(v.FailuresBeforeCritical),
 		FailuresBeforeWarning:          intValWithDefault(v.FailuresBeforeWarning, intVal(v.FailuresBeforeCritical)),
+		DependsOn:                      checkIDsVal(v.DependsOn),
 		H2PING:                         stringVal(v.H2PING),
 		H2PingUseTLS:                   H2PingUseTLSVal,
 		OSService:                      stringVal(v.OSService),
@@ -1776,6 +1841,25 @@ func (b *builder) checkVal(v *CheckDefinition) *structs.CheckDefinition {
 	}
 }
 
+func (b *builder) httpCheckAssertionsVal(id types.CheckID, v []HTTPAssertion) []structs.HTTPCheckAssertion {
+	if len(v) == 0 {
+		return nil
+	}
+
+	assertions := make([]structs.HTTPCheckAssertion, 0, len(v))
+	for i, a := range v {
+		assertions = append(assertions, structs.HTTPCheckAssertion{
+			Body:       stringVal(a.Body),
+			JSONPath:   stringVal(a.JSONPath),
+			Header:     stringVal(a.Header),
+			Value:      stringVal(a.Value),
+			MaxLatency: b.durationVal(fmt.Sprintf("check[%s].http_assertions[%d].max_latency", id, i), a.MaxLatency),
+			Status:     stringVal(a.Status),
+		})
+	}
+	return assertions
+}
+
 func (b *builder) svcTaggedAddresses(v map[string]ServiceAddress) map[string]structs.ServiceAddress {
 	if len(v) <= 0 {
 		return nil
@@ -2197,6 +2281,17 @@ func stringVal(v *string) string {
 	return *v
 }
 
+func checkIDsVal(v []string) []types.CheckID {
+	if len(v) == 0 {
+		return nil
+	}
+	ids := make([]types.CheckID, 0, len(v))
+	for _, id := range v {
+		ids = append(ids, types.CheckID(id))
+	}
+	return ids
+}
+
 func timeValWithDefault(v *time.Time, defaultVal time.Time) time.Time {
 	if v == nil {
 		return defaultVal
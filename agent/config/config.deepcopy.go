@@ -24,6 +24,24 @@ func (o *RuntimeConfig) DeepCopy() *RuntimeConfig {
 		cp.DNSRecursors = make([]string, len(o.DNSRecursors))
 		copy(cp.DNSRecursors, o.DNSRecursors)
 	}
+	if o.DNSZoneTransfer.AllowedCIDRs != nil {
+		cp.DNSZoneTransfer.AllowedCIDRs = make([]*net.IPNet, len(o.DNSZoneTransfer.AllowedCIDRs))
+		copy(cp.DNSZoneTransfer.AllowedCIDRs, o.DNSZoneTransfer.AllowedCIDRs)
+		for i3 := range o.DNSZoneTransfer.AllowedCIDRs {
+			if o.DNSZoneTransfer.AllowedCIDRs[i3] != nil {
+				cp.DNSZoneTransfer.AllowedCIDRs[i3] = new(net.IPNet)
+				*cp.DNSZoneTransfer.AllowedCIDRs[i3] = *o.DNSZoneTransfer.AllowedCIDRs[i3]
+				if o.DNSZoneTransfer.AllowedCIDRs[i3].IP != nil {
+					cp.DNSZoneTransfer.AllowedCIDRs[i3].IP = make([]byte, len(o.DNSZoneTransfer.AllowedCIDRs[i3].IP))
+					copy(cp.DNSZoneTransfer.AllowedCIDRs[i3].IP, o.DNSZoneTransfer.AllowedCIDRs[i3].IP)
+				}
+				if o.DNSZoneTransfer.AllowedCIDRs[i3].Mask != nil {
+					cp.DNSZoneTransfer.AllowedCIDRs[i3].Mask = make([]byte, len(o.DNSZoneTransfer.AllowedCIDRs[i3].Mask))
+					copy(cp.DNSZoneTransfer.AllowedCIDRs[i3].Mask, o.DNSZoneTransfer.AllowedCIDRs[i3].Mask)
+				}
+			}
+		}
+	}
 	if o.HTTPBlockEndpoints != nil {
 		cp.HTTPBlockEndpoints = make([]string, len(o.HTTPBlockEndpoints))
 		copy(cp.HTTPBlockEndpoints, o.HTTPBlockEndpoints)
@@ -152,6 +170,18 @@ func (o *RuntimeConfig) DeepCopy() *RuntimeConfig {
 						cp.Checks[i2].Header[k5] = cp_Checks_i2_Header_v5
 					}
 				}
+				if o.Checks[i2].HTTPAssertions != nil {
+					cp.Checks[i2].HTTPAssertions = make([]structs.HTTPCheckAssertion, len(o.Checks[i2].HTTPAssertions))
+					copy(cp.Checks[i2].HTTPAssertions, o.Checks[i2].HTTPAssertions)
+				}
+				if o.Checks[i2].DNSExpectedValues != nil {
+					cp.Checks[i2].DNSExpectedValues = make([]string, len(o.Checks[i2].DNSExpectedValues))
+					copy(cp.Checks[i2].DNSExpectedValues, o.Checks[i2].DNSExpectedValues)
+				}
+				if o.Checks[i2].DependsOn != nil {
+					cp.Checks[i2].DependsOn = make([]types.CheckID, len(o.Checks[i2].DependsOn))
+					copy(cp.Checks[i2].DependsOn, o.Checks[i2].DependsOn)
+				}
 			}
 		}
 	}
@@ -225,6 +255,14 @@ func (o *RuntimeConfig) DeepCopy() *RuntimeConfig {
 		cp.DNSAddrs = make([]net.Addr, len(o.DNSAddrs))
 		copy(cp.DNSAddrs, o.DNSAddrs)
 	}
+	if o.DNSTLSAddrs != nil {
+		cp.DNSTLSAddrs = make([]net.Addr, len(o.DNSTLSAddrs))
+		copy(cp.DNSTLSAddrs, o.DNSTLSAddrs)
+	}
+	if o.DNSSECKeyFiles != nil {
+		cp.DNSSECKeyFiles = make([]string, len(o.DNSSECKeyFiles))
+		copy(cp.DNSSECKeyFiles, o.DNSSECKeyFiles)
+	}
 	if o.GRPCAddrs != nil {
 		cp.GRPCAddrs = make([]net.Addr, len(o.GRPCAddrs))
 		copy(cp.GRPCAddrs, o.GRPCAddrs)
@@ -151,6 +151,7 @@ type Config struct {
 	BootstrapExpect                        *int                `mapstructure:"bootstrap_expect" json:"bootstrap_expect,omitempty"`
 	Cache                                  Cache               `mapstructure:"cache" json:"-"`
 	Check                                  *CheckDefinition    `mapstructure:"check" json:"-"` // needs to be a pointer to avoid partial merges
+	CheckFlapDetection                     CheckFlapDetection  `mapstructure:"check_flap_detection" json:"-"`
 	CheckOutputMaxSize                     *int                `mapstructure:"check_output_max_size" json:"check_output_max_size,omitempty"`
 	CheckUpdateInterval                    *string             `mapstructure:"check_update_interval" json:"check_update_interval,omitempty"`
 	Checks                                 []CheckDefinition   `mapstructure:"checks" json:"-"`
@@ -356,6 +357,7 @@ type Consul struct {
 
 type Addresses struct {
 	DNS     *string `mapstructure:"dns"`
+	DNSTLS  *string `mapstructure:"dns_tls"`
 	HTTP    *string `mapstructure:"http"`
 	HTTPS   *string `mapstructure:"https"`
 	GRPC    *string `mapstructure:"grpc"`
@@ -445,10 +447,20 @@ type CheckDefinition struct {
 	Method                         *string             `mapstructure:"method"`
 	Body                           *string             `mapstructure:"body"`
 	DisableRedirects               *bool               `mapstructure:"disable_redirects"`
+	HTTPAssertions                 []HTTPAssertion     `mapstructure:"http_assertions"`
 	OutputMaxSize                  *int                `mapstructure:"output_max_size"`
 	TCP                            *string             `mapstructure:"tcp"`
 	TCPUseTLS                      *bool               `mapstructure:"tcp_use_tls"`
 	UDP                            *string             `mapstructure:"udp"`
+	TLS                            *string             `mapstructure:"tls"`
+	TLSExpiryWarningDays           *int                `mapstructure:"tls_expiry_warning_days"`
+	TLSExpiryCriticalDays          *int                `mapstructure:"tls_expiry_critical_days"`
+	DNS                            *string             `mapstructure:"dns"`
+	DNSQueryType                   *string             `mapstructure:"dns_query_type"`
+	DNSResolver                    *string             `mapstructure:"dns_resolver"`
+	DNSExpectedValues              []string            `mapstructure:"dns_expected_values"`
+	DNSRcode                       *string             `mapstructure:"dns_rcode"`
+	DNSMaxLatency                  *string             `mapstructure:"dns_max_latency"`
 	Interval                       *string             `mapstructure:"interval"`
 	DockerContainerID              *string             `mapstructure:"docker_container_id" alias:"dockercontainerid"`
 	Shell                          *string             `mapstructure:"shell"`
@@ -466,11 +478,30 @@ type CheckDefinition struct {
 	SuccessBeforePassing           *int                `mapstructure:"success_before_passing"`
 	FailuresBeforeWarning          *int                `mapstructure:"failures_before_warning"`
 	FailuresBeforeCritical         *int                `mapstructure:"failures_before_critical"`
+	DependsOn                      []string            `mapstructure:"depends_on"`
 	DeregisterCriticalServiceAfter *string             `mapstructure:"deregister_critical_service_after" alias:"deregistercriticalserviceafter"`
 
 	EnterpriseMeta `mapstructure:",squash"`
 }
 
+// CheckFlapDetection configures the detection of the checks that are flapping
+type CheckFlapDetection struct {
+	Enabled       *bool    `mapstructure:"enabled"`
+	HistorySize   *int     `mapstructure:"history_size"`
+	LowThreshold  *float64 `mapstructure:"low_threshold"`
+	HighThreshold *float64 `mapstructure:"high_threshold"`
+}
+
+// HTTPAssertion is an assertion on the response of an HTTP check
+type HTTPAssertion struct {
+	Body       *string `mapstructure:"body"`
+	JSONPath   *string `mapstructure:"json_path"`
+	Header     *string `mapstructure:"header"`
+	Value      *string `mapstructure:"value"`
+	MaxLatency *string `mapstructure:"max_latency"`
+	Status     *string `mapstructure:"status"`
+}
+
 // ServiceConnect is the connect block within a service registration
 type ServiceConnect struct {
 	// Native is true when this service can natively understand Connect.
@@ -670,6 +701,29 @@ type SOA struct {
 	Minttl  *uint32 `mapstructure:"min_ttl"`
 }
 
+// DNSRecursorCache is the configuration of the cache in front of the DNS
+// recursors
+type DNSRecursorCache struct {
+	MaxEntries     *int    `mapstructure:"max_entries"`
+	MaxTTL         *string `mapstructure:"max_ttl"`
+	MaxNegativeTTL *string `mapstructure:"max_negative_ttl"`
+	Prefetch       *bool   `mapstructure:"prefetch"`
+}
+
+// DNSZoneTransfer is the configuration of the zone transfers of the Consul
+// domain
+type DNSZoneTransfer struct {
+	AllowedCIDRs []string `mapstructure:"allowed_cidrs"`
+	Token        *string  `mapstructure:"token"`
+	CacheTTL     *string  `mapstructure:"cache_ttl"`
+}
+
+// DNSSEC is the configuration of online DNSSEC signing for DNS
+type DNSSEC struct {
+	KeyFiles []string `mapstructure:"key_files"`
+	NSEC3    *bool    `mapstructure:"nsec3"`
+}
+
 type DNS struct {
 	AllowStale         *bool             `mapstructure:"allow_stale"`
 	ARecordLimit       *int              `mapstructure:"a_record_limit"`
@@ -679,13 +733,18 @@ type DNS struct {
 	NodeTTL            *string           `mapstructure:"node_ttl"`
 	OnlyPassing        *bool             `mapstructure:"only_passing"`
 	RecursorStrategy   *string           `mapstructure:"recursor_strategy"`
+	RecursorCache      *DNSRecursorCache `mapstructure:"recursor_cache"`
 	RecursorTimeout    *string           `mapstructure:"recursor_timeout"`
 	ServiceTTL         map[string]string `mapstructure:"service_ttl"`
 	UDPAnswerLimit     *int              `mapstructure:"udp_answer_limit"`
 	NodeMetaTXT        *bool             `mapstructure:"enable_additional_node_meta_txt"`
 	SOA                *SOA              `mapstructure:"soa"`
+	DNSSEC             *DNSSEC           `mapstructure:"dnssec"`
 	UseCache           *bool             `mapstructure:"use_cache"`
 	CacheMaxAge        *string           `mapstructure:"cache_max_age"`
+	ZoneTransfer       *DNSZoneTransfer  `mapstructure:"zone_transfer"`
+
+	AnswerOrderFromConfigEntries *bool `mapstructure:"answer_order_from_config_entries"`
 
 	// Enterprise Only
 	PreferNamespace *bool `mapstructure:"prefer_namespace"`
@@ -752,6 +811,7 @@ type CertificateTelemetry struct {
 
 type Ports struct {
 	DNS            *int `mapstructure:"dns" json:"dns,omitempty"`
+	DNSTLS         *int `mapstructure:"dns_tls" json:"dns_tls,omitempty"`
 	HTTP           *int `mapstructure:"http" json:"http,omitempty"`
 	HTTPS          *int `mapstructure:"https" json:"https,omitempty"`
 	SerfLAN        *int `mapstructure:"serf_lan" json:"serf_lan,omitempty"`
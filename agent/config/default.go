@@ -11,6 +11,7 @@ import (
 
 	"github.com/hashicorp/consul/agent/checks"
 	"github.com/hashicorp/consul/agent/consul"
+	"github.com/hashicorp/consul/agent/local/flap"
 	"github.com/hashicorp/consul/version"
 )
 
@@ -37,6 +38,12 @@ func DefaultSource() Source {
 		bootstrap_expect = 0
 		check_output_max_size = ` + strconv.Itoa(checks.DefaultBufSize) + `
 		check_update_interval = "5m"
+		check_flap_detection = {
+			enabled = false
+			history_size = ` + strconv.Itoa(flap.DefaultHistorySize) + `
+			low_threshold = ` + strconv.FormatFloat(flap.DefaultLowThreshold, 'f', -1, 64) + `
+			high_threshold = ` + strconv.FormatFloat(flap.DefaultHighThreshold, 'f', -1, 64) + `
+		}
 		client_addr = "127.0.0.1"
 		datacenter = "` + consul.DefaultDC + `"
 		default_query_time = "300s"
@@ -125,6 +132,7 @@ func DefaultSource() Source {
 		}
 		ports = {
 			dns = 8600
+			dns_tls = -1
 			http = 8500
 			https = -1
 			grpc = -1
@@ -33,6 +33,35 @@ type RuntimeSOAConfig struct {
 	Minttl  uint32 // 0,
 }
 
+type RuntimeDNSRecursorCacheConfig struct {
+	// Size is the maximum number of responses from the DNS recursors that
+	// are cached. Setting this to 0 disables the cache.
+	Size int
+	// MaxTTL caps the time a positive response is cached, whatever the TTL
+	// of its records.
+	MaxTTL time.Duration
+	// MaxNegativeTTL caps the time a negative response is cached. The TTL of
+	// negative responses is otherwise taken from their SOA record, as
+	// described in RFC 2308.
+	MaxNegativeTTL time.Duration
+	// Prefetch controls whether cached responses that are frequently
+	// requested are refreshed in the background shortly before they expire.
+	Prefetch bool
+}
+
+type RuntimeDNSZoneTransferConfig struct {
+	// AllowedCIDRs is the list of networks allowed to transfer the zone of
+	// the Consul domain. Zone transfers are disabled when it is empty.
+	AllowedCIDRs []*net.IPNet
+	// Token is the ACL token used to read the catalog when materializing the
+	// zone. The DNS token of the agent is used when it is not set.
+	Token string
+	// CacheTTL is how long a zone built from the catalog is reused to answer
+	// the zone transfers and SOA queries. Setting this to 0 disables the
+	// cache.
+	CacheTTL time.Duration
+}
+
 // StaticRuntimeConfig specifies the subset of configuration the consul agent actually
 // uses and that are not reloadable by configuration auto reload.
 type StaticRuntimeConfig struct {
@@ -200,6 +229,13 @@ type RuntimeConfig struct {
 	// hcl: dns_config { disable_compression = (true|false) }
 	DNSDisableCompression bool
 
+	// DNSAnswerOrderFromConfigEntries enables the dns_answer_order field of
+	// the service-defaults config entries. When it is disabled service
+	// lookups don't fetch the config entry of the service.
+	//
+	// hcl: dns_config { answer_order_from_config_entries = (true|false) }
+	DNSAnswerOrderFromConfigEntries bool
+
 	// DNSDomain is the DNS domain for the records. Should end with a dot.
 	// Defaults to "consul."
 	//
@@ -254,6 +290,12 @@ type RuntimeConfig struct {
 	// hcl: dns_config { recursor_strategy = "(random|sequential)" }
 	DNSRecursorStrategy structs.RecursorStrategy
 
+	// DNSRecursorCache configures the cache of the responses from the DNS
+	// recursors.
+	//
+	// hcl: dns_config { recursor_cache { max_entries = int max_ttl = "duration" max_negative_ttl = "duration" prefetch = (true|false) } }
+	DNSRecursorCache RuntimeDNSRecursorCacheConfig
+
 	// DNSRecursorTimeout specifies the timeout in seconds
 	// for Consul's internal dns client used for recursion.
 	// This value is used for the connection, read and write timeout.
@@ -300,6 +342,12 @@ type RuntimeConfig struct {
 	// hcl: dns_config { cache_max_age = "duration" }
 	DNSCacheMaxAge time.Duration
 
+	// DNSZoneTransfer configures the AXFR and IXFR zone transfers of the
+	// Consul domain.
+	//
+	// hcl: dns_config { zone_transfer { allowed_cidrs = []string token = string } }
+	DNSZoneTransfer RuntimeDNSZoneTransferConfig
+
 	// HTTPUseCache whether or not to use cache for http queries. Defaults
 	// to true.
 	//
@@ -420,6 +468,32 @@ type RuntimeConfig struct {
 	// flag: -check_output_max_size int
 	CheckOutputMaxSize int
 
+	// CheckFlapDetectionEnabled enables the detection of the checks that are
+	// flapping, that is changing status too often for their status to be
+	// meaningful. The status of a flapping check is held steady until it
+	// stabilizes, to avoid churn in the catalog and the proxies.
+	//
+	// hcl: check_flap_detection { enabled = (true|false) }
+	CheckFlapDetectionEnabled bool
+
+	// CheckFlapDetectionHistorySize is the number of results of a check its
+	// flap score is computed over.
+	//
+	// hcl: check_flap_detection { history_size = int }
+	CheckFlapDetectionHistorySize int
+
+	// CheckFlapDetectionLowThreshold is the flap score, between 0 and 1,
+	// below which a flapping check stops flapping.
+	//
+	// hcl: check_flap_detection { low_threshold = float64 }
+	CheckFlapDetectionLowThreshold float64
+
+	// CheckFlapDetectionHighThreshold is the flap score, between 0 and 1,
+	// from which a check starts flapping.
+	//
+	// hcl: check_flap_detection { high_threshold = float64 }
+	CheckFlapDetectionHighThreshold float64
+
 	// Checks contains the provided check definitions.
 	//
 	// hcl: checks = [
@@ -560,10 +634,48 @@ type RuntimeConfig struct {
 	// flags: -dns-port int
 	DNSPort int
 
+	// DNSTLSAddrs contains the list of TCP addresses the DNS over TLS server
+	// (RFC 7858) will bind to. If the endpoint is disabled (ports.dns_tls <= 0)
+	// the list is empty.
+	//
+	// The ip addresses are taken from 'addresses.dns_tls' which should contain
+	// a space separated list of ip addresses and/or go-sockaddr templates.
+	//
+	// If 'addresses.dns_tls' was not provided the 'client_addr' addresses are
+	// used.
+	//
+	// The server uses the certificates configured for HTTPS and cannot be
+	// bound to UNIX sockets.
+	//
+	// hcl: client_addr = string addresses { dns_tls = string } ports { dns_tls = int }
+	DNSTLSAddrs []net.Addr
+
+	// DNSTLSPort is the port the DNS over TLS server listens on. It is
+	// disabled by default.
+	//
+	// hcl: ports { dns_tls = int }
+	DNSTLSPort int
+
 	// DNSSOA is the settings applied for DNS SOA
 	// hcl: soa {}
 	DNSSOA RuntimeSOAConfig
 
+	// DNSSECKeyFiles is the list of zone keys used to sign responses for the
+	// Consul domain. Each entry is the path of a key in BIND format, either
+	// with or without the ".key" extension; the private key is read from the
+	// file with the same name and a ".private" extension. Keys that have the
+	// SEP flag set sign the DNSKEY RRset, the others sign everything else. When
+	// empty, DNSSEC signing is disabled.
+	//
+	// hcl: dns_config { dnssec { key_files = []string } }
+	DNSSECKeyFiles []string
+
+	// DNSSECNSEC3 configures the DNS server to use NSEC3 records instead of
+	// NSEC records to prove the non-existence of names and record types.
+	//
+	// hcl: dns_config { dnssec { nsec3 = (true|false) } }
+	DNSSECNSEC3 bool
+
 	// DataDir is the path to the directory where the local state is stored.
 	//
 	// hcl: data_dir = string
@@ -1861,6 +1861,26 @@ func TestLoad_IntegrationWithFlags(t *testing.T) {
 		hcl:         []string{`autopilot = { max_trailing_logs = -1 }`},
 		expectedErr: "autopilot.max_trailing_logs cannot be -1. Must be greater than or equal to zero",
 	})
+	run(t, testCase{
+		desc: "check_flap_detection.history_size invalid",
+		args: []string{
+			`-datacenter=a`,
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "check_flap_detection": { "history_size": 2 } }`},
+		hcl:         []string{`check_flap_detection = { history_size = 2 }`},
+		expectedErr: "check_flap_detection.history_size cannot be 2. Must be at least 3",
+	})
+	run(t, testCase{
+		desc: "check_flap_detection thresholds invalid",
+		args: []string{
+			`-datacenter=a`,
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "check_flap_detection": { "low_threshold": 0.6, "high_threshold": 0.4 } }`},
+		hcl:         []string{`check_flap_detection = { low_threshold = 0.6 high_threshold = 0.4 }`},
+		expectedErr: "check_flap_detection thresholds must satisfy 0 < low_threshold <= high_threshold <= 1",
+	})
 	run(t, testCase{
 		desc:        "bind_addr cannot be empty",
 		args:        []string{`-data-dir=` + dataDir},
@@ -1875,6 +1895,13 @@ func TestLoad_IntegrationWithFlags(t *testing.T) {
 		hcl:         []string{`bind_addr = "1.1.1.1 2.2.2.2"`},
 		expectedErr: "bind_addr cannot contain multiple addresses",
 	})
+	run(t, testCase{
+		desc:        "dns_tls port requires a certificate",
+		args:        []string{`-data-dir=` + dataDir},
+		json:        []string{`{ "ports": { "dns_tls": 8853 } }`},
+		hcl:         []string{`ports { dns_tls = 8853 }`},
+		expectedErr: "ports.dns_tls requires a certificate",
+	})
 	run(t, testCase{
 		desc:        "bind_addr cannot be a unix socket",
 		args:        []string{`-data-dir=` + dataDir},
@@ -2593,7 +2620,7 @@ func TestLoad_IntegrationWithFlags(t *testing.T) {
 		hcl: []string{
 			`check = { name = "a", os_service = "foo" }`,
 		},
-		expectedErr: `Interval must be > 0 for Script, HTTP, H2PING, TCP, UDP or OSService checks`,
+		expectedErr: `Interval must be > 0 for Script, HTTP, H2PING, TCP, UDP, TLS, DNS or OSService checks`,
 	})
 	run(t, testCase{
 		desc: "os_service check",
@@ -6454,6 +6481,11 @@ func TestLoad_FullConfig(t *testing.T) {
 			EntryFetchMaxBurst: 42,
 			EntryFetchRate:     0.334,
 		},
+		CheckFlapDetectionEnabled:       true,
+		CheckFlapDetectionHistorySize:   31,
+		CheckFlapDetectionLowThreshold:  0.2,
+		CheckFlapDetectionHighThreshold: 0.6,
+
 		CheckOutputMaxSize: checks.DefaultBufSize,
 		Checks: []*structs.CheckDefinition{
 			{
@@ -6469,10 +6501,24 @@ func TestLoad_FullConfig(t *testing.T) {
 					"ZBfTin3L": {"1sDbEqYG", "lJGASsWK"},
 					"Ui0nU99X": {"LMccm3Qe", "k5H5RggQ"},
 				},
+				HTTPAssertions: []structs.HTTPCheckAssertion{
+					{JSONPath: "$.status", Value: "ok", Status: "warning"},
+					{MaxLatency: 1500 * time.Millisecond},
+				},
 				Method:                         "aldrIQ4l",
 				Body:                           "wSjTy7dg",
 				DisableRedirects:               true,
 				TCP:                            "RJQND605",
+				TLS:                            "kT2bX1sp",
+				TLSExpiryWarningDays:           21,
+				TLSExpiryCriticalDays:          5,
+				DNS:                            "Hs4bxbPc",
+				DNSQueryType:                   "AAAA",
+				DNSResolver:                    "10.0.0.53",
+				DNSExpectedValues:              []string{"fd00::1", "fd00::2"},
+				DNSRcode:                       "NOERROR",
+				DNSMaxLatency:                  250 * time.Millisecond,
+				DependsOn:                      []types.CheckID{"Cqq95BhP"},
 				TCPUseTLS:                      false,
 				H2PING:                         "9N1cSb5B",
 				H2PingUseTLS:                   false,
@@ -6620,6 +6666,7 @@ func TestLoad_FullConfig(t *testing.T) {
 		ConnectMeshGatewayWANFederationEnabled: false,
 		DNSAddrs:                               []net.Addr{tcpAddr("93.95.95.81:7001"), udpAddr("93.95.95.81:7001")},
 		DNSARecordLimit:                        29907,
+		DNSAnswerOrderFromConfigEntries:        true,
 		DNSAllowStale:                          true,
 		DNSDisableCompression:                  true,
 		DNSDomain:                              "7W1xXSqd",
@@ -6629,15 +6676,21 @@ func TestLoad_FullConfig(t *testing.T) {
 		DNSNodeTTL:                             7084 * time.Second,
 		DNSOnlyPassing:                         true,
 		DNSPort:                                7001,
+		DNSTLSAddrs:                            []net.Addr{tcpAddr("38.12.72.17:7002")},
+		DNSTLSPort:                             7002,
 		DNSRecursorStrategy:                    "sequential",
+		DNSRecursorCache:                       RuntimeDNSRecursorCacheConfig{Size: 4096, MaxTTL: 30 * time.Minute, MaxNegativeTTL: 90 * time.Second},
 		DNSRecursorTimeout:                     4427 * time.Second,
 		DNSRecursors:                           []string{"63.38.39.58", "92.49.18.18"},
 		DNSSOA:                                 RuntimeSOAConfig{Refresh: 3600, Retry: 600, Expire: 86400, Minttl: 0},
+		DNSSECKeyFiles:                         []string{"/etc/consul/Kconsul.+013+40410"},
+		DNSSECNSEC3:                            true,
 		DNSServiceTTL:                          map[string]time.Duration{"*": 32030 * time.Second},
 		DNSUDPAnswerLimit:                      29909,
 		DNSNodeMetaTXT:                         true,
 		DNSUseCache:                            true,
 		DNSCacheMaxAge:                         5 * time.Minute,
+		DNSZoneTransfer:                        RuntimeDNSZoneTransferConfig{AllowedCIDRs: []*net.IPNet{cidr("10.0.0.0/8")}, Token: "dd1e9c8e-7a3c-4bbf-8e1b-6e7b6bbf2a41", CacheTTL: 15 * time.Second},
 		DataDir:                                dataDir,
 		Datacenter:                             "rzo029wg",
 		DefaultQueryTime:                       16743 * time.Second,
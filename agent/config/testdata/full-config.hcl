@@ -44,6 +44,7 @@ acl = {
 }
 addresses = {
     dns = "93.95.95.81"
+    dns_tls = "38.12.72.17"
     http = "83.39.91.39"
     https = "95.17.17.19"
     grpc = "32.31.61.91"
@@ -145,7 +146,27 @@ checks = [
         method = "aldrIQ4l"
         body = "wSjTy7dg"
         disable_redirects = true
+        http_assertions = [
+            {
+                json_path = "$.status"
+                value = "ok"
+                status = "warning"
+            },
+            {
+                max_latency = "1500ms"
+            }
+        ]
         tcp = "RJQND605"
+        tls = "kT2bX1sp"
+        tls_expiry_warning_days = 21
+        tls_expiry_critical_days = 5
+        dns = "Hs4bxbPc"
+        dns_query_type = "AAAA"
+        dns_resolver = "10.0.0.53"
+        dns_expected_values = ["fd00::1", "fd00::2"]
+        dns_rcode = "NOERROR"
+        dns_max_latency = "250ms"
+        depends_on = ["Cqq95BhP"]
         h2ping = "9N1cSb5B"
         h2ping_use_tls = false
         interval = "22164s"
@@ -189,6 +210,12 @@ checks = [
     }
 ]
 check_update_interval = "16507s"
+check_flap_detection = {
+    enabled = true
+    history_size = 31
+    low_threshold = 0.2
+    high_threshold = 0.6
+}
 client_addr = "93.83.18.19"
 config_entries {
     # This is using the repeated block-to-array HCL magic
@@ -261,18 +288,34 @@ alt_domain = "1789hsd"
 dns_config {
     allow_stale = true
     a_record_limit = 29907
+    answer_order_from_config_entries = true
     disable_compression = true
     enable_truncate = true
     max_stale = "29685s"
     node_ttl = "7084s"
     only_passing = true
     recursor_timeout = "4427s"
+    recursor_cache {
+        max_entries = 4096
+        max_ttl = "30m"
+        max_negative_ttl = "90s"
+        prefetch = false
+    }
     service_ttl = {
         "*" = "32030s"
     }
     udp_answer_limit = 29909
     use_cache = true
     cache_max_age = "5m"
+    dnssec {
+        key_files = ["/etc/consul/Kconsul.+013+40410"]
+        nsec3 = true
+    }
+    zone_transfer {
+        allowed_cidrs = ["10.0.0.0/8"]
+        token = "dd1e9c8e-7a3c-4bbf-8e1b-6e7b6bbf2a41"
+        cache_ttl = "15s"
+    }
     prefer_namespace = true
 }
 enable_acl_replication = true
@@ -348,6 +391,7 @@ performance {
 pid_file = "43xN80Km"
 ports {
     dns = 7001
+    dns_tls = 7002
     http = 7999
     https = 15127
     server = 3757
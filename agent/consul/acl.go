@@ -181,6 +181,10 @@ type ACLResolverConfig struct {
 
 	// Tokens is the token store of locally managed tokens
 	Tokens *token.Store
+
+	// TokenUsed, if set, is called with every token successfully resolved
+	// through the backend. Servers use it to track when tokens were last used.
+	TokenUsed func(token *structs.ACLToken)
 }
 
 const aclClientDisabledTTL = 30 * time.Second
@@ -284,6 +288,8 @@ type ACLResolver struct {
 
 	tokens *token.Store
 
+	tokenUsed func(token *structs.ACLToken)
+
 	cache         *structs.ACLCaches
 	identityGroup singleflight.Group
 	policyGroup   singleflight.Group
@@ -371,6 +377,7 @@ func NewACLResolver(config *ACLResolverConfig) (*ACLResolver, error) {
 		disableDuration:    config.DisableDuration,
 		down:               down,
 		tokens:             config.Tokens,
+		tokenUsed:          config.TokenUsed,
 		agentRecoveryAuthz: authz,
 	}, nil
 }
@@ -1055,6 +1062,13 @@ func (r *ACLResolver) resolveLocallyManagedToken(token string) (structs.ACLIdent
 // can be used to check permissions granted to the token using its secret, and the
 // ACLIdentity describes the token and any defaults applied to it.
 func (r *ACLResolver) ResolveToken(tokenSecretID string) (resolver.Result, error) {
+	return r.resolveToken(tokenSecretID, nil)
+}
+
+// resolveToken resolves the token for the given request, leaving out the
+// policy rules whose conditions the request doesn't meet. When req is nil
+// only the time based conditions can be met.
+func (r *ACLResolver) resolveToken(tokenSecretID string, req *acl.RequestContext) (resolver.Result, error) {
 	if !r.ACLsEnabled() {
 		return resolver.Result{Authorizer: acl.ManageAll()}, nil
 	}
@@ -1086,6 +1100,10 @@ func (r *ACLResolver) ResolveToken(tokenSecretID string) (resolver.Result, error
 		return resolver.Result{}, err
 	}
 
+	if token, ok := identity.(*structs.ACLToken); ok && r.tokenUsed != nil {
+		r.tokenUsed(token)
+	}
+
 	// Build the Authorizer
 	var chain []acl.Authorizer
 	var conf acl.Config
@@ -1094,7 +1112,7 @@ func (r *ACLResolver) ResolveToken(tokenSecretID string) (resolver.Result, error
 	}
 	setEnterpriseConf(identity.EnterpriseMetadata(), &conf)
 
-	authz, err := policies.Compile(r.cache, &conf)
+	authz, validUntil, err := policies.CompileForRequest(r.cache, &conf, req)
 	if err != nil {
 		return resolver.Result{}, err
 	}
@@ -1112,7 +1130,11 @@ func (r *ACLResolver) ResolveToken(tokenSecretID string) (resolver.Result, error
 	}
 
 	chain = append(chain, acl.RootAuthorizer(r.config.ACLDefaultPolicy))
-	return resolver.Result{Authorizer: acl.NewChainedAuthorizer(chain), ACLIdentity: identity}, nil
+	return resolver.Result{
+		Authorizer:  acl.NewChainedAuthorizer(chain),
+		ACLIdentity: identity,
+		ValidUntil:  validUntil,
+	}, nil
 }
 
 func (r *ACLResolver) ACLsEnabled() bool {
@@ -1136,7 +1158,7 @@ func (r *ACLResolver) ResolveTokenAndDefaultMeta(
 	entMeta *acl.EnterpriseMeta,
 	authzContext *acl.AuthorizerContext,
 ) (resolver.Result, error) {
-	result, err := r.ResolveToken(tokenSecretID)
+	result, err := r.resolveToken(tokenSecretID, authzContext.RequestOrNil())
 	if err != nil {
 		return resolver.Result{}, err
 	}
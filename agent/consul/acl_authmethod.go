@@ -11,7 +11,9 @@ import (
 
 	// register these as a builtin auth method
 	_ "github.com/hashicorp/consul/agent/consul/authmethod/awsauth"
+	_ "github.com/hashicorp/consul/agent/consul/authmethod/certauth"
 	_ "github.com/hashicorp/consul/agent/consul/authmethod/kubeauth"
+	_ "github.com/hashicorp/consul/agent/consul/authmethod/ldapauth"
 	_ "github.com/hashicorp/consul/agent/consul/authmethod/ssoauth"
 )
 
@@ -657,6 +657,40 @@ func (a *ACL) TokenDelete(args *structs.ACLTokenDeleteRequest, reply *string) er
 	return nil
 }
 
+// TokenLastUsedSet records when and where tokens were last used. It is only
+// meant for servers flushing the token usage they tracked to the leader, so it
+// requires the server management token. Servers in secondary datacenters send
+// the usage of global tokens to the primary datacenter with their replication
+// token instead, so a token allowed to write ACLs is accepted as well.
+func (a *ACL) TokenLastUsedSet(args *structs.ACLTokenLastUsedSetRequest, reply *struct{}) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.TokenLastUsedSet", args, reply); done {
+		return err
+	}
+
+	result, err := a.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if _, ok := result.ACLIdentity.(*structs.ACLServerIdentity); !ok {
+		var authzContext acl.AuthorizerContext
+		if err := result.ToAllowAuthorizer().ACLWriteAllowed(&authzContext); err != nil {
+			return err
+		}
+	}
+
+	// We set the "safe to ignore" flag on this update type so old servers
+	// don't crash if they see one of these.
+	t := structs.ACLTokenLastUsedSetRequestType | structs.IgnoreUnknownTypeFlag
+	if _, err := a.srv.raftApply(t, args); err != nil {
+		return fmt.Errorf("Failed to apply token last used request: %v", err)
+	}
+	return nil
+}
+
 func (a *ACL) TokenList(args *structs.ACLTokenListRequest, reply *structs.ACLTokenListResponse) error {
 	if err := a.aclPreCheck(); err != nil {
 		return err
@@ -364,7 +364,42 @@ func (s *Server) fetchACLTokens(lastRemoteIndex uint64) (*structs.ACLTokenListRe
 
 func (s *Server) replicateACLTokens(ctx context.Context, logger hclog.Logger, lastRemoteIndex uint64) (uint64, bool, error) {
 	tr := &aclTokenReplicator{}
-	return s.replicateACLType(ctx, logger, tr, lastRemoteIndex)
+	index, exit, err := s.replicateACLType(ctx, logger, tr, lastRemoteIndex)
+	if exit || err != nil {
+		return index, exit, err
+	}
+
+	// Global tokens are used in every datacenter but their last use is only
+	// recorded in the primary, so bring it over as well. This doesn't change
+	// the remote index, so it's picked up whenever the blocking query returns.
+	if entries := tr.LastUsedUpdates(); len(entries) > 0 {
+		if err := s.updateLocalACLTokensLastUsed(entries); err != nil {
+			return 0, false, fmt.Errorf("failed to update local ACL token last use: %v", err)
+		}
+		logger.Debug("acl replication - updated token last use", "amount", len(entries))
+	}
+	return index, false, nil
+}
+
+// updateLocalACLTokensLastUsed records the last use of replicated tokens in
+// batches.
+func (s *Server) updateLocalACLTokensLastUsed(entries []*structs.ACLTokenLastUsedEntry) error {
+	batchSize := s.config.ACLTokenLastUsedUpdateBatchSize
+	for start := 0; start < len(entries); start += batchSize {
+		end := start + batchSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		req := structs.ACLTokenLastUsedSetRequest{
+			Entries: entries[start:end],
+		}
+		t := structs.ACLTokenLastUsedSetRequestType | structs.IgnoreUnknownTypeFlag
+		if _, err := s.leaderRaftApply("ACL.TokenLastUsedSet", t, &req); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *Server) replicateACLPolicies(ctx context.Context, logger hclog.Logger, lastRemoteIndex uint64) (uint64, bool, error) {
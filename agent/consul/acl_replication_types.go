@@ -125,6 +125,32 @@ func (r *aclTokenReplicator) UpdateLocalBatch(ctx context.Context, srv *Server,
 	return err
 }
 
+// LastUsedUpdates returns the last use of the remote tokens that is more
+// recent than the one recorded locally. The last use of a token isn't part of
+// its hash, so it isn't brought over by the regular updates.
+func (r *aclTokenReplicator) LastUsedUpdates() []*structs.ACLTokenLastUsedEntry {
+	local := make(map[string]*structs.ACLToken, len(r.local))
+	for _, token := range r.local {
+		local[token.AccessorID] = token
+	}
+
+	var entries []*structs.ACLTokenLastUsedEntry
+	for _, stub := range r.remote {
+		if stub.LastUsed == nil {
+			continue
+		}
+		token, ok := local[stub.AccessorID]
+		if ok && token.LastUsed != nil && !token.LastUsed.Time.Before(stub.LastUsed.Time) {
+			continue
+		}
+		entries = append(entries, &structs.ACLTokenLastUsedEntry{
+			AccessorID: stub.AccessorID,
+			LastUsed:   *stub.LastUsed,
+		})
+	}
+	return entries
+}
+
 ///////////////////////
 
 type aclPolicyReplicator struct {
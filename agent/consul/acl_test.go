@@ -6,6 +6,7 @@ package consul
 import (
 	"context"
 	"fmt"
+	"net"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -224,6 +225,22 @@ func testIdentityForToken(token string) (bool, structs.ACLIdentity, error) {
 				},
 			},
 		}, nil
+	case "break-glass":
+		return true, &structs.ACLToken{
+			AccessorID: "d1bd7e4b-8f6e-4a35-9b3c-2a0c3f1d5e8a",
+			SecretID:   "break-glass",
+			Policies: []structs.ACLTokenPolicyLink{
+				{
+					ID: "node-wr",
+				},
+				{
+					ID: "bastion-key-wr",
+				},
+				{
+					ID: "expired-acl-wr",
+				},
+			},
+		}, nil
 	default:
 		return true, nil, acl.ErrNotFound
 	}
@@ -293,6 +310,30 @@ func testPolicyForID(policyID string) (bool, *structs.ACLPolicy, error) {
 		}
 		p.SetHash(false)
 		return true, p, nil
+	case "bastion-key-wr":
+		p := &structs.ACLPolicy{
+			ID:          "bastion-key-wr",
+			Name:        "bastion-key-wr",
+			Description: "bastion-key-wr",
+			Rules:       `agent_prefix "" { policy = "write" source_cidrs = ["10.0.0.0/24"] }`,
+			RaftIndex:   structs.RaftIndex{CreateIndex: 1, ModifyIndex: 2},
+		}
+		p.SetHash(false)
+		return true, p, nil
+	case "expired-acl-wr":
+		p := &structs.ACLPolicy{
+			ID:          "expired-acl-wr",
+			Name:        "expired-acl-wr",
+			Description: "expired-acl-wr",
+			Rules: `
+				conditions {
+					valid_between = ["2020-01-01T00:00:00Z", "2020-01-02T00:00:00Z"]
+				}
+				acl = "write"`,
+			RaftIndex: structs.RaftIndex{CreateIndex: 1, ModifyIndex: 2},
+		}
+		p.SetHash(false)
+		return true, p, nil
 	default:
 		return true, nil, acl.ErrNotFound
 	}
@@ -1434,6 +1475,58 @@ func TestACLResolver_DatacenterScoping(t *testing.T) {
 	})
 }
 
+func TestACLResolver_RuleConditions(t *testing.T) {
+	t.Parallel()
+	delegate := &ACLResolverTestDelegate{
+		enabled:       true,
+		datacenter:    "dc1",
+		localTokens:   true,
+		localPolicies: true,
+		localRoles:    true,
+		// No need to provide any of the RPC callbacks
+	}
+	r := newTestACLResolver(t, delegate, nil)
+
+	t.Run("unknown source", func(t *testing.T) {
+		authz, err := r.ResolveToken("break-glass")
+		require.NoError(t, err)
+		require.Equal(t, acl.Allow, authz.NodeWrite("foo", nil))
+		require.Equal(t, acl.Deny, authz.AgentWrite("foo", nil))
+		require.Equal(t, acl.Deny, authz.ACLWrite(nil))
+	})
+
+	t.Run("outside bastion subnet", func(t *testing.T) {
+		authzContext := &acl.AuthorizerContext{
+			Request: &acl.RequestContext{SourceAddr: net.ParseIP("172.16.0.7")},
+		}
+		authz, err := r.ResolveTokenAndDefaultMeta("break-glass", nil, authzContext)
+		require.NoError(t, err)
+		require.Equal(t, acl.Allow, authz.NodeWrite("foo", nil))
+		require.Equal(t, acl.Deny, authz.AgentWrite("foo", nil))
+	})
+
+	t.Run("from bastion subnet", func(t *testing.T) {
+		authzContext := &acl.AuthorizerContext{
+			Request: &acl.RequestContext{SourceAddr: net.ParseIP("10.0.0.7")},
+		}
+		authz, err := r.ResolveTokenAndDefaultMeta("break-glass", nil, authzContext)
+		require.NoError(t, err)
+		require.Equal(t, acl.Allow, authz.NodeWrite("foo", nil))
+		require.Equal(t, acl.Allow, authz.AgentWrite("foo", nil))
+		require.Equal(t, acl.Deny, authz.ACLWrite(nil))
+	})
+
+	t.Run("during window", func(t *testing.T) {
+		authzContext := &acl.AuthorizerContext{
+			Request: &acl.RequestContext{Time: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)},
+		}
+		authz, err := r.ResolveTokenAndDefaultMeta("break-glass", nil, authzContext)
+		require.NoError(t, err)
+		require.Equal(t, acl.Allow, authz.ACLWrite(nil))
+		require.Equal(t, acl.Deny, authz.AgentWrite("foo", nil))
+	})
+}
+
 // TODO(rb): replicate this sort of test but for roles
 func TestACLResolver_Client(t *testing.T) {
 	if testing.Short() {
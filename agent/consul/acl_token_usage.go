@@ -0,0 +1,184 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package consul
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/logging"
+)
+
+// aclTokenUsage tracks the tokens this server resolves and periodically sends
+// when and where they were last used to the leader, so tokens that aren't in
+// use anymore can be found and cleaned up. Usage of local tokens is sent to
+// this datacenter's leader, while usage of global tokens is sent to the
+// primary datacenter where they are managed.
+type aclTokenUsage struct {
+	// srv is a pointer back to the server.
+	srv *Server
+
+	logger hclog.Logger
+
+	// pending holds the token usage not yet sent, keyed by the datacenter it
+	// is sent to and then by the token's accessor ID.
+	pending map[string]map[string]*structs.ACLTokenLastUsedEntry
+
+	// recorded holds when this server last recorded the use of each token.
+	// The last use of global tokens is only replicated back to the secondary
+	// datacenters after a while, so the token's own LastUsed isn't enough to
+	// keep them from being sent on every use.
+	recorded map[string]time.Time
+
+	// pendingLock synchronizes access to the pending and recorded maps.
+	pendingLock sync.Mutex
+}
+
+// newACLTokenUsage returns a new aclTokenUsage for the given server.
+func newACLTokenUsage(srv *Server, logger hclog.Logger) *aclTokenUsage {
+	u := &aclTokenUsage{
+		srv:      srv,
+		logger:   logger.Named(logging.ACL),
+		pending:  make(map[string]map[string]*structs.ACLTokenLastUsedEntry),
+		recorded: make(map[string]time.Time),
+	}
+
+	go u.batchUpdate()
+	return u
+}
+
+// record notes that the given token was just resolved. It is a no-op if the
+// last use already recorded for the token is within the tracking granularity,
+// which keeps this cheap for the tokens resolved on every request.
+func (u *aclTokenUsage) record(token *structs.ACLToken) {
+	now := time.Now()
+	granularity := u.srv.config.ACLTokenLastUsedGranularity
+	if token.LastUsed != nil && now.Sub(token.LastUsed.Time) < granularity {
+		return
+	}
+
+	datacenter := u.srv.config.Datacenter
+	if !token.Local && u.srv.config.PrimaryDatacenter != "" {
+		datacenter = u.srv.config.PrimaryDatacenter
+	}
+
+	u.pendingLock.Lock()
+	defer u.pendingLock.Unlock()
+
+	if recorded, ok := u.recorded[token.AccessorID]; ok && now.Sub(recorded) < granularity {
+		return
+	}
+	u.recorded[token.AccessorID] = now
+
+	pending, ok := u.pending[datacenter]
+	if !ok {
+		pending = make(map[string]*structs.ACLTokenLastUsedEntry)
+		u.pending[datacenter] = pending
+	}
+	pending[token.AccessorID] = &structs.ACLTokenLastUsedEntry{
+		AccessorID: token.AccessorID,
+		LastUsed: structs.ACLTokenLastUsed{
+			Time:       now,
+			Server:     u.srv.config.NodeName,
+			Datacenter: u.srv.config.Datacenter,
+		},
+	}
+}
+
+// batchUpdate is a long-running routine that sends the pending token usage to
+// the leader once per period.
+func (u *aclTokenUsage) batchUpdate() {
+	for {
+		select {
+		case <-time.After(u.srv.config.ACLTokenLastUsedUpdatePeriod):
+			if err := u.flush(); err != nil {
+				u.logger.Warn("Failed to record ACL token usage", "error", err)
+			}
+		case <-u.srv.shutdownCh:
+			return
+		}
+	}
+}
+
+// flush sends up to one batch of pending token usage to each datacenter. Usage
+// that fails to be sent is dropped, it will be recorded again the next time the
+// token is used.
+func (u *aclTokenUsage) flush() error {
+	u.pendingLock.Lock()
+	batches := make(map[string][]*structs.ACLTokenLastUsedEntry, len(u.pending))
+	for datacenter, pending := range u.pending {
+		entries := make([]*structs.ACLTokenLastUsedEntry, 0, len(pending))
+		for accessorID, entry := range pending {
+			if len(entries) >= u.srv.config.ACLTokenLastUsedUpdateBatchSize {
+				break
+			}
+			entries = append(entries, entry)
+			delete(pending, accessorID)
+		}
+		if len(pending) == 0 {
+			delete(u.pending, datacenter)
+		}
+		batches[datacenter] = entries
+	}
+
+	// Forget the uses that are past the granularity, they will be checked
+	// against the token's LastUsed again.
+	now := time.Now()
+	for accessorID, recorded := range u.recorded {
+		if now.Sub(recorded) >= u.srv.config.ACLTokenLastUsedGranularity {
+			delete(u.recorded, accessorID)
+		}
+	}
+	u.pendingLock.Unlock()
+
+	var merr error
+	for datacenter, entries := range batches {
+		if err := u.send(datacenter, entries); err != nil {
+			u.pendingLock.Lock()
+			for _, entry := range entries {
+				delete(u.recorded, entry.AccessorID)
+			}
+			u.pendingLock.Unlock()
+
+			merr = multierror.Append(merr, fmt.Errorf("datacenter %q: %w", datacenter, err))
+		}
+	}
+	return merr
+}
+
+// send sends a batch of token usage to the leader of the given datacenter.
+// Servers authenticate to their own datacenter with the server management
+// token, and to the primary datacenter with the ACL replication token.
+func (u *aclTokenUsage) send(datacenter string, entries []*structs.ACLTokenLastUsedEntry) error {
+	var token string
+	if datacenter == u.srv.config.Datacenter {
+		var err error
+		token, err = u.srv.GetSystemMetadata(structs.ServerManagementTokenAccessorID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch the server management token: %w", err)
+		}
+		if token == "" {
+			return fmt.Errorf("the server management token is not set yet")
+		}
+	} else {
+		token = u.srv.tokens.ReplicationToken()
+		if token == "" {
+			return fmt.Errorf("the ACL replication token is not set")
+		}
+	}
+
+	req := structs.ACLTokenLastUsedSetRequest{
+		Entries:      entries,
+		Datacenter:   datacenter,
+		WriteRequest: structs.WriteRequest{Token: token},
+	}
+	var reply struct{}
+	return u.srv.RPC(context.Background(), "ACL.TokenLastUsedSet", &req, &reply)
+}
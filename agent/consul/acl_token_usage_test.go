@@ -0,0 +1,224 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package consul
+
+import (
+	"testing"
+	"time"
+
+	msgpackrpc "github.com/hashicorp/consul-net-rpc/net-rpc-msgpackrpc"
+	"github.com/hashicorp/consul-net-rpc/net/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestACLTokenUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, s1, codec1 := testACLServerWithConfig(t, func(c *Config) {
+		c.ACLTokenLastUsedUpdatePeriod = 50 * time.Millisecond
+	}, false)
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+	_, s2, codec2 := testACLServerWithConfig(t, func(c *Config) {
+		c.Bootstrap = false
+		c.ACLTokenLastUsedUpdatePeriod = 50 * time.Millisecond
+	}, false)
+	joinLAN(t, s2, s1)
+	retry.Run(t, func(r *retry.R) { r.Check(wantPeers(s2, 2)) })
+
+	rules := `node_prefix "" { policy = "read" }`
+	leaderToken, err := upsertTestTokenWithPolicyRules(codec1, TestDefaultInitialManagementToken, "dc1", rules)
+	require.NoError(t, err)
+	followerToken, err := upsertTestTokenWithPolicyRules(codec1, TestDefaultInitialManagementToken, "dc1", rules)
+	require.NoError(t, err)
+	unusedToken, err := upsertTestTokenWithPolicyRules(codec1, TestDefaultInitialManagementToken, "dc1", rules)
+	require.NoError(t, err)
+
+	// Wait for the follower to have the tokens, then use one token on each
+	// server.
+	retry.Run(t, func(r *retry.R) {
+		_, token, err := s2.fsm.State().ACLTokenGetByAccessor(nil, unusedToken.AccessorID, nil)
+		require.NoError(r, err)
+		require.NotNil(r, token)
+	})
+	listNodes := func(codec rpc.ClientCodec, token string) {
+		args := structs.DCSpecificRequest{
+			Datacenter:   "dc1",
+			QueryOptions: structs.QueryOptions{Token: token, AllowStale: true},
+		}
+		var out structs.IndexedNodes
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "Catalog.ListNodes", &args, &out))
+	}
+	listNodes(codec1, leaderToken.SecretID)
+	listNodes(codec2, followerToken.SecretID)
+
+	lastUsed := func(r *retry.R, accessorID string) *structs.ACLTokenLastUsed {
+		_, token, err := s1.fsm.State().ACLTokenGetByAccessor(nil, accessorID, nil)
+		require.NoError(r, err)
+		require.NotNil(r, token)
+		return token.LastUsed
+	}
+	retry.Run(t, func(r *retry.R) {
+		used := lastUsed(r, leaderToken.AccessorID)
+		require.NotNil(r, used)
+		require.Equal(r, s1.config.NodeName, used.Server)
+		require.Equal(r, "dc1", used.Datacenter)
+
+		used = lastUsed(r, followerToken.AccessorID)
+		require.NotNil(r, used)
+		require.Equal(r, s2.config.NodeName, used.Server)
+		require.Equal(r, "dc1", used.Datacenter)
+	})
+	retry.Run(t, func(r *retry.R) {
+		require.Nil(r, lastUsed(r, unusedToken.AccessorID))
+	})
+
+	// Using a token again within the granularity doesn't record it again.
+	_, token, err := s1.fsm.State().ACLTokenGetByAccessor(nil, leaderToken.AccessorID, nil)
+	require.NoError(t, err)
+	listNodes(codec1, leaderToken.SecretID)
+	s1.aclTokenUsage.pendingLock.Lock()
+	require.Empty(t, s1.aclTokenUsage.pending)
+	s1.aclTokenUsage.pendingLock.Unlock()
+	_, token2, err := s1.fsm.State().ACLTokenGetByAccessor(nil, leaderToken.AccessorID, nil)
+	require.NoError(t, err)
+	require.Equal(t, token.LastUsed, token2.LastUsed)
+}
+
+func TestACLTokenUsage_MultiDC(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, s1, codec1 := testACLServerWithConfig(t, func(c *Config) {
+		c.ACLTokenLastUsedUpdatePeriod = 50 * time.Millisecond
+	}, false)
+	_, s2, codec2 := testACLServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "dc2"
+		c.ACLTokenReplication = true
+		c.ACLTokenLastUsedUpdatePeriod = 50 * time.Millisecond
+	}, true)
+	waitForLeaderEstablishment(t, s1)
+	waitForLeaderEstablishment(t, s2)
+	joinWAN(t, s2, s1)
+	waitForNewACLReplication(t, s2, structs.ACLReplicateTokens, 1, 1, 0)
+
+	rules := `node_prefix "" { policy = "read" }`
+	globalToken, err := upsertTestTokenWithPolicyRules(codec1, TestDefaultInitialManagementToken, "dc1", rules)
+	require.NoError(t, err)
+	localToken, err := upsertTestToken(codec2, TestDefaultInitialManagementToken, "dc2", func(token *structs.ACLToken) {
+		token.Local = true
+	})
+	require.NoError(t, err)
+
+	// Wait for the global token to be replicated, then use both tokens in the
+	// secondary datacenter.
+	retry.Run(t, func(r *retry.R) {
+		_, token, err := s2.fsm.State().ACLTokenGetByAccessor(nil, globalToken.AccessorID, nil)
+		require.NoError(r, err)
+		require.NotNil(r, token)
+	})
+	for _, token := range []string{globalToken.SecretID, localToken.SecretID} {
+		args := structs.DCSpecificRequest{
+			Datacenter:   "dc2",
+			QueryOptions: structs.QueryOptions{Token: token},
+		}
+		var out structs.IndexedNodes
+		require.NoError(t, msgpackrpc.CallWithCodec(codec2, "Catalog.ListNodes", &args, &out))
+	}
+
+	// The use of the global token is recorded in the primary datacenter where
+	// it is managed, the use of the local token in the secondary one.
+	retry.Run(t, func(r *retry.R) {
+		_, token, err := s1.fsm.State().ACLTokenGetByAccessor(nil, globalToken.AccessorID, nil)
+		require.NoError(r, err)
+		require.NotNil(r, token)
+		require.NotNil(r, token.LastUsed)
+		require.Equal(r, s2.config.NodeName, token.LastUsed.Server)
+		require.Equal(r, "dc2", token.LastUsed.Datacenter)
+
+		_, token, err = s2.fsm.State().ACLTokenGetByAccessor(nil, localToken.AccessorID, nil)
+		require.NoError(r, err)
+		require.NotNil(r, token)
+		require.NotNil(r, token.LastUsed)
+		require.Equal(r, s2.config.NodeName, token.LastUsed.Server)
+		require.Equal(r, "dc2", token.LastUsed.Datacenter)
+	})
+
+	// The global token is only recorded once within the granularity, even
+	// before its last use is replicated back to the secondary datacenter.
+	args := structs.DCSpecificRequest{
+		Datacenter:   "dc2",
+		QueryOptions: structs.QueryOptions{Token: globalToken.SecretID},
+	}
+	var out structs.IndexedNodes
+	require.NoError(t, msgpackrpc.CallWithCodec(codec2, "Catalog.ListNodes", &args, &out))
+	s2.aclTokenUsage.pendingLock.Lock()
+	require.Empty(t, s2.aclTokenUsage.pending)
+	s2.aclTokenUsage.pendingLock.Unlock()
+
+	// The last use of the global token is replicated back the next time the
+	// tokens are replicated, so listing the tokens in the secondary datacenter
+	// shows it too.
+	_, err = upsertTestTokenWithPolicyRules(codec1, TestDefaultInitialManagementToken, "dc1", rules)
+	require.NoError(t, err)
+	retry.Run(t, func(r *retry.R) {
+		req := structs.ACLTokenListRequest{
+			Datacenter:    "dc2",
+			IncludeLocal:  true,
+			IncludeGlobal: true,
+			QueryOptions:  structs.QueryOptions{Token: TestDefaultInitialManagementToken},
+		}
+		var resp structs.ACLTokenListResponse
+		require.NoError(r, msgpackrpc.CallWithCodec(codec2, "ACL.TokenList", &req, &resp))
+
+		var found bool
+		for _, token := range resp.Tokens {
+			if token.AccessorID != globalToken.AccessorID {
+				continue
+			}
+			found = true
+			require.NotNil(r, token.LastUsed)
+			require.Equal(r, s2.config.NodeName, token.LastUsed.Server)
+			require.Equal(r, "dc2", token.LastUsed.Datacenter)
+		}
+		require.True(r, found)
+	})
+}
+
+func TestACLEndpoint_TokenLastUsedSet_RequiresACLWrite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, s1, codec := testACLServerWithConfig(t, nil, false)
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+	token, err := upsertTestTokenWithPolicyRules(codec, TestDefaultInitialManagementToken, "dc1", `acl = "read"`)
+	require.NoError(t, err)
+
+	args := structs.ACLTokenLastUsedSetRequest{
+		Datacenter:   "dc1",
+		WriteRequest: structs.WriteRequest{Token: token.SecretID},
+	}
+	var reply struct{}
+	err = msgpackrpc.CallWithCodec(codec, "ACL.TokenLastUsedSet", &args, &reply)
+	require.True(t, acl.IsErrPermissionDenied(err), "unexpected error: %v", err)
+
+	args.Token = TestDefaultInitialManagementToken
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "ACL.TokenLastUsedSet", &args, &reply))
+}
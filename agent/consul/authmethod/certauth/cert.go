@@ -0,0 +1,458 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package certauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/consul/agent/connect"
+	"github.com/hashicorp/consul/agent/consul/authmethod"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+const (
+	authMethodType string = "x509"
+
+	// DefaultMaxTokenTTL is the longest a login token is valid for when the
+	// auth method doesn't configure MaxTokenTTL.
+	DefaultMaxTokenTTL = 5 * time.Minute
+)
+
+func init() {
+	// register this as an available auth method type
+	authmethod.Register(authMethodType, func(logger hclog.Logger, method *structs.ACLAuthMethod) (authmethod.Validator, error) {
+		v, err := NewValidator(logger, method)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+}
+
+// Config is the configuration of an X.509 auth method.
+//
+// Logging in with this auth method requires a login token proving possession
+// of a client certificate: a short-lived JWT whose x5c header carries the
+// certificate chain, signed with the certificate's private key. NewLoginToken
+// creates one.
+type Config struct {
+	// TrustBundles are the PEM encoded CA certificates that plain X.509
+	// client certificates must chain up to. They are never used to verify
+	// SPIFFE SVIDs.
+	TrustBundles []string `json:",omitempty"`
+
+	// SPIFFETrustBundles are the PEM encoded SPIFFE trust bundles keyed by
+	// trust domain. An X.509 SVID must chain up to the bundle of the trust
+	// domain in its SPIFFE ID, so that a bundle only vouches for its own
+	// trust domain.
+	SPIFFETrustBundles map[string]string `json:",omitempty"`
+
+	// BoundAudiences are the audiences the login token may be meant for. They
+	// are required so that tokens meant for other systems, or for other
+	// clusters trusting the same CA, can't be replayed against this auth
+	// method. Use an audience unique to the cluster, such as its address.
+	BoundAudiences []string `json:",omitempty"`
+
+	// MaxTokenTTL is the longest a login token may be valid for. It defaults
+	// to 5 minutes.
+	MaxTokenTTL time.Duration `json:",omitempty"`
+}
+
+type Validator struct {
+	name   string
+	config *Config
+	logger hclog.Logger
+
+	// roots verifies plain X.509 client certificates, it is nil when no
+	// TrustBundles are configured.
+	roots *x509.CertPool
+	// spiffeRoots verifies X.509 SVIDs, keyed by trust domain.
+	spiffeRoots map[string]*x509.CertPool
+}
+
+var _ authmethod.Validator = (*Validator)(nil)
+
+func NewValidator(logger hclog.Logger, method *structs.ACLAuthMethod) (*Validator, error) {
+	if method.Type != authMethodType {
+		return nil, fmt.Errorf("%q is not an X.509 auth method", method.Name)
+	}
+
+	var config Config
+	if err := authmethod.ParseConfig(method.Config, &config); err != nil {
+		return nil, err
+	}
+
+	if len(config.TrustBundles) == 0 && len(config.SPIFFETrustBundles) == 0 {
+		return nil, fmt.Errorf("one of Config.TrustBundles or Config.SPIFFETrustBundles is required")
+	}
+	var roots *x509.CertPool
+	if len(config.TrustBundles) > 0 {
+		roots = x509.NewCertPool()
+		for i, bundle := range config.TrustBundles {
+			if !roots.AppendCertsFromPEM([]byte(bundle)) {
+				return nil, fmt.Errorf("Config.TrustBundles[%d] contains no valid PEM encoded certificate", i)
+			}
+		}
+	}
+	spiffeRoots := make(map[string]*x509.CertPool, len(config.SPIFFETrustBundles))
+	for td, bundle := range config.SPIFFETrustBundles {
+		if td == "" || strings.ContainsAny(td, "/:") || td != strings.ToLower(td) {
+			return nil, fmt.Errorf("Config.SPIFFETrustBundles: %q is not a valid trust domain", td)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(bundle)) {
+			return nil, fmt.Errorf("Config.SPIFFETrustBundles[%q] contains no valid PEM encoded certificate", td)
+		}
+		spiffeRoots[td] = pool
+	}
+
+	if len(config.BoundAudiences) == 0 {
+		return nil, fmt.Errorf("Config.BoundAudiences is required")
+	}
+
+	if config.MaxTokenTTL < 0 {
+		return nil, fmt.Errorf("Config.MaxTokenTTL can't be negative")
+	}
+	if config.MaxTokenTTL == 0 {
+		config.MaxTokenTTL = DefaultMaxTokenTTL
+	}
+
+	return &Validator{
+		name:        method.Name,
+		config:      &config,
+		logger:      logger,
+		roots:       roots,
+		spiffeRoots: spiffeRoots,
+	}, nil
+}
+
+// Name implements authmethod.Validator.
+func (v *Validator) Name() string { return v.name }
+
+// Stop implements authmethod.Validator.
+func (v *Validator) Stop() {}
+
+// ValidateLogin implements authmethod.Validator.
+func (v *Validator) ValidateLogin(ctx context.Context, loginToken string) (*authmethod.Identity, error) {
+	cert, spiffeID, err := v.verifyLoginToken(loginToken, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	fields := selectableFieldsFromCert(cert, spiffeID)
+	return &authmethod.Identity{
+		SelectableFields: fields,
+		ProjectedVars:    fields.projectedVars(),
+		EnterpriseMeta:   nil,
+	}, nil
+}
+
+// verifyLoginToken checks that the login token was signed by the private key
+// of a client certificate trusted by the auth method, and returns that
+// certificate along with its SPIFFE ID, if it is an X.509 SVID.
+func (v *Validator) verifyLoginToken(loginToken string, now time.Time) (*x509.Certificate, *url.URL, error) {
+	tok, err := jwt.ParseSigned(loginToken)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse login token: %w", err)
+	}
+	if len(tok.Headers) != 1 {
+		return nil, nil, errors.New("login token must have exactly one signature")
+	}
+
+	cert, spiffeID, err := v.verifyCertificateChain(loginToken, now)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to verify client certificate: %w", err)
+	}
+
+	var claims jwt.Claims
+	if err := tok.Claims(cert.PublicKey, &claims); err != nil {
+		return nil, nil, fmt.Errorf("failed to verify login token signature: %w", err)
+	}
+
+	if claims.IssuedAt == nil || claims.Expiry == nil {
+		return nil, nil, errors.New("login token must have iat and exp claims")
+	}
+	if ttl := claims.Expiry.Time().Sub(claims.IssuedAt.Time()); ttl > v.config.MaxTokenTTL {
+		return nil, nil, fmt.Errorf("login token is valid for %s, longer than the maximum of %s", ttl, v.config.MaxTokenTTL)
+	}
+	if err := claims.ValidateWithLeeway(jwt.Expected{Time: now}, jwt.DefaultLeeway); err != nil {
+		return nil, nil, fmt.Errorf("invalid login token: %w", err)
+	}
+
+	var found bool
+	for _, aud := range v.config.BoundAudiences {
+		if claims.Audience.Contains(aud) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, nil, errors.New("login token isn't meant for any of the bound audiences")
+	}
+
+	return cert, spiffeID, nil
+}
+
+// verifyCertificateChain verifies the certificate chain in the x5c header of
+// the login token and returns the client certificate. An X.509 SVID is only
+// verified against the trust bundle of its own trust domain, any other
+// certificate against the plain X.509 trust bundles.
+func (v *Validator) verifyCertificateChain(loginToken string, now time.Time) (*x509.Certificate, *url.URL, error) {
+	chain, err := certificateChain(loginToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert := chain[0]
+
+	spiffeID, err := spiffeIDFromCert(cert)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	roots := v.roots
+	if spiffeID != nil {
+		roots = v.spiffeRoots[spiffeID.Host]
+		if roots == nil {
+			return nil, nil, fmt.Errorf("no trust bundle for trust domain %q", spiffeID.Host)
+		}
+	} else if roots == nil {
+		return nil, nil, errors.New("certificate isn't an X.509 SVID and no plain X.509 trust bundles are configured")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, intermediate := range chain[1:] {
+		intermediates.AddCert(intermediate)
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, spiffeID, nil
+}
+
+// certificateChain returns the unverified certificate chain from the x5c
+// header of the login token. go-jose only exposes the chain after verifying
+// it, but the trust bundle to verify it with depends on the client
+// certificate.
+func certificateChain(loginToken string) ([]*x509.Certificate, error) {
+	encoded, _, ok := strings.Cut(loginToken, ".")
+	if !ok {
+		return nil, errors.New("login token isn't in compact serialization")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode login token header: %w", err)
+	}
+	var header struct {
+		X5C [][]byte `json:"x5c"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("failed to decode login token header: %w", err)
+	}
+	if len(header.X5C) == 0 {
+		return nil, errors.New("login token has no x5c header")
+	}
+
+	chain := make([]*x509.Certificate, 0, len(header.X5C))
+	for i, der := range header.X5C {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse x5c certificate %d: %w", i, err)
+		}
+		chain = append(chain, cert)
+	}
+	return chain, nil
+}
+
+// spiffeIDFromCert returns the SPIFFE ID of the certificate, or nil if it
+// isn't an X.509 SVID. An X.509 SVID must have exactly one URI SAN, its
+// SPIFFE ID, so a certificate with a spiffe:// URI SAN among others is
+// rejected rather than picking one of them.
+func spiffeIDFromCert(cert *x509.Certificate) (*url.URL, error) {
+	var spiffeID *url.URL
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" {
+			spiffeID = uri
+			break
+		}
+	}
+	if spiffeID == nil {
+		return nil, nil
+	}
+	if len(cert.URIs) != 1 {
+		return nil, fmt.Errorf("certificate has a SPIFFE ID and %d URI SANs, an X.509 SVID must have exactly one", len(cert.URIs))
+	}
+	if spiffeID.Host == "" {
+		return nil, fmt.Errorf("SPIFFE ID %q has no trust domain", spiffeID)
+	}
+	return spiffeID, nil
+}
+
+// NewIdentity implements authmethod.Validator.
+func (v *Validator) NewIdentity() *authmethod.Identity {
+	// Populate projectable vars with empty values so HIL works.
+	fields := &certSelectableFields{}
+	return &authmethod.Identity{
+		SelectableFields: fields,
+		ProjectedVars:    fields.projectedVars(),
+	}
+}
+
+// NewLoginToken creates a login token for an X.509 auth method. It proves
+// possession of the given client certificate by signing a token meant for the
+// given audience and valid for the given TTL with the certificate's private
+// key. The chain holds the client certificate first, followed by any
+// intermediates needed to verify it.
+func NewLoginToken(chain []*x509.Certificate, key crypto.Signer, audience string, ttl time.Duration) (string, error) {
+	if len(chain) == 0 {
+		return "", errors.New("a client certificate is required")
+	}
+	if audience == "" {
+		return "", errors.New("an audience is required")
+	}
+
+	var alg jose.SignatureAlgorithm
+	switch pub := key.Public().(type) {
+	case *rsa.PublicKey:
+		alg = jose.RS256
+	case *ecdsa.PublicKey:
+		switch pub.Curve.Params().BitSize {
+		case 256:
+			alg = jose.ES256
+		case 384:
+			alg = jose.ES384
+		case 521:
+			alg = jose.ES512
+		default:
+			return "", fmt.Errorf("unsupported ECDSA curve %s", pub.Curve.Params().Name)
+		}
+	case ed25519.PublicKey:
+		alg = jose.EdDSA
+	default:
+		return "", fmt.Errorf("unsupported private key type %T", pub)
+	}
+
+	raw := make([][]byte, 0, len(chain))
+	for _, cert := range chain {
+		raw = append(raw, cert.Raw)
+	}
+	opts := (&jose.SignerOptions{}).WithType("JWT").WithHeader("x5c", raw)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key}, opts)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := jwt.Claims{
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(ttl)),
+		Audience: jwt.Audience{audience},
+	}
+	return jwt.Signed(signer).Claims(claims).CompactSerialize()
+}
+
+type certSelectableFields struct {
+	Subject      certName   `bexpr:"subject"`
+	Issuer       certName   `bexpr:"issuer"`
+	SerialNumber string     `bexpr:"serial_number"`
+	SAN          certSANs   `bexpr:"san"`
+	SPIFFE       certSPIFFE `bexpr:"spiffe"`
+}
+
+type certName struct {
+	CommonName         string   `bexpr:"common_name"`
+	Organization       []string `bexpr:"organization"`
+	OrganizationalUnit []string `bexpr:"organizational_unit"`
+}
+
+type certSANs struct {
+	DNS   []string `bexpr:"dns"`
+	URI   []string `bexpr:"uri"`
+	Email []string `bexpr:"email"`
+	IP    []string `bexpr:"ip"`
+}
+
+type certSPIFFE struct {
+	ID          string `bexpr:"id"`
+	TrustDomain string `bexpr:"trust_domain"`
+	Path        string `bexpr:"path"`
+}
+
+func selectableFieldsFromCert(cert *x509.Certificate, spiffeID *url.URL) *certSelectableFields {
+	fields := &certSelectableFields{
+		Subject: certName{
+			CommonName:         cert.Subject.CommonName,
+			Organization:       cert.Subject.Organization,
+			OrganizationalUnit: cert.Subject.OrganizationalUnit,
+		},
+		Issuer: certName{
+			CommonName:         cert.Issuer.CommonName,
+			Organization:       cert.Issuer.Organization,
+			OrganizationalUnit: cert.Issuer.OrganizationalUnit,
+		},
+		SerialNumber: connect.HexString(cert.SerialNumber.Bytes()),
+		SAN: certSANs{
+			DNS:   cert.DNSNames,
+			Email: cert.EmailAddresses,
+		},
+	}
+	for _, ip := range cert.IPAddresses {
+		fields.SAN.IP = append(fields.SAN.IP, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		fields.SAN.URI = append(fields.SAN.URI, uri.String())
+	}
+	if spiffeID != nil {
+		fields.SPIFFE = certSPIFFE{
+			ID:          spiffeID.String(),
+			TrustDomain: spiffeID.Host,
+			Path:        spiffeID.Path,
+		}
+	}
+	return fields
+}
+
+// projectedVars returns the fields suitable for interpolation in a bind name.
+// Only the first value of list fields is projected.
+func (f *certSelectableFields) projectedVars() map[string]string {
+	first := func(values []string) string {
+		if len(values) == 0 {
+			return ""
+		}
+		return values[0]
+	}
+	return map[string]string{
+		"subject.common_name": f.Subject.CommonName,
+		"issuer.common_name":  f.Issuer.CommonName,
+		"serial_number":       f.SerialNumber,
+		"san.dns":             first(f.SAN.DNS),
+		"san.uri":             first(f.SAN.URI),
+		"san.email":           first(f.SAN.Email),
+		"san.ip":              first(f.SAN.IP),
+		"spiffe.id":           f.SPIFFE.ID,
+		"spiffe.trust_domain": f.SPIFFE.TrustDomain,
+		"spiffe.path":         f.SPIFFE.Path,
+	}
+}
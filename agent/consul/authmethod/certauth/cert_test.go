@@ -0,0 +1,381 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package certauth
+
+import (
+	"context"
+	"crypto/x509"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/consul/authmethod"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+func TestNewValidator(t *testing.T) {
+	ca, _ := TestCA(t)
+
+	type AM = *structs.ACLAuthMethod
+	// Create the auth method, with an optional modification function.
+	makeMethod := func(modifyFn func(AM)) AM {
+		m := &structs.ACLAuthMethod{
+			Name:        "test-x509",
+			Type:        "x509",
+			Description: "x509 auth",
+			Config: map[string]interface{}{
+				"TrustBundles":       []string{TestCertPEM(ca)},
+				"SPIFFETrustBundles": map[string]string{"example.org": TestCertPEM(ca)},
+				"BoundAudiences":     []string{"consul"},
+			},
+		}
+		if modifyFn != nil {
+			modifyFn(m)
+		}
+		return m
+	}
+
+	cases := map[string]struct {
+		ok       bool
+		modifyFn func(AM)
+	}{
+		"success":                 {true, nil},
+		"success with max ttl":    {true, func(m AM) { m.Config["MaxTokenTTL"] = time.Minute }},
+		"only plain trust bundle": {true, func(m AM) { delete(m.Config, "SPIFFETrustBundles") }},
+		"only spiffe bundle":      {true, func(m AM) { delete(m.Config, "TrustBundles") }},
+		"wrong type":              {false, func(m AM) { m.Type = "not-x509" }},
+		"extra config":            {false, func(m AM) { m.Config["extraField"] = "123" }},
+		"missing trust bundles": {false, func(m AM) {
+			delete(m.Config, "TrustBundles")
+			delete(m.Config, "SPIFFETrustBundles")
+		}},
+		"missing audiences":    {false, func(m AM) { delete(m.Config, "BoundAudiences") }},
+		"invalid trust bundle": {false, func(m AM) { m.Config["TrustBundles"] = []string{"not a cert"} }},
+		"invalid spiffe bundle": {false, func(m AM) {
+			m.Config["SPIFFETrustBundles"] = map[string]string{"example.org": "not a cert"}
+		}},
+		"invalid trust domain": {false, func(m AM) {
+			m.Config["SPIFFETrustBundles"] = map[string]string{"spiffe://example.org": TestCertPEM(ca)}
+		}},
+		"negative max ttl": {false, func(m AM) { m.Config["MaxTokenTTL"] = -time.Minute }},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, err := NewValidator(hclog.NewNullLogger(), makeMethod(c.modifyFn))
+			if c.ok {
+				require.NoError(t, err)
+				require.NotNil(t, v)
+				require.Equal(t, "test-x509", v.Name())
+				require.Equal(t, []string{"consul"}, v.config.BoundAudiences)
+				require.NotZero(t, v.config.MaxTokenTTL)
+			} else {
+				require.Error(t, err)
+				require.Nil(t, v)
+			}
+		})
+	}
+}
+
+func TestValidateLogin(t *testing.T) {
+	ca, caKey := TestCA(t)
+	cert, key := TestClientCert(t, ca, caKey, nil)
+	otherCA, otherCAKey := TestCA(t)
+	untrustedCert, untrustedKey := TestClientCert(t, otherCA, otherCAKey, nil)
+	serverCert, serverKey := TestClientCert(t, ca, caKey, func(c *x509.Certificate) {
+		c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	})
+	_, otherKey := TestClientCert(t, ca, caKey, nil)
+	plainCert, plainKey := TestClientCert(t, ca, caKey, func(c *x509.Certificate) {
+		c.URIs = nil
+	})
+	multiURICert, multiURIKey := TestClientCert(t, ca, caKey, func(c *x509.Certificate) {
+		c.URIs = append(c.URIs, &url.URL{Scheme: "https", Host: "web.example.org"})
+	})
+	// With both trust domains federated, untrustedCert is issued by the CA of
+	// other.org but claims a SPIFFE ID in example.org.
+	otherDomainCert, otherDomainKey := TestClientCert(t, otherCA, otherCAKey, func(c *x509.Certificate) {
+		c.URIs = []*url.URL{{Scheme: "spiffe", Host: "other.org", Path: "/ns/default/sa/api"}}
+	})
+	federated := map[string]interface{}{
+		"SPIFFETrustBundles": map[string]string{
+			"example.org": TestCertPEM(ca),
+			"other.org":   TestCertPEM(otherCA),
+		},
+		"BoundAudiences": []string{"consul"},
+	}
+
+	newToken := func(t *testing.T, cert *x509.Certificate, audience string, ttl time.Duration) string {
+		token, err := NewLoginToken([]*x509.Certificate{cert}, key, audience, ttl)
+		require.NoError(t, err)
+		return token
+	}
+
+	config := map[string]interface{}{
+		"SPIFFETrustBundles": map[string]string{"example.org": TestCertPEM(ca)},
+		"BoundAudiences":     []string{"consul"},
+	}
+
+	t.Run("audience is required", func(t *testing.T) {
+		_, err := NewLoginToken([]*x509.Certificate{cert}, key, "", time.Minute)
+		require.EqualError(t, err, "an audience is required")
+	})
+
+	cases := map[string]struct {
+		config    map[string]interface{}
+		token     func(t *testing.T) string
+		expVars   map[string]string
+		expFields []string
+		expError  string
+	}{
+		"success": {
+			config: config,
+			token: func(t *testing.T) string {
+				return newToken(t, cert, "consul", time.Minute)
+			},
+			expVars: map[string]string{
+				"subject.common_name": "web",
+				"issuer.common_name":  "Test CA",
+				"serial_number":       "2a",
+				"san.dns":             "web.example.org",
+				"san.uri":             "spiffe://example.org/ns/default/sa/web",
+				"san.email":           "",
+				"san.ip":              "",
+				"spiffe.id":           "spiffe://example.org/ns/default/sa/web",
+				"spiffe.trust_domain": "example.org",
+				"spiffe.path":         "/ns/default/sa/web",
+			},
+			expFields: []string{
+				`subject.common_name == "web"`,
+				`"payments" in subject.organizational_unit`,
+				`"Example" in issuer.organization`,
+				`"web.example.org" in san.dns`,
+				`"web" in san.dns`,
+				`spiffe.trust_domain == "example.org"`,
+				`spiffe.path matches "^/ns/default/"`,
+			},
+		},
+		"success - bound audience": {
+			config: map[string]interface{}{
+				"SPIFFETrustBundles": map[string]string{"example.org": TestCertPEM(ca)},
+				"BoundAudiences":     []string{"other", "consul"},
+			},
+			token: func(t *testing.T) string {
+				return newToken(t, cert, "consul", time.Minute)
+			},
+			expFields: []string{`spiffe.id == "spiffe://example.org/ns/default/sa/web"`},
+		},
+		"wrong audience": {
+			config: config,
+			token: func(t *testing.T) string {
+				return newToken(t, cert, "vault", time.Minute)
+			},
+			expError: "isn't meant for any of the bound audiences",
+		},
+		"missing audience": {
+			config: config,
+			token: func(t *testing.T) string {
+				signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key},
+					(&jose.SignerOptions{}).WithHeader("x5c", [][]byte{cert.Raw}))
+				require.NoError(t, err)
+				token, err := jwt.Signed(signer).Claims(jwt.Claims{
+					IssuedAt: jwt.NewNumericDate(time.Now()),
+					Expiry:   jwt.NewNumericDate(time.Now().Add(time.Minute)),
+				}).CompactSerialize()
+				require.NoError(t, err)
+				return token
+			},
+			expError: "isn't meant for any of the bound audiences",
+		},
+		"untrusted certificate": {
+			config: config,
+			token: func(t *testing.T) string {
+				token, err := NewLoginToken([]*x509.Certificate{untrustedCert}, untrustedKey, "consul", time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+			expError: "failed to verify client certificate",
+		},
+		"success - plain certificate": {
+			config: map[string]interface{}{
+				"TrustBundles":   []string{TestCertPEM(ca)},
+				"BoundAudiences": []string{"consul"},
+			},
+			token: func(t *testing.T) string {
+				token, err := NewLoginToken([]*x509.Certificate{plainCert}, plainKey, "consul", time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+			expFields: []string{`subject.common_name == "web"`, `spiffe.id == ""`},
+		},
+		"svid with only plain trust bundles": {
+			config: map[string]interface{}{
+				"TrustBundles":   []string{TestCertPEM(ca)},
+				"BoundAudiences": []string{"consul"},
+			},
+			token: func(t *testing.T) string {
+				return newToken(t, cert, "consul", time.Minute)
+			},
+			expError: `no trust bundle for trust domain "example.org"`,
+		},
+		"plain certificate with only spiffe bundles": {
+			config: config,
+			token: func(t *testing.T) string {
+				token, err := NewLoginToken([]*x509.Certificate{plainCert}, plainKey, "consul", time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+			expError: "no plain X.509 trust bundles are configured",
+		},
+		"success - federated trust domain": {
+			config: federated,
+			token: func(t *testing.T) string {
+				token, err := NewLoginToken([]*x509.Certificate{otherDomainCert}, otherDomainKey, "consul", time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+			expFields: []string{`spiffe.trust_domain == "other.org"`},
+		},
+		"svid from another trust domain's ca": {
+			config: federated,
+			token: func(t *testing.T) string {
+				token, err := NewLoginToken([]*x509.Certificate{untrustedCert}, untrustedKey, "consul", time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+			expError: "failed to verify client certificate",
+		},
+		"svid with multiple uri sans": {
+			config: config,
+			token: func(t *testing.T) string {
+				token, err := NewLoginToken([]*x509.Certificate{multiURICert}, multiURIKey, "consul", time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+			expError: "an X.509 SVID must have exactly one",
+		},
+		"not a client certificate": {
+			config: config,
+			token: func(t *testing.T) string {
+				token, err := NewLoginToken([]*x509.Certificate{serverCert}, serverKey, "consul", time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+			expError: "failed to verify client certificate",
+		},
+		"signed by another key": {
+			config: config,
+			token: func(t *testing.T) string {
+				token, err := NewLoginToken([]*x509.Certificate{cert}, otherKey, "consul", time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+			expError: "failed to verify login token signature",
+		},
+		"ttl too long": {
+			config: config,
+			token: func(t *testing.T) string {
+				return newToken(t, cert, "consul", time.Hour)
+			},
+			expError: "longer than the maximum",
+		},
+		"expired": {
+			config: config,
+			token: func(t *testing.T) string {
+				signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key},
+					(&jose.SignerOptions{}).WithHeader("x5c", [][]byte{cert.Raw}))
+				require.NoError(t, err)
+				issued := time.Now().Add(-10 * time.Minute)
+				token, err := jwt.Signed(signer).Claims(jwt.Claims{
+					IssuedAt: jwt.NewNumericDate(issued),
+					Expiry:   jwt.NewNumericDate(issued.Add(time.Minute)),
+				}).CompactSerialize()
+				require.NoError(t, err)
+				return token
+			},
+			expError: "invalid login token",
+		},
+		"missing expiry": {
+			config: config,
+			token: func(t *testing.T) string {
+				signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key},
+					(&jose.SignerOptions{}).WithHeader("x5c", [][]byte{cert.Raw}))
+				require.NoError(t, err)
+				token, err := jwt.Signed(signer).Claims(jwt.Claims{
+					IssuedAt: jwt.NewNumericDate(time.Now()),
+				}).CompactSerialize()
+				require.NoError(t, err)
+				return token
+			},
+			expError: "must have iat and exp claims",
+		},
+		"invalid token": {
+			config: config,
+			token: func(t *testing.T) string {
+				return "not-a-token"
+			},
+			expError: "failed to parse login token",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			method := &structs.ACLAuthMethod{
+				Name:   "test-method",
+				Type:   "x509",
+				Config: c.config,
+			}
+			v, err := NewValidator(hclog.NewNullLogger(), method)
+			require.NoError(t, err)
+
+			id, err := v.ValidateLogin(context.Background(), c.token(t))
+			if c.expError != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.expError)
+				require.Nil(t, id)
+			} else {
+				require.NoError(t, err)
+				if c.expVars != nil {
+					require.Equal(t, c.expVars, id.ProjectedVars)
+				}
+				authmethod.RequireIdentityMatch(t, id, id.ProjectedVars, c.expFields...)
+			}
+		})
+	}
+}
+
+func TestNewIdentity(t *testing.T) {
+	ca, _ := TestCA(t)
+	method := &structs.ACLAuthMethod{
+		Name: "test-method",
+		Type: "x509",
+		Config: map[string]interface{}{
+			"SPIFFETrustBundles": map[string]string{"example.org": TestCertPEM(ca)},
+			"BoundAudiences":     []string{"consul"},
+		},
+	}
+	v, err := NewValidator(hclog.NewNullLogger(), method)
+	require.NoError(t, err)
+
+	id := v.NewIdentity()
+	authmethod.RequireIdentityMatch(t, id, map[string]string{
+		"subject.common_name": "",
+		"issuer.common_name":  "",
+		"serial_number":       "",
+		"san.dns":             "",
+		"san.uri":             "",
+		"san.email":           "",
+		"san.ip":              "",
+		"spiffe.id":           "",
+		"spiffe.trust_domain": "",
+		"spiffe.path":         "",
+	},
+		`subject.common_name == ""`,
+		`spiffe.id == ""`,
+		`san.dns is empty`,
+	)
+}
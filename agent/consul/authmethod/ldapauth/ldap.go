@@ -0,0 +1,404 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package ldapauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"text/template"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/consul/agent/consul/authmethod"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+const (
+	authMethodType string = "ldap"
+
+	defaultUserAttribute  = "uid"
+	defaultGroupAttribute = "cn"
+	defaultGroupFilter    = "(|(memberUid={{.Username}})(member={{.UserDN}})(uniqueMember={{.UserDN}}))"
+
+	// requestTimeout bounds how long a login waits on the LDAP server.
+	requestTimeout = 10 * time.Second
+)
+
+// errInvalidCredentials is returned for any login with an unknown username or
+// a wrong password, so that logins can't be used to find out which users
+// exist.
+var errInvalidCredentials = errors.New("invalid username or password")
+
+func init() {
+	// register this as an available auth method type
+	authmethod.Register(authMethodType, func(logger hclog.Logger, method *structs.ACLAuthMethod) (authmethod.Validator, error) {
+		v, err := NewValidator(logger, method)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+}
+
+// Config is the configuration of an LDAP auth method.
+//
+// Logging in looks the user up by searching UserDN for an entry whose
+// UserAttribute is the username, binds as that entry with the password to
+// check it, then searches GroupDN with GroupFilter for the user's groups.
+type Config struct {
+	// URL of the LDAP server, using the ldap:// or ldaps:// scheme.
+	URL string `json:",omitempty"`
+
+	// StartTLS upgrades ldap:// connections to TLS before binding. It is
+	// required with an ldap:// URL, as the passwords of the users logging in
+	// would otherwise be sent in plaintext.
+	StartTLS bool `json:",omitempty"`
+
+	// CACert is the PEM encoded CA certificate used to verify the LDAP
+	// server's certificate. The system's CA certificates are used if unset.
+	CACert string `json:",omitempty"`
+
+	// InsecureTLS disables verification of the LDAP server's certificate.
+	InsecureTLS bool `json:",omitempty"`
+
+	// BindDN and BindPassword are the credentials used to search for users
+	// and groups. Searches are anonymous if unset.
+	BindDN       string `json:",omitempty"`
+	BindPassword string `json:",omitempty"`
+
+	// UserDN is the base DN to search for users under.
+	UserDN string `json:",omitempty"`
+
+	// UserAttribute is the attribute matched against the username. The
+	// entry's value of it, rather than the username as typed, is the username
+	// made available to binding rules. It defaults to "uid".
+	UserAttribute string `json:",omitempty"`
+
+	// GroupDN is the base DN to search for groups under. Group membership
+	// isn't looked up if unset.
+	GroupDN string `json:",omitempty"`
+
+	// GroupFilter is the template of the filter used to search for the user's
+	// groups. {{.Username}} and {{.UserDN}} are replaced with the escaped
+	// username and user DN. It defaults to matching the memberUid, member and
+	// uniqueMember attributes.
+	GroupFilter string `json:",omitempty"`
+
+	// GroupAttribute is the attribute of group entries holding the group's
+	// name. It defaults to "cn".
+	GroupAttribute string `json:",omitempty"`
+
+	// Attributes are the user attributes, such as "mail" or "department",
+	// made available to binding rules.
+	Attributes []string `json:",omitempty"`
+}
+
+type Validator struct {
+	name   string
+	config *Config
+	logger hclog.Logger
+
+	tlsConfig   *tls.Config
+	groupFilter *template.Template
+}
+
+var _ authmethod.Validator = (*Validator)(nil)
+
+func NewValidator(logger hclog.Logger, method *structs.ACLAuthMethod) (*Validator, error) {
+	if method.Type != authMethodType {
+		return nil, fmt.Errorf("%q is not an LDAP auth method", method.Name)
+	}
+
+	var config Config
+	if err := authmethod.ParseConfig(method.Config, &config); err != nil {
+		return nil, err
+	}
+
+	if config.URL == "" {
+		return nil, fmt.Errorf("Config.URL is required")
+	}
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("Config.URL is invalid: %w", err)
+	}
+	switch u.Scheme {
+	case "ldap":
+		if !config.StartTLS {
+			return nil, fmt.Errorf("Config.StartTLS is required with an ldap:// URL")
+		}
+	case "ldaps":
+		if config.StartTLS {
+			return nil, fmt.Errorf("Config.StartTLS can't be used with an ldaps:// URL")
+		}
+	default:
+		return nil, fmt.Errorf("Config.URL must use the ldap:// or ldaps:// scheme")
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: config.InsecureTLS,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if config.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(config.CACert)) {
+			return nil, fmt.Errorf("Config.CACert contains no valid PEM encoded certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (config.BindDN == "") != (config.BindPassword == "") {
+		return nil, fmt.Errorf("Config.BindDN and Config.BindPassword must be set together")
+	}
+	if config.UserDN == "" {
+		return nil, fmt.Errorf("Config.UserDN is required")
+	}
+	if config.UserAttribute == "" {
+		config.UserAttribute = defaultUserAttribute
+	}
+	if config.GroupAttribute == "" {
+		config.GroupAttribute = defaultGroupAttribute
+	}
+	if config.GroupFilter == "" {
+		config.GroupFilter = defaultGroupFilter
+	}
+	groupFilter, err := template.New("GroupFilter").Option("missingkey=error").Parse(config.GroupFilter)
+	if err != nil {
+		return nil, fmt.Errorf("Config.GroupFilter is invalid: %w", err)
+	}
+
+	return &Validator{
+		name:        method.Name,
+		config:      &config,
+		logger:      logger,
+		tlsConfig:   tlsConfig,
+		groupFilter: groupFilter,
+	}, nil
+}
+
+// Name implements authmethod.Validator.
+func (v *Validator) Name() string { return v.name }
+
+// Stop implements authmethod.Validator.
+func (v *Validator) Stop() {}
+
+// ValidateLogin implements authmethod.Validator.
+func (v *Validator) ValidateLogin(ctx context.Context, loginToken string) (*authmethod.Identity, error) {
+	var creds loginCredentials
+	if err := json.Unmarshal([]byte(loginToken), &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse login token: %w", err)
+	}
+	if creds.Username == "" || creds.Password == "" {
+		// An empty password would be an unauthenticated bind, which LDAP
+		// servers accept for any DN.
+		return nil, errInvalidCredentials
+	}
+
+	conn, err := v.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := v.searchBind(conn); err != nil {
+		return nil, err
+	}
+
+	entry, err := v.findUser(conn, creds.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, creds.Password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			v.logger.Debug("LDAP login failed: wrong password", "method", v.name, "user_dn", entry.DN)
+			return nil, errInvalidCredentials
+		}
+		return nil, fmt.Errorf("LDAP bind failed: %w", err)
+	}
+
+	// The username as typed may differ from the directory's value, as the
+	// server matches it ignoring case and insignificant spaces. Use the
+	// directory's value so the same user always gets the same identity.
+	username := entry.GetAttributeValue(v.config.UserAttribute)
+	if username == "" {
+		return nil, fmt.Errorf("LDAP user entry %q has no %s attribute", entry.DN, v.config.UserAttribute)
+	}
+
+	// Rebind with the search credentials, rather than as the user, to look
+	// up the user's groups.
+	if err := v.searchBind(conn); err != nil {
+		return nil, err
+	}
+	groups, err := v.findGroups(conn, username, entry.DN)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := &ldapSelectableFields{
+		Username:   username,
+		UserDN:     entry.DN,
+		Groups:     groups,
+		Attributes: make(map[string]string, len(v.config.Attributes)),
+	}
+	for _, attr := range v.config.Attributes {
+		fields.Attributes[attr] = entry.GetAttributeValue(attr)
+	}
+
+	return &authmethod.Identity{
+		SelectableFields: fields,
+		ProjectedVars:    fields.projectedVars(),
+		EnterpriseMeta:   nil,
+	}, nil
+}
+
+// dial connects to the LDAP server, upgrading the connection to TLS if
+// configured to.
+func (v *Validator) dial() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(v.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: requestTimeout}),
+		ldap.DialWithTLSConfig(v.tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP server: %w", err)
+	}
+	conn.SetTimeout(requestTimeout)
+
+	if v.config.StartTLS {
+		if err := conn.StartTLS(v.tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start TLS with LDAP server: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// searchBind binds with the credentials used for searches.
+func (v *Validator) searchBind(conn *ldap.Conn) error {
+	var err error
+	if v.config.BindDN == "" {
+		err = conn.UnauthenticatedBind("")
+	} else {
+		err = conn.Bind(v.config.BindDN, v.config.BindPassword)
+	}
+	if err != nil {
+		return fmt.Errorf("LDAP bind for search failed: %w", err)
+	}
+	return nil
+}
+
+// findUser returns the entry of the user with the given username, with its
+// UserAttribute and the configured Attributes.
+func (v *Validator) findUser(conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	filter := fmt.Sprintf("(%s=%s)", ldap.EscapeFilter(v.config.UserAttribute), ldap.EscapeFilter(username))
+	attributes := append([]string{v.config.UserAttribute}, v.config.Attributes...)
+	res, err := conn.Search(ldap.NewSearchRequest(
+		v.config.UserDN, ldap.ScopeWholeSubtree, ldap.DerefInSearching, 2, 0, false,
+		filter, attributes, nil,
+	))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("LDAP user search failed: %w", err)
+	}
+
+	switch len(res.Entries) {
+	case 0:
+		v.logger.Debug("LDAP login failed: user not found", "method", v.name, "username", username)
+		return nil, errInvalidCredentials
+	case 1:
+		return res.Entries[0], nil
+	default:
+		return nil, fmt.Errorf("LDAP user search for %q matched more than one entry", username)
+	}
+}
+
+// findGroups returns the names of the groups the user is a member of.
+func (v *Validator) findGroups(conn *ldap.Conn, username, userDN string) ([]string, error) {
+	if v.config.GroupDN == "" {
+		return nil, nil
+	}
+
+	var filter bytes.Buffer
+	err := v.groupFilter.Execute(&filter, struct{ Username, UserDN string }{
+		Username: ldap.EscapeFilter(username),
+		UserDN:   ldap.EscapeFilter(userDN),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render group filter: %w", err)
+	}
+
+	res, err := conn.Search(ldap.NewSearchRequest(
+		v.config.GroupDN, ldap.ScopeWholeSubtree, ldap.DerefInSearching, 0, 0, false,
+		filter.String(), []string{v.config.GroupAttribute}, nil,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("LDAP group search failed: %w", err)
+	}
+
+	groups := make([]string, 0, len(res.Entries))
+	for _, entry := range res.Entries {
+		if name := entry.GetAttributeValue(v.config.GroupAttribute); name != "" {
+			groups = append(groups, name)
+		}
+	}
+	return groups, nil
+}
+
+// NewIdentity implements authmethod.Validator.
+func (v *Validator) NewIdentity() *authmethod.Identity {
+	// Populate projectable vars with empty values so HIL works.
+	fields := &ldapSelectableFields{
+		Attributes: make(map[string]string, len(v.config.Attributes)),
+	}
+	for _, attr := range v.config.Attributes {
+		fields.Attributes[attr] = ""
+	}
+	return &authmethod.Identity{
+		SelectableFields: fields,
+		ProjectedVars:    fields.projectedVars(),
+	}
+}
+
+// loginCredentials is the login token of an LDAP auth method.
+type loginCredentials struct {
+	Username string
+	Password string
+}
+
+// NewLoginToken creates a login token for an LDAP auth method from the given
+// username and password.
+func NewLoginToken(username, password string) (string, error) {
+	token, err := json.Marshal(loginCredentials{Username: username, Password: password})
+	if err != nil {
+		return "", err
+	}
+	return string(token), nil
+}
+
+type ldapSelectableFields struct {
+	Username   string            `bexpr:"username"`
+	UserDN     string            `bexpr:"user_dn"`
+	Groups     []string          `bexpr:"groups"`
+	Attributes map[string]string `bexpr:"attributes"`
+}
+
+// projectedVars returns the fields suitable for interpolation in a bind name.
+func (f *ldapSelectableFields) projectedVars() map[string]string {
+	vars := map[string]string{
+		"username": f.Username,
+		"user_dn":  f.UserDN,
+	}
+	for attr, value := range f.Attributes {
+		vars["attributes."+attr] = value
+	}
+	return vars
+}
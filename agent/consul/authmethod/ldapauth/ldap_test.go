@@ -0,0 +1,298 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package ldapauth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/consul/authmethod"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+func TestNewValidator(t *testing.T) {
+	type AM = *structs.ACLAuthMethod
+	// Create the auth method, with an optional modification function.
+	makeMethod := func(modifyFn func(AM)) AM {
+		m := &structs.ACLAuthMethod{
+			Name:        "test-ldap",
+			Type:        "ldap",
+			Description: "ldap auth",
+			Config: map[string]interface{}{
+				"URL":          "ldap://ldap.example.org",
+				"StartTLS":     true,
+				"BindDN":       "cn=consul,dc=example,dc=org",
+				"BindPassword": "secret",
+				"UserDN":       "ou=people,dc=example,dc=org",
+				"GroupDN":      "ou=groups,dc=example,dc=org",
+			},
+		}
+		if modifyFn != nil {
+			modifyFn(m)
+		}
+		return m
+	}
+
+	cases := map[string]struct {
+		ok       bool
+		modifyFn func(AM)
+	}{
+		"success":                {true, nil},
+		"success - ldaps":        {true, func(m AM) { m.Config["URL"] = "ldaps://ldap.example.org"; delete(m.Config, "StartTLS") }},
+		"success - anonymous":    {true, func(m AM) { delete(m.Config, "BindDN"); delete(m.Config, "BindPassword") }},
+		"wrong type":             {false, func(m AM) { m.Type = "not-ldap" }},
+		"extra config":           {false, func(m AM) { m.Config["extraField"] = "123" }},
+		"missing url":            {false, func(m AM) { delete(m.Config, "URL") }},
+		"wrong url scheme":       {false, func(m AM) { m.Config["URL"] = "https://ldap.example.org" }},
+		"start tls with ldaps":   {false, func(m AM) { m.Config["URL"] = "ldaps://ldap.example.org" }},
+		"ldap without start tls": {false, func(m AM) { delete(m.Config, "StartTLS") }},
+		"invalid ca cert":        {false, func(m AM) { m.Config["CACert"] = "not a cert" }},
+		"bind dn without secret": {false, func(m AM) { delete(m.Config, "BindPassword") }},
+		"missing user dn":        {false, func(m AM) { delete(m.Config, "UserDN") }},
+		"invalid group filter":   {false, func(m AM) { m.Config["GroupFilter"] = "(member={{.UserDN)" }},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, err := NewValidator(hclog.NewNullLogger(), makeMethod(c.modifyFn))
+			if c.ok {
+				require.NoError(t, err)
+				require.NotNil(t, v)
+				require.Equal(t, "test-ldap", v.Name())
+				require.Equal(t, "uid", v.config.UserAttribute)
+				require.Equal(t, "cn", v.config.GroupAttribute)
+				require.Equal(t, defaultGroupFilter, v.config.GroupFilter)
+			} else {
+				require.Error(t, err)
+				require.Nil(t, v)
+			}
+		})
+	}
+}
+
+func TestValidateLogin(t *testing.T) {
+	srv := StartTestLDAPServer(t, false)
+	defer srv.Stop()
+	tlsSrv := StartTestLDAPServer(t, true)
+	defer tlsSrv.Stop()
+
+	for _, s := range []*TestLDAPServer{srv, tlsSrv} {
+		s.AddEntry("cn=consul,dc=example,dc=org", map[string][]string{"cn": {"consul"}})
+		s.SetPassword("cn=consul,dc=example,dc=org", "search-secret")
+
+		s.AddEntry("uid=alice,ou=people,dc=example,dc=org", map[string][]string{
+			"uid":        {"alice"},
+			"mail":       {"alice@example.org"},
+			"department": {"platform"},
+		})
+		s.SetPassword("uid=alice,ou=people,dc=example,dc=org", "alice-secret")
+		s.AddEntry("uid=bob,ou=people,dc=example,dc=org", map[string][]string{"uid": {"bob"}})
+		s.SetPassword("uid=bob,ou=people,dc=example,dc=org", "bob-secret")
+
+		s.AddEntry("cn=operators,ou=groups,dc=example,dc=org", map[string][]string{
+			"cn":     {"operators"},
+			"member": {"uid=alice,ou=people,dc=example,dc=org"},
+		})
+		s.AddEntry("cn=dba,ou=groups,dc=example,dc=org", map[string][]string{
+			"cn":        {"dba"},
+			"memberUid": {"alice", "bob"},
+		})
+		s.AddEntry("cn=web,ou=groups,dc=example,dc=org", map[string][]string{
+			"cn":     {"web"},
+			"member": {"uid=bob,ou=people,dc=example,dc=org"},
+		})
+	}
+
+	config := func(modifyFn func(map[string]interface{})) map[string]interface{} {
+		c := map[string]interface{}{
+			"URL":          "ldap://" + srv.Addr(),
+			"StartTLS":     true,
+			"CACert":       srv.CACert(),
+			"BindDN":       "cn=consul,dc=example,dc=org",
+			"BindPassword": "search-secret",
+			"UserDN":       "ou=people,dc=example,dc=org",
+			"GroupDN":      "ou=groups,dc=example,dc=org",
+			"Attributes":   []string{"mail", "department"},
+		}
+		if modifyFn != nil {
+			modifyFn(c)
+		}
+		return c
+	}
+	token := func(username, password string) string {
+		tok, err := NewLoginToken(username, password)
+		require.NoError(t, err)
+		return tok
+	}
+
+	aliceVars := map[string]string{
+		"username":              "alice",
+		"user_dn":               "uid=alice,ou=people,dc=example,dc=org",
+		"attributes.mail":       "alice@example.org",
+		"attributes.department": "platform",
+	}
+	aliceFields := []string{
+		`username == "alice"`,
+		`"operators" in groups`,
+		`"dba" in groups`,
+		`"web" not in groups`,
+		`attributes.mail == "alice@example.org"`,
+		`attributes.department == "platform"`,
+	}
+
+	cases := map[string]struct {
+		config    map[string]interface{}
+		token     string
+		expVars   map[string]string
+		expFields []string
+		expError  string
+	}{
+		"success": {
+			config:    config(nil),
+			token:     token("alice", "alice-secret"),
+			expVars:   aliceVars,
+			expFields: aliceFields,
+		},
+		"success - different casing": {
+			// The directory matches the username ignoring case, the identity
+			// uses the directory's value.
+			config:    config(nil),
+			token:     token("ALICE", "alice-secret"),
+			expVars:   aliceVars,
+			expFields: aliceFields,
+		},
+		"success - ldaps": {
+			config: config(func(c map[string]interface{}) {
+				c["URL"] = "ldaps://" + tlsSrv.Addr()
+				c["CACert"] = tlsSrv.CACert()
+				delete(c, "StartTLS")
+			}),
+			token:     token("alice", "alice-secret"),
+			expVars:   aliceVars,
+			expFields: aliceFields,
+		},
+		"success - anonymous search": {
+			config: config(func(c map[string]interface{}) {
+				delete(c, "BindDN")
+				delete(c, "BindPassword")
+			}),
+			token:     token("bob", "bob-secret"),
+			expFields: []string{`username == "bob"`, `"web" in groups`, `"dba" in groups`, `"operators" not in groups`},
+		},
+		"success - custom group filter": {
+			config: config(func(c map[string]interface{}) {
+				c["GroupFilter"] = "(member={{.UserDN}})"
+			}),
+			token:     token("alice", "alice-secret"),
+			expFields: []string{`"operators" in groups`, `"dba" not in groups`},
+		},
+		"success - no group dn": {
+			config: config(func(c map[string]interface{}) {
+				delete(c, "GroupDN")
+			}),
+			token:     token("alice", "alice-secret"),
+			expFields: []string{`groups is empty`},
+		},
+		"wrong password": {
+			config:   config(nil),
+			token:    token("alice", "bob-secret"),
+			expError: "invalid username or password",
+		},
+		"empty password": {
+			config:   config(nil),
+			token:    token("alice", ""),
+			expError: "invalid username or password",
+		},
+		"unknown user": {
+			config:   config(nil),
+			token:    token("mallory", "alice-secret"),
+			expError: "invalid username or password",
+		},
+		"filter injection": {
+			config:   config(nil),
+			token:    token("*", "alice-secret"),
+			expError: "invalid username or password",
+		},
+		"wrong search password": {
+			config: config(func(c map[string]interface{}) {
+				c["BindPassword"] = "wrong"
+			}),
+			token:    token("alice", "alice-secret"),
+			expError: "LDAP bind for search failed",
+		},
+		"untrusted server certificate": {
+			config: config(func(c map[string]interface{}) {
+				c["URL"] = "ldaps://" + tlsSrv.Addr()
+				delete(c, "StartTLS")
+				delete(c, "CACert")
+			}),
+			token:    token("alice", "alice-secret"),
+			expError: "failed to connect to LDAP server",
+		},
+		"untrusted server certificate - start tls": {
+			config: config(func(c map[string]interface{}) {
+				delete(c, "CACert")
+			}),
+			token:    token("alice", "alice-secret"),
+			expError: "failed to start TLS with LDAP server",
+		},
+		"invalid token": {
+			config:   config(nil),
+			token:    "alice:alice-secret",
+			expError: "failed to parse login token",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			method := &structs.ACLAuthMethod{
+				Name:   "test-method",
+				Type:   "ldap",
+				Config: c.config,
+			}
+			v, err := NewValidator(hclog.NewNullLogger(), method)
+			require.NoError(t, err)
+
+			id, err := v.ValidateLogin(context.Background(), c.token)
+			if c.expError != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.expError)
+				require.Nil(t, id)
+			} else {
+				require.NoError(t, err)
+				if c.expVars != nil {
+					require.Equal(t, c.expVars, id.ProjectedVars)
+				}
+				authmethod.RequireIdentityMatch(t, id, id.ProjectedVars, c.expFields...)
+			}
+		})
+	}
+}
+
+func TestNewIdentity(t *testing.T) {
+	method := &structs.ACLAuthMethod{
+		Name: "test-method",
+		Type: "ldap",
+		Config: map[string]interface{}{
+			"URL":        "ldap://ldap.example.org",
+			"StartTLS":   true,
+			"UserDN":     "ou=people,dc=example,dc=org",
+			"Attributes": []string{"mail"},
+		},
+	}
+	v, err := NewValidator(hclog.NewNullLogger(), method)
+	require.NoError(t, err)
+
+	id := v.NewIdentity()
+	authmethod.RequireIdentityMatch(t, id, map[string]string{
+		"username":        "",
+		"user_dn":         "",
+		"attributes.mail": "",
+	},
+		`username == ""`,
+		`groups is empty`,
+		`attributes.mail == ""`,
+	)
+}
@@ -0,0 +1,353 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package ldapauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/mitchellh/go-testing-interface"
+	"github.com/stretchr/testify/require"
+)
+
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// TestLDAPServer is an in-process stand-in for an LDAP directory, supporting
+// just enough of the protocol for the consul LDAP auth method:
+//
+//   - simple binds
+//   - searches with and, or, not, equality and presence filters
+//   - StartTLS
+//
+// Entries are added with AddEntry and their passwords set with SetPassword.
+type TestLDAPServer struct {
+	ln        net.Listener
+	tlsConfig *tls.Config
+	caCert    string
+
+	mu        sync.Mutex
+	entries   map[string]map[string][]string // lowercased DN to attributes
+	dns       map[string]string              // lowercased DN to DN
+	passwords map[string]string              // lowercased DN to password
+	binds     int
+}
+
+// StartTestLDAPServer starts a TestLDAPServer listening on 127.0.0.1. If
+// useTLS is true the server only accepts TLS connections, as ldaps://,
+// otherwise connections can be upgraded with StartTLS.
+func StartTestLDAPServer(t testing.T, useTLS bool) *TestLDAPServer {
+	cert, caCert := testServerCert(t)
+	s := &TestLDAPServer{
+		tlsConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		},
+		caCert:    caCert,
+		entries:   make(map[string]map[string][]string),
+		dns:       make(map[string]string),
+		passwords: make(map[string]string),
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	if useTLS {
+		ln = tls.NewListener(ln, s.tlsConfig)
+	}
+	s.ln = ln
+
+	go s.serve()
+	return s
+}
+
+// Stop shuts the server down.
+func (s *TestLDAPServer) Stop() {
+	_ = s.ln.Close()
+}
+
+// Addr returns the host:port the server is listening on.
+func (s *TestLDAPServer) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// CACert returns the PEM encoded CA certificate of the server's TLS
+// certificate.
+func (s *TestLDAPServer) CACert() string {
+	return s.caCert
+}
+
+// AddEntry adds or replaces the entry with the given DN.
+func (s *TestLDAPServer) AddEntry(dn string, attributes map[string][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attrs := make(map[string][]string, len(attributes))
+	for k, v := range attributes {
+		attrs[strings.ToLower(k)] = v
+	}
+	s.entries[strings.ToLower(dn)] = attrs
+	s.dns[strings.ToLower(dn)] = dn
+}
+
+// SetPassword sets the password used to bind as the given DN.
+func (s *TestLDAPServer) SetPassword(dn, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passwords[strings.ToLower(dn)] = password
+}
+
+// Binds returns the number of successful binds the server handled.
+func (s *TestLDAPServer) Binds() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.binds
+}
+
+func (s *TestLDAPServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *TestLDAPServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		id, _ := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			code := s.bind(op)
+			writeResult(conn, id, ldap.ApplicationBindResponse, code)
+
+		case ldap.ApplicationUnbindRequest:
+			return
+
+		case ldap.ApplicationSearchRequest:
+			code := s.search(conn, id, op)
+			writeResult(conn, id, ldap.ApplicationSearchResultDone, code)
+
+		case ldap.ApplicationExtendedRequest:
+			if len(op.Children) == 0 || op.Children[0].Data.String() != startTLSOID {
+				writeResult(conn, id, ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError)
+				continue
+			}
+			if _, ok := conn.(*tls.Conn); ok {
+				writeResult(conn, id, ldap.ApplicationExtendedResponse, ldap.LDAPResultOperationsError)
+				continue
+			}
+			writeResult(conn, id, ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess)
+
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+
+		default:
+			writeResult(conn, id, ldap.ApplicationExtendedResponse, ldap.LDAPResultUnwillingToPerform)
+		}
+	}
+}
+
+func (s *TestLDAPServer) bind(op *ber.Packet) uint16 {
+	if len(op.Children) < 3 {
+		return ldap.LDAPResultProtocolError
+	}
+	dn, _ := op.Children[1].Value.(string)
+	password := op.Children[2].Data.String()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if dn == "" && password == "" {
+		// anonymous bind
+		return ldap.LDAPResultSuccess
+	}
+	if expected, ok := s.passwords[strings.ToLower(dn)]; !ok || password == "" || password != expected {
+		return ldap.LDAPResultInvalidCredentials
+	}
+	s.binds++
+	return ldap.LDAPResultSuccess
+}
+
+func (s *TestLDAPServer) search(conn net.Conn, id int64, op *ber.Packet) uint16 {
+	if len(op.Children) < 8 {
+		return ldap.LDAPResultProtocolError
+	}
+	base, _ := op.Children[0].Value.(string)
+	scope, _ := op.Children[1].Value.(int64)
+	filter := op.Children[6]
+	var requested []string
+	for _, attr := range op.Children[7].Children {
+		if name, ok := attr.Value.(string); ok {
+			requested = append(requested, strings.ToLower(name))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	base = strings.ToLower(base)
+	for dn, attrs := range s.entries {
+		if !inScope(dn, base, int(scope)) {
+			continue
+		}
+		match, err := matchFilter(filter, attrs)
+		if err != nil {
+			return ldap.LDAPResultUnwillingToPerform
+		}
+		if !match {
+			continue
+		}
+		writeEntry(conn, id, s.dns[dn], attrs, requested)
+	}
+	return ldap.LDAPResultSuccess
+}
+
+func inScope(dn, base string, scope int) bool {
+	switch scope {
+	case ldap.ScopeBaseObject:
+		return dn == base
+	case ldap.ScopeSingleLevel:
+		parent := ""
+		if i := strings.Index(dn, ","); i >= 0 {
+			parent = dn[i+1:]
+		}
+		return parent == base
+	default:
+		return dn == base || base == "" || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+func matchFilter(filter *ber.Packet, attrs map[string][]string) (bool, error) {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if ok, err := matchFilter(child, attrs); err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if ok, err := matchFilter(child, attrs); err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+
+	case ldap.FilterNot:
+		if len(filter.Children) != 1 {
+			return false, fmt.Errorf("invalid not filter")
+		}
+		ok, err := matchFilter(filter.Children[0], attrs)
+		return !ok, err
+
+	case ldap.FilterEqualityMatch:
+		if len(filter.Children) != 2 {
+			return false, fmt.Errorf("invalid equality filter")
+		}
+		name := strings.ToLower(filter.Children[0].Data.String())
+		value := filter.Children[1].Data.String()
+		for _, v := range attrs[name] {
+			if strings.EqualFold(v, value) {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case ldap.FilterPresent:
+		return len(attrs[strings.ToLower(filter.Data.String())]) > 0, nil
+
+	default:
+		return false, fmt.Errorf("unsupported filter %s", ldap.FilterMap[uint64(filter.Tag)])
+	}
+}
+
+func writeEntry(conn net.Conn, id int64, dn string, attrs map[string][]string, requested []string) {
+	entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, "Object Name"))
+
+	list := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for _, name := range requested {
+		values, ok := attrs[name]
+		if !ok {
+			continue
+		}
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, v := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "Value"))
+		}
+		attr.AppendChild(set)
+		list.AppendChild(attr)
+	}
+	entry.AppendChild(list)
+
+	writeMessage(conn, id, entry)
+}
+
+func writeResult(conn net.Conn, id int64, tag ber.Tag, code uint16) {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+
+	writeMessage(conn, id, result)
+}
+
+func writeMessage(conn net.Conn, id int64, op *ber.Packet) {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "Message ID"))
+	packet.AppendChild(op)
+	_, _ = conn.Write(packet.Bytes())
+}
+
+// testServerCert returns a self-signed certificate for 127.0.0.1, along with
+// its PEM encoding.
+func testServerCert(t testing.T) (tls.Certificate, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test LDAP Server"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	return cert, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
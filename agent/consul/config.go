@@ -328,6 +328,22 @@ type Config struct {
 	// warning and discard the remaining updates.
 	CoordinateUpdateMaxBatches int
 
+	// ACLTokenLastUsedGranularity controls how precisely servers track when
+	// tokens were last used. A token's last use is only recorded again once
+	// the recorded one is older than this, so a larger granularity leads to
+	// fewer Raft transactions.
+	ACLTokenLastUsedGranularity time.Duration
+
+	// ACLTokenLastUsedUpdatePeriod controls how long a server batches token
+	// usage before sending it to the leader to be applied in a Raft
+	// transaction.
+	ACLTokenLastUsedUpdatePeriod time.Duration
+
+	// ACLTokenLastUsedUpdateBatchSize controls the maximum number of token
+	// usages a server sends to the leader in one period. Any remaining ones
+	// are kept for the next period.
+	ACLTokenLastUsedUpdateBatchSize int
+
 	// CheckOutputMaxSize control the max size of output of checks
 	CheckOutputMaxSize int
 
@@ -590,6 +606,12 @@ func DefaultConfig() *Config {
 		CoordinateUpdateBatchSize:  128,
 		CoordinateUpdateMaxBatches: 5,
 
+		// Each server applies at most one batch of token usage per period,
+		// and records a token's use at most once per granularity.
+		ACLTokenLastUsedGranularity:     1 * time.Hour,
+		ACLTokenLastUsedUpdatePeriod:    1 * time.Minute,
+		ACLTokenLastUsedUpdateBatchSize: 512,
+
 		CheckOutputMaxSize: checks.DefaultBufSize,
 
 		RequestLimitsMode:      "disabled",
@@ -106,6 +106,14 @@ var CommandsSummaries = []prometheus.SummaryDefinition{
 		Name: []string{"fsm", "peering"},
 		Help: "Measures the time it takes to apply a peering operation to the FSM.",
 	},
+	{
+		Name: []string{"fsm", "kvs_history_config"},
+		Help: "Measures the time it takes to apply a KV history configuration operation to the FSM.",
+	},
+	{
+		Name: []string{"fsm", "kvs_quota"},
+		Help: "Measures the time it takes to apply a KV quota operation to the FSM.",
+	},
 	// TODO(kit): We generate the config-entry fsm summaries by reading off of the request. It is
 	//  possible to statically declare these when we know all of the names, but I didn't get to it
 	//  in this patch. Config-entries are known though and we should add these in the future.
@@ -128,10 +136,13 @@ func init() {
 	registerCommand(structs.TxnRequestType, (*FSM).applyTxn)
 	registerCommand(structs.AutopilotRequestType, (*FSM).applyAutopilotUpdate)
 	registerCommand(structs.FeatureGateRequestType, (*FSM).applyFeatureGateUpdate)
+	registerCommand(structs.KVSHistoryConfigRequestType, (*FSM).applyKVSHistoryConfigOperation)
+	registerCommand(structs.KVSQuotaRequestType, (*FSM).applyKVSQuotaOperation)
 	registerCommand(structs.IntentionRequestType, (*FSM).applyIntentionOperation)
 	registerCommand(structs.ConnectCARequestType, (*FSM).applyConnectCAOperation)
 	registerCommand(structs.ACLTokenSetRequestType, (*FSM).applyACLTokenSetOperation)
 	registerCommand(structs.ACLTokenDeleteRequestType, (*FSM).applyACLTokenDeleteOperation)
+	registerCommand(structs.ACLTokenLastUsedSetRequestType, (*FSM).applyACLTokenLastUsedSetOperation)
 	registerCommand(structs.ACLBootstrapRequestType, (*FSM).applyACLTokenBootstrap)
 	registerCommand(structs.ACLPolicySetRequestType, (*FSM).applyACLPolicySetOperation)
 	registerCommand(structs.ACLPolicyDeleteRequestType, (*FSM).applyACLPolicyDeleteOperation)
@@ -386,6 +397,44 @@ func (c *FSM) applyFeatureGateUpdate(buf []byte, index uint64) interface{} {
 	return applied
 }
 
+func (c *FSM) applyKVSHistoryConfigOperation(buf []byte, index uint64) interface{} {
+	var req structs.KVSHistoryConfigRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+	defer metrics.MeasureSinceWithLabels([]string{"fsm", "kvs_history_config"}, time.Now(),
+		[]metrics.Label{{Name: "op", Value: string(req.Op)}})
+
+	switch req.Op {
+	case structs.KVSHistoryConfigUpsert:
+		return c.state.KVSHistoryConfigSet(index, &req.Config)
+	case structs.KVSHistoryConfigDelete:
+		return c.state.KVSHistoryConfigDelete(index, req.Config.Prefix)
+	default:
+		c.logger.Warn("Invalid KV history config operation", "operation", req.Op)
+		return fmt.Errorf("Invalid KV history config operation '%s'", req.Op)
+	}
+}
+
+func (c *FSM) applyKVSQuotaOperation(buf []byte, index uint64) interface{} {
+	var req structs.KVSQuotaRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+	defer metrics.MeasureSinceWithLabels([]string{"fsm", "kvs_quota"}, time.Now(),
+		[]metrics.Label{{Name: "op", Value: string(req.Op)}})
+
+	switch req.Op {
+	case structs.KVSQuotaUpsert:
+		return c.state.KVSQuotaSet(index, &req.Quota)
+	case structs.KVSQuotaDelete:
+		return c.state.KVSQuotaDelete(index, req.Quota.Prefix, &req.Quota.EnterpriseMeta)
+	default:
+		c.logger.Warn("Invalid KV quota operation", "operation", req.Op)
+		return fmt.Errorf("Invalid KV quota operation '%s'", req.Op)
+	}
+}
+
 // applyIntentionOperation applies the given intention operation to the state store.
 func (c *FSM) applyIntentionOperation(buf []byte, index uint64) interface{} {
 	var req structs.IntentionRequest
@@ -551,6 +600,17 @@ func (c *FSM) applyACLTokenDeleteOperation(buf []byte, index uint64) interface{}
 	return c.state.ACLTokenBatchDelete(index, req.TokenIDs)
 }
 
+func (c *FSM) applyACLTokenLastUsedSetOperation(buf []byte, index uint64) interface{} {
+	var req structs.ACLTokenLastUsedSetRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+	defer metrics.MeasureSinceWithLabels([]string{"fsm", "acl", "token"}, time.Now(),
+		[]metrics.Label{{Name: "op", Value: "last-used"}})
+
+	return c.state.ACLTokenLastUsedSet(index, req.Entries)
+}
+
 func (c *FSM) applyACLTokenBootstrap(buf []byte, index uint64) interface{} {
 	var req structs.ACLTokenBootstrapRequest
 	if err := structs.Decode(buf, &req); err != nil {
@@ -1426,6 +1426,93 @@ func TestFSM_ConfigEntry(t *testing.T) {
 	}
 }
 
+func TestFSM_KVSHistoryConfig(t *testing.T) {
+	t.Parallel()
+
+	logger := testutil.Logger(t)
+	fsm, err := New(nil, logger)
+	require.NoError(t, err)
+
+	req := structs.KVSHistoryConfigRequest{
+		Datacenter: "dc1",
+		Op:         structs.KVSHistoryConfigUpsert,
+		Config:     structs.KVSHistoryConfig{Prefix: "foo/", Revisions: 3},
+	}
+	buf, err := structs.Encode(structs.KVSHistoryConfigRequestType, req)
+	require.NoError(t, err)
+	resp := fsm.Apply(makeLog(buf))
+	require.Nil(t, resp)
+
+	_, configs, err := fsm.state.KVSHistoryConfigs(nil)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	require.Equal(t, "foo/", configs[0].Prefix)
+	require.Equal(t, 3, configs[0].Revisions)
+
+	req.Op = structs.KVSHistoryConfigDelete
+	buf, err = structs.Encode(structs.KVSHistoryConfigRequestType, req)
+	require.NoError(t, err)
+	resp = fsm.Apply(makeLog(buf))
+	require.Nil(t, resp)
+
+	_, configs, err = fsm.state.KVSHistoryConfigs(nil)
+	require.NoError(t, err)
+	require.Empty(t, configs)
+}
+
+func TestFSM_KVSQuota(t *testing.T) {
+	t.Parallel()
+
+	logger := testutil.Logger(t)
+	fsm, err := New(nil, logger)
+	require.NoError(t, err)
+
+	req := structs.KVSQuotaRequest{
+		Datacenter: "dc1",
+		Op:         structs.KVSQuotaUpsert,
+		Quota:      structs.KVSQuota{Prefix: "foo/", MaxKeys: 1},
+	}
+	buf, err := structs.Encode(structs.KVSQuotaRequestType, req)
+	require.NoError(t, err)
+	resp := fsm.Apply(makeLog(buf))
+	require.Nil(t, resp)
+
+	_, quotas, err := fsm.state.KVSQuotas(nil)
+	require.NoError(t, err)
+	require.Len(t, quotas, 1)
+	require.Equal(t, "foo/", quotas[0].Prefix)
+	require.Equal(t, 1, quotas[0].MaxKeys)
+
+	// Writes over the quota fail.
+	for i, key := range []string{"foo/a", "foo/b"} {
+		kvReq := structs.KVSRequest{
+			Datacenter: "dc1",
+			Op:         api.KVSet,
+			DirEnt:     structs.DirEntry{Key: key, Value: []byte("test")},
+		}
+		buf, err := structs.Encode(structs.KVSRequestType, kvReq)
+		require.NoError(t, err)
+		resp := fsm.Apply(makeLog(buf))
+		if i == 0 {
+			require.Nil(t, resp)
+			continue
+		}
+		err, ok := resp.(error)
+		require.True(t, ok, "resp: %v", resp)
+		require.True(t, structs.IsErrKVSQuotaExceeded(err))
+	}
+
+	req.Op = structs.KVSQuotaDelete
+	buf, err = structs.Encode(structs.KVSQuotaRequestType, req)
+	require.NoError(t, err)
+	resp = fsm.Apply(makeLog(buf))
+	require.Nil(t, resp)
+
+	_, quotas, err = fsm.state.KVSQuotas(nil)
+	require.NoError(t, err)
+	require.Empty(t, quotas)
+}
+
 func TestFSM_ConfigEntry_StatusCAS(t *testing.T) {
 	t.Parallel()
 
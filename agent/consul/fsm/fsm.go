@@ -452,6 +452,14 @@ func (c *FSM) registerStreamSnapshotHandlers() {
 		return c.State().ExportedServicesSnapshot(req, buf)
 	}, true)
 	panicIfErr(err)
+
+	// Every KV event is also published to each of the key's prefixes, so a
+	// wildcard subscription would see duplicates; the empty prefix is used
+	// instead.
+	err = c.deps.Publisher.RegisterHandler(state.EventTopicKV, func(req stream.SubscribeRequest, buf stream.SnapshotAppender) (uint64, error) {
+		return c.State().KVSnapshot(req, buf)
+	}, false)
+	panicIfErr(err)
 }
 
 func panicIfErr(err error) {
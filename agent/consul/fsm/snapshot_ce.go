@@ -22,6 +22,9 @@ func init() {
 	registerRestorer(structs.RegisterRequestType, restoreRegistration)
 	registerRestorer(structs.KVSRequestType, restoreKV)
 	registerRestorer(structs.TombstoneRequestType, restoreTombstone)
+	registerRestorer(structs.KVSHistoryConfigRequestType, restoreKVSHistoryConfig)
+	registerRestorer(structs.KVSRevisionType, restoreKVSRevision)
+	registerRestorer(structs.KVSQuotaRequestType, restoreKVSQuota)
 	registerRestorer(structs.SessionRequestType, restoreSession)
 	registerRestorer(structs.CoordinateBatchUpdateType, restoreCoordinates)
 	registerRestorer(structs.PreparedQueryRequestType, restorePreparedQuery)
@@ -66,6 +69,12 @@ func persistCE(s *snapshot, sink raft.SnapshotSink, encoder *codec.Encoder) erro
 	if err := s.persistTombstones(sink, encoder); err != nil {
 		return err
 	}
+	if err := s.persistKVsHistory(sink, encoder); err != nil {
+		return err
+	}
+	if err := s.persistKVsQuotas(sink, encoder); err != nil {
+		return err
+	}
 	if err := s.persistPreparedQueries(sink, encoder); err != nil {
 		return err
 	}
@@ -331,6 +340,58 @@ func (s *snapshot) persistTombstones(sink raft.SnapshotSink,
 	return nil
 }
 
+func (s *snapshot) persistKVsHistory(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	configs, err := s.state.KVsHistoryConfig()
+	if err != nil {
+		return err
+	}
+
+	for config := configs.Next(); config != nil; config = configs.Next() {
+		if _, err := sink.Write([]byte{byte(structs.KVSHistoryConfigRequestType)}); err != nil {
+			return err
+		}
+		if err := encoder.Encode(config.(*structs.KVSHistoryConfig)); err != nil {
+			return err
+		}
+	}
+
+	revisions, err := s.state.KVsHistory()
+	if err != nil {
+		return err
+	}
+
+	for rev := revisions.Next(); rev != nil; rev = revisions.Next() {
+		if _, err := sink.Write([]byte{byte(structs.KVSRevisionType)}); err != nil {
+			return err
+		}
+		if err := encoder.Encode(rev.(*structs.DirEntryRevision)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// persistKVsQuotas must run after persistKVs, restoring a quota computes its
+// usage from the restored KV entries.
+func (s *snapshot) persistKVsQuotas(sink raft.SnapshotSink,
+	encoder *codec.Encoder) error {
+	quotas, err := s.state.KVsQuotas()
+	if err != nil {
+		return err
+	}
+
+	for quota := quotas.Next(); quota != nil; quota = quotas.Next() {
+		if _, err := sink.Write([]byte{byte(structs.KVSQuotaRequestType)}); err != nil {
+			return err
+		}
+		if err := encoder.Encode(quota.(*structs.KVSQuota)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *snapshot) persistPreparedQueries(sink raft.SnapshotSink,
 	encoder *codec.Encoder) error {
 	queries, err := s.state.PreparedQueries()
@@ -671,6 +732,30 @@ func restoreKV(header *SnapshotHeader, restore *state.Restore, decoder *codec.De
 	return nil
 }
 
+func restoreKVSHistoryConfig(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
+	var req structs.KVSHistoryConfig
+	if err := decoder.Decode(&req); err != nil {
+		return err
+	}
+	return restore.KVSHistoryConfig(&req)
+}
+
+func restoreKVSRevision(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
+	var req structs.DirEntryRevision
+	if err := decoder.Decode(&req); err != nil {
+		return err
+	}
+	return restore.KVSRevision(&req)
+}
+
+func restoreKVSQuota(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
+	var req structs.KVSQuota
+	if err := decoder.Decode(&req); err != nil {
+		return err
+	}
+	return restore.KVSQuota(&req)
+}
+
 func restoreTombstone(header *SnapshotHeader, restore *state.Restore, decoder *codec.Decoder) error {
 	var req structs.DirEntry
 	if err := decoder.Decode(&req); err != nil {
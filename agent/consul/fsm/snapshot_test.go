@@ -1026,6 +1026,112 @@ func TestFSM_BadSnapshot_NilCAConfig(t *testing.T) {
 	require.Nil(t, config)
 }
 
+func TestFSM_SnapshotRestore_KVSHistory(t *testing.T) {
+	t.Parallel()
+
+	logger := testutil.Logger(t)
+	fsm := NewFromDeps(Deps{
+		Logger: logger,
+		NewStateStore: func() *state.Store {
+			return state.NewStateStore(nil)
+		},
+		StorageBackend: newStorageBackend(t, nil),
+	})
+
+	config := &structs.KVSHistoryConfig{Prefix: "foo/", Revisions: 5}
+	require.NoError(t, fsm.state.KVSHistoryConfigSet(1, config))
+	require.NoError(t, fsm.state.KVSSet(2, &structs.DirEntry{Key: "foo/bar", Value: []byte("1")}))
+	require.NoError(t, fsm.state.KVSSet(3, &structs.DirEntry{Key: "foo/bar", Value: []byte("2")}))
+	require.NoError(t, fsm.state.KVSDelete(4, "foo/bar", nil))
+
+	// Snapshot
+	snap, err := fsm.Snapshot()
+	require.NoError(t, err)
+	defer snap.Release()
+
+	// Persist
+	buf := bytes.NewBuffer(nil)
+	sink := &MockSink{buf, false}
+	require.NoError(t, snap.Persist(sink))
+
+	// Try to restore on a new FSM
+	fsm2 := NewFromDeps(Deps{
+		Logger: logger,
+		NewStateStore: func() *state.Store {
+			return state.NewStateStore(nil)
+		},
+		StorageBackend: newStorageBackend(t, nil),
+	})
+	require.NoError(t, fsm2.Restore(sink))
+
+	_, configs, err := fsm2.state.KVSHistoryConfigs(nil)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	require.Equal(t, "foo/", configs[0].Prefix)
+	require.Equal(t, 5, configs[0].Revisions)
+
+	_, revs, err := fsm2.state.KVSRevisions(nil, "foo/bar", nil)
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+	require.Equal(t, "1", string(revs[0].Value))
+	require.Equal(t, uint64(3), revs[0].SupersededIndex)
+	require.Equal(t, "2", string(revs[1].Value))
+	require.True(t, revs[1].Deleted)
+
+	_, ent, err := fsm2.state.KVSGetAtIndex(nil, "foo/bar", 3, nil)
+	require.NoError(t, err)
+	require.Equal(t, "2", string(ent.Value))
+}
+
+func TestFSM_SnapshotRestore_KVSQuota(t *testing.T) {
+	t.Parallel()
+
+	logger := testutil.Logger(t)
+	fsm := NewFromDeps(Deps{
+		Logger: logger,
+		NewStateStore: func() *state.Store {
+			return state.NewStateStore(nil)
+		},
+		StorageBackend: newStorageBackend(t, nil),
+	})
+
+	require.NoError(t, fsm.state.KVSSet(1, &structs.DirEntry{Key: "foo/bar", Value: []byte("123")}))
+	require.NoError(t, fsm.state.KVSQuotaSet(2, &structs.KVSQuota{Prefix: "foo/", MaxKeys: 1}))
+
+	// Snapshot
+	snap, err := fsm.Snapshot()
+	require.NoError(t, err)
+	defer snap.Release()
+
+	// Persist
+	buf := bytes.NewBuffer(nil)
+	sink := &MockSink{buf, false}
+	require.NoError(t, snap.Persist(sink))
+
+	// Try to restore on a new FSM
+	fsm2 := NewFromDeps(Deps{
+		Logger: logger,
+		NewStateStore: func() *state.Store {
+			return state.NewStateStore(nil)
+		},
+		StorageBackend: newStorageBackend(t, nil),
+	})
+	require.NoError(t, fsm2.Restore(sink))
+
+	_, quotas, err := fsm2.state.KVSQuotas(nil)
+	require.NoError(t, err)
+	require.Len(t, quotas, 1)
+	require.Equal(t, "foo/", quotas[0].Prefix)
+
+	// The usage is rebuilt from the restored entries.
+	_, usage, err := fsm2.state.KVUsage()
+	require.NoError(t, err)
+	require.Equal(t, []state.KVSQuotaUsage{{Prefix: "foo/", Keys: 1, Bytes: 3}}, usage.QuotaUsage)
+
+	err = fsm2.state.KVSSet(3, &structs.DirEntry{Key: "foo/baz", Value: []byte("1")})
+	require.True(t, structs.IsErrKVSQuotaExceeded(err), "err: %v", err)
+}
+
 // This test asserts that ServiceVirtualIP, which made a breaking change
 // in 1.13.0, can still restore from older snapshots which use the old
 // state.ServiceVirtualIP type.
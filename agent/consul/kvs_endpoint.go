@@ -4,6 +4,7 @@
 package consul
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -20,6 +21,13 @@ import (
 	"github.com/hashicorp/consul/api"
 )
 
+var KVSCounters = []prometheus.CounterDefinition{
+	{
+		Name: []string{"kvs", "expired"},
+		Help: "Counts the number of KV entries deleted by the leader because their expiration time passed.",
+	},
+}
+
 var KVSummaries = []prometheus.SummaryDefinition{
 	{
 		Name: []string{"kvs", "apply"},
@@ -56,7 +64,7 @@ func kvsPreApply(logger hclog.Logger, srv *Server, authz resolver.Result, op api
 	case api.KVGet, api.KVGetTree, api.KVGetOrEmpty:
 		// Filtering for GETs is done on the output side.
 
-	case api.KVCheckSession, api.KVCheckIndex:
+	case api.KVCheckSession, api.KVCheckIndex, api.KVCheckFence:
 		// These could reveal information based on the outcome
 		// of the transaction, and they operate on individual
 		// keys so we check them here.
@@ -78,6 +86,13 @@ func kvsPreApply(logger hclog.Logger, srv *Server, authz resolver.Result, op api
 		return false, fmt.Errorf("unknown KV operation: %s", op)
 	}
 
+	// Resolve any requested TTL into an absolute expiration time. This is
+	// done before commit for the same reason as lock-delay below: wall-time
+	// must only be sampled on the leader so the FSMs stay consistent.
+	if err := kvsPrepareExpiration(op, dirEnt, time.Now()); err != nil {
+		return false, err
+	}
+
 	// If this is a lock, we must check for a lock-delay. Since lock-delay
 	// is based on wall-time, each peer would expire the lock-delay at a slightly
 	// different time. This means the enforcement of lock-delay cannot be done
@@ -99,6 +114,35 @@ func kvsPreApply(logger hclog.Logger, srv *Server, authz resolver.Result, op api
 	return true, nil
 }
 
+// kvsPrepareExpiration validates the expiration fields of the entry and
+// converts any ExpirationTTL into an ExpirationTime relative to now.
+func kvsPrepareExpiration(op api.KVOp, dirEnt *structs.DirEntry, now time.Time) error {
+	switch op {
+	case api.KVSet, api.KVCAS, api.KVLock, api.KVUnlock:
+	default:
+		// Only operations that write a value can carry an expiration.
+		dirEnt.ExpirationTTL = 0
+		dirEnt.ExpirationTime = nil
+		return nil
+	}
+
+	if dirEnt.ExpirationTTL < 0 {
+		return fmt.Errorf("Key expiration TTL cannot be negative")
+	}
+	if dirEnt.ExpirationTTL != 0 {
+		if dirEnt.HasExpirationTime() {
+			return fmt.Errorf("Key expiration TTL and expiration time cannot both be set")
+		}
+		expirationTime := now.Add(dirEnt.ExpirationTTL)
+		dirEnt.ExpirationTime = &expirationTime
+		dirEnt.ExpirationTTL = 0
+	}
+	if dirEnt.HasExpirationTime() && dirEnt.ExpirationTime.Unix() < 0 {
+		return fmt.Errorf("Key expiration time cannot be before the unix epoch")
+	}
+	return nil
+}
+
 // Apply is used to apply a KVS update request to the data store.
 func (k *KVS) Apply(args *structs.KVSRequest, reply *bool) error {
 	if done, err := k.srv.ForwardRPC("KVS.Apply", args, reply); done {
@@ -127,7 +171,10 @@ func (k *KVS) Apply(args *structs.KVSRequest, reply *bool) error {
 
 	// Apply the update.
 	resp, err := k.srv.raftApply(structs.KVSRequestType, args)
-	if err != nil {
+	if errors.Is(err, structs.ErrKVSQuotaExceeded) {
+		// Returned as is so callers can still recognize it over RPC.
+		return err
+	} else if err != nil {
 		return fmt.Errorf("raft apply failed: %w", err)
 	}
 
@@ -158,7 +205,16 @@ func (k *KVS) Get(args *structs.KeyRequest, reply *structs.IndexedDirEntries) er
 		&args.QueryOptions,
 		&reply.QueryMeta,
 		func(ws memdb.WatchSet, state *state.Store) error {
-			index, ent, err := state.KVSGet(ws, args.Key, &args.EnterpriseMeta)
+			var (
+				index uint64
+				ent   *structs.DirEntry
+				err   error
+			)
+			if args.AtIndex != 0 {
+				index, ent, err = state.KVSGetAtIndex(ws, args.Key, args.AtIndex, &args.EnterpriseMeta)
+			} else {
+				index, ent, err = state.KVSGet(ws, args.Key, &args.EnterpriseMeta)
+			}
 			if err != nil {
 				return err
 			}
@@ -173,11 +229,57 @@ func (k *KVS) Get(args *structs.KeyRequest, reply *structs.IndexedDirEntries) er
 			}
 
 			reply.Index = ent.ModifyIndex
+			if args.AtIndex != 0 {
+				// Newer revisions do not change the result, but the index must
+				// still move forward so blocking queries return.
+				reply.Index = index
+			}
 			reply.Entries = structs.DirEntries{ent}
 			return nil
 		})
 }
 
+// Revisions is used to list the retained revisions of a single key.
+func (k *KVS) Revisions(args *structs.KeyRequest, reply *structs.IndexedDirEntryRevisions) error {
+	if done, err := k.srv.ForwardRPC("KVS.Revisions", args, reply); done {
+		return err
+	}
+
+	var authzContext acl.AuthorizerContext
+	authz, err := k.srv.ResolveTokenAndDefaultMeta(args.Token, &args.EnterpriseMeta, &authzContext)
+	if err != nil {
+		return err
+	}
+
+	if err := k.srv.validateEnterpriseRequest(&args.EnterpriseMeta, false); err != nil {
+		return err
+	}
+
+	if err := authz.ToAllowAuthorizer().KeyReadAllowed(args.Key, &authzContext); err != nil {
+		return err
+	}
+
+	return k.srv.blockingQuery(
+		&args.QueryOptions,
+		&reply.QueryMeta,
+		func(ws memdb.WatchSet, state *state.Store) error {
+			index, revs, err := state.KVSRevisions(ws, args.Key, &args.EnterpriseMeta)
+			if err != nil {
+				return err
+			}
+
+			// Must provide non-zero index to prevent blocking
+			// Index 1 is impossible anyways (due to Raft internals)
+			if index == 0 {
+				reply.Index = 1
+			} else {
+				reply.Index = index
+			}
+			reply.Revisions = revs
+			return nil
+		})
+}
+
 // List is used to list all keys with a given prefix.
 func (k *KVS) List(args *structs.KeyRequest, reply *structs.IndexedDirEntries) error {
 	if done, err := k.srv.ForwardRPC("KVS.List", args, reply); done {
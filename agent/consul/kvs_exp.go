@@ -0,0 +1,102 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package consul
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-metrics"
+	"golang.org/x/time/rate"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+)
+
+const (
+	// kvsReapingRateLimit is the number of batch KV reaping requests per second allowed.
+	kvsReapingRateLimit rate.Limit = 1.0
+
+	// kvsReapingBurst is the number of batch KV reaping requests per second
+	// that can burst after a period of idleness.
+	kvsReapingBurst = 5
+
+	// kvsBatchDeleteSize is the number of expired keys to delete in a single
+	// transaction.
+	kvsBatchDeleteSize = 128
+)
+
+func (s *Server) reapExpiredKVs(ctx context.Context) error {
+	limiter := rate.NewLimiter(kvsReapingRateLimit, kvsReapingBurst)
+	for {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		if _, err := s.reapExpiredKVEntries(time.Now()); err != nil {
+			s.logger.Error("error reaping expired KV entries", "error", err)
+		}
+	}
+}
+
+func (s *Server) startKVSReaping(ctx context.Context) {
+	s.leaderRoutineManager.Start(ctx, kvsReapingRoutineName, s.reapExpiredKVs)
+}
+
+func (s *Server) stopKVSReaping() {
+	s.leaderRoutineManager.Stop(kvsReapingRoutineName)
+}
+
+// reapExpiredKVEntries deletes a single batch of KV entries that have expired
+// as of now. Each delete is a check-and-set against the ModifyIndex that was
+// observed, so an entry that is rewritten concurrently is not removed.
+func (s *Server) reapExpiredKVEntries(now time.Time) (int, error) {
+	minExpiredTime, err := s.fsm.State().KVSMinExpirationTime()
+	if err != nil {
+		return 0, err
+	}
+	if minExpiredTime.IsZero() || minExpiredTime.After(now) {
+		return 0, nil // nothing to do
+	}
+
+	entries, err := s.fsm.State().KVSListExpired(now, kvsBatchDeleteSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	req := structs.TxnRequest{Ops: make(structs.TxnOps, 0, len(entries))}
+	for _, entry := range entries {
+		req.Ops = append(req.Ops, &structs.TxnOp{
+			KV: &structs.TxnKVOp{
+				Verb: api.KVDeleteCAS,
+				DirEnt: structs.DirEntry{
+					Key:            entry.Key,
+					EnterpriseMeta: entry.EnterpriseMeta,
+					RaftIndex: structs.RaftIndex{
+						ModifyIndex: entry.ModifyIndex,
+					},
+				},
+			},
+		})
+	}
+
+	s.logger.Debug("deleting expired KV entries", "amount", len(req.Ops))
+
+	resp, err := s.leaderRaftApply("KVS.Reap", structs.TxnRequestType, &req)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to apply KV expiration deletions: %v", err)
+	}
+	if txnResp, ok := resp.(structs.TxnResponse); ok && len(txnResp.Errors) > 0 {
+		// One of the entries was modified after we listed it. The whole batch
+		// is rolled back and will be retried on the next pass.
+		return 0, fmt.Errorf("Failed to apply KV expiration deletions: %v", txnResp.Error())
+	}
+
+	metrics.IncrCounter([]string{"kvs", "expired"}, float32(len(req.Ops)))
+	return len(req.Ops), nil
+}
@@ -0,0 +1,112 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package consul
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	msgpackrpc "github.com/hashicorp/consul-net-rpc/net-rpc-msgpackrpc"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestKVSPrepareExpiration(t *testing.T) {
+	now := time.Now()
+
+	t.Run("ttl is converted", func(t *testing.T) {
+		ent := &structs.DirEntry{Key: "foo", ExpirationTTL: time.Minute}
+		require.NoError(t, kvsPrepareExpiration(api.KVSet, ent, now))
+		require.Zero(t, ent.ExpirationTTL)
+		require.True(t, now.Add(time.Minute).Equal(*ent.ExpirationTime))
+	})
+
+	t.Run("negative ttl", func(t *testing.T) {
+		ent := &structs.DirEntry{Key: "foo", ExpirationTTL: -time.Minute}
+		require.Error(t, kvsPrepareExpiration(api.KVSet, ent, now))
+	})
+
+	t.Run("ttl and time", func(t *testing.T) {
+		exp := now.Add(time.Hour)
+		ent := &structs.DirEntry{Key: "foo", ExpirationTTL: time.Minute, ExpirationTime: &exp}
+		require.Error(t, kvsPrepareExpiration(api.KVCAS, ent, now))
+	})
+
+	t.Run("ignored for deletes", func(t *testing.T) {
+		ent := &structs.DirEntry{Key: "foo", ExpirationTTL: time.Minute}
+		require.NoError(t, kvsPrepareExpiration(api.KVDelete, ent, now))
+		require.Zero(t, ent.ExpirationTTL)
+		require.Nil(t, ent.ExpirationTime)
+	})
+}
+
+func TestKVSReap(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	dir1, s1 := testServer(t)
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	testrpc.WaitForTestAgent(t, s1.RPC, "dc1")
+
+	set := func(key string, ttl time.Duration) {
+		arg := structs.KVSRequest{
+			Datacenter: "dc1",
+			Op:         api.KVSet,
+			DirEnt: structs.DirEntry{
+				Key:           key,
+				Value:         []byte("test"),
+				ExpirationTTL: ttl,
+			},
+		}
+		var out bool
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "KVS.Apply", &arg, &out))
+	}
+	set("short", time.Hour)
+	set("long", 2*time.Hour)
+	set("forever", 0)
+
+	state := s1.fsm.State()
+	_, short, err := state.KVSGet(nil, "short", nil)
+	require.NoError(t, err)
+	require.NotNil(t, short.ExpirationTime)
+	require.Zero(t, short.ExpirationTTL)
+
+	// Nothing has expired yet.
+	n, err := s1.reapExpiredKVEntries(time.Now())
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// Pretend the first TTL has passed.
+	n, err = s1.reapExpiredKVEntries(time.Now().Add(90 * time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	_, ent, err := state.KVSGet(nil, "short", nil)
+	require.NoError(t, err)
+	require.Nil(t, ent)
+	_, ent, err = state.KVSGet(nil, "long", nil)
+	require.NoError(t, err)
+	require.NotNil(t, ent)
+
+	// Rewriting a key without a TTL keeps it from being reaped.
+	set("long", 0)
+	n, err = s1.reapExpiredKVEntries(time.Now().Add(3 * time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	_, entries, err := state.KVSList(nil, "", nil)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
@@ -324,6 +324,8 @@ func (s *Server) establishLeadership(ctx context.Context) error {
 
 	s.startDeferredDeletion(ctx)
 
+	s.startKVSReaping(ctx)
+
 	if err := s.startConnectLeader(ctx); err != nil {
 		return err
 	}
@@ -366,6 +368,8 @@ func (s *Server) revokeLeadership() {
 
 	s.stopDeferredDeletion()
 
+	s.stopKVSReaping()
+
 	s.stopFederationStateAntiEntropy()
 
 	s.stopFederationStateReplication()
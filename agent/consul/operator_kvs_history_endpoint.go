@@ -0,0 +1,84 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package consul
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/hashicorp/consul/agent/consul/state"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+// KVSHistoryConfigList is used to retrieve the KV revision retention
+// configuration for all prefixes.
+func (op *Operator) KVSHistoryConfigList(args *structs.DCSpecificRequest, reply *structs.IndexedKVSHistoryConfigs) error {
+	if done, err := op.srv.ForwardRPC("Operator.KVSHistoryConfigList", args, reply); done {
+		return err
+	}
+
+	// This action requires operator read access.
+	authz, err := op.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := op.srv.validateEnterpriseToken(authz.Identity()); err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorReadAllowed(nil); err != nil {
+		return err
+	}
+
+	return op.srv.blockingQuery(&args.QueryOptions, &reply.QueryMeta, func(ws memdb.WatchSet, state *state.Store) error {
+		index, configs, err := state.KVSHistoryConfigs(ws)
+		if err != nil {
+			return err
+		}
+		reply.Index, reply.Configs = index, configs
+		return nil
+	})
+}
+
+// KVSHistoryConfigApply is used to set or delete the KV revision retention
+// for a prefix.
+func (op *Operator) KVSHistoryConfigApply(args *structs.KVSHistoryConfigRequest, reply *bool) error {
+	if done, err := op.srv.ForwardRPC("Operator.KVSHistoryConfigApply", args, reply); done {
+		return err
+	}
+
+	// This action requires operator write access.
+	authz, err := op.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := op.srv.validateEnterpriseToken(authz.Identity()); err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorWriteAllowed(nil); err != nil {
+		return err
+	}
+
+	switch args.Op {
+	case structs.KVSHistoryConfigUpsert:
+		if err := args.Config.Validate(); err != nil {
+			return err
+		}
+	case structs.KVSHistoryConfigDelete:
+	default:
+		return fmt.Errorf("Invalid KV history config operation '%s'", args.Op)
+	}
+
+	// Older servers can safely ignore this, they just won't retain history.
+	resp, err := op.srv.raftApply(structs.KVSHistoryConfigRequestType|structs.IgnoreUnknownTypeFlag, args)
+	if err != nil {
+		return fmt.Errorf("raft apply failed: %w", err)
+	}
+	if respErr, ok := resp.(error); ok {
+		return respErr
+	}
+
+	*reply = true
+	return nil
+}
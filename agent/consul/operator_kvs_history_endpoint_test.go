@@ -0,0 +1,143 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package consul
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	msgpackrpc "github.com/hashicorp/consul-net-rpc/net-rpc-msgpackrpc"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestOperator_KVSHistoryConfig(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	dir1, s1 := testServer(t)
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+	// Invalid configurations are rejected.
+	arg := structs.KVSHistoryConfigRequest{
+		Datacenter: "dc1",
+		Op:         structs.KVSHistoryConfigUpsert,
+		Config:     structs.KVSHistoryConfig{Prefix: "app/", Revisions: 0},
+	}
+	var out bool
+	err := msgpackrpc.CallWithCodec(codec, "Operator.KVSHistoryConfigApply", &arg, &out)
+	require.ErrorContains(t, err, "Revisions must be at least 1")
+
+	arg.Config.Revisions = 2
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "Operator.KVSHistoryConfigApply", &arg, &out))
+	require.True(t, out)
+
+	list := structs.DCSpecificRequest{Datacenter: "dc1"}
+	var configs structs.IndexedKVSHistoryConfigs
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "Operator.KVSHistoryConfigList", &list, &configs))
+	require.Len(t, configs.Configs, 1)
+	require.Equal(t, "app/", configs.Configs[0].Prefix)
+	require.Equal(t, 2, configs.Configs[0].Revisions)
+
+	// Write a few values and read the history back.
+	for _, v := range []string{"1", "2", "3"} {
+		set := structs.KVSRequest{
+			Datacenter: "dc1",
+			Op:         api.KVSet,
+			DirEnt:     structs.DirEntry{Key: "app/key", Value: []byte(v)},
+		}
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "KVS.Apply", &set, &out))
+	}
+
+	get := structs.KeyRequest{Datacenter: "dc1", Key: "app/key"}
+	var revs structs.IndexedDirEntryRevisions
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "KVS.Revisions", &get, &revs))
+	require.Len(t, revs.Revisions, 3)
+	require.Equal(t, "1", string(revs.Revisions[0].Value))
+	require.Equal(t, "3", string(revs.Revisions[2].Value))
+	require.NotZero(t, revs.Index)
+
+	// Read the key as of the first write.
+	get.AtIndex = revs.Revisions[0].ModifyIndex
+	var dirent structs.IndexedDirEntries
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "KVS.Get", &get, &dirent))
+	require.Len(t, dirent.Entries, 1)
+	require.Equal(t, "1", string(dirent.Entries[0].Value))
+
+	// Before the key existed.
+	get.AtIndex = revs.Revisions[0].ModifyIndex - 1
+	dirent = structs.IndexedDirEntries{}
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "KVS.Get", &get, &dirent))
+	require.Empty(t, dirent.Entries)
+
+	// Removing the configuration discards the history.
+	arg.Op = structs.KVSHistoryConfigDelete
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "Operator.KVSHistoryConfigApply", &arg, &out))
+
+	get.AtIndex = 0
+	revs = structs.IndexedDirEntryRevisions{}
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "KVS.Revisions", &get, &revs))
+	require.Len(t, revs.Revisions, 1)
+	require.Equal(t, "3", string(revs.Revisions[0].Value))
+}
+
+func TestOperator_KVSHistoryConfig_ACLDeny(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	dir1, s1 := testServerWithConfig(t, func(c *Config) {
+		c.PrimaryDatacenter = "dc1"
+		c.ACLsEnabled = true
+		c.ACLInitialManagementToken = "root"
+		c.ACLResolverSettings.ACLDefaultPolicy = "deny"
+	})
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+	// Try to set config without permissions
+	arg := structs.KVSHistoryConfigRequest{
+		Datacenter: "dc1",
+		Op:         structs.KVSHistoryConfigUpsert,
+		Config:     structs.KVSHistoryConfig{Prefix: "app/", Revisions: 2},
+	}
+	var out bool
+	err := msgpackrpc.CallWithCodec(codec, "Operator.KVSHistoryConfigApply", &arg, &out)
+	require.True(t, acl.IsErrPermissionDenied(err), "err: %v", err)
+
+	list := structs.DCSpecificRequest{Datacenter: "dc1"}
+	var configs structs.IndexedKVSHistoryConfigs
+	err = msgpackrpc.CallWithCodec(codec, "Operator.KVSHistoryConfigList", &list, &configs)
+	require.True(t, acl.IsErrPermissionDenied(err), "err: %v", err)
+
+	// Reading history requires read access to the key.
+	get := structs.KeyRequest{Datacenter: "dc1", Key: "app/key"}
+	var revs structs.IndexedDirEntryRevisions
+	err = msgpackrpc.CallWithCodec(codec, "KVS.Revisions", &get, &revs)
+	require.True(t, acl.IsErrPermissionDenied(err), "err: %v", err)
+
+	// With the management token everything works.
+	arg.Token = "root"
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "Operator.KVSHistoryConfigApply", &arg, &out))
+	list.Token = "root"
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "Operator.KVSHistoryConfigList", &list, &configs))
+	require.Len(t, configs.Configs, 1)
+}
@@ -0,0 +1,83 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package consul
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/hashicorp/consul/agent/consul/state"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+// KVSQuotaList is used to retrieve the KV quotas for all prefixes.
+func (op *Operator) KVSQuotaList(args *structs.DCSpecificRequest, reply *structs.IndexedKVSQuotas) error {
+	if done, err := op.srv.ForwardRPC("Operator.KVSQuotaList", args, reply); done {
+		return err
+	}
+
+	// This action requires operator read access.
+	authz, err := op.srv.ResolveToken(args.Token)
+	if err != nil {
+		return err
+	}
+	if err := op.srv.validateEnterpriseToken(authz.Identity()); err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorReadAllowed(nil); err != nil {
+		return err
+	}
+
+	return op.srv.blockingQuery(&args.QueryOptions, &reply.QueryMeta, func(ws memdb.WatchSet, state *state.Store) error {
+		index, quotas, err := state.KVSQuotas(ws)
+		if err != nil {
+			return err
+		}
+		reply.Index, reply.Quotas = index, quotas
+		return nil
+	})
+}
+
+// KVSQuotaApply is used to set or delete the KV quota for a prefix.
+func (op *Operator) KVSQuotaApply(args *structs.KVSQuotaRequest, reply *bool) error {
+	if done, err := op.srv.ForwardRPC("Operator.KVSQuotaApply", args, reply); done {
+		return err
+	}
+
+	// This action requires operator write access.
+	authz, err := op.srv.ResolveTokenAndDefaultMeta(args.Token, &args.Quota.EnterpriseMeta, nil)
+	if err != nil {
+		return err
+	}
+	if err := op.srv.validateEnterpriseToken(authz.Identity()); err != nil {
+		return err
+	}
+	if err := authz.ToAllowAuthorizer().OperatorWriteAllowed(nil); err != nil {
+		return err
+	}
+
+	switch args.Op {
+	case structs.KVSQuotaUpsert:
+		if err := args.Quota.Validate(); err != nil {
+			return err
+		}
+	case structs.KVSQuotaDelete:
+	default:
+		return fmt.Errorf("Invalid KV quota operation '%s'", args.Op)
+	}
+
+	// Older servers can safely ignore this, they just won't enforce the
+	// quota.
+	resp, err := op.srv.raftApply(structs.KVSQuotaRequestType|structs.IgnoreUnknownTypeFlag, args)
+	if err != nil {
+		return fmt.Errorf("raft apply failed: %w", err)
+	}
+	if respErr, ok := resp.(error); ok {
+		return respErr
+	}
+
+	*reply = true
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package consul
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	msgpackrpc "github.com/hashicorp/consul-net-rpc/net-rpc-msgpackrpc"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestOperator_KVSQuota(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	dir1, s1 := testServer(t)
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+	// Invalid quotas are rejected.
+	arg := structs.KVSQuotaRequest{
+		Datacenter: "dc1",
+		Op:         structs.KVSQuotaUpsert,
+		Quota:      structs.KVSQuota{Prefix: "app/"},
+	}
+	var out bool
+	err := msgpackrpc.CallWithCodec(codec, "Operator.KVSQuotaApply", &arg, &out)
+	require.ErrorContains(t, err, "At least one of MaxKeys, MaxBytes or MaxValueSize must be set")
+
+	arg.Quota.MaxValueSize = 4
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "Operator.KVSQuotaApply", &arg, &out))
+	require.True(t, out)
+
+	list := structs.DCSpecificRequest{Datacenter: "dc1"}
+	var quotas structs.IndexedKVSQuotas
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "Operator.KVSQuotaList", &list, &quotas))
+	require.Len(t, quotas.Quotas, 1)
+	require.Equal(t, "app/", quotas.Quotas[0].Prefix)
+	require.Equal(t, int64(4), quotas.Quotas[0].MaxValueSize)
+
+	// Writes are checked against the quota.
+	set := structs.KVSRequest{
+		Datacenter: "dc1",
+		Op:         api.KVSet,
+		DirEnt:     structs.DirEntry{Key: "app/key", Value: []byte("1234")},
+	}
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "KVS.Apply", &set, &out))
+
+	set.DirEnt.Value = []byte("12345")
+	err = msgpackrpc.CallWithCodec(codec, "KVS.Apply", &set, &out)
+	require.True(t, structs.IsErrKVSQuotaExceeded(err), "err: %v", err)
+
+	// Removing the quota lifts the limit.
+	arg.Op = structs.KVSQuotaDelete
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "Operator.KVSQuotaApply", &arg, &out))
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "KVS.Apply", &set, &out))
+}
+
+func TestOperator_KVSQuota_ACLDeny(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	dir1, s1 := testServerWithConfig(t, func(c *Config) {
+		c.PrimaryDatacenter = "dc1"
+		c.ACLsEnabled = true
+		c.ACLInitialManagementToken = "root"
+		c.ACLResolverSettings.ACLDefaultPolicy = "deny"
+	})
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+	// Try to set a quota without permissions
+	arg := structs.KVSQuotaRequest{
+		Datacenter: "dc1",
+		Op:         structs.KVSQuotaUpsert,
+		Quota:      structs.KVSQuota{Prefix: "app/", MaxKeys: 2},
+	}
+	var out bool
+	err := msgpackrpc.CallWithCodec(codec, "Operator.KVSQuotaApply", &arg, &out)
+	require.True(t, acl.IsErrPermissionDenied(err), "err: %v", err)
+
+	list := structs.DCSpecificRequest{Datacenter: "dc1"}
+	var quotas structs.IndexedKVSQuotas
+	err = msgpackrpc.CallWithCodec(codec, "Operator.KVSQuotaList", &list, &quotas)
+	require.True(t, acl.IsErrPermissionDenied(err), "err: %v", err)
+
+	// With the management token everything works.
+	arg.Token = "root"
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "Operator.KVSQuotaApply", &arg, &out))
+	list.Token = "root"
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "Operator.KVSQuotaList", &list, &quotas))
+	require.Len(t, quotas.Quotas, 1)
+}
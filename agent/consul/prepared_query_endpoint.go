@@ -5,6 +5,7 @@ package consul
 
 import (
 	"fmt"
+	"net/netip"
 	"strings"
 	"time"
 
@@ -432,7 +433,7 @@ func (p *PreparedQuery) Execute(args *structs.PreparedQueryExecuteRequest,
 				}
 
 				for _, node := range nodes {
-					if args.Source.Ip == node.Address {
+					if sourceIPMatches(args.Source.Ip, node.Address) {
 						qs.Node = node.Node
 						break
 					}
@@ -494,6 +495,18 @@ func (p *PreparedQuery) Execute(args *structs.PreparedQueryExecuteRequest,
 	return nil
 }
 
+// sourceIPMatches returns whether a node address matches the source IP of a
+// query. The source is either a single address or, when the query was made on
+// behalf of a client network such as with the EDNS Client Subnet option, a
+// subnet in CIDR notation.
+func sourceIPMatches(source, address string) bool {
+	if prefix, err := netip.ParsePrefix(source); err == nil {
+		addr, err := netip.ParseAddr(address)
+		return err == nil && prefix.Contains(addr.Unmap())
+	}
+	return source == address
+}
+
 // ExecuteRemote is used when a local node doesn't have any instances of a
 // service available and needs to probe remote DCs. This sends the full query
 // over since the remote side won't have it in its state store, and this doesn't
@@ -1440,6 +1440,25 @@ func TestPreparedQuery_Explain(t *testing.T) {
 // This is a beast of a test, but the setup is so extensive it makes sense to
 // walk through the different cases once we have it up. This is broken into
 // sections so it's still pretty easy to read.
+func TestPreparedQuery_sourceIPMatches(t *testing.T) {
+	cases := []struct {
+		source  string
+		address string
+		match   bool
+	}{
+		{"198.18.0.1", "198.18.0.1", true},
+		{"198.18.0.1", "198.18.0.2", false},
+		{"198.18.0.0/24", "198.18.0.10", true},
+		{"198.18.0.0/24", "198.18.1.10", false},
+		{"198.18.0.0/24", "::ffff:198.18.0.10", true},
+		{"2001:db8::/32", "2001:db8::1", true},
+		{"2001:db8::/32", "node.example.com", false},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.match, sourceIPMatches(tc.source, tc.address), "%s %s", tc.source, tc.address)
+	}
+}
+
 func TestPreparedQuery_Execute(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
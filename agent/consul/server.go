@@ -137,6 +137,7 @@ const (
 	intentionMigrationRoutineName         = "intention config entry migration"
 	secondaryCARootWatchRoutineName       = "secondary CA roots watch"
 	intermediateCertRenewWatchRoutineName = "intermediate cert renew watch"
+	kvsReapingRoutineName                 = "kv expiration reaping"
 	backgroundCAInitializationRoutineName = "CA initialization"
 	virtualIPCheckRoutineName             = "virtual IP version check"
 	peeringStreamsRoutineName             = "streaming peering resources"
@@ -180,6 +181,10 @@ type Server struct {
 	// acls is used to resolve tokens to effective policies
 	*ACLResolver
 
+	// aclTokenUsage tracks when the tokens resolved by this server were last
+	// used.
+	aclTokenUsage *aclTokenUsage
+
 	aclAuthMethodValidators authmethod.Cache
 
 	// autopilot is the Autopilot instance for this server.
@@ -644,6 +649,7 @@ func NewServer(config *Config, flat Deps, externalGRPCServer *grpc.Server,
 
 	partitionInfo := serverPartitionInfo(s)
 	s.aclConfig = newACLConfig(partitionInfo, logger)
+	s.aclTokenUsage = newACLTokenUsage(s, logger)
 	aclConfig := ACLResolverConfig{
 		Config:      config.ACLResolverSettings,
 		Backend:     &serverACLResolverBackend{Server: s},
@@ -651,6 +657,7 @@ func NewServer(config *Config, flat Deps, externalGRPCServer *grpc.Server,
 		Logger:      logger,
 		ACLConfig:   s.aclConfig,
 		Tokens:      flat.Tokens,
+		TokenUsed:   s.aclTokenUsage.record,
 	}
 	// Initialize the ACL resolver.
 	if s.ACLResolver, err = NewACLResolver(&aclConfig); err != nil {
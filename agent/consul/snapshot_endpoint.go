@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/consul-net-rpc/go-msgpack/codec"
+	"github.com/hashicorp/raft"
 
 	"github.com/hashicorp/consul/agent/pool"
 	"github.com/hashicorp/consul/agent/structs"
@@ -80,6 +81,16 @@ func (s *Server) dispatchSnapshotRequest(args *structs.SnapshotRequest, in io.Re
 		// pessimistic if we get more data while the snapshot is being taken.
 		s.SetQueryMeta(&reply.QueryMeta, args.Token)
 
+		// Take an incremental snapshot if we were given a base.
+		if args.BaseIndex != 0 {
+			snap, err := snapshot.NewIncremental(s.logger, s.raft, s.raftLogStore(), snapshot.IncrementalMeta{
+				BaseIndex: args.BaseIndex,
+				BaseHash:  args.BaseHash,
+			})
+			reply.Index = snap.Index()
+			return snap, err
+		}
+
 		// Take the snapshot and capture the index.
 		snap, err := snapshot.New(s.logger, s.raft)
 		reply.Index = snap.Index()
@@ -147,6 +158,15 @@ func (s *Server) dispatchSnapshotRequest(args *structs.SnapshotRequest, in io.Re
 	}
 }
 
+// raftLogStore returns the store holding the Raft log, which is kept in
+// memory in dev mode.
+func (s *Server) raftLogStore() raft.LogStore {
+	if s.raftStore != nil {
+		return s.raftStore
+	}
+	return s.raftInmem
+}
+
 // handleSnapshotRequest reads the request from the conn and dispatches it. This
 // will be called from a goroutine after an incoming stream is determined to be
 // a snapshot request.
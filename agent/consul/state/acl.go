@@ -542,6 +542,12 @@ func aclTokenSetTxn(tx WriteTxn, idx uint64, token *structs.ACLToken, opts ACLTo
 			return fmt.Errorf("The ACL Token SecretID field is immutable")
 		}
 
+		// The token's last use is tracked by the servers rather than set by
+		// whoever writes the token, so keep the most recent one around.
+		if original.LastUsed != nil && (token.LastUsed == nil || token.LastUsed.Time.Before(original.LastUsed.Time)) {
+			token.LastUsed = original.LastUsed
+		}
+
 		token.CreateIndex = original.CreateIndex
 		token.ModifyIndex = idx
 	} else {
@@ -555,6 +561,46 @@ func aclTokenSetTxn(tx WriteTxn, idx uint64, token *structs.ACLToken, opts ACLTo
 	return aclTokenInsert(tx, token)
 }
 
+// ACLTokenLastUsedSet records when and where the given tokens were last used.
+// Entries for tokens that don't exist anymore, or that are older than the use
+// already recorded, are ignored. A token's last use is metadata rather than a
+// modification, so it bumps neither the token's ModifyIndex nor the tokens
+// table index.
+func (s *Store) ACLTokenLastUsedSet(idx uint64, entries []*structs.ACLTokenLastUsedEntry) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	if err := aclTokenLastUsedSetTxn(tx, entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func aclTokenLastUsedSetTxn(tx WriteTxn, entries []*structs.ACLTokenLastUsedEntry) error {
+	for _, entry := range entries {
+		_, existing, err := aclTokenGetFromIndex(tx, entry.AccessorID, indexAccessor, nil)
+		if err != nil {
+			return fmt.Errorf("failed token lookup: %s", err)
+		}
+		if existing == nil {
+			continue
+		}
+
+		token := existing.(*structs.ACLToken)
+		if token.LastUsed != nil && !token.LastUsed.Time.Before(entry.LastUsed.Time) {
+			continue
+		}
+
+		updated := token.Clone()
+		lastUsed := entry.LastUsed
+		updated.LastUsed = &lastUsed
+		if err := tx.Insert(tableACLTokens, updated); err != nil {
+			return fmt.Errorf("failed inserting acl token: %v", err)
+		}
+	}
+	return nil
+}
+
 // ACLTokenGetBySecret is used to look up an existing ACL token by its SecretID.
 func (s *Store) ACLTokenGetBySecret(ws memdb.WatchSet, secret string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLToken, error) {
 	return s.aclTokenGet(ws, secret, indexID, entMeta)
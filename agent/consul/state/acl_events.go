@@ -21,6 +21,12 @@ func aclChangeUnsubscribeEvent(tx ReadTxn, changes Changes) ([]stream.Event, err
 		switch change.Table {
 		case tableACLTokens:
 			token := changeObject(change).(*structs.ACLToken)
+			// Recording the token's last use doesn't change what it grants
+			// and leaves its ModifyIndex as is, so it shouldn't close the
+			// subscriptions using it.
+			if change.Updated() && change.Before.(*structs.ACLToken).ModifyIndex == token.ModifyIndex {
+				continue
+			}
 			secretIDs = append(secretIDs, token.SecretID)
 
 		case tableACLRoles:
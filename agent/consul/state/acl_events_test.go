@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/consul/agent/consul/stream"
 	"github.com/hashicorp/consul/agent/structs"
@@ -41,6 +42,20 @@ func TestACLChangeUnsubscribeEvent(t *testing.T) {
 			},
 			expected: stream.NewCloseSubscriptionEvent(newSecretIDs(1)),
 		},
+		{
+			Name: "token last used",
+			Setup: func(tx *txn) error {
+				return aclTokenSetTxn(tx, tx.Index, newACLToken(1), ACLTokenSetOptions{})
+			},
+			Mutate: func(tx *txn) error {
+				token := newACLToken(1)
+				entries := []*structs.ACLTokenLastUsedEntry{
+					{AccessorID: token.AccessorID, LastUsed: structs.ACLTokenLastUsed{Time: time.Now()}},
+				}
+				return aclTokenLastUsedSetTxn(tx, entries)
+			},
+			expected: stream.NewCloseSubscriptionEvent(nil),
+		},
 		{
 			Name: "token delete",
 			Setup: func(tx *txn) error {
@@ -1440,6 +1440,68 @@ func TestStateStore_ACLToken_FixupRoleLinks(t *testing.T) {
 	require.True(t, found)
 }
 
+func TestStateStore_ACLToken_LastUsed(t *testing.T) {
+	t.Parallel()
+	s := testACLTokensStateStore(t)
+
+	token := &structs.ACLToken{
+		AccessorID: "f1093997-b6c7-496d-bfb8-6b1b1895641b",
+		SecretID:   "34ec8eb3-095d-417a-a937-b439af7a8e8b",
+		Policies: []structs.ACLTokenPolicyLink{
+			{
+				ID: structs.ACLPolicyGlobalManagementID,
+			},
+		},
+	}
+	require.NoError(t, s.ACLTokenSet(2, token.Clone()))
+
+	used := time.Date(2024, 5, 22, 18, 0, 0, 0, time.UTC)
+	entries := []*structs.ACLTokenLastUsedEntry{
+		{
+			AccessorID: token.AccessorID,
+			LastUsed:   structs.ACLTokenLastUsed{Time: used, Server: "server-1", Datacenter: "dc1"},
+		},
+		{
+			// Tokens that don't exist anymore are ignored.
+			AccessorID: "a3f2b6fc-4bd3-4b10-8b8f-4d1a8e4a2e3f",
+			LastUsed:   structs.ACLTokenLastUsed{Time: used, Server: "server-1", Datacenter: "dc1"},
+		},
+	}
+	require.NoError(t, s.ACLTokenLastUsedSet(3, entries))
+
+	idx, rtoken, err := s.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+	require.NoError(t, err)
+	require.Equal(t, &structs.ACLTokenLastUsed{Time: used, Server: "server-1", Datacenter: "dc1"}, rtoken.LastUsed)
+
+	// The last use is metadata, it doesn't count as a modification.
+	require.Equal(t, uint64(2), idx)
+	require.Equal(t, uint64(2), rtoken.ModifyIndex)
+
+	// An older use doesn't override a more recent one.
+	entries = []*structs.ACLTokenLastUsedEntry{
+		{
+			AccessorID: token.AccessorID,
+			LastUsed:   structs.ACLTokenLastUsed{Time: used.Add(-time.Hour), Server: "server-2", Datacenter: "dc1"},
+		},
+	}
+	require.NoError(t, s.ACLTokenLastUsedSet(4, entries))
+
+	_, rtoken, err = s.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+	require.NoError(t, err)
+	require.Equal(t, "server-1", rtoken.LastUsed.Server)
+
+	// Updating the token keeps its last use.
+	updated := token.Clone()
+	updated.Description = "updated"
+	require.NoError(t, s.ACLTokenSet(5, updated))
+
+	_, rtoken, err = s.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+	require.NoError(t, err)
+	require.Equal(t, "updated", rtoken.Description)
+	require.Equal(t, uint64(5), rtoken.ModifyIndex)
+	require.Equal(t, &structs.ACLTokenLastUsed{Time: used, Server: "server-1", Datacenter: "dc1"}, rtoken.LastUsed)
+}
+
 func TestStateStore_ACLToken_Delete(t *testing.T) {
 	t.Parallel()
 
@@ -6,6 +6,7 @@ package state
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/consul/acl"
 	"github.com/hashicorp/consul/agent/consul/stream"
@@ -32,10 +33,16 @@ func PBToStreamSubscribeRequest(req *pbsubscribe.SubscribeRequest, entMeta acl.E
 			}
 		}
 
-		if named.Key == "" {
+		// The KV topic allows subscribing to the empty prefix to receive
+		// events for every key.
+		if named.Key == "" && !(req.Topic == EventTopicKV && named.Prefix) {
 			return nil, errors.New("either WildcardSubject or NamedSubject.Key is required")
 		}
 
+		if named.Prefix && req.Topic != EventTopicKV {
+			return nil, fmt.Errorf("topic %s does not support prefix subscriptions", req.Topic)
+		}
+
 		switch req.Topic {
 		case EventTopicServiceHealth, EventTopicServiceHealthConnect:
 			subject = EventSubjectService{
@@ -52,6 +59,15 @@ func PBToStreamSubscribeRequest(req *pbsubscribe.SubscribeRequest, entMeta acl.E
 				Name:           named.Key,
 				EnterpriseMeta: &entMeta,
 			}
+		case EventTopicKV:
+			if named.Prefix && named.Key != "" && !strings.HasSuffix(named.Key, "/") {
+				return nil, errors.New("KV prefix subscriptions require a prefix that is empty or ends in a '/'")
+			}
+			subject = EventSubjectKV{
+				Key:            named.Key,
+				Prefix:         named.Prefix,
+				EnterpriseMeta: entMeta,
+			}
 		case EventTopicServiceList:
 			// Events on this topic are published to SubjectNone, but rather than
 			// exposing this in (and further complicating) the streaming API we rely
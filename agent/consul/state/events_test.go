@@ -136,6 +136,80 @@ func TestPBToStreamSubscribeRequest(t *testing.T) {
 			},
 			err: nil,
 		},
+		"KV key": {
+			req: &pbsubscribe.SubscribeRequest{
+				Topic: EventTopicKV,
+				Subject: &pbsubscribe.SubscribeRequest_NamedSubject{
+					NamedSubject: &pbsubscribe.NamedSubject{
+						Key: "foo/bar",
+					},
+				},
+				Token: aclToken,
+				Index: 2,
+			},
+			entMeta: acl.EnterpriseMeta{},
+			expectedSubscribeRequest: &stream.SubscribeRequest{
+				Topic: EventTopicKV,
+				Subject: EventSubjectKV{
+					Key:            "foo/bar",
+					EnterpriseMeta: acl.EnterpriseMeta{},
+				},
+				Token: aclToken,
+				Index: 2,
+			},
+			err: nil,
+		},
+		"KV root prefix": {
+			req: &pbsubscribe.SubscribeRequest{
+				Topic: EventTopicKV,
+				Subject: &pbsubscribe.SubscribeRequest_NamedSubject{
+					NamedSubject: &pbsubscribe.NamedSubject{
+						Prefix: true,
+					},
+				},
+				Token: aclToken,
+				Index: 2,
+			},
+			entMeta: acl.EnterpriseMeta{},
+			expectedSubscribeRequest: &stream.SubscribeRequest{
+				Topic: EventTopicKV,
+				Subject: EventSubjectKV{
+					Prefix:         true,
+					EnterpriseMeta: acl.EnterpriseMeta{},
+				},
+				Token: aclToken,
+				Index: 2,
+			},
+			err: nil,
+		},
+		"KV prefix without trailing slash returns error": {
+			req: &pbsubscribe.SubscribeRequest{
+				Topic: EventTopicKV,
+				Subject: &pbsubscribe.SubscribeRequest_NamedSubject{
+					NamedSubject: &pbsubscribe.NamedSubject{
+						Key:    "foo",
+						Prefix: true,
+					},
+				},
+			},
+			entMeta:                  acl.EnterpriseMeta{},
+			expectedSubscribeRequest: nil,
+			err:                      fmt.Errorf("KV prefix subscriptions require a prefix that is empty or ends in a '/'"),
+		},
+		"Prefix on other topics returns error": {
+			req: &pbsubscribe.SubscribeRequest{
+				Topic: EventTopicServiceHealth,
+				Subject: &pbsubscribe.SubscribeRequest_NamedSubject{
+					NamedSubject: &pbsubscribe.NamedSubject{
+						Key:    "key",
+						Prefix: true,
+					},
+				},
+			},
+			entMeta:                  acl.EnterpriseMeta{},
+			expectedSubscribeRequest: nil,
+			err:                      fmt.Errorf("topic %s does not support prefix subscriptions", EventTopicServiceHealth),
+		},
 		"Service list without wildcard returns error": {
 			req: &pbsubscribe.SubscribeRequest{
 				Topic: EventTopicServiceList,
@@ -18,6 +18,7 @@ const (
 	tableTombstones = "tombstones"
 
 	indexSession = "session"
+	indexExpires = "expires"
 )
 
 // kvsTableSchema returns a new table schema used for storing structs.DirEntry
@@ -39,10 +40,32 @@ func kvsTableSchema() *memdb.TableSchema {
 					Field: "Session",
 				},
 			},
+			indexExpires: {
+				Name:         indexExpires,
+				AllowMissing: true,
+				Unique:       false,
+				Indexer: indexerSingle[*TimeQuery, *structs.DirEntry]{
+					readIndex:  indexFromTimeQuery,
+					writeIndex: indexExpiresFromDirEntry,
+				},
+			},
 		},
 	}
 }
 
+func indexExpiresFromDirEntry(e *structs.DirEntry) ([]byte, error) {
+	if !e.HasExpirationTime() {
+		return nil, errMissingValueForIndex
+	}
+	if e.ExpirationTime.Unix() < 0 {
+		return nil, fmt.Errorf("kv entry expiration time cannot be before the unix epoch: %s", e.ExpirationTime)
+	}
+
+	var b indexBuilder
+	b.Time(*e.ExpirationTime)
+	return b.Bytes(), nil
+}
+
 // indexFromIDValue creates an index key from any struct that implements singleValueID
 func indexFromIDValue(e singleValueID) ([]byte, error) {
 	v := e.IDValue()
@@ -146,13 +169,15 @@ func kvsSetTxn(tx WriteTxn, idx uint64, entry *structs.DirEntry, updateSession b
 		entry.CreateIndex = idx
 	}
 
-	// Preserve the existing session unless told otherwise. The "existing"
-	// session for a new entry is "no session".
+	// Preserve the existing session and fencing token unless told otherwise.
+	// The "existing" session for a new entry is "no session".
 	if !updateSession {
 		if existing != nil {
 			entry.Session = existing.Session
+			entry.FencingToken = existing.FencingToken
 		} else {
 			entry.Session = ""
+			entry.FencingToken = 0
 		}
 	}
 
@@ -166,6 +191,19 @@ func kvsSetTxn(tx WriteTxn, idx uint64, entry *structs.DirEntry, updateSession b
 	}
 	entry.ModifyIndex = idx
 
+	// Make sure the write fits within any quotas for the key before changing
+	// anything.
+	if err := kvsQuotaSetTxn(tx, existing, entry); err != nil {
+		return err
+	}
+
+	// Retain the value being replaced if history is enabled for the key.
+	if existing != nil {
+		if err := kvsHistoryRecordTxn(tx, idx, existing, false); err != nil {
+			return err
+		}
+	}
+
 	// Store the kv pair in the state store and update the index.
 	if err := insertKVTxn(tx, entry, false, false); err != nil {
 		return fmt.Errorf("failed inserting kvs entry: %s", err)
@@ -295,6 +333,15 @@ func (s *Store) kvsDeleteTxn(tx WriteTxn, idx uint64, key string, entMeta *acl.E
 		return fmt.Errorf("failed adding to graveyard: %s", err)
 	}
 
+	// Retain the deleted value if history is enabled for the key.
+	if err := kvsHistoryRecordTxn(tx, idx, entry.(*structs.DirEntry), true); err != nil {
+		return err
+	}
+
+	if err := kvsQuotaDeleteTxn(tx, entry.(*structs.DirEntry)); err != nil {
+		return err
+	}
+
 	return kvsDeleteWithEntry(tx, entry.(*structs.DirEntry), idx)
 }
 
@@ -400,6 +447,50 @@ func (s *Store) KVSDeleteTree(idx uint64, prefix string, entMeta *acl.Enterprise
 	return tx.Commit()
 }
 
+// KVSMinExpirationTime returns the earliest expiration time of any KV entry,
+// or the zero time if no entries are scheduled to expire.
+func (s *Store) KVSMinExpirationTime() (time.Time, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	item, err := tx.First(tableKVs, indexExpires)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed kvs lookup: %s", err)
+	}
+	if item == nil {
+		return time.Time{}, nil
+	}
+
+	return *item.(*structs.DirEntry).ExpirationTime, nil
+}
+
+// KVSListExpired lists KV entries across all partitions and namespaces that
+// are expired as of the provided time. The returned set will be no larger
+// than the max value provided.
+func (s *Store) KVSListExpired(asOf time.Time, max int) (structs.DirEntries, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	iter, err := tx.Get(tableKVs, indexExpires)
+	if err != nil {
+		return nil, fmt.Errorf("failed kvs lookup: %s", err)
+	}
+
+	var entries structs.DirEntries
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		entry := raw.(*structs.DirEntry)
+		if !entry.IsExpired(asOf) {
+			break
+		}
+
+		entries = append(entries, entry)
+		if len(entries) >= max {
+			break
+		}
+	}
+	return entries, nil
+}
+
 // KVSLockDelay returns the expiration time for any lock delay associated with
 // the given key.
 func (s *Store) KVSLockDelay(key string, entMeta *acl.EnterpriseMeta) time.Time {
@@ -450,6 +541,7 @@ func kvsLockTxn(tx WriteTxn, idx uint64, entry *structs.DirEntry) (bool, error)
 			// We already hold this lock, good to go.
 			entry.CreateIndex = e.CreateIndex
 			entry.LockIndex = e.LockIndex
+			entry.FencingToken = e.FencingToken
 		} else if e.Session != "" {
 			// Bail out, someone else holds this lock.
 			return false, nil
@@ -457,10 +549,12 @@ func kvsLockTxn(tx WriteTxn, idx uint64, entry *structs.DirEntry) (bool, error)
 			// Set up a new lock with this session.
 			entry.CreateIndex = e.CreateIndex
 			entry.LockIndex = e.LockIndex + 1
+			entry.FencingToken = idx
 		}
 	} else {
 		entry.CreateIndex = idx
 		entry.LockIndex = 1
+		entry.FencingToken = idx
 	}
 	entry.ModifyIndex = idx
 
@@ -510,9 +604,11 @@ func kvsUnlockTxn(tx WriteTxn, idx uint64, entry *structs.DirEntry) (bool, error
 		return false, nil
 	}
 
-	// Clear the lock and update the entry.
+	// Clear the lock and update the entry. The fencing token is kept so
+	// the last holder's token is still visible, but it is no longer current.
 	entry.Session = ""
 	entry.LockIndex = e.LockIndex
+	entry.FencingToken = e.FencingToken
 	entry.CreateIndex = e.CreateIndex
 	entry.ModifyIndex = idx
 
@@ -548,6 +644,35 @@ func kvsCheckSessionTxn(tx WriteTxn,
 	return e, nil
 }
 
+// kvsCheckFenceTxn checks to see if the given fencing token is still current
+// for a key, meaning the key is locked and the lock was acquired at the index
+// the token was issued.
+func kvsCheckFenceTxn(tx WriteTxn,
+	key string, token uint64, entMeta *acl.EnterpriseMeta) (*structs.DirEntry, error) {
+
+	if entMeta == nil {
+		entMeta = structs.DefaultEnterpriseMetaInDefaultPartition()
+	}
+
+	entry, err := tx.First(tableKVs, indexID, Query{Value: key, EnterpriseMeta: *entMeta})
+	if err != nil {
+		return nil, fmt.Errorf("failed kvs lookup: %s", err)
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("failed to check fencing token, key %q doesn't exist", key)
+	}
+
+	e := entry.(*structs.DirEntry)
+	if e.Session == "" {
+		return nil, fmt.Errorf("failed fencing token check for key %q, lock is not held", key)
+	}
+	if e.FencingToken != token {
+		return nil, fmt.Errorf("failed fencing token check for key %q, current token %d != %d", key, e.FencingToken, token)
+	}
+
+	return e, nil
+}
+
 // kvsCheckIndexTxn checks to see if the given modify index matches the current
 // entry for a key.
 func kvsCheckIndexTxn(tx WriteTxn,
@@ -83,6 +83,28 @@ func kvsListEntriesTxn(tx ReadTxn, ws memdb.WatchSet, prefix string, entMeta acl
 // kvsDeleteTreeTxn is the inner method that does a recursive delete inside an
 // existing transaction.
 func (s *Store) kvsDeleteTreeTxn(tx WriteTxn, idx uint64, prefix string, entMeta *acl.EnterpriseMeta) error {
+	// Retain the deleted values if history is enabled for any key in the
+	// subtree.
+	historyEnabled, err := kvsHistoryEnabledTxn(tx, prefix)
+	if err != nil {
+		return err
+	}
+	if historyEnabled {
+		_, entries, err := kvsListEntriesTxn(tx, nil, prefix, acl.EnterpriseMeta{})
+		if err != nil {
+			return fmt.Errorf("failed kvs lookup: %s", err)
+		}
+		for _, e := range entries {
+			if err := kvsHistoryRecordTxn(tx, idx, e, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := kvsQuotaDeleteTreeTxn(tx, prefix, entMeta); err != nil {
+		return err
+	}
+
 	// For prefix deletes, only insert one tombstone and delete the entire subtree
 	deleted, err := tx.DeletePrefix(tableKVs, indexID+"_prefix", prefix)
 	if err != nil {
@@ -0,0 +1,198 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/consul/stream"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/proto/private/pbcommon"
+	"github.com/hashicorp/consul/proto/private/pbsubscribe"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// EventSubjectKV is a stream.Subject used to route and receive events for a
+// single KV entry, or for every entry under a prefix when Prefix is true.
+//
+// Prefix subjects are only published for the empty prefix and for prefixes
+// ending in a '/', so a subscription to "foo/" will see "foo/bar" but a
+// subscription to "fo" will never receive any events.
+type EventSubjectKV struct {
+	Key            string
+	Prefix         bool
+	EnterpriseMeta acl.EnterpriseMeta
+}
+
+func (s EventSubjectKV) String() string {
+	kind := "key"
+	if s.Prefix {
+		kind = "prefix"
+	}
+	return fmt.Sprintf(
+		"%s/%s/%s:%s",
+		s.EnterpriseMeta.PartitionOrDefault(),
+		s.EnterpriseMeta.NamespaceOrDefault(),
+		kind,
+		s.Key,
+	)
+}
+
+// EventPayloadKV is used as the Payload for a stream.Event to indicate a KV
+// entry was written or deleted. The same change is published once for the key
+// and once for each of the prefixes it belongs to, so the payload carries the
+// subject it was published to.
+type EventPayloadKV struct {
+	Op    pbsubscribe.KVUpdate_UpdateOp
+	Value *structs.DirEntry
+
+	subject EventSubjectKV
+}
+
+func (e EventPayloadKV) Subject() stream.Subject {
+	return e.subject
+}
+
+func (e EventPayloadKV) HasReadPermission(authz acl.Authorizer) bool {
+	var authzContext acl.AuthorizerContext
+	e.Value.FillAuthzContext(&authzContext)
+	return authz.KeyRead(e.Value.Key, &authzContext) == acl.Allow
+}
+
+func (e EventPayloadKV) ToSubscriptionEvent(idx uint64) *pbsubscribe.Event {
+	var expirationTime *timestamppb.Timestamp
+	if e.Value.HasExpirationTime() {
+		expirationTime = timestamppb.New(*e.Value.ExpirationTime)
+	}
+
+	return &pbsubscribe.Event{
+		Index: idx,
+		Payload: &pbsubscribe.Event_KV{
+			KV: &pbsubscribe.KVUpdate{
+				Op:             e.Op,
+				Key:            e.Value.Key,
+				Flags:          e.Value.Flags,
+				Value:          e.Value.Value,
+				Session:        e.Value.Session,
+				LockIndex:      e.Value.LockIndex,
+				CreateIndex:    e.Value.CreateIndex,
+				ModifyIndex:    e.Value.ModifyIndex,
+				EnterpriseMeta: pbcommon.NewEnterpriseMetaFromStructs(e.Value.EnterpriseMeta),
+				ExpirationTime: expirationTime,
+				FencingToken:   e.Value.FencingToken,
+			},
+		},
+	}
+}
+
+// KVEventsFromChanges returns events that will be emitted when KV entries
+// change in the state store.
+func KVEventsFromChanges(_ ReadTxn, changes Changes) ([]stream.Event, error) {
+	var events []stream.Event
+	for _, c := range changes.Changes {
+		if c.Table != tableKVs {
+			continue
+		}
+
+		entry := changeObject(c).(*structs.DirEntry)
+		op := pbsubscribe.KVUpdate_Upsert
+		if c.Deleted() {
+			op = pbsubscribe.KVUpdate_Delete
+		}
+		events = append(events, kvEvents(changes.Index, op, entry)...)
+	}
+	return events, nil
+}
+
+// kvEvents returns an event for the entry's key and one for every prefix the
+// key can be subscribed to by.
+func kvEvents(idx uint64, op pbsubscribe.KVUpdate_UpdateOp, entry *structs.DirEntry) []stream.Event {
+	subjects := []EventSubjectKV{{
+		Key:            entry.Key,
+		EnterpriseMeta: entry.EnterpriseMeta,
+	}}
+	for _, prefix := range kvEventPrefixes(entry.Key) {
+		subjects = append(subjects, EventSubjectKV{
+			Key:            prefix,
+			Prefix:         true,
+			EnterpriseMeta: entry.EnterpriseMeta,
+		})
+	}
+
+	events := make([]stream.Event, 0, len(subjects))
+	for _, subject := range subjects {
+		events = append(events, kvEvent(idx, op, entry, subject))
+	}
+	return events
+}
+
+// kvEventPrefixes returns the empty prefix and every '/'-terminated prefix of
+// key, including key itself if it ends in a '/'.
+func kvEventPrefixes(key string) []string {
+	prefixes := []string{""}
+	for i := 0; i < len(key); i++ {
+		if key[i] == '/' {
+			prefixes = append(prefixes, key[:i+1])
+		}
+	}
+	return prefixes
+}
+
+func kvEvent(idx uint64, op pbsubscribe.KVUpdate_UpdateOp, entry *structs.DirEntry, subject EventSubjectKV) stream.Event {
+	return stream.Event{
+		Topic: EventTopicKV,
+		Index: idx,
+		Payload: EventPayloadKV{
+			Op:      op,
+			Value:   entry,
+			subject: subject,
+		},
+	}
+}
+
+// KVSnapshot is a stream.SnapshotFunc that returns a snapshot of the KV entry
+// or the entries under the prefix identified by the subscription's subject.
+func (s *Store) KVSnapshot(req stream.SubscribeRequest, buf stream.SnapshotAppender) (uint64, error) {
+	subject, ok := req.Subject.(EventSubjectKV)
+	if !ok {
+		return 0, fmt.Errorf("expected SubscribeRequest.Subject to be a: state.EventSubjectKV, was a: %T", req.Subject)
+	}
+
+	tx := s.db.ReadTxn()
+	defer tx.Abort()
+
+	var (
+		idx     uint64
+		entries structs.DirEntries
+	)
+	if subject.Prefix {
+		// Use the same prefix-scoped index as a KVS.List so blocking queries
+		// can switch between the RPC and streaming backends.
+		index, ents, err := s.kvsListTxn(tx, nil, subject.Key, subject.EnterpriseMeta)
+		if err != nil {
+			return 0, err
+		}
+		idx, entries = index, ents
+	} else {
+		index, entry, err := kvsGetTxn(tx, nil, subject.Key, subject.EnterpriseMeta)
+		if err != nil {
+			return 0, err
+		}
+		idx = index
+		if entry != nil {
+			entries = structs.DirEntries{entry}
+		}
+	}
+
+	if l := len(entries); l != 0 {
+		events := make([]stream.Event, l)
+		for i, e := range entries {
+			events[i] = kvEvent(idx, pbsubscribe.KVUpdate_Upsert, e, subject)
+		}
+		buf.Append(events)
+	}
+
+	return idx, nil
+}
@@ -0,0 +1,169 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/consul/stream"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/proto/private/pbsubscribe"
+)
+
+func TestKVEventPrefixes(t *testing.T) {
+	cases := map[string][]string{
+		"":          {""},
+		"foo":       {""},
+		"foo/":      {"", "foo/"},
+		"foo/bar":   {"", "foo/"},
+		"foo/bar/":  {"", "foo/", "foo/bar/"},
+		"/foo//bar": {"", "/", "/foo/", "/foo//"},
+	}
+	for key, expected := range cases {
+		require.Equal(t, expected, kvEventPrefixes(key), "key %q", key)
+	}
+}
+
+func TestKVEventsFromChanges(t *testing.T) {
+	const changeIndex uint64 = 123
+
+	type event struct {
+		op      pbsubscribe.KVUpdate_UpdateOp
+		key     string
+		subject EventSubjectKV
+	}
+
+	testCases := map[string]struct {
+		setup  func(s *Store, tx *txn) error
+		mutate func(s *Store, tx *txn) error
+		events []event
+	}{
+		"upsert key": {
+			mutate: func(_ *Store, tx *txn) error {
+				return kvsSetTxn(tx, changeIndex, &structs.DirEntry{Key: "foo/bar", Value: []byte("a")}, false)
+			},
+			events: []event{
+				{pbsubscribe.KVUpdate_Upsert, "foo/bar", EventSubjectKV{Key: "foo/bar"}},
+				{pbsubscribe.KVUpdate_Upsert, "foo/bar", EventSubjectKV{Key: "", Prefix: true}},
+				{pbsubscribe.KVUpdate_Upsert, "foo/bar", EventSubjectKV{Key: "foo/", Prefix: true}},
+			},
+		},
+		"delete key": {
+			setup: func(_ *Store, tx *txn) error {
+				return kvsSetTxn(tx, 1, &structs.DirEntry{Key: "foo"}, false)
+			},
+			mutate: func(s *Store, tx *txn) error {
+				return s.kvsDeleteTxn(tx, changeIndex, "foo", nil)
+			},
+			events: []event{
+				{pbsubscribe.KVUpdate_Delete, "foo", EventSubjectKV{Key: "foo"}},
+				{pbsubscribe.KVUpdate_Delete, "foo", EventSubjectKV{Key: "", Prefix: true}},
+			},
+		},
+		"delete tree": {
+			setup: func(_ *Store, tx *txn) error {
+				if err := kvsSetTxn(tx, 1, &structs.DirEntry{Key: "foo/a"}, false); err != nil {
+					return err
+				}
+				if err := kvsSetTxn(tx, 1, &structs.DirEntry{Key: "foo/b"}, false); err != nil {
+					return err
+				}
+				return kvsSetTxn(tx, 1, &structs.DirEntry{Key: "other"}, false)
+			},
+			mutate: func(s *Store, tx *txn) error {
+				return s.kvsDeleteTreeTxn(tx, changeIndex, "foo/", nil)
+			},
+			events: []event{
+				{pbsubscribe.KVUpdate_Delete, "foo/a", EventSubjectKV{Key: "foo/a"}},
+				{pbsubscribe.KVUpdate_Delete, "foo/a", EventSubjectKV{Key: "", Prefix: true}},
+				{pbsubscribe.KVUpdate_Delete, "foo/a", EventSubjectKV{Key: "foo/", Prefix: true}},
+				{pbsubscribe.KVUpdate_Delete, "foo/b", EventSubjectKV{Key: "foo/b"}},
+				{pbsubscribe.KVUpdate_Delete, "foo/b", EventSubjectKV{Key: "", Prefix: true}},
+				{pbsubscribe.KVUpdate_Delete, "foo/b", EventSubjectKV{Key: "foo/", Prefix: true}},
+			},
+		},
+	}
+	for desc, tc := range testCases {
+		t.Run(desc, func(t *testing.T) {
+			store := testStateStore(t)
+
+			if tc.setup != nil {
+				tx := store.db.WriteTxn(0)
+				require.NoError(t, tc.setup(store, tx))
+				require.NoError(t, tx.Commit())
+			}
+
+			tx := store.db.WriteTxn(0)
+			t.Cleanup(tx.Abort)
+
+			require.NoError(t, tc.mutate(store, tx))
+
+			events, err := KVEventsFromChanges(tx, Changes{Index: changeIndex, Changes: tx.Changes()})
+			require.NoError(t, err)
+
+			var actual []event
+			for _, e := range events {
+				require.Equal(t, EventTopicKV, e.Topic)
+				require.Equal(t, changeIndex, e.Index)
+
+				payload := e.Payload.(EventPayloadKV)
+				actual = append(actual, event{payload.Op, payload.Value.Key, payload.Subject().(EventSubjectKV)})
+			}
+			require.Equal(t, tc.events, actual)
+		})
+	}
+}
+
+func TestKVSnapshot(t *testing.T) {
+	store := testStateStore(t)
+	testSetKey(t, store, 1, "foo/a", "a", nil)
+	testSetKey(t, store, 2, "foo/b", "b", nil)
+	testSetKey(t, store, 3, "bar", "c", nil)
+
+	testCases := map[string]struct {
+		subject EventSubjectKV
+		index   uint64
+		keys    []string
+	}{
+		"key":         {subject: EventSubjectKV{Key: "foo/a"}, index: 3, keys: []string{"foo/a"}},
+		"missing key": {subject: EventSubjectKV{Key: "foo"}, index: 3},
+		"prefix":      {subject: EventSubjectKV{Key: "foo/", Prefix: true}, index: 2, keys: []string{"foo/a", "foo/b"}},
+		"root prefix": {subject: EventSubjectKV{Prefix: true}, index: 3, keys: []string{"bar", "foo/a", "foo/b"}},
+	}
+	for desc, tc := range testCases {
+		t.Run(desc, func(t *testing.T) {
+			buf := &snapshotAppender{}
+
+			idx, err := store.KVSnapshot(stream.SubscribeRequest{Topic: EventTopicKV, Subject: tc.subject}, buf)
+			require.NoError(t, err)
+			require.Equal(t, tc.index, idx)
+
+			var keys []string
+			for _, events := range buf.events {
+				for _, e := range events {
+					payload := e.Payload.(EventPayloadKV)
+					require.Equal(t, pbsubscribe.KVUpdate_Upsert, payload.Op)
+					require.Equal(t, tc.subject, payload.Subject())
+					keys = append(keys, payload.Value.Key)
+				}
+			}
+			require.Equal(t, tc.keys, keys)
+		})
+	}
+}
+
+func TestEventPayloadKV_HasReadPermission(t *testing.T) {
+	payload := EventPayloadKV{Value: &structs.DirEntry{Key: "foo/bar"}}
+
+	allowed := acl.MockAuthorizer{}
+	allowed.On("KeyRead", "foo/bar", &acl.AuthorizerContext{}).Return(acl.Allow)
+	require.True(t, payload.HasReadPermission(&allowed))
+
+	denied := acl.MockAuthorizer{}
+	denied.On("KeyRead", "foo/bar", &acl.AuthorizerContext{}).Return(acl.Deny)
+	require.False(t, payload.HasReadPermission(&denied))
+}
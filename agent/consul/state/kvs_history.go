@@ -0,0 +1,426 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+const (
+	tableKVsHistory       = "kvs-history"
+	tableKVsHistoryConfig = "kvs-history-config"
+)
+
+// KVSRevisionQuery is used to look up a single retained revision of a key.
+type KVSRevisionQuery struct {
+	Key   string
+	Index uint64
+	acl.EnterpriseMeta
+}
+
+// kvsHistoryTableSchema returns a new table schema used for storing previous
+// revisions of KV entries. Revisions are ordered by partition, namespace and
+// key, and then by the index at which they were written, so a prefix lookup on
+// a key yields its history oldest first.
+func kvsHistoryTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: tableKVsHistory,
+		Indexes: map[string]*memdb.IndexSchema{
+			indexID: {
+				Name:         indexID,
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: indexerSingleWithPrefix[KVSRevisionQuery, *structs.DirEntryRevision, Query]{
+					readIndex:   indexFromKVSRevisionQuery,
+					writeIndex:  indexFromDirEntryRevision,
+					prefixIndex: prefixIndexFromKVSRevisionKey,
+				},
+			},
+		},
+	}
+}
+
+// kvsHistoryConfigTableSchema returns a new table schema used for storing the
+// per-prefix revision retention configuration.
+func kvsHistoryConfigTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: tableKVsHistoryConfig,
+		Indexes: map[string]*memdb.IndexSchema{
+			indexID: {
+				Name:         indexID,
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: indexerSingleWithPrefix[string, *structs.KVSHistoryConfig, string]{
+					readIndex:   indexFromKVSHistoryPrefix,
+					writeIndex:  indexFromKVSHistoryConfig,
+					prefixIndex: indexFromKVSHistoryPrefix,
+				},
+			},
+		},
+	}
+}
+
+func indexFromKVSRevisionQuery(q KVSRevisionQuery) ([]byte, error) {
+	b := kvsRevisionKeyIndex(q.Key, &q.EnterpriseMeta)
+	b.Raw(kvsRevisionIndexBytes(q.Index))
+	return b.Bytes(), nil
+}
+
+func indexFromDirEntryRevision(r *structs.DirEntryRevision) ([]byte, error) {
+	if r.Key == "" {
+		return nil, errMissingValueForIndex
+	}
+	return indexFromKVSRevisionQuery(KVSRevisionQuery{
+		Key:            r.Key,
+		Index:          r.ModifyIndex,
+		EnterpriseMeta: r.EnterpriseMeta,
+	})
+}
+
+func prefixIndexFromKVSRevisionKey(q Query) ([]byte, error) {
+	// Keep the null terminator so that "foo" does not match "foobar".
+	b := kvsRevisionKeyIndex(q.Value, &q.EnterpriseMeta)
+	return b.Bytes(), nil
+}
+
+// kvsRevisionKeyIndex returns the index of the revisions of a key, which
+// starts with its partition and namespace so that keys with the same name in
+// different namespaces have separate histories.
+func kvsRevisionKeyIndex(key string, entMeta *acl.EnterpriseMeta) *indexBuilder {
+	var b indexBuilder
+	b.String(strings.ToLower(entMeta.PartitionOrDefault()))
+	b.String(strings.ToLower(entMeta.NamespaceOrDefault()))
+	b.String(key)
+	return &b
+}
+
+func kvsRevisionIndexBytes(idx uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, idx)
+	return buf
+}
+
+// indexFromKVSHistoryPrefix is used for both exact and longest prefix
+// lookups of a configuration, so it is not null terminated. Instead a null is
+// prepended so that the empty prefix can be represented.
+func indexFromKVSHistoryPrefix(prefix string) ([]byte, error) {
+	return []byte("\x00" + prefix), nil
+}
+
+func indexFromKVSHistoryConfig(c *structs.KVSHistoryConfig) ([]byte, error) {
+	return indexFromKVSHistoryPrefix(c.Prefix)
+}
+
+// KVsHistory is used to pull the full list of retained KV revisions for use
+// during snapshots.
+func (s *Snapshot) KVsHistory() (memdb.ResultIterator, error) {
+	return s.tx.Get(tableKVsHistory, indexID)
+}
+
+// KVsHistoryConfig is used to pull the revision retention configuration for
+// use during snapshots.
+func (s *Snapshot) KVsHistoryConfig() (memdb.ResultIterator, error) {
+	return s.tx.Get(tableKVsHistoryConfig, indexID)
+}
+
+// KVSRevision is used when restoring from a snapshot.
+func (s *Restore) KVSRevision(rev *structs.DirEntryRevision) error {
+	if err := s.tx.Insert(tableKVsHistory, rev); err != nil {
+		return fmt.Errorf("failed restoring kvs revision: %s", err)
+	}
+	if err := indexUpdateMaxTxn(s.tx, rev.SupersededIndex, tableKVsHistory); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	return nil
+}
+
+// KVSHistoryConfig is used when restoring from a snapshot.
+func (s *Restore) KVSHistoryConfig(config *structs.KVSHistoryConfig) error {
+	if err := s.tx.Insert(tableKVsHistoryConfig, config); err != nil {
+		return fmt.Errorf("failed restoring kvs history config: %s", err)
+	}
+	if err := indexUpdateMaxTxn(s.tx, config.ModifyIndex, tableKVsHistoryConfig); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	return nil
+}
+
+// KVSHistoryConfigs returns all of the revision retention configurations,
+// ordered by prefix.
+func (s *Store) KVSHistoryConfigs(ws memdb.WatchSet) (uint64, []*structs.KVSHistoryConfig, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	idx := maxIndexTxn(tx, tableKVsHistoryConfig)
+
+	iter, err := tx.Get(tableKVsHistoryConfig, indexID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed kvs history config lookup: %s", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	var configs []*structs.KVSHistoryConfig
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		configs = append(configs, raw.(*structs.KVSHistoryConfig))
+	}
+	return idx, configs, nil
+}
+
+// KVSHistoryConfigSet is used to create or update the revision retention for
+// a prefix. Existing history is trimmed to the new limit.
+func (s *Store) KVSHistoryConfigSet(idx uint64, config *structs.KVSHistoryConfig) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	existing, err := tx.First(tableKVsHistoryConfig, indexID, config.Prefix)
+	if err != nil {
+		return fmt.Errorf("failed kvs history config lookup: %s", err)
+	}
+
+	stored := *config
+	if existing != nil {
+		stored.CreateIndex = existing.(*structs.KVSHistoryConfig).CreateIndex
+	} else {
+		stored.CreateIndex = idx
+	}
+	stored.ModifyIndex = idx
+
+	if err := tx.Insert(tableKVsHistoryConfig, &stored); err != nil {
+		return fmt.Errorf("failed inserting kvs history config: %s", err)
+	}
+	if err := tx.Insert(tableIndex, &IndexEntry{tableKVsHistoryConfig, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	if err := kvsHistoryPruneTxn(tx, idx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// KVSHistoryConfigDelete is used to remove the revision retention for a
+// prefix. History for keys that are no longer covered by any configuration is
+// discarded.
+func (s *Store) KVSHistoryConfigDelete(idx uint64, prefix string) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	existing, err := tx.First(tableKVsHistoryConfig, indexID, prefix)
+	if err != nil {
+		return fmt.Errorf("failed kvs history config lookup: %s", err)
+	}
+	if existing == nil {
+		return nil
+	}
+
+	if err := tx.Delete(tableKVsHistoryConfig, existing); err != nil {
+		return fmt.Errorf("failed deleting kvs history config: %s", err)
+	}
+	if err := tx.Insert(tableIndex, &IndexEntry{tableKVsHistoryConfig, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	if err := kvsHistoryPruneTxn(tx, idx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// KVSRevisions returns the retained revisions of a key, oldest first. If the
+// key currently exists its value is returned as the last revision.
+func (s *Store) KVSRevisions(ws memdb.WatchSet, key string, entMeta *acl.EnterpriseMeta) (uint64, []*structs.DirEntryRevision, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	if entMeta == nil {
+		entMeta = structs.DefaultEnterpriseMetaInDefaultPartition()
+	}
+
+	idx := maxIndexTxn(tx, tableKVs, tableTombstones, tableKVsHistory)
+
+	revs, err := kvsRevisionsTxn(tx, ws, key, entMeta)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	_, current, err := kvsGetTxn(tx, ws, key, *entMeta)
+	if err != nil {
+		return 0, nil, err
+	}
+	if current != nil {
+		revs = append(revs, &structs.DirEntryRevision{DirEntry: *current})
+	}
+	return idx, revs, nil
+}
+
+// KVSGetAtIndex returns the value that the key had at the given Raft index,
+// or nil if the key did not exist then or that revision is no longer
+// retained.
+func (s *Store) KVSGetAtIndex(ws memdb.WatchSet, key string, atIndex uint64, entMeta *acl.EnterpriseMeta) (uint64, *structs.DirEntry, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	if entMeta == nil {
+		entMeta = structs.DefaultEnterpriseMetaInDefaultPartition()
+	}
+
+	idx := maxIndexTxn(tx, tableKVs, tableTombstones, tableKVsHistory)
+
+	_, current, err := kvsGetTxn(tx, ws, key, *entMeta)
+	if err != nil {
+		return 0, nil, err
+	}
+	if current != nil && current.ModifyIndex <= atIndex {
+		return idx, current, nil
+	}
+
+	revs, err := kvsRevisionsTxn(tx, ws, key, entMeta)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, rev := range revs {
+		if rev.ModifyIndex <= atIndex && atIndex < rev.SupersededIndex {
+			entry := rev.DirEntry.Clone()
+			return idx, entry, nil
+		}
+	}
+	return idx, nil, nil
+}
+
+func kvsRevisionsTxn(tx ReadTxn, ws memdb.WatchSet, key string, entMeta *acl.EnterpriseMeta) ([]*structs.DirEntryRevision, error) {
+	iter, err := tx.Get(tableKVsHistory, indexID+"_prefix", Query{Value: key, EnterpriseMeta: *entMeta})
+	if err != nil {
+		return nil, fmt.Errorf("failed kvs history lookup: %s", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	var revs []*structs.DirEntryRevision
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		revs = append(revs, raw.(*structs.DirEntryRevision))
+	}
+	return revs, nil
+}
+
+// kvsHistoryLimitTxn returns the number of revisions to retain for the given
+// key, using the configuration with the longest matching prefix.
+func kvsHistoryLimitTxn(tx WriteTxn, key string) (int, error) {
+	raw, err := tx.LongestPrefix(tableKVsHistoryConfig, indexID+"_prefix", key)
+	if err != nil {
+		return 0, fmt.Errorf("failed kvs history config lookup: %s", err)
+	}
+	if raw == nil {
+		return 0, nil
+	}
+	return raw.(*structs.KVSHistoryConfig).Revisions, nil
+}
+
+// kvsHistoryEnabledTxn returns true if any retention configuration could
+// apply to a key under the given prefix.
+func kvsHistoryEnabledTxn(tx ReadTxn, prefix string) (bool, error) {
+	iter, err := tx.Get(tableKVsHistoryConfig, indexID)
+	if err != nil {
+		return false, fmt.Errorf("failed kvs history config lookup: %s", err)
+	}
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		config := raw.(*structs.KVSHistoryConfig)
+		if strings.HasPrefix(prefix, config.Prefix) || strings.HasPrefix(config.Prefix, prefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// kvsHistoryRecordTxn retains the given entry as a revision that was
+// superseded at idx, if its key is covered by a retention configuration, and
+// trims the key's history to the configured limit.
+func kvsHistoryRecordTxn(tx WriteTxn, idx uint64, entry *structs.DirEntry, deleted bool) error {
+	limit, err := kvsHistoryLimitTxn(tx, entry.Key)
+	if err != nil {
+		return err
+	}
+	if limit == 0 {
+		return nil
+	}
+
+	rev := &structs.DirEntryRevision{
+		DirEntry:        *entry.Clone(),
+		SupersededIndex: idx,
+		Deleted:         deleted,
+	}
+	if err := tx.Insert(tableKVsHistory, rev); err != nil {
+		return fmt.Errorf("failed inserting kvs revision: %s", err)
+	}
+	if err := tx.Insert(tableIndex, &IndexEntry{tableKVsHistory, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	return kvsHistoryTrimTxn(tx, entry.Key, &entry.EnterpriseMeta, limit)
+}
+
+// kvsHistoryTrimTxn deletes the oldest revisions of a key until no more than
+// limit remain.
+func kvsHistoryTrimTxn(tx WriteTxn, key string, entMeta *acl.EnterpriseMeta, limit int) error {
+	revs, err := kvsRevisionsTxn(tx, nil, key, entMeta)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(revs)-limit; i++ {
+		if err := tx.Delete(tableKVsHistory, revs[i]); err != nil {
+			return fmt.Errorf("failed deleting kvs revision: %s", err)
+		}
+	}
+	return nil
+}
+
+// kvsHistoryPruneTxn applies the current retention configuration to all of
+// the retained history. It is used after the configuration changes.
+func kvsHistoryPruneTxn(tx WriteTxn, idx uint64) error {
+	iter, err := tx.Get(tableKVsHistory, indexID)
+	if err != nil {
+		return fmt.Errorf("failed kvs history lookup: %s", err)
+	}
+
+	// Revisions are ordered by key, so all of a key's revisions are counted
+	// before moving on to the next key.
+	var keys []KVSRevisionQuery
+	var counts []int
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		rev := raw.(*structs.DirEntryRevision)
+		key := KVSRevisionQuery{Key: rev.Key, EnterpriseMeta: rev.EnterpriseMeta}
+		if n := len(keys); n > 0 && keys[n-1] == key {
+			counts[n-1]++
+			continue
+		}
+		keys = append(keys, key)
+		counts = append(counts, 1)
+	}
+
+	var changed bool
+	for i, key := range keys {
+		limit, err := kvsHistoryLimitTxn(tx, key.Key)
+		if err != nil {
+			return err
+		}
+		if counts[i] <= limit {
+			continue
+		}
+		if err := kvsHistoryTrimTxn(tx, key.Key, &key.EnterpriseMeta, limit); err != nil {
+			return err
+		}
+		changed = true
+	}
+
+	if changed {
+		if err := tx.Insert(tableIndex, &IndexEntry{tableKVsHistory, idx}); err != nil {
+			return fmt.Errorf("failed updating index: %s", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+func TestStateStore_KVSHistory(t *testing.T) {
+	s := testStateStore(t)
+
+	// Nothing is retained before history is configured.
+	testSetKey(t, s, 1, "foo/a", "1", nil)
+	testSetKey(t, s, 2, "foo/a", "2", nil)
+	_, revs, err := s.KVSRevisions(nil, "foo/a", nil)
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	require.Equal(t, uint64(0), revs[0].SupersededIndex)
+
+	require.NoError(t, s.KVSHistoryConfigSet(3, &structs.KVSHistoryConfig{Prefix: "foo/", Revisions: 2}))
+
+	// Setting a watch should fire when a revision is retained.
+	ws := memdb.NewWatchSet()
+	_, _, err = s.KVSRevisions(ws, "foo/a", nil)
+	require.NoError(t, err)
+
+	testSetKey(t, s, 4, "foo/a", "3", nil)
+	require.True(t, watchFired(ws))
+
+	testSetKey(t, s, 5, "foo/a", "4", nil)
+	testSetKey(t, s, 6, "foo/a", "5", nil)
+	testSetKey(t, s, 7, "bar", "1", nil)
+	testSetKey(t, s, 8, "bar", "2", nil)
+
+	// Only the configured number of revisions is retained, plus the current
+	// value.
+	idx, revs, err := s.KVSRevisions(nil, "foo/a", nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), idx)
+	require.Len(t, revs, 3)
+	require.Equal(t, "3", string(revs[0].Value))
+	require.Equal(t, uint64(4), revs[0].ModifyIndex)
+	require.Equal(t, uint64(5), revs[0].SupersededIndex)
+	require.Equal(t, "4", string(revs[1].Value))
+	require.Equal(t, uint64(6), revs[1].SupersededIndex)
+	require.Equal(t, "5", string(revs[2].Value))
+	require.Equal(t, uint64(0), revs[2].SupersededIndex)
+
+	// Keys outside the prefix are not retained.
+	_, revs, err = s.KVSRevisions(nil, "bar", nil)
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+
+	// Point-in-time reads.
+	cases := map[uint64]string{
+		3: "",
+		4: "3",
+		5: "4",
+		6: "5",
+		9: "5",
+	}
+	for at, expect := range cases {
+		_, ent, err := s.KVSGetAtIndex(nil, "foo/a", at, nil)
+		require.NoError(t, err)
+		if expect == "" {
+			require.Nil(t, ent, "index %d", at)
+			continue
+		}
+		require.NotNil(t, ent, "index %d", at)
+		require.Equal(t, expect, string(ent.Value), "index %d", at)
+	}
+
+	// Deletes are retained and the key reads as missing afterwards.
+	err = s.KVSDelete(10, "foo/a", nil)
+	require.NoError(t, err)
+	_, revs, err = s.KVSRevisions(nil, "foo/a", nil)
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+	require.True(t, revs[1].Deleted)
+	require.Equal(t, uint64(10), revs[1].SupersededIndex)
+
+	_, ent, err := s.KVSGetAtIndex(nil, "foo/a", 9, nil)
+	require.NoError(t, err)
+	require.Equal(t, "5", string(ent.Value))
+	_, ent, err = s.KVSGetAtIndex(nil, "foo/a", 10, nil)
+	require.NoError(t, err)
+	require.Nil(t, ent)
+
+	// Tree deletes retain every key under the prefix.
+	testSetKey(t, s, 11, "foo/b", "1", nil)
+	testSetKey(t, s, 12, "foo/c", "1", nil)
+	require.NoError(t, s.KVSDeleteTree(13, "foo/", nil))
+	for _, key := range []string{"foo/b", "foo/c"} {
+		_, revs, err := s.KVSRevisions(nil, key, nil)
+		require.NoError(t, err)
+		require.Len(t, revs, 1)
+		require.True(t, revs[0].Deleted)
+		require.Equal(t, uint64(13), revs[0].SupersededIndex)
+	}
+
+	// A longer prefix takes precedence, and lowering the limit trims the
+	// existing history.
+	require.NoError(t, s.KVSHistoryConfigSet(14, &structs.KVSHistoryConfig{Prefix: "foo/a", Revisions: 1}))
+	_, revs, err = s.KVSRevisions(nil, "foo/a", nil)
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	require.True(t, revs[0].Deleted)
+
+	idx, configs, err := s.KVSHistoryConfigs(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(14), idx)
+	require.Len(t, configs, 2)
+	require.Equal(t, "foo/", configs[0].Prefix)
+	require.Equal(t, "foo/a", configs[1].Prefix)
+
+	// Updating a config keeps its create index.
+	require.NoError(t, s.KVSHistoryConfigSet(15, &structs.KVSHistoryConfig{Prefix: "foo/", Revisions: 5}))
+	_, configs, err = s.KVSHistoryConfigs(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), configs[0].CreateIndex)
+	require.Equal(t, uint64(15), configs[0].ModifyIndex)
+
+	// Removing all configs discards the history.
+	require.NoError(t, s.KVSHistoryConfigDelete(16, "foo/a"))
+	require.NoError(t, s.KVSHistoryConfigDelete(17, "foo/"))
+	for _, key := range []string{"foo/a", "foo/b", "foo/c"} {
+		_, revs, err := s.KVSRevisions(nil, key, nil)
+		require.NoError(t, err)
+		require.Empty(t, revs)
+	}
+
+	// Deleting a missing config is a no-op.
+	require.NoError(t, s.KVSHistoryConfigDelete(18, "nope"))
+	idx, configs, err = s.KVSHistoryConfigs(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(17), idx)
+	require.Empty(t, configs)
+}
+
+func TestStateStore_KVSHistory_Snapshot_Restore(t *testing.T) {
+	s := testStateStore(t)
+
+	require.NoError(t, s.KVSHistoryConfigSet(1, &structs.KVSHistoryConfig{Prefix: "foo", Revisions: 3}))
+	testSetKey(t, s, 2, "foo", "1", nil)
+	testSetKey(t, s, 3, "foo", "2", nil)
+	testSetKey(t, s, 4, "foo", "3", nil)
+
+	snap := s.Snapshot()
+	defer snap.Close()
+
+	iter, err := snap.KVsHistoryConfig()
+	require.NoError(t, err)
+	var configs []*structs.KVSHistoryConfig
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		configs = append(configs, raw.(*structs.KVSHistoryConfig))
+	}
+	require.Len(t, configs, 1)
+
+	iter, err = snap.KVsHistory()
+	require.NoError(t, err)
+	var revs []*structs.DirEntryRevision
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		revs = append(revs, raw.(*structs.DirEntryRevision))
+	}
+	require.Len(t, revs, 2)
+
+	s2 := testStateStore(t)
+	restore := s2.Restore()
+	for _, config := range configs {
+		require.NoError(t, restore.KVSHistoryConfig(config))
+	}
+	for _, rev := range revs {
+		require.NoError(t, restore.KVSRevision(rev))
+	}
+	restore.Commit()
+
+	idx, out, err := s2.KVSHistoryConfigs(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), idx)
+	require.Equal(t, configs, out)
+
+	idx, outRevs, err := s2.KVSRevisions(nil, "foo", nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), idx)
+	require.Equal(t, revs, outRevs)
+}
+
+func testIndexerTableKVsHistory() map[string]indexerTestCase {
+	return map[string]indexerTestCase{
+		indexID: {
+			read: indexValue{
+				source:   KVSRevisionQuery{Key: "TheKey", Index: 3},
+				expected: []byte("default\x00default\x00TheKey\x00\x00\x00\x00\x00\x00\x00\x00\x03"),
+			},
+			write: indexValue{
+				source: &structs.DirEntryRevision{
+					DirEntry: structs.DirEntry{Key: "TheKey", RaftIndex: structs.RaftIndex{ModifyIndex: 3}},
+				},
+				expected: []byte("default\x00default\x00TheKey\x00\x00\x00\x00\x00\x00\x00\x00\x03"),
+			},
+			prefix: []indexValue{
+				{
+					source:   Query{Value: "TheKey"},
+					expected: []byte("default\x00default\x00TheKey\x00"),
+				},
+			},
+		},
+	}
+}
+
+func testIndexerTableKVsHistoryConfig() map[string]indexerTestCase {
+	return map[string]indexerTestCase{
+		indexID: {
+			read: indexValue{
+				source:   "foo/",
+				expected: []byte("\x00foo/"),
+			},
+			write: indexValue{
+				source:   &structs.KVSHistoryConfig{Prefix: "foo/"},
+				expected: []byte("\x00foo/"),
+			},
+			prefix: []indexValue{
+				{
+					source:   "foo/bar",
+					expected: []byte("\x00foo/bar"),
+				},
+			},
+		},
+	}
+}
+
+func TestStateStore_KVSHistory_EmptyPrefix(t *testing.T) {
+	s := testStateStore(t)
+
+	// The empty prefix applies to every key, with longer prefixes taking
+	// precedence.
+	require.NoError(t, s.KVSHistoryConfigSet(1, &structs.KVSHistoryConfig{Prefix: "", Revisions: 2}))
+	require.NoError(t, s.KVSHistoryConfigSet(2, &structs.KVSHistoryConfig{Prefix: "foo/", Revisions: 1}))
+	for i, value := range []string{"1", "2", "3", "4"} {
+		testSetKey(t, s, uint64(3+i), "bar", value, nil)
+		testSetKey(t, s, uint64(3+i), "foo/a", value, nil)
+	}
+
+	_, revs, err := s.KVSRevisions(nil, "bar", nil)
+	require.NoError(t, err)
+	require.Len(t, revs, 3)
+
+	_, revs, err = s.KVSRevisions(nil, "foo/a", nil)
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+}
@@ -0,0 +1,379 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-memdb"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+const (
+	tableKVsQuotas     = "kvs-quotas"
+	tableKVsQuotaUsage = "kvs-quota-usage"
+)
+
+// KVSQuotaUsage tracks the number of keys and the combined size of the values
+// under the prefix of a KV quota. It is derived from the KV table, so it is
+// not stored in snapshots; it's rebuilt whenever a quota is written or
+// restored.
+type KVSQuotaUsage struct {
+	Prefix string
+	Keys   int
+	Bytes  int64
+
+	acl.EnterpriseMeta
+}
+
+// kvsQuotasTableSchema returns a new table schema used for storing the
+// per-prefix KV quotas.
+func kvsQuotasTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: tableKVsQuotas,
+		Indexes: map[string]*memdb.IndexSchema{
+			indexID: {
+				Name:         indexID,
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: indexerSingle[Query, *structs.KVSQuota]{
+					readIndex:  indexFromKVSQuotaPrefix,
+					writeIndex: indexFromKVSQuota,
+				},
+			},
+		},
+	}
+}
+
+// kvsQuotaUsageTableSchema returns a new table schema used for tracking the
+// current usage of each KV quota.
+func kvsQuotaUsageTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: tableKVsQuotaUsage,
+		Indexes: map[string]*memdb.IndexSchema{
+			indexID: {
+				Name:         indexID,
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: indexerSingle[Query, *KVSQuotaUsage]{
+					readIndex:  indexFromKVSQuotaPrefix,
+					writeIndex: indexFromKVSQuotaUsage,
+				},
+			},
+		},
+	}
+}
+
+func indexFromKVSQuotaPrefix(q Query) ([]byte, error) {
+	var b indexBuilder
+	b.String(strings.ToLower(q.PartitionOrDefault()))
+	b.String(strings.ToLower(q.NamespaceOrDefault()))
+	b.String(q.Value)
+	return b.Bytes(), nil
+}
+
+func indexFromKVSQuota(q *structs.KVSQuota) ([]byte, error) {
+	return indexFromKVSQuotaPrefix(Query{Value: q.Prefix, EnterpriseMeta: q.EnterpriseMeta})
+}
+
+func indexFromKVSQuotaUsage(u *KVSQuotaUsage) ([]byte, error) {
+	return indexFromKVSQuotaPrefix(Query{Value: u.Prefix, EnterpriseMeta: u.EnterpriseMeta})
+}
+
+// KVsQuotas is used to pull the KV quotas for use during snapshots.
+func (s *Snapshot) KVsQuotas() (memdb.ResultIterator, error) {
+	return s.tx.Get(tableKVsQuotas, indexID)
+}
+
+// KVSQuota is used when restoring from a snapshot. The KV entries must have
+// been restored first so the usage can be computed.
+func (s *Restore) KVSQuota(quota *structs.KVSQuota) error {
+	if err := s.tx.Insert(tableKVsQuotas, quota); err != nil {
+		return fmt.Errorf("failed restoring kvs quota: %s", err)
+	}
+	if err := indexUpdateMaxTxn(s.tx, quota.ModifyIndex, tableKVsQuotas); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	return kvsQuotaUsageResetTxn(s.tx, quota.Prefix, &quota.EnterpriseMeta)
+}
+
+// KVSQuotas returns all of the KV quotas, ordered by partition, namespace and
+// prefix.
+func (s *Store) KVSQuotas(ws memdb.WatchSet) (uint64, []*structs.KVSQuota, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	idx := maxIndexTxn(tx, tableKVsQuotas)
+
+	iter, err := tx.Get(tableKVsQuotas, indexID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed kvs quota lookup: %s", err)
+	}
+	ws.Add(iter.WatchCh())
+
+	var quotas []*structs.KVSQuota
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		quotas = append(quotas, raw.(*structs.KVSQuota))
+	}
+	return idx, quotas, nil
+}
+
+// KVSQuotaSet is used to create or update the quota for a prefix.
+func (s *Store) KVSQuotaSet(idx uint64, quota *structs.KVSQuota) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	existing, err := tx.First(tableKVsQuotas, indexID, Query{Value: quota.Prefix, EnterpriseMeta: quota.EnterpriseMeta})
+	if err != nil {
+		return fmt.Errorf("failed kvs quota lookup: %s", err)
+	}
+
+	stored := *quota
+	if existing != nil {
+		stored.CreateIndex = existing.(*structs.KVSQuota).CreateIndex
+	} else {
+		stored.CreateIndex = idx
+	}
+	stored.ModifyIndex = idx
+
+	if err := tx.Insert(tableKVsQuotas, &stored); err != nil {
+		return fmt.Errorf("failed inserting kvs quota: %s", err)
+	}
+	if err := tx.Insert(tableIndex, &IndexEntry{tableKVsQuotas, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	if existing == nil {
+		if err := kvsQuotaUsageResetTxn(tx, quota.Prefix, &quota.EnterpriseMeta); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// KVSQuotaDelete is used to remove the quota for a prefix.
+func (s *Store) KVSQuotaDelete(idx uint64, prefix string, entMeta *acl.EnterpriseMeta) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	if entMeta == nil {
+		entMeta = structs.DefaultEnterpriseMetaInDefaultPartition()
+	}
+
+	q := Query{Value: prefix, EnterpriseMeta: *entMeta}
+	existing, err := tx.First(tableKVsQuotas, indexID, q)
+	if err != nil {
+		return fmt.Errorf("failed kvs quota lookup: %s", err)
+	}
+	if existing == nil {
+		return nil
+	}
+
+	if err := tx.Delete(tableKVsQuotas, existing); err != nil {
+		return fmt.Errorf("failed deleting kvs quota: %s", err)
+	}
+	if err := tx.Insert(tableIndex, &IndexEntry{tableKVsQuotas, idx}); err != nil {
+		return fmt.Errorf("failed updating index: %s", err)
+	}
+	if _, err := tx.DeleteAll(tableKVsQuotaUsage, indexID, q); err != nil {
+		return fmt.Errorf("failed deleting kvs quota usage: %s", err)
+	}
+
+	return tx.Commit()
+}
+
+// kvsQuotaUsageResetTxn recomputes the usage of the quota for the given
+// prefix from the KV entries.
+func kvsQuotaUsageResetTxn(tx WriteTxn, prefix string, entMeta *acl.EnterpriseMeta) error {
+	iter, err := tx.Get(tableKVs, indexID+"_prefix", Query{Value: prefix, EnterpriseMeta: *entMeta})
+	if err != nil {
+		return fmt.Errorf("failed kvs lookup: %s", err)
+	}
+
+	usage := &KVSQuotaUsage{Prefix: prefix, EnterpriseMeta: *entMeta}
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		usage.Keys++
+		usage.Bytes += int64(len(raw.(*structs.DirEntry).Value))
+	}
+
+	if err := tx.Insert(tableKVsQuotaUsage, usage); err != nil {
+		return fmt.Errorf("failed inserting kvs quota usage: %s", err)
+	}
+	return nil
+}
+
+// kvsQuotasForKeyTxn returns the quotas whose prefix covers the given key.
+// Quotas only apply to the keys of their own namespace.
+func kvsQuotasForKeyTxn(tx ReadTxn, key string, entMeta *acl.EnterpriseMeta) ([]*structs.KVSQuota, error) {
+	iter, err := tx.Get(tableKVsQuotas, indexID)
+	if err != nil {
+		return nil, fmt.Errorf("failed kvs quota lookup: %s", err)
+	}
+
+	var quotas []*structs.KVSQuota
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		quota := raw.(*structs.KVSQuota)
+		if quota.IsSame(entMeta) && strings.HasPrefix(key, quota.Prefix) {
+			quotas = append(quotas, quota)
+		}
+	}
+	return quotas, nil
+}
+
+func kvsQuotaUsageTxn(tx ReadTxn, quota *structs.KVSQuota) (*KVSQuotaUsage, error) {
+	raw, err := tx.First(tableKVsQuotaUsage, indexID, Query{Value: quota.Prefix, EnterpriseMeta: quota.EnterpriseMeta})
+	if err != nil {
+		return nil, fmt.Errorf("failed kvs quota usage lookup: %s", err)
+	}
+	if raw == nil {
+		return &KVSQuotaUsage{Prefix: quota.Prefix, EnterpriseMeta: quota.EnterpriseMeta}, nil
+	}
+	return raw.(*KVSQuotaUsage), nil
+}
+
+// kvsQuotaSetTxn checks that replacing existing with entry doesn't exceed
+// any of the quotas covering the key and, if so, updates their usage. Only
+// growth is rejected, so writes that don't add a key or grow the stored
+// values, such as releasing a lock, always succeed.
+func kvsQuotaSetTxn(tx WriteTxn, existing, entry *structs.DirEntry) error {
+	quotas, err := kvsQuotasForKeyTxn(tx, entry.Key, &entry.EnterpriseMeta)
+	if err != nil {
+		return err
+	}
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	var keys int
+	bytesDelta := int64(len(entry.Value))
+	valueChanged := true
+	if existing == nil {
+		keys = 1
+	} else {
+		bytesDelta -= int64(len(existing.Value))
+		valueChanged = !bytes.Equal(existing.Value, entry.Value)
+	}
+
+	for _, quota := range quotas {
+		usage, err := kvsQuotaUsageTxn(tx, quota)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case quota.MaxValueSize > 0 && valueChanged && int64(len(entry.Value)) > quota.MaxValueSize:
+			return fmt.Errorf("%w: value for key %q is %d bytes, prefix %q allows at most %d bytes per value",
+				structs.ErrKVSQuotaExceeded, entry.Key, len(entry.Value), quota.Prefix, quota.MaxValueSize)
+		case quota.MaxKeys > 0 && keys > 0 && usage.Keys+keys > quota.MaxKeys:
+			return fmt.Errorf("%w: prefix %q allows at most %d keys",
+				structs.ErrKVSQuotaExceeded, quota.Prefix, quota.MaxKeys)
+		case quota.MaxBytes > 0 && bytesDelta > 0 && usage.Bytes+bytesDelta > quota.MaxBytes:
+			return fmt.Errorf("%w: prefix %q allows at most %d bytes of values",
+				structs.ErrKVSQuotaExceeded, quota.Prefix, quota.MaxBytes)
+		}
+	}
+
+	for _, quota := range quotas {
+		if err := kvsQuotaUsageUpdateTxn(tx, quota, keys, bytesDelta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kvsQuotaDeleteTxn updates the usage of the quotas covering the key of a
+// deleted entry.
+func kvsQuotaDeleteTxn(tx WriteTxn, entry *structs.DirEntry) error {
+	quotas, err := kvsQuotasForKeyTxn(tx, entry.Key, &entry.EnterpriseMeta)
+	if err != nil {
+		return err
+	}
+	for _, quota := range quotas {
+		if err := kvsQuotaUsageUpdateTxn(tx, quota, -1, -int64(len(entry.Value))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kvsQuotaDeleteTreeTxn updates the usage of the quotas affected by deleting
+// every key under the given prefix. It must be called before the keys are
+// removed.
+func kvsQuotaDeleteTreeTxn(tx WriteTxn, prefix string, entMeta *acl.EnterpriseMeta) error {
+	if entMeta == nil {
+		entMeta = structs.DefaultEnterpriseMetaInDefaultPartition()
+	}
+
+	iter, err := tx.Get(tableKVsQuotas, indexID)
+	if err != nil {
+		return fmt.Errorf("failed kvs quota lookup: %s", err)
+	}
+
+	var resets, parents []*structs.KVSQuota
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		quota := raw.(*structs.KVSQuota)
+		switch {
+		case !quota.IsSame(entMeta):
+			// The quota is for another namespace.
+		case strings.HasPrefix(quota.Prefix, prefix):
+			// The whole quota is inside the deleted subtree.
+			resets = append(resets, quota)
+		case strings.HasPrefix(prefix, quota.Prefix):
+			parents = append(parents, quota)
+		}
+	}
+
+	for _, quota := range resets {
+		usage := &KVSQuotaUsage{Prefix: quota.Prefix, EnterpriseMeta: quota.EnterpriseMeta}
+		if err := tx.Insert(tableKVsQuotaUsage, usage); err != nil {
+			return fmt.Errorf("failed inserting kvs quota usage: %s", err)
+		}
+	}
+	if len(parents) == 0 {
+		return nil
+	}
+
+	entries, err := tx.Get(tableKVs, indexID+"_prefix", Query{Value: prefix, EnterpriseMeta: *entMeta})
+	if err != nil {
+		return fmt.Errorf("failed kvs lookup: %s", err)
+	}
+	var keys int
+	var size int64
+	for raw := entries.Next(); raw != nil; raw = entries.Next() {
+		keys++
+		size += int64(len(raw.(*structs.DirEntry).Value))
+	}
+	for _, quota := range parents {
+		if err := kvsQuotaUsageUpdateTxn(tx, quota, -keys, -size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func kvsQuotaUsageUpdateTxn(tx WriteTxn, quota *structs.KVSQuota, keys int, size int64) error {
+	if keys == 0 && size == 0 {
+		return nil
+	}
+
+	existing, err := kvsQuotaUsageTxn(tx, quota)
+	if err != nil {
+		return err
+	}
+
+	usage := &KVSQuotaUsage{
+		Prefix:         quota.Prefix,
+		Keys:           existing.Keys + keys,
+		Bytes:          existing.Bytes + size,
+		EnterpriseMeta: quota.EnterpriseMeta,
+	}
+	if err := tx.Insert(tableKVsQuotaUsage, usage); err != nil {
+		return fmt.Errorf("failed inserting kvs quota usage: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package state
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+)
+
+func requireKVSQuotaUsage(t *testing.T, s *Store, prefix string, keys int, size int64) {
+	t.Helper()
+	_, usage, err := s.KVUsage()
+	require.NoError(t, err)
+	for _, u := range usage.QuotaUsage {
+		if u.Prefix == prefix {
+			require.Equal(t, KVSQuotaUsage{Prefix: prefix, Keys: keys, Bytes: size}, u)
+			return
+		}
+	}
+	t.Fatalf("no usage for quota %q", prefix)
+}
+
+func TestStateStore_KVSQuota(t *testing.T) {
+	s := testStateStore(t)
+
+	// Existing data counts towards a new quota.
+	testSetKey(t, s, 1, "team/a", "12345", nil)
+	testSetKey(t, s, 2, "other", "12345", nil)
+
+	ws := memdb.NewWatchSet()
+	_, _, err := s.KVSQuotas(ws)
+	require.NoError(t, err)
+
+	require.NoError(t, s.KVSQuotaSet(3, &structs.KVSQuota{Prefix: "team/", MaxKeys: 3, MaxBytes: 19, MaxValueSize: 10}))
+	require.True(t, watchFired(ws))
+	requireKVSQuotaUsage(t, s, "team/", 1, 5)
+
+	idx, quotas, err := s.KVSQuotas(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), idx)
+	require.Len(t, quotas, 1)
+	require.Equal(t, uint64(3), quotas[0].CreateIndex)
+
+	// Values over the per-value limit are rejected.
+	err = s.KVSSet(4, &structs.DirEntry{Key: "team/b", Value: []byte("12345678901")})
+	require.True(t, structs.IsErrKVSQuotaExceeded(err), "err: %v", err)
+	require.Contains(t, err.Error(), "at most 10 bytes per value")
+
+	// Keys outside the prefix aren't limited.
+	testSetKey(t, s, 5, "other", "12345678901", nil)
+
+	testSetKey(t, s, 6, "team/b", "1234567890", nil)
+	requireKVSQuotaUsage(t, s, "team/", 2, 15)
+
+	// Growing past the total size is rejected, including via CAS.
+	_, b, err := s.KVSGet(nil, "team/b", nil)
+	require.NoError(t, err)
+	err = s.KVSSet(7, &structs.DirEntry{Key: "team/c", Value: []byte("123456")})
+	require.True(t, structs.IsErrKVSQuotaExceeded(err), "err: %v", err)
+	require.Contains(t, err.Error(), "at most 19 bytes of values")
+	ok, err := s.KVSSetCAS(7, &structs.DirEntry{Key: "team/a", Value: []byte("1234567890"), RaftIndex: structs.RaftIndex{ModifyIndex: 1}})
+	require.False(t, ok)
+	require.True(t, structs.IsErrKVSQuotaExceeded(err), "err: %v", err)
+
+	// Shrinking is always allowed.
+	ok, err = s.KVSSetCAS(8, &structs.DirEntry{Key: "team/b", Value: []byte("1"), RaftIndex: structs.RaftIndex{ModifyIndex: b.ModifyIndex}})
+	require.True(t, ok)
+	require.NoError(t, err)
+	requireKVSQuotaUsage(t, s, "team/", 2, 6)
+
+	// Too many keys is rejected.
+	testSetKey(t, s, 9, "team/c", "1", nil)
+	err = s.KVSSet(10, &structs.DirEntry{Key: "team/d", Value: []byte("1")})
+	require.True(t, structs.IsErrKVSQuotaExceeded(err), "err: %v", err)
+	require.Contains(t, err.Error(), "at most 3 keys")
+
+	// The quota is checked for every op in a transaction, and a failed
+	// transaction doesn't change the usage.
+	ops := structs.TxnOps{
+		&structs.TxnOp{KV: &structs.TxnKVOp{Verb: api.KVDelete, DirEnt: structs.DirEntry{Key: "team/c"}}},
+		&structs.TxnOp{KV: &structs.TxnKVOp{Verb: api.KVSet, DirEnt: structs.DirEntry{Key: "team/d", Value: []byte("1")}}},
+		&structs.TxnOp{KV: &structs.TxnKVOp{Verb: api.KVSet, DirEnt: structs.DirEntry{Key: "team/e", Value: []byte("1")}}},
+	}
+	results, errors := s.TxnRW(10, ops)
+	require.Nil(t, results)
+	require.Len(t, errors, 1)
+	require.Equal(t, 2, errors[0].OpIndex)
+	require.True(t, structs.IsErrKVSQuotaExceeded(errors[0]), "err: %v", errors[0])
+	requireKVSQuotaUsage(t, s, "team/", 3, 7)
+
+	// Deleting frees up space.
+	require.NoError(t, s.KVSDelete(11, "team/c", nil))
+	requireKVSQuotaUsage(t, s, "team/", 2, 6)
+	testSetKey(t, s, 12, "team/d", "1", nil)
+	requireKVSQuotaUsage(t, s, "team/", 3, 7)
+
+	// Nested quotas are all enforced and all tracked.
+	require.NoError(t, s.KVSQuotaSet(13, &structs.KVSQuota{Prefix: "team/sub/", MaxKeys: 1}))
+	require.NoError(t, s.KVSDelete(14, "team/d", nil))
+	testSetKey(t, s, 15, "team/sub/x", "1", nil)
+	requireKVSQuotaUsage(t, s, "team/", 3, 7)
+	requireKVSQuotaUsage(t, s, "team/sub/", 1, 1)
+
+	// Deleting a subtree updates the quotas above and below it.
+	require.NoError(t, s.KVSDeleteTree(16, "team/sub", nil))
+	requireKVSQuotaUsage(t, s, "team/", 2, 6)
+	requireKVSQuotaUsage(t, s, "team/sub/", 0, 0)
+
+	// Updating a quota keeps its usage; deleting it removes the usage.
+	require.NoError(t, s.KVSQuotaSet(17, &structs.KVSQuota{Prefix: "team/", MaxKeys: 1}))
+	requireKVSQuotaUsage(t, s, "team/", 2, 6)
+	_, quotas, err = s.KVSQuotas(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), quotas[0].CreateIndex)
+	require.Equal(t, uint64(17), quotas[0].ModifyIndex)
+
+	// A quota lowered below the current usage blocks growth but not updates
+	// that keep the same size.
+	testSetKey(t, s, 18, "team/a", "54321", nil)
+	err = s.KVSSet(19, &structs.DirEntry{Key: "team/new", Value: []byte("1")})
+	require.True(t, structs.IsErrKVSQuotaExceeded(err), "err: %v", err)
+
+	require.NoError(t, s.KVSQuotaDelete(20, "team/", nil))
+	require.NoError(t, s.KVSQuotaDelete(21, "team/sub/", nil))
+	_, usage, err := s.KVUsage()
+	require.NoError(t, err)
+	require.Empty(t, usage.QuotaUsage)
+	testSetKey(t, s, 22, "team/new", "1", nil)
+}
+
+func TestStateStore_KVSQuota_Snapshot_Restore(t *testing.T) {
+	s := testStateStore(t)
+
+	testSetKey(t, s, 1, "team/a", "123", nil)
+	testSetKey(t, s, 2, "team/b", "45", nil)
+	require.NoError(t, s.KVSQuotaSet(3, &structs.KVSQuota{Prefix: "team/", MaxKeys: 10}))
+
+	snap := s.Snapshot()
+	defer snap.Close()
+
+	kvs, err := snap.KVs()
+	require.NoError(t, err)
+	iter, err := snap.KVsQuotas()
+	require.NoError(t, err)
+	var quotas []*structs.KVSQuota
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		quotas = append(quotas, raw.(*structs.KVSQuota))
+	}
+	require.Len(t, quotas, 1)
+
+	s2 := testStateStore(t)
+	restore := s2.Restore()
+	for raw := kvs.Next(); raw != nil; raw = kvs.Next() {
+		require.NoError(t, restore.KVS(raw.(*structs.DirEntry)))
+	}
+	for _, quota := range quotas {
+		require.NoError(t, restore.KVSQuota(quota))
+	}
+	restore.Commit()
+
+	idx, out, err := s2.KVSQuotas(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), idx)
+	require.Equal(t, quotas, out)
+	requireKVSQuotaUsage(t, s2, "team/", 2, 5)
+}
@@ -1027,6 +1027,60 @@ func TestStateStore_KVSLockDelay(t *testing.T) {
 	}
 }
 
+func TestStateStore_KVSListExpired(t *testing.T) {
+	s := testStateStore(t)
+
+	// Nothing expires in an empty store.
+	min, err := s.KVSMinExpirationTime()
+	require.NoError(t, err)
+	require.True(t, min.IsZero())
+
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	older := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	require.NoError(t, s.KVSSet(1, &structs.DirEntry{Key: "foo", Value: []byte("1"), ExpirationTime: &past}))
+	require.NoError(t, s.KVSSet(2, &structs.DirEntry{Key: "bar", Value: []byte("2"), ExpirationTime: &older}))
+	require.NoError(t, s.KVSSet(3, &structs.DirEntry{Key: "baz", Value: []byte("3"), ExpirationTime: &future}))
+	require.NoError(t, s.KVSSet(4, &structs.DirEntry{Key: "qux", Value: []byte("4")}))
+
+	min, err = s.KVSMinExpirationTime()
+	require.NoError(t, err)
+	require.True(t, older.Equal(min))
+
+	// Only expired entries are returned, oldest first.
+	entries, err := s.KVSListExpired(now, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "bar", entries[0].Key)
+	require.Equal(t, "foo", entries[1].Key)
+
+	// The max is respected.
+	entries, err = s.KVSListExpired(now, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "bar", entries[0].Key)
+
+	// Rewriting an entry without an expiration clears it.
+	require.NoError(t, s.KVSSet(5, &structs.DirEntry{Key: "bar", Value: []byte("2")}))
+	entries, err = s.KVSListExpired(now, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "foo", entries[0].Key)
+
+	// Changing only the expiration counts as a modification.
+	_, before, err := s.KVSGet(nil, "baz", nil)
+	require.NoError(t, err)
+	later := future.Add(time.Hour)
+	require.NoError(t, s.KVSSet(6, &structs.DirEntry{Key: "baz", Value: []byte("3"), ExpirationTime: &later}))
+	_, after, err := s.KVSGet(nil, "baz", nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), before.ModifyIndex)
+	require.Equal(t, uint64(6), after.ModifyIndex)
+	require.True(t, later.Equal(*after.ExpirationTime))
+}
+
 func TestStateStore_KVSLock(t *testing.T) {
 	s := testStateStore(t)
 
@@ -1159,6 +1213,55 @@ func TestStateStore_KVSLock(t *testing.T) {
 	}
 }
 
+func TestStateStore_KVSLock_FencingToken(t *testing.T) {
+	s := testStateStore(t)
+
+	testRegisterNode(t, s, 1, "node1")
+	session1, session2 := testUUID(), testUUID()
+	require.NoError(t, s.SessionCreate(2, &structs.Session{ID: session1, Node: "node1"}))
+	require.NoError(t, s.SessionCreate(3, &structs.Session{ID: session2, Node: "node1"}))
+
+	token := func() uint64 {
+		t.Helper()
+		_, e, err := s.KVSGet(nil, "foo", nil)
+		require.NoError(t, err)
+		return e.FencingToken
+	}
+
+	// The first acquisition issues the Raft index as the token.
+	ok, err := s.KVSLock(4, &structs.DirEntry{Key: "foo", Session: session1})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(4), token())
+
+	// Re-locking with the same session and plain writes keep the token, even
+	// if the write tries to set one.
+	ok, err = s.KVSLock(5, &structs.DirEntry{Key: "foo", Value: []byte("bar"), Session: session1})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, s.KVSSet(6, &structs.DirEntry{Key: "foo", Value: []byte("baz"), FencingToken: 100}))
+	require.Equal(t, uint64(4), token())
+
+	// Unlocking keeps the last token for reference.
+	ok, err = s.KVSUnlock(7, &structs.DirEntry{Key: "foo", Session: session1})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(4), token())
+
+	// The next acquisition gets a larger token.
+	ok, err = s.KVSLock(8, &structs.DirEntry{Key: "foo", Session: session2})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(8), token())
+
+	// Invalidating the session releases the lock but keeps the token.
+	require.NoError(t, s.SessionDestroy(9, session2, nil))
+	_, e, err := s.KVSGet(nil, "foo", nil)
+	require.NoError(t, err)
+	require.Equal(t, "", e.Session)
+	require.Equal(t, uint64(8), e.FencingToken)
+}
+
 func TestStateStore_KVSUnlock(t *testing.T) {
 	s := testStateStore(t)
 
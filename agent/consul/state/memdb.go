@@ -38,6 +38,7 @@ type WriteTxn interface {
 	DeleteAll(table, index string, args ...interface{}) (int, error)
 	DeletePrefix(table string, index string, prefix string) (bool, error)
 	Insert(table string, obj interface{}) error
+	LongestPrefix(table, index string, args ...interface{}) (interface{}, error)
 }
 
 // Changes wraps a memdb.Changes to include the index at which these changes
@@ -215,6 +216,7 @@ var (
 	EventTopicSamenessGroup         = pbsubscribe.Topic_SamenessGroup
 	EventTopicJWTProvider           = pbsubscribe.Topic_JWTProvider
 	EventTopicExportedServices      = pbsubscribe.Topic_ExportedServices
+	EventTopicKV                    = pbsubscribe.Topic_KV
 )
 
 func processDBChanges(tx ReadTxn, changes Changes) ([]stream.Event, error) {
@@ -225,6 +227,7 @@ func processDBChanges(tx ReadTxn, changes Changes) ([]stream.Event, error) {
 		ServiceHealthEventsFromChanges,
 		ServiceListUpdateEventsFromChanges,
 		ConfigEntryEventsFromChanges,
+		KVEventsFromChanges,
 		// TODO: add other table handlers here.
 	}
 	for _, fn := range fns {
@@ -33,6 +33,10 @@ func newDBSchema() *memdb.DBSchema {
 		intentionsTableSchema,
 		kindServiceNameTableSchema,
 		kvsTableSchema,
+		kvsHistoryTableSchema,
+		kvsHistoryConfigTableSchema,
+		kvsQuotasTableSchema,
+		kvsQuotaUsageTableSchema,
 		meshTopologyTableSchema,
 		nodesTableSchema,
 		peeringTableSchema,
@@ -57,8 +57,10 @@ func TestNewDBSchema_Indexers(t *testing.T) {
 		tableServiceVirtualIPs: testIndexerTableServiceVirtualIPs,
 		tableKindServiceNames:  testIndexerTableKindServiceNames,
 		// KV
-		tableKVs:        testIndexerTableKVs,
-		tableTombstones: testIndexerTableTombstones,
+		tableKVs:              testIndexerTableKVs,
+		tableTombstones:       testIndexerTableTombstones,
+		tableKVsHistory:       testIndexerTableKVsHistory,
+		tableKVsHistoryConfig: testIndexerTableKVsHistoryConfig,
 		// config
 		tableConfigEntries: testIndexerTableConfigEntries,
 		// peerings
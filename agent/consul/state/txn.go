@@ -99,6 +99,9 @@ func (s *Store) txnKVS(tx WriteTxn, idx uint64, op *structs.TxnKVOp) (structs.Tx
 	case api.KVCheckSession:
 		entry, err = kvsCheckSessionTxn(tx, op.DirEnt.Key, op.DirEnt.Session, &op.DirEnt.EnterpriseMeta)
 
+	case api.KVCheckFence:
+		entry, err = kvsCheckFenceTxn(tx, op.DirEnt.Key, op.DirEnt.FencingToken, &op.DirEnt.EnterpriseMeta)
+
 	case api.KVCheckIndex:
 		entry, err = kvsCheckIndexTxn(tx, op.DirEnt.Key, op.DirEnt.ModifyIndex, op.DirEnt.EnterpriseMeta)
 
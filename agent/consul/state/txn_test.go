@@ -1467,3 +1467,62 @@ func TestStateStore_UnknownTxnOperationsPanic(t *testing.T) {
 		require.Panics(t, func() { s.TxnRW(3, tc) })
 	}
 }
+
+func TestStateStore_Txn_KVS_CheckFence(t *testing.T) {
+	s := testStateStore(t)
+
+	testRegisterNode(t, s, 1, "node1")
+	session := testUUID()
+	require.NoError(t, s.SessionCreate(2, &structs.Session{ID: session, Node: "node1"}))
+	testSetKey(t, s, 3, "unlocked", "", nil)
+
+	ok, err := s.KVSLock(4, &structs.DirEntry{Key: "lock", Session: session})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	fencedSet := func(idx uint64, key string, token uint64) structs.TxnErrors {
+		ops := structs.TxnOps{
+			&structs.TxnOp{
+				KV: &structs.TxnKVOp{
+					Verb:   api.KVCheckFence,
+					DirEnt: structs.DirEntry{Key: key, FencingToken: token},
+				},
+			},
+			&structs.TxnOp{
+				KV: &structs.TxnKVOp{
+					Verb:   api.KVSet,
+					DirEnt: structs.DirEntry{Key: "data", Value: []byte("value")},
+				},
+			},
+		}
+		_, errors := s.TxnRW(idx, ops)
+		return errors
+	}
+
+	// A current token allows the write.
+	require.Empty(t, fencedSet(5, "lock", 4))
+	_, e, err := s.KVSGet(nil, "data", nil)
+	require.NoError(t, err)
+	require.NotNil(t, e)
+
+	// A stale token, an unlocked key, and a missing key all fail.
+	errs := fencedSet(6, "lock", 3)
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), "current token 4 != 3")
+
+	errs = fencedSet(7, "unlocked", 0)
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), "lock is not held")
+
+	errs = fencedSet(8, "nope", 4)
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), "doesn't exist")
+
+	// Once the lock is released the old token is no longer current.
+	ok, err = s.KVSUnlock(9, &structs.DirEntry{Key: "lock", Session: session})
+	require.NoError(t, err)
+	require.True(t, ok)
+	errs = fencedSet(10, "lock", 4)
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), "lock is not held")
+}
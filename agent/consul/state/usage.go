@@ -71,6 +71,10 @@ type PeeringUsage struct {
 
 type KVUsage struct {
 	KVCount int
+
+	// QuotaUsage is the usage of each KV quota, ordered by partition,
+	// namespace and prefix.
+	QuotaUsage []KVSQuotaUsage
 	EnterpriseKVUsage
 }
 
@@ -476,6 +480,15 @@ func (s *Store) KVUsage() (uint64, KVUsage, error) {
 	usage := KVUsage{
 		KVCount: kvs.Count,
 	}
+
+	quotas, err := tx.Get(tableKVsQuotaUsage, indexID)
+	if err != nil {
+		return 0, KVUsage{}, fmt.Errorf("failed kvs quota usage lookup: %s", err)
+	}
+	for raw := quotas.Next(); raw != nil; raw = quotas.Next() {
+		usage.QuotaUsage = append(usage.QuotaUsage, *raw.(*KVSQuotaUsage))
+	}
+
 	results, err := compileEnterpriseKVUsage(tx, usage)
 	if err != nil {
 		return 0, KVUsage{}, fmt.Errorf("failed kvs lookup: %s", err)
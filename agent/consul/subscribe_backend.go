@@ -7,6 +7,7 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/acl/resolver"
 	"github.com/hashicorp/consul/agent/consul/stream"
 	"github.com/hashicorp/consul/agent/grpc-internal/services/subscribe"
 	"github.com/hashicorp/consul/agent/structs"
@@ -23,7 +24,7 @@ func (s subscribeBackend) ResolveTokenAndDefaultMeta(
 	token string,
 	entMeta *acl.EnterpriseMeta,
 	authzContext *acl.AuthorizerContext,
-) (acl.Authorizer, error) {
+) (resolver.Result, error) {
 	return s.srv.ResolveTokenAndDefaultMeta(token, entMeta, authzContext)
 }
 
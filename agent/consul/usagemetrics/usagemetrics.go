@@ -49,6 +49,14 @@ var Gauges = []prometheus.GaugeDefinition{
 		Name: []string{"state", "kv_entries"},
 		Help: "Measures the current number of entries in the Consul KV store. It is only emitted by Consul servers. Added in v1.10.3.",
 	},
+	{
+		Name: []string{"state", "kv_quota", "keys"},
+		Help: "Measures the current number of entries under each KV quota prefix, labeled by prefix. It is only emitted by Consul servers.",
+	},
+	{
+		Name: []string{"state", "kv_quota", "bytes"},
+		Help: "Measures the current combined size in bytes of the values under each KV quota prefix, labeled by prefix. It is only emitted by Consul servers.",
+	},
 	{
 		Name: []string{"state", "connect_instances"},
 		Help: "Measures the current number of unique connect service instances registered with Consul, labeled by Kind. It is only emitted by Consul servers. Added in v1.10.4.",
@@ -285,6 +293,10 @@ type usageReporter interface {
 
 type baseUsageReporter struct {
 	metricLabels []metrics.Label
+
+	// quotaLabels are the labels of the KV quota gauges set by the last
+	// report, keyed by quota.
+	quotaLabels map[string][]metrics.Label
 }
 
 var _ usageReporter = (*baseUsageReporter)(nil)
@@ -371,6 +383,43 @@ func (u *baseUsageReporter) emitKVUsage(kvUsage state.KVUsage) {
 		float32(kvUsage.KVCount),
 		u.metricLabels,
 	)
+
+	quotaLabels := make(map[string][]metrics.Label, len(kvUsage.QuotaUsage))
+	for _, usage := range kvUsage.QuotaUsage {
+		labels := append([]metrics.Label(nil), u.metricLabels...)
+		if ap := usage.PartitionOrEmpty(); ap != "" {
+			labels = append(labels, metrics.Label{Name: "partition", Value: ap})
+		}
+		if ns := usage.NamespaceOrEmpty(); ns != "" {
+			labels = append(labels, metrics.Label{Name: "namespace", Value: ns})
+		}
+		labels = append(labels, metrics.Label{Name: "prefix", Value: usage.Prefix})
+		quotaLabels[usage.PartitionOrDefault()+"/"+usage.NamespaceOrDefault()+"/"+usage.Prefix] = labels
+
+		u.emitKVQuotaUsage(float32(usage.Keys), float32(usage.Bytes), labels)
+	}
+
+	// Zero the gauges of the quotas removed since the last report, otherwise
+	// their last usage would be reported until the server restarts.
+	for quota, labels := range u.quotaLabels {
+		if _, ok := quotaLabels[quota]; !ok {
+			u.emitKVQuotaUsage(0, 0, labels)
+		}
+	}
+	u.quotaLabels = quotaLabels
+}
+
+func (u *baseUsageReporter) emitKVQuotaUsage(keys, bytes float32, labels []metrics.Label) {
+	metrics.SetGaugeWithLabels(
+		[]string{"state", "kv_quota", "keys"},
+		keys,
+		labels,
+	)
+	metrics.SetGaugeWithLabels(
+		[]string{"state", "kv_quota", "bytes"},
+		bytes,
+		labels,
+	)
 }
 
 func (u *baseUsageReporter) emitConfigEntryUsage(configUsage state.ConfigEntryUsage) {
@@ -10,9 +10,11 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-metrics"
+	"github.com/hashicorp/serf/serf"
 	"github.com/stretchr/testify/require"
 
 	"github.com/hashicorp/consul/agent/consul/state"
+	"github.com/hashicorp/consul/agent/structs"
 	"github.com/hashicorp/consul/sdk/testutil"
 )
 
@@ -49,3 +51,43 @@ func TestUsageReporter_CE(t *testing.T) {
 
 	testUsageReporter_Tenantless(t, getMetricsReporter)
 }
+
+func TestUsageReporter_emitKVUsage_RemovedQuota(t *testing.T) {
+	sink := metrics.NewInmemSink(1*time.Minute, 1*time.Minute)
+	cfg := metrics.DefaultConfig("consul.usage.test")
+	cfg.EnableHostname = false
+	metrics.NewGlobal(cfg, sink)
+
+	s, err := newStateStore()
+	require.NoError(t, err)
+	require.NoError(t, s.KVSSet(1, &structs.DirEntry{Key: "quota/a", Value: []byte{1, 2, 3}}))
+	require.NoError(t, s.KVSQuotaSet(2, &structs.KVSQuota{Prefix: "quota/", MaxKeys: 10}))
+
+	mockStateProvider := &mockStateProvider{}
+	mockStateProvider.On("State").Return(s)
+
+	reporter, err := NewUsageMetricsReporter(
+		new(Config).
+			WithStateProvider(mockStateProvider).
+			WithLogger(testutil.Logger(t)).
+			WithDatacenter("dc1").
+			WithGetMembersFunc(func() []serf.Member { return nil }),
+	)
+	require.NoError(t, err)
+
+	const keysGauge = "consul.usage.test.state.kv_quota.keys;datacenter=dc1;prefix=quota/"
+	const bytesGauge = "consul.usage.test.state.kv_quota.bytes;datacenter=dc1;prefix=quota/"
+
+	reporter.runOnce()
+	gauges := sink.Data()[0].Gauges
+	require.Equal(t, float32(1), gauges[keysGauge].Value)
+	require.Equal(t, float32(3), gauges[bytesGauge].Value)
+
+	// Once the quota is removed its gauges must drop to zero rather than
+	// keep reporting the last usage.
+	require.NoError(t, s.KVSQuotaDelete(3, "quota/", nil))
+	reporter.runOnce()
+	gauges = sink.Data()[0].Gauges
+	require.Equal(t, float32(0), gauges[keysGauge].Value)
+	require.Equal(t, float32(0), gauges[bytesGauge].Value)
+}
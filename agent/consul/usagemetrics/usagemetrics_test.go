@@ -993,12 +993,30 @@ func testUsageReporter_emitKVUsage_CE(t *testing.T, getMetricsReporter func(test
 		require.NoError(t, s.KVSDelete(9, "c", &acl.EnterpriseMeta{}))
 		require.NoError(t, s.KVSSet(10, &structs.DirEntry{Key: "e", Value: []byte{1}}))
 		require.NoError(t, s.KVSSet(11, &structs.DirEntry{Key: "f", Value: []byte{1}}))
+		require.NoError(t, s.KVSSet(12, &structs.DirEntry{Key: "quota/a", Value: []byte{1, 2, 3}}))
+		require.NoError(t, s.KVSQuotaSet(13, &structs.KVSQuota{Prefix: "quota/", MaxKeys: 10}))
 	}
 	nodesCase.expectedGauges["consul.usage.test.state.kv_entries;datacenter=dc1"] = metrics.GaugeValue{
 		Name:   "consul.usage.test.state.kv_entries",
-		Value:  4,
+		Value:  5,
 		Labels: []metrics.Label{{Name: "datacenter", Value: "dc1"}},
 	}
+	nodesCase.expectedGauges["consul.usage.test.state.kv_quota.keys;datacenter=dc1;prefix=quota/"] = metrics.GaugeValue{
+		Name:  "consul.usage.test.state.kv_quota.keys",
+		Value: 1,
+		Labels: []metrics.Label{
+			{Name: "datacenter", Value: "dc1"},
+			{Name: "prefix", Value: "quota/"},
+		},
+	}
+	nodesCase.expectedGauges["consul.usage.test.state.kv_quota.bytes;datacenter=dc1;prefix=quota/"] = metrics.GaugeValue{
+		Name:  "consul.usage.test.state.kv_quota.bytes",
+		Value: 3,
+		Labels: []metrics.Label{
+			{Name: "datacenter", Value: "dc1"},
+			{Name: "prefix", Value: "quota/"},
+		},
+	}
 	cases["nodes"] = nodesCase
 
 	for name, tcase := range cases {
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"net/netip"
 	"regexp"
 	"strconv"
 	"strings"
@@ -86,6 +87,8 @@ type dnsServerConfig struct {
 	RecursorStrategy structs.RecursorStrategy
 	RecursorTimeout  time.Duration
 	Recursors        []string
+	RecursorCache    config.RuntimeDNSRecursorCacheConfig
+	ZoneTransfer     config.RuntimeDNSZoneTransferConfig
 	SegmentName      string
 	UDPAnswerLimit   int
 	ARecordLimit     int
@@ -96,6 +99,11 @@ type dnsServerConfig struct {
 	// TTLStict sets TTLs to service by full name match. It Has higher priority than TTLRadix
 	TTLStrict          map[string]time.Duration
 	DisableCompression bool
+	// DNSSEC holds the zone keys when DNSSEC signing is enabled, it is nil otherwise
+	DNSSEC *dnssecConfig
+	// AnswerOrderFromConfigEntries enables the answer order set in the
+	// service-defaults config entries
+	AnswerOrderFromConfigEntries bool
 
 	enterpriseDNSConfig
 }
@@ -110,6 +118,7 @@ type serviceLookup struct {
 	Connect           bool
 	Ingress           bool
 	PortName          string //Only applicable for SRV records
+	AnswerOrder       string
 	acl.EnterpriseMeta
 }
 
@@ -131,6 +140,11 @@ type DNSServer struct {
 	domain    string
 	altDomain string
 	logger    hclog.Logger
+	recursor  *dnsRecursor
+
+	// zoneHistory keeps the last versions of the zones for the incremental
+	// zone transfers.
+	zoneHistory *dnsZoneHistory
 
 	// config stores the config as an atomic value (for hot-reloading). It is always of type *dnsServerConfig
 	config atomic.Value
@@ -151,6 +165,8 @@ func NewDNSServer(a *Agent) (*DNSServer, error) {
 		domain:                domain,
 		altDomain:             altDomain,
 		logger:                a.logger.Named(logging.DNS),
+		recursor:              a.dnsRecursor,
+		zoneHistory:           a.dnsZoneHistory,
 		defaultEnterpriseMeta: *a.AgentEnterpriseMeta(),
 		mux:                   dns.NewServeMux(),
 	}
@@ -189,6 +205,8 @@ func getDNSServerConfig(conf *config.RuntimeConfig) (*dnsServerConfig, error) {
 		OnlyPassing:        conf.DNSOnlyPassing,
 		RecursorStrategy:   conf.DNSRecursorStrategy,
 		RecursorTimeout:    conf.DNSRecursorTimeout,
+		RecursorCache:      conf.DNSRecursorCache,
+		ZoneTransfer:       conf.DNSZoneTransfer,
 		SegmentName:        conf.SegmentName,
 		UDPAnswerLimit:     conf.DNSUDPAnswerLimit,
 		NodeMetaTXT:        conf.DNSNodeMetaTXT,
@@ -201,7 +219,8 @@ func getDNSServerConfig(conf *config.RuntimeConfig) (*dnsServerConfig, error) {
 			Refresh: conf.DNSSOA.Refresh,
 			Retry:   conf.DNSSOA.Retry,
 		},
-		enterpriseDNSConfig: getEnterpriseDNSConfig(conf),
+		AnswerOrderFromConfigEntries: conf.DNSAnswerOrderFromConfigEntries,
+		enterpriseDNSConfig:          getEnterpriseDNSConfig(conf),
 	}
 	if conf.DNSServiceTTL != nil {
 		cfg.TTLRadix = radix.New()
@@ -225,6 +244,12 @@ func getDNSServerConfig(conf *config.RuntimeConfig) (*dnsServerConfig, error) {
 		cfg.Recursors = append(cfg.Recursors, ra)
 	}
 
+	dnssec, err := loadDNSSECConfig(dns.Fqdn(strings.ToLower(conf.DNSDomain)), conf.DNSSECKeyFiles, conf.DNSSECNSEC3)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DNSSEC = dnssec
+
 	return cfg, nil
 }
 
@@ -253,8 +278,11 @@ func (d *DNSServer) ListenAndServe(network, addr string, notif func()) error {
 		Handler:           d.mux,
 		NotifyStartedFunc: notif,
 	}
-	if network == "udp" {
+	switch network {
+	case "udp":
 		d.UDPSize = 65535
+	case "tcp-tls":
+		d.TLSConfig = d.agent.tlsConfigurator.IncomingDNSConfig()
 	}
 	return d.Server.ListenAndServe()
 }
@@ -325,8 +353,9 @@ func setEDNS(request *dns.Msg, response *dns.Msg, ecsGlobal bool) {
 	ednsResp.Hdr.Rrtype = dns.TypeOPT
 	ednsResp.SetUDPSize(edns.UDPSize())
 
-	// Setup the ECS option if present
-	if subnet := ednsSubnetForRequest(request); subnet != nil {
+	// Setup the ECS option if present, it is left out of the responses to
+	// malformed options as required by RFC 7871.
+	if subnet := ednsSubnetForRequest(request); subnet != nil && response.Rcode != dns.RcodeFormatError {
 		subOp := new(dns.EDNS0_SUBNET)
 		subOp.Code = dns.EDNS0SUBNET
 		subOp.Family = subnet.Family
@@ -564,7 +593,13 @@ func (d *DNSServer) handleQuery(resp dns.ResponseWriter, req *dns.Msg) {
 	switch req.Question[0].Qtype {
 	case dns.TypeSOA:
 		ns, glue := d.getNameserversAndNodeRecord(req.Question[0].Name, cfg, maxRecursionLevelDefault)
-		m.Answer = append(m.Answer, d.makeSOARecord(cfg, q.Name))
+		soa := d.makeSOARecord(cfg, q.Name)
+		if zone := d.getResponseDomain(q.Name); strings.EqualFold(q.Name, zone) {
+			if serial, ok := d.zoneSerial(cfg, resp, zone); ok {
+				soa.Serial = serial
+			}
+		}
+		m.Answer = append(m.Answer, soa)
 		m.Ns = append(m.Ns, ns...)
 		m.Extra = append(m.Extra, glue...)
 		m.SetRcode(req, dns.RcodeSuccess)
@@ -575,8 +610,28 @@ func (d *DNSServer) handleQuery(resp dns.ResponseWriter, req *dns.Msg) {
 		m.Extra = glue
 		m.SetRcode(req, dns.RcodeSuccess)
 
-	case dns.TypeAXFR:
-		m.SetRcode(req, dns.RcodeNotImplemented)
+	case dns.TypeAXFR, dns.TypeIXFR:
+		rrs, err := d.zoneTransferRecords(cfg, resp, network, req)
+		if err == nil {
+			d.sendZoneTransfer(resp, req, rrs)
+			return
+		}
+		if !errors.Is(err, errZoneTransferDisabled) {
+			d.logger.Warn("zone transfer failed",
+				"zone", q.Name,
+				"client", resp.RemoteAddr().String(),
+				"error", err,
+			)
+		}
+		m.SetRcode(req, zoneTransferRcode(err))
+
+	case dns.TypeDNSKEY:
+		if zone := d.getResponseDomain(q.Name); cfg.DNSSEC != nil && strings.EqualFold(q.Name, zone) {
+			m.Answer = cfg.DNSSEC.dnskeys(zone, cfg.SOAConfig.Minttl)
+			m.SetRcode(req, dns.RcodeSuccess)
+			break
+		}
+		fallthrough
 
 	default:
 		err = d.dispatch(resp.RemoteAddr(), req, m, cfg, maxRecursionLevelDefault)
@@ -591,6 +646,15 @@ func (d *DNSServer) handleQuery(resp dns.ResponseWriter, req *dns.Msg) {
 
 	d.trimDNSResponse(cfg, network, req, m)
 
+	// Signatures are added once the response has its final set of records,
+	// which may push a UDP response over the size the client can receive.
+	d.signResponse(cfg, d.getResponseDomain(q.Name), req, m)
+	if network != "tcp" && cfg.DNSSEC != nil {
+		if size := maxUDPResponseSize(req); m.Len() > size {
+			m.Truncate(size)
+		}
+	}
+
 	if err := resp.WriteMsg(m); err != nil {
 		d.logger.Warn("failed to respond", "error", err)
 	}
@@ -599,7 +663,7 @@ func (d *DNSServer) handleQuery(resp dns.ResponseWriter, req *dns.Msg) {
 // Craft dns records for an SOA
 func (d *DNSServer) makeSOARecord(cfg *dnsRequestConfig, questionName string) *dns.SOA {
 	domain := d.domain
-	if d.altDomain != "" && strings.HasSuffix(questionName, "."+d.altDomain) {
+	if d.altDomain != "" && (strings.HasSuffix(questionName, "."+d.altDomain) || strings.EqualFold(questionName, d.altDomain)) {
 		domain = d.altDomain
 	}
 
@@ -700,6 +764,7 @@ func (d *DNSServer) parseDatacenter(labels []string, datacenter *string) bool {
 }
 
 var errECSNotGlobal = fmt.Errorf("ECS response is not global")
+var errInvalidECS = fmt.Errorf("invalid EDNS client subnet option")
 var errNameNotFound = fmt.Errorf("DNS name not found")
 
 // errNoData is used to indicate no resource records exist for the specified query type.
@@ -754,6 +819,11 @@ type queryLocality struct {
 	// Example query: _<service>._<protocol>.service.<port_name>.port.consul
 	portName string
 
+	// answerOrder is the order of the service instances in the answers parsed
+	// from a label that has explicit parts.
+	// Example query: <service>.service.<order>.order.consul
+	answerOrder string
+
 	acl.EnterpriseMeta
 }
 
@@ -773,6 +843,13 @@ func (l queryLocality) effectiveDatacenter(defaultDC string) string {
 // dispatch is used to parse a request and invoke the correct handler.
 // parameter maxRecursionLevel will handle whether recursive call can be performed
 func (d *DNSServer) dispatch(remoteAddr net.Addr, req, resp *dns.Msg, cfg *dnsRequestConfig, maxRecursionLevel int) error {
+	// Find out who the query is for, queries forwarded by a resolver can
+	// carry the subnet of the client.
+	source, err := querySourceIP(remoteAddr, req)
+	if err != nil {
+		return err
+	}
+
 	// Choose correct response domain
 	respDomain := d.getResponseDomain(req.Question[0].Name)
 
@@ -833,6 +910,7 @@ func (d *DNSServer) dispatch(remoteAddr net.Addr, req, resp *dns.Msg, cfg *dnsRe
 			MaxRecursionLevel: maxRecursionLevel,
 			EnterpriseMeta:    locality.EnterpriseMeta,
 			PortName:          locality.portName,
+			AnswerOrder:       locality.answerOrder,
 		}
 
 		// Only one of dc or peer can be used.
@@ -894,6 +972,7 @@ func (d *DNSServer) dispatch(remoteAddr net.Addr, req, resp *dns.Msg, cfg *dnsRe
 			Ingress:           false,
 			MaxRecursionLevel: maxRecursionLevel,
 			EnterpriseMeta:    locality.EnterpriseMeta,
+			AnswerOrder:       locality.answerOrder,
 		}
 		// name.connect.consul
 		return d.handleServiceQuery(cfg, lookup, req, resp)
@@ -998,6 +1077,7 @@ func (d *DNSServer) dispatch(remoteAddr net.Addr, req, resp *dns.Msg, cfg *dnsRe
 			Ingress:           true,
 			MaxRecursionLevel: maxRecursionLevel,
 			EnterpriseMeta:    locality.EnterpriseMeta,
+			AnswerOrder:       locality.answerOrder,
 		}
 		// name.ingress.consul
 		return d.handleServiceQuery(cfg, lookup, req, resp)
@@ -1065,7 +1145,7 @@ func (d *DNSServer) dispatch(remoteAddr net.Addr, req, resp *dns.Msg, cfg *dnsRe
 			query = strings.Join(queryParts, ".")
 		}
 
-		err := d.handlePreparedQuery(cfg, datacenter, query, remoteAddr, req, resp, maxRecursionLevel)
+		err := d.handlePreparedQuery(cfg, datacenter, query, source, req, resp, maxRecursionLevel)
 		return ecsNotGlobalError{error: err}
 
 	case "addr":
@@ -1150,6 +1230,8 @@ func rCodeFromError(err error) int {
 		return dns.RcodeSuccess
 	case errors.Is(err, errECSNotGlobal):
 		return rCodeFromError(errors.Unwrap(err))
+	case errors.Is(err, errInvalidECS):
+		return dns.RcodeFormatError
 	case errors.Is(err, errNameNotFound),
 		structs.IsErrNoDCPath(err),
 		structs.IsErrQueryNotFound(err),
@@ -1398,13 +1480,9 @@ func trimTCPResponse(req, resp *dns.Msg) (trimmed bool) {
 	return truncated
 }
 
-// trimUDPResponse makes sure a UDP response is not longer than allowed by RFC
-// 1035. Enforce an arbitrary limit that can be further ratcheted down by
-// config, and then make sure the response doesn't exceed 512 bytes. Any extra
-// records will be trimmed along with answers.
-func trimUDPResponse(req, resp *dns.Msg, udpAnswerLimit int) (trimmed bool) {
-	numAnswers := len(resp.Answer)
-	hasExtra := len(resp.Extra) > 0
+// maxUDPResponseSize returns the largest UDP response the client accepts, as
+// advertised by EDNS, capped to what fits in a UDP datagram.
+func maxUDPResponseSize(req *dns.Msg) int {
 	maxSize := defaultMaxUDPSize
 
 	// Update to the maximum edns size
@@ -1418,6 +1496,17 @@ func trimUDPResponse(req, resp *dns.Msg, udpAnswerLimit int) (trimmed bool) {
 	if maxSize > maxUDPDatagramSize {
 		maxSize = maxUDPDatagramSize
 	}
+	return maxSize
+}
+
+// trimUDPResponse makes sure a UDP response is not longer than allowed by RFC
+// 1035. Enforce an arbitrary limit that can be further ratcheted down by
+// config, and then make sure the response doesn't exceed 512 bytes. Any extra
+// records will be trimmed along with answers.
+func trimUDPResponse(req, resp *dns.Msg, udpAnswerLimit int) (trimmed bool) {
+	numAnswers := len(resp.Answer)
+	hasExtra := len(resp.Extra) > 0
+	maxSize := maxUDPResponseSize(req)
 
 	// We avoid some function calls and allocations by only handling the
 	// extra data when necessary.
@@ -1525,6 +1614,17 @@ func (d *DNSServer) lookupServiceNodes(cfg *dnsRequestConfig, lookup serviceLook
 		EnterpriseMeta: lookup.EnterpriseMeta,
 	}
 
+	// Have the servers sort the instances by estimated round trip time
+	// from this agent.
+	if lookup.AnswerOrder == structs.DNSAnswerOrderNearest {
+		args.Source = structs.QuerySource{
+			Datacenter:    d.agent.config.Datacenter,
+			Segment:       d.agent.config.SegmentName,
+			Node:          d.agent.config.NodeName,
+			NodePartition: d.agent.config.PartitionOrEmpty(),
+		}
+	}
+
 	out, _, err := d.agent.rpcClientHealth.ServiceNodes(context.TODO(), args)
 	if err != nil {
 		return out, err
@@ -1535,6 +1635,7 @@ func (d *DNSServer) lookupServiceNodes(cfg *dnsRequestConfig, lookup serviceLook
 
 // handleServiceQuery is used to handle a service query
 func (d *DNSServer) handleServiceQuery(cfg *dnsRequestConfig, lookup serviceLookup, req, resp *dns.Msg) error {
+	lookup.AnswerOrder = d.serviceAnswerOrder(cfg, lookup)
 	out, err := d.lookupServiceNodes(cfg, lookup)
 	if err != nil {
 		return fmt.Errorf("rpc request failed: %w", err)
@@ -1545,8 +1646,7 @@ func (d *DNSServer) handleServiceQuery(cfg *dnsRequestConfig, lookup serviceLook
 		return errNameNotFound
 	}
 
-	// Perform a random shuffle
-	out.Nodes.Shuffle()
+	d.orderServiceNodes(lookup.AnswerOrder, out.Nodes)
 
 	// Determine the TTL
 	ttl, _ := cfg.GetTTLForService(lookup.Service)
@@ -1568,6 +1668,75 @@ func (d *DNSServer) handleServiceQuery(cfg *dnsRequestConfig, lookup serviceLook
 	return nil
 }
 
+// querySourceIP returns the address of the client that sent req, used to sort
+// the results of prepared queries. When the query has an EDNS Client Subnet
+// option, as added by resolvers forwarding queries on behalf of their clients,
+// the subnet of the client is used instead of the address of the resolver.
+// Subnets larger than a single address are returned in CIDR notation.
+func querySourceIP(remoteAddr net.Addr, req *dns.Msg) (string, error) {
+	if subnet := ednsSubnetForRequest(req); subnet != nil {
+		prefix, err := ednsSubnetPrefix(subnet)
+		if err != nil {
+			return "", err
+		}
+		// A source prefix length of 0 means that the client did not want to
+		// disclose its address.
+		if prefix.Bits() > 0 {
+			if prefix.IsSingleIP() {
+				return prefix.Addr().String(), nil
+			}
+			return prefix.String(), nil
+		}
+	}
+
+	switch v := remoteAddr.(type) {
+	case *net.UDPAddr:
+		return v.IP.String(), nil
+	case *net.TCPAddr:
+		return v.IP.String(), nil
+	case *net.IPAddr:
+		return v.IP.String(), nil
+	}
+	return "", nil
+}
+
+// ednsSubnetPrefix returns the subnet of an EDNS Client Subnet option. It
+// returns errInvalidECS when the option is malformed as described in section
+// 7.1.2 of RFC 7871.
+func ednsSubnetPrefix(subnet *dns.EDNS0_SUBNET) (netip.Prefix, error) {
+	// The scope is only set in responses.
+	if subnet.SourceScope != 0 {
+		return netip.Prefix{}, errInvalidECS
+	}
+	if subnet.SourceNetmask == 0 {
+		return netip.Prefix{}, nil
+	}
+
+	addr, ok := netip.AddrFromSlice(subnet.Address)
+	if !ok {
+		return netip.Prefix{}, errInvalidECS
+	}
+	switch subnet.Family {
+	case 1:
+		if addr = addr.Unmap(); !addr.Is4() {
+			return netip.Prefix{}, errInvalidECS
+		}
+	case 2:
+		if !addr.Is6() {
+			return netip.Prefix{}, errInvalidECS
+		}
+	default:
+		return netip.Prefix{}, errInvalidECS
+	}
+
+	prefix, err := addr.Prefix(int(subnet.SourceNetmask))
+	// The address bits past the source prefix length must be zero.
+	if err != nil || prefix.Addr() != addr {
+		return netip.Prefix{}, errInvalidECS
+	}
+	return prefix, nil
+}
+
 func ednsSubnetForRequest(req *dns.Msg) *dns.EDNS0_SUBNET {
 	// IsEdns0 returns the EDNS RR if present or nil otherwise
 	edns := req.IsEdns0()
@@ -1586,7 +1755,7 @@ func ednsSubnetForRequest(req *dns.Msg) *dns.EDNS0_SUBNET {
 }
 
 // handlePreparedQuery is used to handle a prepared query.
-func (d *DNSServer) handlePreparedQuery(cfg *dnsRequestConfig, datacenter, query string, remoteAddr net.Addr, req, resp *dns.Msg, maxRecursionLevel int) error {
+func (d *DNSServer) handlePreparedQuery(cfg *dnsRequestConfig, datacenter, query, source string, req, resp *dns.Msg, maxRecursionLevel int) error {
 	// Execute the prepared query.
 	args := structs.PreparedQueryExecuteRequest{
 		Datacenter:    datacenter,
@@ -1607,21 +1776,9 @@ func (d *DNSServer) handlePreparedQuery(cfg *dnsRequestConfig, datacenter, query
 			Node:          d.agent.config.NodeName,
 			NodePartition: d.agent.config.PartitionOrEmpty(),
 		},
-	}
-
-	subnet := ednsSubnetForRequest(req)
-
-	if subnet != nil {
-		args.Source.Ip = subnet.Address.String()
-	} else {
-		switch v := remoteAddr.(type) {
-		case *net.UDPAddr:
-			args.Source.Ip = v.IP.String()
-		case *net.TCPAddr:
-			args.Source.Ip = v.IP.String()
-		case *net.IPAddr:
-			args.Source.Ip = v.IP.String()
-		}
+		Source: structs.QuerySource{
+			Ip: source,
+		},
 	}
 
 	out, err := d.lookupPreparedQuery(cfg, args)
@@ -2169,42 +2326,25 @@ func (d *DNSServer) handleRecurse(resp dns.ResponseWriter, req *dns.Msg) {
 	}
 
 	// Recursively resolve
-	c := &dns.Client{Net: network, Timeout: cfg.RecursorTimeout}
-	var r *dns.Msg
-	var rtt time.Duration
-	var err error
-	for _, idx := range cfg.RecursorStrategy.Indexes(len(cfg.Recursors)) {
-		recursor := cfg.Recursors[idx]
-		r, rtt, err = c.Exchange(req, recursor)
-		// Check if the response is valid and has the desired Response code
-		if r != nil && (r.Rcode != dns.RcodeSuccess && r.Rcode != dns.RcodeNameError) {
-			d.logger.Debug("recurse failed for question",
-				"question", q,
-				"rtt", rtt,
-				"recursor", recursor,
-				"rcode", dns.RcodeToString[r.Rcode],
-			)
-			// If we still have recursors to forward the query to,
-			// we move forward onto the next one else the loop ends
-			continue
-		} else if err == nil || (r != nil && r.Truncated) {
-			// Compress the response; we don't know if the incoming
-			// response was compressed or not, so by not compressing
-			// we might generate an invalid packet on the way out.
-			r.Compress = !cfg.DisableCompression
-
-			// Forward the response
-			d.logger.Debug("recurse succeeded for question",
-				"question", q,
-				"rtt", rtt,
-				"recursor", recursor,
-			)
-			if err := resp.WriteMsg(r); err != nil {
-				d.logger.Warn("failed to respond", "error", err)
+	if r, err := d.recursor.exchange(cfg, req, network); err == nil {
+		// Compress the response; we don't know if the incoming
+		// response was compressed or not, so by not compressing
+		// we might generate an invalid packet on the way out.
+		r.Compress = !cfg.DisableCompression
+
+		// A cached response may have been received over TCP and not
+		// fit in a UDP datagram.
+		if network == "udp" {
+			if size := maxUDPResponseSize(req); r.Len() > size {
+				r.Truncate(size)
 			}
-			return
 		}
-		d.logger.Error("recurse failed", "error", err)
+
+		// Forward the response
+		if err := resp.WriteMsg(r); err != nil {
+			d.logger.Warn("failed to respond", "error", err)
+		}
+		return
 	}
 
 	// If all resolvers fail, return a SERVFAIL message
@@ -2255,27 +2395,12 @@ func (d *DNSServer) resolveCNAME(cfg *dnsRequestConfig, name string, maxRecursio
 	m.SetQuestion(name, dns.TypeA)
 
 	// Make a DNS lookup request
-	c := &dns.Client{Net: "udp", Timeout: cfg.RecursorTimeout}
-	var r *dns.Msg
-	var rtt time.Duration
-	var err error
-	for _, idx := range cfg.RecursorStrategy.Indexes(len(cfg.Recursors)) {
-		recursor := cfg.Recursors[idx]
-		r, rtt, err = c.Exchange(m, recursor)
-		if err == nil {
-			d.logger.Debug("cname recurse RTT for name",
-				"name", name,
-				"rtt", rtt,
-			)
-			return r.Answer
-		}
-		d.logger.Error("cname recurse failed for name",
-			"name", name,
-			"error", err,
-		)
+	r, err := d.recursor.exchange(cfg, m, "udp")
+	if err != nil {
+		d.logger.Error("all resolvers failed for name", "name", name)
+		return nil
 	}
-	d.logger.Error("all resolvers failed for name", "name", name)
-	return nil
+	return r.Answer
 }
 
 // coalesceDNSToken returns the ACL token to use for DNS queries.
@@ -0,0 +1,134 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"math/rand"
+	"slices"
+
+	cachetype "github.com/hashicorp/consul/agent/cache-types"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+// serviceAnswerOrder returns the order of the service instances in the
+// answers to lookup. The order given in the query takes precedence over the
+// one set in the service-defaults config entry of the service, instances are
+// shuffled when neither is set. The config entry is only looked up when
+// answer_order_from_config_entries is enabled.
+func (d *DNSServer) serviceAnswerOrder(cfg *dnsRequestConfig, lookup serviceLookup) string {
+	if lookup.AnswerOrder != "" {
+		return lookup.AnswerOrder
+	}
+
+	// The config entries of the services imported from peers are not known
+	// to this cluster.
+	if !cfg.AnswerOrderFromConfigEntries || lookup.PeerName != "" {
+		return structs.DNSAnswerOrderRandom
+	}
+
+	// Config entries are replicated to every datacenter, so the local one is
+	// used even for the lookups in other datacenters.
+	args := structs.ConfigEntryQuery{
+		Kind:       structs.ServiceDefaults,
+		Name:       lookup.Service,
+		Datacenter: d.agent.config.Datacenter,
+		QueryOptions: structs.QueryOptions{
+			Token:      d.coalesceDNSToken(cfg.token),
+			AllowStale: true,
+		},
+		EnterpriseMeta: lookup.EnterpriseMeta,
+	}
+	raw, _, err := d.agent.cache.Get(context.TODO(), cachetype.ConfigEntryName, &args)
+	if err != nil {
+		d.logger.Debug("failed to fetch service defaults, using the default answer order",
+			"service", lookup.Service,
+			"error", err,
+		)
+		return structs.DNSAnswerOrderRandom
+	}
+	reply, ok := raw.(*structs.ConfigEntryResponse)
+	if !ok {
+		return structs.DNSAnswerOrderRandom
+	}
+	entry, ok := reply.Entry.(*structs.ServiceConfigEntry)
+	if !ok || entry.DNSAnswerOrder == "" {
+		return structs.DNSAnswerOrderRandom
+	}
+	return entry.DNSAnswerOrder
+}
+
+// orderServiceNodes sorts the instances of a service in the given order.
+func (d *DNSServer) orderServiceNodes(order string, nodes structs.CheckServiceNodes) {
+	switch order {
+	case structs.DNSAnswerOrderWeighted:
+		weightedShuffle(nodes)
+
+	case structs.DNSAnswerOrderNearest:
+		// The servers have already sorted the instances by round trip time,
+		// they are shuffled by weight instead when the agent has no
+		// coordinates. Either way the sort below keeps that order within
+		// each locality.
+		if d.agent.config.DisableCoordinates {
+			weightedShuffle(nodes)
+		}
+		agentLocality := d.agent.config.StructLocality()
+		slices.SortStableFunc(nodes, func(a, b structs.CheckServiceNode) int {
+			return cmp.Compare(localityDistance(agentLocality, a), localityDistance(agentLocality, b))
+		})
+
+	default:
+		nodes.Shuffle()
+	}
+}
+
+// weightedShuffle shuffles nodes so that the probability of an instance coming
+// before the others is proportional to its weight, using the algorithm of
+// Efraimidis and Spirakis. The instances with a weight of 0 come last.
+func weightedShuffle(nodes structs.CheckServiceNodes) {
+	// Break the ties between the instances with a weight of 0.
+	nodes.Shuffle()
+
+	type weightedNode struct {
+		node structs.CheckServiceNode
+		key  float64
+	}
+	weighted := make([]weightedNode, len(nodes))
+	for i, node := range nodes {
+		weighted[i].node = node
+		if weight := findWeight(node); weight > 0 {
+			weighted[i].key = math.Pow(rand.Float64(), 1/float64(weight))
+		}
+	}
+	slices.SortStableFunc(weighted, func(a, b weightedNode) int {
+		return cmp.Compare(b.key, a.key)
+	})
+	for i := range weighted {
+		nodes[i] = weighted[i].node
+	}
+}
+
+// localityDistance returns how far an instance is from the locality of the
+// agent: 0 for the same zone, 1 for the same region and 2 otherwise. All the
+// instances are at the same distance when the agent has no locality.
+func localityDistance(agentLocality *structs.Locality, node structs.CheckServiceNode) int {
+	if agentLocality == nil || agentLocality.Region == "" {
+		return 0
+	}
+
+	locality := node.Service.Locality
+	if locality == nil && node.Node != nil {
+		locality = node.Node.Locality
+	}
+	switch {
+	case locality == nil || locality.Region != agentLocality.Region:
+		return 2
+	case agentLocality.Zone != "" && locality.Zone == agentLocality.Zone:
+		return 0
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestDNS_ServiceLookup_AnswerOrder(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		locality {
+			region = "us-west-1"
+			zone = "us-west-1a"
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	instances := []struct {
+		node     string
+		address  string
+		weight   int
+		locality *structs.Locality
+	}{
+		{"heavy", "127.0.0.1", 1000, nil},
+		{"light", "127.0.0.2", 1, &structs.Locality{Region: "us-west-1", Zone: "us-west-1b"}},
+		{"near", "127.0.0.3", 1, &structs.Locality{Region: "us-west-1", Zone: "us-west-1a"}},
+	}
+	for _, inst := range instances {
+		args := &structs.RegisterRequest{
+			Datacenter: "dc1",
+			Node:       inst.node,
+			Address:    inst.address,
+			Service: &structs.NodeService{
+				Service:  "db",
+				Port:     12345,
+				Weights:  &structs.Weights{Passing: inst.weight, Warning: 1},
+				Locality: inst.locality,
+			},
+		}
+		var out struct{}
+		require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+	}
+
+	// firstAnswers counts how many times each address comes first in the
+	// answers to name.
+	firstAnswers := func(t *testing.T, name string, qtype uint16) map[string]int {
+		t.Helper()
+		counts := make(map[string]int)
+		for range 20 {
+			m := new(dns.Msg)
+			m.SetQuestion(name, qtype)
+			in, _, err := new(dns.Client).Exchange(m, a.DNSAddr())
+			require.NoError(t, err)
+			require.Equal(t, dns.RcodeSuccess, in.Rcode)
+			require.Len(t, in.Answer, 3)
+			switch rr := in.Answer[0].(type) {
+			case *dns.A:
+				counts[rr.A.String()]++
+			case *dns.SRV:
+				counts[rr.Target]++
+			}
+		}
+		return counts
+	}
+
+	t.Run("weighted label", func(t *testing.T) {
+		counts := firstAnswers(t, "db.service.weighted.order.consul.", dns.TypeA)
+		require.GreaterOrEqual(t, counts["127.0.0.1"], 15, "%v", counts)
+	})
+
+	t.Run("nearest label", func(t *testing.T) {
+		counts := firstAnswers(t, "db.service.nearest.order.consul.", dns.TypeA)
+		require.Equal(t, map[string]int{"127.0.0.3": 20}, counts)
+
+		counts = firstAnswers(t, "_db._tcp.service.nearest.order.consul.", dns.TypeSRV)
+		require.Equal(t, map[string]int{"near.node.dc1.consul.": 20}, counts)
+	})
+
+	t.Run("invalid label", func(t *testing.T) {
+		m := new(dns.Msg)
+		m.SetQuestion("db.service.sorted.order.consul.", dns.TypeA)
+		in, _, err := new(dns.Client).Exchange(m, a.DNSAddr())
+		require.NoError(t, err)
+		require.Equal(t, dns.RcodeNameError, in.Rcode)
+	})
+
+	t.Run("config entry", func(t *testing.T) {
+		args := &structs.ConfigEntryRequest{
+			Datacenter: "dc1",
+			Entry: &structs.ServiceConfigEntry{
+				Kind:           structs.ServiceDefaults,
+				Name:           "db",
+				DNSAnswerOrder: structs.DNSAnswerOrderNearest,
+			},
+		}
+		var out bool
+		require.NoError(t, a.RPC(context.Background(), "ConfigEntry.Apply", args, &out))
+
+		// The config entry is ignored until answer_order_from_config_entries
+		// is enabled.
+		counts := firstAnswers(t, "db.service.consul.", dns.TypeA)
+		require.Less(t, counts["127.0.0.3"], 20, "%v", counts)
+
+		cfg := *a.config
+		cfg.DNSAnswerOrderFromConfigEntries = true
+		for _, srv := range a.dnsServers {
+			require.NoError(t, srv.ReloadConfig(&cfg))
+		}
+		counts = firstAnswers(t, "db.service.consul.", dns.TypeA)
+		require.Equal(t, map[string]int{"127.0.0.3": 20}, counts)
+
+		// The label takes precedence over the config entry.
+		counts = firstAnswers(t, "db.service.weighted.order.consul.", dns.TypeA)
+		require.GreaterOrEqual(t, counts["127.0.0.1"], 15, "%v", counts)
+	})
+}
+
+func TestWeightedShuffle(t *testing.T) {
+	nodes := make(structs.CheckServiceNodes, 4)
+	for i := range nodes {
+		nodes[i] = structs.CheckServiceNode{
+			Node: &structs.Node{Node: fmt.Sprintf("node%d", i)},
+			Service: &structs.NodeService{
+				Service: "db",
+				Weights: &structs.Weights{Passing: i, Warning: 1},
+			},
+		}
+	}
+
+	first := make(map[string]int)
+	for range 1000 {
+		weightedShuffle(nodes)
+		require.Len(t, nodes, 4)
+		// The instance with a weight of 0 always comes last.
+		require.Equal(t, "node0", nodes[3].Node.Node)
+		first[nodes[0].Node.Node]++
+	}
+
+	// node3 comes first half of the time, node1 a sixth of the time.
+	require.InDelta(t, 500, first["node3"], 100)
+	require.InDelta(t, 167, first["node1"], 70)
+}
+
+func TestLocalityDistance(t *testing.T) {
+	agentLocality := &structs.Locality{Region: "us-west-1", Zone: "us-west-1a"}
+	node := func(nodeLocality, serviceLocality *structs.Locality) structs.CheckServiceNode {
+		return structs.CheckServiceNode{
+			Node:    &structs.Node{Locality: nodeLocality},
+			Service: &structs.NodeService{Locality: serviceLocality},
+		}
+	}
+
+	cases := map[string]struct {
+		agent    *structs.Locality
+		node     structs.CheckServiceNode
+		expected int
+	}{
+		"same zone": {
+			agent:    agentLocality,
+			node:     node(nil, &structs.Locality{Region: "us-west-1", Zone: "us-west-1a"}),
+			expected: 0,
+		},
+		"same region": {
+			agent:    agentLocality,
+			node:     node(nil, &structs.Locality{Region: "us-west-1", Zone: "us-west-1b"}),
+			expected: 1,
+		},
+		"other region": {
+			agent:    agentLocality,
+			node:     node(nil, &structs.Locality{Region: "us-east-1", Zone: "us-west-1a"}),
+			expected: 2,
+		},
+		"node locality": {
+			agent:    agentLocality,
+			node:     node(&structs.Locality{Region: "us-west-1", Zone: "us-west-1a"}, nil),
+			expected: 0,
+		},
+		"service locality takes precedence": {
+			agent:    agentLocality,
+			node:     node(&structs.Locality{Region: "us-west-1", Zone: "us-west-1a"}, &structs.Locality{Region: "us-east-1"}),
+			expected: 2,
+		},
+		"no locality": {
+			agent:    agentLocality,
+			node:     node(nil, nil),
+			expected: 2,
+		},
+		"agent without locality": {
+			node:     node(nil, &structs.Locality{Region: "us-west-1", Zone: "us-west-1a"}),
+			expected: 0,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, localityDistance(tc.agent, tc.node))
+		})
+	}
+}
@@ -33,11 +33,12 @@ func (d *DNSServer) parseLocality(labels []string, cfg *dnsRequestConfig) (query
 	}
 
 	switch len(labels) {
-	case 2, 4, 6:
+	case 2, 4, 6, 8:
 		// Support the following formats:
 		// - [.<datacenter>.dc]
 		// - [.<peer>.peer]
 		// - [.<port-name>.port]
+		// - [.<order>.order]
 		for i := 0; i < len(labels); i += 2 {
 			switch labels[i+1] {
 			case "dc":
@@ -46,6 +47,11 @@ func (d *DNSServer) parseLocality(labels []string, cfg *dnsRequestConfig) (query
 				locality.peer = labels[i]
 			case "port":
 				locality.portName = labels[i]
+			case "order":
+				if !structs.IsValidDNSAnswerOrder(labels[i]) {
+					return queryLocality{}, false
+				}
+				locality.answerOrder = labels[i]
 			default:
 				return queryLocality{}, false
 			}
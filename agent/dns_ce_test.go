@@ -153,6 +153,24 @@ func getTestCasesParseLocality() []testCaseParseLocality {
 			},
 			expectedOK: true,
 		},
+		{
+			name:                "test [.<order>.order]",
+			labels:              []string{"nearest", "order", "test-dc", "dc"},
+			enterpriseDNSConfig: enterpriseDNSConfig{},
+			expectedResult: queryLocality{
+				EnterpriseMeta: acl.EnterpriseMeta{},
+				datacenter:     "test-dc",
+				answerOrder:    "nearest",
+			},
+			expectedOK: true,
+		},
+		{
+			name:                "test invalid order",
+			labels:              []string{"sorted", "order"},
+			enterpriseDNSConfig: enterpriseDNSConfig{},
+			expectedResult:      queryLocality{},
+			expectedOK:          false,
+		},
 		{
 			name:                "test 1 label",
 			labels:              []string{"test-peer"},
@@ -0,0 +1,387 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"crypto"
+	"encoding/base32"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	// dnssecInceptionSkew backdates the inception of signatures to cope with
+	// resolvers whose clocks are behind ours.
+	dnssecInceptionSkew = time.Hour
+
+	// dnssecValidity is how long generated signatures stay valid. Answers are
+	// signed online so this only needs to cover caching and clock skew.
+	dnssecValidity = 24 * time.Hour
+
+	// typeNXNAME is the pseudo-type from RFC 9824 used in compact denial of
+	// existence responses to mark a name that does not exist.
+	typeNXNAME = 128
+)
+
+// dnssecTypes are the record types that may exist at a name in the Consul
+// domain. They are listed in the type bitmap of synthesized NSEC and NSEC3
+// records so that a denial never covers records we could serve.
+var dnssecTypes = []uint16{dns.TypeA, dns.TypeAAAA, dns.TypeSRV, dns.TypeTXT}
+
+// dnssecApexTypes are the record types that exist at the zone apex.
+var dnssecApexTypes = []uint16{dns.TypeNS, dns.TypeSOA, dns.TypeDNSKEY}
+
+// dnssecKey is a zone key loaded from disk.
+type dnssecKey struct {
+	dnskey *dns.DNSKEY
+	signer crypto.Signer
+	tag    uint16
+}
+
+// dnssecConfig holds the keys used to sign responses. It is part of the
+// dnsServerConfig so it is reloaded along with the rest of the DNS config.
+type dnssecConfig struct {
+	// ksks sign the DNSKEY RRset, zsks sign all the other RRsets. A single key
+	// is used as both when the zone does not have both kinds.
+	ksks  []*dnssecKey
+	zsks  []*dnssecKey
+	nsec3 bool
+}
+
+// loadDNSSECConfig reads the zone keys for the given domain. It returns nil if
+// no keys are configured.
+func loadDNSSECConfig(domain string, keyFiles []string, nsec3 bool) (*dnssecConfig, error) {
+	if len(keyFiles) == 0 {
+		return nil, nil
+	}
+
+	cfg := &dnssecConfig{nsec3: nsec3}
+	for _, file := range keyFiles {
+		key, err := loadDNSSECKey(file)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(key.dnskey.Hdr.Name, domain) {
+			return nil, fmt.Errorf("DNSSEC key %q is for zone %q, not %q", file, key.dnskey.Hdr.Name, domain)
+		}
+		if key.dnskey.Flags&dns.SEP != 0 {
+			cfg.ksks = append(cfg.ksks, key)
+		} else {
+			cfg.zsks = append(cfg.zsks, key)
+		}
+	}
+	if len(cfg.ksks) == 0 {
+		cfg.ksks = cfg.zsks
+	}
+	if len(cfg.zsks) == 0 {
+		cfg.zsks = cfg.ksks
+	}
+	return cfg, nil
+}
+
+// loadDNSSECKey reads a key pair written in the BIND format, as generated by
+// dnssec-keygen or ldns-keygen.
+func loadDNSSECKey(file string) (*dnssecKey, error) {
+	base := strings.TrimSuffix(file, ".key")
+
+	pub, err := os.Open(base + ".key")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read DNSSEC key: %w", err)
+	}
+	defer pub.Close()
+	rr, err := dns.ReadRR(pub, base+".key")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse DNSSEC key %q: %w", base+".key", err)
+	}
+	dnskey, ok := rr.(*dns.DNSKEY)
+	if !ok {
+		return nil, fmt.Errorf("Failed to parse DNSSEC key %q: not a DNSKEY record", base+".key")
+	}
+	if dnskey.Flags&dns.ZONE == 0 {
+		return nil, fmt.Errorf("DNSSEC key %q is not a zone key", base+".key")
+	}
+
+	priv, err := os.Open(base + ".private")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read DNSSEC private key: %w", err)
+	}
+	defer priv.Close()
+	pk, err := dnskey.ReadPrivateKey(priv, base+".private")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse DNSSEC private key %q: %w", base+".private", err)
+	}
+	signer, ok := pk.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported DNSSEC private key %q", base+".private")
+	}
+
+	return &dnssecKey{dnskey: dnskey, signer: signer, tag: dnskey.KeyTag()}, nil
+}
+
+// dnskeys returns the DNSKEY RRset of the given zone. The same keys are used
+// for the domain and the alt domain, only the owner name differs.
+func (c *dnssecConfig) dnskeys(zone string, ttl uint32) []dns.RR {
+	var rrs []dns.RR
+	seen := make(map[uint16]bool)
+	for _, key := range slices.Concat(c.ksks, c.zsks) {
+		if seen[key.tag] {
+			continue
+		}
+		seen[key.tag] = true
+		dnskey := *key.dnskey
+		dnskey.Hdr.Name = zone
+		dnskey.Hdr.Ttl = ttl
+		rrs = append(rrs, &dnskey)
+	}
+	return rrs
+}
+
+// wantsDNSSEC returns whether the request asked for DNSSEC records by setting
+// the DO bit.
+func wantsDNSSEC(req *dns.Msg) bool {
+	edns := req.IsEdns0()
+	return edns != nil && edns.Do()
+}
+
+// signResponse adds the DNSSEC records to a response for the given zone: the
+// proof of non-existence for negative answers, and the signatures of all the
+// RRsets that belong to the zone. It is a no-op when signing is disabled or
+// the client did not set the DO bit.
+func (d *DNSServer) signResponse(cfg *dnsRequestConfig, zone string, req, resp *dns.Msg) {
+	if cfg.DNSSEC == nil || !wantsDNSSEC(req) {
+		return
+	}
+	if opt := resp.IsEdns0(); opt != nil {
+		opt.SetDo()
+	}
+
+	if len(resp.Answer) == 0 && (resp.Rcode == dns.RcodeSuccess || resp.Rcode == dns.RcodeNameError) {
+		d.addDenialOfExistence(cfg, zone, resp)
+	}
+
+	now := time.Now()
+	for _, section := range []*[]dns.RR{&resp.Answer, &resp.Ns, &resp.Extra} {
+		sigs, err := cfg.DNSSEC.sign(zone, *section, now)
+		if err != nil {
+			d.logger.Error("failed to sign DNS response", "error", err)
+			resp.Rcode = dns.RcodeServerFailure
+			return
+		}
+		*section = append(*section, sigs...)
+	}
+}
+
+// sign returns the RRSIG records covering the RRsets in rrs. Records that are
+// not part of the zone, such as answers from recursors, are left unsigned.
+func (c *dnssecConfig) sign(zone string, rrs []dns.RR, now time.Time) ([]dns.RR, error) {
+	type rrsetKey struct {
+		name  string
+		rtype uint16
+	}
+
+	var order []rrsetKey
+	rrsets := make(map[rrsetKey][]dns.RR)
+	for _, rr := range rrs {
+		hdr := rr.Header()
+		switch hdr.Rrtype {
+		case dns.TypeOPT, dns.TypeRRSIG:
+			continue
+		}
+		if !dns.IsSubDomain(zone, hdr.Name) {
+			continue
+		}
+		key := rrsetKey{name: strings.ToLower(hdr.Name), rtype: hdr.Rrtype}
+		if _, ok := rrsets[key]; !ok {
+			order = append(order, key)
+		}
+		rrsets[key] = append(rrsets[key], rr)
+	}
+
+	var sigs []dns.RR
+	for _, key := range order {
+		rrset := rrsets[key]
+
+		// All the records of an RRset must share the same TTL, use the
+		// lowest one as required by RFC 2181.
+		ttl := rrset[0].Header().Ttl
+		for _, rr := range rrset[1:] {
+			ttl = min(ttl, rr.Header().Ttl)
+		}
+		for _, rr := range rrset {
+			rr.Header().Ttl = ttl
+		}
+
+		keys := c.zsks
+		if key.rtype == dns.TypeDNSKEY {
+			keys = c.ksks
+		}
+		for _, k := range keys {
+			sig := &dns.RRSIG{
+				Hdr:        dns.RR_Header{Ttl: ttl},
+				OrigTtl:    ttl,
+				Algorithm:  k.dnskey.Algorithm,
+				KeyTag:     k.tag,
+				SignerName: zone,
+				Inception:  uint32(now.Add(-dnssecInceptionSkew).Unix()),
+				Expiration: uint32(now.Add(dnssecValidity).Unix()),
+			}
+			if err := sig.Sign(k.signer, rrset); err != nil {
+				return nil, fmt.Errorf("failed to sign %s %s: %w", key.name, dns.Type(key.rtype), err)
+			}
+			sigs = append(sigs, sig)
+		}
+	}
+	return sigs, nil
+}
+
+// addDenialOfExistence adds the NSEC or NSEC3 records proving that the
+// question name or type does not exist to a negative response. Since answers
+// are built on the fly there is no zone to walk, so the records are
+// synthesized around the question name and only ever deny that name.
+//
+// With NSEC, the compact denial of existence from RFC 9824 is used: a
+// non-existent name is answered with NOERROR and an NSEC record listing only
+// the NXNAME pseudo-type. With NSEC3, NXDOMAIN is preserved using the
+// minimally covering records described in RFC 7129.
+func (d *DNSServer) addDenialOfExistence(cfg *dnsRequestConfig, zone string, resp *dns.Msg) {
+	if len(resp.Question) == 0 || !dns.IsSubDomain(zone, resp.Question[0].Name) {
+		return
+	}
+	q := resp.Question[0]
+	name := strings.ToLower(dns.Fqdn(q.Name))
+	ttl := cfg.SOAConfig.Minttl
+	nxdomain := resp.Rcode == dns.RcodeNameError
+
+	// The apex always exists, whatever dispatch made of it.
+	if dns.CountLabel(name) <= dns.CountLabel(zone) {
+		nxdomain = false
+		resp.Rcode = dns.RcodeSuccess
+	}
+
+	if !cfg.DNSSEC.nsec3 {
+		types := []uint16{typeNXNAME}
+		if !nxdomain {
+			types = existingTypes(name, zone, q.Qtype)
+		}
+		resp.Rcode = dns.RcodeSuccess
+		resp.Ns = append(resp.Ns, &dns.NSEC{
+			Hdr:        dns.RR_Header{Name: name, Rrtype: dns.TypeNSEC, Class: dns.ClassINET, Ttl: ttl},
+			NextDomain: `\000.` + name,
+			TypeBitMap: sortedTypes(append(types, dns.TypeNSEC, dns.TypeRRSIG)),
+		})
+		return
+	}
+
+	if !nxdomain {
+		resp.Ns = append(resp.Ns, matchingNSEC3(name, zone, ttl, existingTypes(name, zone, q.Qtype)))
+		return
+	}
+
+	// The closest encloser is assumed to be the parent of the question name,
+	// so the next closer name is the question name itself.
+	encloser := strings.SplitN(name, ".", 2)[1]
+	var encloserTypes []uint16
+	if dns.CountLabel(encloser) > dns.CountLabel(zone) {
+		encloserTypes = existingTypes(encloser, zone, 0)
+	} else {
+		encloserTypes = existingTypes(zone, zone, 0)
+	}
+	nsec3s := []dns.RR{
+		matchingNSEC3(encloser, zone, ttl, encloserTypes),
+		coveringNSEC3(name, zone, ttl),
+		coveringNSEC3("*."+encloser, zone, ttl),
+	}
+	seen := make(map[string]bool)
+	for _, rr := range nsec3s {
+		if owner := rr.Header().Name; !seen[owner] {
+			seen[owner] = true
+			resp.Ns = append(resp.Ns, rr)
+		}
+	}
+}
+
+// existingTypes returns the record types listed in the bitmap of a record
+// proving that qtype does not exist at name.
+func existingTypes(name, zone string, qtype uint16) []uint16 {
+	types := dnssecTypes
+	if strings.EqualFold(name, zone) {
+		types = slices.Concat(dnssecTypes, dnssecApexTypes)
+	}
+	return slices.DeleteFunc(slices.Clone(types), func(t uint16) bool { return t == qtype })
+}
+
+func sortedTypes(types []uint16) []uint16 {
+	slices.Sort(types)
+	return slices.Compact(types)
+}
+
+// NSEC3 records are generated with the parameters recommended by RFC 9276: no
+// salt and no additional iterations.
+const (
+	nsec3Iterations = 0
+	nsec3Salt       = ""
+)
+
+var nsec3Encoding = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+func nsec3Hash(name string) []byte {
+	b, _ := nsec3Encoding.DecodeString(dns.HashName(name, dns.SHA1, nsec3Iterations, nsec3Salt))
+	return b
+}
+
+func newNSEC3(hash, next []byte, zone string, ttl uint32, types []uint16) *dns.NSEC3 {
+	return &dns.NSEC3{
+		Hdr: dns.RR_Header{
+			Name:   strings.ToLower(nsec3Encoding.EncodeToString(hash)) + "." + zone,
+			Rrtype: dns.TypeNSEC3,
+			Class:  dns.ClassINET,
+			Ttl:    ttl,
+		},
+		Hash:       dns.SHA1,
+		Iterations: nsec3Iterations,
+		SaltLength: uint8(len(nsec3Salt) / 2),
+		Salt:       nsec3Salt,
+		HashLength: uint8(len(next)),
+		NextDomain: nsec3Encoding.EncodeToString(next),
+		TypeBitMap: sortedTypes(types),
+	}
+}
+
+// matchingNSEC3 returns an NSEC3 record proving that name exists with the
+// given types only.
+func matchingNSEC3(name, zone string, ttl uint32, types []uint16) *dns.NSEC3 {
+	hash := nsec3Hash(name)
+	return newNSEC3(hash, nsec3Add(hash, 1), zone, ttl, append(types, dns.TypeRRSIG))
+}
+
+// coveringNSEC3 returns an NSEC3 record proving that name does not exist. It
+// covers the smallest possible range around the hash of name.
+func coveringNSEC3(name, zone string, ttl uint32) *dns.NSEC3 {
+	hash := nsec3Hash(name)
+	return newNSEC3(nsec3Add(hash, -1), nsec3Add(hash, 1), zone, ttl, nil)
+}
+
+// nsec3Add adds delta to the hash, interpreted as a big endian number.
+func nsec3Add(hash []byte, delta int) []byte {
+	out := slices.Clone(hash)
+	for i := len(out) - 1; i >= 0; i-- {
+		if delta > 0 {
+			out[i]++
+			if out[i] != 0 {
+				break
+			}
+		} else {
+			out[i]--
+			if out[i] != 0xff {
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,244 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+// writeDNSSECKey generates a zone key and writes it in the BIND format,
+// returning the path of the key without extension.
+func writeDNSSECKey(t *testing.T, dir, zone string, flags uint16) (string, *dns.DNSKEY) {
+	t.Helper()
+
+	key := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: zone, Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     flags,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+	priv, err := key.Generate(256)
+	require.NoError(t, err)
+
+	base := filepath.Join(dir, fmt.Sprintf("K%s+%03d+%05d", zone, key.Algorithm, key.KeyTag()))
+	require.NoError(t, os.WriteFile(base+".key", []byte(key.String()+"\n"), 0600))
+	require.NoError(t, os.WriteFile(base+".private", []byte(key.PrivateKeyString(priv)), 0600))
+	return base, key
+}
+
+// dnssecQuery sends a query with the DO bit set.
+func dnssecQuery(t *testing.T, a *TestAgent, name string, qtype uint16) *dns.Msg {
+	t.Helper()
+
+	m := new(dns.Msg)
+	m.SetQuestion(name, qtype)
+	m.SetEdns0(4096, true)
+
+	c := &dns.Client{Net: "tcp"}
+	in, _, err := c.Exchange(m, a.DNSAddr())
+	require.NoError(t, err)
+	return in
+}
+
+// requireSigned checks that every RRset in rrs is covered by a valid
+// signature made with key.
+func requireSigned(t *testing.T, rrs []dns.RR, key *dns.DNSKEY) {
+	t.Helper()
+
+	rrsets := make(map[string][]dns.RR)
+	sigs := make(map[string]*dns.RRSIG)
+	for _, rr := range rrs {
+		switch rr := rr.(type) {
+		case *dns.OPT:
+		case *dns.RRSIG:
+			sigs[strings.ToLower(rr.Hdr.Name)+dns.Type(rr.TypeCovered).String()] = rr
+		default:
+			k := strings.ToLower(rr.Header().Name) + dns.Type(rr.Header().Rrtype).String()
+			rrsets[k] = append(rrsets[k], rr)
+		}
+	}
+	require.NotEmpty(t, rrsets)
+	for k, rrset := range rrsets {
+		sig, ok := sigs[k]
+		require.True(t, ok, "RRset %s is not signed", k)
+		require.Equal(t, key.KeyTag(), sig.KeyTag)
+		require.NoError(t, sig.Verify(key, rrset), "RRset %s", k)
+		require.True(t, sig.ValidityPeriod(time.Now()))
+	}
+}
+
+func TestDNS_DNSSEC(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	dir := t.TempDir()
+	file, key := writeDNSSECKey(t, dir, "consul.", dns.ZONE|dns.SEP)
+
+	a := NewTestAgent(t, fmt.Sprintf(`dns_config { dnssec { key_files = [%q] } }`, file+".key"))
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	args := &structs.RegisterRequest{
+		Datacenter: "dc1",
+		Node:       "foo",
+		Address:    "127.0.0.1",
+		Service: &structs.NodeService{
+			Service: "db",
+			Port:    12345,
+		},
+	}
+	var out struct{}
+	require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+
+	t.Run("DNSKEY", func(t *testing.T) {
+		in := dnssecQuery(t, a, "consul.", dns.TypeDNSKEY)
+		require.Equal(t, dns.RcodeSuccess, in.Rcode)
+		require.Len(t, in.Answer, 2)
+		dnskey, ok := in.Answer[0].(*dns.DNSKEY)
+		require.True(t, ok, "answer is not a DNSKEY record")
+		require.Equal(t, key.PublicKey, dnskey.PublicKey)
+		requireSigned(t, in.Answer, key)
+	})
+
+	t.Run("service", func(t *testing.T) {
+		in := dnssecQuery(t, a, "db.service.consul.", dns.TypeSRV)
+		require.Equal(t, dns.RcodeSuccess, in.Rcode)
+		require.True(t, in.IsEdns0().Do())
+		requireSigned(t, in.Answer, key)
+		requireSigned(t, in.Extra, key)
+	})
+
+	t.Run("node", func(t *testing.T) {
+		in := dnssecQuery(t, a, "foo.node.consul.", dns.TypeA)
+		require.Equal(t, dns.RcodeSuccess, in.Rcode)
+		requireSigned(t, in.Answer, key)
+	})
+
+	t.Run("without DO bit", func(t *testing.T) {
+		m := new(dns.Msg)
+		m.SetQuestion("db.service.consul.", dns.TypeSRV)
+		in, _, err := new(dns.Client).Exchange(m, a.DNSAddr())
+		require.NoError(t, err)
+		for _, rr := range append(in.Answer, in.Extra...) {
+			require.NotEqual(t, dns.TypeRRSIG, rr.Header().Rrtype)
+		}
+	})
+
+	t.Run("NXDOMAIN", func(t *testing.T) {
+		// Compact denial of existence answers with NOERROR.
+		in := dnssecQuery(t, a, "nope.node.consul.", dns.TypeA)
+		require.Equal(t, dns.RcodeSuccess, in.Rcode)
+		require.Empty(t, in.Answer)
+		requireSigned(t, in.Ns, key)
+
+		var nsec *dns.NSEC
+		for _, rr := range in.Ns {
+			if rr, ok := rr.(*dns.NSEC); ok {
+				nsec = rr
+			}
+		}
+		require.NotNil(t, nsec)
+		require.Equal(t, "nope.node.consul.", nsec.Hdr.Name)
+		require.Equal(t, []uint16{dns.TypeRRSIG, dns.TypeNSEC, typeNXNAME}, nsec.TypeBitMap)
+	})
+
+	t.Run("NODATA", func(t *testing.T) {
+		in := dnssecQuery(t, a, "foo.node.consul.", dns.TypeMX)
+		require.Equal(t, dns.RcodeSuccess, in.Rcode)
+		require.Empty(t, in.Answer)
+		requireSigned(t, in.Ns, key)
+
+		var nsec *dns.NSEC
+		for _, rr := range in.Ns {
+			if rr, ok := rr.(*dns.NSEC); ok {
+				nsec = rr
+			}
+		}
+		require.NotNil(t, nsec)
+		require.Contains(t, nsec.TypeBitMap, dns.TypeA)
+		require.NotContains(t, nsec.TypeBitMap, dns.TypeMX)
+	})
+}
+
+func TestDNS_DNSSEC_NSEC3(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	dir := t.TempDir()
+	file, key := writeDNSSECKey(t, dir, "consul.", dns.ZONE)
+
+	a := NewTestAgent(t, fmt.Sprintf(`dns_config { dnssec { key_files = [%q] nsec3 = true } }`, file))
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	in := dnssecQuery(t, a, "nope.node.consul.", dns.TypeA)
+	require.Equal(t, dns.RcodeNameError, in.Rcode)
+	requireSigned(t, in.Ns, key)
+
+	var encloser, nextCloser, wildcard bool
+	for _, rr := range in.Ns {
+		nsec3, ok := rr.(*dns.NSEC3)
+		if !ok {
+			continue
+		}
+		encloser = encloser || nsec3.Match("node.consul.")
+		nextCloser = nextCloser || nsec3.Cover("nope.node.consul.")
+		wildcard = wildcard || nsec3.Cover("*.node.consul.")
+		require.False(t, nsec3.Match("nope.node.consul."))
+	}
+	require.True(t, encloser, "missing NSEC3 matching the closest encloser")
+	require.True(t, nextCloser, "missing NSEC3 covering the next closer name")
+	require.True(t, wildcard, "missing NSEC3 covering the wildcard")
+}
+
+func TestDNS_DNSSEC_ReloadConfig(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	dir := t.TempDir()
+	file, key := writeDNSSECKey(t, dir, "consul.", dns.ZONE|dns.SEP)
+
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	// Signing is disabled by default.
+	in := dnssecQuery(t, a, "consul.", dns.TypeDNSKEY)
+	require.Empty(t, in.Answer)
+
+	newCfg := *a.Config
+	newCfg.DNSSECKeyFiles = []string{file}
+	require.NoError(t, a.reloadConfigInternal(&newCfg))
+
+	in = dnssecQuery(t, a, "consul.", dns.TypeDNSKEY)
+	require.Len(t, in.Answer, 2)
+	requireSigned(t, in.Answer, key)
+
+	// A key for another zone is rejected and the current keys are kept.
+	other, _ := writeDNSSECKey(t, dir, "example.com.", dns.ZONE)
+	newCfg.DNSSECKeyFiles = []string{other}
+	err := a.reloadConfigInternal(&newCfg)
+	require.ErrorContains(t, err, `is for zone "example.com.", not "consul."`)
+
+	in = dnssecQuery(t, a, "consul.", dns.TypeDNSKEY)
+	requireSigned(t, in.Answer, key)
+}
@@ -0,0 +1,145 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+
+	"github.com/miekg/dns"
+
+	agentdns "github.com/hashicorp/consul/agent/dns"
+	"github.com/hashicorp/consul/logging"
+)
+
+const (
+	// dnsMessageContentType is the media type of DNS over HTTPS requests
+	// and responses, see RFC 8484.
+	dnsMessageContentType = "application/dns-message"
+
+	// dnsOverHTTPSPath is the well known path of the DNS over HTTPS endpoint.
+	dnsOverHTTPSPath = "/dns-query"
+)
+
+// DNSQuery answers DNS over HTTPS queries as described in RFC 8484. Queries
+// are served by the same handlers as the DNS listeners, so they resolve names
+// in the Consul domain and are forwarded to the recursors otherwise. The ACL
+// token of the HTTP request is used for the lookups, the agent DNS token is
+// used when none is given.
+func (s *HTTPHandlers) DNSQuery(resp http.ResponseWriter, req *http.Request) {
+	// Plaintext DNS is already available on the DNS port, this endpoint only
+	// exists to provide an encrypted transport.
+	if req.TLS == nil {
+		http.Error(resp, "DNS queries are only accepted over HTTPS", http.StatusNotFound)
+		return
+	}
+
+	srv := s.agent.dnsQueryServer
+	if srv == nil {
+		http.Error(resp, "DNS server is not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	var raw []byte
+	switch req.Method {
+	case http.MethodGet:
+		q := req.URL.Query().Get("dns")
+		if q == "" {
+			http.Error(resp, "Missing dns query parameter", http.StatusBadRequest)
+			return
+		}
+		b, err := base64.RawURLEncoding.DecodeString(q)
+		if err != nil {
+			http.Error(resp, fmt.Sprintf("Invalid dns query parameter: %v", err), http.StatusBadRequest)
+			return
+		}
+		raw = b
+
+	case http.MethodPost:
+		if ct := req.Header.Get(contentTypeHeader); ct != dnsMessageContentType {
+			http.Error(resp, fmt.Sprintf("Unsupported content type %q, must be %q", ct, dnsMessageContentType), http.StatusUnsupportedMediaType)
+			return
+		}
+		b, err := io.ReadAll(io.LimitReader(req.Body, dns.MaxMsgSize+1))
+		if err != nil {
+			http.Error(resp, fmt.Sprintf("Failed to read request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if len(b) > dns.MaxMsgSize {
+			http.Error(resp, "DNS message is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		raw = b
+
+	default:
+		resp.Header().Set("Allow", "GET, POST")
+		http.Error(resp, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	msg := new(dns.Msg)
+	if err := msg.Unpack(raw); err != nil {
+		http.Error(resp, fmt.Sprintf("Invalid DNS message: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(msg.Question) == 0 {
+		http.Error(resp, "DNS message has no question", http.StatusBadRequest)
+		return
+	}
+
+	var token string
+	s.parseToken(req, &token)
+
+	// The query is answered as if it was received over TCP so that it is
+	// never trimmed to the size of a UDP datagram.
+	w := &agentdns.BufferResponseWriter{
+		LocalAddress:   &net.TCPAddr{},
+		RemoteAddress:  &net.TCPAddr{},
+		Logger:         s.agent.logger.Named(logging.DNS),
+		RequestContext: agentdns.Context{Token: token},
+	}
+	if addr, ok := req.Context().Value(http.LocalAddrContextKey).(*net.TCPAddr); ok {
+		w.LocalAddress = addr
+	}
+	if addrPort, err := netip.ParseAddrPort(req.RemoteAddr); err == nil {
+		w.RemoteAddress = net.TCPAddrFromAddrPort(addrPort)
+	}
+	srv.mux.ServeDNS(w, msg)
+
+	out := w.ResponseBuffer()
+	if len(out) == 0 {
+		http.Error(resp, "Failed to answer DNS query", http.StatusInternalServerError)
+		return
+	}
+
+	resp.Header().Set(contentTypeHeader, dnsMessageContentType)
+	reply := new(dns.Msg)
+	if err := reply.Unpack(out); err == nil {
+		resp.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", dnsResponseMaxAge(reply)))
+	}
+	resp.Write(out)
+}
+
+// dnsResponseMaxAge returns how long a DNS over HTTPS response can be cached,
+// which is the smallest TTL of the records it contains.
+func dnsResponseMaxAge(msg *dns.Msg) uint32 {
+	var maxAge uint32
+	first := true
+	for _, section := range [][]dns.RR{msg.Answer, msg.Ns, msg.Extra} {
+		for _, rr := range section {
+			if rr.Header().Rrtype == dns.TypeOPT {
+				continue
+			}
+			if first || rr.Header().Ttl < maxAge {
+				maxAge = rr.Header().Ttl
+				first = false
+			}
+		}
+	}
+	return maxAge
+}
@@ -0,0 +1,240 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/sdk/freeport"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestDNS_OverHTTPS(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	args := &structs.RegisterRequest{
+		Datacenter: "dc1",
+		Node:       "foo",
+		Address:    "127.0.0.1",
+	}
+	var out struct{}
+	require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+
+	m := new(dns.Msg)
+	m.SetQuestion("foo.node.consul.", dns.TypeA)
+	m.Id = 0
+	query, err := m.Pack()
+	require.NoError(t, err)
+
+	requireAnswer := func(t *testing.T, resp *httptest.ResponseRecorder) {
+		t.Helper()
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		// The headers as sent, later changes made by the middlewares have
+		// no effect.
+		header := resp.Result().Header
+		require.Equal(t, dnsMessageContentType, header.Get("Content-Type"))
+		require.Equal(t, "max-age=0", header.Get("Cache-Control"))
+
+		in := new(dns.Msg)
+		require.NoError(t, in.Unpack(resp.Body.Bytes()))
+		require.Len(t, in.Answer, 1)
+		aRec, ok := in.Answer[0].(*dns.A)
+		require.True(t, ok, "answer is not an A record")
+		require.Equal(t, "127.0.0.1", aRec.A.String())
+	}
+
+	t.Run("GET", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/dns-query?dns="+base64.RawURLEncoding.EncodeToString(query), nil)
+		req.TLS = &tls.ConnectionState{}
+		resp := httptest.NewRecorder()
+		a.srv.handler().ServeHTTP(resp, req)
+		requireAnswer(t, resp)
+	})
+
+	t.Run("POST", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/dns-query", bytes.NewReader(query))
+		req.Header.Set("Content-Type", dnsMessageContentType)
+		req.TLS = &tls.ConnectionState{}
+		resp := httptest.NewRecorder()
+		a.srv.handler().ServeHTTP(resp, req)
+		requireAnswer(t, resp)
+	})
+
+	cases := map[string]struct {
+		method      string
+		url         string
+		contentType string
+		body        []byte
+		plaintext   bool
+		code        int
+	}{
+		"plaintext": {
+			method:    "GET",
+			url:       "/dns-query?dns=" + base64.RawURLEncoding.EncodeToString(query),
+			plaintext: true,
+			code:      http.StatusNotFound,
+		},
+		"missing query": {
+			method: "GET",
+			url:    "/dns-query",
+			code:   http.StatusBadRequest,
+		},
+		"bad encoding": {
+			method: "GET",
+			url:    "/dns-query?dns=!!!",
+			code:   http.StatusBadRequest,
+		},
+		"bad message": {
+			method: "GET",
+			url:    "/dns-query?dns=AAAA",
+			code:   http.StatusBadRequest,
+		},
+		"bad content type": {
+			method:      "POST",
+			url:         "/dns-query",
+			contentType: "application/json",
+			body:        query,
+			code:        http.StatusUnsupportedMediaType,
+		},
+		"bad method": {
+			method: "PUT",
+			url:    "/dns-query",
+			code:   http.StatusMethodNotAllowed,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, bytes.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			if !tc.plaintext {
+				req.TLS = &tls.ConnectionState{}
+			}
+			resp := httptest.NewRecorder()
+			a.srv.handler().ServeHTTP(resp, req)
+			require.Equal(t, tc.code, resp.Code, resp.Body.String())
+		})
+	}
+}
+
+func TestDNS_OverHTTPS_ACLToken(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		primary_datacenter = "dc1"
+		acl {
+			enabled = true
+			default_policy = "deny"
+			tokens {
+				initial_management = "root"
+			}
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	args := &structs.RegisterRequest{
+		Datacenter:   "dc1",
+		Node:         "foo",
+		Address:      "127.0.0.1",
+		WriteRequest: structs.WriteRequest{Token: "root"},
+	}
+	var out struct{}
+	require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+
+	m := new(dns.Msg)
+	m.SetQuestion("foo.node.consul.", dns.TypeA)
+	query, err := m.Pack()
+	require.NoError(t, err)
+
+	lookup := func(token string) *dns.Msg {
+		req := httptest.NewRequest("POST", "/dns-query", bytes.NewReader(query))
+		req.Header.Set("Content-Type", dnsMessageContentType)
+		if token != "" {
+			req.Header.Set("X-Consul-Token", token)
+		}
+		req.TLS = &tls.ConnectionState{}
+		resp := httptest.NewRecorder()
+		a.srv.handler().ServeHTTP(resp, req)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		in := new(dns.Msg)
+		require.NoError(t, in.Unpack(resp.Body.Bytes()))
+		return in
+	}
+
+	// The anonymous token cannot read the node.
+	in := lookup("")
+	require.Empty(t, in.Answer)
+
+	in = lookup("root")
+	require.Len(t, in.Answer, 1)
+}
+
+func TestDNS_OverTLS(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	port := freeport.GetOne(t)
+	a := StartTestAgent(t, TestAgent{
+		HCL: fmt.Sprintf(`
+			ports {
+				dns_tls = %d
+			}
+			tls {
+				defaults {
+					ca_file = "../test/client_certs/rootca.crt"
+					cert_file = "../test/client_certs/server.crt"
+					key_file = "../test/client_certs/server.key"
+				}
+			}
+		`, port),
+	})
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	require.Len(t, a.Config.DNSTLSAddrs, 1)
+	addr := a.Config.DNSTLSAddrs[0].String()
+
+	m := new(dns.Msg)
+	m.SetQuestion(a.Config.NodeName+".node.consul.", dns.TypeA)
+
+	c := &dns.Client{
+		Net:       "tcp-tls",
+		TLSConfig: &tls.Config{InsecureSkipVerify: true, NextProtos: []string{"dot"}},
+	}
+	in, _, err := c.Exchange(m, addr)
+	require.NoError(t, err)
+	require.Len(t, in.Answer, 1)
+
+	// Plaintext queries are not accepted on the TLS port.
+	c = &dns.Client{Net: "tcp", Timeout: time.Second}
+	_, _, err = c.Exchange(m, addr)
+	require.Error(t, err)
+}
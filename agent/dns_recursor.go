@@ -0,0 +1,379 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-metrics"
+	"github.com/hashicorp/go-metrics/prometheus"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/miekg/dns"
+)
+
+const (
+	// recursorMaxFailures is the number of consecutive failures after which a
+	// recursor is considered unhealthy and moved to the end of the list.
+	recursorMaxFailures = 3
+
+	// recursorBackoff is how long an unhealthy recursor is only used as a
+	// last resort before being tried again.
+	recursorBackoff = 30 * time.Second
+
+	// recursorPrefetchHits is the number of times a cached response must have
+	// been served before it is worth prefetching.
+	recursorPrefetchHits = 3
+
+	// recursorPrefetchRatio is the fraction of the TTL remaining under which
+	// a hot cached response is prefetched.
+	recursorPrefetchRatio = 10
+)
+
+var DNSRecursorCounters = []prometheus.CounterDefinition{
+	{
+		Name: []string{"dns", "recursor", "cache_hit"},
+		Help: "Increments when a query forwarded to the DNS recursors is answered from the cache.",
+	},
+	{
+		Name: []string{"dns", "recursor", "cache_miss"},
+		Help: "Increments when a query forwarded to the DNS recursors is not found in the cache.",
+	},
+	{
+		Name: []string{"dns", "recursor", "prefetch"},
+		Help: "Increments when a cached response from the DNS recursors is refreshed before it expires.",
+	},
+	{
+		Name: []string{"dns", "recursor", "failure"},
+		Help: "Increments when a DNS recursor fails to answer a query.",
+	},
+}
+
+var DNSRecursorSummaries = []prometheus.SummaryDefinition{
+	{
+		Name: []string{"dns", "recursor", "rtt"},
+		Help: "Measures the time taken by a DNS recursor to answer a query.",
+	},
+}
+
+var errAllRecursorsFailed = errors.New("all recursors failed")
+
+// dnsRecursor forwards the queries outside of the Consul domain to the
+// configured recursors. It keeps track of the health of each recursor so that
+// failing ones are tried last, and caches the responses when enabled. It is
+// shared by all the DNS servers of the agent.
+type dnsRecursor struct {
+	logger hclog.Logger
+
+	// exchangeFn sends a query to a recursor, it is replaced in tests.
+	exchangeFn func(c *dns.Client, req *dns.Msg, addr string) (*dns.Msg, time.Duration, error)
+
+	lock   sync.Mutex
+	cache  *lru.Cache
+	size   int
+	health map[string]*recursorHealth
+}
+
+type recursorHealth struct {
+	failures       int
+	unhealthyUntil time.Time
+}
+
+// recursorCacheEntry is a response stored in the cache.
+type recursorCacheEntry struct {
+	msg        *dns.Msg
+	stored     time.Time
+	expires    time.Time
+	hits       int
+	prefetched bool
+}
+
+func newDNSRecursor(logger hclog.Logger) *dnsRecursor {
+	return &dnsRecursor{
+		logger: logger,
+		exchangeFn: func(c *dns.Client, req *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+			return c.Exchange(req, addr)
+		},
+		health: make(map[string]*recursorHealth),
+	}
+}
+
+// recursorCacheKey identifies the responses that can be used to answer req.
+// The DO and CD bits are part of the key since they change the content of the
+// response, and so is the EDNS client subnet since the recursor may tailor the
+// response to it.
+func recursorCacheKey(req *dns.Msg) string {
+	q := req.Question[0]
+	var b strings.Builder
+	b.WriteString(strings.ToLower(q.Name))
+	b.WriteByte('/')
+	b.WriteString(dns.Type(q.Qtype).String())
+	b.WriteByte('/')
+	b.WriteString(dns.Class(q.Qclass).String())
+	if edns := req.IsEdns0(); edns != nil {
+		if edns.Do() {
+			b.WriteString("/do")
+		}
+		for _, opt := range edns.Option {
+			if subnet, ok := opt.(*dns.EDNS0_SUBNET); ok {
+				// Only the leading SourceNetmask bits of the address are
+				// significant.
+				addr, bits := subnet.Address.To16(), 128
+				if subnet.Family == 1 {
+					addr, bits = subnet.Address.To4(), 32
+				}
+				if mask := net.CIDRMask(int(subnet.SourceNetmask), bits); addr != nil && mask != nil {
+					addr = addr.Mask(mask)
+				}
+				fmt.Fprintf(&b, "/ecs=%d/%s/%d", subnet.Family, addr, subnet.SourceNetmask)
+			}
+		}
+	}
+	if req.CheckingDisabled {
+		b.WriteString("/cd")
+	}
+	return b.String()
+}
+
+// exchange resolves req using the recursors, or the cache when it holds a
+// response. It returns errAllRecursorsFailed when none of the recursors
+// answered.
+func (r *dnsRecursor) exchange(cfg *dnsRequestConfig, req *dns.Msg, network string) (*dns.Msg, error) {
+	if cfg.RecursorCache.Size <= 0 || len(req.Question) != 1 {
+		return r.forward(cfg, req, network)
+	}
+
+	key := recursorCacheKey(req)
+	if msg, prefetch := r.lookup(cfg, key, req); msg != nil {
+		metrics.IncrCounter([]string{"dns", "recursor", "cache_hit"}, 1)
+		if prefetch {
+			metrics.IncrCounter([]string{"dns", "recursor", "prefetch"}, 1)
+			go r.refresh(cfg, key, req.Copy())
+		}
+		return msg, nil
+	}
+	metrics.IncrCounter([]string{"dns", "recursor", "cache_miss"}, 1)
+
+	resp, err := r.forward(cfg, req, network)
+	if err != nil {
+		return nil, err
+	}
+	r.store(cfg, key, resp)
+	return resp, nil
+}
+
+// refresh fetches a fresh copy of a hot cached response.
+func (r *dnsRecursor) refresh(cfg *dnsRequestConfig, key string, req *dns.Msg) {
+	resp, err := r.forward(cfg, req, "udp")
+	if err == nil && resp.Truncated {
+		resp, err = r.forward(cfg, req, "tcp")
+	}
+	if err != nil {
+		r.logger.Debug("failed to prefetch cached response", "question", req.Question[0], "error", err)
+		return
+	}
+	r.store(cfg, key, resp)
+}
+
+// lookup returns a copy of the cached response for req with its TTLs
+// decremented by the time spent in the cache, or nil if there is none. It
+// also returns whether the response should be prefetched.
+func (r *dnsRecursor) lookup(cfg *dnsRequestConfig, key string, req *dns.Msg) (*dns.Msg, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.resize(cfg.RecursorCache.Size)
+	raw, ok := r.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	entry := raw.(*recursorCacheEntry)
+	now := time.Now()
+	if !now.Before(entry.expires) {
+		r.cache.Remove(key)
+		return nil, false
+	}
+	entry.hits++
+
+	var prefetch bool
+	if cfg.RecursorCache.Prefetch && !entry.prefetched && entry.hits >= recursorPrefetchHits {
+		ttl := entry.expires.Sub(entry.stored)
+		if entry.expires.Sub(now) < ttl/recursorPrefetchRatio {
+			entry.prefetched = true
+			prefetch = true
+		}
+	}
+
+	msg := entry.msg.Copy()
+	msg.Id = req.Id
+	msg.Question = slices.Clone(req.Question)
+	elapsed := uint32(now.Sub(entry.stored) / time.Second)
+	for _, section := range [][]dns.RR{msg.Answer, msg.Ns, msg.Extra} {
+		for _, rr := range section {
+			if hdr := rr.Header(); hdr.Rrtype != dns.TypeOPT {
+				hdr.Ttl -= min(hdr.Ttl, elapsed)
+			}
+		}
+	}
+	return msg, prefetch
+}
+
+// store adds a response to the cache if it can be cached.
+func (r *dnsRecursor) store(cfg *dnsRequestConfig, key string, resp *dns.Msg) {
+	ttl := recursorCacheTTL(cfg, resp)
+	if ttl <= 0 {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.resize(cfg.RecursorCache.Size)
+	now := time.Now()
+	r.cache.Add(key, &recursorCacheEntry{
+		msg:     resp.Copy(),
+		stored:  now,
+		expires: now.Add(ttl),
+	})
+}
+
+// resize creates the cache or adjusts its size after a config reload. It must
+// be called with the lock held.
+func (r *dnsRecursor) resize(size int) {
+	if r.cache == nil {
+		// lru.New only fails for a non-positive size.
+		r.cache, _ = lru.New(size)
+		r.size = size
+	} else if r.size != size {
+		r.cache.Resize(size)
+		r.size = size
+	}
+}
+
+// recursorCacheTTL returns how long resp can be cached. Positive responses
+// are cached for the lowest TTL of their records. Negative responses are
+// cached for the TTL of their SOA record, bounded by its minimum field, as
+// described in RFC 2308. It returns 0 for the responses that must not be
+// cached.
+func recursorCacheTTL(cfg *dnsRequestConfig, resp *dns.Msg) time.Duration {
+	if resp.Truncated || (resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError) {
+		return 0
+	}
+
+	if resp.Rcode == dns.RcodeSuccess && len(resp.Answer) > 0 {
+		var ttl uint32
+		first := true
+		for _, section := range [][]dns.RR{resp.Answer, resp.Ns} {
+			for _, rr := range section {
+				if first || rr.Header().Ttl < ttl {
+					ttl = rr.Header().Ttl
+					first = false
+				}
+			}
+		}
+		return min(time.Duration(ttl)*time.Second, cfg.RecursorCache.MaxTTL)
+	}
+
+	for _, rr := range resp.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			ttl := min(soa.Hdr.Ttl, soa.Minttl)
+			return min(time.Duration(ttl)*time.Second, cfg.RecursorCache.MaxNegativeTTL)
+		}
+	}
+	// Negative responses without an SOA record are not cached.
+	return 0
+}
+
+// forward sends req to the recursors, starting with the healthy ones in the
+// order of the recursor strategy, and returns the first valid response.
+func (r *dnsRecursor) forward(cfg *dnsRequestConfig, req *dns.Msg, network string) (*dns.Msg, error) {
+	q := req.Question[0]
+	c := &dns.Client{Net: network, Timeout: cfg.RecursorTimeout}
+	for _, recursor := range r.order(cfg) {
+		resp, rtt, err := r.exchangeFn(c, req, recursor)
+		labels := []metrics.Label{{Name: "recursor", Value: recursor}}
+		if resp != nil {
+			metrics.AddSampleWithLabels([]string{"dns", "recursor", "rtt"}, float32(rtt.Milliseconds()), labels)
+		}
+		// Check if the response is valid and has the desired Response code
+		if resp != nil && (resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError) {
+			r.logger.Debug("recurse failed for question",
+				"question", q,
+				"rtt", rtt,
+				"recursor", recursor,
+				"rcode", dns.RcodeToString[resp.Rcode],
+			)
+			r.recordFailure(recursor)
+			metrics.IncrCounterWithLabels([]string{"dns", "recursor", "failure"}, 1, labels)
+			// If we still have recursors to forward the query to,
+			// we move forward onto the next one else the loop ends
+			continue
+		} else if err == nil || (resp != nil && resp.Truncated) {
+			r.logger.Debug("recurse succeeded for question",
+				"question", q,
+				"rtt", rtt,
+				"recursor", recursor,
+			)
+			r.recordSuccess(recursor)
+			return resp, nil
+		}
+		r.logger.Error("recurse failed", "error", err)
+		r.recordFailure(recursor)
+		metrics.IncrCounterWithLabels([]string{"dns", "recursor", "failure"}, 1, labels)
+	}
+	return nil, errAllRecursorsFailed
+}
+
+// order returns the recursors in the order they should be tried: the healthy
+// ones first, in the order of the recursor strategy, then the unhealthy ones
+// starting with the one that will recover first.
+func (r *dnsRecursor) order(cfg *dnsRequestConfig) []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	var healthy, unhealthy []string
+	for _, idx := range cfg.RecursorStrategy.Indexes(len(cfg.Recursors)) {
+		recursor := cfg.Recursors[idx]
+		if h, ok := r.health[recursor]; ok && now.Before(h.unhealthyUntil) {
+			unhealthy = append(unhealthy, recursor)
+		} else {
+			healthy = append(healthy, recursor)
+		}
+	}
+	slices.SortStableFunc(unhealthy, func(a, b string) int {
+		return r.health[a].unhealthyUntil.Compare(r.health[b].unhealthyUntil)
+	})
+	return append(healthy, unhealthy...)
+}
+
+func (r *dnsRecursor) recordSuccess(recursor string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.health, recursor)
+}
+
+func (r *dnsRecursor) recordFailure(recursor string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	h, ok := r.health[recursor]
+	if !ok {
+		h = &recursorHealth{}
+		r.health[recursor] = h
+	}
+	h.failures++
+	if h.failures >= recursorMaxFailures {
+		if h.failures == recursorMaxFailures {
+			r.logger.Warn("recursor is failing, trying the other recursors first", "recursor", recursor)
+		}
+		h.unhealthyUntil = time.Now().Add(recursorBackoff)
+	}
+}
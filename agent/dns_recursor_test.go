@@ -0,0 +1,357 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/config"
+)
+
+// stubRecursors replaces the exchange function of r so that the queries are
+// answered by the handlers in answers, keyed by recursor address. A recursor
+// without a handler fails. It returns the list of the recursors that were
+// queried.
+func stubRecursors(r *dnsRecursor, answers map[string]func(req *dns.Msg) *dns.Msg) func() []string {
+	var lock sync.Mutex
+	var queried []string
+	r.exchangeFn = func(c *dns.Client, req *dns.Msg, addr string) (*dns.Msg, time.Duration, error) {
+		lock.Lock()
+		queried = append(queried, addr)
+		lock.Unlock()
+
+		fn, ok := answers[addr]
+		if !ok {
+			return nil, 0, errors.New("connection refused")
+		}
+		return fn(req), time.Millisecond, nil
+	}
+	return func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string(nil), queried...)
+	}
+}
+
+func recursorRequestConfig(size int, recursors ...string) *dnsRequestConfig {
+	return &dnsRequestConfig{
+		dnsServerConfig: &dnsServerConfig{
+			Recursors:       recursors,
+			RecursorTimeout: time.Second,
+			RecursorCache: config.RuntimeDNSRecursorCacheConfig{
+				Size:           size,
+				MaxTTL:         time.Hour,
+				MaxNegativeTTL: 5 * time.Minute,
+				Prefetch:       true,
+			},
+		},
+	}
+}
+
+func answerA(ttl uint32) func(req *dns.Msg) *dns.Msg {
+	return func(req *dns.Msg) *dns.Msg {
+		m := new(dns.Msg)
+		m.SetReply(req)
+		m.Answer = []dns.RR{&dns.A{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+			A:   []byte{192, 0, 2, 1},
+		}}
+		return m
+	}
+}
+
+func TestDNSRecursor_Cache(t *testing.T) {
+	r := newDNSRecursor(hclog.NewNullLogger())
+	queried := stubRecursors(r, map[string]func(*dns.Msg) *dns.Msg{
+		"192.0.2.53:53": answerA(60),
+	})
+	cfg := recursorRequestConfig(16, "192.0.2.53:53")
+
+	req := new(dns.Msg)
+	req.SetQuestion("example.com.", dns.TypeA)
+	resp, err := r.exchange(cfg, req, "udp")
+	require.NoError(t, err)
+	require.Len(t, resp.Answer, 1)
+	require.Len(t, queried(), 1)
+
+	// The names are not case sensitive and the response must match the ID
+	// and question of the new query.
+	req = new(dns.Msg)
+	req.SetQuestion("EXAMPLE.com.", dns.TypeA)
+	resp, err = r.exchange(cfg, req, "udp")
+	require.NoError(t, err)
+	require.Len(t, queried(), 1)
+	require.Equal(t, req.Id, resp.Id)
+	require.Equal(t, "EXAMPLE.com.", resp.Question[0].Name)
+	require.Len(t, resp.Answer, 1)
+
+	// The TTL is decremented by the time spent in the cache.
+	r.lock.Lock()
+	raw, ok := r.cache.Get(recursorCacheKey(req))
+	require.True(t, ok)
+	entry := raw.(*recursorCacheEntry)
+	entry.stored = entry.stored.Add(-20 * time.Second)
+	r.lock.Unlock()
+
+	resp, err = r.exchange(cfg, req, "udp")
+	require.NoError(t, err)
+	require.Equal(t, uint32(40), resp.Answer[0].Header().Ttl)
+
+	// Other types are cached separately.
+	req = new(dns.Msg)
+	req.SetQuestion("example.com.", dns.TypeAAAA)
+	_, err = r.exchange(cfg, req, "udp")
+	require.NoError(t, err)
+	require.Len(t, queried(), 2)
+
+	// Expired responses are fetched again.
+	r.lock.Lock()
+	entry.expires = time.Now().Add(-time.Second)
+	r.lock.Unlock()
+	req = new(dns.Msg)
+	req.SetQuestion("example.com.", dns.TypeA)
+	_, err = r.exchange(cfg, req, "udp")
+	require.NoError(t, err)
+	require.Len(t, queried(), 3)
+}
+
+func TestDNSRecursor_Cache_ClientSubnet(t *testing.T) {
+	r := newDNSRecursor(hclog.NewNullLogger())
+	queried := stubRecursors(r, map[string]func(*dns.Msg) *dns.Msg{
+		"192.0.2.53:53": answerA(60),
+	})
+	cfg := recursorRequestConfig(16, "192.0.2.53:53")
+
+	query := func(subnet string) {
+		t.Helper()
+		req := new(dns.Msg)
+		req.SetQuestion("example.com.", dns.TypeA)
+		if subnet != "" {
+			_, ipNet, err := net.ParseCIDR(subnet)
+			require.NoError(t, err)
+			ones, _ := ipNet.Mask.Size()
+			opt := &dns.OPT{Hdr: dns.RR_Header{Name: ".", Rrtype: dns.TypeOPT}}
+			opt.Option = append(opt.Option, &dns.EDNS0_SUBNET{
+				Code:          dns.EDNS0SUBNET,
+				Family:        1,
+				SourceNetmask: uint8(ones),
+				Address:       ipNet.IP,
+			})
+			req.Extra = append(req.Extra, opt)
+		}
+		_, err := r.exchange(cfg, req, "udp")
+		require.NoError(t, err)
+	}
+
+	// Responses tailored to a client subnet are not shared with other
+	// subnets, or with the queries without one.
+	query("198.51.100.0/24")
+	query("198.51.100.0/24")
+	require.Len(t, queried(), 1)
+	query("203.0.113.0/24")
+	require.Len(t, queried(), 2)
+	query("")
+	require.Len(t, queried(), 3)
+}
+
+func TestDNSRecursor_Cache_Disabled(t *testing.T) {
+	r := newDNSRecursor(hclog.NewNullLogger())
+	queried := stubRecursors(r, map[string]func(*dns.Msg) *dns.Msg{
+		"192.0.2.53:53": answerA(60),
+	})
+	cfg := recursorRequestConfig(0, "192.0.2.53:53")
+
+	for range 3 {
+		req := new(dns.Msg)
+		req.SetQuestion("example.com.", dns.TypeA)
+		_, err := r.exchange(cfg, req, "udp")
+		require.NoError(t, err)
+	}
+	require.Len(t, queried(), 3)
+	require.Nil(t, r.cache)
+}
+
+func TestDNSRecursor_NegativeCache(t *testing.T) {
+	nxdomain := func(soaTTL, minTTL uint32) func(req *dns.Msg) *dns.Msg {
+		return func(req *dns.Msg) *dns.Msg {
+			m := new(dns.Msg)
+			m.SetRcode(req, dns.RcodeNameError)
+			m.Ns = []dns.RR{&dns.SOA{
+				Hdr:    dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: soaTTL},
+				Ns:     "ns.example.com.",
+				Mbox:   "hostmaster.example.com.",
+				Minttl: minTTL,
+			}}
+			return m
+		}
+	}
+
+	cases := map[string]struct {
+		answer func(req *dns.Msg) *dns.Msg
+		ttl    time.Duration
+	}{
+		"SOA TTL": {
+			answer: nxdomain(30, 600),
+			ttl:    30 * time.Second,
+		},
+		"SOA minimum": {
+			answer: nxdomain(600, 45),
+			ttl:    45 * time.Second,
+		},
+		"capped": {
+			answer: nxdomain(3600, 3600),
+			ttl:    5 * time.Minute,
+		},
+		"no SOA": {
+			answer: func(req *dns.Msg) *dns.Msg {
+				m := new(dns.Msg)
+				m.SetRcode(req, dns.RcodeNameError)
+				return m
+			},
+		},
+		"NODATA": {
+			answer: func(req *dns.Msg) *dns.Msg {
+				m := nxdomain(120, 120)(req)
+				m.Rcode = dns.RcodeSuccess
+				return m
+			},
+			ttl: 2 * time.Minute,
+		},
+		"truncated": {
+			answer: func(req *dns.Msg) *dns.Msg {
+				m := answerA(60)(req)
+				m.Truncated = true
+				return m
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cfg := recursorRequestConfig(16)
+			require.Equal(t, tc.ttl, recursorCacheTTL(cfg, tc.answer(new(dns.Msg).SetQuestion("nope.example.com.", dns.TypeA))))
+		})
+	}
+
+	r := newDNSRecursor(hclog.NewNullLogger())
+	queried := stubRecursors(r, map[string]func(*dns.Msg) *dns.Msg{
+		"192.0.2.53:53": nxdomain(30, 30),
+	})
+	cfg := recursorRequestConfig(16, "192.0.2.53:53")
+	for range 2 {
+		req := new(dns.Msg)
+		req.SetQuestion("nope.example.com.", dns.TypeA)
+		resp, err := r.exchange(cfg, req, "udp")
+		require.NoError(t, err)
+		require.Equal(t, dns.RcodeNameError, resp.Rcode)
+	}
+	require.Len(t, queried(), 1)
+}
+
+func TestDNSRecursor_Failover(t *testing.T) {
+	r := newDNSRecursor(hclog.NewNullLogger())
+	queried := stubRecursors(r, map[string]func(*dns.Msg) *dns.Msg{
+		"192.0.2.2:53": answerA(60),
+		"192.0.2.3:53": func(req *dns.Msg) *dns.Msg {
+			m := new(dns.Msg)
+			m.SetRcode(req, dns.RcodeServerFailure)
+			return m
+		},
+	})
+	cfg := recursorRequestConfig(0, "192.0.2.1:53", "192.0.2.2:53")
+
+	lookup := func() {
+		t.Helper()
+		req := new(dns.Msg)
+		req.SetQuestion("example.com.", dns.TypeA)
+		resp, err := r.exchange(cfg, req, "udp")
+		require.NoError(t, err)
+		require.Len(t, resp.Answer, 1)
+	}
+
+	// The failing recursor is tried first until it is considered unhealthy.
+	for range recursorMaxFailures {
+		lookup()
+	}
+	require.Len(t, queried(), 2*recursorMaxFailures)
+
+	lookup()
+	require.Equal(t, "192.0.2.2:53", queried()[2*recursorMaxFailures])
+	require.Equal(t, []string{"192.0.2.2:53", "192.0.2.1:53"}, r.order(cfg))
+
+	// It is tried again once the backoff is over.
+	r.lock.Lock()
+	r.health["192.0.2.1:53"].unhealthyUntil = time.Now().Add(-time.Second)
+	r.lock.Unlock()
+	require.Equal(t, []string{"192.0.2.1:53", "192.0.2.2:53"}, r.order(cfg))
+
+	// A recursor answering with an error is a failure as well, and a
+	// successful answer resets the health of a recursor.
+	cfg = recursorRequestConfig(0, "192.0.2.3:53", "192.0.2.2:53")
+	for range recursorMaxFailures {
+		lookup()
+	}
+	require.Equal(t, []string{"192.0.2.2:53", "192.0.2.3:53"}, r.order(cfg))
+	r.recordSuccess("192.0.2.3:53")
+	require.Equal(t, []string{"192.0.2.3:53", "192.0.2.2:53"}, r.order(cfg))
+
+	// All the recursors failing is reported.
+	cfg = recursorRequestConfig(0, "192.0.2.1:53")
+	req := new(dns.Msg)
+	req.SetQuestion("example.com.", dns.TypeA)
+	_, err := r.exchange(cfg, req, "udp")
+	require.ErrorIs(t, err, errAllRecursorsFailed)
+}
+
+func TestDNSRecursor_Prefetch(t *testing.T) {
+	r := newDNSRecursor(hclog.NewNullLogger())
+	queried := stubRecursors(r, map[string]func(*dns.Msg) *dns.Msg{
+		"192.0.2.53:53": answerA(100),
+	})
+	cfg := recursorRequestConfig(16, "192.0.2.53:53")
+
+	req := new(dns.Msg)
+	req.SetQuestion("example.com.", dns.TypeA)
+	key := recursorCacheKey(req)
+	_, err := r.exchange(cfg, req, "udp")
+	require.NoError(t, err)
+
+	// Move the entry close to its expiration.
+	r.lock.Lock()
+	raw, _ := r.cache.Get(key)
+	entry := raw.(*recursorCacheEntry)
+	entry.stored = entry.stored.Add(-95 * time.Second)
+	entry.expires = entry.expires.Add(-95 * time.Second)
+	r.lock.Unlock()
+
+	// The entry is only prefetched once it has been used enough times.
+	for range recursorPrefetchHits - 1 {
+		_, err := r.exchange(cfg, req, "udp")
+		require.NoError(t, err)
+	}
+	require.Len(t, queried(), 1)
+
+	_, err = r.exchange(cfg, req, "udp")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		raw, ok := r.cache.Get(key)
+		return ok && raw.(*recursorCacheEntry) != entry
+	}, time.Second, 10*time.Millisecond)
+	require.Len(t, queried(), 2)
+
+	// The refreshed response is served with its full TTL.
+	resp, err := r.exchange(cfg, req, "udp")
+	require.NoError(t, err)
+	require.Equal(t, uint32(100), resp.Answer[0].Header().Ttl)
+	require.Len(t, queried(), 2)
+}
@@ -559,6 +559,172 @@ func TestDNS_EDNS0_ECS(t *testing.T) {
 	}
 }
 
+func TestDNS_EDNS0_ECS_NearIP(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	// Register a node in each of two client networks.
+	for node, addr := range map[string]string{"foo": "198.18.0.10", "bar": "203.0.113.10"} {
+		args := &structs.RegisterRequest{
+			Datacenter: "dc1",
+			Node:       node,
+			Address:    addr,
+			Service: &structs.NodeService{
+				Service: "db",
+				Port:    12345,
+			},
+		}
+
+		var out struct{}
+		require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+	}
+
+	{
+		args := &structs.PreparedQueryRequest{
+			Datacenter: "dc1",
+			Op:         structs.PreparedQueryCreate,
+			Query: &structs.PreparedQuery{
+				Name: "test",
+				Service: structs.ServiceQuery{
+					Service: "db",
+					Near:    "_ip",
+				},
+			},
+		}
+		var id string
+		require.NoError(t, a.RPC(context.Background(), "PreparedQuery.Apply", args, &id))
+	}
+
+	cases := []struct {
+		name          string
+		subnet        string
+		sourceNetmask uint8
+		expected      string
+	}{
+		{"host foo", "198.18.0.10", 32, "198.18.0.10"},
+		{"subnet foo", "198.18.0.0", 24, "198.18.0.10"},
+		{"host bar", "203.0.113.10", 32, "203.0.113.10"},
+		{"subnet bar", "203.0.113.0", 24, "203.0.113.10"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// The nodes are shuffled when there is no match, so the
+			// lookup is repeated to make sure the order is not random.
+			for range 5 {
+				m := new(dns.Msg)
+				m.SetQuestion("test.query.consul.", dns.TypeA)
+				m.SetEdns0(512, false)
+				m.IsEdns0().Option = append(m.IsEdns0().Option, &dns.EDNS0_SUBNET{
+					Code:          dns.EDNS0SUBNET,
+					Family:        1,
+					SourceNetmask: tc.sourceNetmask,
+					Address:       net.ParseIP(tc.subnet),
+				})
+
+				in, _, err := new(dns.Client).Exchange(m, a.DNSAddr())
+				require.NoError(t, err)
+				require.Len(t, in.Answer, 2)
+				aRec, ok := in.Answer[0].(*dns.A)
+				require.True(t, ok)
+				require.Equal(t, tc.expected, aRec.A.String())
+
+				subnet := in.IsEdns0().Option[0].(*dns.EDNS0_SUBNET)
+				require.Equal(t, tc.sourceNetmask, subnet.SourceScope)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		m := new(dns.Msg)
+		m.SetQuestion("test.query.consul.", dns.TypeA)
+		m.SetEdns0(512, false)
+		m.IsEdns0().Option = append(m.IsEdns0().Option, &dns.EDNS0_SUBNET{
+			Code:          dns.EDNS0SUBNET,
+			Family:        1,
+			SourceNetmask: 24,
+			SourceScope:   24,
+			Address:       net.ParseIP("198.18.0.0"),
+		})
+
+		in, _, err := new(dns.Client).Exchange(m, a.DNSAddr())
+		require.NoError(t, err)
+		require.Equal(t, dns.RcodeFormatError, in.Rcode)
+		require.Empty(t, in.Answer)
+		require.Empty(t, in.IsEdns0().Option)
+	})
+}
+
+func TestDNS_QuerySourceIP(t *testing.T) {
+	remoteAddr := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 53}
+
+	cases := []struct {
+		name     string
+		subnet   *dns.EDNS0_SUBNET
+		expected string
+		err      error
+	}{
+		{
+			name:     "no subnet",
+			expected: "192.0.2.1",
+		},
+		{
+			name:     "IPv4 host",
+			subnet:   &dns.EDNS0_SUBNET{Family: 1, SourceNetmask: 32, Address: net.ParseIP("198.18.0.1")},
+			expected: "198.18.0.1",
+		},
+		{
+			name:     "IPv4 subnet",
+			subnet:   &dns.EDNS0_SUBNET{Family: 1, SourceNetmask: 21, Address: net.ParseIP("198.18.0.0")},
+			expected: "198.18.0.0/21",
+		},
+		{
+			name:     "IPv6 subnet",
+			subnet:   &dns.EDNS0_SUBNET{Family: 2, SourceNetmask: 56, Address: net.ParseIP("2001:db8:0:100::")},
+			expected: "2001:db8:0:100::/56",
+		},
+		{
+			name:     "undisclosed",
+			subnet:   &dns.EDNS0_SUBNET{Family: 0, Address: net.IPv4zero},
+			expected: "192.0.2.1",
+		},
+		{
+			name:   "scope set",
+			subnet: &dns.EDNS0_SUBNET{Family: 1, SourceNetmask: 24, SourceScope: 24, Address: net.ParseIP("198.18.0.0")},
+			err:    errInvalidECS,
+		},
+		{
+			name:   "bits past prefix",
+			subnet: &dns.EDNS0_SUBNET{Family: 1, SourceNetmask: 24, Address: net.ParseIP("198.18.0.1")},
+			err:    errInvalidECS,
+		},
+		{
+			name:   "wrong family",
+			subnet: &dns.EDNS0_SUBNET{Family: 2, SourceNetmask: 24, Address: net.ParseIP("198.18.0.0").To4()},
+			err:    errInvalidECS,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := new(dns.Msg)
+			m.SetQuestion("test.query.consul.", dns.TypeA)
+			if tc.subnet != nil {
+				m.SetEdns0(512, false)
+				tc.subnet.Code = dns.EDNS0SUBNET
+				m.IsEdns0().Option = append(m.IsEdns0().Option, tc.subnet)
+			}
+
+			source, err := querySourceIP(remoteAddr, m)
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.expected, source)
+		})
+	}
+}
+
 func TestDNS_SOA_Settings(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
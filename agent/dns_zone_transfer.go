@@ -0,0 +1,472 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-metrics"
+	"github.com/hashicorp/go-metrics/prometheus"
+	"github.com/miekg/dns"
+	"golang.org/x/sync/singleflight"
+
+	agentdns "github.com/hashicorp/consul/agent/dns"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/internal/dnsutil"
+)
+
+const (
+	// zoneTransferHistory is the number of versions of each zone kept to
+	// answer the incremental zone transfers with the changes since the
+	// version of the client.
+	zoneTransferHistory = 16
+
+	// zoneTransferEnvelopeSize is the number of records sent in each message
+	// of a zone transfer.
+	zoneTransferEnvelopeSize = 100
+)
+
+var DNSZoneTransferCounters = []prometheus.CounterDefinition{
+	{
+		Name: []string{"dns", "zone_transfer"},
+		Help: "Increments when a zone transfer of the Consul domain is served.",
+	},
+}
+
+var (
+	errZoneTransferDisabled = errors.New("zone transfers are disabled")
+	errZoneTransferRefused  = errors.New("zone transfer refused")
+	errZoneTransferNotAuth  = errors.New("zone transfer of a name that is not a zone apex")
+	errZoneTransferFormat   = errors.New("malformed zone transfer query")
+)
+
+// zoneVersion is the content of a zone at a given serial.
+type zoneVersion struct {
+	serial uint32
+	// records are the sorted records of the zone, the SOA record excepted.
+	records []dns.RR
+}
+
+// builtZone is the last version of a zone built for a token.
+type builtZone struct {
+	version *zoneVersion
+	builtAt time.Time
+}
+
+// dnsZoneHistory keeps the last versions of the zones of the Consul domain
+// so that incremental zone transfers only send the records that changed, and
+// the last version built for each token so that the catalog is not read for
+// every zone transfer or SOA query. It is shared by all the DNS servers of the
+// agent.
+//
+// Both are keyed by zone and token: the versions built with a token only
+// contain the records it can read, so an incremental transfer must never diff
+// versions built with different tokens.
+type dnsZoneHistory struct {
+	lock  sync.Mutex
+	zones map[string][]*zoneVersion
+	built map[string]builtZone
+
+	// builds shares the concurrent builds of the same zone.
+	builds singleflight.Group
+}
+
+func newDNSZoneHistory() *dnsZoneHistory {
+	return &dnsZoneHistory{
+		zones: make(map[string][]*zoneVersion),
+		built: make(map[string]builtZone),
+	}
+}
+
+// latest returns the last version of zone built with token if it was built
+// less than ttl ago, or nil.
+func (h *dnsZoneHistory) latest(zone, token string, ttl time.Duration) *zoneVersion {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	built, ok := h.built[zoneHistoryKey(zone, token)]
+	if !ok || time.Since(built.builtAt) >= ttl {
+		return nil
+	}
+	return built.version
+}
+
+// add records a version of zone built with token, replacing the last version
+// when its serial did not change.
+func (h *dnsZoneHistory) add(zone, token string, version *zoneVersion) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	key := zoneHistoryKey(zone, token)
+	h.built[key] = builtZone{version: version, builtAt: time.Now()}
+
+	versions := h.zones[key]
+	if n := len(versions); n > 0 && versions[n-1].serial == version.serial {
+		versions[n-1] = version
+		return
+	}
+	versions = append(versions, version)
+	if len(versions) > zoneTransferHistory {
+		versions = slices.Delete(versions, 0, len(versions)-zoneTransferHistory)
+	}
+	h.zones[key] = versions
+}
+
+// get returns the version of zone built with token with the given serial, or
+// nil when it is not known anymore.
+func (h *dnsZoneHistory) get(zone, token string, serial uint32) *zoneVersion {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for _, version := range h.zones[zoneHistoryKey(zone, token)] {
+		if version.serial == serial {
+			return version
+		}
+	}
+	return nil
+}
+
+// zoneHistoryKey returns the key of the versions of zone built with token.
+func zoneHistoryKey(zone, token string) string {
+	return zone + "\x00" + token
+}
+
+// zoneTransferRcode returns the response code for an error returned by
+// zoneTransferRecords.
+func zoneTransferRcode(err error) int {
+	switch {
+	case errors.Is(err, errZoneTransferDisabled):
+		return dns.RcodeNotImplemented
+	case errors.Is(err, errZoneTransferRefused):
+		return dns.RcodeRefused
+	case errors.Is(err, errZoneTransferNotAuth):
+		return dns.RcodeNotAuth
+	case errors.Is(err, errZoneTransferFormat):
+		return dns.RcodeFormatError
+	default:
+		return dns.RcodeServerFailure
+	}
+}
+
+// zoneTransferAllowed returns an error when the client of resp may not read
+// the content of the whole zone. Zone transfers are disabled when no CIDR is
+// allowed, and are only possible over the DNS listeners: the queries received
+// over gRPC or HTTPS cannot be answered with several messages.
+func (d *DNSServer) zoneTransferAllowed(cfg *dnsRequestConfig, resp dns.ResponseWriter) error {
+	if len(cfg.ZoneTransfer.AllowedCIDRs) == 0 {
+		return errZoneTransferDisabled
+	}
+	if _, ok := resp.(*agentdns.BufferResponseWriter); ok {
+		return errZoneTransferDisabled
+	}
+
+	var ip net.IP
+	switch addr := resp.RemoteAddr().(type) {
+	case *net.TCPAddr:
+		ip = addr.IP
+	case *net.UDPAddr:
+		ip = addr.IP
+	}
+	for _, cidr := range cfg.ZoneTransfer.AllowedCIDRs {
+		if ip != nil && cidr.Contains(ip) {
+			return nil
+		}
+	}
+	return errZoneTransferRefused
+}
+
+// zoneTransferRecords returns the records answering an AXFR or IXFR query,
+// in the order they must be sent. Incremental transfers are answered with the
+// changes since the serial of the client when its version of the zone is
+// still known, and with the whole zone otherwise, as described in RFC 1995.
+func (d *DNSServer) zoneTransferRecords(cfg *dnsRequestConfig, resp dns.ResponseWriter, network string, req *dns.Msg) ([]dns.RR, error) {
+	if err := d.zoneTransferAllowed(cfg, resp); err != nil {
+		return nil, err
+	}
+
+	q := req.Question[0]
+	zone := d.getResponseDomain(q.Name)
+	if !strings.EqualFold(q.Name, zone) {
+		return nil, errZoneTransferNotAuth
+	}
+
+	var clientSerial uint32
+	if q.Qtype == dns.TypeIXFR {
+		if len(req.Ns) == 0 {
+			return nil, errZoneTransferFormat
+		}
+		soa, ok := req.Ns[0].(*dns.SOA)
+		if !ok {
+			return nil, errZoneTransferFormat
+		}
+		clientSerial = soa.Serial
+	} else if network != "tcp" {
+		// AXFR is only defined over TCP, see RFC 5936.
+		return nil, errZoneTransferFormat
+	}
+
+	token := d.zoneTransferToken(cfg)
+	current, err := d.currentZone(cfg, zone, token)
+	if err != nil {
+		return nil, err
+	}
+	soa := d.zoneSOARecord(cfg, zone, current.serial)
+
+	if q.Qtype == dns.TypeIXFR {
+		// The client is up to date, or the response must fit in a single
+		// UDP message.
+		if int32(current.serial-clientSerial) <= 0 || network != "tcp" {
+			return []dns.RR{soa}, nil
+		}
+
+		if previous := d.zoneHistory.get(zone, token, clientSerial); previous != nil {
+			deleted, added := diffZoneRecords(previous.records, current.records)
+			rrs := make([]dns.RR, 0, len(deleted)+len(added)+4)
+			rrs = append(rrs, soa, d.zoneSOARecord(cfg, zone, previous.serial))
+			rrs = append(rrs, deleted...)
+			rrs = append(rrs, soa)
+			rrs = append(rrs, added...)
+			return append(rrs, soa), nil
+		}
+	}
+
+	rrs := make([]dns.RR, 0, len(current.records)+2)
+	rrs = append(rrs, soa)
+	rrs = append(rrs, current.records...)
+	return append(rrs, soa), nil
+}
+
+// sendZoneTransfer writes the records of a zone transfer to the client,
+// split over several messages.
+func (d *DNSServer) sendZoneTransfer(resp dns.ResponseWriter, req *dns.Msg, rrs []dns.RR) {
+	ch := make(chan *dns.Envelope, len(rrs)/zoneTransferEnvelopeSize+1)
+	for chunk := range slices.Chunk(rrs, zoneTransferEnvelopeSize) {
+		ch <- &dns.Envelope{RR: chunk}
+	}
+	close(ch)
+
+	q := req.Question[0]
+	metrics.IncrCounterWithLabels([]string{"dns", "zone_transfer"}, 1,
+		[]metrics.Label{{Name: "type", Value: dns.Type(q.Qtype).String()}})
+	if err := new(dns.Transfer).Out(resp, req, ch); err != nil {
+		d.logger.Warn("failed to send zone transfer",
+			"zone", q.Name,
+			"client", resp.RemoteAddr().String(),
+			"error", err,
+		)
+	}
+}
+
+// zoneSOARecord returns the SOA record of zone with the given serial.
+func (d *DNSServer) zoneSOARecord(cfg *dnsRequestConfig, zone string, serial uint32) *dns.SOA {
+	soa := d.makeSOARecord(cfg, zone)
+	soa.Serial = serial
+	return soa
+}
+
+// zoneSerial returns the serial of zone, the Raft index of the catalog of the
+// local datacenter, when the client is allowed to transfer the zone so that
+// secondary servers polling the SOA record only transfer it when it changed.
+func (d *DNSServer) zoneSerial(cfg *dnsRequestConfig, resp dns.ResponseWriter, zone string) (uint32, bool) {
+	if d.zoneTransferAllowed(cfg, resp) != nil {
+		return 0, false
+	}
+
+	current, err := d.currentZone(cfg, zone, d.zoneTransferToken(cfg))
+	if err != nil {
+		d.logger.Warn("failed to build the zone for its serial", "zone", zone, "error", err)
+		return 0, false
+	}
+	return current.serial, true
+}
+
+// zoneTransferToken returns the token the zone is built with:
+// ZoneTransfer.Token, or the token of the DNS request when it is not set.
+func (d *DNSServer) zoneTransferToken(cfg *dnsRequestConfig) string {
+	if cfg.ZoneTransfer.Token != "" {
+		return cfg.ZoneTransfer.Token
+	}
+	return d.coalesceDNSToken(cfg.token)
+}
+
+// currentZone returns the current version of zone built with token. The zone
+// is built from the catalog at most once per ZoneTransfer.CacheTTL, the
+// concurrent builds of the same zone are shared.
+func (d *DNSServer) currentZone(cfg *dnsRequestConfig, zone, token string) (*zoneVersion, error) {
+	if current := d.zoneHistory.latest(zone, token, cfg.ZoneTransfer.CacheTTL); current != nil {
+		return current, nil
+	}
+
+	raw, err, _ := d.zoneHistory.builds.Do(zoneHistoryKey(zone, token), func() (interface{}, error) {
+		current, err := d.buildZone(cfg, zone, token)
+		if err != nil {
+			return nil, err
+		}
+		d.zoneHistory.add(zone, token, current)
+		return current, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return raw.(*zoneVersion), nil
+}
+
+// buildZone materializes the records of the nodes and services of the local
+// datacenter from the catalog. The serial of the zone is the Raft index of
+// the catalog so that it only changes when the catalog does.
+func (d *DNSServer) buildZone(cfg *dnsRequestConfig, zone, token string) (*zoneVersion, error) {
+	datacenter := d.agent.config.Datacenter
+
+	// The reads are consistent so that the serial never goes back when the
+	// agent talks to another server.
+	nodesArgs := structs.DCSpecificRequest{
+		Datacenter:     datacenter,
+		QueryOptions:   structs.QueryOptions{Token: token, RequireConsistent: true},
+		EnterpriseMeta: cfg.defaultEnterpriseMeta,
+	}
+	var nodes structs.IndexedNodes
+	if err := d.agent.RPC(context.TODO(), "Catalog.ListNodes", &nodesArgs, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to list the nodes: %w", err)
+	}
+
+	dumpArgs := structs.ServiceDumpRequest{
+		Datacenter:     datacenter,
+		NodesOnly:      true,
+		QueryOptions:   structs.QueryOptions{Token: token, RequireConsistent: true},
+		EnterpriseMeta: cfg.defaultEnterpriseMeta,
+	}
+	var dump structs.IndexedNodesWithGateways
+	if err := d.agent.RPC(context.TODO(), "Internal.ServiceDump", &dumpArgs, &dump); err != nil {
+		return nil, fmt.Errorf("failed to list the services: %w", err)
+	}
+
+	// Every record is generated, the names in the Consul domain are resolved
+	// without falling back to the recursors.
+	serverCfg := *cfg.dnsServerConfig
+	serverCfg.ARecordLimit = 0
+	serverCfg.Recursors = nil
+	zoneCfg := &dnsRequestConfig{
+		dnsServerConfig:       &serverCfg,
+		token:                 token,
+		defaultEnterpriseMeta: cfg.defaultEnterpriseMeta,
+	}
+
+	var rrs []dns.RR
+	for _, node := range nodes.Nodes {
+		if dnsutil.InvalidNameRe.MatchString(node.Node) {
+			continue
+		}
+		node.Datacenter = datacenter
+		name := nodeCanonicalDNSName(node, zone)
+		rrs = append(rrs, d.makeRecordFromNode(node, dns.TypeANY, name, zoneCfg, maxRecursionLevelDefault)...)
+		if zoneCfg.NodeMetaTXT {
+			rrs = append(rrs, d.makeTXTRecordFromNodeMeta(name, node, zoneCfg.NodeTTL)...)
+		}
+	}
+
+	filterType := structs.HealthFilterExcludeCritical
+	if zoneCfg.OnlyPassing {
+		filterType = structs.HealthFilterIncludeOnlyPassing
+	}
+	services := make(map[string]structs.CheckServiceNodes)
+	for _, node := range dump.Nodes.Filter(structs.CheckServiceNodeFilterOptions{FilterType: filterType}) {
+		node.Node.Datacenter = datacenter
+		services[node.Service.Service] = append(services[node.Service.Service], node)
+	}
+	for service, instances := range services {
+		if dnsutil.InvalidNameRe.MatchString(service) {
+			continue
+		}
+		lookup := serviceLookup{
+			Datacenter:        datacenter,
+			Service:           service,
+			MaxRecursionLevel: maxRecursionLevelDefault,
+			EnterpriseMeta:    cfg.defaultEnterpriseMeta,
+		}
+		ttl, _ := zoneCfg.GetTTLForService(service)
+		name := service + ".service." + datacenter + "." + zone
+
+		msg := new(dns.Msg)
+		d.addServiceNodeRecordsToMessage(zoneCfg, lookup, instances, new(dns.Msg).SetQuestion(name, dns.TypeANY), msg, ttl, maxRecursionLevelDefault)
+		for _, srvName := range []string{name, "_" + service + "._tcp.service." + datacenter + "." + zone} {
+			err := d.addServiceSRVRecordsToMessage(zoneCfg, lookup, instances, new(dns.Msg).SetQuestion(srvName, dns.TypeSRV), msg, ttl, maxRecursionLevelDefault)
+			if err != nil {
+				return nil, err
+			}
+		}
+		rrs = append(rrs, msg.Answer...)
+		rrs = append(rrs, msg.Extra...)
+
+		if service == structs.ConsulServiceName {
+			for _, instance := range instances {
+				rrs = append(rrs, &dns.NS{
+					Hdr: dns.RR_Header{
+						Name:   zone,
+						Rrtype: dns.TypeNS,
+						Class:  dns.ClassINET,
+						Ttl:    uint32(zoneCfg.NodeTTL.Seconds()),
+					},
+					Ns: nodeCanonicalDNSName(instance.Node, zone),
+				})
+			}
+		}
+	}
+
+	return &zoneVersion{
+		serial:  uint32(max(nodes.Index, dump.Index)),
+		records: normalizeZoneRecords(zone, rrs),
+	}, nil
+}
+
+// normalizeZoneRecords drops the records outside of zone and the duplicates,
+// and sorts the others so that two versions of the zone can be compared.
+func normalizeZoneRecords(zone string, rrs []dns.RR) []dns.RR {
+	seen := make(map[string]struct{}, len(rrs))
+	records := make([]dns.RR, 0, len(rrs))
+	for _, rr := range rrs {
+		if !dns.IsSubDomain(zone, rr.Header().Name) {
+			continue
+		}
+		key := rr.String()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		records = append(records, rr)
+	}
+	slices.SortFunc(records, func(a, b dns.RR) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return records
+}
+
+// diffZoneRecords returns the records of previous that are not in current,
+// and the records of current that are not in previous.
+func diffZoneRecords(previous, current []dns.RR) (deleted, added []dns.RR) {
+	previousKeys := make(map[string]struct{}, len(previous))
+	for _, rr := range previous {
+		previousKeys[rr.String()] = struct{}{}
+	}
+	currentKeys := make(map[string]struct{}, len(current))
+	for _, rr := range current {
+		key := rr.String()
+		currentKeys[key] = struct{}{}
+		if _, ok := previousKeys[key]; !ok {
+			added = append(added, rr)
+		}
+	}
+	for _, rr := range previous {
+		if _, ok := currentKeys[rr.String()]; !ok {
+			deleted = append(deleted, rr)
+		}
+	}
+	return deleted, added
+}
@@ -0,0 +1,353 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/agent/token"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+	"github.com/hashicorp/consul/types"
+)
+
+// transferZone runs the zone transfer m and returns the records received.
+func transferZone(t *testing.T, addr string, m *dns.Msg) []dns.RR {
+	t.Helper()
+	envelopes, err := new(dns.Transfer).In(m, addr)
+	require.NoError(t, err)
+
+	var rrs []dns.RR
+	for envelope := range envelopes {
+		require.NoError(t, envelope.Error)
+		rrs = append(rrs, envelope.RR...)
+	}
+	return rrs
+}
+
+func TestDNS_ZoneTransfer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		dns_config {
+			zone_transfer {
+				allowed_cidrs = ["127.0.0.0/8"]
+				cache_ttl = "0s"
+			}
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	register := func(t *testing.T, node, address, service, status string) {
+		t.Helper()
+		args := &structs.RegisterRequest{
+			Datacenter: "dc1",
+			Node:       node,
+			Address:    address,
+			Service: &structs.NodeService{
+				Service: service,
+				Port:    12345,
+			},
+			Check: &structs.HealthCheck{
+				CheckID:   types.CheckID("check-" + service),
+				Name:      "check",
+				ServiceID: service,
+				Status:    status,
+			},
+		}
+		var out struct{}
+		require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+	}
+	register(t, "foo", "127.0.0.1", "db", api.HealthPassing)
+	register(t, "bar", "127.0.0.2", "web", api.HealthCritical)
+
+	query := func(qtype uint16, serial uint32) *dns.Msg {
+		m := new(dns.Msg)
+		m.SetQuestion("consul.", qtype)
+		if qtype == dns.TypeIXFR {
+			m.Ns = []dns.RR{&dns.SOA{
+				Hdr:    dns.RR_Header{Name: "consul.", Rrtype: dns.TypeSOA, Class: dns.ClassINET},
+				Ns:     "ns.consul.",
+				Mbox:   "hostmaster.consul.",
+				Serial: serial,
+			}}
+		}
+		return m
+	}
+	records := func(rrs []dns.RR) []string {
+		var out []string
+		for _, rr := range rrs {
+			if _, ok := rr.(*dns.SOA); ok {
+				continue
+			}
+			out = append(out, rr.String())
+		}
+		return out
+	}
+
+	rrs := transferZone(t, a.DNSAddr(), query(dns.TypeAXFR, 0))
+	require.GreaterOrEqual(t, len(rrs), 2)
+	first, last := rrs[0].(*dns.SOA), rrs[len(rrs)-1].(*dns.SOA)
+	require.Equal(t, first.Serial, last.Serial)
+	serial := first.Serial
+	require.NotZero(t, serial)
+
+	axfr := records(rrs)
+	require.Contains(t, axfr, "foo.node.dc1.consul.\t0\tIN\tA\t127.0.0.1")
+	require.Contains(t, axfr, "bar.node.dc1.consul.\t0\tIN\tA\t127.0.0.2")
+	require.Contains(t, axfr, "db.service.dc1.consul.\t0\tIN\tA\t127.0.0.1")
+	require.Contains(t, axfr, "db.service.dc1.consul.\t0\tIN\tSRV\t1 1 12345 foo.node.dc1.consul.")
+	require.Contains(t, axfr, "_db._tcp.service.dc1.consul.\t0\tIN\tSRV\t1 1 12345 foo.node.dc1.consul.")
+	require.Contains(t, axfr, "consul.\t0\tIN\tNS\t"+a.Config.NodeName+".node.dc1.consul.")
+	// The critical instances are not part of the zone.
+	for _, rr := range axfr {
+		require.NotContains(t, rr, "web.service")
+	}
+
+	t.Run("SOA serial", func(t *testing.T) {
+		m := new(dns.Msg)
+		m.SetQuestion("consul.", dns.TypeSOA)
+		in, _, err := new(dns.Client).Exchange(m, a.DNSAddr())
+		require.NoError(t, err)
+		require.Len(t, in.Answer, 1)
+		require.Equal(t, serial, in.Answer[0].(*dns.SOA).Serial)
+	})
+
+	t.Run("IXFR up to date", func(t *testing.T) {
+		rrs := transferZone(t, a.DNSAddr(), query(dns.TypeIXFR, serial))
+		require.Len(t, rrs, 1)
+		require.Equal(t, serial, rrs[0].(*dns.SOA).Serial)
+	})
+
+	t.Run("IXFR", func(t *testing.T) {
+		register(t, "baz", "127.0.0.3", "db", api.HealthPassing)
+
+		rrs := transferZone(t, a.DNSAddr(), query(dns.TypeIXFR, serial))
+
+		// SOA(new), SOA(old), deletions, SOA(new), additions, SOA(new)
+		newSerial := rrs[0].(*dns.SOA).Serial
+		require.Greater(t, newSerial, serial)
+		require.Equal(t, serial, rrs[1].(*dns.SOA).Serial)
+		var deleted, added []string
+		current := &deleted
+		for _, rr := range rrs[2 : len(rrs)-1] {
+			if soa, ok := rr.(*dns.SOA); ok {
+				require.Equal(t, newSerial, soa.Serial)
+				current = &added
+				continue
+			}
+			*current = append(*current, rr.String())
+		}
+		require.Empty(t, deleted)
+		require.Contains(t, added, "baz.node.dc1.consul.\t0\tIN\tA\t127.0.0.3")
+		require.Contains(t, added, "db.service.dc1.consul.\t0\tIN\tA\t127.0.0.3")
+		require.Contains(t, added, "db.service.dc1.consul.\t0\tIN\tSRV\t1 1 12345 baz.node.dc1.consul.")
+
+		// A serial that is not known anymore gets the whole zone.
+		rrs = transferZone(t, a.DNSAddr(), query(dns.TypeIXFR, 1))
+		require.Equal(t, newSerial, rrs[0].(*dns.SOA).Serial)
+		require.Equal(t, newSerial, rrs[len(rrs)-1].(*dns.SOA).Serial)
+		require.Contains(t, records(rrs), "foo.node.dc1.consul.\t0\tIN\tA\t127.0.0.1")
+		require.Contains(t, records(rrs), "baz.node.dc1.consul.\t0\tIN\tA\t127.0.0.3")
+	})
+
+	t.Run("not a zone apex", func(t *testing.T) {
+		m := new(dns.Msg)
+		m.SetQuestion("service.consul.", dns.TypeAXFR)
+		in, _, err := (&dns.Client{Net: "tcp"}).Exchange(m, a.DNSAddr())
+		require.NoError(t, err)
+		require.Equal(t, dns.RcodeNotAuth, in.Rcode)
+	})
+
+	t.Run("AXFR over UDP", func(t *testing.T) {
+		in, _, err := new(dns.Client).Exchange(query(dns.TypeAXFR, 0), a.DNSAddr())
+		require.NoError(t, err)
+		require.Equal(t, dns.RcodeFormatError, in.Rcode)
+	})
+}
+
+func TestDNS_ZoneTransfer_Refused(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	cases := map[string]struct {
+		config string
+		rcode  int
+	}{
+		"disabled": {
+			rcode: dns.RcodeNotImplemented,
+		},
+		"source not allowed": {
+			config: `
+				dns_config {
+					zone_transfer {
+						allowed_cidrs = ["192.0.2.0/24"]
+					}
+				}
+			`,
+			rcode: dns.RcodeRefused,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			a := NewTestAgent(t, tc.config)
+			defer a.Shutdown()
+			testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+			for _, qtype := range []uint16{dns.TypeAXFR, dns.TypeIXFR} {
+				m := new(dns.Msg)
+				m.SetQuestion("consul.", qtype)
+				m.Ns = []dns.RR{&dns.SOA{
+					Hdr:  dns.RR_Header{Name: "consul.", Rrtype: dns.TypeSOA, Class: dns.ClassINET},
+					Ns:   "ns.consul.",
+					Mbox: "hostmaster.consul.",
+				}}
+				in, _, err := (&dns.Client{Net: "tcp"}).Exchange(m, a.DNSAddr())
+				require.NoError(t, err)
+				require.Equal(t, tc.rcode, in.Rcode, dns.TypeToString[qtype])
+				require.Empty(t, in.Answer)
+			}
+		})
+	}
+}
+
+func TestDNS_ZoneTransfer_Cache(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		dns_config {
+			zone_transfer {
+				allowed_cidrs = ["127.0.0.0/8"]
+				cache_ttl = "1h"
+			}
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	soaSerial := func() uint32 {
+		m := new(dns.Msg)
+		m.SetQuestion("consul.", dns.TypeSOA)
+		in, _, err := new(dns.Client).Exchange(m, a.DNSAddr())
+		require.NoError(t, err)
+		require.Len(t, in.Answer, 1)
+		return in.Answer[0].(*dns.SOA).Serial
+	}
+
+	m := new(dns.Msg)
+	m.SetQuestion("consul.", dns.TypeAXFR)
+	rrs := transferZone(t, a.DNSAddr(), m)
+	serial := rrs[0].(*dns.SOA).Serial
+	require.Equal(t, serial, soaSerial())
+
+	// The zone built for the transfer keeps being used until it expires, even
+	// though the catalog changed.
+	args := &structs.RegisterRequest{
+		Datacenter: "dc1",
+		Node:       "foo",
+		Address:    "127.0.0.1",
+	}
+	var out struct{}
+	require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+
+	require.Equal(t, serial, soaSerial())
+	rrs = transferZone(t, a.DNSAddr(), m)
+	require.Equal(t, serial, rrs[0].(*dns.SOA).Serial)
+	for _, rr := range rrs {
+		require.NotContains(t, rr.String(), "foo.node")
+	}
+}
+
+func TestDNS_ZoneTransfer_ACLToken(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		primary_datacenter = "dc1"
+		acl {
+			enabled = true
+			default_policy = "deny"
+			tokens {
+				initial_management = "root"
+			}
+		}
+		dns_config {
+			zone_transfer {
+				allowed_cidrs = ["127.0.0.0/8"]
+				cache_ttl = "0s"
+			}
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	register := func(t *testing.T, node, address string) {
+		t.Helper()
+		args := &structs.RegisterRequest{
+			Datacenter:   "dc1",
+			Node:         node,
+			Address:      address,
+			WriteRequest: structs.WriteRequest{Token: "root"},
+		}
+		var out struct{}
+		require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+	}
+	register(t, "foo", "127.0.0.1")
+	register(t, "bar", "127.0.0.2")
+	limited := testCreateToken(t, a, `
+		node "foo" { policy = "read" }
+		node "baz" { policy = "read" }
+	`)
+
+	names := func(rrs []dns.RR) []string {
+		var out []string
+		for _, rr := range rrs {
+			out = append(out, rr.Header().Name)
+		}
+		return out
+	}
+
+	// The zone is first transferred with a token that can read every node.
+	a.tokens.UpdateDNSToken("root", token.TokenSourceConfig)
+	m := new(dns.Msg)
+	m.SetQuestion("consul.", dns.TypeAXFR)
+	rrs := transferZone(t, a.DNSAddr(), m)
+	serial := rrs[0].(*dns.SOA).Serial
+	require.Contains(t, names(rrs), "bar.node.dc1.consul.")
+
+	// After the token changed, an incremental transfer from that serial must
+	// not be computed against the version built with the previous token, or
+	// the records of bar would be sent as deleted.
+	register(t, "baz", "127.0.0.3")
+	a.tokens.UpdateDNSToken(limited, token.TokenSourceConfig)
+	m = new(dns.Msg)
+	m.SetQuestion("consul.", dns.TypeIXFR)
+	m.Ns = []dns.RR{&dns.SOA{
+		Hdr:    dns.RR_Header{Name: "consul.", Rrtype: dns.TypeSOA, Class: dns.ClassINET},
+		Ns:     "ns.consul.",
+		Mbox:   "hostmaster.consul.",
+		Serial: serial,
+	}}
+	rrs = transferZone(t, a.DNSAddr(), m)
+	require.Greater(t, rrs[0].(*dns.SOA).Serial, serial)
+	require.Contains(t, names(rrs), "foo.node.dc1.consul.")
+	require.Contains(t, names(rrs), "baz.node.dc1.consul.")
+	require.NotContains(t, names(rrs), "bar.node.dc1.consul.")
+}
@@ -4,6 +4,7 @@
 package subscribe
 
 import (
+	"context"
 	"errors"
 
 	"github.com/hashicorp/go-hclog"
@@ -12,6 +13,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/acl/resolver"
 	"github.com/hashicorp/consul/agent/consul/state"
 	"github.com/hashicorp/consul/agent/consul/stream"
 	"github.com/hashicorp/consul/agent/structs"
@@ -36,8 +38,12 @@ type Logger interface {
 
 var _ pbsubscribe.StateChangeSubscriptionServer = (*Server)(nil)
 
+// errACLConditionsChanged is returned to close a subscription when the time
+// windows of the token's policy conditions change which rules apply.
+var errACLConditionsChanged = errors.New("ACL policy conditions changed")
+
 type Backend interface {
-	ResolveTokenAndDefaultMeta(token string, entMeta *acl.EnterpriseMeta, authzContext *acl.AuthorizerContext) (acl.Authorizer, error)
+	ResolveTokenAndDefaultMeta(token string, entMeta *acl.EnterpriseMeta, authzContext *acl.AuthorizerContext) (resolver.Result, error)
 	Forward(info structs.RPCInfo, f func(*grpc.ClientConn) error) (handled bool, err error)
 	Subscribe(req *stream.SubscribeRequest) (*stream.Subscription, error)
 }
@@ -69,7 +75,16 @@ func (h *Server) Subscribe(req *pbsubscribe.SubscribeRequest, serverStream pbsub
 	}
 	defer sub.Unsubscribe()
 
+	// The authorizer only holds the rules whose time windows applied when the
+	// token was resolved, so the stream is closed when they change and the
+	// client re-subscribes with a fresh one.
 	ctx := serverStream.Context()
+	if !authz.ValidUntil.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, authz.ValidUntil)
+		defer cancel()
+	}
+
 	elog := &eventLogger{logger: logger}
 	for {
 		event, err := sub.Next(ctx)
@@ -77,6 +92,9 @@ func (h *Server) Subscribe(req *pbsubscribe.SubscribeRequest, serverStream pbsub
 		case errors.Is(err, stream.ErrSubForceClosed):
 			logger.Trace("subscription reset by server")
 			return status.Error(codes.Aborted, err.Error())
+		case errors.Is(err, context.DeadlineExceeded) && serverStream.Context().Err() == nil:
+			logger.Trace("ACL conditions changed; re-authenticating")
+			return status.Error(codes.Aborted, errACLConditionsChanged.Error())
 		case errors.Is(err, stream.ErrACLChanged):
 			logger.Trace("ACL change occurred; re-authenticating")
 			_, authzErr := h.Backend.ResolveTokenAndDefaultMeta(req.Token, &entMeta, nil)
@@ -23,6 +23,7 @@ import (
 	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/acl/resolver"
 	"github.com/hashicorp/consul/agent/consul/rate"
 	"github.com/hashicorp/consul/agent/consul/state"
 	"github.com/hashicorp/consul/agent/consul/stream"
@@ -327,7 +328,7 @@ type testBackend struct {
 	publisher                  *stream.EventPublisher
 	store                      *state.Store
 	authorizer                 func(token string, entMeta *acl.EnterpriseMeta) acl.Authorizer
-	resolveTokenAndDefaultMeta func(token string, entMeta *acl.EnterpriseMeta, _ *acl.AuthorizerContext) (acl.Authorizer, error)
+	resolveTokenAndDefaultMeta func(token string, entMeta *acl.EnterpriseMeta, _ *acl.AuthorizerContext) (resolver.Result, error)
 	forwardConn                *gogrpc.ClientConn
 }
 
@@ -335,11 +336,11 @@ func (b testBackend) ResolveTokenAndDefaultMeta(
 	token string,
 	entMeta *acl.EnterpriseMeta,
 	authCtx *acl.AuthorizerContext,
-) (acl.Authorizer, error) {
+) (resolver.Result, error) {
 	if b.resolveTokenAndDefaultMeta != nil {
 		return b.resolveTokenAndDefaultMeta(token, entMeta, authCtx)
 	}
-	return b.authorizer(token, entMeta), nil
+	return resolver.Result{Authorizer: b.authorizer(token, entMeta)}, nil
 }
 
 func (b testBackend) Forward(_ structs.RPCInfo, fn func(*gogrpc.ClientConn) error) (handled bool, err error) {
@@ -1009,8 +1010,8 @@ node "node1" {
 	go recvEvents(chEvents, streamHandle)
 
 	// Stub out token authn function so that the token is no longer considered valid.
-	backend.resolveTokenAndDefaultMeta = func(t string, entMeta *acl.EnterpriseMeta, _ *acl.AuthorizerContext) (acl.Authorizer, error) {
-		return nil, fmt.Errorf("ACL not found")
+	backend.resolveTokenAndDefaultMeta = func(t string, entMeta *acl.EnterpriseMeta, _ *acl.AuthorizerContext) (resolver.Result, error) {
+		return resolver.Result{}, fmt.Errorf("ACL not found")
 	}
 
 	testutil.RunStep(t, "invalid token should return an error", func(t *testing.T) {
@@ -1034,6 +1035,53 @@ node "node1" {
 	})
 }
 
+func TestServer_Subscribe_IntegrationWithBackend_ACLConditionsChange(t *testing.T) {
+	backend := newTestBackend(t)
+	addr := runTestServer(t, NewServer(backend, hclog.New(nil)))
+
+	// The token's policy has a time window that ends shortly.
+	validUntil := time.Now().Add(500 * time.Millisecond)
+	backend.resolveTokenAndDefaultMeta = func(string, *acl.EnterpriseMeta, *acl.AuthorizerContext) (resolver.Result, error) {
+		return resolver.Result{Authorizer: acl.AllowAll(), ValidUntil: validUntil}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	//nolint:staticcheck
+	conn, err := gogrpc.DialContext(ctx, addr.String(), gogrpc.WithInsecure())
+	require.NoError(t, err)
+	t.Cleanup(logError(t, conn.Close))
+
+	chEvents := make(chan eventOrError)
+	streamClient := pbsubscribe.NewStateChangeSubscriptionClient(conn)
+	streamHandle, err := streamClient.Subscribe(ctx, &pbsubscribe.SubscribeRequest{
+		Topic: pbsubscribe.Topic_ServiceHealth,
+		Subject: &pbsubscribe.SubscribeRequest_NamedSubject{
+			NamedSubject: &pbsubscribe.NamedSubject{
+				Key: "foo",
+			},
+		},
+		Token: "window-token",
+	})
+	require.NoError(t, err)
+
+	go recvEvents(chEvents, streamHandle)
+	require.True(t, getEvent(t, chEvents).GetEndOfSnapshot())
+
+	// The stream is closed once the window ends, even though no ACLs changed,
+	// so the client re-subscribes with a freshly resolved token.
+	select {
+	case item := <-chEvents:
+		require.Error(t, item.err, "got event instead of an error: %v", item.event)
+		s, _ := status.FromError(item.err)
+		require.Equal(t, codes.Aborted, s.Code())
+		require.False(t, time.Now().Before(validUntil))
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout waiting for aborted error")
+	}
+}
+
 func assertNoEvents(t *testing.T, chEvents chan eventOrError) {
 	t.Helper()
 	select {
@@ -234,7 +234,7 @@ func (s *HTTPHandlers) handler() http.Handler {
 			var token string
 			s.parseToken(req, &token)
 
-			authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+			authz, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, requestAuthzContext(req))
 			if err != nil {
 				resp.Header().Set(contentTypeHeader, plainContentType)
 				resp.WriteHeader(http.StatusForbidden)
@@ -267,6 +267,8 @@ func (s *HTTPHandlers) handler() http.Handler {
 		handleFuncMetrics(pattern, s.wrap(bound, methods))
 	}
 
+	handleFuncMetrics(dnsOverHTTPSPath, s.DNSQuery)
+
 	handlePProf("/debug/pprof/", pprof.Index)
 	handlePProf("/debug/pprof/cmdline", pprof.Cmdline)
 	handlePProf("/debug/pprof/profile", pprof.Profile)
@@ -1142,6 +1144,27 @@ func sourceAddrFromRequest(req *http.Request) string {
 	}
 }
 
+// requestContext describes req for evaluating the conditions of the ACL
+// rules of its token. Unlike sourceAddrFromRequest it ignores
+// X-Forwarded-For, which is set by the client, so that source_cidrs
+// conditions can't be met by spoofing it.
+func requestContext(req *http.Request) *acl.RequestContext {
+	rc := &acl.RequestContext{
+		Time:    time.Now(),
+		Headers: req.Header,
+	}
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		rc.SourceAddr = net.ParseIP(host)
+	}
+	return rc
+}
+
+// requestAuthzContext returns an authorizer context for resolving the token
+// of req.
+func requestAuthzContext(req *http.Request) *acl.AuthorizerContext {
+	return &acl.AuthorizerContext{Request: requestContext(req)}
+}
+
 // parseSource is used to parse the ?near=<node> query parameter, used for
 // sorting by RTT based on a source node. We set the source's DC to the target
 // DC in the request, if given, or else the agent's DC.
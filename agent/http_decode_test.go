@@ -36,6 +36,7 @@ package agent
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -1037,6 +1038,92 @@ func TestDecodeAgentRegisterCheck(t *testing.T) {
 		}
 	}
 
+	t.Run("http assertions", func(t *testing.T) {
+		body := bytes.NewBufferString(`{
+			"http_assertions": [
+				{"json_path": "$.status", "value": "ok", "status": "warning"},
+				{"Header": "X-Consul-Index"},
+				{"max_latency": "500ms"},
+				{"MaxLatency": 2000000000}
+			]
+		}`)
+
+		var out structs.CheckDefinition
+		if err := decodeBody(body, &out); err != nil {
+			t.Fatal(err)
+		}
+		want := []structs.HTTPCheckAssertion{
+			{JSONPath: "$.status", Value: "ok", Status: "warning"},
+			{Header: "X-Consul-Index"},
+			{MaxLatency: 500 * time.Millisecond},
+			{MaxLatency: 2 * time.Second},
+		}
+		if !reflect.DeepEqual(out.HTTPAssertions, want) {
+			t.Fatalf("expected HTTPAssertions %v, got %v", want, out.HTTPAssertions)
+		}
+	})
+
+	t.Run("tls expiry days", func(t *testing.T) {
+		body := bytes.NewBufferString(`{
+			"tls": "example.com:443",
+			"tls_expiry_warning_days": 14,
+			"tls_expiry_critical_days": 3
+		}`)
+
+		var out structs.CheckDefinition
+		if err := decodeBody(body, &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.TLS != "example.com:443" || out.TLSExpiryWarningDays != 14 || out.TLSExpiryCriticalDays != 3 {
+			t.Fatalf("bad: %#v", out)
+		}
+	})
+
+	t.Run("dns", func(t *testing.T) {
+		body := bytes.NewBufferString(`{
+			"dns": "db.example.com",
+			"dns_query_type": "AAAA",
+			"dns_resolver": "10.0.0.53",
+			"dns_expected_values": ["fd00::1"],
+			"dns_rcode": "NOERROR",
+			"dns_max_latency": "250ms"
+		}`)
+
+		var out structs.CheckDefinition
+		if err := decodeBody(body, &out); err != nil {
+			t.Fatal(err)
+		}
+		want := structs.CheckDefinition{
+			DNS:               "db.example.com",
+			DNSQueryType:      "AAAA",
+			DNSResolver:       "10.0.0.53",
+			DNSExpectedValues: []string{"fd00::1"},
+			DNSRcode:          "NOERROR",
+			DNSMaxLatency:     250 * time.Millisecond,
+		}
+		if !reflect.DeepEqual(out, want) {
+			t.Fatalf("expected %#v, got %#v", want, out)
+		}
+	})
+
+	t.Run("depends on", func(t *testing.T) {
+		body := bytes.NewBufferString(`{
+			"ttl": "10s",
+			"depends_on": ["node-disk", "node-network"]
+		}`)
+
+		var out structs.CheckDefinition
+		if err := decodeBody(body, &out); err != nil {
+			t.Fatal(err)
+		}
+		want := structs.CheckDefinition{
+			TTL:       10 * time.Second,
+			DependsOn: []types.CheckID{"node-disk", "node-network"},
+		}
+		if !reflect.DeepEqual(out, want) {
+			t.Fatalf("expected %#v, got %#v", want, out)
+		}
+	})
 }
 
 // structs.ServiceDefinition
@@ -13,6 +13,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/consul/agent/structs"
 	"github.com/hashicorp/consul/api"
@@ -91,6 +92,24 @@ func (s *HTTPHandlers) KVSGet(resp http.ResponseWriter, req *http.Request, args
 		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "Missing key name"}
 	}
 
+	// Check for a point-in-time read or the revision history
+	if _, ok := params["at-index"]; ok {
+		if method != "KVS.Get" {
+			return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "at-index cannot be used with recurse"}
+		}
+		atIndex, err := strconv.ParseUint(params.Get("at-index"), 10, 64)
+		if err != nil || atIndex == 0 {
+			return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "Invalid at-index: must be a positive integer"}
+		}
+		args.AtIndex = atIndex
+	}
+	if _, ok := params["revisions"]; ok {
+		if method != "KVS.Get" || args.AtIndex != 0 {
+			return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "revisions cannot be used with recurse or at-index"}
+		}
+		return s.kvsGetRevisions(resp, req, args)
+	}
+
 	// Do not allow wildcard NS on GET reqs
 	if method == "KVS.Get" {
 		if err := s.parseEntMetaNoWildcard(req, &args.EnterpriseMeta); err != nil {
@@ -102,9 +121,17 @@ func (s *HTTPHandlers) KVSGet(resp http.ResponseWriter, req *http.Request, args
 		}
 	}
 
-	// Make the RPC
-	var out structs.IndexedDirEntries
-	if err := s.agent.RPC(req.Context(), method, args, &out); err != nil {
+	// Make the RPC, which may be served by the streaming backend
+	var (
+		out structs.IndexedDirEntries
+		err error
+	)
+	if method == "KVS.List" {
+		out, _, err = s.agent.rpcClientKV.List(req.Context(), *args)
+	} else {
+		out, _, err = s.agent.rpcClientKV.Get(req.Context(), *args)
+	}
+	if err != nil {
 		return nil, err
 	}
 	setMeta(resp, &out.QueryMeta)
@@ -136,6 +163,25 @@ func (s *HTTPHandlers) KVSGet(resp http.ResponseWriter, req *http.Request, args
 	return out.Entries, nil
 }
 
+// kvsGetRevisions handles a GET request for the revision history of a key
+func (s *HTTPHandlers) kvsGetRevisions(resp http.ResponseWriter, req *http.Request, args *structs.KeyRequest) (interface{}, error) {
+	if err := s.parseEntMetaNoWildcard(req, &args.EnterpriseMeta); err != nil {
+		return nil, err
+	}
+
+	var out structs.IndexedDirEntryRevisions
+	if err := s.agent.RPC(req.Context(), "KVS.Revisions", args, &out); err != nil {
+		return nil, err
+	}
+	setMeta(resp, &out.QueryMeta)
+
+	if len(out.Revisions) == 0 {
+		resp.WriteHeader(http.StatusNotFound)
+		return nil, nil
+	}
+	return out.Revisions, nil
+}
+
 // KVSGetKeys handles a GET request for keys
 func (s *HTTPHandlers) KVSGetKeys(resp http.ResponseWriter, req *http.Request, args *structs.KeyRequest) (interface{}, error) {
 	if err := s.parseEntMeta(req, &args.EnterpriseMeta); err != nil {
@@ -216,6 +262,18 @@ func (s *HTTPHandlers) KVSPut(resp http.ResponseWriter, req *http.Request, args
 		applyReq.DirEnt.Flags = flagVal
 	}
 
+	// Check for an expiration TTL
+	if _, ok := params["ttl"]; ok {
+		ttl, err := time.ParseDuration(params.Get("ttl"))
+		if err != nil {
+			return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Invalid ttl: %v", err)}
+		}
+		if ttl <= 0 {
+			return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "Invalid ttl: must be positive"}
+		}
+		applyReq.DirEnt.ExpirationTTL = ttl
+	}
+
 	// Check for cas value
 	if _, ok := params["cas"]; ok {
 		casVal, err := strconv.ParseUint(params.Get("cas"), 10, 64)
@@ -283,6 +341,9 @@ func (s *HTTPHandlers) KVSPut(resp http.ResponseWriter, req *http.Request, args
 	// Make the RPC
 	var out bool
 	if err := s.agent.RPC(req.Context(), "KVS.Apply", &applyReq, &out); err != nil {
+		if structs.IsErrKVSQuotaExceeded(err) {
+			return nil, HTTPError{StatusCode: http.StatusRequestEntityTooLarge, Reason: err.Error()}
+		}
 		return nil, err
 	}
 
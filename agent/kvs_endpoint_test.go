@@ -13,8 +13,12 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
 
 	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/testrpc"
 )
 
@@ -312,6 +316,223 @@ func TestKVSEndpoint_CAS(t *testing.T) {
 	}
 }
 
+func TestKVSEndpoint_TTL(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+
+	{
+		buf := bytes.NewBuffer([]byte("test"))
+		req, _ := http.NewRequest("PUT", "/v1/kv/test?ttl=1h", buf)
+		resp := httptest.NewRecorder()
+		obj, err := a.srv.KVSEndpoint(resp, req)
+		require.NoError(t, err)
+		require.True(t, obj.(bool))
+	}
+
+	req, _ := http.NewRequest("GET", "/v1/kv/test", nil)
+	resp := httptest.NewRecorder()
+	obj, err := a.srv.KVSEndpoint(resp, req)
+	require.NoError(t, err)
+	d := obj.(structs.DirEntries)[0]
+	require.NotNil(t, d.ExpirationTime)
+	require.WithinDuration(t, time.Now().Add(time.Hour), *d.ExpirationTime, time.Minute)
+
+	for _, ttl := range []string{"nope", "-1s", "0s"} {
+		buf := bytes.NewBuffer([]byte("test"))
+		req, _ := http.NewRequest("PUT", "/v1/kv/test?ttl="+ttl, buf)
+		resp := httptest.NewRecorder()
+		_, err := a.srv.KVSEndpoint(resp, req)
+		require.Error(t, err, ttl)
+		require.True(t, isHTTPBadRequest(err), ttl)
+	}
+}
+
+func TestKVSEndpoint_History(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	{
+		body := bytes.NewBuffer([]byte(`{"Prefix": "app/", "Revisions": 5}`))
+		req, _ := http.NewRequest("PUT", "/v1/operator/kv/history", body)
+		resp := httptest.NewRecorder()
+		obj, err := a.srv.OperatorKVHistoryConfiguration(resp, req)
+		require.NoError(t, err)
+		require.True(t, obj.(bool))
+	}
+
+	var indexes []uint64
+	for _, v := range []string{"1", "2"} {
+		buf := bytes.NewBuffer([]byte(v))
+		req, _ := http.NewRequest("PUT", "/v1/kv/app/key", buf)
+		resp := httptest.NewRecorder()
+		_, err := a.srv.KVSEndpoint(resp, req)
+		require.NoError(t, err)
+
+		req, _ = http.NewRequest("GET", "/v1/kv/app/key", nil)
+		resp = httptest.NewRecorder()
+		obj, err := a.srv.KVSEndpoint(resp, req)
+		require.NoError(t, err)
+		indexes = append(indexes, obj.(structs.DirEntries)[0].ModifyIndex)
+	}
+
+	// List the revisions.
+	{
+		req, _ := http.NewRequest("GET", "/v1/kv/app/key?revisions", nil)
+		resp := httptest.NewRecorder()
+		obj, err := a.srv.KVSEndpoint(resp, req)
+		require.NoError(t, err)
+		revs := obj.([]*structs.DirEntryRevision)
+		require.Len(t, revs, 2)
+		require.Equal(t, "1", string(revs[0].Value))
+		require.Equal(t, indexes[1], revs[0].SupersededIndex)
+		require.Equal(t, "2", string(revs[1].Value))
+	}
+
+	// Read the first value back.
+	{
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/kv/app/key?at-index=%d", indexes[0]), nil)
+		resp := httptest.NewRecorder()
+		obj, err := a.srv.KVSEndpoint(resp, req)
+		require.NoError(t, err)
+		require.Equal(t, "1", string(obj.(structs.DirEntries)[0].Value))
+	}
+
+	// A key without any revisions is not found.
+	{
+		req, _ := http.NewRequest("GET", "/v1/kv/missing?revisions", nil)
+		resp := httptest.NewRecorder()
+		obj, err := a.srv.KVSEndpoint(resp, req)
+		require.NoError(t, err)
+		require.Nil(t, obj)
+		require.Equal(t, http.StatusNotFound, resp.Code)
+	}
+
+	// Invalid combinations are rejected.
+	for _, url := range []string{
+		"/v1/kv/app/key?at-index=nope",
+		"/v1/kv/app/key?at-index=0",
+		"/v1/kv/app/?recurse&at-index=1",
+		"/v1/kv/app/key?revisions&at-index=1",
+	} {
+		req, _ := http.NewRequest("GET", url, nil)
+		resp := httptest.NewRecorder()
+		_, err := a.srv.KVSEndpoint(resp, req)
+		require.True(t, isHTTPBadRequest(err), url)
+	}
+
+	// The configuration can be listed and removed.
+	{
+		req, _ := http.NewRequest("GET", "/v1/operator/kv/history", nil)
+		resp := httptest.NewRecorder()
+		obj, err := a.srv.OperatorKVHistoryConfiguration(resp, req)
+		require.NoError(t, err)
+		configs := obj.([]api.KVHistoryConfig)
+		require.Len(t, configs, 1)
+		require.Equal(t, "app/", configs[0].Prefix)
+		require.Equal(t, 5, configs[0].Revisions)
+
+		req, _ = http.NewRequest("DELETE", "/v1/operator/kv/history?prefix=app/", nil)
+		resp = httptest.NewRecorder()
+		_, err = a.srv.OperatorKVHistoryConfiguration(resp, req)
+		require.NoError(t, err)
+
+		req, _ = http.NewRequest("GET", "/v1/operator/kv/history", nil)
+		resp = httptest.NewRecorder()
+		obj, err = a.srv.OperatorKVHistoryConfiguration(resp, req)
+		require.NoError(t, err)
+		require.Empty(t, obj.([]api.KVHistoryConfig))
+	}
+
+	{
+		body := bytes.NewBuffer([]byte(`{"Prefix": "app/", "Revisions": 0}`))
+		req, _ := http.NewRequest("PUT", "/v1/operator/kv/history", body)
+		resp := httptest.NewRecorder()
+		_, err := a.srv.OperatorKVHistoryConfiguration(resp, req)
+		require.True(t, isHTTPBadRequest(err))
+	}
+}
+
+func TestKVSEndpoint_Quota(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	{
+		body := bytes.NewBuffer([]byte(`{"Prefix": "app/", "MaxKeys": 1}`))
+		req, _ := http.NewRequest("PUT", "/v1/operator/kv/quota", body)
+		resp := httptest.NewRecorder()
+		obj, err := a.srv.OperatorKVQuota(resp, req)
+		require.NoError(t, err)
+		require.True(t, obj.(bool))
+	}
+
+	{
+		req, _ := http.NewRequest("GET", "/v1/operator/kv/quota", nil)
+		resp := httptest.NewRecorder()
+		obj, err := a.srv.OperatorKVQuota(resp, req)
+		require.NoError(t, err)
+		quotas := obj.([]api.KVQuota)
+		require.Len(t, quotas, 1)
+		require.Equal(t, "app/", quotas[0].Prefix)
+		require.Equal(t, 1, quotas[0].MaxKeys)
+	}
+
+	{
+		req, _ := http.NewRequest("PUT", "/v1/kv/app/one", bytes.NewBuffer([]byte("1")))
+		resp := httptest.NewRecorder()
+		_, err := a.srv.KVSEndpoint(resp, req)
+		require.NoError(t, err)
+	}
+
+	// Exceeding the quota is reported as a 413.
+	{
+		req, _ := http.NewRequest("PUT", "/v1/kv/app/two", bytes.NewBuffer([]byte("2")))
+		resp := httptest.NewRecorder()
+		_, err := a.srv.KVSEndpoint(resp, req)
+		require.Error(t, err)
+		httpErr, ok := err.(HTTPError)
+		require.True(t, ok, "err: %v", err)
+		require.Equal(t, http.StatusRequestEntityTooLarge, httpErr.StatusCode)
+		require.Contains(t, httpErr.Reason, "KV quota exceeded")
+	}
+
+	{
+		body := bytes.NewBuffer([]byte(`{"Prefix": "app/", "MaxKeys": -1}`))
+		req, _ := http.NewRequest("PUT", "/v1/operator/kv/quota", body)
+		resp := httptest.NewRecorder()
+		_, err := a.srv.OperatorKVQuota(resp, req)
+		require.True(t, isHTTPBadRequest(err))
+	}
+
+	{
+		req, _ := http.NewRequest("DELETE", "/v1/operator/kv/quota?prefix=app/", nil)
+		resp := httptest.NewRecorder()
+		_, err := a.srv.OperatorKVQuota(resp, req)
+		require.NoError(t, err)
+
+		req, _ = http.NewRequest("PUT", "/v1/kv/app/two", bytes.NewBuffer([]byte("2")))
+		resp = httptest.NewRecorder()
+		_, err = a.srv.KVSEndpoint(resp, req)
+		require.NoError(t, err)
+	}
+}
+
 func TestKVSEndpoint_ListKeys(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -1299,3 +1520,96 @@ func TestKVSEndpoint_PathCleaningEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestKVSEndpoint_StreamingBackend(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		rpc {
+			enable_streaming = true
+		}
+		use_streaming_backend = true
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	put := func(key, value string) {
+		t.Helper()
+		req, _ := http.NewRequest("PUT", "/v1/kv/"+key, bytes.NewBuffer([]byte(value)))
+		resp := httptest.NewRecorder()
+		obj, err := a.srv.KVSEndpoint(resp, req)
+		require.NoError(t, err)
+		require.True(t, obj.(bool))
+	}
+
+	put("app/one", "1")
+
+	req, _ := http.NewRequest("GET", "/v1/kv/app/?recurse", nil)
+	resp := httptest.NewRecorder()
+	_, err := a.srv.KVSEndpoint(resp, req)
+	require.NoError(t, err)
+	require.Equal(t, "blocking-query", resp.Header().Get("X-Consul-Query-Backend"))
+	index := resp.Header().Get("X-Consul-Index")
+
+	type result struct {
+		entries structs.DirEntries
+		backend string
+		err     error
+	}
+	blockingGet := func(url string) chan result {
+		ch := make(chan result, 1)
+		go func() {
+			req, _ := http.NewRequest("GET", url, nil)
+			resp := httptest.NewRecorder()
+			obj, err := a.srv.KVSEndpoint(resp, req)
+			entries, _ := obj.(structs.DirEntries)
+			ch <- result{entries, resp.Header().Get("X-Consul-Query-Backend"), err}
+		}()
+		return ch
+	}
+
+	check := func(ch chan result, key, value string) {
+		t.Helper()
+		select {
+		case res := <-ch:
+			require.NoError(t, res.err)
+			require.Equal(t, "streaming", res.backend)
+			require.NotEmpty(t, res.entries)
+			require.Equal(t, key, res.entries[len(res.entries)-1].Key)
+			require.Equal(t, []byte(value), res.entries[len(res.entries)-1].Value)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for blocking query")
+		}
+	}
+
+	// Writes outside the prefix must not wake the watcher.
+	listCh := blockingGet("/v1/kv/app/?recurse&wait=5s&index=" + index)
+	time.Sleep(100 * time.Millisecond)
+	put("other", "x")
+	put("app/two", "2")
+	check(listCh, "app/two", "2")
+
+	req, _ = http.NewRequest("GET", "/v1/kv/app/two", nil)
+	resp = httptest.NewRecorder()
+	_, err = a.srv.KVSEndpoint(resp, req)
+	require.NoError(t, err)
+	index = resp.Header().Get("X-Consul-Index")
+
+	keyCh := blockingGet("/v1/kv/app/two?wait=5s&index=" + index)
+	time.Sleep(100 * time.Millisecond)
+	put("app/two", "3")
+	check(keyCh, "app/two", "3")
+
+	// The prefix view holds both keys, sorted.
+	req, _ = http.NewRequest("GET", "/v1/kv/app/?recurse&index=1", nil)
+	resp = httptest.NewRecorder()
+	obj, err := a.srv.KVSEndpoint(resp, req)
+	require.NoError(t, err)
+	entries := obj.(structs.DirEntries)
+	require.Len(t, entries, 2)
+	require.Equal(t, "app/one", entries[0].Key)
+	require.Equal(t, "app/two", entries[1].Key)
+}
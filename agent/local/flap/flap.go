@@ -0,0 +1,110 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package flap detects the health checks that are flapping.
+package flap
+
+const (
+	// DefaultHistorySize is the number of results of a check the flap
+	// score is computed over, unless set.
+	DefaultHistorySize = 20
+
+	// DefaultLowThreshold is the flap score below which a flapping check
+	// stops flapping, unless set.
+	DefaultLowThreshold = 0.25
+
+	// DefaultHighThreshold is the flap score from which a check starts
+	// flapping, unless set.
+	DefaultHighThreshold = 0.5
+)
+
+// Detector keeps the history of the latest results of a check to detect
+// when it is flapping, that is changing status too often for its status to
+// be meaningful.
+// A check starts flapping when its flap score reaches HighThreshold and stops
+// flapping when it goes below LowThreshold, so that a check that is about to
+// stabilize doesn't start and stop flapping on every result.
+// It is not safe for concurrent use.
+type Detector struct {
+	HistorySize   int
+	LowThreshold  float64
+	HighThreshold float64
+
+	history  []string
+	flapping bool
+}
+
+// Record adds the status of the latest result of the check to the history.
+// It returns whether the check is flapping and whether this result made it
+// start or stop flapping.
+func (d *Detector) Record(status string) (flapping, changed bool) {
+	d.history = append(d.history, status)
+	if size := d.historySize(); len(d.history) > size {
+		d.history = d.history[len(d.history)-size:]
+	}
+
+	score := d.Score()
+	switch {
+	case !d.flapping && score >= d.highThreshold():
+		d.flapping = true
+		changed = true
+	case d.flapping && score < d.lowThreshold():
+		d.flapping = false
+		changed = true
+	}
+	return d.flapping, changed
+}
+
+// Flapping returns whether the check is flapping.
+func (d *Detector) Flapping() bool {
+	return d.flapping
+}
+
+// Score returns the flap score of the check, from 0 when its status didn't
+// change over the history to 1 when it changed on every result. Recent
+// changes weigh more than older ones, from 0.8 for the oldest to 1.2 for the
+// most recent. Changes that are missing because the history is not full yet
+// count as no change so that a new check doesn't flap on its first results.
+func (d *Detector) Score() float64 {
+	transitions := d.historySize() - 1
+	if transitions <= 0 {
+		return 0
+	}
+
+	// The most recent change of a full history is at position
+	// transitions-1.
+	offset := transitions - (len(d.history) - 1)
+	var score float64
+	for i := 1; i < len(d.history); i++ {
+		if d.history[i] == d.history[i-1] {
+			continue
+		}
+		weight := 1.0
+		if transitions > 1 {
+			weight = 0.8 + 0.4*float64(offset+i-1)/float64(transitions-1)
+		}
+		score += weight
+	}
+	return score / float64(transitions)
+}
+
+func (d *Detector) historySize() int {
+	if d.HistorySize <= 0 {
+		return DefaultHistorySize
+	}
+	return d.HistorySize
+}
+
+func (d *Detector) lowThreshold() float64 {
+	if d.LowThreshold <= 0 {
+		return DefaultLowThreshold
+	}
+	return d.LowThreshold
+}
+
+func (d *Detector) highThreshold() float64 {
+	if d.HighThreshold <= 0 {
+		return DefaultHighThreshold
+	}
+	return d.HighThreshold
+}
@@ -0,0 +1,80 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package flap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/api"
+)
+
+func TestDetector(t *testing.T) {
+	t.Parallel()
+
+	d := &Detector{HistorySize: 11, LowThreshold: 0.25, HighThreshold: 0.5}
+
+	// A stable check doesn't flap.
+	for i := 0; i < 11; i++ {
+		flapping, changed := d.Record(api.HealthPassing)
+		require.False(t, flapping)
+		require.False(t, changed)
+	}
+	require.Equal(t, 0.0, d.Score())
+
+	// It starts flapping once its status changed on enough results.
+	statuses := []string{api.HealthCritical, api.HealthPassing}
+	var started int
+	for i := 0; i < 10; i++ {
+		flapping, changed := d.Record(statuses[i%2])
+		if changed {
+			require.True(t, flapping)
+			started = i + 1
+			break
+		}
+	}
+	require.Equal(t, 5, started)
+	require.True(t, d.Flapping())
+	require.GreaterOrEqual(t, d.Score(), 0.5)
+
+	// It keeps flapping until enough changes left the history for its score to
+	// go below the low threshold.
+	var stopped int
+	for i := 0; i < 10; i++ {
+		flapping, changed := d.Record(api.HealthPassing)
+		if changed {
+			require.False(t, flapping)
+			stopped = i + 1
+			break
+		}
+	}
+	require.Equal(t, 9, stopped)
+	require.False(t, d.Flapping())
+	require.Less(t, d.Score(), 0.25)
+}
+
+func TestDetector_Score(t *testing.T) {
+	t.Parallel()
+
+	// A status changing on every result of a full history scores 1.
+	d := &Detector{HistorySize: 5}
+	for i := 0; i < 5; i++ {
+		d.Record([]string{api.HealthPassing, api.HealthWarning}[i%2])
+	}
+	require.InDelta(t, 1.0, d.Score(), 1e-9)
+
+	// The missing results of a new check count as no change.
+	d = &Detector{HistorySize: 5}
+	d.Record(api.HealthPassing)
+	d.Record(api.HealthCritical)
+	require.InDelta(t, 1.2/4, d.Score(), 1e-9)
+
+	// Older changes weigh less than recent ones.
+	d = &Detector{HistorySize: 5}
+	for _, status := range []string{api.HealthPassing, api.HealthCritical, api.HealthCritical, api.HealthCritical, api.HealthCritical} {
+		d.Record(status)
+	}
+	require.InDelta(t, 0.8/4, d.Score(), 1e-9)
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/hashicorp/consul/acl"
 	"github.com/hashicorp/consul/acl/resolver"
+	"github.com/hashicorp/consul/agent/local/flap"
 	"github.com/hashicorp/consul/agent/structs"
 	"github.com/hashicorp/consul/agent/token"
 	"github.com/hashicorp/consul/api"
@@ -50,6 +51,21 @@ var StateCounters = []prometheus.CounterDefinition{
 		Name: []string{"acl", "blocked", "node", "registration"},
 		Help: "Increments whenever a registration fails for a node (blocked by an ACL)",
 	},
+	{
+		Name: []string{"agent", "check", "flapping", "started"},
+		Help: "Increments whenever a check starts flapping and its status is held steady",
+	},
+	{
+		Name: []string{"agent", "check", "flapping", "stopped"},
+		Help: "Increments whenever a check stops flapping",
+	},
+}
+
+var StateGauges = []prometheus.GaugeDefinition{
+	{
+		Name: []string{"agent", "check", "flapping"},
+		Help: "Is 1 while a check of the agent is flapping and its status is held steady, 0 otherwise",
+	},
 }
 
 const fullSyncReadMaxStale = 2 * time.Second
@@ -65,6 +81,14 @@ type Config struct {
 	NodeLocality        *structs.Locality
 	Partition           string // this defaults if empty
 	TaggedAddresses     map[string]string
+
+	// CheckFlapDetection enables holding the status of the checks that are
+	// flapping steady. The thresholds and history size default to the ones
+	// of flap.Detector when zero.
+	CheckFlapDetection     bool
+	CheckFlapHistorySize   int
+	CheckFlapLowThreshold  float64
+	CheckFlapHighThreshold float64
 }
 
 // ServiceState describes the state of a service record.
@@ -136,6 +160,10 @@ type CheckState struct {
 	// IsLocallyDefined indicates whether the check was defined locally in config
 	// as opposed to being registered through the Agent API.
 	IsLocallyDefined bool
+
+	// flap keeps the history of the statuses of the check to detect when it
+	// is flapping. It is shared by the clones of the check state.
+	flap *flap.Detector
 }
 
 // Clone returns a shallow copy of the object.
@@ -663,6 +691,14 @@ func (l *State) removeCheckLocked(id structs.CheckID) error {
 	c.Deleted = true
 	l.TriggerSyncChanges()
 
+	// A removed check is not flapping anymore.
+	if c.Check.Flapping {
+		metrics.SetGaugeWithLabels([]string{"agent", "check", "flapping"}, 0, checkFlappingLabels(c.Check))
+	}
+
+	// The checks depending on this one are no longer suppressed by it.
+	l.updateSuppressedChecksLocked()
+
 	return nil
 }
 
@@ -680,6 +716,13 @@ func (l *State) UpdateCheck(id structs.CheckID, status, output string) {
 		output = ""
 	}
 
+	// Hold the status of a flapping check steady so that it doesn't cause
+	// a sync on every change.
+	flapping := false
+	if l.config.CheckFlapDetection {
+		status, output, flapping = l.dampFlappingLocked(c, status, output)
+	}
+
 	// Update the critical time tracking (this doesn't cause a server updates
 	// so we can always keep this up to date).
 	if status == api.HealthCritical {
@@ -691,10 +734,17 @@ func (l *State) UpdateCheck(id structs.CheckID, status, output string) {
 	}
 
 	// Do nothing if update is idempotent
-	if c.Check.Status == status && c.Check.Output == output {
+	if c.Check.Status == status && c.Check.Output == output && c.Check.Flapping == flapping {
 		return
 	}
 
+	// A change of status may suppress or restore the checks depending on
+	// this one. This must run after the deferred update of the checks map
+	// below, hence it is deferred first.
+	if c.Check.Status != status {
+		defer l.updateSuppressedChecksLocked()
+	}
+
 	// Ensure we only mutate a copy of the check state and put the finalized
 	// version into the checks map when complete.
 	//
@@ -711,7 +761,7 @@ func (l *State) UpdateCheck(id structs.CheckID, status, output string) {
 	// frequent updates of output. Instead, we update the output internally,
 	// and periodically do a write-back to the servers. If there is a status
 	// change we do the write immediately.
-	if l.config.CheckUpdateInterval > 0 && c.Check.Status == status {
+	if l.config.CheckUpdateInterval > 0 && c.Check.Status == status && c.Check.Flapping == flapping {
 		c.Check.Output = output
 		if c.DeferCheck == nil {
 			d := l.config.CheckUpdateInterval
@@ -741,10 +791,140 @@ func (l *State) UpdateCheck(id structs.CheckID, status, output string) {
 	// Update status and mark out of sync
 	c.Check.Status = status
 	c.Check.Output = output
+	c.Check.Flapping = flapping
 	c.InSync = false
 	l.TriggerSyncChanges()
 }
 
+// dampFlappingLocked records the status of a check to detect when it is
+// flapping. While it is, it returns the status the check had when it started
+// flapping along with an output saying so, and the given status and output
+// otherwise.
+func (l *State) dampFlappingLocked(c *CheckState, status, output string) (string, string, bool) {
+	if c.flap == nil {
+		c.flap = &flap.Detector{
+			HistorySize:   l.config.CheckFlapHistorySize,
+			LowThreshold:  l.config.CheckFlapLowThreshold,
+			HighThreshold: l.config.CheckFlapHighThreshold,
+		}
+	}
+
+	flapping, changed := c.flap.Record(status)
+	if changed {
+		id := c.Check.CompoundCheckID()
+		labels := checkFlappingLabels(c.Check)
+		if flapping {
+			l.logger.Warn("Check started flapping, holding its status",
+				"check", id.String(),
+				"status", c.Check.Status,
+				"score", c.flap.Score(),
+			)
+			metrics.IncrCounterWithLabels([]string{"agent", "check", "flapping", "started"}, 1, labels)
+			metrics.SetGaugeWithLabels([]string{"agent", "check", "flapping"}, 1, labels)
+		} else {
+			l.logger.Info("Check stopped flapping",
+				"check", id.String(),
+				"status", status,
+			)
+			metrics.IncrCounterWithLabels([]string{"agent", "check", "flapping", "stopped"}, 1, labels)
+			metrics.SetGaugeWithLabels([]string{"agent", "check", "flapping"}, 0, labels)
+		}
+	}
+	if !flapping {
+		return status, output, false
+	}
+
+	held := c.Check.Status
+	output = fmt.Sprintf("Check is flapping (score %.2f), status held at %s. Last status %s: %s",
+		c.flap.Score(), held, status, output)
+	return held, output, true
+}
+
+// checkFlappingLabels returns the labels of the flapping metrics of check.
+func checkFlappingLabels(check *structs.HealthCheck) []metrics.Label {
+	labels := []metrics.Label{{Name: "check", Value: string(check.CheckID)}}
+	if check.ServiceID != "" {
+		labels = append(labels, metrics.Label{Name: "service", Value: check.ServiceID})
+	}
+	if ns := check.NamespaceOrEmpty(); ns != "" {
+		labels = append(labels, metrics.Label{Name: "namespace", Value: ns})
+	}
+	if ap := check.PartitionOrEmpty(); ap != "" {
+		labels = append(labels, metrics.Label{Name: "partition", Value: ap})
+	}
+	return labels
+}
+
+// updateSuppressedChecksLocked sets the SuppressedBy field of the checks
+// that are critical while one of the checks they depend on is critical too
+// to the ID of the check at the root of the failure, and clears it on the
+// other checks. The checks that change are marked out of sync.
+func (l *State) updateSuppressedChecksLocked() {
+	for id, c := range l.checks {
+		if c.Deleted || c.Check == nil {
+			continue
+		}
+		var root types.CheckID
+		if c.Check.Status == api.HealthCritical {
+			root = l.failureRootLocked(c, map[structs.CheckID]bool{id: true}, false)
+		}
+		if c.Check.SuppressedBy == root {
+			continue
+		}
+
+		c = c.Clone()
+		c.Check.SuppressedBy = root
+		c.InSync = false
+		l.checks[id] = c
+		l.TriggerSyncChanges()
+	}
+}
+
+// failureRootLocked returns the ID of the check at the root of the failure of
+// the given critical check, found by following its critical dependencies. The
+// root is a critical check that does not have any critical dependency itself,
+// and is the given check if self is set and it has none. An empty ID is
+// returned if there is no root, like when the critical dependencies only lead
+// to a cycle, so that the checks in a cycle are never all suppressed. path
+// holds the checks being walked.
+func (l *State) failureRootLocked(c *CheckState, path map[structs.CheckID]bool, self bool) types.CheckID {
+	var hasCritical bool
+	for _, dep := range c.Check.DependsOn {
+		id, d := l.dependencyLocked(c.Check, dep)
+		if d == nil || d.Check.Status != api.HealthCritical {
+			continue
+		}
+		hasCritical = true
+		if path[id] {
+			continue
+		}
+
+		path[id] = true
+		root := l.failureRootLocked(d, path, true)
+		delete(path, id)
+		if root != "" {
+			return root
+		}
+	}
+	if self && !hasCritical {
+		return c.Check.CheckID
+	}
+	return ""
+}
+
+// dependencyLocked returns the check a check depends on, looked up in the
+// enterprise meta of the check first and then in the one of the agent so that
+// service checks can depend on node checks.
+func (l *State) dependencyLocked(check *structs.HealthCheck, dep types.CheckID) (structs.CheckID, *CheckState) {
+	for _, entMeta := range []*acl.EnterpriseMeta{&check.EnterpriseMeta, &l.agentEnterpriseMeta} {
+		id := structs.NewCheckID(dep, entMeta)
+		if d := l.checks[id]; d != nil && !d.Deleted && d.Check != nil {
+			return id, d
+		}
+	}
+	return structs.CheckID{}, nil
+}
+
 // Check returns the locally registered check that the
 // agent is aware of and are being kept in sync with the server
 func (l *State) Check(id structs.CheckID) *structs.HealthCheck {
@@ -852,6 +1032,10 @@ func (l *State) setCheckStateLocked(c *CheckState) {
 	l.notifyIfAliased(c.Check.CompoundServiceID())
 
 	l.TriggerSyncChanges()
+
+	// The new check may depend on critical checks or be one that others
+	// depend on.
+	l.updateSuppressedChecksLocked()
 }
 
 // AllCheckStates returns a shallow copy of all health check state records.
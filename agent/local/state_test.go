@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-metrics"
 	"github.com/hashicorp/go-uuid"
 	"github.com/mitchellh/copystructure"
 	"github.com/stretchr/testify/assert"
@@ -2216,6 +2217,144 @@ func TestAgent_AddCheckFailure(t *testing.T) {
 	require.Equal(t, wantErr, got)
 }
 
+func TestAgent_CheckFlapping(t *testing.T) {
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	metricsCfg := metrics.DefaultConfig("consul")
+	metricsCfg.EnableHostname = false
+	metrics.NewGlobal(metricsCfg, sink)
+	flappingGauge := func() float32 {
+		return sink.Data()[0].Gauges["consul.agent.check.flapping;check=web"].Value
+	}
+
+	cfg := loadRuntimeConfig(t, `bind_addr = "127.0.0.1" data_dir = "dummy" node_name = "dummy"`)
+	lcfg := agent.LocalConfig(cfg)
+	lcfg.CheckFlapDetection = true
+	lcfg.CheckFlapHistorySize = 11
+	l := local.NewState(lcfg, testutil.Logger(t), new(token.Store))
+	l.TriggerSyncChanges = func() {}
+
+	checkID := structs.NewCheckID("web", nil)
+	require.NoError(t, l.AddCheck(&structs.HealthCheck{CheckID: "web", Status: api.HealthPassing}, "", false))
+	for i := 0; i < 11; i++ {
+		l.UpdateCheck(checkID, api.HealthPassing, "ok")
+	}
+
+	// The status is held once the check starts flapping.
+	statuses := []string{api.HealthCritical, api.HealthPassing}
+	for i := 0; i < 5; i++ {
+		l.UpdateCheck(checkID, statuses[i%2], "flip")
+	}
+	chk := l.Check(checkID)
+	require.True(t, chk.Flapping)
+	require.Equal(t, api.HealthPassing, chk.Status)
+	require.Contains(t, chk.Output, "Check is flapping")
+	require.Contains(t, chk.Output, "Last status critical: flip")
+	require.False(t, l.CheckState(checkID).InSync)
+	require.Equal(t, float32(1), flappingGauge())
+
+	l.UpdateCheck(checkID, api.HealthCritical, "flip")
+	chk = l.Check(checkID)
+	require.True(t, chk.Flapping)
+	require.Equal(t, api.HealthPassing, chk.Status)
+
+	// The latest status is reported once the check stops flapping.
+	for i := 0; i < 11; i++ {
+		l.UpdateCheck(checkID, api.HealthCritical, "down")
+	}
+	chk = l.Check(checkID)
+	require.False(t, chk.Flapping)
+	require.Equal(t, api.HealthCritical, chk.Status)
+	require.Equal(t, "down", chk.Output)
+	require.Equal(t, float32(0), flappingGauge())
+}
+
+func TestAgent_CheckDependencies(t *testing.T) {
+	t.Parallel()
+	cfg := loadRuntimeConfig(t, `bind_addr = "127.0.0.1" data_dir = "dummy" node_name = "dummy"`)
+	l := local.NewState(agent.LocalConfig(cfg), nil, new(token.Store))
+	l.TriggerSyncChanges = func() {}
+
+	require.NoError(t, l.AddServiceWithChecks(&structs.NodeService{ID: "web", Service: "web"}, nil, "", false))
+
+	// The service check depends on the disk check which depends on the
+	// network check.
+	require.NoError(t, l.AddCheck(&structs.HealthCheck{CheckID: "network", Status: api.HealthPassing}, "", false))
+	require.NoError(t, l.AddCheck(&structs.HealthCheck{
+		CheckID:   "disk",
+		Status:    api.HealthPassing,
+		DependsOn: []types.CheckID{"network"},
+	}, "", false))
+	require.NoError(t, l.AddCheck(&structs.HealthCheck{
+		CheckID:   "web",
+		ServiceID: "web",
+		Status:    api.HealthPassing,
+		DependsOn: []types.CheckID{"missing", "disk"},
+	}, "", false))
+
+	suppressedBy := func(id types.CheckID) types.CheckID {
+		t.Helper()
+		c := l.CheckState(structs.NewCheckID(id, nil))
+		require.NotNil(t, c)
+		return c.Check.SuppressedBy
+	}
+	setStatus := func(id types.CheckID, status string) {
+		l.UpdateCheck(structs.NewCheckID(id, nil), status, "")
+	}
+
+	// A critical check is not suppressed while its dependencies are passing.
+	setStatus("web", api.HealthCritical)
+	require.Equal(t, types.CheckID(""), suppressedBy("web"))
+
+	// It is suppressed by its dependency once it goes critical.
+	setStatus("disk", api.HealthCritical)
+	require.Equal(t, types.CheckID("disk"), suppressedBy("web"))
+	require.Equal(t, types.CheckID(""), suppressedBy("disk"))
+	require.Equal(t, api.HealthCritical, l.Check(structs.NewCheckID("web", nil)).Status)
+	require.False(t, l.CheckState(structs.NewCheckID("web", nil)).InSync)
+
+	// The root of the failure is reported when the dependencies are chained.
+	setStatus("network", api.HealthCritical)
+	require.Equal(t, types.CheckID("network"), suppressedBy("web"))
+	require.Equal(t, types.CheckID("network"), suppressedBy("disk"))
+	require.Equal(t, types.CheckID(""), suppressedBy("network"))
+
+	// A passing check is never suppressed.
+	setStatus("disk", api.HealthPassing)
+	require.Equal(t, types.CheckID(""), suppressedBy("web"))
+	require.Equal(t, types.CheckID(""), suppressedBy("disk"))
+
+	setStatus("disk", api.HealthCritical)
+	require.Equal(t, types.CheckID("network"), suppressedBy("web"))
+
+	// The dependents are restored when a dependency is removed.
+	require.NoError(t, l.RemoveCheck(structs.NewCheckID("network", nil)))
+	require.Equal(t, types.CheckID("disk"), suppressedBy("web"))
+	require.Equal(t, types.CheckID(""), suppressedBy("disk"))
+
+	// The checks in a dependency cycle have no root, so none of them are
+	// suppressed.
+	require.NoError(t, l.AddCheck(&structs.HealthCheck{
+		CheckID:   "network",
+		Status:    api.HealthCritical,
+		DependsOn: []types.CheckID{"web"},
+	}, "", false))
+	require.Equal(t, types.CheckID(""), suppressedBy("web"))
+	require.Equal(t, types.CheckID(""), suppressedBy("disk"))
+	require.Equal(t, types.CheckID(""), suppressedBy("network"))
+
+	// Unless one of them depends on a check outside of the cycle.
+	require.NoError(t, l.AddCheck(&structs.HealthCheck{CheckID: "uplink", Status: api.HealthCritical}, "", false))
+	require.NoError(t, l.AddCheck(&structs.HealthCheck{
+		CheckID:   "network",
+		Status:    api.HealthCritical,
+		DependsOn: []types.CheckID{"web", "uplink"},
+	}, "", false))
+	require.Equal(t, types.CheckID("uplink"), suppressedBy("web"))
+	require.Equal(t, types.CheckID("uplink"), suppressedBy("disk"))
+	require.Equal(t, types.CheckID("uplink"), suppressedBy("network"))
+	require.Equal(t, types.CheckID(""), suppressedBy("uplink"))
+}
+
 func TestAgent_AliasCheck(t *testing.T) {
 	t.Parallel()
 
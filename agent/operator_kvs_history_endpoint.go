@@ -0,0 +1,85 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+)
+
+// OperatorKVHistoryConfiguration is used to inspect and manage the KV
+// revision retention configuration.
+func (s *HTTPHandlers) OperatorKVHistoryConfiguration(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	switch req.Method {
+	case http.MethodGet:
+		var args structs.DCSpecificRequest
+		if done := s.parse(resp, req, &args.Datacenter, &args.QueryOptions); done {
+			return nil, nil
+		}
+
+		var reply structs.IndexedKVSHistoryConfigs
+		if err := s.agent.RPC(req.Context(), "Operator.KVSHistoryConfigList", &args, &reply); err != nil {
+			return nil, err
+		}
+		setMeta(resp, &reply.QueryMeta)
+
+		out := make([]api.KVHistoryConfig, 0, len(reply.Configs))
+		for _, config := range reply.Configs {
+			out = append(out, api.KVHistoryConfig{
+				Prefix:      config.Prefix,
+				Revisions:   config.Revisions,
+				CreateIndex: config.CreateIndex,
+				ModifyIndex: config.ModifyIndex,
+			})
+		}
+		return out, nil
+
+	case http.MethodPut:
+		var body api.KVHistoryConfig
+		if err := decodeBody(req.Body, &body); err != nil {
+			return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Error parsing KV history config: %v", err)}
+		}
+
+		args := structs.KVSHistoryConfigRequest{
+			Op: structs.KVSHistoryConfigUpsert,
+			Config: structs.KVSHistoryConfig{
+				Prefix:    body.Prefix,
+				Revisions: body.Revisions,
+			},
+		}
+		s.parseDC(req, &args.Datacenter)
+		s.parseToken(req, &args.Token)
+		if err := args.Config.Validate(); err != nil {
+			return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Invalid KV history config: %v", err)}
+		}
+
+		var reply bool
+		if err := s.agent.RPC(req.Context(), "Operator.KVSHistoryConfigApply", &args, &reply); err != nil {
+			return nil, err
+		}
+		return reply, nil
+
+	case http.MethodDelete:
+		args := structs.KVSHistoryConfigRequest{
+			Op: structs.KVSHistoryConfigDelete,
+			Config: structs.KVSHistoryConfig{
+				Prefix: req.URL.Query().Get("prefix"),
+			},
+		}
+		s.parseDC(req, &args.Datacenter)
+		s.parseToken(req, &args.Token)
+
+		var reply bool
+		if err := s.agent.RPC(req.Context(), "Operator.KVSHistoryConfigApply", &args, &reply); err != nil {
+			return nil, err
+		}
+		return reply, nil
+
+	default:
+		return nil, MethodNotAllowedError{req.Method, []string{"GET", "PUT", "DELETE"}}
+	}
+}
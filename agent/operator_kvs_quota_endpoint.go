@@ -0,0 +1,98 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package agent
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+)
+
+// OperatorKVQuota is used to inspect and manage the per-prefix KV quotas.
+func (s *HTTPHandlers) OperatorKVQuota(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	switch req.Method {
+	case http.MethodGet:
+		var args structs.DCSpecificRequest
+		if done := s.parse(resp, req, &args.Datacenter, &args.QueryOptions); done {
+			return nil, nil
+		}
+
+		var reply structs.IndexedKVSQuotas
+		if err := s.agent.RPC(req.Context(), "Operator.KVSQuotaList", &args, &reply); err != nil {
+			return nil, err
+		}
+		setMeta(resp, &reply.QueryMeta)
+
+		out := make([]api.KVQuota, 0, len(reply.Quotas))
+		for _, quota := range reply.Quotas {
+			out = append(out, api.KVQuota{
+				Prefix:       quota.Prefix,
+				Namespace:    quota.NamespaceOrEmpty(),
+				Partition:    quota.PartitionOrEmpty(),
+				MaxKeys:      quota.MaxKeys,
+				MaxBytes:     quota.MaxBytes,
+				MaxValueSize: quota.MaxValueSize,
+				CreateIndex:  quota.CreateIndex,
+				ModifyIndex:  quota.ModifyIndex,
+			})
+		}
+		return out, nil
+
+	case http.MethodPut:
+		var body api.KVQuota
+		if err := decodeBody(req.Body, &body); err != nil {
+			return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Error parsing KV quota: %v", err)}
+		}
+
+		args := structs.KVSQuotaRequest{
+			Op: structs.KVSQuotaUpsert,
+			Quota: structs.KVSQuota{
+				Prefix:         body.Prefix,
+				MaxKeys:        body.MaxKeys,
+				MaxBytes:       body.MaxBytes,
+				MaxValueSize:   body.MaxValueSize,
+				EnterpriseMeta: acl.NewEnterpriseMetaWithPartition(body.Partition, body.Namespace),
+			},
+		}
+		s.parseDC(req, &args.Datacenter)
+		s.parseToken(req, &args.Token)
+		if err := s.parseEntMetaNoWildcard(req, &args.Quota.EnterpriseMeta); err != nil {
+			return nil, err
+		}
+		if err := args.Quota.Validate(); err != nil {
+			return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Invalid KV quota: %v", err)}
+		}
+
+		var reply bool
+		if err := s.agent.RPC(req.Context(), "Operator.KVSQuotaApply", &args, &reply); err != nil {
+			return nil, err
+		}
+		return reply, nil
+
+	case http.MethodDelete:
+		args := structs.KVSQuotaRequest{
+			Op: structs.KVSQuotaDelete,
+			Quota: structs.KVSQuota{
+				Prefix: req.URL.Query().Get("prefix"),
+			},
+		}
+		s.parseDC(req, &args.Datacenter)
+		s.parseToken(req, &args.Token)
+		if err := s.parseEntMetaNoWildcard(req, &args.Quota.EnterpriseMeta); err != nil {
+			return nil, err
+		}
+
+		var reply bool
+		if err := s.agent.RPC(req.Context(), "Operator.KVSQuotaApply", &args, &reply); err != nil {
+			return nil, err
+		}
+		return reply, nil
+
+	default:
+		return nil, MethodNotAllowedError{req.Method, []string{"GET", "PUT", "DELETE"}}
+	}
+}
@@ -39,6 +39,7 @@ var rpcRateLimitSpecs = map[string]rate.OperationSpec{
 	"ACL.TokenBatchRead":    {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
 	"ACL.TokenClone":        {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
 	"ACL.TokenDelete":       {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
+	"ACL.TokenLastUsedSet":  {Type: rate.OperationTypeExempt, Category: rate.OperationCategoryACL},
 	"ACL.TokenList":         {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
 	"ACL.TokenRead":         {Type: rate.OperationTypeRead, Category: rate.OperationCategoryACL},
 	"ACL.TokenSet":          {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryACL},
@@ -112,14 +113,19 @@ var rpcRateLimitSpecs = map[string]rate.OperationSpec{
 	"Internal.ServiceGateways":               {Type: rate.OperationTypeRead, Category: rate.OperationCategoryInternal},
 	"Internal.ServiceTopology":               {Type: rate.OperationTypeRead, Category: rate.OperationCategoryInternal},
 
-	"KVS.Apply":    {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryKV},
-	"KVS.Get":      {Type: rate.OperationTypeRead, Category: rate.OperationCategoryKV},
-	"KVS.List":     {Type: rate.OperationTypeRead, Category: rate.OperationCategoryKV},
-	"KVS.ListKeys": {Type: rate.OperationTypeRead, Category: rate.OperationCategoryKV},
+	"KVS.Apply":     {Type: rate.OperationTypeWrite, Category: rate.OperationCategoryKV},
+	"KVS.Get":       {Type: rate.OperationTypeRead, Category: rate.OperationCategoryKV},
+	"KVS.List":      {Type: rate.OperationTypeRead, Category: rate.OperationCategoryKV},
+	"KVS.ListKeys":  {Type: rate.OperationTypeRead, Category: rate.OperationCategoryKV},
+	"KVS.Revisions": {Type: rate.OperationTypeRead, Category: rate.OperationCategoryKV},
 
 	"Operator.AutopilotGetConfiguration": {Type: rate.OperationTypeExempt, Category: rate.OperationCategoryOperator},
 	"Operator.AutopilotSetConfiguration": {Type: rate.OperationTypeExempt, Category: rate.OperationCategoryOperator},
 	"Operator.AutopilotState":            {Type: rate.OperationTypeExempt, Category: rate.OperationCategoryOperator},
+	"Operator.KVSHistoryConfigApply":     {Type: rate.OperationTypeExempt, Category: rate.OperationCategoryOperator},
+	"Operator.KVSHistoryConfigList":      {Type: rate.OperationTypeExempt, Category: rate.OperationCategoryOperator},
+	"Operator.KVSQuotaApply":             {Type: rate.OperationTypeExempt, Category: rate.OperationCategoryOperator},
+	"Operator.KVSQuotaList":              {Type: rate.OperationTypeExempt, Category: rate.OperationCategoryOperator},
 	"Operator.RaftGetConfiguration":      {Type: rate.OperationTypeExempt, Category: rate.OperationCategoryOperator},
 	"Operator.RaftRemovePeerByAddress":   {Type: rate.OperationTypeExempt, Category: rate.OperationCategoryOperator},
 	"Operator.RaftRemovePeerByID":        {Type: rate.OperationTypeExempt, Category: rate.OperationCategoryOperator},
@@ -0,0 +1,125 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package kv
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/hashstructure"
+
+	"github.com/hashicorp/consul/agent/cache"
+	"github.com/hashicorp/consul/agent/rpcclient"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/agent/submatview"
+	"github.com/hashicorp/consul/proto/private/pbsubscribe"
+)
+
+// Client provides access to KV data.
+type Client struct {
+	rpcclient.Client
+}
+
+// Get returns the entry for a single key. Blocking queries are served from a
+// streaming subscription when the streaming backend is enabled.
+func (c *Client) Get(ctx context.Context, req structs.KeyRequest) (structs.IndexedDirEntries, cache.ResultMeta, error) {
+	return c.get(ctx, req, false)
+}
+
+// List returns the entries under a prefix. Blocking queries are served from a
+// streaming subscription when the streaming backend is enabled and the prefix
+// is empty or ends in a '/'.
+func (c *Client) List(ctx context.Context, req structs.KeyRequest) (structs.IndexedDirEntries, cache.ResultMeta, error) {
+	return c.get(ctx, req, true)
+}
+
+func (c *Client) get(ctx context.Context, req structs.KeyRequest, recurse bool) (structs.IndexedDirEntries, cache.ResultMeta, error) {
+	if c.useStreaming(req, recurse) {
+		c.QueryOptionDefaults(&req.QueryOptions)
+
+		result, err := c.ViewStore.Get(ctx, c.newKVRequest(req, recurse))
+		if err != nil {
+			return structs.IndexedDirEntries{}, cache.ResultMeta{}, err
+		}
+		meta := cache.ResultMeta{Index: result.Index, Hit: result.Cached}
+		return *result.Value.(*structs.IndexedDirEntries), meta, err
+	}
+
+	method := "KVS.Get"
+	if recurse {
+		method = "KVS.List"
+	}
+
+	var out structs.IndexedDirEntries
+	err := c.NetRPC.RPC(ctx, method, &req, &out)
+	return out, cache.ResultMeta{}, err
+}
+
+func (c *Client) useStreaming(req structs.KeyRequest, recurse bool) bool {
+	return c.UseStreamingBackend &&
+		// Only blocking queries benefit from a materialized view, and
+		// consistent reads must go to the leader.
+		req.MinQueryIndex > 0 &&
+		!req.RequireConsistent &&
+		// Point-in-time reads are served from the revision history.
+		req.AtIndex == 0 &&
+		// Prefix events are only published for '/'-terminated prefixes.
+		(!recurse || req.Key == "" || strings.HasSuffix(req.Key, "/"))
+}
+
+func (c *Client) newKVRequest(req structs.KeyRequest, recurse bool) kvRequest {
+	return kvRequest{
+		KeyRequest: req,
+		recurse:    recurse,
+		deps:       c.MaterializerDeps,
+	}
+}
+
+var _ submatview.Request = (*kvRequest)(nil)
+
+type kvRequest struct {
+	structs.KeyRequest
+	recurse bool
+	deps    rpcclient.MaterializerDeps
+}
+
+func (r kvRequest) CacheInfo() cache.RequestInfo {
+	info := cache.RequestInfo{
+		Token:          r.Token,
+		Datacenter:     r.Datacenter,
+		MinIndex:       r.MinQueryIndex,
+		Timeout:        r.MaxQueryTime,
+		MaxAge:         r.MaxAge,
+		MustRevalidate: r.MustRevalidate,
+	}
+
+	v, err := hashstructure.Hash([]interface{}{
+		r.Key,
+		r.recurse,
+		r.EnterpriseMeta,
+	}, nil)
+	if err == nil {
+		// If there is an error, we don't set the key. A blank key forces
+		// no cache for this request so the request is forwarded directly
+		// to the server.
+		info.Key = strconv.FormatUint(v, 10)
+	}
+
+	return info
+}
+
+func (r kvRequest) Type() string {
+	return "agent.rpcclient.kv.kvRequest"
+}
+
+func (r kvRequest) NewMaterializer() (submatview.Materializer, error) {
+	deps := submatview.Deps{
+		View:    NewKVView(),
+		Logger:  r.deps.Logger,
+		Request: NewMaterializerRequest(r.KeyRequest, r.recurse),
+	}
+
+	return submatview.NewRPCMaterializer(pbsubscribe.NewStateChangeSubscriptionClient(r.deps.Conn), deps), nil
+}
@@ -0,0 +1,149 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package kv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/cache"
+	"github.com/hashicorp/consul/agent/config"
+	"github.com/hashicorp/consul/agent/rpcclient"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/agent/submatview"
+)
+
+func TestClient_BackendRouting(t *testing.T) {
+	type testCase struct {
+		name     string
+		req      structs.KeyRequest
+		recurse  bool
+		expected string
+	}
+
+	run := func(t *testing.T, tc testCase) {
+		rpc := &fakeNetRPC{}
+		store := &fakeViewStore{}
+		c := &Client{
+			Client: rpcclient.Client{
+				NetRPC:              rpc,
+				ViewStore:           store,
+				UseStreamingBackend: true,
+				QueryOptionDefaults: config.ApplyDefaultQueryOptions(&config.RuntimeConfig{}),
+			},
+		}
+
+		var err error
+		if tc.recurse {
+			_, _, err = c.List(context.Background(), tc.req)
+		} else {
+			_, _, err = c.Get(context.Background(), tc.req)
+		}
+		require.NoError(t, err)
+
+		if tc.expected == "streaming" {
+			require.Len(t, rpc.calls, 0)
+			require.Len(t, store.calls, 1)
+		} else {
+			require.Len(t, store.calls, 0)
+			require.Equal(t, []string{tc.expected}, rpc.calls)
+		}
+	}
+
+	blocking := structs.QueryOptions{MinQueryIndex: 22}
+
+	testCases := []testCase{
+		{
+			name:     "rpc for non-blocking get",
+			req:      structs.KeyRequest{Key: "foo"},
+			expected: "KVS.Get",
+		},
+		{
+			name:     "streaming for blocking get",
+			req:      structs.KeyRequest{Key: "foo", QueryOptions: blocking},
+			expected: "streaming",
+		},
+		{
+			name: "rpc for consistent get",
+			req: structs.KeyRequest{Key: "foo", QueryOptions: structs.QueryOptions{
+				MinQueryIndex:     22,
+				RequireConsistent: true,
+			}},
+			expected: "KVS.Get",
+		},
+		{
+			name:     "rpc for point-in-time get",
+			req:      structs.KeyRequest{Key: "foo", AtIndex: 10, QueryOptions: blocking},
+			expected: "KVS.Get",
+		},
+		{
+			name:     "streaming for blocking list of root",
+			req:      structs.KeyRequest{QueryOptions: blocking},
+			recurse:  true,
+			expected: "streaming",
+		},
+		{
+			name:     "streaming for blocking list of directory",
+			req:      structs.KeyRequest{Key: "foo/", QueryOptions: blocking},
+			recurse:  true,
+			expected: "streaming",
+		},
+		{
+			name:     "rpc for blocking list of partial prefix",
+			req:      structs.KeyRequest{Key: "fo", QueryOptions: blocking},
+			recurse:  true,
+			expected: "KVS.List",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			run(t, tc)
+		})
+	}
+}
+
+func TestClient_StreamingDisabled(t *testing.T) {
+	rpc := &fakeNetRPC{}
+	store := &fakeViewStore{}
+	c := &Client{
+		Client: rpcclient.Client{
+			NetRPC:    rpc,
+			ViewStore: store,
+		},
+	}
+
+	_, _, err := c.Get(context.Background(), structs.KeyRequest{
+		Key:          "foo",
+		QueryOptions: structs.QueryOptions{MinQueryIndex: 22},
+	})
+	require.NoError(t, err)
+	require.Len(t, store.calls, 0)
+	require.Equal(t, []string{"KVS.Get"}, rpc.calls)
+}
+
+type fakeNetRPC struct {
+	calls []string
+}
+
+func (f *fakeNetRPC) RPC(_ context.Context, method string, _ interface{}, _ interface{}) error {
+	f.calls = append(f.calls, method)
+	return nil
+}
+
+type fakeViewStore struct {
+	calls []submatview.Request
+}
+
+func (f *fakeViewStore) Get(_ context.Context, req submatview.Request) (submatview.Result, error) {
+	f.calls = append(f.calls, req)
+	return submatview.Result{Value: &structs.IndexedDirEntries{}}, nil
+}
+
+func (f *fakeViewStore) NotifyCallback(_ context.Context, req submatview.Request, _ string, _ cache.Callback) error {
+	f.calls = append(f.calls, req)
+	return nil
+}
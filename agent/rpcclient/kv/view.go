@@ -0,0 +1,112 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package kv
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/agent/submatview"
+	"github.com/hashicorp/consul/proto/private/pbcommon"
+	"github.com/hashicorp/consul/proto/private/pbsubscribe"
+)
+
+// NewMaterializerRequest returns a function that builds the subscription for
+// a single key or, if recurse is true, for every key under the prefix.
+func NewMaterializerRequest(req structs.KeyRequest, recurse bool) func(index uint64) *pbsubscribe.SubscribeRequest {
+	return func(index uint64) *pbsubscribe.SubscribeRequest {
+		return &pbsubscribe.SubscribeRequest{
+			Topic: pbsubscribe.Topic_KV,
+			Subject: &pbsubscribe.SubscribeRequest_NamedSubject{
+				NamedSubject: &pbsubscribe.NamedSubject{
+					Key:       req.Key,
+					Prefix:    recurse,
+					Namespace: req.NamespaceOrEmpty(),
+					Partition: req.PartitionOrEmpty(),
+				},
+			},
+			Token:      req.Token,
+			Datacenter: req.Datacenter,
+			Index:      index,
+		}
+	}
+}
+
+var _ submatview.View = (*KVView)(nil)
+
+// KVView implements submatview.View for a single KV entry or all of the
+// entries under a prefix. Entries are stored by key and sorted when the
+// result is built.
+type KVView struct {
+	state map[string]*structs.DirEntry
+}
+
+// NewKVView returns an empty KVView.
+func NewKVView() *KVView {
+	view := &KVView{}
+	view.Reset()
+	return view
+}
+
+// Update implements View
+func (v *KVView) Update(events []*pbsubscribe.Event) error {
+	for _, event := range events {
+		update := event.GetKV()
+		if update == nil {
+			return fmt.Errorf("unexpected event type for KV view: %T", event.GetPayload())
+		}
+
+		switch update.Op {
+		case pbsubscribe.KVUpdate_Delete:
+			delete(v.state, update.Key)
+		case pbsubscribe.KVUpdate_Upsert:
+			v.state[update.Key] = dirEntryFromKVUpdate(update)
+		}
+	}
+	return nil
+}
+
+// Result returns the structs.IndexedDirEntries stored by this view.
+func (v *KVView) Result(index uint64) any {
+	result := structs.IndexedDirEntries{
+		QueryMeta: structs.QueryMeta{
+			Index:   index,
+			Backend: structs.QueryBackendStreaming,
+		},
+	}
+	for _, entry := range v.state {
+		result.Entries = append(result.Entries, entry)
+	}
+	sort.Slice(result.Entries, func(i, j int) bool {
+		return result.Entries[i].Key < result.Entries[j].Key
+	})
+	return &result
+}
+
+// Reset implements View
+func (v *KVView) Reset() {
+	v.state = make(map[string]*structs.DirEntry)
+}
+
+func dirEntryFromKVUpdate(update *pbsubscribe.KVUpdate) *structs.DirEntry {
+	entry := &structs.DirEntry{
+		LockIndex:    update.LockIndex,
+		Key:          update.Key,
+		Flags:        update.Flags,
+		Value:        update.Value,
+		Session:      update.Session,
+		FencingToken: update.FencingToken,
+		RaftIndex: structs.RaftIndex{
+			CreateIndex: update.CreateIndex,
+			ModifyIndex: update.ModifyIndex,
+		},
+	}
+	if update.ExpirationTime != nil {
+		expirationTime := update.ExpirationTime.AsTime()
+		entry.ExpirationTime = &expirationTime
+	}
+	pbcommon.EnterpriseMetaToStructs(update.EnterpriseMeta, &entry.EnterpriseMeta)
+	return entry
+}
@@ -0,0 +1,114 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package kv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/proto/private/pbsubscribe"
+	"github.com/hashicorp/consul/sdk/testutil"
+)
+
+func TestKVView(t *testing.T) {
+	const index uint64 = 123
+
+	view := NewKVView()
+
+	kvEvent := func(op pbsubscribe.KVUpdate_UpdateOp, key, value string) *pbsubscribe.Event {
+		return &pbsubscribe.Event{
+			Index: index,
+			Payload: &pbsubscribe.Event_KV{
+				KV: &pbsubscribe.KVUpdate{
+					Op:          op,
+					Key:         key,
+					Value:       []byte(value),
+					CreateIndex: index,
+					ModifyIndex: index,
+				},
+			},
+		}
+	}
+
+	keys := func(t *testing.T) []string {
+		result := view.Result(index)
+		resp, ok := result.(*structs.IndexedDirEntries)
+		require.Truef(t, ok, "expected IndexedDirEntries, got: %T", result)
+		require.Equal(t, index, resp.Index)
+		require.Equal(t, structs.QueryBackendStreaming, resp.Backend)
+
+		var keys []string
+		for _, e := range resp.Entries {
+			keys = append(keys, e.Key)
+		}
+		return keys
+	}
+
+	testutil.RunStep(t, "initial state", func(t *testing.T) {
+		require.Empty(t, keys(t))
+	})
+
+	testutil.RunStep(t, "upsert events", func(t *testing.T) {
+		err := view.Update([]*pbsubscribe.Event{
+			kvEvent(pbsubscribe.KVUpdate_Upsert, "foo/b", "b"),
+			kvEvent(pbsubscribe.KVUpdate_Upsert, "foo/a", "a"),
+			kvEvent(pbsubscribe.KVUpdate_Upsert, "foo/a", "a2"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"foo/a", "foo/b"}, keys(t))
+
+		resp := view.Result(index).(*structs.IndexedDirEntries)
+		require.Equal(t, []byte("a2"), resp.Entries[0].Value)
+	})
+
+	testutil.RunStep(t, "delete event", func(t *testing.T) {
+		err := view.Update([]*pbsubscribe.Event{
+			kvEvent(pbsubscribe.KVUpdate_Delete, "foo/a", ""),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"foo/b"}, keys(t))
+	})
+
+	testutil.RunStep(t, "unexpected event", func(t *testing.T) {
+		err := view.Update([]*pbsubscribe.Event{
+			{Index: index, Payload: &pbsubscribe.Event_EndOfSnapshot{EndOfSnapshot: true}},
+		})
+		require.Error(t, err)
+	})
+
+	testutil.RunStep(t, "reset", func(t *testing.T) {
+		view.Reset()
+		require.Empty(t, keys(t))
+	})
+}
+
+func TestDirEntryFromKVUpdate(t *testing.T) {
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	entry := dirEntryFromKVUpdate(&pbsubscribe.KVUpdate{
+		Key:            "foo",
+		Flags:          42,
+		Value:          []byte("bar"),
+		Session:        "session",
+		LockIndex:      3,
+		FencingToken:   3,
+		CreateIndex:    4,
+		ModifyIndex:    5,
+		ExpirationTime: timestamppb.New(expires),
+	})
+	require.Equal(t, &structs.DirEntry{
+		Key:            "foo",
+		Flags:          42,
+		Value:          []byte("bar"),
+		Session:        "session",
+		LockIndex:      3,
+		FencingToken:   3,
+		ExpirationTime: &expires,
+		RaftIndex:      structs.RaftIndex{CreateIndex: 4, ModifyIndex: 5},
+	}, entry)
+}
@@ -343,6 +343,7 @@ func getPrometheusDefs(cfg *config.RuntimeConfig, isServer bool) ([]prometheus.G
 		grpcWare.StatsGauges,
 		xds.StatsGauges,
 		usagemetrics.Gauges,
+		local.StateGauges,
 		consul.ReplicationGauges,
 		certExpirationGauges(cfg.Datacenter, cfg.PartitionOrDefault(), cfg.NodeName, tlsCertRole(isServer)),
 		Gauges,
@@ -422,10 +423,13 @@ func getPrometheusDefs(cfg *config.RuntimeConfig, isServer bool) ([]prometheus.G
 
 	var counters = [][]prometheus.CounterDefinition{
 		CatalogCounters,
+		DNSRecursorCounters,
+		DNSZoneTransferCounters,
 		cache.Counters,
 		consul.ACLCounters,
 		consul.CatalogCounters,
 		consul.ClientCounters,
+		consul.KVSCounters,
 		consul.RPCCounters,
 		grpcWare.StatsCounters,
 		local.StateCounters,
@@ -497,6 +501,7 @@ func getPrometheusDefs(cfg *config.RuntimeConfig, isServer bool) ([]prometheus.G
 
 	var summaries = [][]prometheus.SummaryDefinition{
 		HTTPSummaries,
+		DNSRecursorSummaries,
 		consul.ACLSummaries,
 		consul.ACLEndpointSummaries,
 		consul.CatalogSummaries,
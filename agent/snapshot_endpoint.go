@@ -6,6 +6,7 @@ package agent
 import (
 	"bytes"
 	"net/http"
+	"strconv"
 
 	"github.com/hashicorp/consul/agent/structs"
 )
@@ -25,6 +26,20 @@ func (s *HTTPHandlers) Snapshot(resp http.ResponseWriter, req *http.Request) (in
 	case "GET":
 		args.Op = structs.SnapshotSave
 
+		// An incremental snapshot names its base by index and hash.
+		params := req.URL.Query()
+		if params.Has("base-index") {
+			baseIndex, err := strconv.ParseUint(params.Get("base-index"), 10, 64)
+			if err != nil || baseIndex == 0 {
+				return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "Invalid base-index: must be a positive integer"}
+			}
+			args.BaseIndex = baseIndex
+			args.BaseHash = params.Get("base-hash")
+			if args.BaseHash == "" {
+				return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "Missing base-hash for incremental snapshot"}
+			}
+		}
+
 		// Headers need to go out before we stream the body.
 		replyFn := func(reply *structs.SnapshotResponse) error {
 			setMeta(resp, &reply.QueryMeta)
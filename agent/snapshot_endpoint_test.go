@@ -8,10 +8,14 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/snapshot"
 	"github.com/hashicorp/consul/testrpc"
 )
 
@@ -122,3 +126,40 @@ func TestSnapshot_Options(t *testing.T) {
 		})
 	}
 }
+
+func TestSnapshot_Incremental(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	t.Run("bad base index", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/snapshot?base-index=nope&base-hash=abc", nil)
+		resp := httptest.NewRecorder()
+		_, err := a.srv.Snapshot(resp, req)
+		require.ErrorContains(t, err, "Invalid base-index")
+	})
+
+	t.Run("missing base hash", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/snapshot?base-index=1", nil)
+		resp := httptest.NewRecorder()
+		_, err := a.srv.Snapshot(resp, req)
+		require.ErrorContains(t, err, "Missing base-hash")
+	})
+
+	t.Run("incremental snapshot", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/snapshot?base-index=1&base-hash=abc", nil)
+		resp := httptest.NewRecorder()
+		_, err := a.srv.Snapshot(resp, req)
+		require.NoError(t, err)
+
+		info, err := snapshot.ReadInfo(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, &snapshot.IncrementalMeta{BaseIndex: 1, BaseHash: "abc"}, info.Incremental)
+		require.Equal(t, resp.Header().Get("X-Consul-Index"), strconv.FormatUint(info.Meta.Index, 10))
+	})
+}
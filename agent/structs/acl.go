@@ -351,6 +351,12 @@ type ACLToken struct {
 	// The time when this token was created
 	CreateTime time.Time `json:",omitempty"`
 
+	// LastUsed is when and where the token was last used to resolve an
+	// identity on a server. It is tracked at a coarse granularity and is nil
+	// for tokens that were never used since tracking began. It is maintained
+	// by the servers and so isn't part of the token's Hash.
+	LastUsed *ACLTokenLastUsed `json:",omitempty"`
+
 	// Hash of the contents of the token
 	//
 	// This is needed mainly for replication purposes. When replicating from
@@ -365,6 +371,22 @@ type ACLToken struct {
 	RaftIndex
 }
 
+// ACLTokenLastUsed describes the last use of a token.
+type ACLTokenLastUsed struct {
+	// Time is when the token was last used. It is only updated once it is
+	// older than the servers' tracking granularity, so the token may have been
+	// used more recently than this.
+	Time time.Time
+
+	// Server is the name of the server that resolved the token. Agents
+	// resolve tokens through the servers, so this is not necessarily the node
+	// the request using the token was made to.
+	Server string
+
+	// Datacenter is the datacenter of the server that resolved the token.
+	Datacenter string
+}
+
 func (t *ACLToken) UnmarshalJSON(data []byte) (err error) {
 	type Alias ACLToken
 	aux := &struct {
@@ -403,6 +425,10 @@ func (t *ACLToken) Clone() *ACLToken {
 	t2.NodeIdentities = nil
 	t2.TemplatedPolicies = nil
 
+	if t.LastUsed != nil {
+		lastUsed := *t.LastUsed
+		t2.LastUsed = &lastUsed
+	}
 	if len(t.Policies) > 0 {
 		t2.Policies = make([]ACLTokenPolicyLink, len(t.Policies))
 		copy(t2.Policies, t.Policies)
@@ -587,9 +613,10 @@ type ACLTokenListStub struct {
 	NodeIdentities    ACLNodeIdentities    `json:",omitempty"`
 	TemplatedPolicies ACLTemplatedPolicies `json:",omitempty"`
 	Local             bool
-	AuthMethod        string     `json:",omitempty"`
-	ExpirationTime    *time.Time `json:",omitempty"`
-	CreateTime        time.Time  `json:",omitempty"`
+	AuthMethod        string            `json:",omitempty"`
+	ExpirationTime    *time.Time        `json:",omitempty"`
+	CreateTime        time.Time         `json:",omitempty"`
+	LastUsed          *ACLTokenLastUsed `json:",omitempty"`
 	Hash              []byte
 	CreateIndex       uint64
 	ModifyIndex       uint64
@@ -614,6 +641,7 @@ func (token *ACLToken) Stub() *ACLTokenListStub {
 		AuthMethod:                  token.AuthMethod,
 		ExpirationTime:              token.ExpirationTime,
 		CreateTime:                  token.CreateTime,
+		LastUsed:                    token.LastUsed,
 		Hash:                        token.Hash,
 		CreateIndex:                 token.CreateIndex,
 		ModifyIndex:                 token.ModifyIndex,
@@ -827,28 +855,54 @@ func (policies ACLPolicies) resolveWithCache(cache *ACLCaches, entConf *acl.Conf
 }
 
 func (policies ACLPolicies) Compile(cache *ACLCaches, entConf *acl.Config) (acl.Authorizer, error) {
-	// Determine the cache key
+	authorizer, _, err := policies.CompileForRequest(cache, entConf, nil)
+	return authorizer, err
+}
+
+// CompileForRequest compiles the policies into an Authorizer for the given
+// request, leaving out the rules whose conditions the request doesn't meet.
+// It also returns when the time windows of the conditions next change which
+// rules apply, or the zero time if they never do.
+func (policies ACLPolicies) CompileForRequest(cache *ACLCaches, entConf *acl.Config, req *acl.RequestContext) (acl.Authorizer, time.Time, error) {
+	// Determine the cache key. Only policy sets without conditions are cached
+	// under it, the others are cached by which of their rules apply.
 	cacheKey := policies.HashKey()
 	entry := cache.GetAuthorizer(cacheKey)
 	if entry != nil {
 		// the hash key takes into account the policy contents. There is no reason to expire this cache or check its age.
-		return entry.Authorizer, nil
+		return entry.Authorizer, time.Time{}, nil
 	}
 
 	parsed, err := policies.resolveWithCache(cache, entConf)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse the ACL policies: %v", err)
+		return nil, time.Time{}, fmt.Errorf("failed to parse the ACL policies: %v", err)
+	}
+
+	var conditions strings.Builder
+	var validUntil time.Time
+	for i, policy := range parsed {
+		next := policy.NextConditionChange(req)
+		if !next.IsZero() && (validUntil.IsZero() || next.Before(validUntil)) {
+			validUntil = next
+		}
+		parsed[i] = policy.ForRequest(req, &conditions)
+	}
+	if conditions.Len() > 0 {
+		cacheKey += "/" + conditions.String()
+		if entry := cache.GetAuthorizer(cacheKey); entry != nil {
+			return entry.Authorizer, validUntil, nil
+		}
 	}
 
 	// Create the ACL object
 	authorizer, err := acl.NewPolicyAuthorizer(parsed, entConf)
 	if err != nil {
-		return nil, fmt.Errorf("failed to construct ACL Authorizer: %v", err)
+		return nil, time.Time{}, fmt.Errorf("failed to construct ACL Authorizer: %v", err)
 	}
 
 	// Update the cache
 	cache.PutAuthorizer(cacheKey, authorizer)
-	return authorizer, nil
+	return authorizer, validUntil, nil
 }
 
 type ACLRoles []*ACLRole
@@ -1465,6 +1519,27 @@ type ACLTokenBatchDeleteRequest struct {
 	TokenIDs []string // Tokens to delete
 }
 
+// ACLTokenLastUsedSetRequest is used by servers to record when and where
+// tokens were last used. Every server tracks the tokens it resolves and
+// periodically sends them to the leader in a batch, which is then applied at
+// the Raft layer as is.
+type ACLTokenLastUsedSetRequest struct {
+	Entries    []*ACLTokenLastUsedEntry
+	Datacenter string
+	WriteRequest
+}
+
+func (r *ACLTokenLastUsedSetRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// ACLTokenLastUsedEntry is the last use of a single token within an
+// ACLTokenLastUsedSetRequest.
+type ACLTokenLastUsedEntry struct {
+	AccessorID string
+	LastUsed   ACLTokenLastUsed
+}
+
 type ACLInitialTokenBootstrapRequest struct {
 	BootstrapSecret string
 	Datacenter      string
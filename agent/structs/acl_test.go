@@ -5,8 +5,10 @@ package structs
 
 import (
 	"fmt"
+	"net"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/consul/acl"
 
@@ -622,3 +624,103 @@ func TestStructs_ACLPolicies_Compile(t *testing.T) {
 		require.Equal(t, acl.Deny, authz.ACLRead(nil))
 	})
 }
+
+func TestStructs_ACLPolicies_CompileForRequest(t *testing.T) {
+	config := ACLCachesConfig{
+		ParsedPolicies: 4,
+		Authorizers:    4,
+	}
+	cache, err := NewACLCaches(&config)
+	require.NoError(t, err)
+
+	testPolicies := ACLPolicies{
+		&ACLPolicy{
+			ID:          "5d5653a1-2c2b-4b36-b083-fc9f1398eb7b",
+			Name:        "policy1",
+			Description: "policy1",
+			Rules:       `node_prefix "" { policy = "read" }`,
+			RaftIndex: RaftIndex{
+				CreateIndex: 1,
+				ModifyIndex: 2,
+			},
+		},
+		&ACLPolicy{
+			ID:          "b35541f0-a88a-48da-bc66-43553c60b628",
+			Name:        "break-glass",
+			Description: "break-glass",
+			Rules: `
+				agent_prefix "" {
+					policy = "write"
+					source_cidrs = ["10.0.0.0/24"]
+				}`,
+			RaftIndex: RaftIndex{
+				CreateIndex: 3,
+				ModifyIndex: 4,
+			},
+		},
+	}
+
+	bastion := &acl.RequestContext{SourceAddr: net.ParseIP("10.0.0.7")}
+
+	t.Run("Unknown Source", func(t *testing.T) {
+		authz, err := testPolicies.Compile(cache, nil)
+		require.NoError(t, err)
+
+		require.Equal(t, acl.Allow, authz.NodeRead("foo", nil))
+		require.Equal(t, acl.Default, authz.AgentWrite("foo", nil))
+	})
+
+	t.Run("Conditions Met", func(t *testing.T) {
+		authz, validUntil, err := testPolicies.CompileForRequest(cache, nil, bastion)
+		require.NoError(t, err)
+		require.True(t, validUntil.IsZero())
+
+		require.Equal(t, acl.Allow, authz.NodeRead("foo", nil))
+		require.Equal(t, acl.Allow, authz.AgentWrite("foo", nil))
+	})
+
+	t.Run("Check Cache", func(t *testing.T) {
+		// policy sets with conditions are cached by which rules apply
+		require.Nil(t, cache.GetAuthorizer(testPolicies.HashKey()))
+		require.NotNil(t, cache.GetAuthorizer(testPolicies.HashKey()+"/0"))
+		require.NotNil(t, cache.GetAuthorizer(testPolicies.HashKey()+"/1"))
+	})
+
+	t.Run("Time Window", func(t *testing.T) {
+		windowPolicies := ACLPolicies{
+			&ACLPolicy{
+				ID:          "1f2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d",
+				Name:        "maintenance",
+				Description: "maintenance",
+				Rules: `
+					key_prefix "" {
+						policy = "write"
+						valid_between = ["2030-01-01T00:00:00Z", "2030-01-01T04:00:00Z"]
+					}`,
+				RaftIndex: RaftIndex{
+					CreateIndex: 5,
+					ModifyIndex: 6,
+				},
+			},
+		}
+
+		// Before the window the authorizer is valid until it starts, and
+		// during the window until it ends.
+		before := &acl.RequestContext{Time: time.Date(2029, 12, 31, 0, 0, 0, 0, time.UTC)}
+		authz, validUntil, err := windowPolicies.CompileForRequest(cache, nil, before)
+		require.NoError(t, err)
+		require.Equal(t, acl.Default, authz.KeyWrite("foo", nil))
+		require.Equal(t, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), validUntil.UTC())
+
+		during := &acl.RequestContext{Time: time.Date(2030, 1, 1, 1, 0, 0, 0, time.UTC)}
+		authz, validUntil, err = windowPolicies.CompileForRequest(cache, nil, during)
+		require.NoError(t, err)
+		require.Equal(t, acl.Allow, authz.KeyWrite("foo", nil))
+		require.Equal(t, time.Date(2030, 1, 1, 4, 0, 0, 0, time.UTC), validUntil.UTC())
+
+		after := &acl.RequestContext{Time: time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)}
+		_, validUntil, err = windowPolicies.CompileForRequest(cache, nil, after)
+		require.NoError(t, err)
+		require.True(t, validUntil.IsZero())
+	})
+}
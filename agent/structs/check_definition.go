@@ -35,9 +35,19 @@ type CheckDefinition struct {
 	Method                         string
 	Body                           string
 	DisableRedirects               bool
+	HTTPAssertions                 []HTTPCheckAssertion
 	TCP                            string
 	TCPUseTLS                      bool
 	UDP                            string
+	TLS                            string
+	TLSExpiryWarningDays           int
+	TLSExpiryCriticalDays          int
+	DNS                            string
+	DNSQueryType                   string
+	DNSResolver                    string
+	DNSExpectedValues              []string
+	DNSRcode                       string
+	DNSMaxLatency                  time.Duration
 	Interval                       time.Duration
 	DockerContainerID              string
 	Shell                          string
@@ -53,6 +63,7 @@ type CheckDefinition struct {
 	SuccessBeforePassing           int
 	FailuresBeforeWarning          int
 	FailuresBeforeCritical         int
+	DependsOn                      []types.CheckID
 	DeregisterCriticalServiceAfter time.Duration
 	OutputMaxSize                  int
 
@@ -67,21 +78,31 @@ func (t *CheckDefinition) UnmarshalJSON(data []byte) (err error) {
 		Timeout                        interface{}
 		TTL                            interface{}
 		DeregisterCriticalServiceAfter interface{}
+		DNSMaxLatency                  interface{}
 
 		// Translate fields
 
 		// "args" -> ScriptArgs
-		Args                                []string    `json:"args"`
-		ScriptArgsSnake                     []string    `json:"script_args"`
-		DeregisterCriticalServiceAfterSnake interface{} `json:"deregister_critical_service_after"`
-		DockerContainerIDSnake              string      `json:"docker_container_id"`
-		TLSServerNameSnake                  string      `json:"tls_server_name"`
-		TLSSkipVerifySnake                  bool        `json:"tls_skip_verify"`
-		TCPUseTLSSnake                      bool        `json:"tcp_use_tls"`
-		GRPCUseTLSSnake                     bool        `json:"grpc_use_tls"`
-		ServiceIDSnake                      string      `json:"service_id"`
-		H2PingUseTLSSnake                   bool        `json:"h2ping_use_tls"`
-		DisableRedirectsSnake               bool        `json:"disable_redirects"`
+		Args                                []string             `json:"args"`
+		ScriptArgsSnake                     []string             `json:"script_args"`
+		DeregisterCriticalServiceAfterSnake interface{}          `json:"deregister_critical_service_after"`
+		DockerContainerIDSnake              string               `json:"docker_container_id"`
+		TLSServerNameSnake                  string               `json:"tls_server_name"`
+		TLSSkipVerifySnake                  bool                 `json:"tls_skip_verify"`
+		TCPUseTLSSnake                      bool                 `json:"tcp_use_tls"`
+		GRPCUseTLSSnake                     bool                 `json:"grpc_use_tls"`
+		ServiceIDSnake                      string               `json:"service_id"`
+		H2PingUseTLSSnake                   bool                 `json:"h2ping_use_tls"`
+		DisableRedirectsSnake               bool                 `json:"disable_redirects"`
+		HTTPAssertionsSnake                 []HTTPCheckAssertion `json:"http_assertions"`
+		TLSExpiryWarningDaysSnake           int                  `json:"tls_expiry_warning_days"`
+		TLSExpiryCriticalDaysSnake          int                  `json:"tls_expiry_critical_days"`
+		DNSQueryTypeSnake                   string               `json:"dns_query_type"`
+		DNSResolverSnake                    string               `json:"dns_resolver"`
+		DNSExpectedValuesSnake              []string             `json:"dns_expected_values"`
+		DNSRcodeSnake                       string               `json:"dns_rcode"`
+		DNSMaxLatencySnake                  interface{}          `json:"dns_max_latency"`
+		DependsOnSnake                      []types.CheckID      `json:"depends_on"`
 
 		*Alias
 	}{
@@ -133,6 +154,33 @@ func (t *CheckDefinition) UnmarshalJSON(data []byte) (err error) {
 	if aux.DisableRedirectsSnake {
 		t.DisableRedirects = aux.DisableRedirectsSnake
 	}
+	if len(t.HTTPAssertions) == 0 {
+		t.HTTPAssertions = aux.HTTPAssertionsSnake
+	}
+	if t.TLSExpiryWarningDays == 0 {
+		t.TLSExpiryWarningDays = aux.TLSExpiryWarningDaysSnake
+	}
+	if t.TLSExpiryCriticalDays == 0 {
+		t.TLSExpiryCriticalDays = aux.TLSExpiryCriticalDaysSnake
+	}
+	if t.DNSQueryType == "" {
+		t.DNSQueryType = aux.DNSQueryTypeSnake
+	}
+	if t.DNSResolver == "" {
+		t.DNSResolver = aux.DNSResolverSnake
+	}
+	if len(t.DNSExpectedValues) == 0 {
+		t.DNSExpectedValues = aux.DNSExpectedValuesSnake
+	}
+	if t.DNSRcode == "" {
+		t.DNSRcode = aux.DNSRcodeSnake
+	}
+	if aux.DNSMaxLatency == nil {
+		aux.DNSMaxLatency = aux.DNSMaxLatencySnake
+	}
+	if len(t.DependsOn) == 0 {
+		t.DependsOn = aux.DependsOnSnake
+	}
 
 	if (aux.H2PING != "" && !aux.H2PingUseTLSSnake) || (aux.H2PING == "" && aux.H2PingUseTLSSnake) {
 		t.H2PingUseTLS = aux.H2PingUseTLSSnake
@@ -179,6 +227,16 @@ func (t *CheckDefinition) UnmarshalJSON(data []byte) (err error) {
 			t.DeregisterCriticalServiceAfter = time.Duration(v)
 		}
 	}
+	if aux.DNSMaxLatency != nil {
+		switch v := aux.DNSMaxLatency.(type) {
+		case string:
+			if t.DNSMaxLatency, err = time.ParseDuration(v); err != nil {
+				return err
+			}
+		case float64:
+			t.DNSMaxLatency = time.Duration(v)
+		}
+	}
 
 	return nil
 }
@@ -193,6 +251,7 @@ func (c *CheckDefinition) HealthCheck(node string) *HealthCheck {
 		ServiceID:      c.ServiceID,
 		Interval:       c.Interval.String(),
 		Timeout:        c.Timeout.String(),
+		DependsOn:      c.DependsOn,
 		EnterpriseMeta: c.EnterpriseMeta,
 	}
 	if c.Status != "" {
@@ -223,10 +282,20 @@ func (c *CheckDefinition) CheckType() *CheckType {
 		Method:                         c.Method,
 		Body:                           c.Body,
 		DisableRedirects:               c.DisableRedirects,
+		HTTPAssertions:                 c.HTTPAssertions,
 		OutputMaxSize:                  c.OutputMaxSize,
 		TCP:                            c.TCP,
 		TCPUseTLS:                      c.TCPUseTLS,
 		UDP:                            c.UDP,
+		TLS:                            c.TLS,
+		TLSExpiryWarningDays:           c.TLSExpiryWarningDays,
+		TLSExpiryCriticalDays:          c.TLSExpiryCriticalDays,
+		DNS:                            c.DNS,
+		DNSQueryType:                   c.DNSQueryType,
+		DNSResolver:                    c.DNSResolver,
+		DNSExpectedValues:              c.DNSExpectedValues,
+		DNSRcode:                       c.DNSRcode,
+		DNSMaxLatency:                  c.DNSMaxLatency,
 		Interval:                       c.Interval,
 		DockerContainerID:              c.DockerContainerID,
 		Shell:                          c.Shell,
@@ -238,6 +307,7 @@ func (c *CheckDefinition) CheckType() *CheckType {
 		SuccessBeforePassing:           c.SuccessBeforePassing,
 		FailuresBeforeWarning:          c.FailuresBeforeWarning,
 		FailuresBeforeCritical:         c.FailuresBeforeCritical,
+		DependsOn:                      c.DependsOn,
 		DeregisterCriticalServiceAfter: c.DeregisterCriticalServiceAfter,
 	}
 }
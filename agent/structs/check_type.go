@@ -6,19 +6,35 @@ package structs
 import (
 	"fmt"
 	"reflect"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/miekg/dns"
+
 	"github.com/hashicorp/consul/lib"
+	"github.com/hashicorp/consul/lib/jsonpath"
 	"github.com/hashicorp/consul/types"
 )
 
 type CheckTypes []*CheckType
 
+const (
+	// DefaultTLSExpiryWarningDays is the number of days before the expiry of
+	// a certificate at which a TLS check goes warning, unless set.
+	DefaultTLSExpiryWarningDays = 30
+
+	// DefaultTLSExpiryCriticalDays is the number of days before the expiry of
+	// a certificate at which a TLS check goes critical, unless set.
+	DefaultTLSExpiryCriticalDays = 7
+)
+
 // CheckType is used to create either the CheckMonitor or the CheckTTL.
-// The following types are supported: Script, HTTP, TCP, Docker, TTL, GRPC, Alias, H2PING. Script,
-// HTTP, Docker, TCP, GRPC, and H2PING all require Interval. Only one of the types may
+// The following types are supported: Script, HTTP, TCP, Docker, TTL, GRPC, Alias, H2PING, TLS, DNS. Script,
+// HTTP, Docker, TCP, GRPC, H2PING, TLS and DNS all require Interval. Only one of the types may
 // to be provided: TTL or Script/Interval or HTTP/Interval or TCP/Interval or
-// Docker/Interval or GRPC/Interval or AliasService or H2PING/Interval.
+// Docker/Interval or GRPC/Interval or AliasService or H2PING/Interval or TLS/Interval or
+// DNS/Interval.
 // Since types like CheckHTTP and CheckGRPC derive from CheckType, there are
 // helper conversion methods that do the reverse conversion. ie. checkHTTP.CheckType()
 type CheckType struct {
@@ -41,9 +57,19 @@ type CheckType struct {
 	Method                 string
 	Body                   string
 	DisableRedirects       bool
+	HTTPAssertions         []HTTPCheckAssertion
 	TCP                    string
 	TCPUseTLS              bool
 	UDP                    string
+	TLS                    string
+	TLSExpiryWarningDays   int
+	TLSExpiryCriticalDays  int
+	DNS                    string
+	DNSQueryType           string
+	DNSResolver            string
+	DNSExpectedValues      []string
+	DNSRcode               string
+	DNSMaxLatency          time.Duration
 	Interval               time.Duration
 	AliasNode              string
 	AliasService           string
@@ -60,6 +86,12 @@ type CheckType struct {
 	FailuresBeforeWarning  int
 	FailuresBeforeCritical int
 
+	// DependsOn is the list of the IDs of the checks of the same node this
+	// check depends on. When one of them is critical, this check is reported
+	// as suppressed by it while it is critical too. Checks whose critical
+	// dependencies only lead back to themselves are never suppressed.
+	DependsOn []types.CheckID
+
 	// Definition fields used when exposing checks through a proxy
 	ProxyHTTP string
 	ProxyGRPC string
@@ -78,19 +110,29 @@ func (t *CheckType) UnmarshalJSON(data []byte) (err error) {
 		Timeout                        interface{}
 		TTL                            interface{}
 		DeregisterCriticalServiceAfter interface{}
+		DNSMaxLatency                  interface{}
 
 		// Translate fields
 
 		// "args" -> ScriptArgs
-		Args                                []string    `json:"args"`
-		ScriptArgsSnake                     []string    `json:"script_args"`
-		DeregisterCriticalServiceAfterSnake interface{} `json:"deregister_critical_service_after"`
-		DockerContainerIDSnake              string      `json:"docker_container_id"`
-		TLSServerNameSnake                  string      `json:"tls_server_name"`
-		TLSSkipVerifySnake                  bool        `json:"tls_skip_verify"`
-		TCPUseTLSSnake                      bool        `json:"tcp_use_tls"`
-		GRPCUseTLSSnake                     bool        `json:"grpc_use_tls"`
-		H2PingUseTLSSnake                   bool        `json:"h2ping_use_tls"`
+		Args                                []string             `json:"args"`
+		ScriptArgsSnake                     []string             `json:"script_args"`
+		DeregisterCriticalServiceAfterSnake interface{}          `json:"deregister_critical_service_after"`
+		DockerContainerIDSnake              string               `json:"docker_container_id"`
+		TLSServerNameSnake                  string               `json:"tls_server_name"`
+		TLSSkipVerifySnake                  bool                 `json:"tls_skip_verify"`
+		TCPUseTLSSnake                      bool                 `json:"tcp_use_tls"`
+		GRPCUseTLSSnake                     bool                 `json:"grpc_use_tls"`
+		H2PingUseTLSSnake                   bool                 `json:"h2ping_use_tls"`
+		HTTPAssertionsSnake                 []HTTPCheckAssertion `json:"http_assertions"`
+		TLSExpiryWarningDaysSnake           int                  `json:"tls_expiry_warning_days"`
+		TLSExpiryCriticalDaysSnake          int                  `json:"tls_expiry_critical_days"`
+		DNSQueryTypeSnake                   string               `json:"dns_query_type"`
+		DNSResolverSnake                    string               `json:"dns_resolver"`
+		DNSExpectedValuesSnake              []string             `json:"dns_expected_values"`
+		DNSRcodeSnake                       string               `json:"dns_rcode"`
+		DNSMaxLatencySnake                  interface{}          `json:"dns_max_latency"`
+		DependsOnSnake                      []types.CheckID      `json:"depends_on"`
 
 		// These are going to be ignored but since we are disallowing unknown fields
 		// during parsing we have to be explicit about parsing but not using these.
@@ -139,6 +181,33 @@ func (t *CheckType) UnmarshalJSON(data []byte) (err error) {
 	if aux.GRPCUseTLSSnake {
 		t.GRPCUseTLS = aux.GRPCUseTLSSnake
 	}
+	if len(t.HTTPAssertions) == 0 {
+		t.HTTPAssertions = aux.HTTPAssertionsSnake
+	}
+	if t.TLSExpiryWarningDays == 0 {
+		t.TLSExpiryWarningDays = aux.TLSExpiryWarningDaysSnake
+	}
+	if t.TLSExpiryCriticalDays == 0 {
+		t.TLSExpiryCriticalDays = aux.TLSExpiryCriticalDaysSnake
+	}
+	if t.DNSQueryType == "" {
+		t.DNSQueryType = aux.DNSQueryTypeSnake
+	}
+	if t.DNSResolver == "" {
+		t.DNSResolver = aux.DNSResolverSnake
+	}
+	if len(t.DNSExpectedValues) == 0 {
+		t.DNSExpectedValues = aux.DNSExpectedValuesSnake
+	}
+	if t.DNSRcode == "" {
+		t.DNSRcode = aux.DNSRcodeSnake
+	}
+	if aux.DNSMaxLatency == nil {
+		aux.DNSMaxLatency = aux.DNSMaxLatencySnake
+	}
+	if len(t.DependsOn) == 0 {
+		t.DependsOn = aux.DependsOnSnake
+	}
 	if aux.Interval != nil {
 		switch v := aux.Interval.(type) {
 		case string:
@@ -179,6 +248,16 @@ func (t *CheckType) UnmarshalJSON(data []byte) (err error) {
 			t.DeregisterCriticalServiceAfter = time.Duration(v)
 		}
 	}
+	if aux.DNSMaxLatency != nil {
+		switch v := aux.DNSMaxLatency.(type) {
+		case string:
+			if t.DNSMaxLatency, err = time.ParseDuration(v); err != nil {
+				return err
+			}
+		case float64:
+			t.DNSMaxLatency = time.Duration(v)
+		}
+	}
 	if (aux.H2PING != "" && !aux.H2PingUseTLSSnake) || (aux.H2PING == "" && aux.H2PingUseTLSSnake) {
 		t.H2PingUseTLS = aux.H2PingUseTLSSnake
 	}
@@ -189,13 +268,13 @@ func (t *CheckType) UnmarshalJSON(data []byte) (err error) {
 
 // Validate returns an error message if the check is invalid
 func (c *CheckType) Validate() error {
-	intervalCheck := c.IsScript() || c.HTTP != "" || c.TCP != "" || c.UDP != "" || c.GRPC != "" || c.H2PING != "" || c.OSService != ""
+	intervalCheck := c.IsScript() || c.HTTP != "" || c.TCP != "" || c.UDP != "" || c.GRPC != "" || c.H2PING != "" || c.TLS != "" || c.DNS != "" || c.OSService != ""
 
 	if c.Interval > 0 && c.TTL > 0 {
 		return fmt.Errorf("Interval and TTL cannot both be specified")
 	}
 	if intervalCheck && c.Interval <= 0 {
-		return fmt.Errorf("Interval must be > 0 for Script, HTTP, H2PING, TCP, UDP or OSService checks")
+		return fmt.Errorf("Interval must be > 0 for Script, HTTP, H2PING, TCP, UDP, TLS, DNS or OSService checks")
 	}
 	if intervalCheck && c.IsAlias() {
 		return fmt.Errorf("Interval cannot be set for Alias checks")
@@ -212,7 +291,69 @@ func (c *CheckType) Validate() error {
 	if c.FailuresBeforeWarning > c.FailuresBeforeCritical {
 		return fmt.Errorf("FailuresBeforeWarning can't be higher than FailuresBeforeCritical")
 	}
+	if len(c.HTTPAssertions) > 0 && c.HTTP == "" {
+		return fmt.Errorf("HTTPAssertions can only be set for HTTP checks")
+	}
+	for i, assertion := range c.HTTPAssertions {
+		if err := assertion.Validate(); err != nil {
+			return fmt.Errorf("HTTPAssertions[%d]: %w", i, err)
+		}
+	}
+	if (c.TLSExpiryWarningDays != 0 || c.TLSExpiryCriticalDays != 0) && c.TLS == "" {
+		return fmt.Errorf("TLSExpiryWarningDays and TLSExpiryCriticalDays can only be set for TLS checks")
+	}
+	if c.TLSExpiryWarningDays < 0 || c.TLSExpiryCriticalDays < 0 {
+		return fmt.Errorf("TLSExpiryWarningDays and TLSExpiryCriticalDays must be positive")
+	}
+	if c.TLS != "" {
+		// Compare the thresholds the check will use, so that setting only one
+		// of them can't make the warning status unreachable.
+		warningDays, criticalDays := c.TLSExpiryWarningDays, c.TLSExpiryCriticalDays
+		if warningDays == 0 {
+			warningDays = DefaultTLSExpiryWarningDays
+		}
+		if criticalDays == 0 {
+			criticalDays = DefaultTLSExpiryCriticalDays
+		}
+		if criticalDays >= warningDays {
+			return fmt.Errorf("TLSExpiryCriticalDays (%d) must be lower than TLSExpiryWarningDays (%d)", criticalDays, warningDays)
+		}
+	}
+	if err := c.validateDNS(); err != nil {
+		return err
+	}
+	for _, dep := range c.DependsOn {
+		if dep == "" {
+			return fmt.Errorf("DependsOn can't contain an empty check ID")
+		}
+		if c.CheckID != "" && dep == c.CheckID {
+			return fmt.Errorf("check %q can't depend on itself", c.CheckID)
+		}
+	}
+
+	return nil
+}
 
+// validateDNS returns an error if the fields of a DNS check are invalid.
+func (c *CheckType) validateDNS() error {
+	if c.DNS == "" {
+		if c.DNSQueryType != "" || c.DNSResolver != "" || len(c.DNSExpectedValues) > 0 || c.DNSRcode != "" || c.DNSMaxLatency != 0 {
+			return fmt.Errorf("DNSQueryType, DNSResolver, DNSExpectedValues, DNSRcode and DNSMaxLatency can only be set for DNS checks")
+		}
+		return nil
+	}
+	if _, ok := dns.IsDomainName(c.DNS); !ok {
+		return fmt.Errorf("DNS must be a domain name, got %q", c.DNS)
+	}
+	if _, ok := dns.StringToType[strings.ToUpper(c.DNSQueryType)]; c.DNSQueryType != "" && !ok {
+		return fmt.Errorf("invalid DNSQueryType %q", c.DNSQueryType)
+	}
+	if _, ok := dns.StringToRcode[strings.ToUpper(c.DNSRcode)]; c.DNSRcode != "" && !ok {
+		return fmt.Errorf("invalid DNSRcode %q", c.DNSRcode)
+	}
+	if c.DNSMaxLatency < 0 {
+		return fmt.Errorf("DNSMaxLatency must be positive")
+	}
 	return nil
 }
 
@@ -270,6 +411,16 @@ func (c *CheckType) IsH2PING() bool {
 	return c.H2PING != "" && c.Interval > 0
 }
 
+// IsTLS checks if this is a TLS type
+func (c *CheckType) IsTLS() bool {
+	return c.TLS != "" && c.Interval > 0
+}
+
+// IsDNS checks if this is a DNS type
+func (c *CheckType) IsDNS() bool {
+	return c.DNS != "" && c.Interval > 0
+}
+
 // IsOSService checks if this is a WindowsService/systemd type
 func (c *CheckType) IsOSService() bool {
 	return c.OSService != "" && c.Interval > 0
@@ -295,9 +446,111 @@ func (c *CheckType) Type() string {
 		return "script"
 	case c.IsH2PING():
 		return "h2ping"
+	case c.IsTLS():
+		return "tls"
+	case c.IsDNS():
+		return "dns"
 	case c.IsOSService():
 		return "os_service"
 	default:
 		return ""
 	}
 }
+
+// HTTPCheckAssertion is an assertion on the response of an HTTP check, on top
+// of its status code. Exactly one of Body, JSONPath, Header and MaxLatency
+// must be set.
+type HTTPCheckAssertion struct {
+	// Body is a regular expression that the body of the response must match.
+	Body string
+
+	// JSONPath is a JSONPath expression selecting a value in the body of the
+	// response, which must be a JSON document. The assertion fails when the
+	// expression selects nothing, or a value other than Value when it is set.
+	JSONPath string
+
+	// Header is the name of a header that the response must have, with the
+	// value Value when it is set.
+	Header string
+
+	// Value is the expected value of JSONPath or Header. Values that are not
+	// JSON strings are compared with their JSON encoding, e.g. true or 42.
+	Value string
+
+	// MaxLatency is the longest time the response may take to be received.
+	MaxLatency time.Duration
+
+	// Status is the status of the check when the assertion fails, critical
+	// by default. The check has the worst status of its failed assertions and
+	// of the status code of the response.
+	Status string
+}
+
+func (a *HTTPCheckAssertion) UnmarshalJSON(data []byte) (err error) {
+	type Alias HTTPCheckAssertion
+	aux := &struct {
+		MaxLatency interface{}
+
+		// Translate fields
+		JSONPathSnake   string      `json:"json_path"`
+		MaxLatencySnake interface{} `json:"max_latency"`
+
+		*Alias
+	}{
+		Alias: (*Alias)(a),
+	}
+	if err = lib.UnmarshalJSON(data, aux); err != nil {
+		return err
+	}
+
+	if a.JSONPath == "" {
+		a.JSONPath = aux.JSONPathSnake
+	}
+	if aux.MaxLatency == nil {
+		aux.MaxLatency = aux.MaxLatencySnake
+	}
+	if aux.MaxLatency != nil {
+		switch v := aux.MaxLatency.(type) {
+		case string:
+			if a.MaxLatency, err = time.ParseDuration(v); err != nil {
+				return err
+			}
+		case float64:
+			a.MaxLatency = time.Duration(v)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if the assertion is invalid.
+func (a *HTTPCheckAssertion) Validate() error {
+	set := 0
+	for _, ok := range []bool{a.Body != "", a.JSONPath != "", a.Header != "", a.MaxLatency != 0} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of Body, JSONPath, Header or MaxLatency must be set")
+	}
+	if a.Value != "" && a.JSONPath == "" && a.Header == "" {
+		return fmt.Errorf("Value can only be set with JSONPath or Header")
+	}
+	if a.Body != "" {
+		if _, err := regexp.Compile(a.Body); err != nil {
+			return fmt.Errorf("invalid Body regular expression: %w", err)
+		}
+	}
+	if a.JSONPath != "" {
+		if _, err := jsonpath.Parse(a.JSONPath); err != nil {
+			return err
+		}
+	}
+	if a.MaxLatency < 0 {
+		return fmt.Errorf("MaxLatency must be positive")
+	}
+	if a.Status != "" && !ValidStatus(a.Status) {
+		return fmt.Errorf("invalid Status %q", a.Status)
+	}
+	return nil
+}
@@ -54,6 +54,18 @@ const (
 	DefaultServiceProtocol = "tcp"
 
 	ConnectionExactBalance = "exact_balance"
+
+	// DNSAnswerOrderRandom shuffles the instances of a service in DNS answers.
+	DNSAnswerOrderRandom = "random"
+
+	// DNSAnswerOrderWeighted shuffles the instances of a service in DNS
+	// answers so that the ones with a higher weight come first more often.
+	DNSAnswerOrderWeighted = "weighted"
+
+	// DNSAnswerOrderNearest sorts the instances of a service in DNS answers
+	// so that the ones in the locality of the agent come first, followed by
+	// the others in order of estimated round trip time from the agent.
+	DNSAnswerOrderNearest = "nearest"
 )
 
 var AllConfigEntryKinds = []string{
@@ -180,6 +192,7 @@ type ServiceConfigEntry struct {
 	BalanceInboundConnections string                 `json:",omitempty" alias:"balance_inbound_connections"`
 	RateLimits                *RateLimits            `json:",omitempty" alias:"rate_limits"`
 	EnvoyExtensions           EnvoyExtensions        `json:",omitempty" alias:"envoy_extensions"`
+	DNSAnswerOrder            string                 `json:",omitempty" alias:"dns_answer_order"`
 
 	Meta               map[string]string `json:",omitempty"`
 	Hash               uint64            `json:",omitempty" hash:"ignore"`
@@ -273,6 +286,10 @@ func (e *ServiceConfigEntry) Validate() error {
 		validationErr = multierror.Append(validationErr, fmt.Errorf("invalid value for balance_inbound_connections: %v", e.BalanceInboundConnections))
 	}
 
+	if !IsValidDNSAnswerOrder(e.DNSAnswerOrder) {
+		validationErr = multierror.Append(validationErr, fmt.Errorf("invalid value for dns_answer_order: %v", e.DNSAnswerOrder))
+	}
+
 	switch e.Protocol {
 	case "", "http", "http2", "grpc", "tcp":
 	default:
@@ -1496,3 +1513,13 @@ type ConfigEntryDeleteResponse struct {
 func isValidConnectionBalance(s string) bool {
 	return s == "" || s == ConnectionExactBalance
 }
+
+// IsValidDNSAnswerOrder returns whether s is a known DNS answer order, the
+// empty string is the default order.
+func IsValidDNSAnswerOrder(s string) bool {
+	switch s {
+	case "", DNSAnswerOrderRandom, DNSAnswerOrderWeighted, DNSAnswerOrderNearest:
+		return true
+	}
+	return false
+}
@@ -426,6 +426,7 @@ func TestDecodeConfigEntry(t *testing.T) {
 				}
 				mutual_tls_mode = "permissive"
 				balance_inbound_connections = "exact_balance"
+				dns_answer_order = "weighted"
 				upstream_config {
 					overrides = [
 						{
@@ -473,6 +474,7 @@ func TestDecodeConfigEntry(t *testing.T) {
 				}
 				MutualTLSMode = "permissive"
 				BalanceInboundConnections = "exact_balance"
+				DNSAnswerOrder = "weighted"
 				UpstreamConfig {
 					Overrides = [
 						{
@@ -520,6 +522,7 @@ func TestDecodeConfigEntry(t *testing.T) {
 				},
 				MutualTLSMode:             MutualTLSModePermissive,
 				BalanceInboundConnections: "exact_balance",
+				DNSAnswerOrder:            "weighted",
 				UpstreamConfig: &UpstreamConfiguration{
 					Overrides: []*UpstreamConfig{
 						{
@@ -3198,6 +3201,15 @@ func TestServiceConfigEntry(t *testing.T) {
 			},
 			validateErr: "invalid value for balance_inbound_connections",
 		},
+		"validate: invalid dns answer order": {
+			entry: &ServiceConfigEntry{
+				Kind:           ServiceDefaults,
+				Name:           "external",
+				Protocol:       "http",
+				DNSAnswerOrder: "invalid",
+			},
+			validateErr: "invalid value for dns_answer_order",
+		},
 		"validate: invalid default outbound connection balance": {
 			entry: &ServiceConfigEntry{
 				Kind:     ServiceDefaults,
@@ -25,6 +25,7 @@ const (
 	errStateReadOnly                         = "CA Provider State is read-only"
 	errSamenessGroupNotFound                 = "Sameness Group not found"
 	errSamenessGroupMustBeDefaultForFailover = "Sameness Group must have DefaultForFailover set to true in order to use this endpoint"
+	errKVSQuotaExceeded                      = "KV quota exceeded"
 )
 
 var (
@@ -43,6 +44,7 @@ var (
 	ErrStateReadOnly                         = errors.New(errStateReadOnly)
 	ErrSamenessGroupNotFound                 = errors.New(errSamenessGroupNotFound)
 	ErrSamenessGroupMustBeDefaultForFailover = errors.New(errSamenessGroupMustBeDefaultForFailover)
+	ErrKVSQuotaExceeded                      = errors.New(errKVSQuotaExceeded)
 )
 
 func IsErrNoDCPath(err error) bool {
@@ -68,3 +70,21 @@ func IsErrSamenessGroupNotFound(err error) bool {
 func IsErrSamenessGroupMustBeDefaultForFailover(err error) bool {
 	return err != nil && strings.Contains(err.Error(), errSamenessGroupMustBeDefaultForFailover)
 }
+
+// IsErrKVSQuotaExceeded returns true if err wraps ErrKVSQuotaExceeded. Errors
+// returned over RPC or as part of a transaction lose their type, in which case
+// their message must start with the one of ErrKVSQuotaExceeded.
+func IsErrKVSQuotaExceeded(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrKVSQuotaExceeded) {
+		return true
+	}
+
+	var txnErr *TxnError
+	if errors.As(err, &txnErr) {
+		return strings.HasPrefix(txnErr.What, errKVSQuotaExceeded)
+	}
+	return strings.HasPrefix(err.Error(), errKVSQuotaExceeded)
+}
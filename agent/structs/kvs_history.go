@@ -0,0 +1,89 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package structs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KVSHistoryMaxRevisions is the largest number of previous revisions that
+// can be retained for a single key.
+const KVSHistoryMaxRevisions = 1000
+
+// KVSHistoryConfig controls how many previous revisions are retained for keys
+// under a prefix. When several configurations match a key the one with the
+// longest prefix wins.
+type KVSHistoryConfig struct {
+	// Prefix is the KV prefix the configuration applies to. An empty prefix
+	// applies to every key.
+	Prefix string
+
+	// Revisions is the number of previous revisions to keep per key.
+	Revisions int
+
+	RaftIndex
+}
+
+func (c *KVSHistoryConfig) Validate() error {
+	if strings.HasPrefix(c.Prefix, "/") {
+		return fmt.Errorf("Prefix must not begin with a '/'")
+	}
+	if c.Revisions < 1 {
+		return fmt.Errorf("Revisions must be at least 1")
+	}
+	if c.Revisions > KVSHistoryMaxRevisions {
+		return fmt.Errorf("Revisions must not be greater than %d", KVSHistoryMaxRevisions)
+	}
+	return nil
+}
+
+type KVSHistoryConfigOp string
+
+const (
+	KVSHistoryConfigUpsert KVSHistoryConfigOp = "upsert"
+	KVSHistoryConfigDelete KVSHistoryConfigOp = "delete"
+)
+
+// KVSHistoryConfigRequest is used to create, update or delete the revision
+// retention for a KV prefix.
+type KVSHistoryConfigRequest struct {
+	Datacenter string
+	Op         KVSHistoryConfigOp
+	Config     KVSHistoryConfig
+	WriteRequest
+}
+
+func (r *KVSHistoryConfigRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// IndexedKVSHistoryConfigs is used to return the revision retention
+// configurations along with the query metadata.
+type IndexedKVSHistoryConfigs struct {
+	Configs []*KVSHistoryConfig
+	QueryMeta
+}
+
+// DirEntryRevision is a previous version of a KV entry that was retained
+// because the key matched a KVSHistoryConfig. The embedded entry is the
+// value as it was written at its ModifyIndex.
+type DirEntryRevision struct {
+	DirEntry
+
+	// SupersededIndex is the Raft index at which this revision was replaced
+	// or deleted. It is zero for the current value of a key.
+	SupersededIndex uint64
+
+	// Deleted is true if the revision was removed by a delete rather than
+	// replaced by a newer value.
+	Deleted bool
+}
+
+// IndexedDirEntryRevisions is used to return the revisions of a key, oldest
+// first, along with the query metadata.
+type IndexedDirEntryRevisions struct {
+	Revisions []*DirEntryRevision
+	QueryMeta
+}
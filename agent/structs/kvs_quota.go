@@ -0,0 +1,72 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package structs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/consul/acl"
+)
+
+// KVSQuota limits the keys stored under a KV prefix in a namespace. A zero
+// limit means that dimension is not limited. Quotas only prevent growth;
+// lowering a quota below the current usage does not remove any data.
+type KVSQuota struct {
+	// Prefix is the KV prefix the quota applies to. An empty prefix applies
+	// to every key.
+	Prefix string
+
+	// MaxKeys is the maximum number of keys under the prefix.
+	MaxKeys int
+
+	// MaxBytes is the maximum combined size of the values under the prefix.
+	MaxBytes int64
+
+	// MaxValueSize is the maximum size of a single value under the prefix.
+	MaxValueSize int64
+
+	acl.EnterpriseMeta `hcl:",squash" mapstructure:",squash"`
+	RaftIndex
+}
+
+func (q *KVSQuota) Validate() error {
+	if strings.HasPrefix(q.Prefix, "/") {
+		return fmt.Errorf("Prefix must not begin with a '/'")
+	}
+	if q.MaxKeys < 0 || q.MaxBytes < 0 || q.MaxValueSize < 0 {
+		return fmt.Errorf("Quota limits must not be negative")
+	}
+	if q.MaxKeys == 0 && q.MaxBytes == 0 && q.MaxValueSize == 0 {
+		return fmt.Errorf("At least one of MaxKeys, MaxBytes or MaxValueSize must be set")
+	}
+	return nil
+}
+
+type KVSQuotaOp string
+
+const (
+	KVSQuotaUpsert KVSQuotaOp = "upsert"
+	KVSQuotaDelete KVSQuotaOp = "delete"
+)
+
+// KVSQuotaRequest is used to create, update or delete the quota for a KV
+// prefix.
+type KVSQuotaRequest struct {
+	Datacenter string
+	Op         KVSQuotaOp
+	Quota      KVSQuota
+	WriteRequest
+}
+
+func (r *KVSQuotaRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// IndexedKVSQuotas is used to return the KV quotas along with the query
+// metadata.
+type IndexedKVSQuotas struct {
+	Quotas []*KVSQuota
+	QueryMeta
+}
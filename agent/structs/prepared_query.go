@@ -313,6 +313,7 @@ func (q *PreparedQueryExecuteRequest) CacheInfo() cache.RequestInfo {
 		q.QueryIDOrName,
 		q.Limit,
 		q.Connect,
+		q.Source,
 	}, nil)
 	if err == nil {
 		// If there is an error, we don't set the key. A blank key forces
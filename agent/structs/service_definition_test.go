@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/types"
 )
 
 func TestAgentStructs_CheckTypes(t *testing.T) {
@@ -47,6 +49,21 @@ func TestAgentStructs_CheckTypes(t *testing.T) {
 		{&CheckType{HTTP: "http://foo/baz"}, fmt.Errorf("Interval must be > 0 for Script, HTTP, or TCP checks"), "Missing interval"},
 		{&CheckType{TTL: -1}, fmt.Errorf("TTL must be > 0 for TTL checks"), "Negative TTL"},
 		{&CheckType{TTL: 20 * time.Second, Interval: 10 * time.Second}, fmt.Errorf("Interval and TTL cannot both be specified"), "Interval and TTL both set"},
+		{&CheckType{TTL: 10 * time.Second, HTTPAssertions: []HTTPCheckAssertion{{Body: "ok"}}}, fmt.Errorf("HTTPAssertions can only be set for HTTP checks"), "HTTP assertions on a TTL check"},
+		{&CheckType{HTTP: "http://foo/baz", Interval: 10 * time.Second, HTTPAssertions: []HTTPCheckAssertion{{Body: "ok", Header: "X-Status"}}}, fmt.Errorf("HTTPAssertions[0]: exactly one of Body, JSONPath, Header or MaxLatency must be set"), "HTTP assertion with two conditions"},
+		{&CheckType{HTTP: "http://foo/baz", Interval: 10 * time.Second, HTTPAssertions: []HTTPCheckAssertion{{JSONPath: "status"}}}, fmt.Errorf(`HTTPAssertions[0]: invalid JSONPath "status": must start with $`), "Invalid JSONPath"},
+		{&CheckType{HTTP: "http://foo/baz", Interval: 10 * time.Second, HTTPAssertions: []HTTPCheckAssertion{{Body: "ok", Status: "degraded"}}}, fmt.Errorf(`HTTPAssertions[0]: invalid Status "degraded"`), "Invalid HTTP assertion status"},
+		{&CheckType{HTTP: "http://foo/baz", Interval: 10 * time.Second, TLSExpiryWarningDays: 30}, fmt.Errorf("TLSExpiryWarningDays and TLSExpiryCriticalDays can only be set for TLS checks"), "TLS expiry on an HTTP check"},
+		{&CheckType{TLS: "foo:443", Interval: 10 * time.Second, TLSExpiryWarningDays: 7, TLSExpiryCriticalDays: 30}, fmt.Errorf("TLSExpiryCriticalDays (30) must be lower than TLSExpiryWarningDays (7)"), "TLS critical days higher than warning days"},
+		{&CheckType{TLS: "foo:443", Interval: 10 * time.Second, TLSExpiryWarningDays: 14, TLSExpiryCriticalDays: 14}, fmt.Errorf("TLSExpiryCriticalDays (14) must be lower than TLSExpiryWarningDays (14)"), "TLS critical days equal to warning days"},
+		{&CheckType{TLS: "foo:443", Interval: 10 * time.Second, TLSExpiryWarningDays: 5}, fmt.Errorf("TLSExpiryCriticalDays (7) must be lower than TLSExpiryWarningDays (5)"), "TLS warning days below the default critical days"},
+		{&CheckType{TLS: "foo:443", Interval: 10 * time.Second, TLSExpiryCriticalDays: 45}, fmt.Errorf("TLSExpiryCriticalDays (45) must be lower than TLSExpiryWarningDays (30)"), "TLS critical days above the default warning days"},
+		{&CheckType{TLS: "foo:443"}, fmt.Errorf("Interval must be > 0 for Script, HTTP, H2PING, TCP, UDP, TLS, DNS or OSService checks"), "TLS check without interval"},
+		{&CheckType{DNS: "example.com", Interval: 10 * time.Second, DNSQueryType: "BOGUS"}, fmt.Errorf(`invalid DNSQueryType "BOGUS"`), "Invalid DNS query type"},
+		{&CheckType{DNS: "example.com", Interval: 10 * time.Second, DNSRcode: "BROKEN"}, fmt.Errorf(`invalid DNSRcode "BROKEN"`), "Invalid DNS rcode"},
+		{&CheckType{TCP: "foo:80", Interval: 10 * time.Second, DNSExpectedValues: []string{"10.0.0.1"}}, fmt.Errorf("DNSQueryType, DNSResolver, DNSExpectedValues, DNSRcode and DNSMaxLatency can only be set for DNS checks"), "DNS fields on a TCP check"},
+		{&CheckType{TTL: 20 * time.Second, DependsOn: []types.CheckID{""}}, fmt.Errorf("DependsOn can't contain an empty check ID"), "Empty dependency"},
+		{&CheckType{CheckID: "foo", TTL: 20 * time.Second, DependsOn: []types.CheckID{"bar", "foo"}}, fmt.Errorf(`check "foo" can't depend on itself`), "Dependency on itself"},
 	}
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
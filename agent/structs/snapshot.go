@@ -32,6 +32,13 @@ type SnapshotRequest struct {
 
 	// Op is the operation code for the RPC.
 	Op SnapshotOp
+
+	// BaseIndex and BaseHash identify the base snapshot for an incremental
+	// snapshot, which holds only the Raft log entries applied since the
+	// base. A full snapshot is taken if BaseIndex is zero. Only applies to
+	// SnapshotSave.
+	BaseIndex uint64
+	BaseHash  string
 }
 
 // SnapshotResponse is used header for a snapshot RPC response. This will
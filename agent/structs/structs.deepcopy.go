@@ -106,6 +106,10 @@ func (o *CheckType) DeepCopy() *CheckType {
 			cp.Header[k2] = cp_Header_v2
 		}
 	}
+	if o.DependsOn != nil {
+		cp.DependsOn = make([]types.CheckID, len(o.DependsOn))
+		copy(cp.DependsOn, o.DependsOn)
+	}
 	return &cp
 }
 
@@ -662,6 +666,10 @@ func (o *HealthCheck) DeepCopy() *HealthCheck {
 		cp.ServiceTags = make([]string, len(o.ServiceTags))
 		copy(cp.ServiceTags, o.ServiceTags)
 	}
+	if o.DependsOn != nil {
+		cp.DependsOn = make([]types.CheckID, len(o.DependsOn))
+		copy(cp.DependsOn, o.DependsOn)
+	}
 	if o.Definition.Header != nil {
 		cp.Definition.Header = make(map[string][]string, len(o.Definition.Header))
 		for k3, v3 := range o.Definition.Header {
@@ -93,6 +93,10 @@ const (
 	UpdateVirtualIPRequestType                  = 43
 	CensusRequestType                           = 44
 	FeatureGateRequestType                      = 45
+	KVSHistoryConfigRequestType                 = 46
+	KVSRevisionType                             = 47 // FSM snapshots only.
+	KVSQuotaRequestType                         = 48
+	ACLTokenLastUsedSetRequestType              = 49
 )
 
 const (
@@ -164,6 +168,10 @@ var requestTypeStrings = map[MessageType]string{
 	UpdateVirtualIPRequestType:      "UpdateManualVirtualIPRequestType",
 	CensusRequestType:               "Census",
 	FeatureGateRequestType:          "FeatureGate",
+	KVSHistoryConfigRequestType:     "KVSHistoryConfig",
+	KVSRevisionType:                 "KVSRevision", // FSM snapshots only.
+	KVSQuotaRequestType:             "KVSQuota",
+	ACLTokenLastUsedSetRequestType:  "ACLTokenLastUsed",
 }
 
 const (
@@ -595,7 +603,9 @@ type QuerySource struct {
 	// for determining the flow of the RPC. This is needed for agentless + wanfed to
 	// utilize streaming RPCs.
 	DisableNode bool `json:",omitempty"`
-	Ip          string
+	// Ip is the address of the client, or the subnet of the client in CIDR
+	// notation when only its network is known.
+	Ip string
 }
 
 func (s QuerySource) NodeEnterpriseMeta() *acl.EnterpriseMeta {
@@ -842,6 +852,9 @@ func (r *ServiceSpecificRequest) CacheInfo() cache.RequestInfo {
 		r.ServiceKind,
 		r.MergeCentralConfig,
 		r.HealthFilterType,
+		// Source is only set when the results are sorted by distance from it,
+		// which must not share an entry with unsorted results.
+		r.Source,
 	}, nil)
 	if err == nil {
 		// If there is an error, we don't set the key. A blank key forces
@@ -1955,6 +1968,20 @@ type HealthCheck struct {
 	Interval string // from definition
 	Timeout  string // from definition
 
+	// DependsOn is the list of the IDs of the checks of the same node this
+	// check depends on.
+	DependsOn []types.CheckID `json:",omitempty"`
+
+	// SuppressedBy is the ID of the check at the root of the failure of this
+	// check when it is critical while one of the checks it depends on is
+	// critical too. Alerts on this check can be suppressed in favor of the
+	// ones on SuppressedBy.
+	SuppressedBy types.CheckID `json:",omitempty"`
+
+	// Flapping is true while the status of the check changes too often to be
+	// meaningful. The status is then held steady until it stabilizes.
+	Flapping bool `json:",omitempty"`
+
 	// ExposedPort is the port of the exposed Envoy listener representing the
 	// HTTP or GRPC health check of the service.
 	ExposedPort int
@@ -2143,6 +2170,9 @@ func (c *HealthCheck) IsSame(other *HealthCheck) bool {
 		c.ServiceID != other.ServiceID ||
 		c.ServiceName != other.ServiceName ||
 		!reflect.DeepEqual(c.ServiceTags, other.ServiceTags) ||
+		!reflect.DeepEqual(c.DependsOn, other.DependsOn) ||
+		c.SuppressedBy != other.SuppressedBy ||
+		c.Flapping != other.Flapping ||
 		!reflect.DeepEqual(c.Definition, other.Definition) ||
 		c.PeerName != other.PeerName ||
 		!c.EnterpriseMeta.IsSame(&other.EnterpriseMeta) {
@@ -2153,7 +2183,7 @@ func (c *HealthCheck) IsSame(other *HealthCheck) bool {
 }
 
 // Clone returns a distinct clone of the HealthCheck. Note that the
-// "ServiceTags" and "Definition.Header" field are not deep copied.
+// "ServiceTags", "DependsOn" and "Definition.Header" field are not deep copied.
 func (c *HealthCheck) Clone() *HealthCheck {
 	clone := new(HealthCheck)
 	*clone = *c
@@ -2801,6 +2831,22 @@ type DirEntry struct {
 	Value     []byte
 	Session   string `json:",omitempty"`
 
+	// ExpirationTime, if set, is the time after which the leader will
+	// delete this entry. It is computed from ExpirationTTL when the write
+	// is applied and is replicated as an absolute time so all servers agree.
+	ExpirationTime *time.Time `json:",omitempty"`
+
+	// ExpirationTTL is a convenience field for setting ExpirationTime
+	// relative to the time the write is received by the leader. It is only
+	// used on input and is cleared before the entry is committed.
+	ExpirationTTL time.Duration `json:",omitempty"`
+
+	// FencingToken is the Raft index at which the current (or most recent)
+	// lock holder acquired the lock on this entry. Tokens only increase, so
+	// they can be passed along with writes to other systems to reject
+	// requests from a holder that has since lost the lock.
+	FencingToken uint64 `json:",omitempty"`
+
 	acl.EnterpriseMeta `bexpr:"-"`
 	RaftIndex
 }
@@ -2817,6 +2863,9 @@ func (d *DirEntry) Clone() *DirEntry {
 			CreateIndex: d.CreateIndex,
 			ModifyIndex: d.ModifyIndex,
 		},
+		ExpirationTime: d.ExpirationTime,
+		ExpirationTTL:  d.ExpirationTTL,
+		FencingToken:   d.FencingToken,
 		EnterpriseMeta: d.EnterpriseMeta,
 	}
 }
@@ -2826,7 +2875,24 @@ func (d *DirEntry) Equal(o *DirEntry) bool {
 		d.Key == o.Key &&
 		d.Flags == o.Flags &&
 		bytes.Equal(d.Value, o.Value) &&
-		d.Session == o.Session
+		d.Session == o.Session &&
+		d.FencingToken == o.FencingToken &&
+		d.HasExpirationTime() == o.HasExpirationTime() &&
+		(!d.HasExpirationTime() || d.ExpirationTime.Equal(*o.ExpirationTime))
+}
+
+// HasExpirationTime returns true if the entry is scheduled to expire.
+func (d *DirEntry) HasExpirationTime() bool {
+	return d.ExpirationTime != nil && !d.ExpirationTime.IsZero()
+}
+
+// IsExpired returns true if the entry has an expiration time that is before
+// the given time.
+func (d *DirEntry) IsExpired(asOf time.Time) bool {
+	if asOf.IsZero() || !d.HasExpirationTime() {
+		return false
+	}
+	return d.ExpirationTime.Before(asOf)
 }
 
 // IDValue implements the state.singleValueID interface for indexing.
@@ -2852,6 +2918,11 @@ func (r *KVSRequest) RequestDatacenter() string {
 type KeyRequest struct {
 	Datacenter string
 	Key        string
+
+	// AtIndex, if non-zero, requests the value the key had at the given
+	// Raft index. Only revisions retained by a KVSHistoryConfig can be read.
+	AtIndex uint64
+
 	acl.EnterpriseMeta
 	QueryOptions
 }
@@ -2146,12 +2146,14 @@ func (a aclAuthorizerCheckServiceNode) NodeRead(_ string, ctx *acl.AuthorizerCon
 }
 
 func TestStructs_DirEntry_Clone(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
 	e := &DirEntry{
-		LockIndex: 5,
-		Key:       "hello",
-		Flags:     23,
-		Value:     []byte("this is a test"),
-		Session:   "session1",
+		LockIndex:      5,
+		Key:            "hello",
+		Flags:          23,
+		Value:          []byte("this is a test"),
+		Session:        "session1",
+		ExpirationTime: &expires,
 		RaftIndex: RaftIndex{
 			CreateIndex: 1,
 			ModifyIndex: 2,
@@ -2509,6 +2511,16 @@ func TestSpecificServiceRequest_CacheInfo(t *testing.T) {
 				req.Ingress = true
 			},
 		},
+		{
+			name: "source should be considered",
+			req: ServiceSpecificRequest{
+				Datacenter:  "dc1",
+				ServiceName: "my-service",
+			},
+			mutate: func(req *ServiceSpecificRequest) {
+				req.Source = QuerySource{Datacenter: "dc1", Node: "node1"}
+			},
+		},
 	}
 
 	for _, tc := range tests {
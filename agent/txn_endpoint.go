@@ -160,10 +160,11 @@ func (s *HTTPHandlers) convertOps(resp http.ResponseWriter, req *http.Request) (
 				KV: &structs.TxnKVOp{
 					Verb: verb,
 					DirEnt: structs.DirEntry{
-						Key:     in.KV.Key,
-						Value:   in.KV.Value,
-						Flags:   in.KV.Flags,
-						Session: in.KV.Session,
+						Key:          in.KV.Key,
+						Value:        in.KV.Value,
+						Flags:        in.KV.Flags,
+						Session:      in.KV.Session,
+						FencingToken: in.KV.FencingToken,
 						EnterpriseMeta: acl.NewEnterpriseMetaWithPartition(
 							in.KV.Partition,
 							in.KV.Namespace,
@@ -292,11 +292,12 @@ func TestTxnEndpoint_KV_Actions(t *testing.T) {
 				Results: structs.TxnResults{
 					&structs.TxnResult{
 						KV: &structs.DirEntry{
-							Key:       "key",
-							Value:     nil,
-							Flags:     23,
-							Session:   id,
-							LockIndex: 1,
+							Key:          "key",
+							Value:        nil,
+							Flags:        23,
+							Session:      id,
+							LockIndex:    1,
+							FencingToken: index,
 							RaftIndex: structs.RaftIndex{
 								CreateIndex: index,
 								ModifyIndex: index,
@@ -306,11 +307,12 @@ func TestTxnEndpoint_KV_Actions(t *testing.T) {
 					},
 					&structs.TxnResult{
 						KV: &structs.DirEntry{
-							Key:       "key",
-							Value:     []byte("hello world"),
-							Flags:     23,
-							Session:   id,
-							LockIndex: 1,
+							Key:          "key",
+							Value:        []byte("hello world"),
+							Flags:        23,
+							Session:      id,
+							LockIndex:    1,
+							FencingToken: index,
 							RaftIndex: structs.RaftIndex{
 								CreateIndex: index,
 								ModifyIndex: index,
@@ -371,11 +373,12 @@ func TestTxnEndpoint_KV_Actions(t *testing.T) {
 					Results: structs.TxnResults{
 						&structs.TxnResult{
 							KV: &structs.DirEntry{
-								Key:       "key",
-								Value:     []byte("hello world"),
-								Flags:     23,
-								Session:   id,
-								LockIndex: 1,
+								Key:          "key",
+								Value:        []byte("hello world"),
+								Flags:        23,
+								Session:      id,
+								LockIndex:    1,
+								FencingToken: index,
 								RaftIndex: structs.RaftIndex{
 									CreateIndex: index,
 									ModifyIndex: index,
@@ -385,11 +388,12 @@ func TestTxnEndpoint_KV_Actions(t *testing.T) {
 						},
 						&structs.TxnResult{
 							KV: &structs.DirEntry{
-								Key:       "key",
-								Value:     []byte("hello world"),
-								Flags:     23,
-								Session:   id,
-								LockIndex: 1,
+								Key:          "key",
+								Value:        []byte("hello world"),
+								Flags:        23,
+								Session:      id,
+								LockIndex:    1,
+								FencingToken: index,
 								RaftIndex: structs.RaftIndex{
 									CreateIndex: index,
 									ModifyIndex: index,
@@ -453,9 +457,10 @@ func TestTxnEndpoint_KV_Actions(t *testing.T) {
 				Results: structs.TxnResults{
 					&structs.TxnResult{
 						KV: &structs.DirEntry{
-							Key:     "key",
-							Value:   nil,
-							Session: id,
+							Key:          "key",
+							Value:        nil,
+							Session:      id,
+							FencingToken: index,
 							RaftIndex: structs.RaftIndex{
 								CreateIndex: index,
 								ModifyIndex: modIndex,
@@ -465,9 +470,10 @@ func TestTxnEndpoint_KV_Actions(t *testing.T) {
 					},
 					&structs.TxnResult{
 						KV: &structs.DirEntry{
-							Key:     "key",
-							Value:   []byte("goodbye world"),
-							Session: id,
+							Key:          "key",
+							Value:        []byte("goodbye world"),
+							Session:      id,
+							FencingToken: index,
 							RaftIndex: structs.RaftIndex{
 								CreateIndex: index,
 								ModifyIndex: modIndex,
@@ -57,6 +57,11 @@ type ACLToken struct {
 	CreateTime        time.Time     `json:",omitempty"`
 	Hash              []byte        `json:",omitempty"`
 
+	// LastUsed is when and where the token was last used. It is tracked at a
+	// coarse granularity and is nil for tokens never used since the servers
+	// started tracking it.
+	LastUsed *ACLTokenLastUsed `json:",omitempty"`
+
 	// DEPRECATED (ACL-Legacy-Compat)
 	// Rules are an artifact of legacy tokens deprecated in Consul 1.4
 	Rules string `json:"-"`
@@ -74,6 +79,20 @@ type ACLToken struct {
 	AuthMethodNamespace string `json:",omitempty"`
 }
 
+// ACLTokenLastUsed describes the last use of a token.
+type ACLTokenLastUsed struct {
+	// Time is when the token was last used, within the servers' tracking
+	// granularity of an hour by default.
+	Time time.Time
+
+	// Server is the name of the server that resolved the token, which is not
+	// necessarily the node the request using the token was made to.
+	Server string
+
+	// Datacenter is the datacenter of the server that resolved the token.
+	Datacenter string
+}
+
 type ACLTokenExpanded struct {
 	ExpandedPolicies []ACLPolicy
 	ExpandedRoles    []ACLRole
@@ -107,6 +126,11 @@ type ACLTokenListEntry struct {
 	Hash              []byte
 	Legacy            bool `json:"-"` // DEPRECATED
 
+	// LastUsed is when and where the token was last used. It is tracked at a
+	// coarse granularity and is nil for tokens never used since the servers
+	// started tracking it.
+	LastUsed *ACLTokenLastUsed `json:",omitempty"`
+
 	// Namespace is the namespace the ACLTokenListEntry is associated with.
 	// Namespacing is a Consul Enterprise feature.
 	Namespace string `json:",omitempty"`
@@ -75,6 +75,11 @@ type AgentCheck struct {
 	Definition  HealthCheckDefinition
 	Namespace   string `json:",omitempty"`
 	Partition   string `json:",omitempty"`
+
+	// DependsOn, SuppressedBy and Flapping are the same as in HealthCheck.
+	DependsOn    []string `json:",omitempty"`
+	SuppressedBy string   `json:",omitempty"`
+	Flapping     bool     `json:",omitempty"`
 }
 
 // AgentWeights represent optional weights for a service
@@ -439,6 +444,36 @@ type AgentServiceCheck struct {
 	FailuresBeforeWarning  int                 `json:",omitempty"`
 	FailuresBeforeCritical int                 `json:",omitempty"`
 
+	// HTTPAssertions are checked against the response of an HTTP check, on
+	// top of its status code.
+	HTTPAssertions []HTTPCheckAssertion `json:",omitempty"`
+
+	// TLS is the address of a TLS check, which performs a handshake with it
+	// and goes warning or critical when one of the certificates presented
+	// expires within TLSExpiryWarningDays, 30 by default, or
+	// TLSExpiryCriticalDays, 7 by default.
+	TLS                   string `json:",omitempty"`
+	TLSExpiryWarningDays  int    `json:",omitempty"`
+	TLSExpiryCriticalDays int    `json:",omitempty"`
+
+	// DNS is the name queried by a DNS check, for the record type
+	// DNSQueryType, A by default, against DNSResolver, the first nameserver
+	// of /etc/resolv.conf by default. The check is critical unless the
+	// response code is DNSRcode, NOERROR by default, with at least one answer
+	// when it is NOERROR, and the answers include each of DNSExpectedValues.
+	// It is warning when the response takes longer than DNSMaxLatency.
+	DNS               string   `json:",omitempty"`
+	DNSQueryType      string   `json:",omitempty"`
+	DNSResolver       string   `json:",omitempty"`
+	DNSExpectedValues []string `json:",omitempty"`
+	DNSRcode          string   `json:",omitempty"`
+	DNSMaxLatency     string   `json:",omitempty"`
+
+	// DependsOn is the list of the IDs of the checks of the same node this
+	// check depends on. While one of them is critical, this check is reported
+	// with the root cause in SuppressedBy when it is critical too.
+	DependsOn []string `json:",omitempty"`
+
 	// In Consul 0.7 and later, checks that are associated with a service
 	// may also contain this optional DeregisterCriticalServiceAfter field,
 	// which is a timeout in the same Go time format as Interval and TTL. If
@@ -449,6 +484,32 @@ type AgentServiceCheck struct {
 }
 type AgentServiceChecks []*AgentServiceCheck
 
+// HTTPCheckAssertion is an assertion on the response of an HTTP check. Exactly
+// one of Body, JSONPath, Header and MaxLatency must be set.
+type HTTPCheckAssertion struct {
+	// Body is a regular expression that the body of the response must match.
+	Body string `json:",omitempty"`
+
+	// JSONPath selects a value in the JSON body of the response, which must
+	// exist and be equal to Value when it is set.
+	JSONPath string `json:",omitempty"`
+
+	// Header is the name of a header that the response must have, with the
+	// value Value when it is set.
+	Header string `json:",omitempty"`
+
+	// Value is the expected value of JSONPath or Header.
+	Value string `json:",omitempty"`
+
+	// MaxLatency is the longest time the response may take, in the same Go
+	// time format as Interval.
+	MaxLatency string `json:",omitempty"`
+
+	// Status is the status of the check when the assertion fails: passing,
+	// warning or critical, the default.
+	Status string `json:",omitempty"`
+}
+
 // AgentToken is used when updating ACL tokens for an agent.
 type AgentToken struct {
 	Token string
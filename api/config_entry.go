@@ -393,6 +393,7 @@ type ServiceConfigEntry struct {
 	BalanceInboundConnections string                  `json:",omitempty" alias:"balance_inbound_connections"`
 	RateLimits                *RateLimits             `json:",omitempty" alias:"rate_limits"`
 	EnvoyExtensions           []EnvoyExtension        `json:",omitempty" alias:"envoy_extensions"`
+	DNSAnswerOrder            string                  `json:",omitempty" alias:"dns_answer_order"`
 	Meta                      map[string]string       `json:",omitempty"`
 	CreateIndex               uint64
 	ModifyIndex               uint64
@@ -114,6 +114,7 @@ func TestAPI_ConfigEntries(t *testing.T) {
 			BalanceInboundConnections: "exact_balance",
 			LocalConnectTimeoutMs:     5000,
 			LocalRequestTimeoutMs:     7000,
+			DNSAnswerOrder:            "nearest",
 		}
 
 		dest := &DestinationConfig{
@@ -159,6 +160,7 @@ func TestAPI_ConfigEntries(t *testing.T) {
 		require.Equal(t, service.BalanceInboundConnections, readService.BalanceInboundConnections)
 		require.Equal(t, service.LocalConnectTimeoutMs, readService.LocalConnectTimeoutMs)
 		require.Equal(t, service.LocalRequestTimeoutMs, readService.LocalRequestTimeoutMs)
+		require.Equal(t, service.DNSAnswerOrder, readService.DNSAnswerOrder)
 
 		// update it
 		service.Protocol = "tcp"
@@ -477,6 +479,7 @@ func TestDecodeConfigEntry(t *testing.T) {
 					"DialedDirectly": true
 				},
 				"BalanceInboundConnections": "exact_balance",
+				"DNSAnswerOrder": "weighted",
 				"UpstreamConfig": {
 					"Overrides": [
 						{
@@ -537,6 +540,7 @@ func TestDecodeConfigEntry(t *testing.T) {
 					DialedDirectly:       true,
 				},
 				BalanceInboundConnections: "exact_balance",
+				DNSAnswerOrder:            "weighted",
 				UpstreamConfig: &UpstreamConfiguration{
 					Overrides: []*UpstreamConfig{
 						{
@@ -51,6 +51,19 @@ type HealthCheck struct {
 	ExposedPort int
 	PeerName    string `json:",omitempty"`
 
+	// DependsOn is the list of the IDs of the checks of the same node this
+	// check depends on.
+	DependsOn []string `json:",omitempty"`
+
+	// SuppressedBy is the ID of the check at the root of the failure of this
+	// check, set when it is critical while one of the checks it depends on is
+	// critical too.
+	SuppressedBy string `json:",omitempty"`
+
+	// Flapping is true while the status of the check changes too often to be
+	// meaningful. The status is then held steady until it stabilizes.
+	Flapping bool `json:",omitempty"`
+
 	Definition HealthCheckDefinition
 
 	CreateIndex uint64
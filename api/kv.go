@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // KVPair is used to represent a single K/V entry
@@ -44,6 +45,23 @@ type KVPair struct {
 	// session ID.
 	Session string
 
+	// ExpirationTime is the time after which the key will be deleted by the
+	// servers. It is nil if the key does not expire. This is a read-only
+	// field; use ExpirationTTL to set it.
+	ExpirationTime *time.Time `json:",omitempty"`
+
+	// ExpirationTTL is the duration after which the key will be deleted,
+	// relative to when the write is applied. It is only respected by Put,
+	// CAS and Acquire and is never returned by reads.
+	ExpirationTTL time.Duration `json:",omitempty"`
+
+	// FencingToken is the Raft index at which the current lock holder
+	// acquired the lock on this key. It only increases, so a holder can pass
+	// it to other systems, or to a check-fence transaction operation, to
+	// have writes rejected once the lock has moved on. This is a read-only
+	// field.
+	FencingToken uint64 `json:",omitempty"`
+
 	// Namespace is the namespace the KVPair is associated with
 	// Namespacing is a Consul Enterprise feature.
 	Namespace string `json:",omitempty"`
@@ -56,6 +74,20 @@ type KVPair struct {
 // KVPairs is a list of KVPair objects
 type KVPairs []*KVPair
 
+// KVPairRevision is a previous value of a key that was retained because the
+// key is covered by a KV history configuration.
+type KVPairRevision struct {
+	KVPair
+
+	// SupersededIndex is the index at which this revision was replaced or
+	// deleted. It is zero for the current value of the key.
+	SupersededIndex uint64
+
+	// Deleted is true if the revision was removed by a delete rather than
+	// replaced by a newer value.
+	Deleted bool
+}
+
 // KV is used to manipulate the K/V API
 type KV struct {
 	c *Client
@@ -88,6 +120,49 @@ func (k *KV) Get(key string, q *QueryOptions) (*KVPair, *QueryMeta, error) {
 	return nil, qm, nil
 }
 
+// GetAtIndex is used to lookup the value a key had at the given index. The
+// returned pointer to the KVPair will be nil if the key did not exist at that
+// index or if that revision is no longer retained.
+func (k *KV) GetAtIndex(key string, index uint64, q *QueryOptions) (*KVPair, *QueryMeta, error) {
+	params := map[string]string{"at-index": strconv.FormatUint(index, 10)}
+	resp, qm, err := k.getInternal(key, params, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp == nil {
+		return nil, qm, nil
+	}
+	defer closeResponseBody(resp)
+
+	var entries []*KVPair
+	if err := decodeBody(resp, &entries); err != nil {
+		return nil, nil, err
+	}
+	if len(entries) > 0 {
+		return entries[0], qm, nil
+	}
+	return nil, qm, nil
+}
+
+// Revisions is used to lookup the retained revisions of a key, oldest first.
+// If the key currently exists its value is the last revision.
+func (k *KV) Revisions(key string, q *QueryOptions) ([]*KVPairRevision, *QueryMeta, error) {
+	resp, qm, err := k.getInternal(key, map[string]string{"revisions": ""}, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp == nil {
+		return nil, qm, nil
+	}
+	defer closeResponseBody(resp)
+
+	var entries []*KVPairRevision
+	if err := decodeBody(resp, &entries); err != nil {
+		return nil, nil, err
+	}
+	return entries, qm, nil
+}
+
 // List is used to lookup all keys under a prefix
 func (k *KV) List(prefix string, q *QueryOptions) (KVPairs, *QueryMeta, error) {
 	resp, qm, err := k.getInternal(prefix, map[string]string{"recurse": ""}, q)
@@ -160,34 +235,43 @@ func (k *KV) getInternal(key string, params map[string]string, q *QueryOptions)
 // Put is used to write a new value. Only the
 // Key, Flags and Value is respected.
 func (k *KV) Put(p *KVPair, q *WriteOptions) (*WriteMeta, error) {
-	params := make(map[string]string, 1)
+	params := make(map[string]string, 2)
 	if p.Flags != 0 {
 		params["flags"] = strconv.FormatUint(p.Flags, 10)
 	}
+	if p.ExpirationTTL != 0 {
+		params["ttl"] = p.ExpirationTTL.String()
+	}
 	_, wm, err := k.put(p.Key, params, p.Value, q)
 	return wm, err
 }
 
 // CAS is used for a Check-And-Set operation. The Key,
-// ModifyIndex, Flags and Value are respected. Returns true
+// ModifyIndex, Flags, ExpirationTTL and Value are respected. Returns true
 // on success or false on failures.
 func (k *KV) CAS(p *KVPair, q *WriteOptions) (bool, *WriteMeta, error) {
-	params := make(map[string]string, 2)
+	params := make(map[string]string, 3)
 	if p.Flags != 0 {
 		params["flags"] = strconv.FormatUint(p.Flags, 10)
 	}
+	if p.ExpirationTTL != 0 {
+		params["ttl"] = p.ExpirationTTL.String()
+	}
 	params["cas"] = strconv.FormatUint(p.ModifyIndex, 10)
 	return k.put(p.Key, params, p.Value, q)
 }
 
 // Acquire is used for a lock acquisition operation. The Key,
-// Flags, Value and Session are respected. Returns true
+// Flags, ExpirationTTL, Value and Session are respected. Returns true
 // on success or false on failures.
 func (k *KV) Acquire(p *KVPair, q *WriteOptions) (bool, *WriteMeta, error) {
-	params := make(map[string]string, 2)
+	params := make(map[string]string, 3)
 	if p.Flags != 0 {
 		params["flags"] = strconv.FormatUint(p.Flags, 10)
 	}
+	if p.ExpirationTTL != 0 {
+		params["ttl"] = p.ExpirationTTL.String()
+	}
 	params["acquire"] = p.Session
 	return k.put(p.Key, params, p.Value, q)
 }
@@ -4,7 +4,9 @@
 package api
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 )
@@ -67,6 +69,7 @@ type Lock struct {
 	isHeld       bool
 	sessionRenew chan struct{}
 	lockSession  string
+	fencingToken uint64
 	l            sync.Mutex
 }
 
@@ -83,6 +86,7 @@ type LockOptions struct {
 	LockWaitTime     time.Duration // Optional, defaults to DefaultLockWaitTime
 	LockTryOnce      bool          // Optional, defaults to false which means try forever
 	LockDelay        time.Duration // Optional, defaults to 15s
+	Fair             bool          // Optional, defaults to false which means contenders race for the lock
 	Namespace        string        `json:",omitempty"` // Optional, defaults to API client config, namespace of ACL token, or "default" namespace
 }
 
@@ -172,6 +176,17 @@ func (l *Lock) Lock(stopCh <-chan struct{}) (<-chan struct{}, error) {
 		}()
 	}
 
+	// In fair mode, join the queue of waiters. We leave the queue once we
+	// have the lock or give up, which lets the next waiter contend.
+	var queue *lockQueue
+	if l.opts.Fair {
+		queue = newLockQueue(l.c, l.opts.Key, LockFlagValue, ErrLockConflict, l.opts.Namespace)
+		if err := queue.enqueue(l.lockSession); err != nil {
+			return nil, fmt.Errorf("failed to join lock queue: %v", err)
+		}
+		defer queue.dequeue()
+	}
+
 	// Setup the query options
 	kv := l.c.KV()
 	qOpts := QueryOptions{
@@ -201,6 +216,18 @@ WAIT:
 	}
 	attempts++
 
+	// Only the head of the queue contends for the lock, the rest wait
+	// for the waiter ahead of them to leave.
+	if queue != nil {
+		head, err := queue.waitTurn(&qOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wait in lock queue: %v", err)
+		}
+		if !head {
+			goto WAIT
+		}
+	}
+
 	// Look for an existing lock, blocking until not taken
 	pair, meta, err := kv.Get(l.opts.Key, &qOpts)
 	if err != nil {
@@ -211,6 +238,7 @@ WAIT:
 	}
 	locked := false
 	if pair != nil && pair.Session == l.lockSession {
+		l.fencingToken = pair.FencingToken
 		goto HELD
 	}
 	if pair != nil && pair.Session != "" {
@@ -218,10 +246,16 @@ WAIT:
 		goto WAIT
 	}
 
-	// Try to acquire the lock
-	pair = l.lockEntry(l.lockSession)
+	// Waiters may have joined the queue ahead of us while we were blocked,
+	// so check that it is still our turn before acquiring.
+	if queue != nil && qOpts.WaitIndex != 0 {
+		qOpts.WaitIndex = 0
+		goto WAIT
+	}
 
-	locked, _, err = kv.Acquire(pair, &wOpts)
+	// Try to acquire the lock. This is done in a transaction since its
+	// result carries the fencing token issued for this acquisition.
+	locked, err = l.acquire(kv)
 	if err != nil {
 		return nil, fmt.Errorf("failed to acquire lock: %v", err)
 	}
@@ -263,6 +297,17 @@ HELD:
 	return leaderCh, nil
 }
 
+// FencingToken returns the fencing token issued when the lock was acquired,
+// or zero if the lock is not held. Tokens increase with every acquisition of
+// the key, so passing the token along with writes lets other systems, or a
+// KVCheckFence transaction operation, reject writes from a holder that has
+// since lost the lock.
+func (l *Lock) FencingToken() uint64 {
+	l.l.Lock()
+	defer l.l.Unlock()
+	return l.fencingToken
+}
+
 // Unlock released the lock. It is an error to call this
 // if the lock is not currently held.
 func (l *Lock) Unlock() error {
@@ -277,6 +322,7 @@ func (l *Lock) Unlock() error {
 
 	// Set that we no longer own the lock
 	l.isHeld = false
+	l.fencingToken = 0
 
 	// Stop the session renew
 	if l.sessionRenew != nil {
@@ -368,6 +414,40 @@ func (l *Lock) createSession() (string, error) {
 	return id, nil
 }
 
+// acquire tries to acquire the lock with the lock session, recording the
+// fencing token issued if it succeeds. It returns false without an error if
+// the lock is held by another session or a lock-delay is in effect.
+func (l *Lock) acquire(kv *KV) (bool, error) {
+	pair := l.lockEntry(l.lockSession)
+	ops := KVTxnOps{
+		&KVTxnOp{
+			Verb:      KVLock,
+			Key:       pair.Key,
+			Value:     pair.Value,
+			Flags:     pair.Flags,
+			Session:   pair.Session,
+			Namespace: l.opts.Namespace,
+		},
+	}
+	ok, resp, _, err := kv.Txn(ops, &QueryOptions{Namespace: l.opts.Namespace})
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		for _, txnErr := range resp.Errors {
+			if !strings.Contains(txnErr.What, "lock is already held") &&
+				!strings.Contains(txnErr.What, "due to lock delay") {
+				return false, errors.New(txnErr.What)
+			}
+		}
+		return false, nil
+	}
+	if len(resp.Results) > 0 {
+		l.fencingToken = resp.Results[0].FencingToken
+	}
+	return true, nil
+}
+
 // lockEntry returns a formatted KVPair for the lock
 func (l *Lock) lockEntry(session string) *KVPair {
 	return &KVPair{
@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"fmt"
+	"path"
+)
+
+// DefaultLockWaitersKey is the key used under a lock key or semaphore prefix
+// to hold the queue of waiters when fair mode is enabled.
+const DefaultLockWaitersKey = ".waiters"
+
+// lockQueue is an ordered queue of contenders stored in the KV store. It is
+// used to implement the fair mode of Lock and Semaphore.
+//
+// Each waiter writes an entry named after the creation index of its session,
+// so the entries sort in the order the sessions were created. Only the waiter
+// at the head of the queue contends for the lock, and every other waiter
+// blocks on the entry just ahead of it. This means a release only wakes up
+// the head waiter rather than every contender. Entries are held by the
+// waiter's session, so when a session is invalidated its entry is either
+// deleted or released, and released entries are cleaned up by the other
+// waiters as they walk the queue and leave it.
+type lockQueue struct {
+	c           *Client
+	prefix      string
+	flags       uint64
+	conflictErr error
+	namespace   string
+
+	// key is our entry in the queue, set by enqueue.
+	key     string
+	session string
+}
+
+// newLockQueue returns a queue that stores its entries under the given root
+// key. The flags are set on every entry, and conflictErr is returned if an
+// entry with different flags is found.
+func newLockQueue(c *Client, root string, flags uint64, conflictErr error, namespace string) *lockQueue {
+	return &lockQueue{
+		c:           c,
+		prefix:      path.Join(root, DefaultLockWaitersKey) + "/",
+		flags:       flags,
+		conflictErr: conflictErr,
+		namespace:   namespace,
+	}
+}
+
+// enqueue adds an entry for the given session to the queue.
+func (q *lockQueue) enqueue(session string) error {
+	qOpts := QueryOptions{Namespace: q.namespace}
+	se, _, err := q.c.Session().Info(session, &qOpts)
+	if err != nil {
+		return fmt.Errorf("failed to read session: %v", err)
+	}
+	if se == nil {
+		return fmt.Errorf("session %q not found", session)
+	}
+
+	key := fmt.Sprintf("%s%020d-%s", q.prefix, se.CreateIndex, session)
+	wOpts := WriteOptions{Namespace: q.namespace}
+	made, _, err := q.c.KV().Acquire(&KVPair{
+		Key:     key,
+		Session: session,
+		Flags:   q.flags,
+	}, &wOpts)
+	if err != nil {
+		return err
+	}
+	if !made {
+		return fmt.Errorf("failed to make waiter entry %q", key)
+	}
+
+	q.key = key
+	q.session = session
+	return nil
+}
+
+// dequeue removes our entry from the queue, waking up the waiter behind us.
+// It also removes the entries released by invalidated sessions, which would
+// otherwise be left behind when no waiter is queued behind them.
+func (q *lockQueue) dequeue() error {
+	if q.key == "" {
+		return nil
+	}
+	kv := q.c.KV()
+	wOpts := WriteOptions{Namespace: q.namespace}
+	if _, err := kv.Delete(q.key, &wOpts); err != nil {
+		return err
+	}
+	q.key = ""
+
+	pairs, _, err := kv.List(q.prefix, &QueryOptions{Namespace: q.namespace})
+	if err != nil {
+		return fmt.Errorf("failed to read waiters: %v", err)
+	}
+	for _, pair := range pairs {
+		if pair.Session != "" || pair.Flags != q.flags {
+			continue
+		}
+		// A failed delete means someone else got to it first.
+		if _, _, err := kv.DeleteCAS(pair, &wOpts); err != nil {
+			return fmt.Errorf("failed to remove stale waiter: %v", err)
+		}
+	}
+	return nil
+}
+
+// waitTurn returns true if we are at the head of the queue. Otherwise it
+// blocks until the waiter ahead of us changes, up to the wait time in the
+// given query options, and returns false so the caller can check again.
+func (q *lockQueue) waitTurn(opts *QueryOptions) (bool, error) {
+	kv := q.c.KV()
+	wOpts := WriteOptions{Namespace: q.namespace}
+
+	pairs, _, err := kv.List(q.prefix, &QueryOptions{
+		RequireConsistent: true,
+		Namespace:         q.namespace,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to read waiters: %v", err)
+	}
+
+	var ahead *KVPair
+	for _, pair := range pairs {
+		if pair.Flags != q.flags {
+			return false, q.conflictErr
+		}
+		if pair.Key == q.key {
+			if pair.Session != q.session {
+				return false, fmt.Errorf("session %q was invalidated while waiting", q.session)
+			}
+			if ahead == nil {
+				return true, nil
+			}
+
+			// Block until the waiter ahead of us acquires, gives up, or
+			// has its session invalidated.
+			_, _, err := kv.Get(ahead.Key, &QueryOptions{
+				WaitIndex: ahead.ModifyIndex,
+				WaitTime:  opts.WaitTime,
+				Namespace: q.namespace,
+			})
+			if err != nil {
+				return false, fmt.Errorf("failed to read waiter: %v", err)
+			}
+			return false, nil
+		}
+
+		// Remove the entries of waiters whose sessions have been
+		// invalidated. A failed delete means someone else got to it first.
+		if pair.Session == "" {
+			if _, _, err := kv.DeleteCAS(pair, &wOpts); err != nil {
+				return false, fmt.Errorf("failed to remove stale waiter: %v", err)
+			}
+			continue
+		}
+		ahead = pair
+	}
+	return false, fmt.Errorf("session %q was invalidated while waiting", q.session)
+}
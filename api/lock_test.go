@@ -101,6 +101,43 @@ func TestAPI_LockLockUnlock(t *testing.T) {
 	}
 }
 
+func TestAPI_LockFencingToken(t *testing.T) {
+	t.Parallel()
+	c, s := makeClientWithoutConnect(t)
+	defer s.Stop()
+
+	lock, session := createTestLock(t, c, "test/lock")
+	defer session.Destroy(lock.opts.Session, nil)
+
+	if token := lock.FencingToken(); token != 0 {
+		t.Fatalf("bad: %d", token)
+	}
+
+	if _, err := lock.Lock(nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	first := lock.FencingToken()
+	pair, _, err := c.KV().Get("test/lock", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if first == 0 || pair.FencingToken != first {
+		t.Fatalf("bad: %d %#v", first, pair)
+	}
+
+	// Every acquisition is issued a newer token.
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := lock.Lock(nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer lock.Unlock()
+	if second := lock.FencingToken(); second <= first {
+		t.Fatalf("bad: %d <= %d", second, first)
+	}
+}
+
 func TestAPI_LockForceInvalidate(t *testing.T) {
 	t.Parallel()
 	c, s := makeClientWithoutConnect(t)
@@ -136,6 +173,58 @@ func TestAPI_LockForceInvalidate(t *testing.T) {
 	})
 }
 
+func TestAPI_LockDelay(t *testing.T) {
+	t.Parallel()
+	c, s := makeClientWithoutConnect(t)
+	defer s.Stop()
+
+	// Hold the lock with a session that has a short lock-delay.
+	session := c.Session()
+	id, _, err := session.CreateNoChecks(&SessionEntry{
+		Name:      DefaultLockSessionName,
+		TTL:       DefaultLockSessionTTL,
+		LockDelay: time.Second,
+	}, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	holder, err := c.LockOpts(&LockOptions{Key: "test/lock", Session: id})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	leaderCh, err := holder.Lock(nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if leaderCh == nil {
+		t.Fatalf("not leader")
+	}
+
+	// Invalidate the holder's session, which puts the lock-delay into
+	// effect.
+	if _, err := session.Destroy(id, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	select {
+	case <-leaderCh:
+	case <-time.After(time.Second):
+		t.Fatalf("should not be leader")
+	}
+
+	// Another lock waits out the lock-delay rather than failing.
+	lock, other := createTestLock(t, c, "test/lock")
+	defer other.Destroy(lock.opts.Session, nil)
+
+	leaderCh, err = lock.Lock(nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if leaderCh == nil {
+		t.Fatalf("not leader")
+	}
+	defer lock.Unlock()
+}
+
 func TestAPI_LockDeleteKey(t *testing.T) {
 	t.Parallel()
 	c, s := makeClientWithoutConnect(t)
@@ -229,6 +318,178 @@ func TestAPI_LockContend(t *testing.T) {
 	}
 }
 
+func TestAPI_LockFair(t *testing.T) {
+	t.Parallel()
+	c, s := makeClientWithoutConnect(t)
+	defer s.Stop()
+	s.WaitForSerfCheck(t)
+
+	// Hold the lock so the fair contenders have to queue up.
+	holder, session := createTestLock(t, c, "test/lock")
+	defer session.Destroy(holder.opts.Session, nil)
+	if _, err := holder.Lock(nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Create the contenders in order, but start them in reverse so the
+	// queue order comes from their sessions rather than arrival.
+	locks := make([]*Lock, 3)
+	for idx := range locks {
+		lock, _ := createTestLock(t, c, "test/lock")
+		defer session.Destroy(lock.opts.Session, nil)
+		lock.opts.Fair = true
+		locks[idx] = lock
+	}
+
+	wg := &sync.WaitGroup{}
+	orderCh := make(chan int, len(locks))
+	for idx := len(locks) - 1; idx >= 0; idx-- {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			lock := locks[idx]
+			leaderCh, err := lock.Lock(nil)
+			if err != nil {
+				t.Errorf("err: %v", err)
+				return
+			}
+			if leaderCh == nil {
+				t.Errorf("not leader")
+				return
+			}
+			orderCh <- idx
+			time.Sleep(100 * time.Millisecond)
+			if err := lock.Unlock(); err != nil {
+				t.Errorf("err: %v", err)
+			}
+		}(idx)
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// Wait for everyone to be queued before releasing.
+	retry.Run(t, func(r *retry.R) {
+		keys, _, err := c.KV().Keys("test/lock/"+DefaultLockWaitersKey+"/", "", nil)
+		if err != nil {
+			r.Fatalf("err: %v", err)
+		}
+		if len(keys) != len(locks) {
+			r.Fatalf("bad: %v", keys)
+		}
+	})
+	if err := holder.Unlock(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	doneCh := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(doneCh)
+	}()
+	select {
+	case <-doneCh:
+	case <-time.After(3 * DefaultLockRetryTime):
+		t.Fatalf("timeout")
+	}
+	close(orderCh)
+
+	var order []int
+	for idx := range orderCh {
+		order = append(order, idx)
+	}
+	if len(order) != 3 || order[0] != 0 || order[1] != 1 || order[2] != 2 {
+		t.Fatalf("bad order: %v", order)
+	}
+
+	// The queue should be empty again.
+	keys, _, err := c.KV().Keys("test/lock/"+DefaultLockWaitersKey+"/", "", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("bad: %v", keys)
+	}
+}
+
+func TestAPI_LockFair_StaleWaiter(t *testing.T) {
+	t.Parallel()
+	c, s := makeClientWithoutConnect(t)
+	defer s.Stop()
+	s.WaitForSerfCheck(t)
+
+	// Leave behind the entry of a waiter whose session was invalidated.
+	stale := &KVPair{
+		Key:   "test/lock/" + DefaultLockWaitersKey + "/00000000000000000001-stale",
+		Flags: LockFlagValue,
+	}
+	if _, err := c.KV().Put(stale, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	lock, session := createTestLock(t, c, "test/lock")
+	defer session.Destroy(lock.opts.Session, nil)
+	lock.opts.Fair = true
+	lock.opts.LockTryOnce = true
+	lock.opts.LockWaitTime = 5 * time.Second
+
+	leaderCh, err := lock.Lock(nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if leaderCh == nil {
+		t.Fatalf("not leader")
+	}
+	defer lock.Unlock()
+
+	pair, _, err := c.KV().Get(stale.Key, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if pair != nil {
+		t.Fatalf("stale waiter should be removed: %v", pair)
+	}
+}
+
+func TestAPI_LockFair_StaleWaiterBehind(t *testing.T) {
+	t.Parallel()
+	c, s := makeClientWithoutConnect(t)
+	defer s.Stop()
+	s.WaitForSerfCheck(t)
+
+	// Leave behind the entry of a waiter queued after us whose session was
+	// invalidated, with no other waiter behind it to clean it up.
+	stale := &KVPair{
+		Key:   "test/lock/" + DefaultLockWaitersKey + "/99999999999999999999-stale",
+		Flags: LockFlagValue,
+	}
+	if _, err := c.KV().Put(stale, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	lock, session := createTestLock(t, c, "test/lock")
+	defer session.Destroy(lock.opts.Session, nil)
+	lock.opts.Fair = true
+	lock.opts.LockTryOnce = true
+	lock.opts.LockWaitTime = 5 * time.Second
+
+	leaderCh, err := lock.Lock(nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if leaderCh == nil {
+		t.Fatalf("not leader")
+	}
+	defer lock.Unlock()
+
+	// Leaving the queue removes it.
+	pair, _, err := c.KV().Get(stale.Key, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if pair != nil {
+		t.Fatalf("stale waiter should be removed: %v", pair)
+	}
+}
+
 func TestAPI_LockDestroy(t *testing.T) {
 	t.Parallel()
 	c, s := makeClientWithoutConnect(t)
@@ -0,0 +1,82 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+// KVHistoryConfig controls how many previous revisions are retained for keys
+// under a prefix. When several configurations match a key the one with the
+// longest prefix wins.
+type KVHistoryConfig struct {
+	// Prefix is the KV prefix the configuration applies to. An empty prefix
+	// applies to every key.
+	Prefix string
+
+	// Revisions is the number of previous revisions to keep per key.
+	Revisions int
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// KVHistoryConfigList is used to list the KV revision retention
+// configuration for all prefixes.
+func (op *Operator) KVHistoryConfigList(q *QueryOptions) ([]*KVHistoryConfig, *QueryMeta, error) {
+	r := op.c.newRequest("GET", "/v1/operator/kv/history")
+	r.setQueryOptions(q)
+	rtt, resp, err := op.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+
+	meta := &QueryMeta{}
+	parseQueryMeta(resp, meta)
+	meta.RequestTime = rtt
+	var out []*KVHistoryConfig
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return out, meta, nil
+}
+
+// KVHistoryConfigSet is used to create or update the KV revision retention
+// for a prefix.
+func (op *Operator) KVHistoryConfigSet(conf *KVHistoryConfig, q *WriteOptions) (*WriteMeta, error) {
+	r := op.c.newRequest("PUT", "/v1/operator/kv/history")
+	r.setWriteOptions(q)
+	r.obj = conf
+	rtt, resp, err := op.c.doRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, err
+	}
+
+	wm := &WriteMeta{RequestTime: rtt}
+	return wm, nil
+}
+
+// KVHistoryConfigDelete is used to remove the KV revision retention for a
+// prefix. Retained revisions of keys no longer covered by any configuration
+// are discarded.
+func (op *Operator) KVHistoryConfigDelete(prefix string, q *WriteOptions) (*WriteMeta, error) {
+	r := op.c.newRequest("DELETE", "/v1/operator/kv/history")
+	r.setWriteOptions(q)
+	r.params.Set("prefix", prefix)
+	rtt, resp, err := op.c.doRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, err
+	}
+
+	wm := &WriteMeta{RequestTime: rtt}
+	return wm, nil
+}
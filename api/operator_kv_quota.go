@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+// KVQuota limits the keys stored under a KV prefix. A zero limit means that
+// dimension is not limited. Quotas only prevent growth; writes that would
+// exceed a quota are rejected, but existing data is never removed.
+type KVQuota struct {
+	// Prefix is the KV prefix the quota applies to. An empty prefix applies
+	// to every key.
+	Prefix string
+
+	// Namespace is the namespace of the keys the quota applies to.
+	Namespace string `json:",omitempty"`
+
+	// Partition is the admin partition of the keys the quota applies to.
+	Partition string `json:",omitempty"`
+
+	// MaxKeys is the maximum number of keys under the prefix.
+	MaxKeys int `json:",omitempty"`
+
+	// MaxBytes is the maximum combined size of the values under the prefix.
+	MaxBytes int64 `json:",omitempty"`
+
+	// MaxValueSize is the maximum size of a single value under the prefix.
+	MaxValueSize int64 `json:",omitempty"`
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// KVQuotaList is used to list the KV quotas for all prefixes.
+func (op *Operator) KVQuotaList(q *QueryOptions) ([]*KVQuota, *QueryMeta, error) {
+	r := op.c.newRequest("GET", "/v1/operator/kv/quota")
+	r.setQueryOptions(q)
+	rtt, resp, err := op.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+
+	meta := &QueryMeta{}
+	parseQueryMeta(resp, meta)
+	meta.RequestTime = rtt
+	var out []*KVQuota
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return out, meta, nil
+}
+
+// KVQuotaSet is used to create or update the KV quota for a prefix.
+func (op *Operator) KVQuotaSet(quota *KVQuota, q *WriteOptions) (*WriteMeta, error) {
+	r := op.c.newRequest("PUT", "/v1/operator/kv/quota")
+	r.setWriteOptions(q)
+	r.obj = quota
+	rtt, resp, err := op.c.doRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, err
+	}
+
+	wm := &WriteMeta{RequestTime: rtt}
+	return wm, nil
+}
+
+// KVQuotaDelete is used to remove the KV quota for a prefix.
+func (op *Operator) KVQuotaDelete(prefix string, q *WriteOptions) (*WriteMeta, error) {
+	r := op.c.newRequest("DELETE", "/v1/operator/kv/quota")
+	r.setWriteOptions(q)
+	r.params.Set("prefix", prefix)
+	rtt, resp, err := op.c.doRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, err
+	}
+
+	wm := &WriteMeta{RequestTime: rtt}
+	return wm, nil
+}
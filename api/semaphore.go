@@ -76,6 +76,7 @@ type SemaphoreOptions struct {
 	MonitorRetryTime  time.Duration // Optional, defaults to DefaultMonitorRetryTime
 	SemaphoreWaitTime time.Duration // Optional, defaults to DefaultSemaphoreWaitTime
 	SemaphoreTryOnce  bool          // Optional, defaults to false which means try forever
+	Fair              bool          // Optional, defaults to false which means contenders race for a slot
 	Namespace         string        `json:",omitempty"` // Optional, defaults to API client config, namespace of ACL token, or "default" namespace
 }
 
@@ -187,6 +188,17 @@ func (s *Semaphore) Acquire(stopCh <-chan struct{}) (<-chan struct{}, error) {
 		return nil, fmt.Errorf("failed to make contender entry: %v", err)
 	}
 
+	// In fair mode, join the queue of waiters. We leave the queue once we
+	// have a slot or give up, which lets the next waiter contend.
+	var queue *lockQueue
+	if s.opts.Fair {
+		queue = newLockQueue(s.c, s.opts.Prefix, SemaphoreFlagValue, ErrSemaphoreConflict, s.opts.Namespace)
+		if err := queue.enqueue(s.lockSession); err != nil {
+			return nil, fmt.Errorf("failed to join semaphore queue: %v", err)
+		}
+		defer queue.dequeue()
+	}
+
 	// Setup the query options
 	qOpts := QueryOptions{
 		WaitTime:  s.opts.SemaphoreWaitTime,
@@ -215,6 +227,18 @@ WAIT:
 	}
 	attempts++
 
+	// Only the head of the queue contends for a slot, the rest wait for
+	// the waiter ahead of them to leave.
+	if queue != nil {
+		head, err := queue.waitTurn(&qOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wait in semaphore queue: %v", err)
+		}
+		if !head {
+			goto WAIT
+		}
+	}
+
 	// Read the prefix
 	pairs, meta, err := kv.List(s.opts.Prefix, &qOpts)
 	if err != nil {
@@ -246,6 +270,13 @@ WAIT:
 		goto WAIT
 	}
 
+	// Waiters may have joined the queue ahead of us while we were blocked,
+	// so check that it is still our turn before acquiring.
+	if queue != nil && qOpts.WaitIndex != 0 {
+		qOpts.WaitIndex = 0
+		goto WAIT
+	}
+
 	// Create a new lock with us as a holder
 	lock.Holders[s.lockSession] = true
 	newLock, err := s.encodeLock(lock, lockPair.ModifyIndex)
@@ -223,6 +223,81 @@ func TestAPI_SemaphoreContend(t *testing.T) {
 	}
 }
 
+func TestAPI_SemaphoreFair(t *testing.T) {
+	t.Parallel()
+	c, s := makeClient(t)
+	defer s.Stop()
+	s.WaitForSerfCheck(t)
+
+	// Hold the only slot so the fair contenders have to queue up.
+	holder, session := createTestSemaphore(t, c, "test/semaphore", 1)
+	defer session.Destroy(holder.opts.Session, nil)
+	if _, err := holder.Acquire(nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Create the contenders in order, but start them in reverse so the
+	// queue order comes from their sessions rather than arrival.
+	semas := make([]*Semaphore, 3)
+	for idx := range semas {
+		sema, _ := createTestSemaphore(t, c, "test/semaphore", 1)
+		defer session.Destroy(sema.opts.Session, nil)
+		sema.opts.Fair = true
+		semas[idx] = sema
+	}
+
+	wg := &sync.WaitGroup{}
+	orderCh := make(chan int, len(semas))
+	for idx := len(semas) - 1; idx >= 0; idx-- {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			sema := semas[idx]
+			lockCh, err := sema.Acquire(nil)
+			if err != nil {
+				t.Errorf("err: %v", err)
+				return
+			}
+			if lockCh == nil {
+				t.Errorf("not locked")
+				return
+			}
+			orderCh <- idx
+			time.Sleep(100 * time.Millisecond)
+			if err := sema.Release(); err != nil {
+				t.Errorf("err: %v", err)
+			}
+		}(idx)
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// Give everyone time to queue up before releasing.
+	time.Sleep(500 * time.Millisecond)
+	if err := holder.Release(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	doneCh := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(doneCh)
+	}()
+	select {
+	case <-doneCh:
+	case <-time.After(3 * DefaultLockRetryTime):
+		t.Fatalf("timeout")
+	}
+	close(orderCh)
+
+	var order []int
+	for idx := range orderCh {
+		order = append(order, idx)
+	}
+	if len(order) != 3 || order[0] != 0 || order[1] != 1 || order[2] != 2 {
+		t.Fatalf("bad order: %v", order)
+	}
+}
+
 func TestAPI_SemaphoreBadLimit(t *testing.T) {
 	t.Parallel()
 	c, s := makeClient(t)
@@ -5,6 +5,7 @@ package api
 
 import (
 	"io"
+	"strconv"
 )
 
 // Snapshot can be used to query the /v1/snapshot endpoint to take snapshots of
@@ -40,6 +41,33 @@ func (s *Snapshot) Save(q *QueryOptions) (io.ReadCloser, *QueryMeta, error) {
 	return resp.Body, qm, nil
 }
 
+// SaveIncremental requests an incremental snapshot holding only the changes
+// made since the base snapshot with the given index and hash, and provides an
+// io.ReadCloser with the snapshot data to save. The hash is the hex-encoded
+// SHA-256 of the base snapshot's state.bin. The base can be a full snapshot
+// or another incremental snapshot. If this doesn't return an error, then it's
+// the responsibility of the caller to close it. The same subset of the
+// QueryOptions are supported as for Save.
+func (s *Snapshot) SaveIncremental(baseIndex uint64, baseHash string, q *QueryOptions) (io.ReadCloser, *QueryMeta, error) {
+	r := s.c.newRequest("GET", "/v1/snapshot")
+	r.setQueryOptions(q)
+	r.params.Set("base-index", strconv.FormatUint(baseIndex, 10))
+	r.params.Set("base-hash", baseHash)
+
+	rtt, resp, err := s.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+
+	qm := &QueryMeta{}
+	parseQueryMeta(resp, qm)
+	qm.RequestTime = rtt
+	return resp.Body, qm, nil
+}
+
 // Restore streams in an existing snapshot and attempts to restore it.
 func (s *Snapshot) Restore(q *WriteOptions, in io.Reader) error {
 	r := s.c.newRequest("PUT", "/v1/snapshot")
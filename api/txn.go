@@ -75,6 +75,7 @@ const (
 	KVCheckSession   KVOp = "check-session"
 	KVCheckIndex     KVOp = "check-index"
 	KVCheckNotExists KVOp = "check-not-exists"
+	KVCheckFence     KVOp = "check-fence"
 )
 
 // KVTxnOp defines a single operation inside a transaction.
@@ -87,6 +88,10 @@ type KVTxnOp struct {
 	Session   string
 	Namespace string `json:",omitempty"`
 	Partition string `json:",omitempty"`
+
+	// FencingToken is the token a check-fence operation requires to still
+	// be current for the key.
+	FencingToken uint64 `json:",omitempty"`
 }
 
 // KVTxnOps defines a set of operations to be performed inside a single
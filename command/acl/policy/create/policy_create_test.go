@@ -109,3 +109,56 @@ func TestPolicyCreateCommand_JSON(t *testing.T) {
 	err = json.Unmarshal([]byte(ui.OutputWriter.String()), &jsonOutput)
 	assert.NoError(t, err)
 }
+
+func TestPolicyCreateCommand_conditions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	t.Run("valid", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		args := []string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-name=break-glass",
+			`-rules=agent_prefix "" { policy = "write" source_cidrs = ["10.0.0.0/24"] }`,
+		}
+
+		code := cmd.Run(args)
+		require.Equal(t, 0, code)
+		require.Empty(t, ui.ErrorWriter.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		args := []string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-name=maintenance",
+			`-rules=key_prefix "" { policy = "write" valid_between = ["2026-01-02T00:00:00Z", "2026-01-01T00:00:00Z"] }`,
+		}
+
+		code := cmd.Run(args)
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), "Invalid key_prefix conditions")
+		require.Contains(t, ui.ErrorWriter.String(), "valid_between start time must be before its end time")
+	})
+}
@@ -78,6 +78,9 @@ func (f *prettyFormatter) FormatToken(token *api.ACLToken) (string, error) {
 	if token.ExpirationTime != nil && !token.ExpirationTime.IsZero() {
 		fmt.Fprintf(&buffer, "Expiration Time:  %v\n", *token.ExpirationTime)
 	}
+	if token.LastUsed != nil {
+		fmt.Fprintf(&buffer, "Last Used:        %v (Server: %s, Datacenter: %s)\n", token.LastUsed.Time, token.LastUsed.Server, token.LastUsed.Datacenter)
+	}
 	if f.showMeta {
 		fmt.Fprintf(&buffer, "Hash:             %x\n", token.Hash)
 		fmt.Fprintf(&buffer, "Create Index:     %d\n", token.CreateIndex)
@@ -151,6 +154,9 @@ func (f *prettyFormatter) FormatTokenExpanded(token *api.ACLTokenExpanded) (stri
 	if token.ExpirationTime != nil && !token.ExpirationTime.IsZero() {
 		fmt.Fprintf(&buffer, "Expiration Time:  %v\n", *token.ExpirationTime)
 	}
+	if token.LastUsed != nil {
+		fmt.Fprintf(&buffer, "Last Used:        %v (Server: %s, Datacenter: %s)\n", token.LastUsed.Time, token.LastUsed.Server, token.LastUsed.Datacenter)
+	}
 	if f.showMeta {
 		fmt.Fprintf(&buffer, "Hash:             %x\n", token.Hash)
 		fmt.Fprintf(&buffer, "Create Index:     %d\n", token.CreateIndex)
@@ -350,6 +356,9 @@ func (f *prettyFormatter) formatTokenListEntry(token *api.ACLTokenListEntry) str
 	if token.ExpirationTime != nil && !token.ExpirationTime.IsZero() {
 		fmt.Fprintf(&buffer, "Expiration Time:  %v\n", *token.ExpirationTime)
 	}
+	if token.LastUsed != nil {
+		fmt.Fprintf(&buffer, "Last Used:        %v (Server: %s, Datacenter: %s)\n", token.LastUsed.Time, token.LastUsed.Server, token.LastUsed.Datacenter)
+	}
 	if f.showMeta {
 		fmt.Fprintf(&buffer, "Hash:             %x\n", token.Hash)
 		fmt.Fprintf(&buffer, "Create Index:     %d\n", token.CreateIndex)
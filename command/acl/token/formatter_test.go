@@ -71,9 +71,14 @@ func TestFormatToken(t *testing.T) {
 				AuthMethodNamespace: "baz",
 				CreateTime:          time.Date(2020, 5, 22, 18, 52, 31, 0, time.UTC),
 				ExpirationTime:      timeRef(time.Date(2020, 5, 22, 19, 52, 31, 0, time.UTC)),
-				Hash:                []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'},
-				CreateIndex:         5,
-				ModifyIndex:         10,
+				LastUsed: &api.ACLTokenLastUsed{
+					Time:       time.Date(2020, 5, 22, 19, 12, 0, 0, time.UTC),
+					Server:     "server-1",
+					Datacenter: "dc1",
+				},
+				Hash:        []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'},
+				CreateIndex: 5,
+				ModifyIndex: 10,
 				Policies: []*api.ACLLink{
 					{
 						ID:   "beb04680-815b-4d7c-9e33-3d707c24672c",
@@ -189,9 +194,14 @@ func TestFormatTokenList(t *testing.T) {
 					AuthMethodNamespace: "baz",
 					CreateTime:          time.Date(2020, 5, 22, 18, 52, 31, 0, time.UTC),
 					ExpirationTime:      timeRef(time.Date(2020, 5, 22, 19, 52, 31, 0, time.UTC)),
-					Hash:                []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'},
-					CreateIndex:         5,
-					ModifyIndex:         10,
+					LastUsed: &api.ACLTokenLastUsed{
+						Time:       time.Date(2020, 5, 22, 19, 12, 0, 0, time.UTC),
+						Server:     "server-1",
+						Datacenter: "dc1",
+					},
+					Hash:        []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'},
+					CreateIndex: 5,
+					ModifyIndex: 10,
 					Policies: []*api.ACLLink{
 						{
 							ID:   "beb04680-815b-4d7c-9e33-3d707c24672c",
@@ -439,9 +449,14 @@ var expandedTokenTestCases = map[string]testCase{
 				AuthMethodNamespace: "baz",
 				CreateTime:          time.Date(2020, 5, 22, 18, 52, 31, 0, time.UTC),
 				ExpirationTime:      timeRef(time.Date(2020, 5, 22, 19, 52, 31, 0, time.UTC)),
-				Hash:                []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'},
-				CreateIndex:         5,
-				ModifyIndex:         10,
+				LastUsed: &api.ACLTokenLastUsed{
+					Time:       time.Date(2020, 5, 22, 19, 12, 0, 0, time.UTC),
+					Server:     "server-1",
+					Datacenter: "dc1",
+				},
+				Hash:        []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'},
+				CreateIndex: 5,
+				ModifyIndex: 10,
 				Policies: []*api.ACLLink{
 					{
 						ID:   "beb04680-815b-4d7c-9e33-3d707c24672c",
@@ -6,8 +6,11 @@ package tokenlist
 import (
 	"flag"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/command/acl/token"
 	"github.com/hashicorp/consul/command/flags"
 	"github.com/mitchellh/cli"
@@ -25,8 +28,9 @@ type cmd struct {
 	http  *flags.HTTPFlags
 	help  string
 
-	showMeta bool
-	format   string
+	showMeta    bool
+	format      string
+	unusedSince string
 }
 
 func (c *cmd) init() {
@@ -39,6 +43,10 @@ func (c *cmd) init() {
 		token.PrettyFormat,
 		fmt.Sprintf("Output format {%s}", strings.Join(token.GetSupportedFormats(), "|")),
 	)
+	c.flags.StringVar(&c.unusedSince, "unused-since", "", "Only list the tokens that "+
+		"weren't used within this duration, like \"90d\" or \"12h\". Tokens created "+
+		"within it aren't listed either. The servers track the last use of a token "+
+		"with a granularity of an hour.")
 	c.http = &flags.HTTPFlags{}
 	flags.Merge(c.flags, c.http.ClientFlags())
 	flags.Merge(c.flags, c.http.ServerFlags())
@@ -51,6 +59,16 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
+	var cutoff time.Time
+	if c.unusedSince != "" {
+		d, err := parseDuration(c.unusedSince)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Invalid -unused-since value: %v", err))
+			return 1
+		}
+		cutoff = time.Now().Add(-d)
+	}
+
 	client, err := c.http.APIClient()
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
@@ -62,6 +80,9 @@ func (c *cmd) Run(args []string) int {
 		c.UI.Error(fmt.Sprintf("Failed to retrieve the token list: %v", err))
 		return 1
 	}
+	if !cutoff.IsZero() {
+		tokens = unusedSince(tokens, cutoff)
+	}
 
 	formatter, err := token.NewFormatter(c.format, c.showMeta)
 	if err != nil {
@@ -80,6 +101,43 @@ func (c *cmd) Run(args []string) int {
 	return 0
 }
 
+// parseDuration parses a duration like time.ParseDuration does, but also
+// accepts a whole number of days like "90d".
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid number of days %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration %q can't be negative", s)
+	}
+	return d, nil
+}
+
+// unusedSince returns the tokens that were created before the cutoff and
+// haven't been used since.
+func unusedSince(tokens []*api.ACLTokenListEntry, cutoff time.Time) []*api.ACLTokenListEntry {
+	var unused []*api.ACLTokenListEntry
+	for _, token := range tokens {
+		if !token.CreateTime.Before(cutoff) {
+			continue
+		}
+		if token.LastUsed != nil && !token.LastUsed.Time.Before(cutoff) {
+			continue
+		}
+		unused = append(unused, token)
+	}
+	return unused
+}
+
 func (c *cmd) Synopsis() string {
 	return synopsis
 }
@@ -96,5 +154,9 @@ Usage: consul acl token list [options]
   List all the ACL tokens
 
           $ consul acl token list
+
+  List the ACL tokens that weren't used in the last 90 days
+
+          $ consul acl token list -unused-since=90d
 `
 )
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/consul/agent"
 	"github.com/hashicorp/consul/api"
@@ -137,3 +138,41 @@ func TestTokenListCommand_JSON(t *testing.T) {
 	}
 	require.Subset(t, respIDs, tokenIds)
 }
+
+func TestTokenListCommand_parseDuration(t *testing.T) {
+	t.Parallel()
+
+	d, err := parseDuration("90d")
+	require.NoError(t, err)
+	require.Equal(t, 90*24*time.Hour, d)
+
+	d, err = parseDuration("12h")
+	require.NoError(t, err)
+	require.Equal(t, 12*time.Hour, d)
+
+	for _, s := range []string{"", "d", "1.5d", "-1d", "-1h", "90days"} {
+		_, err := parseDuration(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestTokenListCommand_unusedSince(t *testing.T) {
+	t.Parallel()
+
+	cutoff := time.Date(2024, 5, 22, 0, 0, 0, 0, time.UTC)
+	before := cutoff.Add(-time.Hour)
+	after := cutoff.Add(time.Hour)
+
+	tokens := []*api.ACLTokenListEntry{
+		{AccessorID: "never-used", CreateTime: before},
+		{AccessorID: "used-before", CreateTime: before, LastUsed: &api.ACLTokenLastUsed{Time: before}},
+		{AccessorID: "used-after", CreateTime: before, LastUsed: &api.ACLTokenLastUsed{Time: after}},
+		{AccessorID: "created-after", CreateTime: after},
+	}
+
+	var ids []string
+	for _, token := range unusedSince(tokens, cutoff) {
+		ids = append(ids, token.AccessorID)
+	}
+	require.Equal(t, []string{"never-used", "used-before"}, ids)
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"text/tabwriter"
+	"time"
 
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/command/flags"
@@ -219,6 +220,9 @@ func (c *cmd) Help() string {
 func prettyKVPair(w io.Writer, pair *api.KVPair, base64EncodeValue bool, keysOnly bool) error {
 	tw := tabwriter.NewWriter(w, 0, 2, 6, ' ', 0)
 	fmt.Fprintf(tw, "CreateIndex\t%d\n", pair.CreateIndex)
+	if pair.ExpirationTime != nil {
+		fmt.Fprintf(tw, "ExpirationTime\t%s\n", pair.ExpirationTime.Format(time.RFC3339))
+	}
 	fmt.Fprintf(tw, "Flags\t%d\n", pair.Flags)
 	fmt.Fprintf(tw, "Key\t%s\n", pair.Key)
 	fmt.Fprintf(tw, "LockIndex\t%d\n", pair.LockIndex)
@@ -0,0 +1,146 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package history
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/ryanuber/columnize"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/command/flags"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI     cli.Ui
+	flags  *flag.FlagSet
+	http   *flags.HTTPFlags
+	help   string
+	base64 bool
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.BoolVar(&c.base64, "base64", false,
+		"Base64 encode the value. The default value is false.")
+
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	key := ""
+
+	// Check for arg validation
+	args = c.flags.Args()
+	switch len(args) {
+	case 0:
+		key = ""
+	case 1:
+		key = args[0]
+	default:
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 1, got %d)", len(args)))
+		return 1
+	}
+
+	// This is just a "nice" thing to do. Since pairs cannot start with a /, but
+	// users will likely put "/" or "/foo", lets go ahead and strip that for them
+	// here.
+	if len(key) > 0 && key[0] == '/' {
+		key = key[1:]
+	}
+
+	if key == "" {
+		c.UI.Error("Error! Missing KEY argument")
+		return 1
+	}
+
+	// Create and test the HTTP client
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	revisions, _, err := client.KV().Revisions(key, &api.QueryOptions{
+		AllowStale: c.http.Stale(),
+	})
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error querying Consul agent: %s", err))
+		return 1
+	}
+	if len(revisions) == 0 {
+		c.UI.Error(fmt.Sprintf("Error! No revisions found for key: %s", key))
+		return 1
+	}
+
+	result := []string{"ModifyIndex\x1fSupersededIndex\x1fStatus\x1fFlags\x1fValue"}
+	for _, rev := range revisions {
+		status := "superseded"
+		superseded := fmt.Sprintf("%d", rev.SupersededIndex)
+		switch {
+		case rev.SupersededIndex == 0:
+			status = "current"
+			superseded = "-"
+		case rev.Deleted:
+			status = "deleted"
+		}
+
+		value := string(rev.Value)
+		if c.base64 {
+			value = base64.StdEncoding.EncodeToString(rev.Value)
+		}
+		// Keep multi-line values on a single row of the table.
+		value = strings.ReplaceAll(value, "\n", `\n`)
+
+		result = append(result, fmt.Sprintf("%d\x1f%s\x1f%s\x1f%d\x1f%s",
+			rev.ModifyIndex, superseded, status, rev.Flags, value))
+	}
+
+	c.UI.Output(columnize.Format(result, &columnize.Config{Delim: string([]byte{0x1f})}))
+	return 0
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+const (
+	synopsis = "Lists the retained revisions of a key"
+	help     = `
+Usage: consul kv history [options] KEY
+
+  Lists the previous values of a key, oldest first, that were retained because
+  the key is covered by a KV history configuration. If the key currently
+  exists, its value is listed last with a status of "current".
+
+  To list the revisions of the key named "redis/config/connections":
+
+      $ consul kv history redis/config/connections
+
+  Revision history is configured per prefix with the
+  /v1/operator/kv/history endpoint.
+`
+)
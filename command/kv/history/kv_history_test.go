@@ -0,0 +1,105 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package history
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestKVHistoryCommand_noTabs(t *testing.T) {
+	t.Parallel()
+	if strings.ContainsRune(New(nil).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestKVHistoryCommand_Validation(t *testing.T) {
+	t.Parallel()
+	ui := cli.NewMockUi()
+	c := New(ui)
+
+	cases := map[string]struct {
+		args   []string
+		output string
+	}{
+		"no key": {
+			[]string{},
+			"Missing KEY argument",
+		},
+		"extra args": {
+			[]string{"foo", "bar", "baz"},
+			"Too many arguments",
+		},
+	}
+
+	for name, tc := range cases {
+		c.init()
+		// Ensure our buffer is always clear
+		if ui.ErrorWriter != nil {
+			ui.ErrorWriter.Reset()
+		}
+		if ui.OutputWriter != nil {
+			ui.OutputWriter.Reset()
+		}
+
+		code := c.Run(tc.args)
+		if code == 0 {
+			t.Errorf("%s: expected non-zero exit", name)
+		}
+
+		output := ui.ErrorWriter.String()
+		if !strings.Contains(output, tc.output) {
+			t.Errorf("%s: expected %q to contain %q", name, output, tc.output)
+		}
+	}
+}
+
+func TestKVHistoryCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+	client := a.Client()
+
+	_, err := client.Operator().KVHistoryConfigSet(&api.KVHistoryConfig{Prefix: "foo", Revisions: 5}, nil)
+	require.NoError(t, err)
+
+	for _, v := range []string{"one", "two"} {
+		_, err := client.KV().Put(&api.KVPair{Key: "foo", Value: []byte(v)}, nil)
+		require.NoError(t, err)
+	}
+
+	ui := cli.NewMockUi()
+	c := New(ui)
+
+	code := c.Run([]string{"-http-addr=" + a.HTTPAddr(), "foo"})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+	lines := strings.Split(strings.TrimSpace(ui.OutputWriter.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Contains(t, lines[0], "SupersededIndex")
+	require.Contains(t, lines[1], "superseded")
+	require.Contains(t, lines[1], "one")
+	require.Contains(t, lines[2], "current")
+	require.Contains(t, lines[2], "two")
+
+	// Keys that were never written have no history.
+	ui = cli.NewMockUi()
+	c = New(ui)
+	code = c.Run([]string{"-http-addr=" + a.HTTPAddr(), "nope"})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "No revisions found")
+}
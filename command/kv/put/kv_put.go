@@ -8,6 +8,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/command/flags"
@@ -35,6 +36,7 @@ type cmd struct {
 	session       string
 	acquire       bool
 	release       bool
+	ttl           time.Duration
 
 	// testStdin is the input for testing.
 	testStdin io.Reader
@@ -67,6 +69,10 @@ func (c *cmd) init() {
 		"Forfeit the lock on the key at the given path. This requires the "+
 			"-session flag to be set. The key must be held by the session in order to "+
 			"be unlocked. The default value is false.")
+	c.flags.DurationVar(&c.ttl, "ttl", 0,
+		"Duration after which the key will be automatically deleted, such as "+
+			"\"30s\" or \"24h\". Writing the key again without this flag removes "+
+			"the expiration. The default value is 0 (never expires).")
 
 	c.http = &flags.HTTPFlags{}
 	flags.Merge(c.flags, c.http.ClientFlags())
@@ -102,6 +108,11 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
+	if c.ttl < 0 {
+		c.UI.Error("Error! -ttl must not be negative")
+		return 1
+	}
+
 	// Create and test the HTTP client
 	client, err := c.http.APIClient()
 	if err != nil {
@@ -115,6 +126,8 @@ func (c *cmd) Run(args []string) int {
 		Flags:       c.kvflags,
 		Value:       dataBytes,
 		Session:     c.session,
+
+		ExpirationTTL: c.ttl,
 	}
 
 	switch {
@@ -263,6 +276,11 @@ Usage: consul kv put [options] KEY [DATA]
 
       $ consul kv put -cas -modify-index=844 config/redis/maxconns 5
 
+  To have the key deleted automatically after a period of time, specify the
+  -ttl flag:
+
+      $ consul kv put -ttl=1h maintenance/enabled true
+
   Additional flags and more advanced use cases are detailed below.
 `
 )
@@ -0,0 +1,182 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package rollback
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/command/flags"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+	index uint64
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Uint64Var(&c.index, "index", 0,
+		"The Raft index to roll the key back to. The key is restored to the "+
+			"value it had at this index. This is required.")
+
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	key := ""
+
+	// Check for arg validation
+	args = c.flags.Args()
+	switch len(args) {
+	case 0:
+		key = ""
+	case 1:
+		key = args[0]
+	default:
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 1, got %d)", len(args)))
+		return 1
+	}
+
+	// This is just a "nice" thing to do. Since pairs cannot start with a /, but
+	// users will likely put "/" or "/foo", lets go ahead and strip that for them
+	// here.
+	if len(key) > 0 && key[0] == '/' {
+		key = key[1:]
+	}
+
+	if key == "" {
+		c.UI.Error("Error! Missing KEY argument")
+		return 1
+	}
+
+	if c.index == 0 {
+		c.UI.Error("Must specify an -index greater than 0")
+		return 1
+	}
+
+	// Create and test the HTTP client
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+	kv := client.KV()
+
+	// Make sure the index is actually covered by the retained history before
+	// doing anything, otherwise a missing revision would look like the key
+	// didn't exist and we'd delete it.
+	revisions, _, err := kv.Revisions(key, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error querying Consul agent: %s", err))
+		return 1
+	}
+	if len(revisions) == 0 || c.index < revisions[0].ModifyIndex {
+		c.UI.Error(fmt.Sprintf("Error! No revision of key %s is retained at index %d", key, c.index))
+		return 1
+	}
+
+	old, _, err := kv.GetAtIndex(key, c.index, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error querying Consul agent: %s", err))
+		return 1
+	}
+	current, _, err := kv.Get(key, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error querying Consul agent: %s", err))
+		return 1
+	}
+
+	var modifyIndex uint64
+	if current != nil {
+		modifyIndex = current.ModifyIndex
+	}
+
+	// Both writes are check-and-set against the current value so a
+	// concurrent update isn't silently overwritten.
+	if old == nil {
+		if current == nil {
+			c.UI.Info(fmt.Sprintf("Success! Key %s did not exist at index %d and does not exist now", key, c.index))
+			return 0
+		}
+
+		ok, _, err := kv.DeleteCAS(&api.KVPair{Key: key, ModifyIndex: modifyIndex}, nil)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error! Did not roll back key %s: %s", key, err))
+			return 1
+		}
+		if !ok {
+			c.UI.Error(fmt.Sprintf("Error! Did not roll back key %s: CAS failed", key))
+			return 1
+		}
+
+		c.UI.Info(fmt.Sprintf("Success! Rolled back key %s to index %d (deleted)", key, c.index))
+		return 0
+	}
+
+	pair := &api.KVPair{
+		Key:         key,
+		Flags:       old.Flags,
+		Value:       old.Value,
+		ModifyIndex: modifyIndex,
+	}
+	ok, _, err := kv.CAS(pair, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error! Did not roll back key %s: %s", key, err))
+		return 1
+	}
+	if !ok {
+		c.UI.Error(fmt.Sprintf("Error! Did not roll back key %s: CAS failed", key))
+		return 1
+	}
+
+	c.UI.Info(fmt.Sprintf("Success! Rolled back key %s to index %d", key, c.index))
+	return 0
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+const (
+	synopsis = "Restores a key to a previous revision"
+	help     = `
+Usage: consul kv rollback [options] -index=<index> KEY
+
+  Restores a key to the value it had at the given Raft index using its
+  retained revision history. If the key did not exist at that index, it is
+  deleted. The write is a check-and-set against the current value, so it fails
+  if the key is modified concurrently.
+
+  To restore the key named "redis/config/connections" to its value at index 42:
+
+      $ consul kv rollback -index=42 redis/config/connections
+
+  Use "consul kv history" to list the retained revisions of a key.
+`
+)
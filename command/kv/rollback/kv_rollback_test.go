@@ -0,0 +1,117 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package rollback
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestKVRollbackCommand_noTabs(t *testing.T) {
+	t.Parallel()
+	if strings.ContainsRune(New(nil).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestKVRollbackCommand_Validation(t *testing.T) {
+	t.Parallel()
+	ui := cli.NewMockUi()
+	c := New(ui)
+
+	cases := map[string]struct {
+		args   []string
+		output string
+	}{
+		"no key": {
+			[]string{"-index", "2"},
+			"Missing KEY argument",
+		},
+		"no index": {
+			[]string{"foo"},
+			"Must specify an -index",
+		},
+		"extra args": {
+			[]string{"foo", "bar", "baz"},
+			"Too many arguments",
+		},
+	}
+
+	for name, tc := range cases {
+		c.init()
+		// Ensure our buffer is always clear
+		if ui.ErrorWriter != nil {
+			ui.ErrorWriter.Reset()
+		}
+		if ui.OutputWriter != nil {
+			ui.OutputWriter.Reset()
+		}
+
+		code := c.Run(tc.args)
+		if code == 0 {
+			t.Errorf("%s: expected non-zero exit", name)
+		}
+
+		output := ui.ErrorWriter.String()
+		if !strings.Contains(output, tc.output) {
+			t.Errorf("%s: expected %q to contain %q", name, output, tc.output)
+		}
+	}
+}
+
+func TestKVRollbackCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+	client := a.Client()
+
+	_, err := client.Operator().KVHistoryConfigSet(&api.KVHistoryConfig{Prefix: "foo", Revisions: 5}, nil)
+	require.NoError(t, err)
+
+	_, err = client.KV().Put(&api.KVPair{Key: "foo", Flags: 7, Value: []byte("one")}, nil)
+	require.NoError(t, err)
+	first, _, err := client.KV().Get("foo", nil)
+	require.NoError(t, err)
+
+	_, err = client.KV().Put(&api.KVPair{Key: "foo", Value: []byte("two")}, nil)
+	require.NoError(t, err)
+
+	ui := cli.NewMockUi()
+	c := New(ui)
+	code := c.Run([]string{
+		"-http-addr=" + a.HTTPAddr(),
+		"-index=" + strconv.FormatUint(first.ModifyIndex, 10),
+		"foo",
+	})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+	pair, _, err := client.KV().Get("foo", nil)
+	require.NoError(t, err)
+	require.Equal(t, "one", string(pair.Value))
+	require.Equal(t, uint64(7), pair.Flags)
+
+	// Rolling back before the oldest retained revision fails.
+	ui = cli.NewMockUi()
+	c = New(ui)
+	code = c.Run([]string{
+		"-http-addr=" + a.HTTPAddr(),
+		"-index=" + strconv.FormatUint(first.ModifyIndex-1, 10),
+		"foo",
+	})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "No revision of key foo is retained")
+}
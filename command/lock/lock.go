@@ -9,6 +9,7 @@ import (
 	"os"
 	osexec "os/exec"
 	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -55,6 +56,7 @@ type cmd struct {
 	verbose   bool
 
 	// flags
+	fair               bool
 	limit              int
 	monitorRetry       int
 	name               string
@@ -76,6 +78,11 @@ func (c *cmd) init() {
 		"Exit 2 if the child process exited with an error if this is true, "+
 			"otherwise this doesn't propagate an error from the child. The "+
 			"default value is false.")
+	c.flags.BoolVar(&c.fair, "fair", false,
+		"Queue contenders in the order their sessions were created, so that "+
+			"only the longest waiting contender is woken up when the lock is "+
+			"released. All contenders for the prefix should use this flag. The "+
+			"default value is false.")
 	c.flags.IntVar(&c.limit, "n", 1,
 		"Optional limit on the number of concurrent lock holders. The underlying "+
 			"implementation switches from a lock to a semaphore when the value is "+
@@ -204,7 +211,7 @@ func (c *cmd) run(args []string, lu **LockUnlock) int {
 	// Start the child process
 	childErr = make(chan error, 1)
 	go func() {
-		childErr <- c.startChild(c.flags.Args()[1:], c.passStdin, c.shell)
+		childErr <- c.startChild(c.flags.Args()[1:], (*lu).childEnv(), c.passStdin, c.shell)
 	}()
 
 	// Monitor for shutdown, child termination, or lock loss
@@ -283,6 +290,7 @@ func (c *cmd) setupLock(client *api.Client, prefix, name string,
 		SessionName:      name,
 		MonitorRetries:   retry,
 		MonitorRetryTime: defaultMonitorRetryTime,
+		Fair:             c.fair,
 	}
 	if oneshot {
 		opts.LockTryOnce = true
@@ -293,11 +301,12 @@ func (c *cmd) setupLock(client *api.Client, prefix, name string,
 		return nil, err
 	}
 	lu := &LockUnlock{
-		lockFn:    l.Lock,
-		unlockFn:  l.Unlock,
-		cleanupFn: l.Destroy,
-		inUseErr:  api.ErrLockInUse,
-		rawOpts:   &opts,
+		lockFn:         l.Lock,
+		unlockFn:       l.Unlock,
+		cleanupFn:      l.Destroy,
+		fencingTokenFn: l.FencingToken,
+		inUseErr:       api.ErrLockInUse,
+		rawOpts:        &opts,
 	}
 	return lu, nil
 }
@@ -318,6 +327,7 @@ func (c *cmd) setupSemaphore(client *api.Client, limit int, prefix, name string,
 		SessionName:      name,
 		MonitorRetries:   retry,
 		MonitorRetryTime: defaultMonitorRetryTime,
+		Fair:             c.fair,
 	}
 	if oneshot {
 		opts.SemaphoreTryOnce = true
@@ -339,7 +349,7 @@ func (c *cmd) setupSemaphore(client *api.Client, limit int, prefix, name string,
 
 // startChild is a long running routine used to start and
 // wait for the child process to exit.
-func (c *cmd) startChild(args []string, passStdin, shell bool) error {
+func (c *cmd) startChild(args []string, env []string, passStdin, shell bool) error {
 	if c.verbose {
 		c.UI.Info("Starting handler")
 	}
@@ -361,6 +371,7 @@ func (c *cmd) startChild(args []string, passStdin, shell bool) error {
 	cmd.Env = append(os.Environ(),
 		"CONSUL_LOCK_HELD=true",
 	)
+	cmd.Env = append(cmd.Env, env...)
 	if passStdin {
 		if c.verbose {
 			c.UI.Info("Stdin passed to handler process")
@@ -465,6 +476,23 @@ type LockUnlock struct {
 	cleanupFn func() error
 	inUseErr  error
 	rawOpts   interface{}
+
+	// fencingTokenFn returns the fencing token for a held lock. It is nil
+	// for semaphores, which don't issue fencing tokens.
+	fencingTokenFn func() uint64
+}
+
+// childEnv returns the extra environment variables passed to the child
+// process while the lock is held.
+func (lu *LockUnlock) childEnv() []string {
+	if lu.fencingTokenFn == nil {
+		return nil
+	}
+	opts := lu.rawOpts.(*api.LockOptions)
+	return []string{
+		"CONSUL_LOCK_KEY=" + opts.Key,
+		"CONSUL_LOCK_FENCING_TOKEN=" + strconv.FormatUint(lu.fencingTokenFn(), 10),
+	}
 }
 
 const synopsis = "Execute a command holding a lock"
@@ -484,5 +512,10 @@ Usage: consul lock [options] prefix child...
   exclusion. Setting a higher value switches to a semaphore allowing multiple
   holders to coordinate.
 
+  When holding a lock, the child process is given the lock's key in
+  CONSUL_LOCK_KEY and the fencing token for the acquisition in
+  CONSUL_LOCK_FENCING_TOKEN. The token can be used with a "check-fence"
+  transaction operation to reject writes once the lock has been lost.
+
   The prefix provided must have write privileges.
 `
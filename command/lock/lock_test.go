@@ -6,6 +6,7 @@ package lock
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -72,6 +73,37 @@ func TestLockCommand(t *testing.T) {
 	}
 }
 
+func TestLockCommand_Fair(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	for _, limit := range []string{"1", "3"} {
+		ui := cli.NewMockUi()
+		c := New(ui, nil)
+
+		filePath := filepath.Join(a.Config.DataDir, "test_touch_"+limit)
+		args := []string{"-http-addr=" + a.HTTPAddr(), "-fair", "-n=" + limit, "test/prefix", "touch", filePath}
+
+		code := c.Run(args)
+		if code != 0 {
+			t.Fatalf("bad: %d. %#v", code, ui.ErrorWriter.String())
+		}
+
+		// Check for the file
+		_, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+}
+
 func TestLockCommand_NoShell(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -377,3 +409,39 @@ func TestLockCommand_ChildExitCode(t *testing.T) {
 		})
 	}
 }
+
+func TestLockCommand_FencingTokenEnv(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	ui := cli.NewMockUi()
+	c := New(ui, nil)
+
+	filePath := filepath.Join(a.Config.DataDir, "test_env")
+	args := []string{"-http-addr=" + a.HTTPAddr(), "test/prefix",
+		"echo $CONSUL_LOCK_KEY $CONSUL_LOCK_FENCING_TOKEN > " + filePath}
+
+	code := c.Run(args)
+	if code != 0 {
+		t.Fatalf("bad: %d. %#v", code, ui.ErrorWriter.String())
+	}
+
+	out, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 || fields[0] != "test/prefix/.lock" {
+		t.Fatalf("bad: %q", out)
+	}
+	if token, err := strconv.ParseUint(fields[1], 10, 64); err != nil || token == 0 {
+		t.Fatalf("bad fencing token: %q", fields[1])
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package login
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/agent/consul/authmethod/ldapauth"
+)
+
+const ldapAuthMethodType = "ldap"
+
+type LDAPLogin struct {
+	username     string
+	passwordFile string
+}
+
+func (l *LDAPLogin) flags() *flag.FlagSet {
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	fs.StringVar(&l.username, "ldap-username", "",
+		"Username to login to the LDAP auth method with. The password is prompted for "+
+			"unless -ldap-password-file is set. Requires -type=ldap. [ldap only]")
+
+	fs.StringVar(&l.passwordFile, "ldap-password-file", "",
+		"Path to a file containing the password of -ldap-username. [ldap only]")
+	return fs
+}
+
+// checkFlags validates flags for the ldap auth method.
+func (l *LDAPLogin) checkFlags(authMethodType string) error {
+	if authMethodType != ldapAuthMethodType {
+		if l.username != "" || l.passwordFile != "" {
+			return fmt.Errorf("Missing '-type=ldap' flag")
+		}
+		return nil
+	}
+	if l.username == "" {
+		return fmt.Errorf("Missing '-ldap-username' flag")
+	}
+	return nil
+}
+
+// createLDAPBearerToken generates a bearer token string for the LDAP auth
+// method from the username and password, prompting for the password if it
+// wasn't given in a file.
+func (l *LDAPLogin) createLDAPBearerToken(ui cli.Ui) (string, error) {
+	var password string
+	if l.passwordFile != "" {
+		data, err := os.ReadFile(l.passwordFile)
+		if err != nil {
+			return "", err
+		}
+		password = strings.TrimSpace(string(data))
+	} else {
+		var err error
+		password, err = ui.AskSecret(fmt.Sprintf("Password for %s:", l.username))
+		if err != nil {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+	}
+	if password == "" {
+		return "", fmt.Errorf("No password given for %s", l.username)
+	}
+
+	return ldapauth.NewLoginToken(l.username, password)
+}
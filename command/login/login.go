@@ -39,7 +39,9 @@ type cmd struct {
 	tokenSinkFile   string
 	meta            map[string]string
 
-	aws AWSLogin
+	aws  AWSLogin
+	x509 X509Login
+	ldap LDAPLogin
 
 	enterpriseCmd
 }
@@ -66,6 +68,8 @@ func (c *cmd) init() {
 
 	c.http = &flags.HTTPFlags{}
 	flags.Merge(c.flags, c.aws.flags())
+	flags.Merge(c.flags, c.x509.flags())
+	flags.Merge(c.flags, c.ldap.flags())
 	flags.Merge(c.flags, c.http.ClientFlags())
 	flags.Merge(c.flags, c.http.ServerFlags())
 	flags.Merge(c.flags, c.http.MultiTenancyFlags())
@@ -98,6 +102,22 @@ func (c *cmd) bearerTokenLogin() int {
 		c.UI.Error(err.Error())
 		return 1
 	}
+	if err := c.x509.checkFlags(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	if err := c.ldap.checkFlags(c.authMethodType); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	if c.aws.autoBearerToken && c.x509.certFile != "" {
+		c.UI.Error("Cannot use '-x509-cert-file' flag with '-aws-auto-bearer-token'")
+		return 1
+	}
+	if c.authMethodType == ldapAuthMethodType && (c.aws.autoBearerToken || c.x509.certFile != "") {
+		c.UI.Error("Cannot use '-type=ldap' with '-aws-auto-bearer-token' or '-x509-cert-file'")
+		return 1
+	}
 
 	if c.aws.autoBearerToken {
 		if c.bearerTokenFile != "" {
@@ -111,6 +131,30 @@ func (c *cmd) bearerTokenLogin() int {
 		} else {
 			c.bearerToken = token
 		}
+	} else if c.x509.certFile != "" {
+		if c.bearerTokenFile != "" {
+			c.UI.Error("Cannot use '-bearer-token-file' flag with '-x509-cert-file'")
+			return 1
+		}
+
+		if token, err := c.x509.createX509BearerToken(); err != nil {
+			c.UI.Error(fmt.Sprintf("Error with x509 auth method: %s", err))
+			return 1
+		} else {
+			c.bearerToken = token
+		}
+	} else if c.authMethodType == ldapAuthMethodType {
+		if c.bearerTokenFile != "" {
+			c.UI.Error("Cannot use '-bearer-token-file' flag with '-type=ldap'")
+			return 1
+		}
+
+		if token, err := c.ldap.createLDAPBearerToken(c.UI); err != nil {
+			c.UI.Error(fmt.Sprintf("Error with ldap auth method: %s", err))
+			return 1
+		} else {
+			c.bearerToken = token
+		}
 	} else if c.bearerTokenFile == "" {
 		c.UI.Error("Missing required '-bearer-token-file' flag")
 		return 1
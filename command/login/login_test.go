@@ -4,6 +4,8 @@
 package login
 
 import (
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -17,7 +19,9 @@ import (
 
 	"github.com/hashicorp/consul-awsauth/iamauthtest"
 	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/agent/consul/authmethod/certauth"
 	"github.com/hashicorp/consul/agent/consul/authmethod/kubeauth"
+	"github.com/hashicorp/consul/agent/consul/authmethod/ldapauth"
 	"github.com/hashicorp/consul/agent/consul/authmethod/testauth"
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/command/acl"
@@ -679,6 +683,245 @@ func TestLoginCommand_aws_iam(t *testing.T) {
 	}
 }
 
+func TestLoginCommand_x509(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	testDir := testutil.TempDir(t, "acl")
+
+	a := newTestAgent(t)
+	client := a.Client()
+
+	ca, caKey := certauth.TestCA(t)
+	cert, key := certauth.TestClientCert(t, ca, caKey, nil)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(testDir, "client.pem")
+	keyFile := filepath.Join(testDir, "client-key.pem")
+	require.NoError(t, os.WriteFile(certFile, []byte(certauth.TestCertPEM(cert)), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	_, _, err = client.ACL().AuthMethodCreate(
+		&api.ACLAuthMethod{
+			Name: "x509",
+			Type: "x509",
+			Config: map[string]interface{}{
+				"SPIFFETrustBundles": map[string]string{"example.org": certauth.TestCertPEM(ca)},
+				"BoundAudiences":     []string{"consul"},
+			},
+		},
+		&api.WriteOptions{Token: "root"},
+	)
+	require.NoError(t, err)
+
+	_, _, err = client.ACL().BindingRuleCreate(&api.ACLBindingRule{
+		AuthMethod: "x509",
+		BindType:   api.BindingRuleBindTypeService,
+		BindName:   "${subject.common_name}",
+		Selector:   `spiffe.trust_domain=="example.org"`,
+	},
+		&api.WriteOptions{Token: "root"},
+	)
+	require.NoError(t, err)
+
+	cases := map[string]struct {
+		args   []string
+		expErr string
+	}{
+		"success": {
+			args: []string{"-x509-cert-file", certFile, "-x509-key-file", keyFile, "-x509-audience", "consul"},
+		},
+		"wrong audience": {
+			args:   []string{"-x509-cert-file", certFile, "-x509-key-file", keyFile, "-x509-audience", "vault"},
+			expErr: "Error logging in",
+		},
+		"missing key file": {
+			args:   []string{"-x509-cert-file", certFile},
+			expErr: "Missing '-x509-key-file' flag",
+		},
+		"missing audience": {
+			args:   []string{"-x509-cert-file", certFile, "-x509-key-file", keyFile},
+			expErr: "Missing '-x509-audience' flag",
+		},
+		"missing cert file": {
+			args:   []string{"-x509-key-file", keyFile},
+			expErr: "Missing '-x509-cert-file' flag",
+		},
+		"with bearer token file": {
+			args:   []string{"-x509-cert-file", certFile, "-x509-key-file", keyFile, "-x509-audience", "consul", "-bearer-token-file", certFile},
+			expErr: "Cannot use '-bearer-token-file' flag with '-x509-cert-file'",
+		},
+		"key isn't a key": {
+			args:   []string{"-x509-cert-file", certFile, "-x509-key-file", certFile, "-x509-audience", "consul"},
+			expErr: "Error with x509 auth method",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			tokenSinkFile := filepath.Join(testDir, "test.token")
+			defer os.Remove(tokenSinkFile)
+
+			ui := cli.NewMockUi()
+			cmd := New(ui)
+
+			args := append([]string{
+				"-http-addr=" + a.HTTPAddr(),
+				"-token=root",
+				"-method=x509",
+				"-token-sink-file", tokenSinkFile,
+			}, c.args...)
+
+			code := cmd.Run(args)
+			if c.expErr != "" {
+				require.Equal(t, 1, code)
+				require.Contains(t, ui.ErrorWriter.String(), c.expErr)
+				return
+			}
+			require.Equal(t, 0, code, "err: %s", ui.ErrorWriter.String())
+			require.Empty(t, ui.ErrorWriter.String())
+			require.Empty(t, ui.OutputWriter.String())
+
+			raw, err := os.ReadFile(tokenSinkFile)
+			require.NoError(t, err)
+
+			token := strings.TrimSpace(string(raw))
+			require.Len(t, token, 36, "must be a valid uid: %s", token)
+
+			tok, _, err := client.ACL().TokenReadSelf(&api.QueryOptions{Token: token})
+			require.NoError(t, err)
+			require.Equal(t, []*api.ACLServiceIdentity{{ServiceName: "web"}}, tok.ServiceIdentities)
+		})
+	}
+}
+
+func TestLoginCommand_ldap(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	testDir := testutil.TempDir(t, "acl")
+
+	a := newTestAgent(t)
+	client := a.Client()
+
+	srv := ldapauth.StartTestLDAPServer(t, false)
+	defer srv.Stop()
+
+	srv.AddEntry("uid=alice,ou=people,dc=example,dc=org", map[string][]string{"uid": {"alice"}})
+	srv.SetPassword("uid=alice,ou=people,dc=example,dc=org", "alice-secret")
+	srv.AddEntry("cn=operators,ou=groups,dc=example,dc=org", map[string][]string{
+		"cn":     {"operators"},
+		"member": {"uid=alice,ou=people,dc=example,dc=org"},
+	})
+
+	_, _, err := client.ACL().AuthMethodCreate(
+		&api.ACLAuthMethod{
+			Name: "ldap",
+			Type: "ldap",
+			Config: map[string]interface{}{
+				"URL":      "ldap://" + srv.Addr(),
+				"StartTLS": true,
+				"CACert":   srv.CACert(),
+				"UserDN":   "ou=people,dc=example,dc=org",
+				"GroupDN":  "ou=groups,dc=example,dc=org",
+			},
+		},
+		&api.WriteOptions{Token: "root"},
+	)
+	require.NoError(t, err)
+
+	_, _, err = client.ACL().BindingRuleCreate(&api.ACLBindingRule{
+		AuthMethod: "ldap",
+		BindType:   api.BindingRuleBindTypeService,
+		BindName:   "${username}",
+		Selector:   `"operators" in groups`,
+	},
+		&api.WriteOptions{Token: "root"},
+	)
+	require.NoError(t, err)
+
+	passwordFile := filepath.Join(testDir, "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("alice-secret\n"), 0600))
+
+	cases := map[string]struct {
+		args   []string
+		input  string
+		expErr string
+	}{
+		"success - password prompt": {
+			args:  []string{"-type=ldap", "-ldap-username=alice"},
+			input: "alice-secret\n",
+		},
+		"success - password file": {
+			args: []string{"-type=ldap", "-ldap-username=alice", "-ldap-password-file", passwordFile},
+		},
+		"wrong password": {
+			args:   []string{"-type=ldap", "-ldap-username=alice"},
+			input:  "wrong\n",
+			expErr: "invalid username or password",
+		},
+		"empty password": {
+			args:   []string{"-type=ldap", "-ldap-username=alice"},
+			input:  "\n",
+			expErr: "No password given for alice",
+		},
+		"missing username": {
+			args:   []string{"-type=ldap"},
+			expErr: "Missing '-ldap-username' flag",
+		},
+		"missing type": {
+			args:   []string{"-ldap-username=alice"},
+			expErr: "Missing '-type=ldap' flag",
+		},
+		"with bearer token file": {
+			args:   []string{"-type=ldap", "-ldap-username=alice", "-bearer-token-file", passwordFile},
+			expErr: "Cannot use '-bearer-token-file' flag with '-type=ldap'",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			tokenSinkFile := filepath.Join(testDir, "test.token")
+			defer os.Remove(tokenSinkFile)
+
+			ui := cli.NewMockUi()
+			ui.InputReader = strings.NewReader(c.input)
+			cmd := New(ui)
+
+			args := append([]string{
+				"-http-addr=" + a.HTTPAddr(),
+				"-token=root",
+				"-method=ldap",
+				"-token-sink-file", tokenSinkFile,
+			}, c.args...)
+
+			code := cmd.Run(args)
+			if c.expErr != "" {
+				require.Equal(t, 1, code)
+				require.Contains(t, ui.ErrorWriter.String(), c.expErr)
+				return
+			}
+			require.Equal(t, 0, code, "err: %s", ui.ErrorWriter.String())
+			require.Empty(t, ui.ErrorWriter.String())
+
+			raw, err := os.ReadFile(tokenSinkFile)
+			require.NoError(t, err)
+
+			token := strings.TrimSpace(string(raw))
+			require.Len(t, token, 36, "must be a valid uid: %s", token)
+
+			tok, _, err := client.ACL().TokenReadSelf(&api.QueryOptions{Token: token})
+			require.NoError(t, err)
+			require.Equal(t, []*api.ACLServiceIdentity{{ServiceName: "alice"}}, tok.ServiceIdentities)
+		})
+	}
+}
+
 func newTestAgent(t *testing.T) *agent.TestAgent {
 	a := agent.NewTestAgent(t, `
 	primary_datacenter = "dc1"
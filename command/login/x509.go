@@ -0,0 +1,90 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package login
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashicorp/consul/agent/connect"
+	"github.com/hashicorp/consul/agent/consul/authmethod/certauth"
+)
+
+type X509Login struct {
+	certFile string
+	keyFile  string
+	audience string
+}
+
+func (x *X509Login) flags() *flag.FlagSet {
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	fs.StringVar(&x.certFile, "x509-cert-file", "",
+		"Path to a PEM encoded client certificate, followed by any intermediate certificates, "+
+			"used to construct a bearer token and login to the X.509 auth method. "+
+			"Requires -x509-key-file. [x509 only]")
+
+	fs.StringVar(&x.keyFile, "x509-key-file", "",
+		"Path to the PEM encoded private key of the -x509-cert-file certificate. [x509 only]")
+
+	fs.StringVar(&x.audience, "x509-audience", "",
+		"Audience of the bearer token. This must match one of the auth method's BoundAudiences. "+
+			"Required with -x509-cert-file. [x509 only]")
+	return fs
+}
+
+// checkFlags validates flags for the x509 auth method.
+func (x *X509Login) checkFlags() error {
+	if x.certFile == "" {
+		if x.keyFile != "" || x.audience != "" {
+			return fmt.Errorf("Missing '-x509-cert-file' flag")
+		}
+		return nil
+	}
+	if x.keyFile == "" {
+		return fmt.Errorf("Missing '-x509-key-file' flag")
+	}
+	if x.audience == "" {
+		return fmt.Errorf("Missing '-x509-audience' flag")
+	}
+	return nil
+}
+
+// createX509BearerToken generates a bearer token string for the X.509 auth
+// method. The token carries the client certificate chain and is signed with
+// the certificate's private key, proving possession of the certificate.
+func (x *X509Login) createX509BearerToken() (string, error) {
+	certPEM, err := os.ReadFile(x.certFile)
+	if err != nil {
+		return "", err
+	}
+	var chain []*x509.Certificate
+	for block, rest := pem.Decode(certPEM); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse certificate in %s: %w", x.certFile, err)
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return "", fmt.Errorf("no certificate found in %s", x.certFile)
+	}
+
+	keyPEM, err := os.ReadFile(x.keyFile)
+	if err != nil {
+		return "", err
+	}
+	key, err := connect.ParseSigner(string(keyPEM))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse private key in %s: %w", x.keyFile, err)
+	}
+
+	return certauth.NewLoginToken(chain, key, x.audience, time.Minute)
+}
@@ -57,6 +57,9 @@ var requestTypeZeroValues = map[structs.MessageType]func() any{
 	structs.PeeringSecretsWriteType:      func() any { return new(pbpeering.PeeringSecrets) },
 	structs.ResourceOperationType:        func() any { return new(pbresource.Resource) },
 	structs.FeatureGateRequestType:       func() any { return new(structs.FeatureGateSnapshot) },
+	structs.KVSHistoryConfigRequestType:  func() any { return new(structs.KVSHistoryConfig) },
+	structs.KVSRevisionType:              func() any { return new(structs.DirEntryRevision) },
+	structs.KVSQuotaRequestType:          func() any { return new(structs.KVSQuota) },
 }
 
 func New(ui cli.Ui) *cmd {
@@ -71,6 +74,8 @@ type cmd struct {
 	help   string
 	format string
 
+	keyfile string
+
 	encoder *json.Encoder
 }
 
@@ -82,6 +87,9 @@ func (c *cmd) Write(p []byte) (n int, err error) {
 
 func (c *cmd) init() {
 	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(&c.keyfile, "keyfile", "",
+		"Path to the key file used to decrypt an encrypted snapshot. If not given, the "+
+			"passphrase in the "+snapshot.PassphraseEnvName+" environment variable is used.")
 	c.help = flags.Usage(help, c.flags)
 	c.encoder = json.NewEncoder(c)
 }
@@ -136,7 +144,19 @@ func (c *cmd) Run(args []string) int {
 		}
 		meta = &metaDecoded
 	} else {
-		readFile, meta, err = snapshot.Read(hclog.New(nil), f)
+		key, err := snapshot.LoadEncryptionKey(c.keyfile, os.Getenv(snapshot.PassphraseEnvName))
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error loading encryption key: %s", err))
+			return 1
+		}
+		in, err := snapshot.MaybeDecrypt(hclog.New(nil), f, key)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error decrypting snapshot: %s", err))
+			return 1
+		}
+		defer in.Close()
+
+		readFile, meta, err = snapshot.Read(hclog.New(nil), in)
 		if err != nil {
 			c.UI.Error(fmt.Sprintf("Error reading snapshot: %s", err))
 			return 1
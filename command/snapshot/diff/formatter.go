@@ -0,0 +1,89 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	PrettyFormat string = "pretty"
+	JSONFormat   string = "json"
+)
+
+type Formatter interface {
+	Format(*OutputFormat) (string, error)
+}
+
+func GetSupportedFormats() []string {
+	return []string{PrettyFormat, JSONFormat}
+}
+
+func NewFormatter(format string) (Formatter, error) {
+	switch format {
+	case PrettyFormat:
+		return newPrettyFormatter(), nil
+	case JSONFormat:
+		return newJSONFormatter(), nil
+	default:
+		return nil, fmt.Errorf("Unknown format: %s", format)
+	}
+}
+
+type prettyFormatter struct{}
+
+func newPrettyFormatter() Formatter {
+	return &prettyFormatter{}
+}
+
+func (*prettyFormatter) Format(info *OutputFormat) (string, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s (index %d)\n", info.From.ID, info.From.Index)
+	fmt.Fprintf(&b, "To:   %s (index %d)\n", info.To.ID, info.To.Index)
+
+	var added, removed, modified int
+	for _, recordType := range recordTypes {
+		rc, ok := info.Changes[recordType]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s:\n", recordType)
+		for _, key := range rc.Added {
+			fmt.Fprintf(&b, "  + %s\n", key)
+		}
+		for _, key := range rc.Removed {
+			fmt.Fprintf(&b, "  - %s\n", key)
+		}
+		for _, m := range rc.Modified {
+			fmt.Fprintf(&b, "  ~ %s (%s)\n", m.Key, strings.Join(m.Fields, ", "))
+		}
+		added += len(rc.Added)
+		removed += len(rc.Removed)
+		modified += len(rc.Modified)
+	}
+
+	if added+removed+modified == 0 {
+		b.WriteString("\nNo differences")
+	} else {
+		fmt.Fprintf(&b, "\n%d added, %d removed, %d modified", added, removed, modified)
+	}
+	return b.String(), nil
+}
+
+type jsonFormatter struct{}
+
+func newJSONFormatter() Formatter {
+	return &jsonFormatter{}
+}
+
+func (*jsonFormatter) Format(info *OutputFormat) (string, error) {
+	b, err := json.MarshalIndent(info, "", "   ")
+	if err != nil {
+		return "", fmt.Errorf("Failed to marshal diff: %v", err)
+	}
+	return string(b), nil
+}
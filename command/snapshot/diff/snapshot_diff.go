@@ -0,0 +1,401 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package diff
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/consul-net-rpc/go-msgpack/codec"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/agent/consul/fsm"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/snapshot"
+)
+
+// The types of records that are compared, in the order they are output.
+const (
+	typeNodes           = "nodes"
+	typeServices        = "services"
+	typeKV              = "kv"
+	typeACLTokens       = "acl-tokens"
+	typeACLPolicies     = "acl-policies"
+	typeACLRoles        = "acl-roles"
+	typeACLBindingRules = "acl-binding-rules"
+	typeACLAuthMethods  = "acl-auth-methods"
+	typeConfigEntries   = "config-entries"
+	typeIntentions      = "intentions"
+)
+
+var recordTypes = []string{
+	typeNodes,
+	typeServices,
+	typeKV,
+	typeACLTokens,
+	typeACLPolicies,
+	typeACLRoles,
+	typeACLBindingRules,
+	typeACLAuthMethods,
+	typeConfigEntries,
+	typeIntentions,
+}
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI     cli.Ui
+	flags  *flag.FlagSet
+	help   string
+	format string
+
+	// flags
+	keyfile string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(
+		&c.format,
+		"format",
+		PrettyFormat,
+		fmt.Sprintf("Output format {%s}", strings.Join(GetSupportedFormats(), "|")))
+	c.flags.StringVar(&c.keyfile, "keyfile", "",
+		"Path to the key file used to decrypt encrypted snapshots. If not given, the "+
+			"passphrase in the "+snapshot.PassphraseEnvName+" environment variable is used.")
+
+	c.help = flags.Usage(help, c.flags)
+}
+
+// Snapshot identifies one of the snapshots being compared.
+type Snapshot struct {
+	ID    string
+	Index uint64
+	Term  uint64
+}
+
+// RecordChanges lists the records of one type that differ between the
+// snapshots, by key.
+type RecordChanges struct {
+	Added    []string         `json:",omitempty"`
+	Removed  []string         `json:",omitempty"`
+	Modified []ModifiedRecord `json:",omitempty"`
+}
+
+// ModifiedRecord is a record that is in both snapshots with different values.
+// Only the names of the fields that changed are given, so the output doesn't
+// leak secrets such as token secret IDs.
+type ModifiedRecord struct {
+	Key    string
+	Fields []string
+}
+
+// OutputFormat is used for passing information through the formatter.
+type OutputFormat struct {
+	From    Snapshot
+	To      Snapshot
+	Changes map[string]*RecordChanges
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = c.flags.Args()
+	if len(args) != 2 {
+		c.UI.Error(fmt.Sprintf("Expected two snapshot files to compare, got %d", len(args)))
+		return 1
+	}
+
+	formatter, err := NewFormatter(c.format)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	key, err := snapshot.LoadEncryptionKey(c.keyfile, os.Getenv(snapshot.PassphraseEnvName))
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error loading encryption key: %s", err))
+		return 1
+	}
+
+	fromMeta, from, err := readRecords(args[0], key)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading snapshot %q: %s", args[0], err))
+		return 1
+	}
+	toMeta, to, err := readRecords(args[1], key)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading snapshot %q: %s", args[1], err))
+		return 1
+	}
+
+	out, err := formatter.Format(&OutputFormat{
+		From:    Snapshot{ID: fromMeta.ID, Index: fromMeta.Index, Term: fromMeta.Term},
+		To:      Snapshot{ID: toMeta.ID, Index: toMeta.Index, Term: toMeta.Term},
+		Changes: compare(from, to),
+	})
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error outputting snapshot diff: %s", err))
+		return 1
+	}
+	c.UI.Output(out)
+	return 0
+}
+
+// records holds the records of each type in a snapshot, by key. Each record
+// is held as its decoded JSON encoding so records can be compared field by
+// field.
+type records map[string]map[string]map[string]any
+
+func (r records) add(recordType, key string, val any) error {
+	raw, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s record %q: %v", recordType, key, err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return fmt.Errorf("failed to decode %s record %q: %v", recordType, key, err)
+	}
+
+	// The Raft indexes change whenever a record is written, even if its
+	// contents don't, and hashes only change along with other fields.
+	delete(fields, "CreateIndex")
+	delete(fields, "ModifyIndex")
+	delete(fields, "Hash")
+
+	if r[recordType] == nil {
+		r[recordType] = make(map[string]map[string]any)
+	}
+	r[recordType][key] = fields
+	return nil
+}
+
+// readRecords reads the records to compare from the snapshot in the given
+// file, decrypting it with the given key if needed.
+func readRecords(path string, key *snapshot.EncryptionKey) (*raft.SnapshotMeta, records, error) {
+	logger := hclog.NewNullLogger()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	in, err := snapshot.MaybeDecrypt(logger, f, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer in.Close()
+
+	state, meta, err := snapshot.Read(logger, in)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		state.Close()
+		os.Remove(state.Name())
+	}()
+
+	recs := make(records)
+	handler := func(_ *fsm.SnapshotHeader, msg structs.MessageType, dec *codec.Decoder) error {
+		switch msg {
+		case structs.RegisterRequestType:
+			var req structs.RegisterRequest
+			if err := dec.Decode(&req); err != nil {
+				return err
+			}
+			node := qualify(req.Node, req.PartitionOrEmpty(), "", req.PeerName)
+			switch {
+			case req.Service != nil:
+				return recs.add(typeServices, node+"/"+req.Service.ID, req.Service)
+			case req.Check != nil:
+				return nil
+			default:
+				return recs.add(typeNodes, node, &req)
+			}
+		case structs.KVSRequestType:
+			var entry structs.DirEntry
+			if err := dec.Decode(&entry); err != nil {
+				return err
+			}
+			return recs.add(typeKV, qualify(entry.Key, entry.PartitionOrEmpty(), entry.NamespaceOrEmpty(), ""), &entry)
+		case structs.ACLTokenSetRequestType:
+			var token structs.ACLToken
+			if err := dec.Decode(&token); err != nil {
+				return err
+			}
+			return recs.add(typeACLTokens, token.AccessorID, &token)
+		case structs.ACLPolicySetRequestType:
+			var policy structs.ACLPolicy
+			if err := dec.Decode(&policy); err != nil {
+				return err
+			}
+			return recs.add(typeACLPolicies, qualify(policy.Name, policy.PartitionOrEmpty(), policy.NamespaceOrEmpty(), ""), &policy)
+		case structs.ACLRoleSetRequestType:
+			var role structs.ACLRole
+			if err := dec.Decode(&role); err != nil {
+				return err
+			}
+			return recs.add(typeACLRoles, qualify(role.Name, role.PartitionOrEmpty(), role.NamespaceOrEmpty(), ""), &role)
+		case structs.ACLBindingRuleSetRequestType:
+			var rule structs.ACLBindingRule
+			if err := dec.Decode(&rule); err != nil {
+				return err
+			}
+			return recs.add(typeACLBindingRules, rule.ID, &rule)
+		case structs.ACLAuthMethodSetRequestType:
+			var method structs.ACLAuthMethod
+			if err := dec.Decode(&method); err != nil {
+				return err
+			}
+			return recs.add(typeACLAuthMethods, qualify(method.Name, method.PartitionOrEmpty(), method.NamespaceOrEmpty(), ""), &method)
+		case structs.ConfigEntryRequestType:
+			var req structs.ConfigEntryRequest
+			if err := dec.Decode(&req); err != nil {
+				return err
+			}
+			return addConfigEntry(recs, req.Entry)
+		case structs.IntentionRequestType:
+			var ixn structs.Intention
+			if err := dec.Decode(&ixn); err != nil {
+				return err
+			}
+			key := qualify(ixn.SourceName, ixn.SourcePartition, ixn.SourceNS, ixn.SourcePeer) + " -> " +
+				qualify(ixn.DestinationName, ixn.DestinationPartition, ixn.DestinationNS, "")
+			return recs.add(typeIntentions, key, &ixn)
+		default:
+			var ignore interface{}
+			return dec.Decode(&ignore)
+		}
+	}
+	if err := fsm.ReadSnapshot(state, handler); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode snapshot: %v", err)
+	}
+	return meta, recs, nil
+}
+
+// addConfigEntry adds a config entry to the records. Service intentions are
+// added as one intention per source rather than as a config entry, so that
+// changes to a single intention are easy to spot.
+func addConfigEntry(recs records, entry structs.ConfigEntry) error {
+	ixns, ok := entry.(*structs.ServiceIntentionsConfigEntry)
+	if !ok {
+		key := entry.GetKind() + "/" + qualify(entry.GetName(), entry.GetEnterpriseMeta().PartitionOrEmpty(), entry.GetEnterpriseMeta().NamespaceOrEmpty(), "")
+		return recs.add(typeConfigEntries, key, entry)
+	}
+
+	dest := qualify(ixns.Name, ixns.PartitionOrEmpty(), ixns.NamespaceOrEmpty(), "")
+	for _, src := range ixns.Sources {
+		key := qualify(src.Name, src.PartitionOrEmpty(), src.NamespaceOrEmpty(), src.Peer) + " -> " + dest
+		if err := recs.add(typeIntentions, key, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// qualify prefixes a name with the peer, partition and namespace it belongs
+// to, when they are set.
+func qualify(name, partition, namespace, peer string) string {
+	var parts []string
+	for _, part := range []string{peer, partition, namespace} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(append(parts, name), "/")
+}
+
+// compare returns the changes to each type of record between the snapshots.
+// Types with no changes are left out.
+func compare(from, to records) map[string]*RecordChanges {
+	changes := make(map[string]*RecordChanges)
+	for _, recordType := range recordTypes {
+		var rc RecordChanges
+		for key, fields := range to[recordType] {
+			old, ok := from[recordType][key]
+			if !ok {
+				rc.Added = append(rc.Added, key)
+				continue
+			}
+			if modified := changedFields(old, fields); len(modified) > 0 {
+				rc.Modified = append(rc.Modified, ModifiedRecord{Key: key, Fields: modified})
+			}
+		}
+		for key := range from[recordType] {
+			if _, ok := to[recordType][key]; !ok {
+				rc.Removed = append(rc.Removed, key)
+			}
+		}
+		if len(rc.Added) == 0 && len(rc.Removed) == 0 && len(rc.Modified) == 0 {
+			continue
+		}
+
+		sort.Strings(rc.Added)
+		sort.Strings(rc.Removed)
+		sort.Slice(rc.Modified, func(i, j int) bool {
+			return rc.Modified[i].Key < rc.Modified[j].Key
+		})
+		changes[recordType] = &rc
+	}
+	return changes
+}
+
+// changedFields returns the sorted names of the fields that differ between two
+// records.
+func changedFields(from, to map[string]any) []string {
+	var fields []string
+	for name, val := range to {
+		if !reflect.DeepEqual(from[name], val) {
+			fields = append(fields, name)
+		}
+	}
+	for name := range from {
+		if _, ok := to[name]; !ok {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+const synopsis = "Shows the differences between two snapshots"
+const help = `
+Usage: consul snapshot diff [options] FROM TO
+
+  Compares two snapshot files on disk and shows the records that were added,
+  removed or modified between them. Nodes, services, KV entries, ACL tokens,
+  policies, roles, binding rules and auth methods, config entries and
+  intentions are compared. Modified records list the names of the fields that
+  changed, but not their values.
+
+  To see what changed between two hourly snapshots:
+
+    $ consul snapshot diff backup-0100.snap backup-0200.snap
+
+  For a full list of options and examples, please see the Consul documentation.
+`
@@ -0,0 +1,147 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package diff
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/sdk/testutil"
+)
+
+func TestSnapshotDiffCommand_noTabs(t *testing.T) {
+	t.Parallel()
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestSnapshotDiffCommand_Validation(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args   []string
+		output string
+	}{
+		"no file": {
+			[]string{},
+			"Expected two snapshot files to compare, got 0",
+		},
+		"one file": {
+			[]string{"foo"},
+			"Expected two snapshot files to compare, got 1",
+		},
+		"missing file": {
+			[]string{"foo", "bar"},
+			`Error reading snapshot "foo"`,
+		},
+		"bad format": {
+			[]string{"-format", "yaml", "foo", "bar"},
+			"Unknown format: yaml",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			code := New(ui).Run(tc.args)
+			require.Equal(t, 1, code)
+			require.Contains(t, ui.ErrorWriter.String(), tc.output)
+		})
+	}
+}
+
+func TestSnapshotDiffCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	client := a.Client()
+	kv := client.KV()
+
+	dir := testutil.TempDir(t, "snapshot")
+	save := func(name string) string {
+		t.Helper()
+		snap, _, err := client.Snapshot().Save(nil)
+		require.NoError(t, err)
+		defer snap.Close()
+
+		file := filepath.Join(dir, name)
+		f, err := os.Create(file)
+		require.NoError(t, err)
+		defer f.Close()
+		_, err = io.Copy(f, snap)
+		require.NoError(t, err)
+		return file
+	}
+	put := func(key, value string) {
+		t.Helper()
+		_, err := kv.Put(&api.KVPair{Key: key, Value: []byte(value)}, nil)
+		require.NoError(t, err)
+	}
+
+	put("a", "1")
+	put("b", "2")
+	_, _, err := client.ConfigEntries().Set(&api.ServiceConfigEntry{Kind: api.ServiceDefaults, Name: "web", Protocol: "http"}, nil)
+	require.NoError(t, err)
+	from := save("from.snap")
+
+	put("b", "changed")
+	put("c", "3")
+	_, err = kv.Delete("a", nil)
+	require.NoError(t, err)
+	_, _, err = client.ConfigEntries().Set(&api.ServiceConfigEntry{Kind: api.ServiceDefaults, Name: "web", Protocol: "grpc"}, nil)
+	require.NoError(t, err)
+	_, _, err = client.ConfigEntries().Set(&api.ServiceIntentionsConfigEntry{
+		Kind: api.ServiceIntentions,
+		Name: "db",
+		Sources: []*api.SourceIntention{
+			{Name: "web", Action: api.IntentionActionAllow},
+		},
+	}, nil)
+	require.NoError(t, err)
+	require.NoError(t, client.Agent().ServiceRegister(&api.AgentServiceRegistration{Name: "api", ID: "api-1"}))
+	to := save("to.snap")
+
+	// Comparing a snapshot with itself finds nothing.
+	ui := cli.NewMockUi()
+	code := New(ui).Run([]string{from, from})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	require.Contains(t, ui.OutputWriter.String(), "No differences")
+
+	ui = cli.NewMockUi()
+	code = New(ui).Run([]string{from, to})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	output := ui.OutputWriter.String()
+	require.Contains(t, output, "kv:\n  + c\n  - a\n  ~ b (Value)\n")
+	require.Contains(t, output, "config-entries:\n  ~ service-defaults/web (Protocol)\n")
+	require.Contains(t, output, "intentions:\n  + web -> db\n")
+	require.Contains(t, output, "  + "+a.Config.NodeName+"/api-1\n")
+
+	ui = cli.NewMockUi()
+	code = New(ui).Run([]string{"-format=json", from, to})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+	var out OutputFormat
+	require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &out))
+	require.Equal(t, &RecordChanges{
+		Added:    []string{"c"},
+		Removed:  []string{"a"},
+		Modified: []ModifiedRecord{{Key: "b", Fields: []string{"Value"}}},
+	}, out.Changes[typeKV])
+	require.Equal(t, []string{a.Config.NodeName + "/api-1"}, out.Changes[typeServices].Added)
+	require.Less(t, out.From.Index, out.To.Index)
+}
@@ -39,6 +39,7 @@ type cmd struct {
 	kvDetails bool
 	kvDepth   int
 	kvFilter  string
+	keyfile   string
 }
 
 func (c *cmd) init() {
@@ -54,6 +55,9 @@ func (c *cmd) init() {
 		"format",
 		PrettyFormat,
 		fmt.Sprintf("Output format {%s}", strings.Join(GetSupportedFormats(), "|")))
+	c.flags.StringVar(&c.keyfile, "keyfile", "",
+		"Path to the key file used to decrypt an encrypted snapshot. If not given, the "+
+			"passphrase in the "+snapshot.PassphraseEnvName+" environment variable is used.")
 
 	c.help = flags.Usage(help, c.flags)
 }
@@ -138,7 +142,19 @@ func (c *cmd) Run(args []string) int {
 		}
 		meta = &metaDecoded
 	} else {
-		readFile, meta, err = snapshot.Read(hclog.New(nil), f)
+		key, err := snapshot.LoadEncryptionKey(c.keyfile, os.Getenv(snapshot.PassphraseEnvName))
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error loading encryption key: %s", err))
+			return 1
+		}
+		in, err := snapshot.MaybeDecrypt(hclog.New(nil), f, key)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error decrypting snapshot: %s", err))
+			return 1
+		}
+		defer in.Close()
+
+		readFile, meta, err = snapshot.Read(hclog.New(nil), in)
 		if err != nil {
 			c.UI.Error(fmt.Sprintf("Error reading snapshot: %s", err))
 			return 1
@@ -367,6 +383,10 @@ Usage: consul snapshot inspect [options] FILE
   To inspect the file "backup.snap":
 
     $ consul snapshot inspect backup.snap
+
+  To inspect a snapshot saved with "consul snapshot save -encrypt":
+
+    $ consul snapshot inspect -keyfile snapshot.key backup.snap
   
   For a full list of options and examples, please see the Consul documentation.
 `
@@ -4,6 +4,8 @@
 package inspect
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"flag"
 	"os"
 	"path/filepath"
@@ -12,6 +14,9 @@ import (
 
 	"github.com/mitchellh/cli"
 	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/hashicorp/consul/snapshot"
 )
 
 // update allows golden files to be updated based on the current output.
@@ -187,3 +192,36 @@ func TestSnapshotInspectInvalidFile(t *testing.T) {
 		t.Fatalf("should return an error code")
 	}
 }
+
+func TestSnapshotInspectCommand_Encrypted(t *testing.T) {
+	dir := testutil.TempDir(t, "snapshot")
+
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	keyfile := filepath.Join(dir, "snapshot.key")
+	require.NoError(t, os.WriteFile(keyfile, []byte(base64.StdEncoding.EncodeToString(key)), 0600))
+
+	// Encrypt the test snapshot.
+	in, err := os.Open("./testdata/backup.snap")
+	require.NoError(t, err)
+	defer in.Close()
+	file := filepath.Join(dir, "backup.snap")
+	out, err := os.Create(file)
+	require.NoError(t, err)
+	require.NoError(t, snapshot.Encrypt(out, in, &snapshot.EncryptionKey{Key: key}))
+	require.NoError(t, out.Close())
+
+	ui := cli.NewMockUi()
+	code := New(ui).Run([]string{file})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "snapshot is encrypted")
+
+	// The output matches that of the unencrypted snapshot.
+	ui = cli.NewMockUi()
+	code = New(ui).Run([]string{"-keyfile", keyfile, file})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+	want := golden(t, "TestSnapshotInspectCommand", "")
+	require.Equal(t, want, ui.OutputWriter.String())
+}
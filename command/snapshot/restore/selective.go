@@ -0,0 +1,465 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package restore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/consul-net-rpc/go-msgpack/codec"
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/consul/agent/consul/fsm"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/snapshot"
+)
+
+// Selective restores read the records of the selected types from a snapshot
+// and write them back through the HTTP API, instead of replacing the whole
+// state of the servers. Records that are missing or differ from the snapshot
+// are written, and records created since the snapshot was taken are left in
+// place.
+
+// The record types that can be selected with -only.
+const (
+	onlyKV            = "kv"
+	onlyACLPolicies   = "acl-policies"
+	onlyACLRoles      = "acl-roles"
+	onlyConfigEntries = "config-entries"
+)
+
+var selectiveTypes = []string{onlyACLPolicies, onlyACLRoles, onlyConfigEntries, onlyKV}
+
+// maxKVTxnOps is the number of KV writes batched into each transaction, which
+// is limited by the transaction endpoint.
+const maxKVTxnOps = 64
+
+// parseOnly parses the comma-separated list of record types given to -only.
+func parseOnly(only string) (map[string]bool, error) {
+	selected := make(map[string]bool)
+	for _, name := range strings.Split(only, ",") {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(selectiveTypes, name) {
+			return nil, fmt.Errorf("unsupported record type %q, must be one of: %s", name, strings.Join(selectiveTypes, ", "))
+		}
+		selected[name] = true
+	}
+	return selected, nil
+}
+
+// records holds the records of the selected types read from a snapshot.
+type records struct {
+	kv            []*structs.DirEntry
+	policies      []*structs.ACLPolicy
+	roles         []*structs.ACLRole
+	configEntries []structs.ConfigEntry
+
+	// policyNames maps the IDs of every policy in the snapshot to their
+	// names, so role links can be resolved even if a policy is re-created
+	// with a new ID.
+	policyNames map[string]string
+}
+
+// readRecords reads the records of the selected types whose key or name starts
+// with the given prefix from the snapshot archive.
+func readRecords(in io.Reader, selected map[string]bool, prefix string) (*records, error) {
+	logger := hclog.NewNullLogger()
+	state, _, err := snapshot.Read(logger, in)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		state.Close()
+		os.Remove(state.Name())
+	}()
+
+	recs := &records{policyNames: make(map[string]string)}
+	handler := func(_ *fsm.SnapshotHeader, msg structs.MessageType, dec *codec.Decoder) error {
+		switch msg {
+		case structs.KVSRequestType:
+			var entry structs.DirEntry
+			if err := dec.Decode(&entry); err != nil {
+				return err
+			}
+			if selected[onlyKV] && strings.HasPrefix(entry.Key, prefix) {
+				recs.kv = append(recs.kv, &entry)
+			}
+		case structs.ACLPolicySetRequestType:
+			var policy structs.ACLPolicy
+			if err := dec.Decode(&policy); err != nil {
+				return err
+			}
+			recs.policyNames[policy.ID] = policy.Name
+			if selected[onlyACLPolicies] && strings.HasPrefix(policy.Name, prefix) {
+				recs.policies = append(recs.policies, &policy)
+			}
+		case structs.ACLRoleSetRequestType:
+			var role structs.ACLRole
+			if err := dec.Decode(&role); err != nil {
+				return err
+			}
+			if selected[onlyACLRoles] && strings.HasPrefix(role.Name, prefix) {
+				recs.roles = append(recs.roles, &role)
+			}
+		case structs.ConfigEntryRequestType:
+			var req structs.ConfigEntryRequest
+			if err := dec.Decode(&req); err != nil {
+				return err
+			}
+			if selected[onlyConfigEntries] && strings.HasPrefix(req.Entry.GetName(), prefix) {
+				recs.configEntries = append(recs.configEntries, req.Entry)
+			}
+		default:
+			var ignore interface{}
+			return dec.Decode(&ignore)
+		}
+		return nil
+	}
+	if err := fsm.ReadSnapshot(state, handler); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %v", err)
+	}
+	return recs, nil
+}
+
+// change is a record that differs from the current state of the cluster.
+type change struct {
+	recordType string
+	name       string
+	added      bool
+
+	// kv is set for KV changes, which are batched into transactions.
+	// Otherwise apply writes the change.
+	kv    *api.KVPair
+	apply func() error
+}
+
+func (c *change) String() string {
+	op := "~"
+	if c.added {
+		op = "+"
+	}
+	return fmt.Sprintf("%s %s %s", op, c.recordType, c.name)
+}
+
+// plan compares the records with the current state of the cluster and returns
+// the changes needed to restore them, along with the number of records that
+// are unchanged.
+func plan(client *api.Client, recs *records, prefix string) ([]*change, int, error) {
+	var changes []*change
+	var unchanged int
+	add := func(c *change) {
+		if c == nil {
+			unchanged++
+			return
+		}
+		changes = append(changes, c)
+	}
+
+	if len(recs.kv) > 0 {
+		current, _, err := client.KV().List(prefix, nil)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list keys: %v", err)
+		}
+		existing := make(map[string]*api.KVPair, len(current))
+		for _, pair := range current {
+			existing[pair.Key] = pair
+		}
+		for _, entry := range recs.kv {
+			add(planKV(entry, existing[entry.Key]))
+		}
+	}
+
+	if len(recs.policies) > 0 {
+		current, _, err := client.ACL().PolicyList(nil)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list ACL policies: %v", err)
+		}
+		for _, policy := range recs.policies {
+			c, err := planPolicy(client.ACL(), policy, current)
+			if err != nil {
+				return nil, 0, err
+			}
+			add(c)
+		}
+	}
+
+	if len(recs.roles) > 0 {
+		current, _, err := client.ACL().RoleList(nil)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list ACL roles: %v", err)
+		}
+		for _, role := range recs.roles {
+			c, err := planRole(client.ACL(), role, recs.policyNames, current)
+			if err != nil {
+				return nil, 0, err
+			}
+			add(c)
+		}
+	}
+
+	current := make(map[string][]api.ConfigEntry)
+	for _, entry := range recs.configEntries {
+		kind := entry.GetKind()
+		if _, ok := current[kind]; !ok {
+			entries, _, err := client.ConfigEntries().List(kind, nil)
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to list %s config entries: %v", kind, err)
+			}
+			current[kind] = entries
+		}
+		c, err := planConfigEntry(client.ConfigEntries(), entry, current[kind])
+		if err != nil {
+			return nil, 0, err
+		}
+		add(c)
+	}
+
+	return changes, unchanged, nil
+}
+
+func planKV(entry *structs.DirEntry, existing *api.KVPair) *change {
+	if existing != nil && existing.Flags == entry.Flags && string(existing.Value) == string(entry.Value) {
+		return nil
+	}
+	return &change{
+		recordType: onlyKV,
+		name:       entry.Key,
+		added:      existing == nil,
+		kv: &api.KVPair{
+			Key:       entry.Key,
+			Flags:     entry.Flags,
+			Value:     entry.Value,
+			Namespace: entry.NamespaceOrEmpty(),
+			Partition: entry.PartitionOrEmpty(),
+		},
+	}
+}
+
+// planPolicy matches the policy with an existing one by ID or else by name. A
+// policy that no longer exists is created with a new ID, as policies can't
+// be created with a given ID.
+func planPolicy(acl *api.ACL, policy *structs.ACLPolicy, current []*api.ACLPolicyListEntry) (*change, error) {
+	restored := &api.ACLPolicy{
+		Name:        policy.Name,
+		Description: policy.Description,
+		Rules:       policy.Rules,
+		Datacenters: policy.Datacenters,
+		Namespace:   policy.NamespaceOrEmpty(),
+		Partition:   policy.PartitionOrEmpty(),
+	}
+
+	var match *api.ACLPolicyListEntry
+	for _, entry := range current {
+		if entry.ID == policy.ID {
+			match = entry
+			break
+		}
+		if entry.Name == policy.Name {
+			match = entry
+		}
+	}
+
+	c := &change{recordType: onlyACLPolicies, name: policy.Name}
+	if match == nil {
+		c.added = true
+		c.apply = func() error {
+			_, _, err := acl.PolicyCreate(restored, nil)
+			return err
+		}
+		return c, nil
+	}
+
+	existing, _, err := acl.PolicyRead(match.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ACL policy %q: %v", match.Name, err)
+	}
+	if existing.Name == restored.Name &&
+		existing.Description == restored.Description &&
+		existing.Rules == restored.Rules &&
+		slices.Equal(existing.Datacenters, restored.Datacenters) {
+		return nil, nil
+	}
+	restored.ID = match.ID
+	c.apply = func() error {
+		_, _, err := acl.PolicyUpdate(restored, nil)
+		return err
+	}
+	return c, nil
+}
+
+// planRole matches the role with an existing one by ID or else by name. Role
+// policy links are resolved by name, so they follow any policies that were
+// re-created with a new ID.
+func planRole(acl *api.ACL, role *structs.ACLRole, policyNames map[string]string, current []*api.ACLRole) (*change, error) {
+	var restored api.ACLRole
+	if err := convert(role, &restored); err != nil {
+		return nil, fmt.Errorf("failed to convert ACL role %q: %v", role.Name, err)
+	}
+	restored.ID, restored.Hash, restored.CreateIndex, restored.ModifyIndex = "", nil, 0, 0
+	for _, link := range restored.Policies {
+		if name, ok := policyNames[link.ID]; ok {
+			link.ID, link.Name = "", name
+		}
+	}
+
+	var match *api.ACLRole
+	for _, existing := range current {
+		if existing.ID == role.ID {
+			match = existing
+			break
+		}
+		if existing.Name == role.Name {
+			match = existing
+		}
+	}
+
+	c := &change{recordType: onlyACLRoles, name: role.Name}
+	if match == nil {
+		c.added = true
+		c.apply = func() error {
+			_, _, err := acl.RoleCreate(&restored, nil)
+			return err
+		}
+		return c, nil
+	}
+
+	existing := *match
+	existing.ID, existing.Hash, existing.CreateIndex, existing.ModifyIndex = "", nil, 0, 0
+	existing.Policies = nil
+	for _, link := range match.Policies {
+		existing.Policies = append(existing.Policies, &api.ACLRolePolicyLink{Name: link.Name})
+	}
+	if equalJSON(&existing, &restored) {
+		return nil, nil
+	}
+	restored.ID = match.ID
+	c.apply = func() error {
+		_, _, err := acl.RoleUpdate(&restored, nil)
+		return err
+	}
+	return c, nil
+}
+
+func planConfigEntry(entries *api.ConfigEntries, entry structs.ConfigEntry, current []api.ConfigEntry) (*change, error) {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	restored, err := api.DecodeConfigEntryFromJSON(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s config entry %q: %v", entry.GetKind(), entry.GetName(), err)
+	}
+
+	c := &change{
+		recordType: onlyConfigEntries,
+		name:       entry.GetKind() + "/" + entry.GetName(),
+		added:      true,
+		apply: func() error {
+			_, _, err := entries.Set(restored, nil)
+			return err
+		},
+	}
+	for _, existing := range current {
+		if existing.GetName() != restored.GetName() ||
+			existing.GetNamespace() != restored.GetNamespace() ||
+			existing.GetPartition() != restored.GetPartition() {
+			continue
+		}
+		if equalConfigEntries(existing, restored) {
+			return nil, nil
+		}
+		c.added = false
+		break
+	}
+	return c, nil
+}
+
+// applyChanges writes the changes, batching KV writes into transactions.
+func applyChanges(client *api.Client, changes []*change) error {
+	var ops api.TxnOps
+	flush := func() error {
+		if len(ops) == 0 {
+			return nil
+		}
+		ok, resp, _, err := client.Txn().Txn(ops, nil)
+		if err != nil {
+			return fmt.Errorf("failed to write keys: %v", err)
+		}
+		if !ok {
+			var errs []string
+			for _, e := range resp.Errors {
+				errs = append(errs, e.What)
+			}
+			return fmt.Errorf("failed to write keys: %s", strings.Join(errs, ", "))
+		}
+		ops = nil
+		return nil
+	}
+
+	for _, c := range changes {
+		if c.kv != nil {
+			ops = append(ops, &api.TxnOp{KV: &api.KVTxnOp{
+				Verb:      api.KVSet,
+				Key:       c.kv.Key,
+				Value:     c.kv.Value,
+				Flags:     c.kv.Flags,
+				Namespace: c.kv.Namespace,
+				Partition: c.kv.Partition,
+			}})
+			if len(ops) == maxKVTxnOps {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := c.apply(); err != nil {
+			return fmt.Errorf("failed to write %s %s: %v", c.recordType, c.name, err)
+		}
+	}
+	return flush()
+}
+
+// convert copies in to out through their JSON encoding.
+func convert(in, out interface{}) error {
+	raw, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// equalConfigEntries compares two config entries, ignoring their indexes.
+func equalConfigEntries(a, b api.ConfigEntry) bool {
+	normalize := func(entry api.ConfigEntry) map[string]interface{} {
+		var m map[string]interface{}
+		if err := convert(entry, &m); err != nil {
+			return nil
+		}
+		delete(m, "CreateIndex")
+		delete(m, "ModifyIndex")
+		return m
+	}
+	am, bm := normalize(a), normalize(b)
+	return am != nil && reflect.DeepEqual(am, bm)
+}
+
+// equalJSON reports whether a and b have the same JSON encoding.
+func equalJSON(a, b interface{}) bool {
+	ar, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	br, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(ar) == string(br)
+}
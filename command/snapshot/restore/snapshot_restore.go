@@ -4,12 +4,21 @@
 package restore
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 
-	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/go-hclog"
 	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/agent/consul/fsm"
+	"github.com/hashicorp/consul/agent/consul/state"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/command/flags"
+	raftstorage "github.com/hashicorp/consul/internal/storage/raft"
+	"github.com/hashicorp/consul/snapshot"
 )
 
 func New(ui cli.Ui) *cmd {
@@ -19,10 +28,14 @@ func New(ui cli.Ui) *cmd {
 }
 
 type cmd struct {
-	UI    cli.Ui
-	flags *flag.FlagSet
-	http  *flags.HTTPFlags
-	help  string
+	UI      cli.Ui
+	flags   *flag.FlagSet
+	http    *flags.HTTPFlags
+	help    string
+	keyfile string
+	only    string
+	prefix  string
+	dryRun  bool
 }
 
 func (c *cmd) init() {
@@ -30,6 +43,20 @@ func (c *cmd) init() {
 	c.http = &flags.HTTPFlags{}
 	flags.Merge(c.flags, c.http.ClientFlags())
 	flags.Merge(c.flags, c.http.ServerFlags())
+	c.flags.StringVar(&c.keyfile, "keyfile", "",
+		"Path to the key file used to decrypt encrypted snapshots. If not given, the "+
+			"passphrase in the "+snapshot.PassphraseEnvName+" environment variable is used.")
+	c.flags.StringVar(&c.only, "only", "",
+		"Comma-separated list of record types to restore instead of the whole snapshot. "+
+			"Supported types are \"kv\", \"acl-policies\", \"acl-roles\" and \"config-entries\". "+
+			"The records are written back through the API as normal writes, leaving the rest "+
+			"of the state of the servers untouched.")
+	c.flags.StringVar(&c.prefix, "prefix", "",
+		"Can only be used with -only. Limits the records restored to KV keys, or names of "+
+			"other records, that start with this prefix.")
+	c.flags.BoolVar(&c.dryRun, "dry-run", false,
+		"Can only be used with -only. Prints the records that would be restored without "+
+			"writing them.")
 	c.help = flags.Usage(help, c.flags)
 }
 
@@ -38,17 +65,28 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
-	var file string
-
 	args = c.flags.Args()
-	switch len(args) {
-	case 0:
+	if len(args) == 0 {
 		c.UI.Error("Missing FILE argument")
 		return 1
-	case 1:
-		file = args[0]
-	default:
-		c.UI.Error(fmt.Sprintf("Too many arguments (expected 1, got %d)", len(args)))
+	}
+	file, increments := args[0], args[1:]
+
+	var selected map[string]bool
+	if c.only != "" {
+		var err error
+		if selected, err = parseOnly(c.only); err != nil {
+			c.UI.Error(fmt.Sprintf("Invalid -only: %s", err))
+			return 1
+		}
+	} else if c.prefix != "" || c.dryRun {
+		c.UI.Error("The -prefix and -dry-run flags can only be used with -only")
+		return 1
+	}
+
+	key, err := snapshot.LoadEncryptionKey(c.keyfile, os.Getenv(snapshot.PassphraseEnvName))
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error loading encryption key: %s", err))
 		return 1
 	}
 
@@ -67,8 +105,36 @@ func (c *cmd) Run(args []string) int {
 	}
 	defer f.Close()
 
+	// Encrypted snapshots are decrypted and authenticated in full before
+	// anything is sent to the servers.
+	in, err := snapshot.MaybeDecrypt(hclog.NewNullLogger(), f, key)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error decrypting snapshot: %s", err))
+		return 1
+	}
+	defer in.Close()
+
+	// If we were given incremental snapshots, apply them on top of the base
+	// locally and restore the result.
+	if len(increments) > 0 {
+		merged, err := mergeSnapshots(in, increments, key)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error applying incremental snapshots: %s", err))
+			return 1
+		}
+		defer func() {
+			merged.Close()
+			os.Remove(merged.Name())
+		}()
+		in = merged
+	}
+
+	if selected != nil {
+		return c.restoreSelected(client, in, selected)
+	}
+
 	// Restore the snapshot.
-	err = client.Snapshot().Restore(nil, f)
+	err = client.Snapshot().Restore(nil, in)
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error restoring snapshot: %s", err))
 		return 1
@@ -78,6 +144,98 @@ func (c *cmd) Run(args []string) int {
 	return 0
 }
 
+// restoreSelected restores just the selected types of records from the
+// snapshot, or prints what would be restored for a dry run.
+func (c *cmd) restoreSelected(client *api.Client, in io.Reader, selected map[string]bool) int {
+	recs, err := readRecords(in, selected, c.prefix)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading snapshot: %s", err))
+		return 1
+	}
+	changes, unchanged, err := plan(client, recs, c.prefix)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error comparing snapshot with current state: %s", err))
+		return 1
+	}
+
+	var added int
+	for _, change := range changes {
+		c.UI.Output(change.String())
+		if change.added {
+			added++
+		}
+	}
+	summary := fmt.Sprintf("%d records (%d added, %d modified, %d unchanged)",
+		len(changes), added, len(changes)-added, unchanged)
+
+	if c.dryRun {
+		c.UI.Info("Dry run, would restore " + summary)
+		return 0
+	}
+	if err := applyChanges(client, changes); err != nil {
+		c.UI.Error(fmt.Sprintf("Error restoring records: %s", err))
+		return 1
+	}
+	c.UI.Info("Restored " + summary)
+	return 0
+}
+
+// mergeSnapshots verifies the chain of incremental snapshots at the given
+// paths, decrypting them with the given key if needed, and applies them on top
+// of the base snapshot, returning a temporary file holding the resulting full
+// snapshot. The caller is responsible for removing the file.
+func mergeSnapshots(base io.Reader, paths []string, key *snapshot.EncryptionKey) (*os.File, error) {
+	var increments []io.Reader
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		in, err := snapshot.MaybeDecrypt(hclog.NewNullLogger(), f, key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		defer in.Close()
+		increments = append(increments, in)
+	}
+
+	// The FSM only needs the storage backend for snapshots and restores, so
+	// it's safe to pass a nil handle.
+	backend, err := raftstorage.NewBackend(nil, hclog.NewNullLogger())
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go backend.Run(ctx)
+
+	tmpFSM := fsm.NewFromDeps(fsm.Deps{
+		Logger: hclog.NewNullLogger(),
+		NewStateStore: func() *state.Store {
+			return state.NewStateStore(nil)
+		},
+		StorageBackend: backend,
+	})
+
+	out, err := os.CreateTemp("", "snapshot")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := snapshot.Merge(hclog.NewNullLogger(), tmpFSM.ChunkingFSM(), base, increments, out); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return nil, err
+	}
+	if _, err := out.Seek(0, io.SeekStart); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *cmd) Synopsis() string {
 	return synopsis
 }
@@ -88,7 +246,7 @@ func (c *cmd) Help() string {
 
 const synopsis = "Restores snapshot of Consul server state"
 const help = `
-Usage: consul snapshot restore [options] FILE
+Usage: consul snapshot restore [options] FILE [INCREMENT...]
 
   Restores an atomic, point-in-time snapshot of the state of the Consul servers
   which includes key/value entries, service catalog, prepared queries, sessions,
@@ -106,5 +264,28 @@ Usage: consul snapshot restore [options] FILE
 
     $ consul snapshot restore backup.snap
 
+  To restore "backup.snap" along with a chain of incremental snapshots taken
+  after it with "consul snapshot save -base", list them in the order they were
+  taken. The chain is verified and applied locally before the result is
+  restored:
+
+    $ consul snapshot restore backup.snap backup-1.snap backup-2.snap
+
+  Snapshots saved with "consul snapshot save -encrypt" are decrypted with the
+  key file given by -keyfile, or the passphrase in the CONSUL_SNAPSHOT_PASSPHRASE
+  environment variable. They are authenticated in full before anything is
+  restored, and tampered snapshots are refused:
+
+    $ consul snapshot restore -keyfile snapshot.key backup.snap
+
+  To restore only some types of records, such as the KV entries under "app/"
+  after they were deleted by mistake, use -only. The selected records are
+  written back through the API, so the rest of the state, like the catalog and
+  ACL tokens, is not rolled back. Records that were created since the snapshot
+  was taken are left in place. Use -dry-run to see what would change first:
+
+    $ consul snapshot restore -only=kv -prefix=app/ -dry-run backup.snap
+    $ consul snapshot restore -only=kv -prefix=app/ backup.snap
+
   For a full list of options and examples, please see the Consul documentation.
 `
@@ -15,6 +15,8 @@ import (
 	"github.com/hashicorp/consul/agent"
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/hashicorp/consul/snapshot"
+	"github.com/hashicorp/consul/testrpc"
 	"github.com/mitchellh/cli"
 	"github.com/stretchr/testify/require"
 )
@@ -28,9 +30,6 @@ func TestSnapshotRestoreCommand_noTabs(t *testing.T) {
 
 func TestSnapshotRestoreCommand_Validation(t *testing.T) {
 	t.Parallel()
-	ui := cli.NewMockUi()
-	c := New(ui)
-
 	cases := map[string]struct {
 		args   []string
 		output string
@@ -39,20 +38,24 @@ func TestSnapshotRestoreCommand_Validation(t *testing.T) {
 			[]string{},
 			"Missing FILE argument",
 		},
-		"extra args": {
+		"missing file": {
 			[]string{"foo", "bar", "baz"},
-			"Too many arguments",
+			"Error opening snapshot file",
+		},
+		"prefix without only": {
+			[]string{"-prefix", "app/", "foo"},
+			"can only be used with -only",
+		},
+		"unsupported only": {
+			[]string{"-only", "kv,nodes", "foo"},
+			`unsupported record type "nodes"`,
 		},
 	}
 
 	for name, tc := range cases {
-		// Ensure our buffer is always clear
-		if ui.ErrorWriter != nil {
-			ui.ErrorWriter.Reset()
-		}
-		if ui.OutputWriter != nil {
-			ui.OutputWriter.Reset()
-		}
+		// Use a fresh command so flags don't carry over between cases.
+		ui := cli.NewMockUi()
+		c := New(ui)
 
 		code := c.Run(tc.args)
 		if code == 0 {
@@ -110,6 +113,79 @@ func TestSnapshotRestoreCommand(t *testing.T) {
 	}
 }
 
+func TestSnapshotRestoreCommand_Incremental(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	client := a.Client()
+	kv := client.KV()
+
+	dir := testutil.TempDir(t, "snapshot")
+
+	// save writes a snapshot to the named file, based on the given base file
+	// if there is one.
+	save := func(name, base string) string {
+		t.Helper()
+		var snap io.ReadCloser
+		var err error
+		if base == "" {
+			snap, _, err = client.Snapshot().Save(nil)
+		} else {
+			f, ferr := os.Open(base)
+			require.NoError(t, ferr)
+			info, ierr := snapshot.ReadInfo(f)
+			f.Close()
+			require.NoError(t, ierr)
+			snap, _, err = client.Snapshot().SaveIncremental(info.Meta.Index, info.Hash, nil)
+		}
+		require.NoError(t, err)
+		defer snap.Close()
+
+		file := filepath.Join(dir, name)
+		f, err := os.Create(file)
+		require.NoError(t, err)
+		defer f.Close()
+		_, err = io.Copy(f, snap)
+		require.NoError(t, err)
+		return file
+	}
+
+	_, err := kv.Put(&api.KVPair{Key: "a", Value: []byte("a")}, nil)
+	require.NoError(t, err)
+	full := save("full.snap", "")
+
+	_, err = kv.Put(&api.KVPair{Key: "b", Value: []byte("b")}, nil)
+	require.NoError(t, err)
+	inc1 := save("inc1.snap", full)
+
+	_, err = kv.Put(&api.KVPair{Key: "c", Value: []byte("c")}, nil)
+	require.NoError(t, err)
+	inc2 := save("inc2.snap", inc1)
+
+	_, err = kv.DeleteTree("", nil)
+	require.NoError(t, err)
+
+	// Applying the increments out of order fails the chain check.
+	ui := cli.NewMockUi()
+	c := New(ui)
+	code := c.Run([]string{"-http-addr=" + a.HTTPAddr(), full, inc2})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "incremental snapshot 1: base index")
+
+	ui = cli.NewMockUi()
+	c = New(ui)
+	code = c.Run([]string{"-http-addr=" + a.HTTPAddr(), full, inc1, inc2})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+	keys, _, err := kv.Keys("", "", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c"}, keys)
+}
+
 func TestSnapshotRestoreCommand_TruncatedSnapshot(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -167,3 +243,185 @@ func TestSnapshotRestoreCommand_TruncatedSnapshot(t *testing.T) {
 		})
 	}
 }
+
+func TestSnapshotRestoreCommand_Encrypted(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	client := a.Client()
+	kv := client.KV()
+
+	dir := testutil.TempDir(t, "snapshot")
+	file := filepath.Join(dir, "backup.tgz")
+	t.Setenv(snapshot.PassphraseEnvName, "")
+
+	_, err := kv.Put(&api.KVPair{Key: "a", Value: []byte("a")}, nil)
+	require.NoError(t, err)
+
+	snap, _, err := client.Snapshot().Save(nil)
+	require.NoError(t, err)
+	defer snap.Close()
+	key := &snapshot.EncryptionKey{Passphrase: "hunter2"}
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	require.NoError(t, snapshot.Encrypt(f, snap, key))
+	require.NoError(t, f.Close())
+
+	_, err = kv.Delete("a", nil)
+	require.NoError(t, err)
+
+	// The snapshot can't be restored without the passphrase.
+	ui := cli.NewMockUi()
+	code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), file})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "snapshot is encrypted")
+
+	// A tampered snapshot is refused.
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	data[len(data)-20] ^= 1
+	tampered := filepath.Join(dir, "tampered.tgz")
+	require.NoError(t, os.WriteFile(tampered, data, 0600))
+
+	t.Setenv(snapshot.PassphraseEnvName, key.Passphrase)
+	ui = cli.NewMockUi()
+	code = New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), tampered})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "has been tampered with")
+
+	ui = cli.NewMockUi()
+	code = New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), file})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+	pair, _, err := kv.Get("a", nil)
+	require.NoError(t, err)
+	require.NotNil(t, pair)
+}
+
+func TestSnapshotRestoreCommand_Selective(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	client, err := api.NewClient(&api.Config{Address: a.HTTPAddr(), Token: "root"})
+	require.NoError(t, err)
+	kv, acl, entries := client.KV(), client.ACL(), client.ConfigEntries()
+
+	put := func(key, value string) {
+		t.Helper()
+		_, err := kv.Put(&api.KVPair{Key: key, Value: []byte(value)}, nil)
+		require.NoError(t, err)
+	}
+	get := func(key string) string {
+		t.Helper()
+		pair, _, err := kv.Get(key, nil)
+		require.NoError(t, err)
+		if pair == nil {
+			return ""
+		}
+		return string(pair.Value)
+	}
+	run := func(args ...string) *cli.MockUi {
+		t.Helper()
+		ui := cli.NewMockUi()
+		code := New(ui).Run(append([]string{"-http-addr=" + a.HTTPAddr(), "-token=root"}, args...))
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+		return ui
+	}
+
+	put("app/a", "1")
+	put("app/b", "2")
+	put("other/c", "3")
+	policy, _, err := acl.PolicyCreate(&api.ACLPolicy{
+		Name:  "app",
+		Rules: `key_prefix "app/" { policy = "read" }`,
+	}, nil)
+	require.NoError(t, err)
+	role, _, err := acl.RoleCreate(&api.ACLRole{
+		Name:     "app",
+		Policies: []*api.ACLRolePolicyLink{{ID: policy.ID}},
+	}, nil)
+	require.NoError(t, err)
+	_, _, err = entries.Set(&api.ServiceConfigEntry{Kind: api.ServiceDefaults, Name: "web", Protocol: "http"}, nil)
+	require.NoError(t, err)
+
+	snap, _, err := client.Snapshot().Save(nil)
+	require.NoError(t, err)
+	defer snap.Close()
+	file := filepath.Join(testutil.TempDir(t, "snapshot"), "backup.tgz")
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	_, err = io.Copy(f, snap)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// Make a mess.
+	_, err = kv.Delete("app/a", nil)
+	require.NoError(t, err)
+	put("app/b", "changed")
+	put("app/new", "new")
+	_, err = kv.Delete("other/c", nil)
+	require.NoError(t, err)
+	_, err = acl.RoleDelete(role.ID, nil)
+	require.NoError(t, err)
+	_, err = acl.PolicyDelete(policy.ID, nil)
+	require.NoError(t, err)
+	_, _, err = entries.Set(&api.ServiceConfigEntry{Kind: api.ServiceDefaults, Name: "web", Protocol: "grpc"}, nil)
+	require.NoError(t, err)
+
+	// A dry run reports the changes without making them.
+	ui := run("-only=kv", "-prefix=app/", "-dry-run", file)
+	output := ui.OutputWriter.String()
+	require.Contains(t, output, "+ kv app/a")
+	require.Contains(t, output, "~ kv app/b")
+	require.NotContains(t, output, "app/new")
+	require.Contains(t, output, "Dry run, would restore 2 records (1 added, 1 modified, 0 unchanged)")
+	require.Equal(t, "", get("app/a"))
+
+	// Only the selected keys are restored, and newer keys are left alone.
+	ui = run("-only=kv", "-prefix=app/", file)
+	require.Contains(t, ui.OutputWriter.String(), "Restored 2 records (1 added, 1 modified, 0 unchanged)")
+	require.Equal(t, "1", get("app/a"))
+	require.Equal(t, "2", get("app/b"))
+	require.Equal(t, "new", get("app/new"))
+	require.Equal(t, "", get("other/c"))
+
+	ui = run("-only=acl-policies,acl-roles,config-entries", "-prefix=app", file)
+	output = ui.OutputWriter.String()
+	require.Contains(t, output, "+ acl-policies app")
+	require.Contains(t, output, "+ acl-roles app")
+	require.NotContains(t, output, "web")
+
+	ui = run("-only=config-entries", file)
+	require.Contains(t, ui.OutputWriter.String(), "~ config-entries service-defaults/web")
+
+	restoredPolicy, _, err := acl.PolicyReadByName("app", nil)
+	require.NoError(t, err)
+	require.Equal(t, policy.Rules, restoredPolicy.Rules)
+	restoredRole, _, err := acl.RoleReadByName("app", nil)
+	require.NoError(t, err)
+	require.Len(t, restoredRole.Policies, 1)
+	require.Equal(t, restoredPolicy.ID, restoredRole.Policies[0].ID)
+	entry, _, err := entries.Get(api.ServiceDefaults, "web", nil)
+	require.NoError(t, err)
+	require.Equal(t, "http", entry.(*api.ServiceConfigEntry).Protocol)
+
+	// Once everything is restored there's nothing left to change.
+	ui = run("-only=kv,acl-policies,acl-roles,config-entries", "-prefix=app", file)
+	require.Contains(t, ui.OutputWriter.String(), "Restored 0 records (0 added, 0 modified, 4 unchanged)")
+}
@@ -7,10 +7,12 @@ import (
 	"flag"
 	"fmt"
 	"golang.org/x/exp/slices"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/hashicorp/go-hclog"
 	"github.com/mitchellh/cli"
 	"github.com/rboyer/safeio"
 
@@ -31,6 +33,9 @@ type cmd struct {
 	http               *flags.HTTPFlags
 	help               string
 	appendFileNameFlag flags.StringValue
+	base               string
+	encrypt            bool
+	keyfile            string
 }
 
 func (c *cmd) getAppendFileNameFlag() *flag.FlagSet {
@@ -46,6 +51,16 @@ func (c *cmd) init() {
 	flags.Merge(c.flags, c.http.ClientFlags())
 	flags.Merge(c.flags, c.http.ServerFlags())
 	flags.Merge(c.flags, c.getAppendFileNameFlag())
+	c.flags.StringVar(&c.base, "base", "",
+		"Path to a previous snapshot to use as the base of an incremental snapshot. "+
+			"The new snapshot holds only the changes made since the base, which can be "+
+			"a full snapshot or another incremental snapshot.")
+	c.flags.BoolVar(&c.encrypt, "encrypt", false,
+		"Encrypt the snapshot with the key in -keyfile, or with a key derived from the "+
+			"passphrase in the "+snapshot.PassphraseEnvName+" environment variable.")
+	c.flags.StringVar(&c.keyfile, "keyfile", "",
+		"Path to a file holding a base64-encoded 32 byte key, such as the output of "+
+			"\"consul keygen\", used to encrypt the snapshot and to decrypt the -base snapshot.")
 	c.help = flags.Usage(help, c.flags)
 }
 
@@ -68,6 +83,16 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
+	key, err := snapshot.LoadEncryptionKey(c.keyfile, os.Getenv(snapshot.PassphraseEnvName))
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error loading encryption key: %s", err))
+		return 1
+	}
+	if c.encrypt && key == nil {
+		c.UI.Error(fmt.Sprintf("The -encrypt flag requires -keyfile or the %s environment variable", snapshot.PassphraseEnvName))
+		return 1
+	}
+
 	// Create and test the HTTP client
 	client, err := c.http.APIClient()
 
@@ -116,10 +141,22 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
-	// Take the snapshot.
-	snap, qm, err := client.Snapshot().Save(&api.QueryOptions{
+	// Take the snapshot, or an incremental one if we were given a base.
+	var snap io.ReadCloser
+	var qm *api.QueryMeta
+	qOpts := &api.QueryOptions{
 		AllowStale: c.http.Stale(),
-	})
+	}
+	if c.base != "" {
+		info, err := readBaseInfo(c.base, key)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading base snapshot: %s", err))
+			return 1
+		}
+		snap, qm, err = client.Snapshot().SaveIncremental(info.Meta.Index, info.Hash, qOpts)
+	} else {
+		snap, qm, err = client.Snapshot().Save(qOpts)
+	}
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error saving snapshot: %s", err))
 		return 1
@@ -140,7 +177,7 @@ func (c *cmd) Run(args []string) int {
 		c.UI.Error(fmt.Sprintf("Error opening snapshot file for verify: %s", err))
 		return 1
 	}
-	if _, err := snapshot.Verify(f); err != nil {
+	if _, err := snapshot.ReadInfo(f); err != nil {
 		f.Close()
 		c.UI.Error(fmt.Sprintf("Error verifying snapshot file: %s", err))
 		return 1
@@ -150,15 +187,66 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
-	if err := safeio.Rename(unverifiedFile, file); err != nil {
+	if c.encrypt {
+		if err := encryptFile(unverifiedFile, file, key); err != nil {
+			c.UI.Error(fmt.Sprintf("Error encrypting snapshot file: %s", err))
+			return 1
+		}
+	} else if err := safeio.Rename(unverifiedFile, file); err != nil {
 		c.UI.Error(fmt.Sprintf("Error renaming %q to %q: %v", unverifiedFile, file, err))
 		return 1
 	}
 
-	c.UI.Info(fmt.Sprintf("Saved and verified snapshot to index %d", qm.LastIndex))
+	kind := "snapshot"
+	if c.base != "" {
+		kind = "incremental snapshot"
+	}
+	if c.encrypt {
+		kind = "encrypted " + kind
+	}
+	c.UI.Info(fmt.Sprintf("Saved and verified %s to index %d", kind, qm.LastIndex))
 	return 0
 }
 
+// readBaseInfo verifies the snapshot at the given path, decrypting it with the
+// given key if needed, and returns the index and hash that identify it as a
+// base.
+func readBaseInfo(path string, key *snapshot.EncryptionKey) (*snapshot.Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	in, err := snapshot.MaybeDecrypt(hclog.NewNullLogger(), f, key)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	return snapshot.ReadInfo(in)
+}
+
+// encryptFile encrypts the snapshot in the src file with the given key and
+// atomically writes it to dst.
+func encryptFile(src, dst string, key *snapshot.EncryptionKey) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := safeio.OpenFile(dst, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if err := snapshot.Encrypt(out, in, key); err != nil {
+		return err
+	}
+	return out.Commit()
+}
+
 func (c *cmd) Synopsis() string {
 	return synopsis
 }
@@ -187,5 +275,23 @@ Usage: consul snapshot save [options] FILE
 
     $ consul snapshot save -stale backup.snap
 
+  To create an incremental snapshot holding only the changes made since
+  "backup.snap":
+
+    $ consul snapshot save -base backup.snap backup-1.snap
+
+  Incremental snapshots hold the Raft log entries since their base, so the base
+  must be recent enough that the servers still have those entries.
+
+  Snapshots hold sensitive data such as ACL tokens and CA private keys. To save
+  a snapshot encrypted with a key generated by "consul keygen":
+
+    $ consul keygen > snapshot.key
+    $ consul snapshot save -encrypt -keyfile snapshot.key backup.snap
+
+  The key can also be derived from a passphrase, which is read from the
+  CONSUL_SNAPSHOT_PASSPHRASE environment variable. The other snapshot commands
+  decrypt snapshots given the same key file or passphrase.
+
   For a full list of options and examples, please see the Consul documentation.
 `
@@ -5,6 +5,7 @@ package save
 
 import (
 	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"net/http"
@@ -22,6 +23,7 @@ import (
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/sdk/testutil"
 	"github.com/hashicorp/consul/sdk/testutil/retry"
+	"github.com/hashicorp/consul/snapshot"
 )
 
 func TestSnapshotSaveCommand_noTabs(t *testing.T) {
@@ -177,6 +179,46 @@ func TestSnapshotSaveCommand(t *testing.T) {
 	}
 }
 
+func TestSnapshotSaveCommand_Incremental(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+
+	dir := testutil.TempDir(t, "snapshot")
+	base := filepath.Join(dir, "backup.tgz")
+	file := filepath.Join(dir, "backup-1.tgz")
+
+	ui := cli.NewMockUi()
+	code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), base})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+	ui = cli.NewMockUi()
+	code = New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "-base", base, file})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	require.Contains(t, ui.OutputWriter.String(), "Saved and verified incremental snapshot")
+
+	readInfo := func(path string) *snapshot.Info {
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		info, err := snapshot.ReadInfo(f)
+		require.NoError(t, err)
+		return info
+	}
+	baseInfo, info := readInfo(base), readInfo(file)
+	require.Equal(t, &snapshot.IncrementalMeta{BaseIndex: baseInfo.Meta.Index, BaseHash: baseInfo.Hash}, info.Incremental)
+
+	// A missing base is reported before anything is saved.
+	ui = cli.NewMockUi()
+	code = New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "-base", filepath.Join(dir, "nope"), file})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "Error reading base snapshot")
+}
+
 func TestSnapshotSaveCommand_TruncatedStream(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -277,3 +319,55 @@ func TestSnapshotSaveCommand_TruncatedStream(t *testing.T) {
 		})
 	}
 }
+
+func TestSnapshotSaveCommand_Encrypted(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+
+	dir := testutil.TempDir(t, "snapshot")
+	file := filepath.Join(dir, "backup.tgz")
+
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	keyfile := filepath.Join(dir, "snapshot.key")
+	require.NoError(t, os.WriteFile(keyfile, []byte(base64.StdEncoding.EncodeToString(key)), 0600))
+
+	// A key is required to encrypt.
+	ui := cli.NewMockUi()
+	code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "-encrypt", file})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "The -encrypt flag requires -keyfile")
+
+	ui = cli.NewMockUi()
+	code = New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "-encrypt", "-keyfile", keyfile, file})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	require.Contains(t, ui.OutputWriter.String(), "Saved and verified encrypted snapshot")
+
+	// The file can't be read as a plain snapshot.
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = snapshot.Verify(f)
+	require.Error(t, err)
+
+	// But it can be once it's decrypted.
+	_, err = f.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	in, err := snapshot.MaybeDecrypt(testutil.Logger(t), f, &snapshot.EncryptionKey{Key: key})
+	require.NoError(t, err)
+	defer in.Close()
+	_, err = snapshot.Verify(in)
+	require.NoError(t, err)
+
+	// An encrypted snapshot can be used as the base of an incremental one.
+	ui = cli.NewMockUi()
+	code = New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "-keyfile", keyfile, "-base", file, filepath.Join(dir, "backup-1.tgz")})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	require.Contains(t, ui.OutputWriter.String(), "Saved and verified incremental snapshot")
+}
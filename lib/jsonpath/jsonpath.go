@@ -0,0 +1,113 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+// Package jsonpath implements the subset of JSONPath needed to select a single
+// value in a JSON document: the root $ followed by child members, written
+// .name or ['name'], and array indexes, written [0] or [-1] to count from the
+// end of the array.
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment selects either a member of an object or an element of an array.
+type segment struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+// Path is a parsed JSONPath expression.
+type Path struct {
+	expr     string
+	segments []segment
+}
+
+// Parse parses a JSONPath expression.
+func Parse(expr string) (*Path, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(expr), "$")
+	if !ok {
+		return nil, fmt.Errorf("invalid JSONPath %q: must start with $", expr)
+	}
+
+	p := &Path{expr: expr}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: empty member name", expr)
+			}
+			p.segments = append(p.segments, segment{name: rest[:end]})
+			rest = rest[end:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid JSONPath %q: missing ]", expr)
+			}
+			selector := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+				p.segments = append(p.segments, segment{name: selector[1 : len(selector)-1]})
+				continue
+			}
+			index, err := strconv.Atoi(selector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSONPath %q: unsupported selector [%s]", expr, selector)
+			}
+			p.segments = append(p.segments, segment{index: index, isIndex: true})
+
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q: unexpected %q", expr, rest[0])
+		}
+	}
+	return p, nil
+}
+
+// String returns the expression the path was parsed from.
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Find returns the value selected by the path in doc, a JSON document decoded
+// with encoding/json into an interface{}. It returns false when the path does
+// not select anything.
+func (p *Path) Find(doc interface{}) (interface{}, bool) {
+	current := doc
+	for _, s := range p.segments {
+		if s.isIndex {
+			array, ok := current.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			index := s.index
+			if index < 0 {
+				index += len(array)
+			}
+			if index < 0 || index >= len(array) {
+				return nil, false
+			}
+			current = array[index]
+			continue
+		}
+
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = object[s.name]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,67 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPath_Find(t *testing.T) {
+	var doc interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"status": "ok",
+		"checks": [
+			{"name": "db", "healthy": true},
+			{"name": "cache", "healthy": false}
+		],
+		"dotted.key": 42
+	}`), &doc))
+
+	cases := map[string]struct {
+		expr     string
+		expected interface{}
+		found    bool
+	}{
+		"root":             {expr: "$", expected: doc, found: true},
+		"member":           {expr: "$.status", expected: "ok", found: true},
+		"index":            {expr: "$.checks[1].name", expected: "cache", found: true},
+		"negative index":   {expr: "$.checks[-1].healthy", expected: false, found: true},
+		"bracket member":   {expr: "$['dotted.key']", expected: float64(42), found: true},
+		"double quoted":    {expr: `$.checks[0]["healthy"]`, expected: true, found: true},
+		"missing member":   {expr: "$.missing"},
+		"out of range":     {expr: "$.checks[2]"},
+		"index an object":  {expr: "$.status[0]"},
+		"member of string": {expr: "$.status.value"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p, err := Parse(tc.expr)
+			require.NoError(t, err)
+			value, found := p.Find(doc)
+			require.Equal(t, tc.found, found)
+			require.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"status",
+		"$.",
+		"$..status",
+		"$[0",
+		"$[*]",
+		"$.checks[?(@.healthy)]",
+		"$status",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Parse(expr)
+			require.Error(t, err)
+		})
+	}
+}
@@ -2295,6 +2295,7 @@ func ServiceDefaultsToStructs(s *ServiceDefaults, t *structs.ServiceConfigEntry)
 		t.RateLimits = &x
 	}
 	t.EnvoyExtensions = EnvoyExtensionsToStructs(s.EnvoyExtensions)
+	t.DNSAnswerOrder = s.DNSAnswerOrder
 	t.Meta = s.Meta
 	t.Hash = s.Hash
 	t.MaxRequestHeadersKB = s.MaxRequestHeadersKB
@@ -2342,6 +2343,7 @@ func ServiceDefaultsFromStructs(t *structs.ServiceConfigEntry, s *ServiceDefault
 		s.RateLimits = &x
 	}
 	s.EnvoyExtensions = EnvoyExtensionsFromStructs(t.EnvoyExtensions)
+	s.DNSAnswerOrder = t.DNSAnswerOrder
 	s.Meta = t.Meta
 	s.Hash = t.Hash
 	s.MaxRequestHeadersKB = t.MaxRequestHeadersKB
@@ -4093,6 +4093,7 @@ type ServiceDefaults struct {
 	MutualTLSMode       MutualTLSMode `protobuf:"varint,15,opt,name=MutualTLSMode,proto3,enum=hashicorp.consul.internal.configentry.MutualTLSMode" json:"MutualTLSMode,omitempty"`
 	Hash                uint64        `protobuf:"varint,17,opt,name=Hash,proto3" json:"Hash,omitempty"`
 	MaxRequestHeadersKB *uint32       `protobuf:"varint,18,opt,name=MaxRequestHeadersKB,proto3,oneof" json:"MaxRequestHeadersKB,omitempty"`
+	DNSAnswerOrder      string        `protobuf:"bytes,19,opt,name=DNSAnswerOrder,proto3" json:"DNSAnswerOrder,omitempty"`
 	unknownFields       protoimpl.UnknownFields
 	sizeCache           protoimpl.SizeCache
 }
@@ -4253,6 +4254,13 @@ func (x *ServiceDefaults) GetMaxRequestHeadersKB() uint32 {
 	return 0
 }
 
+func (x *ServiceDefaults) GetDNSAnswerOrder() string {
+	if x != nil {
+		return x.DNSAnswerOrder
+	}
+	return ""
+}
+
 // mog annotation:
 //
 // target=github.com/hashicorp/consul/agent/structs.TransparentProxyConfig
@@ -9297,7 +9305,7 @@ const file_private_pbconfigentry_config_entry_proto_rawDesc = "" +
 	"\bContains\x18\b \x01(\tR\bContains\x12\x1e\n" +
 	"\n" +
 	"IgnoreCase\x18\t \x01(\bR\n" +
-	"IgnoreCase\"\xc8\n" +
+	"IgnoreCase\"\xf0\n" +
 	"\n" +
 	"\x0fServiceDefaults\x12\x1a\n" +
 	"\bProtocol\x18\x01 \x01(\tR\bProtocol\x12D\n" +
@@ -9320,7 +9328,8 @@ const file_private_pbconfigentry_config_entry_proto_rawDesc = "" +
 	"\x0fEnvoyExtensions\x18\x0e \x03(\v20.hashicorp.consul.internal.common.EnvoyExtensionR\x0fEnvoyExtensions\x12Z\n" +
 	"\rMutualTLSMode\x18\x0f \x01(\x0e24.hashicorp.consul.internal.configentry.MutualTLSModeR\rMutualTLSMode\x12\x12\n" +
 	"\x04Hash\x18\x11 \x01(\x04R\x04Hash\x125\n" +
-	"\x13MaxRequestHeadersKB\x18\x12 \x01(\rH\x00R\x13MaxRequestHeadersKB\x88\x01\x01\x1a7\n" +
+	"\x13MaxRequestHeadersKB\x18\x12 \x01(\rH\x00R\x13MaxRequestHeadersKB\x88\x01\x01\x12&\n" +
+	"\x0eDNSAnswerOrder\x18\x13 \x01(\tR\x0eDNSAnswerOrder\x1a7\n" +
 	"\tMetaEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x16\n" +
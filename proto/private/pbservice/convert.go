@@ -52,6 +52,55 @@ func NewMapHeadersFromStructs(t map[string][]string) map[string]*HeaderValue {
 	return s
 }
 
+// TODO: handle this with mog
+func CheckIDsToStructs(s []string) []types.CheckID {
+	if len(s) == 0 {
+		return nil
+	}
+	t := make([]types.CheckID, len(s))
+	for i, v := range s {
+		t[i] = types.CheckID(v)
+	}
+	return t
+}
+
+// TODO: handle this with mog
+func NewCheckIDsFromStructs(t []types.CheckID) []string {
+	if len(t) == 0 {
+		return nil
+	}
+	s := make([]string, len(t))
+	for i, v := range t {
+		s[i] = string(v)
+	}
+	return s
+}
+
+// TODO: handle this with mog
+func HTTPCheckAssertionsToStructs(s []*HTTPCheckAssertion) []structs.HTTPCheckAssertion {
+	if len(s) == 0 {
+		return nil
+	}
+	t := make([]structs.HTTPCheckAssertion, len(s))
+	for i, v := range s {
+		HTTPCheckAssertionToStructs(v, &t[i])
+	}
+	return t
+}
+
+// TODO: handle this with mog
+func NewHTTPCheckAssertionsFromStructs(t []structs.HTTPCheckAssertion) []*HTTPCheckAssertion {
+	if len(t) == 0 {
+		return nil
+	}
+	s := make([]*HTTPCheckAssertion, len(t))
+	for i := range t {
+		s[i] = new(HTTPCheckAssertion)
+		HTTPCheckAssertionFromStructs(&t[i], s[i])
+	}
+	return s
+}
+
 // TODO: use mog once it supports pointers and slices
 func CheckServiceNodeToStructs(s *CheckServiceNode) (*structs.CheckServiceNode, error) {
 	if s == nil {
@@ -20,9 +20,19 @@ func CheckTypeToStructs(s *CheckType, t *structs.CheckType) {
 	t.Method = s.Method
 	t.Body = s.Body
 	t.DisableRedirects = s.DisableRedirects
+	t.HTTPAssertions = HTTPCheckAssertionsToStructs(s.HTTPAssertions)
 	t.TCP = s.TCP
 	t.TCPUseTLS = s.TCPUseTLS
 	t.UDP = s.UDP
+	t.TLS = s.TLS
+	t.TLSExpiryWarningDays = int(s.TLSExpiryWarningDays)
+	t.TLSExpiryCriticalDays = int(s.TLSExpiryCriticalDays)
+	t.DNS = s.DNS
+	t.DNSQueryType = s.DNSQueryType
+	t.DNSResolver = s.DNSResolver
+	t.DNSExpectedValues = s.DNSExpectedValues
+	t.DNSRcode = s.DNSRcode
+	t.DNSMaxLatency = structs.DurationFromProto(s.DNSMaxLatency)
 	t.Interval = structs.DurationFromProto(s.Interval)
 	t.AliasNode = s.AliasNode
 	t.AliasService = s.AliasService
@@ -38,6 +48,7 @@ func CheckTypeToStructs(s *CheckType, t *structs.CheckType) {
 	t.SuccessBeforePassing = int(s.SuccessBeforePassing)
 	t.FailuresBeforeWarning = int(s.FailuresBeforeWarning)
 	t.FailuresBeforeCritical = int(s.FailuresBeforeCritical)
+	t.DependsOn = CheckIDsToStructs(s.DependsOn)
 	t.ProxyHTTP = s.ProxyHTTP
 	t.ProxyGRPC = s.ProxyGRPC
 	t.DeregisterCriticalServiceAfter = structs.DurationFromProto(s.DeregisterCriticalServiceAfter)
@@ -59,9 +70,19 @@ func CheckTypeFromStructs(t *structs.CheckType, s *CheckType) {
 	s.Method = t.Method
 	s.Body = t.Body
 	s.DisableRedirects = t.DisableRedirects
+	s.HTTPAssertions = NewHTTPCheckAssertionsFromStructs(t.HTTPAssertions)
 	s.TCP = t.TCP
 	s.TCPUseTLS = t.TCPUseTLS
 	s.UDP = t.UDP
+	s.TLS = t.TLS
+	s.TLSExpiryWarningDays = int32(t.TLSExpiryWarningDays)
+	s.TLSExpiryCriticalDays = int32(t.TLSExpiryCriticalDays)
+	s.DNS = t.DNS
+	s.DNSQueryType = t.DNSQueryType
+	s.DNSResolver = t.DNSResolver
+	s.DNSExpectedValues = t.DNSExpectedValues
+	s.DNSRcode = t.DNSRcode
+	s.DNSMaxLatency = structs.DurationToProto(t.DNSMaxLatency)
 	s.Interval = structs.DurationToProto(t.Interval)
 	s.AliasNode = t.AliasNode
 	s.AliasService = t.AliasService
@@ -77,11 +98,34 @@ func CheckTypeFromStructs(t *structs.CheckType, s *CheckType) {
 	s.SuccessBeforePassing = int32(t.SuccessBeforePassing)
 	s.FailuresBeforeWarning = int32(t.FailuresBeforeWarning)
 	s.FailuresBeforeCritical = int32(t.FailuresBeforeCritical)
+	s.DependsOn = NewCheckIDsFromStructs(t.DependsOn)
 	s.ProxyHTTP = t.ProxyHTTP
 	s.ProxyGRPC = t.ProxyGRPC
 	s.DeregisterCriticalServiceAfter = structs.DurationToProto(t.DeregisterCriticalServiceAfter)
 	s.OutputMaxSize = int32(t.OutputMaxSize)
 }
+func HTTPCheckAssertionToStructs(s *HTTPCheckAssertion, t *structs.HTTPCheckAssertion) {
+	if s == nil {
+		return
+	}
+	t.Body = s.Body
+	t.JSONPath = s.JSONPath
+	t.Header = s.Header
+	t.Value = s.Value
+	t.MaxLatency = structs.DurationFromProto(s.MaxLatency)
+	t.Status = s.Status
+}
+func HTTPCheckAssertionFromStructs(t *structs.HTTPCheckAssertion, s *HTTPCheckAssertion) {
+	if s == nil {
+		return
+	}
+	s.Body = t.Body
+	s.JSONPath = t.JSONPath
+	s.Header = t.Header
+	s.Value = t.Value
+	s.MaxLatency = structs.DurationToProto(t.MaxLatency)
+	s.Status = t.Status
+}
 func HealthCheckToStructs(s *HealthCheck, t *structs.HealthCheck) {
 	if s == nil {
 		return
@@ -98,6 +142,9 @@ func HealthCheckToStructs(s *HealthCheck, t *structs.HealthCheck) {
 	t.Type = s.Type
 	t.Interval = s.Interval
 	t.Timeout = s.Timeout
+	t.DependsOn = CheckIDsToStructs(s.DependsOn)
+	t.SuppressedBy = CheckIDType(s.SuppressedBy)
+	t.Flapping = s.Flapping
 	t.ExposedPort = int(s.ExposedPort)
 	t.PeerName = s.PeerName
 	if s.Definition != nil {
@@ -122,6 +169,9 @@ func HealthCheckFromStructs(t *structs.HealthCheck, s *HealthCheck) {
 	s.Type = t.Type
 	s.Interval = t.Interval
 	s.Timeout = t.Timeout
+	s.DependsOn = NewCheckIDsFromStructs(t.DependsOn)
+	s.SuppressedBy = string(t.SuppressedBy)
+	s.Flapping = t.Flapping
 	s.ExposedPort = int32(t.ExposedPort)
 	s.PeerName = t.PeerName
 	{
@@ -52,10 +52,15 @@ type HealthCheck struct {
 	// mog: func-to=EnterpriseMetaToStructs func-from=NewEnterpriseMetaFromStructs
 	EnterpriseMeta *pbcommon.EnterpriseMeta `protobuf:"bytes,13,opt,name=EnterpriseMeta,proto3" json:"EnterpriseMeta,omitempty"`
 	// mog: func-to=int func-from=int32
-	ExposedPort   int32  `protobuf:"varint,14,opt,name=ExposedPort,proto3" json:"ExposedPort,omitempty"`
-	Interval      string `protobuf:"bytes,15,opt,name=Interval,proto3" json:"Interval,omitempty"`
-	Timeout       string `protobuf:"bytes,16,opt,name=Timeout,proto3" json:"Timeout,omitempty"`
-	PeerName      string `protobuf:"bytes,17,opt,name=PeerName,proto3" json:"PeerName,omitempty"`
+	ExposedPort int32  `protobuf:"varint,14,opt,name=ExposedPort,proto3" json:"ExposedPort,omitempty"`
+	Interval    string `protobuf:"bytes,15,opt,name=Interval,proto3" json:"Interval,omitempty"`
+	Timeout     string `protobuf:"bytes,16,opt,name=Timeout,proto3" json:"Timeout,omitempty"`
+	PeerName    string `protobuf:"bytes,17,opt,name=PeerName,proto3" json:"PeerName,omitempty"`
+	// mog: func-to=CheckIDsToStructs func-from=NewCheckIDsFromStructs
+	DependsOn []string `protobuf:"bytes,18,rep,name=DependsOn,proto3" json:"DependsOn,omitempty"`
+	// mog: func-to=CheckIDType func-from=string
+	SuppressedBy  string `protobuf:"bytes,19,opt,name=SuppressedBy,proto3" json:"SuppressedBy,omitempty"`
+	Flapping      bool   `protobuf:"varint,20,opt,name=Flapping,proto3" json:"Flapping,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -209,6 +214,27 @@ func (x *HealthCheck) GetPeerName() string {
 	return ""
 }
 
+func (x *HealthCheck) GetDependsOn() []string {
+	if x != nil {
+		return x.DependsOn
+	}
+	return nil
+}
+
+func (x *HealthCheck) GetSuppressedBy() string {
+	if x != nil {
+		return x.SuppressedBy
+	}
+	return ""
+}
+
+func (x *HealthCheck) GetFlapping() bool {
+	if x != nil {
+		return x.Flapping
+	}
+	return false
+}
+
 type HeaderValue struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Value         []string               `protobuf:"bytes,1,rep,name=Value,proto3" json:"Value,omitempty"`
@@ -536,10 +562,24 @@ type CheckType struct {
 	Method           string                  `protobuf:"bytes,7,opt,name=Method,proto3" json:"Method,omitempty"`
 	Body             string                  `protobuf:"bytes,26,opt,name=Body,proto3" json:"Body,omitempty"`
 	DisableRedirects bool                    `protobuf:"varint,31,opt,name=DisableRedirects,proto3" json:"DisableRedirects,omitempty"`
-	TCP              string                  `protobuf:"bytes,8,opt,name=TCP,proto3" json:"TCP,omitempty"`
-	TCPUseTLS        bool                    `protobuf:"varint,34,opt,name=TCPUseTLS,proto3" json:"TCPUseTLS,omitempty"`
-	UDP              string                  `protobuf:"bytes,32,opt,name=UDP,proto3" json:"UDP,omitempty"`
-	OSService        string                  `protobuf:"bytes,33,opt,name=OSService,proto3" json:"OSService,omitempty"`
+	// mog: func-to=HTTPCheckAssertionsToStructs func-from=NewHTTPCheckAssertionsFromStructs
+	HTTPAssertions []*HTTPCheckAssertion `protobuf:"bytes,36,rep,name=HTTPAssertions,proto3" json:"HTTPAssertions,omitempty"`
+	TCP            string                `protobuf:"bytes,8,opt,name=TCP,proto3" json:"TCP,omitempty"`
+	TCPUseTLS      bool                  `protobuf:"varint,34,opt,name=TCPUseTLS,proto3" json:"TCPUseTLS,omitempty"`
+	UDP            string                `protobuf:"bytes,32,opt,name=UDP,proto3" json:"UDP,omitempty"`
+	OSService      string                `protobuf:"bytes,33,opt,name=OSService,proto3" json:"OSService,omitempty"`
+	TLS            string                `protobuf:"bytes,37,opt,name=TLS,proto3" json:"TLS,omitempty"`
+	// mog: func-to=int func-from=int32
+	TLSExpiryWarningDays int32 `protobuf:"varint,38,opt,name=TLSExpiryWarningDays,proto3" json:"TLSExpiryWarningDays,omitempty"`
+	// mog: func-to=int func-from=int32
+	TLSExpiryCriticalDays int32    `protobuf:"varint,39,opt,name=TLSExpiryCriticalDays,proto3" json:"TLSExpiryCriticalDays,omitempty"`
+	DNS                   string   `protobuf:"bytes,40,opt,name=DNS,proto3" json:"DNS,omitempty"`
+	DNSQueryType          string   `protobuf:"bytes,41,opt,name=DNSQueryType,proto3" json:"DNSQueryType,omitempty"`
+	DNSResolver           string   `protobuf:"bytes,42,opt,name=DNSResolver,proto3" json:"DNSResolver,omitempty"`
+	DNSExpectedValues     []string `protobuf:"bytes,43,rep,name=DNSExpectedValues,proto3" json:"DNSExpectedValues,omitempty"`
+	DNSRcode              string   `protobuf:"bytes,44,opt,name=DNSRcode,proto3" json:"DNSRcode,omitempty"`
+	// mog: func-to=structs.DurationFromProto func-from=structs.DurationToProto
+	DNSMaxLatency *durationpb.Duration `protobuf:"bytes,45,opt,name=DNSMaxLatency,proto3" json:"DNSMaxLatency,omitempty"`
 	// mog: func-to=structs.DurationFromProto func-from=structs.DurationToProto
 	Interval          *durationpb.Duration `protobuf:"bytes,9,opt,name=Interval,proto3" json:"Interval,omitempty"`
 	AliasNode         string               `protobuf:"bytes,10,opt,name=AliasNode,proto3" json:"AliasNode,omitempty"`
@@ -562,6 +602,8 @@ type CheckType struct {
 	FailuresBeforeWarning int32 `protobuf:"varint,29,opt,name=FailuresBeforeWarning,proto3" json:"FailuresBeforeWarning,omitempty"`
 	// mog: func-to=int func-from=int32
 	FailuresBeforeCritical int32 `protobuf:"varint,22,opt,name=FailuresBeforeCritical,proto3" json:"FailuresBeforeCritical,omitempty"`
+	// mog: func-to=CheckIDsToStructs func-from=NewCheckIDsFromStructs
+	DependsOn []string `protobuf:"bytes,46,rep,name=DependsOn,proto3" json:"DependsOn,omitempty"`
 	// Definition fields used when exposing checks through a proxy
 	ProxyHTTP string `protobuf:"bytes,23,opt,name=ProxyHTTP,proto3" json:"ProxyHTTP,omitempty"`
 	ProxyGRPC string `protobuf:"bytes,24,opt,name=ProxyGRPC,proto3" json:"ProxyGRPC,omitempty"`
@@ -680,6 +722,13 @@ func (x *CheckType) GetDisableRedirects() bool {
 	return false
 }
 
+func (x *CheckType) GetHTTPAssertions() []*HTTPCheckAssertion {
+	if x != nil {
+		return x.HTTPAssertions
+	}
+	return nil
+}
+
 func (x *CheckType) GetTCP() string {
 	if x != nil {
 		return x.TCP
@@ -708,6 +757,69 @@ func (x *CheckType) GetOSService() string {
 	return ""
 }
 
+func (x *CheckType) GetTLS() string {
+	if x != nil {
+		return x.TLS
+	}
+	return ""
+}
+
+func (x *CheckType) GetTLSExpiryWarningDays() int32 {
+	if x != nil {
+		return x.TLSExpiryWarningDays
+	}
+	return 0
+}
+
+func (x *CheckType) GetTLSExpiryCriticalDays() int32 {
+	if x != nil {
+		return x.TLSExpiryCriticalDays
+	}
+	return 0
+}
+
+func (x *CheckType) GetDNS() string {
+	if x != nil {
+		return x.DNS
+	}
+	return ""
+}
+
+func (x *CheckType) GetDNSQueryType() string {
+	if x != nil {
+		return x.DNSQueryType
+	}
+	return ""
+}
+
+func (x *CheckType) GetDNSResolver() string {
+	if x != nil {
+		return x.DNSResolver
+	}
+	return ""
+}
+
+func (x *CheckType) GetDNSExpectedValues() []string {
+	if x != nil {
+		return x.DNSExpectedValues
+	}
+	return nil
+}
+
+func (x *CheckType) GetDNSRcode() string {
+	if x != nil {
+		return x.DNSRcode
+	}
+	return ""
+}
+
+func (x *CheckType) GetDNSMaxLatency() *durationpb.Duration {
+	if x != nil {
+		return x.DNSMaxLatency
+	}
+	return nil
+}
+
 func (x *CheckType) GetInterval() *durationpb.Duration {
 	if x != nil {
 		return x.Interval
@@ -820,6 +932,13 @@ func (x *CheckType) GetFailuresBeforeCritical() int32 {
 	return 0
 }
 
+func (x *CheckType) GetDependsOn() []string {
+	if x != nil {
+		return x.DependsOn
+	}
+	return nil
+}
+
 func (x *CheckType) GetProxyHTTP() string {
 	if x != nil {
 		return x.ProxyHTTP
@@ -855,11 +974,103 @@ func (x *CheckType) GetSessionName() string {
 	return ""
 }
 
+// HTTPCheckAssertion is an assertion on the response of an HTTP check.
+//
+// mog annotation:
+//
+// target=github.com/hashicorp/consul/agent/structs.HTTPCheckAssertion
+// output=healthcheck.gen.go
+// name=Structs
+type HTTPCheckAssertion struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Body     string                 `protobuf:"bytes,1,opt,name=Body,proto3" json:"Body,omitempty"`
+	JSONPath string                 `protobuf:"bytes,2,opt,name=JSONPath,proto3" json:"JSONPath,omitempty"`
+	Header   string                 `protobuf:"bytes,3,opt,name=Header,proto3" json:"Header,omitempty"`
+	Value    string                 `protobuf:"bytes,4,opt,name=Value,proto3" json:"Value,omitempty"`
+	// mog: func-to=structs.DurationFromProto func-from=structs.DurationToProto
+	MaxLatency    *durationpb.Duration `protobuf:"bytes,5,opt,name=MaxLatency,proto3" json:"MaxLatency,omitempty"`
+	Status        string               `protobuf:"bytes,6,opt,name=Status,proto3" json:"Status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HTTPCheckAssertion) Reset() {
+	*x = HTTPCheckAssertion{}
+	mi := &file_private_pbservice_healthcheck_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HTTPCheckAssertion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HTTPCheckAssertion) ProtoMessage() {}
+
+func (x *HTTPCheckAssertion) ProtoReflect() protoreflect.Message {
+	mi := &file_private_pbservice_healthcheck_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HTTPCheckAssertion.ProtoReflect.Descriptor instead.
+func (*HTTPCheckAssertion) Descriptor() ([]byte, []int) {
+	return file_private_pbservice_healthcheck_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *HTTPCheckAssertion) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *HTTPCheckAssertion) GetJSONPath() string {
+	if x != nil {
+		return x.JSONPath
+	}
+	return ""
+}
+
+func (x *HTTPCheckAssertion) GetHeader() string {
+	if x != nil {
+		return x.Header
+	}
+	return ""
+}
+
+func (x *HTTPCheckAssertion) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *HTTPCheckAssertion) GetMaxLatency() *durationpb.Duration {
+	if x != nil {
+		return x.MaxLatency
+	}
+	return nil
+}
+
+func (x *HTTPCheckAssertion) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
 var File_private_pbservice_healthcheck_proto protoreflect.FileDescriptor
 
 const file_private_pbservice_healthcheck_proto_rawDesc = "" +
 	"\n" +
-	"#private/pbservice/healthcheck.proto\x12!hashicorp.consul.internal.service\x1a\x1egoogle/protobuf/duration.proto\x1a\x1dprivate/pbcommon/common.proto\"\xfe\x04\n" +
+	"#private/pbservice/healthcheck.proto\x12!hashicorp.consul.internal.service\x1a\x1egoogle/protobuf/duration.proto\x1a\x1dprivate/pbcommon/common.proto\"\xdc\x05\n" +
 	"\vHealthCheck\x12\x12\n" +
 	"\x04Node\x18\x01 \x01(\tR\x04Node\x12\x18\n" +
 	"\aCheckID\x18\x02 \x01(\tR\aCheckID\x12\x12\n" +
@@ -880,7 +1091,10 @@ const file_private_pbservice_healthcheck_proto_rawDesc = "" +
 	"\vExposedPort\x18\x0e \x01(\x05R\vExposedPort\x12\x1a\n" +
 	"\bInterval\x18\x0f \x01(\tR\bInterval\x12\x18\n" +
 	"\aTimeout\x18\x10 \x01(\tR\aTimeout\x12\x1a\n" +
-	"\bPeerName\x18\x11 \x01(\tR\bPeerName\"#\n" +
+	"\bPeerName\x18\x11 \x01(\tR\bPeerName\x12\x1c\n" +
+	"\tDependsOn\x18\x12 \x03(\tR\tDependsOn\x12\"\n" +
+	"\fSuppressedBy\x18\x13 \x01(\tR\fSuppressedBy\x12\x1a\n" +
+	"\bFlapping\x18\x14 \x01(\bR\bFlapping\"#\n" +
 	"\vHeaderValue\x12\x14\n" +
 	"\x05Value\x18\x01 \x03(\tR\x05Value\"\xd2\b\n" +
 	"\x15HealthCheckDefinition\x12\x12\n" +
@@ -917,8 +1131,7 @@ const file_private_pbservice_healthcheck_proto_rawDesc = "" +
 	"\vSessionName\x18\x1a \x01(\tR\vSessionName\x1ai\n" +
 	"\vHeaderEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12D\n" +
-	"\x05value\x18\x02 \x01(\v2..hashicorp.consul.internal.service.HeaderValueR\x05value:\x028\x01\"\xf4\n" +
-	"\n" +
+	"\x05value\x18\x02 \x01(\v2..hashicorp.consul.internal.service.HeaderValueR\x05value:\x028\x01\"\xd0\x0e\n" +
 	"\tCheckType\x12\x18\n" +
 	"\aCheckID\x18\x01 \x01(\tR\aCheckID\x12\x12\n" +
 	"\x04Name\x18\x02 \x01(\tR\x04Name\x12\x16\n" +
@@ -931,11 +1144,21 @@ const file_private_pbservice_healthcheck_proto_rawDesc = "" +
 	"\x06Header\x18\x14 \x03(\v28.hashicorp.consul.internal.service.CheckType.HeaderEntryR\x06Header\x12\x16\n" +
 	"\x06Method\x18\a \x01(\tR\x06Method\x12\x12\n" +
 	"\x04Body\x18\x1a \x01(\tR\x04Body\x12*\n" +
-	"\x10DisableRedirects\x18\x1f \x01(\bR\x10DisableRedirects\x12\x10\n" +
+	"\x10DisableRedirects\x18\x1f \x01(\bR\x10DisableRedirects\x12]\n" +
+	"\x0eHTTPAssertions\x18$ \x03(\v25.hashicorp.consul.internal.service.HTTPCheckAssertionR\x0eHTTPAssertions\x12\x10\n" +
 	"\x03TCP\x18\b \x01(\tR\x03TCP\x12\x1c\n" +
 	"\tTCPUseTLS\x18\" \x01(\bR\tTCPUseTLS\x12\x10\n" +
 	"\x03UDP\x18  \x01(\tR\x03UDP\x12\x1c\n" +
-	"\tOSService\x18! \x01(\tR\tOSService\x125\n" +
+	"\tOSService\x18! \x01(\tR\tOSService\x12\x10\n" +
+	"\x03TLS\x18% \x01(\tR\x03TLS\x122\n" +
+	"\x14TLSExpiryWarningDays\x18& \x01(\x05R\x14TLSExpiryWarningDays\x124\n" +
+	"\x15TLSExpiryCriticalDays\x18' \x01(\x05R\x15TLSExpiryCriticalDays\x12\x10\n" +
+	"\x03DNS\x18( \x01(\tR\x03DNS\x12\"\n" +
+	"\fDNSQueryType\x18) \x01(\tR\fDNSQueryType\x12 \n" +
+	"\vDNSResolver\x18* \x01(\tR\vDNSResolver\x12,\n" +
+	"\x11DNSExpectedValues\x18+ \x03(\tR\x11DNSExpectedValues\x12\x1a\n" +
+	"\bDNSRcode\x18, \x01(\tR\bDNSRcode\x12?\n" +
+	"\rDNSMaxLatency\x18- \x01(\v2\x19.google.protobuf.DurationR\rDNSMaxLatency\x125\n" +
 	"\bInterval\x18\t \x01(\v2\x19.google.protobuf.DurationR\bInterval\x12\x1c\n" +
 	"\tAliasNode\x18\n" +
 	" \x01(\tR\tAliasNode\x12\"\n" +
@@ -955,6 +1178,7 @@ const file_private_pbservice_healthcheck_proto_rawDesc = "" +
 	"\x14SuccessBeforePassing\x18\x15 \x01(\x05R\x14SuccessBeforePassing\x124\n" +
 	"\x15FailuresBeforeWarning\x18\x1d \x01(\x05R\x15FailuresBeforeWarning\x126\n" +
 	"\x16FailuresBeforeCritical\x18\x16 \x01(\x05R\x16FailuresBeforeCritical\x12\x1c\n" +
+	"\tDependsOn\x18. \x03(\tR\tDependsOn\x12\x1c\n" +
 	"\tProxyHTTP\x18\x17 \x01(\tR\tProxyHTTP\x12\x1c\n" +
 	"\tProxyGRPC\x18\x18 \x01(\tR\tProxyGRPC\x12a\n" +
 	"\x1eDeregisterCriticalServiceAfter\x18\x13 \x01(\v2\x19.google.protobuf.DurationR\x1eDeregisterCriticalServiceAfter\x12$\n" +
@@ -962,7 +1186,16 @@ const file_private_pbservice_healthcheck_proto_rawDesc = "" +
 	"\vSessionName\x18# \x01(\tR\vSessionName\x1ai\n" +
 	"\vHeaderEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12D\n" +
-	"\x05value\x18\x02 \x01(\v2..hashicorp.consul.internal.service.HeaderValueR\x05value:\x028\x01B\x96\x02\n" +
+	"\x05value\x18\x02 \x01(\v2..hashicorp.consul.internal.service.HeaderValueR\x05value:\x028\x01\"\xc5\x01\n" +
+	"\x12HTTPCheckAssertion\x12\x12\n" +
+	"\x04Body\x18\x01 \x01(\tR\x04Body\x12\x1a\n" +
+	"\bJSONPath\x18\x02 \x01(\tR\bJSONPath\x12\x16\n" +
+	"\x06Header\x18\x03 \x01(\tR\x06Header\x12\x14\n" +
+	"\x05Value\x18\x04 \x01(\tR\x05Value\x129\n" +
+	"\n" +
+	"MaxLatency\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"MaxLatency\x12\x16\n" +
+	"\x06Status\x18\x06 \x01(\tR\x06StatusB\x96\x02\n" +
 	"%com.hashicorp.consul.internal.serviceB\x10HealthcheckProtoP\x01Z3github.com/hashicorp/consul/proto/private/pbservice\xa2\x02\x04HCIS\xaa\x02!Hashicorp.Consul.Internal.Service\xca\x02!Hashicorp\\Consul\\Internal\\Service\xe2\x02-Hashicorp\\Consul\\Internal\\Service\\GPBMetadata\xea\x02$Hashicorp::Consul::Internal::Serviceb\x06proto3"
 
 var (
@@ -977,39 +1210,43 @@ func file_private_pbservice_healthcheck_proto_rawDescGZIP() []byte {
 	return file_private_pbservice_healthcheck_proto_rawDescData
 }
 
-var file_private_pbservice_healthcheck_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_private_pbservice_healthcheck_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_private_pbservice_healthcheck_proto_goTypes = []any{
 	(*HealthCheck)(nil),             // 0: hashicorp.consul.internal.service.HealthCheck
 	(*HeaderValue)(nil),             // 1: hashicorp.consul.internal.service.HeaderValue
 	(*HealthCheckDefinition)(nil),   // 2: hashicorp.consul.internal.service.HealthCheckDefinition
 	(*CheckType)(nil),               // 3: hashicorp.consul.internal.service.CheckType
-	nil,                             // 4: hashicorp.consul.internal.service.HealthCheckDefinition.HeaderEntry
-	nil,                             // 5: hashicorp.consul.internal.service.CheckType.HeaderEntry
-	(*pbcommon.RaftIndex)(nil),      // 6: hashicorp.consul.internal.common.RaftIndex
-	(*pbcommon.EnterpriseMeta)(nil), // 7: hashicorp.consul.internal.common.EnterpriseMeta
-	(*durationpb.Duration)(nil),     // 8: google.protobuf.Duration
+	(*HTTPCheckAssertion)(nil),      // 4: hashicorp.consul.internal.service.HTTPCheckAssertion
+	nil,                             // 5: hashicorp.consul.internal.service.HealthCheckDefinition.HeaderEntry
+	nil,                             // 6: hashicorp.consul.internal.service.CheckType.HeaderEntry
+	(*pbcommon.RaftIndex)(nil),      // 7: hashicorp.consul.internal.common.RaftIndex
+	(*pbcommon.EnterpriseMeta)(nil), // 8: hashicorp.consul.internal.common.EnterpriseMeta
+	(*durationpb.Duration)(nil),     // 9: google.protobuf.Duration
 }
 var file_private_pbservice_healthcheck_proto_depIdxs = []int32{
 	2,  // 0: hashicorp.consul.internal.service.HealthCheck.Definition:type_name -> hashicorp.consul.internal.service.HealthCheckDefinition
-	6,  // 1: hashicorp.consul.internal.service.HealthCheck.RaftIndex:type_name -> hashicorp.consul.internal.common.RaftIndex
-	7,  // 2: hashicorp.consul.internal.service.HealthCheck.EnterpriseMeta:type_name -> hashicorp.consul.internal.common.EnterpriseMeta
-	4,  // 3: hashicorp.consul.internal.service.HealthCheckDefinition.Header:type_name -> hashicorp.consul.internal.service.HealthCheckDefinition.HeaderEntry
-	8,  // 4: hashicorp.consul.internal.service.HealthCheckDefinition.Interval:type_name -> google.protobuf.Duration
-	8,  // 5: hashicorp.consul.internal.service.HealthCheckDefinition.Timeout:type_name -> google.protobuf.Duration
-	8,  // 6: hashicorp.consul.internal.service.HealthCheckDefinition.DeregisterCriticalServiceAfter:type_name -> google.protobuf.Duration
-	8,  // 7: hashicorp.consul.internal.service.HealthCheckDefinition.TTL:type_name -> google.protobuf.Duration
-	5,  // 8: hashicorp.consul.internal.service.CheckType.Header:type_name -> hashicorp.consul.internal.service.CheckType.HeaderEntry
-	8,  // 9: hashicorp.consul.internal.service.CheckType.Interval:type_name -> google.protobuf.Duration
-	8,  // 10: hashicorp.consul.internal.service.CheckType.Timeout:type_name -> google.protobuf.Duration
-	8,  // 11: hashicorp.consul.internal.service.CheckType.TTL:type_name -> google.protobuf.Duration
-	8,  // 12: hashicorp.consul.internal.service.CheckType.DeregisterCriticalServiceAfter:type_name -> google.protobuf.Duration
-	1,  // 13: hashicorp.consul.internal.service.HealthCheckDefinition.HeaderEntry.value:type_name -> hashicorp.consul.internal.service.HeaderValue
-	1,  // 14: hashicorp.consul.internal.service.CheckType.HeaderEntry.value:type_name -> hashicorp.consul.internal.service.HeaderValue
-	15, // [15:15] is the sub-list for method output_type
-	15, // [15:15] is the sub-list for method input_type
-	15, // [15:15] is the sub-list for extension type_name
-	15, // [15:15] is the sub-list for extension extendee
-	0,  // [0:15] is the sub-list for field type_name
+	7,  // 1: hashicorp.consul.internal.service.HealthCheck.RaftIndex:type_name -> hashicorp.consul.internal.common.RaftIndex
+	8,  // 2: hashicorp.consul.internal.service.HealthCheck.EnterpriseMeta:type_name -> hashicorp.consul.internal.common.EnterpriseMeta
+	5,  // 3: hashicorp.consul.internal.service.HealthCheckDefinition.Header:type_name -> hashicorp.consul.internal.service.HealthCheckDefinition.HeaderEntry
+	9,  // 4: hashicorp.consul.internal.service.HealthCheckDefinition.Interval:type_name -> google.protobuf.Duration
+	9,  // 5: hashicorp.consul.internal.service.HealthCheckDefinition.Timeout:type_name -> google.protobuf.Duration
+	9,  // 6: hashicorp.consul.internal.service.HealthCheckDefinition.DeregisterCriticalServiceAfter:type_name -> google.protobuf.Duration
+	9,  // 7: hashicorp.consul.internal.service.HealthCheckDefinition.TTL:type_name -> google.protobuf.Duration
+	6,  // 8: hashicorp.consul.internal.service.CheckType.Header:type_name -> hashicorp.consul.internal.service.CheckType.HeaderEntry
+	4,  // 9: hashicorp.consul.internal.service.CheckType.HTTPAssertions:type_name -> hashicorp.consul.internal.service.HTTPCheckAssertion
+	9,  // 10: hashicorp.consul.internal.service.CheckType.DNSMaxLatency:type_name -> google.protobuf.Duration
+	9,  // 11: hashicorp.consul.internal.service.CheckType.Interval:type_name -> google.protobuf.Duration
+	9,  // 12: hashicorp.consul.internal.service.CheckType.Timeout:type_name -> google.protobuf.Duration
+	9,  // 13: hashicorp.consul.internal.service.CheckType.TTL:type_name -> google.protobuf.Duration
+	9,  // 14: hashicorp.consul.internal.service.CheckType.DeregisterCriticalServiceAfter:type_name -> google.protobuf.Duration
+	9,  // 15: hashicorp.consul.internal.service.HTTPCheckAssertion.MaxLatency:type_name -> google.protobuf.Duration
+	1,  // 16: hashicorp.consul.internal.service.HealthCheckDefinition.HeaderEntry.value:type_name -> hashicorp.consul.internal.service.HeaderValue
+	1,  // 17: hashicorp.consul.internal.service.CheckType.HeaderEntry.value:type_name -> hashicorp.consul.internal.service.HeaderValue
+	18, // [18:18] is the sub-list for method output_type
+	18, // [18:18] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_private_pbservice_healthcheck_proto_init() }
@@ -1023,7 +1260,7 @@ func file_private_pbservice_healthcheck_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_private_pbservice_healthcheck_proto_rawDesc), len(file_private_pbservice_healthcheck_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
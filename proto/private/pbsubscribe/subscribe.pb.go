@@ -25,6 +25,7 @@ import (
 	pbservice "github.com/hashicorp/consul/proto/private/pbservice"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -85,6 +86,10 @@ const (
 	Topic_FileSystemCertificate Topic = 18
 	// GlobalRateLimit topic contains events for changes to global rate limits.
 	Topic_GlobalRateLimit Topic = 19
+	// KV topic contains events for changes to entries in the KV store. A
+	// subscription may be for a single key or, by setting NamedSubject.Prefix,
+	// for every key under a prefix.
+	Topic_KV Topic = 20
 )
 
 // Enum value maps for Topic.
@@ -110,6 +115,7 @@ var (
 		17: "ExportedServices",
 		18: "FileSystemCertificate",
 		19: "GlobalRateLimit",
+		20: "KV",
 	}
 	Topic_value = map[string]int32{
 		"Unknown":               0,
@@ -132,6 +138,7 @@ var (
 		"ExportedServices":      17,
 		"FileSystemCertificate": 18,
 		"GlobalRateLimit":       19,
+		"KV":                    20,
 	}
 )
 
@@ -254,6 +261,52 @@ func (ConfigEntryUpdate_UpdateOp) EnumDescriptor() ([]byte, []int) {
 	return file_private_pbsubscribe_subscribe_proto_rawDescGZIP(), []int{5, 0}
 }
 
+type KVUpdate_UpdateOp int32
+
+const (
+	KVUpdate_Upsert KVUpdate_UpdateOp = 0
+	KVUpdate_Delete KVUpdate_UpdateOp = 1
+)
+
+// Enum value maps for KVUpdate_UpdateOp.
+var (
+	KVUpdate_UpdateOp_name = map[int32]string{
+		0: "Upsert",
+		1: "Delete",
+	}
+	KVUpdate_UpdateOp_value = map[string]int32{
+		"Upsert": 0,
+		"Delete": 1,
+	}
+)
+
+func (x KVUpdate_UpdateOp) Enum() *KVUpdate_UpdateOp {
+	p := new(KVUpdate_UpdateOp)
+	*p = x
+	return p
+}
+
+func (x KVUpdate_UpdateOp) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (KVUpdate_UpdateOp) Descriptor() protoreflect.EnumDescriptor {
+	return file_private_pbsubscribe_subscribe_proto_enumTypes[3].Descriptor()
+}
+
+func (KVUpdate_UpdateOp) Type() protoreflect.EnumType {
+	return &file_private_pbsubscribe_subscribe_proto_enumTypes[3]
+}
+
+func (x KVUpdate_UpdateOp) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use KVUpdate_UpdateOp.Descriptor instead.
+func (KVUpdate_UpdateOp) EnumDescriptor() ([]byte, []int) {
+	return file_private_pbsubscribe_subscribe_proto_rawDescGZIP(), []int{7, 0}
+}
+
 type NamedSubject struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Key is a topic-specific identifier that restricts the scope of the
@@ -272,7 +325,11 @@ type NamedSubject struct {
 	// Partition is an enterprise-only feature.
 	Partition string `protobuf:"bytes,3,opt,name=Partition,proto3" json:"Partition,omitempty"`
 	// PeerName is the name of the peer that the requested service was imported from.
-	PeerName      string `protobuf:"bytes,4,opt,name=PeerName,proto3" json:"PeerName,omitempty"`
+	PeerName string `protobuf:"bytes,4,opt,name=PeerName,proto3" json:"PeerName,omitempty"`
+	// Prefix indicates that Key is a prefix and the subscriber wishes to receive
+	// events for every resource under it. It is only supported by the KV topic,
+	// where the prefix must be empty or end in a '/'.
+	Prefix        bool `protobuf:"varint,5,opt,name=Prefix,proto3" json:"Prefix,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -335,6 +392,13 @@ func (x *NamedSubject) GetPeerName() string {
 	return ""
 }
 
+func (x *NamedSubject) GetPrefix() bool {
+	if x != nil {
+		return x.Prefix
+	}
+	return false
+}
+
 // SubscribeRequest used to subscribe to a topic.
 type SubscribeRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -527,6 +591,7 @@ type Event struct {
 	//	*Event_ServiceHealth
 	//	*Event_ConfigEntry
 	//	*Event_Service
+	//	*Event_KV
 	Payload       isEvent_Payload `protobuf_oneof:"Payload"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -630,6 +695,15 @@ func (x *Event) GetService() *ServiceListUpdate {
 	return nil
 }
 
+func (x *Event) GetKV() *KVUpdate {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_KV); ok {
+			return x.KV
+		}
+	}
+	return nil
+}
+
 type isEvent_Payload interface {
 	isEvent_Payload()
 }
@@ -673,6 +747,11 @@ type Event_Service struct {
 	Service *ServiceListUpdate `protobuf:"bytes,12,opt,name=Service,proto3,oneof"`
 }
 
+type Event_KV struct {
+	// KV is used for the KV topic.
+	KV *KVUpdate `protobuf:"bytes,13,opt,name=KV,proto3,oneof"`
+}
+
 func (*Event_EndOfSnapshot) isEvent_Payload() {}
 
 func (*Event_NewSnapshotToFollow) isEvent_Payload() {}
@@ -685,6 +764,8 @@ func (*Event_ConfigEntry) isEvent_Payload() {}
 
 func (*Event_Service) isEvent_Payload() {}
 
+func (*Event_KV) isEvent_Payload() {}
+
 type EventBatch struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Events        []*Event               `protobuf:"bytes,1,rep,name=Events,proto3" json:"Events,omitempty"`
@@ -901,16 +982,144 @@ func (x *ServiceListUpdate) GetPeerName() string {
 	return ""
 }
 
+type KVUpdate struct {
+	state          protoimpl.MessageState   `protogen:"open.v1"`
+	Op             KVUpdate_UpdateOp        `protobuf:"varint,1,opt,name=Op,proto3,enum=subscribe.KVUpdate_UpdateOp" json:"Op,omitempty"`
+	Key            string                   `protobuf:"bytes,2,opt,name=Key,proto3" json:"Key,omitempty"`
+	Flags          uint64                   `protobuf:"varint,3,opt,name=Flags,proto3" json:"Flags,omitempty"`
+	Value          []byte                   `protobuf:"bytes,4,opt,name=Value,proto3" json:"Value,omitempty"`
+	Session        string                   `protobuf:"bytes,5,opt,name=Session,proto3" json:"Session,omitempty"`
+	LockIndex      uint64                   `protobuf:"varint,6,opt,name=LockIndex,proto3" json:"LockIndex,omitempty"`
+	CreateIndex    uint64                   `protobuf:"varint,7,opt,name=CreateIndex,proto3" json:"CreateIndex,omitempty"`
+	ModifyIndex    uint64                   `protobuf:"varint,8,opt,name=ModifyIndex,proto3" json:"ModifyIndex,omitempty"`
+	EnterpriseMeta *pbcommon.EnterpriseMeta `protobuf:"bytes,9,opt,name=EnterpriseMeta,proto3" json:"EnterpriseMeta,omitempty"`
+	// ExpirationTime is set if the entry was written with a TTL.
+	ExpirationTime *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=ExpirationTime,proto3" json:"ExpirationTime,omitempty"`
+	// FencingToken is the Raft index at which the lock on the entry was last
+	// acquired.
+	FencingToken  uint64 `protobuf:"varint,11,opt,name=FencingToken,proto3" json:"FencingToken,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KVUpdate) Reset() {
+	*x = KVUpdate{}
+	mi := &file_private_pbsubscribe_subscribe_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KVUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KVUpdate) ProtoMessage() {}
+
+func (x *KVUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_private_pbsubscribe_subscribe_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KVUpdate.ProtoReflect.Descriptor instead.
+func (*KVUpdate) Descriptor() ([]byte, []int) {
+	return file_private_pbsubscribe_subscribe_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *KVUpdate) GetOp() KVUpdate_UpdateOp {
+	if x != nil {
+		return x.Op
+	}
+	return KVUpdate_Upsert
+}
+
+func (x *KVUpdate) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *KVUpdate) GetFlags() uint64 {
+	if x != nil {
+		return x.Flags
+	}
+	return 0
+}
+
+func (x *KVUpdate) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *KVUpdate) GetSession() string {
+	if x != nil {
+		return x.Session
+	}
+	return ""
+}
+
+func (x *KVUpdate) GetLockIndex() uint64 {
+	if x != nil {
+		return x.LockIndex
+	}
+	return 0
+}
+
+func (x *KVUpdate) GetCreateIndex() uint64 {
+	if x != nil {
+		return x.CreateIndex
+	}
+	return 0
+}
+
+func (x *KVUpdate) GetModifyIndex() uint64 {
+	if x != nil {
+		return x.ModifyIndex
+	}
+	return 0
+}
+
+func (x *KVUpdate) GetEnterpriseMeta() *pbcommon.EnterpriseMeta {
+	if x != nil {
+		return x.EnterpriseMeta
+	}
+	return nil
+}
+
+func (x *KVUpdate) GetExpirationTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpirationTime
+	}
+	return nil
+}
+
+func (x *KVUpdate) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
 var File_private_pbsubscribe_subscribe_proto protoreflect.FileDescriptor
 
 const file_private_pbsubscribe_subscribe_proto_rawDesc = "" +
 	"\n" +
-	"#private/pbsubscribe/subscribe.proto\x12\tsubscribe\x1a%annotations/ratelimit/ratelimit.proto\x1a\x1dprivate/pbcommon/common.proto\x1a(private/pbconfigentry/config_entry.proto\x1a\x1cprivate/pbservice/node.proto\"x\n" +
+	"#private/pbsubscribe/subscribe.proto\x12\tsubscribe\x1a\x1fgoogle/protobuf/timestamp.proto\x1a%annotations/ratelimit/ratelimit.proto\x1a\x1dprivate/pbcommon/common.proto\x1a(private/pbconfigentry/config_entry.proto\x1a\x1cprivate/pbservice/node.proto\"\x90\x01\n" +
 	"\fNamedSubject\x12\x10\n" +
 	"\x03Key\x18\x01 \x01(\tR\x03Key\x12\x1c\n" +
 	"\tNamespace\x18\x02 \x01(\tR\tNamespace\x12\x1c\n" +
 	"\tPartition\x18\x03 \x01(\tR\tPartition\x12\x1a\n" +
-	"\bPeerName\x18\x04 \x01(\tR\bPeerName\"\xe6\x02\n" +
+	"\bPeerName\x18\x04 \x01(\tR\bPeerName\x12\x16\n" +
+	"\x06Prefix\x18\x05 \x01(\bR\x06Prefix\"\xe6\x02\n" +
 	"\x10SubscribeRequest\x12&\n" +
 	"\x05Topic\x18\x01 \x01(\x0e2\x10.subscribe.TopicR\x05Topic\x12\x10\n" +
 	"\x03Key\x18\x02 \x01(\tR\x03Key\x12\x14\n" +
@@ -925,7 +1134,7 @@ const file_private_pbsubscribe_subscribe_proto_rawDesc = "" +
 	"\x0fWildcardSubject\x18\t \x01(\bH\x00R\x0fWildcardSubject\x12=\n" +
 	"\fNamedSubject\x18\n" +
 	" \x01(\v2\x17.subscribe.NamedSubjectH\x00R\fNamedSubjectB\t\n" +
-	"\aSubject\"\x81\x03\n" +
+	"\aSubject\"\xa8\x03\n" +
 	"\x05Event\x12\x14\n" +
 	"\x05Index\x18\x01 \x01(\x04R\x05Index\x12&\n" +
 	"\rEndOfSnapshot\x18\x02 \x01(\bH\x00R\rEndOfSnapshot\x122\n" +
@@ -936,7 +1145,8 @@ const file_private_pbsubscribe_subscribe_proto_rawDesc = "" +
 	"\rServiceHealth\x18\n" +
 	" \x01(\v2\x1e.subscribe.ServiceHealthUpdateH\x00R\rServiceHealth\x12@\n" +
 	"\vConfigEntry\x18\v \x01(\v2\x1c.subscribe.ConfigEntryUpdateH\x00R\vConfigEntry\x128\n" +
-	"\aService\x18\f \x01(\v2\x1c.subscribe.ServiceListUpdateH\x00R\aServiceB\t\n" +
+	"\aService\x18\f \x01(\v2\x1c.subscribe.ServiceListUpdateH\x00R\aService\x12%\n" +
+	"\x02KV\x18\r \x01(\v2\x13.subscribe.KVUpdateH\x00R\x02KVB\t\n" +
 	"\aPayload\"6\n" +
 	"\n" +
 	"EventBatch\x12(\n" +
@@ -956,7 +1166,25 @@ const file_private_pbsubscribe_subscribe_proto_rawDesc = "" +
 	"\x02Op\x18\x01 \x01(\x0e2\x14.subscribe.CatalogOpR\x02Op\x12\x12\n" +
 	"\x04Name\x18\x02 \x01(\tR\x04Name\x12X\n" +
 	"\x0eEnterpriseMeta\x18\x03 \x01(\v20.hashicorp.consul.internal.common.EnterpriseMetaR\x0eEnterpriseMeta\x12\x1a\n" +
-	"\bPeerName\x18\x04 \x01(\tR\bPeerName*\x8b\x03\n" +
+	"\bPeerName\x18\x04 \x01(\tR\bPeerName\"\xd8\x03\n" +
+	"\bKVUpdate\x12,\n" +
+	"\x02Op\x18\x01 \x01(\x0e2\x1c.subscribe.KVUpdate.UpdateOpR\x02Op\x12\x10\n" +
+	"\x03Key\x18\x02 \x01(\tR\x03Key\x12\x14\n" +
+	"\x05Flags\x18\x03 \x01(\x04R\x05Flags\x12\x14\n" +
+	"\x05Value\x18\x04 \x01(\fR\x05Value\x12\x18\n" +
+	"\aSession\x18\x05 \x01(\tR\aSession\x12\x1c\n" +
+	"\tLockIndex\x18\x06 \x01(\x04R\tLockIndex\x12 \n" +
+	"\vCreateIndex\x18\a \x01(\x04R\vCreateIndex\x12 \n" +
+	"\vModifyIndex\x18\b \x01(\x04R\vModifyIndex\x12X\n" +
+	"\x0eEnterpriseMeta\x18\t \x01(\v20.hashicorp.consul.internal.common.EnterpriseMetaR\x0eEnterpriseMeta\x12B\n" +
+	"\x0eExpirationTime\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\x0eExpirationTime\x12\"\n" +
+	"\fFencingToken\x18\v \x01(\x04R\fFencingToken\"\"\n" +
+	"\bUpdateOp\x12\n" +
+	"\n" +
+	"\x06Upsert\x10\x00\x12\n" +
+	"\n" +
+	"\x06Delete\x10\x01*\x93\x03\n" +
 	"\x05Topic\x12\v\n" +
 	"\aUnknown\x10\x00\x12\x11\n" +
 	"\rServiceHealth\x10\x01\x12\x18\n" +
@@ -980,7 +1208,8 @@ const file_private_pbsubscribe_subscribe_proto_rawDesc = "" +
 	"\vJWTProvider\x10\x10\x12\x14\n" +
 	"\x10ExportedServices\x10\x11\x12\x19\n" +
 	"\x15FileSystemCertificate\x10\x12\x12\x13\n" +
-	"\x0fGlobalRateLimit\x10\x13*)\n" +
+	"\x0fGlobalRateLimit\x10\x13\x12\x06\n" +
+	"\x02KV\x10\x14*)\n" +
 	"\tCatalogOp\x12\f\n" +
 	"\bRegister\x10\x00\x12\x0e\n" +
 	"\n" +
@@ -1001,44 +1230,51 @@ func file_private_pbsubscribe_subscribe_proto_rawDescGZIP() []byte {
 	return file_private_pbsubscribe_subscribe_proto_rawDescData
 }
 
-var file_private_pbsubscribe_subscribe_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_private_pbsubscribe_subscribe_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_private_pbsubscribe_subscribe_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_private_pbsubscribe_subscribe_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_private_pbsubscribe_subscribe_proto_goTypes = []any{
 	(Topic)(0),                         // 0: subscribe.Topic
 	(CatalogOp)(0),                     // 1: subscribe.CatalogOp
 	(ConfigEntryUpdate_UpdateOp)(0),    // 2: subscribe.ConfigEntryUpdate.UpdateOp
-	(*NamedSubject)(nil),               // 3: subscribe.NamedSubject
-	(*SubscribeRequest)(nil),           // 4: subscribe.SubscribeRequest
-	(*Event)(nil),                      // 5: subscribe.Event
-	(*EventBatch)(nil),                 // 6: subscribe.EventBatch
-	(*ServiceHealthUpdate)(nil),        // 7: subscribe.ServiceHealthUpdate
-	(*ConfigEntryUpdate)(nil),          // 8: subscribe.ConfigEntryUpdate
-	(*ServiceListUpdate)(nil),          // 9: subscribe.ServiceListUpdate
-	(*pbservice.CheckServiceNode)(nil), // 10: hashicorp.consul.internal.service.CheckServiceNode
-	(*pbconfigentry.ConfigEntry)(nil),  // 11: hashicorp.consul.internal.configentry.ConfigEntry
-	(*pbcommon.EnterpriseMeta)(nil),    // 12: hashicorp.consul.internal.common.EnterpriseMeta
+	(KVUpdate_UpdateOp)(0),             // 3: subscribe.KVUpdate.UpdateOp
+	(*NamedSubject)(nil),               // 4: subscribe.NamedSubject
+	(*SubscribeRequest)(nil),           // 5: subscribe.SubscribeRequest
+	(*Event)(nil),                      // 6: subscribe.Event
+	(*EventBatch)(nil),                 // 7: subscribe.EventBatch
+	(*ServiceHealthUpdate)(nil),        // 8: subscribe.ServiceHealthUpdate
+	(*ConfigEntryUpdate)(nil),          // 9: subscribe.ConfigEntryUpdate
+	(*ServiceListUpdate)(nil),          // 10: subscribe.ServiceListUpdate
+	(*KVUpdate)(nil),                   // 11: subscribe.KVUpdate
+	(*pbservice.CheckServiceNode)(nil), // 12: hashicorp.consul.internal.service.CheckServiceNode
+	(*pbconfigentry.ConfigEntry)(nil),  // 13: hashicorp.consul.internal.configentry.ConfigEntry
+	(*pbcommon.EnterpriseMeta)(nil),    // 14: hashicorp.consul.internal.common.EnterpriseMeta
+	(*timestamppb.Timestamp)(nil),      // 15: google.protobuf.Timestamp
 }
 var file_private_pbsubscribe_subscribe_proto_depIdxs = []int32{
 	0,  // 0: subscribe.SubscribeRequest.Topic:type_name -> subscribe.Topic
-	3,  // 1: subscribe.SubscribeRequest.NamedSubject:type_name -> subscribe.NamedSubject
-	6,  // 2: subscribe.Event.EventBatch:type_name -> subscribe.EventBatch
-	7,  // 3: subscribe.Event.ServiceHealth:type_name -> subscribe.ServiceHealthUpdate
-	8,  // 4: subscribe.Event.ConfigEntry:type_name -> subscribe.ConfigEntryUpdate
-	9,  // 5: subscribe.Event.Service:type_name -> subscribe.ServiceListUpdate
-	5,  // 6: subscribe.EventBatch.Events:type_name -> subscribe.Event
-	1,  // 7: subscribe.ServiceHealthUpdate.Op:type_name -> subscribe.CatalogOp
-	10, // 8: subscribe.ServiceHealthUpdate.CheckServiceNode:type_name -> hashicorp.consul.internal.service.CheckServiceNode
-	2,  // 9: subscribe.ConfigEntryUpdate.Op:type_name -> subscribe.ConfigEntryUpdate.UpdateOp
-	11, // 10: subscribe.ConfigEntryUpdate.ConfigEntry:type_name -> hashicorp.consul.internal.configentry.ConfigEntry
-	1,  // 11: subscribe.ServiceListUpdate.Op:type_name -> subscribe.CatalogOp
-	12, // 12: subscribe.ServiceListUpdate.EnterpriseMeta:type_name -> hashicorp.consul.internal.common.EnterpriseMeta
-	4,  // 13: subscribe.StateChangeSubscription.Subscribe:input_type -> subscribe.SubscribeRequest
-	5,  // 14: subscribe.StateChangeSubscription.Subscribe:output_type -> subscribe.Event
-	14, // [14:15] is the sub-list for method output_type
-	13, // [13:14] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+	4,  // 1: subscribe.SubscribeRequest.NamedSubject:type_name -> subscribe.NamedSubject
+	7,  // 2: subscribe.Event.EventBatch:type_name -> subscribe.EventBatch
+	8,  // 3: subscribe.Event.ServiceHealth:type_name -> subscribe.ServiceHealthUpdate
+	9,  // 4: subscribe.Event.ConfigEntry:type_name -> subscribe.ConfigEntryUpdate
+	10, // 5: subscribe.Event.Service:type_name -> subscribe.ServiceListUpdate
+	11, // 6: subscribe.Event.KV:type_name -> subscribe.KVUpdate
+	6,  // 7: subscribe.EventBatch.Events:type_name -> subscribe.Event
+	1,  // 8: subscribe.ServiceHealthUpdate.Op:type_name -> subscribe.CatalogOp
+	12, // 9: subscribe.ServiceHealthUpdate.CheckServiceNode:type_name -> hashicorp.consul.internal.service.CheckServiceNode
+	2,  // 10: subscribe.ConfigEntryUpdate.Op:type_name -> subscribe.ConfigEntryUpdate.UpdateOp
+	13, // 11: subscribe.ConfigEntryUpdate.ConfigEntry:type_name -> hashicorp.consul.internal.configentry.ConfigEntry
+	1,  // 12: subscribe.ServiceListUpdate.Op:type_name -> subscribe.CatalogOp
+	14, // 13: subscribe.ServiceListUpdate.EnterpriseMeta:type_name -> hashicorp.consul.internal.common.EnterpriseMeta
+	3,  // 14: subscribe.KVUpdate.Op:type_name -> subscribe.KVUpdate.UpdateOp
+	14, // 15: subscribe.KVUpdate.EnterpriseMeta:type_name -> hashicorp.consul.internal.common.EnterpriseMeta
+	15, // 16: subscribe.KVUpdate.ExpirationTime:type_name -> google.protobuf.Timestamp
+	5,  // 17: subscribe.StateChangeSubscription.Subscribe:input_type -> subscribe.SubscribeRequest
+	6,  // 18: subscribe.StateChangeSubscription.Subscribe:output_type -> subscribe.Event
+	18, // [18:19] is the sub-list for method output_type
+	17, // [17:18] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_private_pbsubscribe_subscribe_proto_init() }
@@ -1057,14 +1293,15 @@ func file_private_pbsubscribe_subscribe_proto_init() {
 		(*Event_ServiceHealth)(nil),
 		(*Event_ConfigEntry)(nil),
 		(*Event_Service)(nil),
+		(*Event_KV)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_private_pbsubscribe_subscribe_proto_rawDesc), len(file_private_pbsubscribe_subscribe_proto_rawDesc)),
-			NumEnums:      3,
-			NumMessages:   7,
+			NumEnums:      4,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -5,11 +5,14 @@
 // tar file with the following contents:
 //
 // meta.json  - JSON-encoded snapshot metadata from Raft
-// state.bin  - Encoded snapshot data from Raft
+// state.bin  - Encoded snapshot data from Raft (log entries if incremental)
 // SHA256SUMS - SHA-256 sums of the above two files
 //
 // The integrity information is automatically created and checked, and a failure
 // there just looks like an error to the caller.
+//
+// The gzip-compressed archive can also be encrypted, see Encrypt and
+// MaybeDecrypt for the format of encrypted snapshots.
 package snapshot
 
 import (
@@ -96,9 +99,23 @@ func (hl *hashList) DecodeAndVerify(r io.Reader) error {
 	return nil
 }
 
+// archiveMeta is the contents of meta.json. Incremental snapshots add
+// information about their base snapshot to the metadata from Raft, which
+// older versions will ignore.
+type archiveMeta struct {
+	raft.SnapshotMeta
+
+	Incremental *IncrementalMeta `json:",omitempty"`
+}
+
 // write takes a writer and creates an archive with the snapshot metadata,
 // the snapshot itself, and adds some integrity checking information.
 func write(out io.Writer, metadata *raft.SnapshotMeta, snap io.Reader) error {
+	return writeArchive(out, &archiveMeta{SnapshotMeta: *metadata}, snap)
+}
+
+// writeArchive is like write but takes the full archive metadata.
+func writeArchive(out io.Writer, metadata *archiveMeta, snap io.Reader) error {
 	// Start a new tarball.
 	now := time.Now()
 	archive := tar.NewWriter(out)
@@ -168,8 +185,25 @@ func write(out io.Writer, metadata *raft.SnapshotMeta, snap io.Reader) error {
 
 // read takes a reader and extracts the snapshot metadata and the snapshot
 // itself, and also checks the integrity of the data. You must arrange to call
-// Close() on the returned object or else you will leak a temporary file.
+// Close() on the returned object or else you will leak a temporary file. It
+// returns ErrIncremental for an incremental snapshot, which only holds log
+// entries and must be merged with its base before it can be used.
 func read(in io.Reader, metadata *raft.SnapshotMeta, snap io.Writer) error {
+	var am archiveMeta
+	if _, err := readArchive(in, &am, snap); err != nil {
+		return err
+	}
+	if am.Incremental != nil {
+		return ErrIncremental
+	}
+	*metadata = am.SnapshotMeta
+	return nil
+}
+
+// readArchive is like read but extracts the full archive metadata. It also
+// returns the SHA-256 of the snapshot data, which identifies the snapshot as
+// the base of an incremental snapshot.
+func readArchive(in io.Reader, metadata *archiveMeta, snap io.Writer) ([]byte, error) {
 	// Start a new tar reader.
 	archive := tar.NewReader(in)
 
@@ -191,7 +225,7 @@ func read(in io.Reader, metadata *raft.SnapshotMeta, snap io.Writer) error {
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("failed reading snapshot: %v", err)
+			return nil, fmt.Errorf("failed reading snapshot: %v", err)
 		}
 
 		switch hdr.Name {
@@ -206,31 +240,31 @@ func read(in io.Reader, metadata *raft.SnapshotMeta, snap io.Writer) error {
 			// independent of how json.Decode works internally.
 			buf, err := io.ReadAll(io.TeeReader(archive, metaHash))
 			if err != nil {
-				return fmt.Errorf("failed to read snapshot metadata: %v", err)
+				return nil, fmt.Errorf("failed to read snapshot metadata: %v", err)
 			}
 			if err := json.Unmarshal(buf, &metadata); err != nil {
-				return fmt.Errorf("failed to decode snapshot metadata: %v", err)
+				return nil, fmt.Errorf("failed to decode snapshot metadata: %v", err)
 			}
 
 		case "state.bin":
 			if _, err := io.Copy(io.MultiWriter(snap, snapHash), archive); err != nil {
-				return fmt.Errorf("failed to read or write snapshot data: %v", err)
+				return nil, fmt.Errorf("failed to read or write snapshot data: %v", err)
 			}
 
 		case "SHA256SUMS":
 			if _, err := io.Copy(&shaBuffer, archive); err != nil {
-				return fmt.Errorf("failed to read snapshot hashes: %v", err)
+				return nil, fmt.Errorf("failed to read snapshot hashes: %v", err)
 			}
 
 		default:
-			return fmt.Errorf("unexpected file %q in snapshot", hdr.Name)
+			return nil, fmt.Errorf("unexpected file %q in snapshot", hdr.Name)
 		}
 	}
 
 	// Verify all the hashes.
 	if err := hl.DecodeAndVerify(&shaBuffer); err != nil {
-		return fmt.Errorf("failed checking integrity of snapshot: %v", err)
+		return nil, fmt.Errorf("failed checking integrity of snapshot: %v", err)
 	}
 
-	return nil
+	return snapHash.Sum(nil), nil
 }
@@ -0,0 +1,448 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"golang.org/x/crypto/scrypt"
+)
+
+// An encrypted snapshot wraps a complete snapshot archive in an envelope that
+// is encrypted and authenticated with AES-256-GCM:
+//
+// magic      - The 8 bytes "CSNAPENC"
+// header     - Big-endian uint32 length followed by a JSON encryptionHeader
+// chunks     - A sequence of encrypted chunks of the archive, each made of a
+//              final flag byte, a big-endian uint32 length and the ciphertext
+//
+// Each archive is encrypted with a random data key, which is stored in the
+// header wrapped by a key encryption key. The key encryption key is either
+// read from a key file or derived from a passphrase with scrypt. The archive
+// is split into chunks so it can be streamed, and every chunk is bound to the
+// header, its position, and whether it is the last chunk, so any tampering,
+// reordering or truncation of the file is detected when it is decrypted.
+
+// PassphraseEnvName is the environment variable the snapshot commands read a
+// passphrase from when a key file is not given.
+const PassphraseEnvName = "CONSUL_SNAPSHOT_PASSPHRASE"
+
+const (
+	encryptionVersion = 1
+
+	keyTypeKeyfile    = "keyfile"
+	keyTypePassphrase = "passphrase"
+
+	// keySize is the size of the data key and the key encryption key, which
+	// selects AES-256.
+	keySize = 32
+
+	// chunkSize is the amount of plaintext in each encrypted chunk.
+	chunkSize = 64 * 1024
+
+	// maxChunkSize limits the chunk size we'll accept from a header so a
+	// corrupt file can't make us allocate an unbounded buffer.
+	maxChunkSize = 16 * 1024 * 1024
+
+	// maxHeaderSize limits the size of the header for the same reason.
+	maxHeaderSize = 64 * 1024
+
+	// The scrypt parameters used to derive a key from a passphrase.
+	scryptN  = 32768
+	scryptR  = 8
+	scryptP  = 1
+	saltSize = 32
+
+	// maxScryptMemory and maxScryptWork limit the memory (128·N·r bytes) and
+	// work (N·r·p) of deriving a key from the unauthenticated parameters in a
+	// header to those of the parameters above.
+	maxScryptMemory = 128 * scryptN * scryptR
+	maxScryptWork   = scryptN * scryptR * scryptP
+
+	// wrapAAD is the additional data used when wrapping the data key.
+	wrapAAD = "consul-snapshot-key"
+)
+
+var encryptionMagic = []byte("CSNAPENC")
+
+// encryptionHeader describes how an encrypted snapshot was encrypted.
+type encryptionHeader struct {
+	Version int
+	KeyType string
+
+	// Salt and the scrypt cost parameters are set when the key encryption
+	// key is derived from a passphrase.
+	Salt []byte `json:",omitempty"`
+	N    int    `json:",omitempty"`
+	R    int    `json:",omitempty"`
+	P    int    `json:",omitempty"`
+
+	// WrappedKey is the data key, encrypted with the key encryption key
+	// using WrapNonce.
+	WrapNonce  []byte
+	WrappedKey []byte
+
+	// ChunkSize is the maximum amount of plaintext in each chunk.
+	ChunkSize int
+}
+
+// EncryptionKey is the secret used to encrypt and decrypt snapshots. Exactly
+// one of Key or Passphrase should be set.
+type EncryptionKey struct {
+	// Key is a 32 byte key, used directly as the key encryption key.
+	Key []byte
+
+	// Passphrase is used to derive the key encryption key.
+	Passphrase string
+}
+
+// LoadEncryptionKey returns the key held in the given key file or, if no key
+// file is given, the given passphrase. The key file holds a base64-encoded
+// 32 byte key, such as the output of "consul keygen". It returns nil if
+// neither is given.
+func LoadEncryptionKey(keyfile, passphrase string) (*EncryptionKey, error) {
+	if keyfile == "" {
+		if passphrase == "" {
+			return nil, nil
+		}
+		return &EncryptionKey{Passphrase: passphrase}, nil
+	}
+
+	raw, err := os.ReadFile(keyfile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %v", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key file: %v", err)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key file must hold a %d byte key, got %d bytes", keySize, len(key))
+	}
+	return &EncryptionKey{Key: key}, nil
+}
+
+// kek returns the key encryption key described by the header.
+func (k *EncryptionKey) kek(hdr *encryptionHeader) ([]byte, error) {
+	switch hdr.KeyType {
+	case keyTypeKeyfile:
+		if k.Key == nil {
+			return nil, fmt.Errorf("snapshot was encrypted with a key file")
+		}
+		if len(k.Key) != keySize {
+			return nil, fmt.Errorf("key must be %d bytes", keySize)
+		}
+		return k.Key, nil
+	case keyTypePassphrase:
+		if k.Key != nil || k.Passphrase == "" {
+			return nil, fmt.Errorf("snapshot was encrypted with a passphrase")
+		}
+		if !validScryptParams(hdr.N, hdr.R, hdr.P) {
+			return nil, fmt.Errorf("passphrase key parameters are invalid or too large")
+		}
+		key, err := scrypt.Key([]byte(k.Passphrase), hdr.Salt, hdr.N, hdr.R, hdr.P, keySize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive key from passphrase: %v", err)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", hdr.KeyType)
+	}
+}
+
+// validScryptParams returns true if the given scrypt parameters are positive
+// and cost no more than the parameters snapshots are encrypted with.
+func validScryptParams(n, r, p int) bool {
+	if n <= 0 || r <= 0 || p <= 0 {
+		return false
+	}
+	memory := 128 * uint64(n) * uint64(r)
+	if n > maxScryptMemory || r > maxScryptMemory || memory > maxScryptMemory {
+		return false
+	}
+	return p <= maxScryptWork && uint64(n)*uint64(r)*uint64(p) <= maxScryptWork
+}
+
+// newGCM returns an AES-GCM cipher using the given key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt reads a snapshot archive from in and writes it to out, encrypted
+// with the given key.
+func Encrypt(out io.Writer, in io.Reader, key *EncryptionKey) error {
+	if key == nil {
+		return fmt.Errorf("an encryption key is required")
+	}
+
+	hdr := encryptionHeader{
+		Version:   encryptionVersion,
+		ChunkSize: chunkSize,
+	}
+	if key.Key != nil {
+		hdr.KeyType = keyTypeKeyfile
+	} else {
+		hdr.KeyType = keyTypePassphrase
+		hdr.Salt = make([]byte, saltSize)
+		if _, err := rand.Read(hdr.Salt); err != nil {
+			return fmt.Errorf("failed to generate salt: %v", err)
+		}
+		hdr.N, hdr.R, hdr.P = scryptN, scryptR, scryptP
+	}
+	kek, err := key.kek(&hdr)
+	if err != nil {
+		return err
+	}
+
+	// Generate the data key and wrap it with the key encryption key.
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return fmt.Errorf("failed to generate data key: %v", err)
+	}
+	wrap, err := newGCM(kek)
+	if err != nil {
+		return fmt.Errorf("failed to create key cipher: %v", err)
+	}
+	hdr.WrapNonce = make([]byte, wrap.NonceSize())
+	if _, err := rand.Read(hdr.WrapNonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	hdr.WrappedKey = wrap.Seal(nil, hdr.WrapNonce, dataKey, []byte(wrapAAD))
+
+	// Write out the magic and the header.
+	encoded, err := json.Marshal(&hdr)
+	if err != nil {
+		return fmt.Errorf("failed to encode encryption header: %v", err)
+	}
+	prefix := make([]byte, 0, len(encryptionMagic)+4+len(encoded))
+	prefix = append(prefix, encryptionMagic...)
+	prefix = binary.BigEndian.AppendUint32(prefix, uint32(len(encoded)))
+	prefix = append(prefix, encoded...)
+	if _, err := out.Write(prefix); err != nil {
+		return fmt.Errorf("failed to write encryption header: %v", err)
+	}
+
+	// Encrypt the archive a chunk at a time. We read ahead one chunk so we
+	// know when we're on the last one.
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return fmt.Errorf("failed to create data cipher: %v", err)
+	}
+	headerHash := sha256.Sum256(prefix)
+	buf := make([]byte, chunkSize)
+	next := make([]byte, chunkSize)
+	n, err := io.ReadFull(in, buf)
+	for index := uint64(0); ; index++ {
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("failed to read snapshot: %v", err)
+		}
+		final := err != nil
+		var m int
+		if !final {
+			m, err = io.ReadFull(in, next)
+			if err == io.EOF {
+				final = true
+			}
+		}
+
+		sealed := aead.Seal(nil, chunkNonce(index), buf[:n], chunkAAD(headerHash[:], index, final))
+		frame := make([]byte, 5, 5+len(sealed))
+		if final {
+			frame[0] = 1
+		}
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(sealed)))
+		if _, err := out.Write(append(frame, sealed...)); err != nil {
+			return fmt.Errorf("failed to write encrypted snapshot: %v", err)
+		}
+		if final {
+			return nil
+		}
+		buf, next, n = next, buf, m
+	}
+}
+
+// decrypt reads an encrypted snapshot from in and writes the decrypted archive
+// to out. Chunks are written as they are authenticated, so if an error is
+// returned anything written to out must be discarded.
+func decrypt(out io.Writer, in io.Reader, key *EncryptionKey) error {
+	prefix := make([]byte, len(encryptionMagic)+4)
+	if _, err := io.ReadFull(in, prefix); err != nil {
+		return fmt.Errorf("failed to read encryption header: %v", err)
+	}
+	if !bytes.Equal(prefix[:len(encryptionMagic)], encryptionMagic) {
+		return fmt.Errorf("not an encrypted snapshot")
+	}
+	size := binary.BigEndian.Uint32(prefix[len(encryptionMagic):])
+	if size > maxHeaderSize {
+		return fmt.Errorf("encryption header is too large")
+	}
+	encoded := make([]byte, size)
+	if _, err := io.ReadFull(in, encoded); err != nil {
+		return fmt.Errorf("failed to read encryption header: %v", err)
+	}
+	prefix = append(prefix, encoded...)
+
+	var hdr encryptionHeader
+	if err := json.Unmarshal(encoded, &hdr); err != nil {
+		return fmt.Errorf("failed to decode encryption header: %v", err)
+	}
+	if hdr.Version != encryptionVersion {
+		return fmt.Errorf("unsupported encryption version %d", hdr.Version)
+	}
+	if hdr.ChunkSize <= 0 || hdr.ChunkSize > maxChunkSize {
+		return fmt.Errorf("invalid chunk size %d", hdr.ChunkSize)
+	}
+
+	// Unwrap the data key.
+	kek, err := key.kek(&hdr)
+	if err != nil {
+		return err
+	}
+	wrap, err := newGCM(kek)
+	if err != nil {
+		return fmt.Errorf("failed to create key cipher: %v", err)
+	}
+	if len(hdr.WrapNonce) != wrap.NonceSize() {
+		return fmt.Errorf("invalid key nonce")
+	}
+	dataKey, err := wrap.Open(nil, hdr.WrapNonce, hdr.WrappedKey, []byte(wrapAAD))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt data key, the key is wrong or the snapshot has been tampered with")
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return fmt.Errorf("failed to create data cipher: %v", err)
+	}
+
+	headerHash := sha256.Sum256(prefix)
+	frame := make([]byte, 5)
+	for index := uint64(0); ; index++ {
+		if _, err := io.ReadFull(in, frame); err != nil {
+			return fmt.Errorf("encrypted snapshot is truncated")
+		}
+		final := frame[0] == 1
+		if frame[0] > 1 {
+			return fmt.Errorf("invalid chunk %d", index)
+		}
+		size := binary.BigEndian.Uint32(frame[1:])
+		if int64(size) > int64(hdr.ChunkSize+aead.Overhead()) {
+			return fmt.Errorf("chunk %d is too large", index)
+		}
+		sealed := make([]byte, size)
+		if _, err := io.ReadFull(in, sealed); err != nil {
+			return fmt.Errorf("encrypted snapshot is truncated")
+		}
+		plain, err := aead.Open(sealed[:0], chunkNonce(index), sealed, chunkAAD(headerHash[:], index, final))
+		if err != nil {
+			return fmt.Errorf("failed to decrypt chunk %d, the snapshot has been tampered with", index)
+		}
+		if _, err := out.Write(plain); err != nil {
+			return fmt.Errorf("failed to write decrypted snapshot: %v", err)
+		}
+		if final {
+			break
+		}
+	}
+
+	// Make sure nothing was appended after the last chunk.
+	var extra [1]byte
+	if n, _ := in.Read(extra[:]); n != 0 {
+		return fmt.Errorf("unexpected data after the last chunk")
+	}
+	return nil
+}
+
+// chunkNonce returns the nonce for the chunk at the given index. Every
+// snapshot has its own data key, so the nonces only need to be unique
+// within a snapshot.
+func chunkNonce(index uint64) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], index)
+	return nonce
+}
+
+// chunkAAD returns the additional data that binds a chunk to the header, its
+// position and whether it's the last chunk.
+func chunkAAD(headerHash []byte, index uint64, final bool) []byte {
+	aad := make([]byte, 0, len(headerHash)+9)
+	aad = append(aad, headerHash...)
+	aad = binary.BigEndian.AppendUint64(aad, index)
+	if final {
+		return append(aad, 1)
+	}
+	return append(aad, 0)
+}
+
+// IsEncrypted reports whether the snapshot in the given reader is encrypted,
+// without consuming any of it.
+func IsEncrypted(in *bufio.Reader) (bool, error) {
+	magic, err := in.Peek(len(encryptionMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return bytes.Equal(magic, encryptionMagic), nil
+}
+
+// MaybeDecrypt returns a reader for the snapshot archive in the given reader.
+// Encrypted snapshots are decrypted with the given key into a temporary file,
+// and are only returned once the whole file has been authenticated, so
+// tampered snapshots are refused before any of their contents are used. Other
+// snapshots are returned as they are. You must arrange to call Close() on the
+// returned object or else you may leak a temporary file.
+func MaybeDecrypt(logger hclog.Logger, in io.Reader, key *EncryptionKey) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(in)
+	encrypted, err := IsEncrypted(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %v", err)
+	}
+	if !encrypted {
+		return io.NopCloser(buffered), nil
+	}
+	if key == nil {
+		return nil, fmt.Errorf("snapshot is encrypted, a key file or passphrase is required")
+	}
+
+	decrypted, err := os.CreateTemp("", "snapshot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp snapshot file: %v", err)
+	}
+	if err := decrypt(decrypted, buffered, key); err != nil {
+		cleanupTempFile(logger, decrypted)
+		return nil, err
+	}
+	if _, err := decrypted.Seek(0, io.SeekStart); err != nil {
+		cleanupTempFile(logger, decrypted)
+		return nil, fmt.Errorf("failed to rewind temp snapshot: %v", err)
+	}
+	return &tempFile{File: decrypted, logger: logger}, nil
+}
+
+// tempFile is a temporary file that is removed when it's closed.
+type tempFile struct {
+	*os.File
+	logger hclog.Logger
+}
+
+func (f *tempFile) Close() error {
+	cleanupTempFile(f.logger, f.File)
+	return nil
+}
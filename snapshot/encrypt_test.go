@@ -0,0 +1,191 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package snapshot
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/sdk/testutil"
+)
+
+func TestEncrypt(t *testing.T) {
+	logger := testutil.Logger(t)
+
+	newKey := func(t *testing.T) *EncryptionKey {
+		key := make([]byte, keySize)
+		_, err := rand.Read(key)
+		require.NoError(t, err)
+		return &EncryptionKey{Key: key}
+	}
+
+	// encrypt returns the given data encrypted with the key.
+	encrypt := func(t *testing.T, data []byte, key *EncryptionKey) []byte {
+		t.Helper()
+		var out bytes.Buffer
+		require.NoError(t, Encrypt(&out, bytes.NewReader(data), key))
+		return out.Bytes()
+	}
+
+	// decrypt returns the plaintext of the given data.
+	decrypt := func(data []byte, key *EncryptionKey) ([]byte, error) {
+		rc, err := MaybeDecrypt(logger, bytes.NewReader(data), key)
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+
+	// Use a few sizes around the chunk boundaries.
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 7} {
+		data := make([]byte, size)
+		_, err := rand.Read(data)
+		require.NoError(t, err)
+
+		key := newKey(t)
+		encrypted := encrypt(t, data, key)
+
+		plain, err := decrypt(encrypted, key)
+		require.NoError(t, err, "size %d", size)
+		require.Equal(t, data, plain, "size %d", size)
+	}
+
+	data := make([]byte, 2*chunkSize+100)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+	key := newKey(t)
+	encrypted := encrypt(t, data, key)
+	headerEnd := len(encryptionMagic) + 4 + int(binary.BigEndian.Uint32(encrypted[len(encryptionMagic):]))
+
+	t.Run("unencrypted", func(t *testing.T) {
+		plain, err := decrypt(data, nil)
+		require.NoError(t, err)
+		require.Equal(t, data, plain)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, err := decrypt(encrypted, nil)
+		require.ErrorContains(t, err, "snapshot is encrypted")
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		_, err := decrypt(encrypted, newKey(t))
+		require.ErrorContains(t, err, "failed to decrypt data key")
+	})
+
+	t.Run("passphrase for key file", func(t *testing.T) {
+		_, err := decrypt(encrypted, &EncryptionKey{Passphrase: "hunter2"})
+		require.ErrorContains(t, err, "snapshot was encrypted with a key file")
+	})
+
+	t.Run("tampered header", func(t *testing.T) {
+		tampered := bytes.Clone(encrypted)
+		tampered[headerEnd-2] ^= 1
+		_, err := decrypt(tampered, key)
+		require.Error(t, err)
+	})
+
+	t.Run("tampered chunk", func(t *testing.T) {
+		tampered := bytes.Clone(encrypted)
+		tampered[len(tampered)-10] ^= 1
+		_, err := decrypt(tampered, key)
+		require.ErrorContains(t, err, "has been tampered with")
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		// Drop the last chunk.
+		lastChunk := 5 + 100 + 16
+		_, err := decrypt(encrypted[:len(encrypted)-lastChunk], key)
+		require.ErrorContains(t, err, "truncated")
+
+		_, err = decrypt(encrypted[:len(encrypted)-1], key)
+		require.ErrorContains(t, err, "truncated")
+	})
+
+	t.Run("final flag", func(t *testing.T) {
+		// Marking the first chunk as the last should fail authentication
+		// rather than silently dropping the rest of the data.
+		tampered := bytes.Clone(encrypted)
+		tampered[headerEnd] = 1
+		_, err := decrypt(tampered, key)
+		require.ErrorContains(t, err, "has been tampered with")
+	})
+
+	t.Run("trailing data", func(t *testing.T) {
+		_, err := decrypt(append(bytes.Clone(encrypted), 0), key)
+		require.ErrorContains(t, err, "unexpected data after the last chunk")
+	})
+
+	t.Run("passphrase", func(t *testing.T) {
+		key := &EncryptionKey{Passphrase: "correct horse battery staple"}
+		encrypted := encrypt(t, data, key)
+
+		plain, err := decrypt(encrypted, key)
+		require.NoError(t, err)
+		require.Equal(t, data, plain)
+
+		_, err = decrypt(encrypted, &EncryptionKey{Passphrase: "hunter2"})
+		require.ErrorContains(t, err, "failed to decrypt data key")
+	})
+
+	t.Run("expensive passphrase parameters", func(t *testing.T) {
+		key := &EncryptionKey{Passphrase: "correct horse battery staple"}
+		for _, params := range [][3]int{
+			{scryptN * 2, scryptR, scryptP},
+			{scryptN, scryptR * 2, scryptP},
+			{scryptN, scryptR, scryptP * 2},
+			{1 << 22, 1 << 10, 1},
+			{scryptN, -scryptR, -scryptP},
+		} {
+			hdr := &encryptionHeader{
+				KeyType: keyTypePassphrase,
+				N:       params[0],
+				R:       params[1],
+				P:       params[2],
+			}
+			_, err := key.kek(hdr)
+			require.ErrorContains(t, err, "parameters are invalid or too large", "params %v", params)
+		}
+	})
+}
+
+func TestLoadEncryptionKey(t *testing.T) {
+	dir := testutil.TempDir(t, "snapshot")
+
+	key, err := LoadEncryptionKey("", "")
+	require.NoError(t, err)
+	require.Nil(t, key)
+
+	key, err = LoadEncryptionKey("", "hunter2")
+	require.NoError(t, err)
+	require.Equal(t, &EncryptionKey{Passphrase: "hunter2"}, key)
+
+	raw := make([]byte, keySize)
+	_, err = rand.Read(raw)
+	require.NoError(t, err)
+	keyfile := filepath.Join(dir, "good.key")
+	require.NoError(t, os.WriteFile(keyfile, []byte(base64.StdEncoding.EncodeToString(raw)+"\n"), 0600))
+
+	// The key file takes precedence over the passphrase.
+	key, err = LoadEncryptionKey(keyfile, "hunter2")
+	require.NoError(t, err)
+	require.Equal(t, &EncryptionKey{Key: raw}, key)
+
+	short := filepath.Join(dir, "short.key")
+	require.NoError(t, os.WriteFile(short, []byte(base64.StdEncoding.EncodeToString(raw[:16])), 0600))
+	_, err = LoadEncryptionKey(short, "")
+	require.ErrorContains(t, err, "must hold a 32 byte key")
+
+	_, err = LoadEncryptionKey(filepath.Join(dir, "missing.key"), "")
+	require.ErrorContains(t, err, "failed to read key file")
+}
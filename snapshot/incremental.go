@@ -0,0 +1,317 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package snapshot
+
+import (
+	"compress/gzip"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hashicorp/consul-net-rpc/go-msgpack/codec"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+)
+
+// An incremental snapshot uses the same archive format as a full snapshot, but
+// state.bin holds the Raft log entries applied since a base snapshot instead
+// of the state of the FSM. The base is identified in meta.json by its index
+// and the hash of its state.bin, which lets a chain of incremental snapshots
+// be verified before it is applied on top of the base with Merge.
+//
+// The base can be a full snapshot, or another incremental snapshot so that
+// each one only holds the changes since the one before. Log entries are only
+// kept by the servers for a while after Raft compacts its logs, so the base
+// must be recent enough that the entries after it are still available.
+
+// ErrIncremental is returned when an incremental snapshot is read or restored
+// on its own. Its log entries only make sense on top of its base, so it must
+// be merged with the base first.
+var ErrIncremental = errors.New("snapshot is incremental and must be merged with its base snapshot")
+
+// IncrementalMeta identifies the base of an incremental snapshot.
+type IncrementalMeta struct {
+	// BaseIndex is the index of the base snapshot. The incremental snapshot
+	// holds the Raft log entries after this index.
+	BaseIndex uint64
+
+	// BaseHash is the hex-encoded SHA-256 of the base snapshot's state.bin.
+	BaseHash string
+}
+
+// Info describes a snapshot archive.
+type Info struct {
+	// Meta is the Raft metadata for the snapshot. For an incremental snapshot
+	// the Index is that of the last log entry it holds.
+	Meta raft.SnapshotMeta
+
+	// Incremental is set for incremental snapshots.
+	Incremental *IncrementalMeta
+
+	// Hash is the hex-encoded SHA-256 of the snapshot's state.bin, which is
+	// used as the BaseHash of incremental snapshots built on this one.
+	Hash string
+}
+
+// ReadInfo takes the snapshot from the reader, verifies its contents, and
+// returns information about it.
+func ReadInfo(in io.Reader) (*Info, error) {
+	// Wrap the reader in a gzip decompressor.
+	decomp, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot: %v", err)
+	}
+	defer decomp.Close()
+
+	// Read the archive, throwing away the snapshot data.
+	var metadata archiveMeta
+	hash, err := readArchive(decomp, &metadata, io.Discard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot file: %v", err)
+	}
+
+	if err := concludeGzipRead(decomp); err != nil {
+		return nil, err
+	}
+
+	return &Info{
+		Meta:        metadata.SnapshotMeta,
+		Incremental: metadata.Incremental,
+		Hash:        hex.EncodeToString(hash),
+	}, nil
+}
+
+// logEntry is the encoding of a Raft log entry in an incremental snapshot.
+type logEntry struct {
+	Index      uint64
+	Term       uint64
+	Type       raft.LogType
+	Data       []byte
+	Extensions []byte
+}
+
+// NewIncremental takes an incremental snapshot of the given Raft instance into
+// a temporary file, holding the log entries that have been applied since the
+// given base. It returns an error if those entries are no longer in the log
+// store, in which case a new full snapshot is needed. You must arrange to call
+// Close() on the returned object or else you will leak a temporary file.
+func NewIncremental(logger hclog.Logger, r *raft.Raft, logs raft.LogStore, base IncrementalMeta) (*Snapshot, error) {
+	last := r.AppliedIndex()
+	if base.BaseIndex > last {
+		return nil, fmt.Errorf("base snapshot index %d is ahead of the last applied index %d", base.BaseIndex, last)
+	}
+	first, err := logs.FirstIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read first log index: %v", err)
+	}
+	if base.BaseIndex < last && base.BaseIndex+1 < first {
+		return nil, fmt.Errorf("log entries after base snapshot index %d have been compacted, a full snapshot is required", base.BaseIndex)
+	}
+
+	configFuture := r.GetConfiguration()
+	if err := configFuture.Error(); err != nil {
+		return nil, fmt.Errorf("failed to get Raft configuration: %v", err)
+	}
+
+	// Encode the log entries into a scratch file first so we know the size
+	// of the data for the archive.
+	entries, err := os.CreateTemp("", "snapshot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp log file: %v", err)
+	}
+	defer func() {
+		if err := entries.Close(); err != nil {
+			logger.Error("Failed to close temp log file", "error", err)
+		}
+		if err := os.Remove(entries.Name()); err != nil {
+			logger.Error("Failed to clean up temp log file", "error", err)
+		}
+	}()
+
+	var term uint64
+	enc := codec.NewEncoder(entries, &codec.MsgpackHandle{})
+	for idx := base.BaseIndex + 1; idx <= last; idx++ {
+		var l raft.Log
+		if err := logs.GetLog(idx, &l); err != nil {
+			return nil, fmt.Errorf("failed to read log entry %d: %v", idx, err)
+		}
+		entry := logEntry{
+			Index:      l.Index,
+			Term:       l.Term,
+			Type:       l.Type,
+			Data:       l.Data,
+			Extensions: l.Extensions,
+		}
+		if err := enc.Encode(&entry); err != nil {
+			return nil, fmt.Errorf("failed to encode log entry %d: %v", idx, err)
+		}
+		term = l.Term
+	}
+
+	size, err := entries.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to size temp log file: %v", err)
+	}
+	if _, err := entries.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind temp log file: %v", err)
+	}
+
+	metadata := &archiveMeta{
+		SnapshotMeta: raft.SnapshotMeta{
+			Version:            raft.SnapshotVersionMax,
+			ID:                 fmt.Sprintf("incremental-%d-%d", base.BaseIndex, last),
+			Index:              last,
+			Term:               term,
+			Configuration:      configFuture.Configuration(),
+			ConfigurationIndex: configFuture.Index(),
+			Size:               size,
+		},
+		Incremental: &base,
+	}
+	return newArchive(logger, metadata, entries)
+}
+
+// Merge rebuilds a full snapshot from a base snapshot and a chain of
+// incremental snapshots, and writes it to out. The base is restored into the
+// given FSM, which should be empty, and the log entries from each incremental
+// snapshot are applied to it in order. The chain is verified as it goes: each
+// incremental snapshot must name the one before it as its base, and hold every
+// log entry after it.
+func Merge(logger hclog.Logger, fsm raft.FSM, base io.Reader, increments []io.Reader, out io.Writer) (*raft.SnapshotMeta, error) {
+	snap, metadata, hash, err := readToFile(logger, base)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanupTempFile(logger, snap)
+
+	if metadata.Incremental != nil {
+		return nil, fmt.Errorf("base snapshot must be a full snapshot")
+	}
+	if err := fsm.Restore(snap); err != nil {
+		return nil, fmt.Errorf("failed to restore base snapshot: %v", err)
+	}
+
+	merged := metadata.SnapshotMeta
+	for i, in := range increments {
+		if err := applyIncrement(logger, fsm, in, &merged, &hash); err != nil {
+			return nil, fmt.Errorf("incremental snapshot %d: %v", i+1, err)
+		}
+	}
+
+	// Write out the state of the FSM as a new full snapshot.
+	state, err := os.CreateTemp("", "snapshot")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp snapshot file: %v", err)
+	}
+	defer cleanupTempFile(logger, state)
+
+	fsmSnap, err := fsm.Snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot merged state: %v", err)
+	}
+	defer fsmSnap.Release()
+	if err := fsmSnap.Persist(&fileSink{state}); err != nil {
+		return nil, fmt.Errorf("failed to persist merged state: %v", err)
+	}
+
+	if merged.Size, err = state.Seek(0, io.SeekCurrent); err != nil {
+		return nil, fmt.Errorf("failed to size temp snapshot file: %v", err)
+	}
+	if _, err := state.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind temp snapshot file: %v", err)
+	}
+	merged.ID = fmt.Sprintf("merged-%d", merged.Index)
+
+	compressor := gzip.NewWriter(out)
+	if err := write(compressor, &merged, state); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot file: %v", err)
+	}
+	if err := compressor.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress snapshot file: %v", err)
+	}
+	return &merged, nil
+}
+
+// applyIncrement verifies that the incremental snapshot from the reader builds
+// on the snapshot described by prev and prevHash, and applies its log entries
+// to the FSM. On success prev and prevHash describe the incremental snapshot.
+func applyIncrement(logger hclog.Logger, fsm raft.FSM, in io.Reader, prev *raft.SnapshotMeta, prevHash *[]byte) error {
+	snap, metadata, hash, err := readToFile(logger, in)
+	if err != nil {
+		return err
+	}
+	defer cleanupTempFile(logger, snap)
+
+	inc := metadata.Incremental
+	switch {
+	case inc == nil:
+		return fmt.Errorf("not an incremental snapshot")
+	case inc.BaseIndex != prev.Index:
+		return fmt.Errorf("base index %d does not match the previous snapshot index %d", inc.BaseIndex, prev.Index)
+	case inc.BaseHash != hex.EncodeToString(*prevHash):
+		return fmt.Errorf("base hash does not match the previous snapshot")
+	}
+
+	next := inc.BaseIndex + 1
+	dec := codec.NewDecoder(snap, &codec.MsgpackHandle{})
+	for {
+		var entry logEntry
+		if err := dec.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to decode log entry %d: %v", next, err)
+		}
+		if entry.Index != next {
+			return fmt.Errorf("expected log entry %d but found %d", next, entry.Index)
+		}
+		next++
+
+		l := &raft.Log{
+			Index:      entry.Index,
+			Term:       entry.Term,
+			Type:       entry.Type,
+			Data:       entry.Data,
+			Extensions: entry.Extensions,
+		}
+		switch l.Type {
+		case raft.LogCommand:
+			fsm.Apply(l)
+		case raft.LogConfiguration:
+			prev.Configuration = raft.DecodeConfiguration(l.Data)
+			prev.ConfigurationIndex = l.Index
+		}
+	}
+	if next-1 != metadata.Index {
+		return fmt.Errorf("log entries end at index %d but the snapshot index is %d", next-1, metadata.Index)
+	}
+
+	prev.Index = metadata.Index
+	if metadata.Term != 0 {
+		prev.Term = metadata.Term
+	}
+	*prevHash = hash
+	return nil
+}
+
+// cleanupTempFile closes and removes a temporary file.
+func cleanupTempFile(logger hclog.Logger, f *os.File) {
+	if err := f.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+		logger.Debug("Failed to close temp snapshot", "error", err)
+	}
+	if err := os.Remove(f.Name()); err != nil {
+		logger.Error("Failed to clean up temp snapshot", "error", err)
+	}
+}
+
+// fileSink is a raft.SnapshotSink that writes to a file. The file is left open
+// so it can be read back once the snapshot has been persisted.
+type fileSink struct {
+	*os.File
+}
+
+func (s *fileSink) ID() string    { return "" }
+func (s *fileSink) Cancel() error { return nil }
+func (s *fileSink) Close() error  { return nil }
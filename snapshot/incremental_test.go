@@ -0,0 +1,170 @@
+// Copyright IBM Corp. 2024, 2026
+// SPDX-License-Identifier: BUSL-1.1
+
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/sdk/testutil"
+)
+
+func TestIncrementalSnapshot(t *testing.T) {
+	dir := testutil.TempDir(t, "snapshot")
+	logger := testutil.Logger(t)
+
+	r, fsm, store := makeRaftWithStore(t, dir)
+	defer r.Shutdown()
+
+	var applied int
+	apply := func(n int) {
+		for i := 0; i < n; i++ {
+			future := r.Apply([]byte(fmt.Sprintf("entry-%d", applied)), time.Second)
+			require.NoError(t, future.Error())
+			applied++
+		}
+	}
+
+	// save reads the snapshot into memory and returns it along with its info.
+	save := func(snap *Snapshot, err error) ([]byte, *Info) {
+		t.Helper()
+		require.NoError(t, err)
+		defer snap.Close()
+
+		data, err := io.ReadAll(snap)
+		require.NoError(t, err)
+		info, err := ReadInfo(bytes.NewReader(data))
+		require.NoError(t, err)
+		return data, info
+	}
+
+	// Take a full snapshot, then a chain of two incremental snapshots.
+	apply(10)
+	full, fullInfo := save(New(logger, r))
+	require.Nil(t, fullInfo.Incremental)
+
+	apply(5)
+	inc1, inc1Info := save(NewIncremental(logger, r, store, IncrementalMeta{
+		BaseIndex: fullInfo.Meta.Index,
+		BaseHash:  fullInfo.Hash,
+	}))
+	require.Equal(t, &IncrementalMeta{BaseIndex: fullInfo.Meta.Index, BaseHash: fullInfo.Hash}, inc1Info.Incremental)
+	require.Equal(t, r.AppliedIndex(), inc1Info.Meta.Index)
+
+	apply(5)
+	inc2, inc2Info := save(NewIncremental(logger, r, store, IncrementalMeta{
+		BaseIndex: inc1Info.Meta.Index,
+		BaseHash:  inc1Info.Hash,
+	}))
+	require.Equal(t, r.AppliedIndex(), inc2Info.Meta.Index)
+
+	merge := func(base []byte, increments ...[]byte) (*raft.SnapshotMeta, []byte, error) {
+		var readers []io.Reader
+		for _, inc := range increments {
+			readers = append(readers, bytes.NewReader(inc))
+		}
+		var out bytes.Buffer
+		meta, err := Merge(logger, &MockFSM{}, bytes.NewReader(base), readers, &out)
+		return meta, out.Bytes(), err
+	}
+
+	testutil.RunStep(t, "merge chain", func(t *testing.T) {
+		meta, merged, err := merge(full, inc1, inc2)
+		require.NoError(t, err)
+		require.Equal(t, inc2Info.Meta.Index, meta.Index)
+
+		// The merged snapshot is a full snapshot with all of the entries.
+		info, err := ReadInfo(bytes.NewReader(merged))
+		require.NoError(t, err)
+		require.Nil(t, info.Incremental)
+		require.Equal(t, inc2Info.Meta.Index, info.Meta.Index)
+
+		snap, _, err := Read(logger, bytes.NewReader(merged))
+		require.NoError(t, err)
+		restored := &MockFSM{}
+		require.NoError(t, restored.Restore(snap))
+
+		fsm.Lock()
+		defer fsm.Unlock()
+		require.Equal(t, fsm.logs, restored.logs)
+		require.Len(t, restored.logs, applied)
+	})
+
+	testutil.RunStep(t, "differential", func(t *testing.T) {
+		// An incremental snapshot can also be based on the full snapshot.
+		diff, _ := save(NewIncremental(logger, r, store, IncrementalMeta{
+			BaseIndex: fullInfo.Meta.Index,
+			BaseHash:  fullInfo.Hash,
+		}))
+		meta, _, err := merge(full, diff)
+		require.NoError(t, err)
+		require.Equal(t, inc2Info.Meta.Index, meta.Index)
+	})
+
+	testutil.RunStep(t, "broken chain", func(t *testing.T) {
+		_, _, err := merge(full, inc2)
+		require.ErrorContains(t, err, "incremental snapshot 1: base index")
+
+		_, _, err = merge(full, inc1, inc1)
+		require.ErrorContains(t, err, "incremental snapshot 2: base index")
+
+		_, _, err = merge(inc1, inc2)
+		require.ErrorContains(t, err, "must be a full snapshot")
+
+		_, _, err = merge(full, full)
+		require.ErrorContains(t, err, "not an incremental snapshot")
+
+		// A base with the right index but different contents is caught by
+		// the hash.
+		forged, _ := save(NewIncremental(logger, r, store, IncrementalMeta{
+			BaseIndex: inc1Info.Meta.Index,
+			BaseHash:  fullInfo.Hash,
+		}))
+		_, _, err = merge(full, inc1, forged)
+		require.ErrorContains(t, err, "base hash does not match")
+	})
+
+	testutil.RunStep(t, "restore without base", func(t *testing.T) {
+		fsm.Lock()
+		before := append([][]byte(nil), fsm.logs...)
+		fsm.Unlock()
+
+		err := Restore(logger, bytes.NewReader(inc1), r)
+		require.ErrorIs(t, err, ErrIncremental)
+
+		_, _, err = Read(logger, bytes.NewReader(inc1))
+		require.ErrorIs(t, err, ErrIncremental)
+
+		_, err = Verify(bytes.NewReader(inc1))
+		require.ErrorIs(t, err, ErrIncremental)
+
+		// The failed restore didn't touch the state.
+		fsm.Lock()
+		defer fsm.Unlock()
+		require.Equal(t, before, fsm.logs)
+	})
+
+	testutil.RunStep(t, "base ahead", func(t *testing.T) {
+		_, err := NewIncremental(logger, r, store, IncrementalMeta{BaseIndex: r.AppliedIndex() + 10})
+		require.ErrorContains(t, err, "is ahead of the last applied index")
+	})
+
+	testutil.RunStep(t, "compacted", func(t *testing.T) {
+		first, err := store.FirstIndex()
+		require.NoError(t, err)
+		require.NoError(t, store.DeleteRange(first, fullInfo.Meta.Index+1))
+
+		_, err = NewIncremental(logger, r, store, IncrementalMeta{
+			BaseIndex: fullInfo.Meta.Index,
+			BaseHash:  fullInfo.Hash,
+		})
+		require.ErrorContains(t, err, "have been compacted")
+	})
+}
@@ -46,6 +46,12 @@ func New(logger hclog.Logger, r *raft.Raft) (*Snapshot, error) {
 		}
 	}()
 
+	return newArchive(logger, &archiveMeta{SnapshotMeta: *metadata}, snap)
+}
+
+// newArchive writes an archive with the given metadata and snapshot data into
+// a temporary file and returns an object that gives access to the file.
+func newArchive(logger hclog.Logger, metadata *archiveMeta, snap io.Reader) (*Snapshot, error) {
 	// Make a scratch file to receive the contents so that we don't buffer
 	// everything in memory. This gets deleted in Close() since we keep it
 	// around for re-reading.
@@ -72,7 +78,7 @@ func New(logger hclog.Logger, r *raft.Raft) (*Snapshot, error) {
 	compressor := gzip.NewWriter(archive)
 
 	// Write the archive.
-	if err := write(compressor, metadata, snap); err != nil {
+	if err := writeArchive(compressor, metadata, snap); err != nil {
 		return nil, fmt.Errorf("failed to write snapshot file: %v", err)
 	}
 
@@ -138,7 +144,7 @@ func Verify(in io.Reader) (*raft.SnapshotMeta, error) {
 	// Read the archive, throwing away the snapshot data.
 	var metadata raft.SnapshotMeta
 	if err := read(decomp, &metadata, io.Discard); err != nil {
-		return nil, fmt.Errorf("failed to read snapshot file: %v", err)
+		return nil, fmt.Errorf("failed to read snapshot file: %w", err)
 	}
 
 	if err := concludeGzipRead(decomp); err != nil {
@@ -164,11 +170,27 @@ func concludeGzipRead(decomp *gzip.Reader) error {
 }
 
 // Read a snapshot into a temporary file. The caller is responsible for removing the file.
+// It returns ErrIncremental for an incremental snapshot, which must be merged
+// with its base before it can be read.
 func Read(logger hclog.Logger, in io.Reader) (*os.File, *raft.SnapshotMeta, error) {
+	snap, metadata, _, err := readToFile(logger, in)
+	if err != nil {
+		return nil, nil, err
+	}
+	if metadata.Incremental != nil {
+		cleanupTempFile(logger, snap)
+		return nil, nil, ErrIncremental
+	}
+	return snap, &metadata.SnapshotMeta, nil
+}
+
+// readToFile is like Read but returns the full archive metadata and the hash
+// of the snapshot data.
+func readToFile(logger hclog.Logger, in io.Reader) (*os.File, *archiveMeta, []byte, error) {
 	// Wrap the reader in a gzip decompressor.
 	decomp, err := gzip.NewReader(in)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to decompress snapshot: %v", err)
+		return nil, nil, nil, fmt.Errorf("failed to decompress snapshot: %v", err)
 	}
 	defer func() {
 		if err := decomp.Close(); err != nil {
@@ -180,27 +202,28 @@ func Read(logger hclog.Logger, in io.Reader) (*os.File, *raft.SnapshotMeta, erro
 	// we can avoid buffering in memory.
 	snap, err := os.CreateTemp("", "snapshot")
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create temp snapshot file: %v", err)
+		return nil, nil, nil, fmt.Errorf("failed to create temp snapshot file: %v", err)
 	}
 
 	// Read the archive.
-	var metadata raft.SnapshotMeta
-	if err := read(decomp, &metadata, snap); err != nil {
-		return nil, nil, fmt.Errorf("failed to read snapshot file: %v", err)
+	var metadata archiveMeta
+	hash, err := readArchive(decomp, &metadata, snap)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read snapshot file: %v", err)
 	}
 
 	if err := concludeGzipRead(decomp); err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	// Sync and rewind the file so it's ready to be read again.
 	if err := snap.Sync(); err != nil {
-		return nil, nil, fmt.Errorf("failed to sync temp snapshot: %v", err)
+		return nil, nil, nil, fmt.Errorf("failed to sync temp snapshot: %v", err)
 	}
 	if _, err := snap.Seek(0, 0); err != nil {
-		return nil, nil, fmt.Errorf("failed to rewind temp snapshot: %v", err)
+		return nil, nil, nil, fmt.Errorf("failed to rewind temp snapshot: %v", err)
 	}
-	return snap, &metadata, nil
+	return snap, &metadata, hash, nil
 }
 
 // Restore takes the snapshot from the reader and attempts to apply it to the
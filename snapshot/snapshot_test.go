@@ -78,6 +78,12 @@ func (m *MockSnapshot) Release() {
 
 // makeRaft returns a Raft and its FSM, with snapshots based in the given dir.
 func makeRaft(t *testing.T, dir string) (*raft.Raft, *MockFSM) {
+	r, fsm, _ := makeRaftWithStore(t, dir)
+	return r, fsm
+}
+
+// makeRaftWithStore is like makeRaft but also returns the Raft log store.
+func makeRaftWithStore(t *testing.T, dir string) (*raft.Raft, *MockFSM, *raft.InmemStore) {
 	snaps, err := raft.NewFileSnapshotStore(dir, 5, nil)
 	if err != nil {
 		t.Fatalf("err: %v", err)
@@ -120,7 +126,7 @@ func makeRaft(t *testing.T, dir string) (*raft.Raft, *MockFSM) {
 		}
 	}
 
-	return raft, fsm
+	return raft, fsm, store
 }
 
 func TestSnapshot(t *testing.T) {
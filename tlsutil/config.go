@@ -869,6 +869,27 @@ func (c *Configurator) IncomingHTTPSConfig() *tls.Config {
 	return config
 }
 
+// IncomingDNSConfig generates a *tls.Config for incoming DNS over TLS
+// connections. It uses the same certificates as HTTPS.
+func (c *Configurator) IncomingDNSConfig() *tls.Config {
+	c.log("IncomingDNSConfig")
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	config := c.commonTLSConfig(
+		c.https,
+		c.base.HTTPS,
+		c.base.HTTPS.VerifyIncoming,
+	)
+	// ALPN protocol identifier for DNS over TLS, see RFC 7858.
+	config.NextProtos = []string{"dot"}
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		return c.IncomingDNSConfig(), nil
+	}
+	return config
+}
+
 // OutgoingTLSConfigForCheck creates a client *tls.Config for executing checks.
 // It is RECOMMENDED that the serverName be left unspecified. The crypto/tls
 // client will deduce the ServerName (for SNI) from the check address unless